@@ -61,6 +61,13 @@ const (
 
 var testMode = false
 
+// subcommands are tools run instead of Cortex, when their name is passed as the first argument.
+// They return the exit code of the process.
+var subcommands = map[string]func(args []string, stdout, stderr io.Writer) int{
+	"memberlist-diff": runMemberlistDiff,
+	"ring-simulate":   runRingSimulate,
+}
+
 func main() {
 	var (
 		cfg                  cortex.Config
@@ -72,6 +79,15 @@ func main() {
 	)
 
 	args := os.Args[1:]
+	if len(args) > 0 {
+		if run, ok := subcommands[args[0]]; ok {
+			if code := run(args[1:], os.Stdout, os.Stderr); code != 0 && !testMode {
+				os.Exit(code)
+			}
+			return
+		}
+	}
+
 	configFile, expandENV := parseConfigFileParameter(args)
 
 	// This sets default values from flags to the config.
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexproject/cortex/pkg/ring/kv/memberlist"
+)
+
+func TestRunMemberlistDiff(t *testing.T) {
+	serveState := func(state *memberlist.StateDump) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			assert.Equal(t, "/memberlist", req.URL.Path)
+			assert.Equal(t, "true", req.URL.Query().Get("dump"))
+			require.NoError(t, json.NewEncoder(w).Encode(state))
+		}))
+	}
+
+	nodeA := serveState(&memberlist.StateDump{Node: "node-a", Keys: map[string]memberlist.KeyState{
+		"ring": {Value: json.RawMessage(`{"ingesters":{"ingester-1":{"state":2,"timestamp":10}}}`)},
+	}})
+	defer nodeA.Close()
+
+	nodeB := serveState(&memberlist.StateDump{Node: "node-b", Keys: map[string]memberlist.KeyState{
+		"ring": {Value: json.RawMessage(`{"ingesters":{"ingester-1":{"state":3,"timestamp":20}}}`)},
+	}})
+	defer nodeB.Close()
+
+	var stdout, stderr bytes.Buffer
+	code := runMemberlistDiff([]string{nodeA.URL + "/memberlist", nodeB.URL + "/memberlist"}, &stdout, &stderr)
+	assert.Equal(t, 1, code)
+	assert.Empty(t, stderr.String())
+	assert.Equal(t, `--- node-a
++++ node-b
+ring: ingesters.ingester-1.state
+- 2
++ 3
+ring: ingesters.ingester-1.timestamp
+- 10
++ 20
+`, stdout.String())
+
+	stdout.Reset()
+	code = runMemberlistDiff([]string{"-ignore-fields=state,timestamp", nodeA.URL + "/memberlist", nodeB.URL + "/memberlist"}, &stdout, &stderr)
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stdout.String(), "No differences found.")
+
+	stdout.Reset()
+	code = runMemberlistDiff([]string{nodeA.URL + "/memberlist"}, &stdout, &stderr)
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr.String(), "Usage: cortex memberlist-diff")
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/pkg/errors"
+	"github.com/thanos-io/thanos/pkg/discovery/dns"
+
+	"github.com/cortexproject/cortex/pkg/cortex"
+	"github.com/cortexproject/cortex/pkg/ingester"
+	"github.com/cortexproject/cortex/pkg/ring"
+	"github.com/cortexproject/cortex/pkg/ring/kv"
+	"github.com/cortexproject/cortex/pkg/ring/kv/codec"
+	"github.com/cortexproject/cortex/pkg/ring/kv/memberlist"
+	"github.com/cortexproject/cortex/pkg/ring/kv/raft"
+	"github.com/cortexproject/cortex/pkg/util"
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+	"github.com/cortexproject/cortex/pkg/util/services"
+)
+
+// runRingSimulate reads the ingesters ring from the KV store configured in the Cortex config file,
+// and reports the availability of the writes and reads of the given tenants if the given instances
+// or zones failed. It exits with 0 if no tenant is affected, 1 if any tenant is affected and 2 on error.
+func runRingSimulate(args []string, stdout, stderr io.Writer) int {
+	var (
+		configFile    string
+		expandEnv     bool
+		failInstances flagext.StringSliceCSV
+		failZones     flagext.StringSliceCSV
+		tenants       flagext.StringSliceCSV
+		timeout       time.Duration
+		output        string
+	)
+
+	fs := flag.NewFlagSet("ring-simulate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&configFile, configFileOption, "", "Cortex configuration file, used to connect to the KV store of the ingesters ring and to get the replication and shuffle sharding settings.")
+	fs.BoolVar(&expandEnv, configExpandENV, false, "Expands ${var} or $var in config according to the values of the environment variables.")
+	fs.Var(&failInstances, "fail-instances", "Comma-separated list of instance IDs to simulate the failure of.")
+	fs.Var(&failZones, "fail-zones", "Comma-separated list of zones to simulate the failure of.")
+	fs.Var(&tenants, "tenants", "Comma-separated list of tenants to report about, each optionally followed by :<shard size>. The shard size defaults to the ingestion tenant shard size of the config file when shuffle sharding is enabled.")
+	fs.DurationVar(&timeout, "timeout", 30*time.Second, "Timeout for reading the ring from the KV store.")
+	fs.StringVar(&output, "output", "text", "Output format: text or json.")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: cortex ring-simulate -config.file=<file> -tenants=<tenants> [-fail-instances=<instances>] [-fail-zones=<zones>] [options]")
+		fmt.Fprintln(fs.Output(), "")
+		fmt.Fprintln(fs.Output(), "Simulates the failure of instances or zones of the ingesters ring, for example: cortex ring-simulate -config.file=cortex.yaml -fail-zones=zone-a -tenants=tenant-1,tenant-2:6")
+		fmt.Fprintln(fs.Output(), "")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 0 || configFile == "" || len(tenants) == 0 || (len(failInstances) == 0 && len(failZones) == 0) {
+		fs.Usage()
+		return 2
+	}
+	if output != "text" && output != "json" {
+		fmt.Fprintf(stderr, "unsupported output format: %s\n", output)
+		return 2
+	}
+
+	var cfg cortex.Config
+	flagext.DefaultValues(&cfg)
+	if err := LoadConfig(configFile, expandEnv, &cfg); err != nil {
+		fmt.Fprintf(stderr, "error loading config from %s: %v\n", configFile, err)
+		return 2
+	}
+
+	sim := ring.FailureSimulation{
+		FailedInstances: failInstances,
+		FailedZones:     failZones,
+		Tenants:         map[string]int{},
+		WriteOp:         ring.WriteNoExtend,
+	}
+	if cfg.Distributor.ExtendWrites {
+		sim.WriteOp = ring.Write
+	}
+	for _, t := range tenants {
+		tenant, shardSize, err := parseTenantShardSize(t, &cfg)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		sim.Tenants[tenant] = shardSize
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ringCfg := cfg.Ingester.LifecyclerConfig.RingConfig
+	client, desc, closeKV, err := readIngestersRing(ctx, ringCfg.KVStore, &cfg.MemberlistKV)
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to read the ingesters ring: %v\n", err)
+		return 2
+	}
+	defer closeKV()
+
+	report, err := ring.SimulateFailure(ringCfg, ingester.RingKey, client, ring.NewDefaultReplicationStrategy(), desc, sim)
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to simulate the failure: %v\n", err)
+		return 2
+	}
+
+	if output == "json" {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintf(stderr, "failed to encode the report: %v\n", err)
+			return 2
+		}
+	} else {
+		printFailureSimulationReport(stdout, report)
+	}
+
+	if report.Affected() {
+		return 1
+	}
+	return 0
+}
+
+func parseTenantShardSize(value string, cfg *cortex.Config) (string, int, error) {
+	tenant, size, found := strings.Cut(value, ":")
+	if !found {
+		if cfg.Distributor.ShardingStrategy != util.ShardingStrategyShuffle {
+			return tenant, 0, nil
+		}
+		return tenant, cfg.LimitsConfig.IngestionTenantShardSize, nil
+	}
+
+	shardSize, err := strconv.Atoi(size)
+	if err != nil || shardSize < 0 {
+		return "", 0, fmt.Errorf("invalid shard size of tenant %s: must be a non-negative integer", tenant)
+	}
+	return tenant, shardSize, nil
+}
+
+// readIngestersRing returns the ingesters ring stored in the KV store, along with the client used
+// to read it. When the ring is stored in memberlist, a memberlist node joins the cluster to receive
+// its state, and leaves it when the returned function is called.
+func readIngestersRing(ctx context.Context, kvCfg kv.Config, memberlistCfg *memberlist.KVConfig) (kv.Client, *ring.Desc, func(), error) {
+	logger := log.NewNopLogger()
+
+	var memberlistKV *memberlist.KV
+	closeKV := func() {
+		if memberlistKV != nil {
+			_ = services.StopAndAwaitTerminated(context.Background(), memberlistKV)
+		}
+	}
+
+	kvCfg.MemberlistKV = func() (*memberlist.KV, error) {
+		cfg := *memberlistCfg
+		cfg.Codecs = []codec.Codec{ring.GetCodec()}
+		// Don't conflict with a Cortex process running on the same host.
+		cfg.TCPTransport.BindPort = 0
+
+		memberlistKV = memberlist.NewKV(cfg, logger, dns.NewProvider(logger, nil, dns.GolangResolverType), nil)
+		if err := services.StartAndAwaitRunning(ctx, memberlistKV); err != nil {
+			return nil, err
+		}
+		return memberlistKV, nil
+	}
+	kvCfg.RaftKV = func() (*raft.KV, error) {
+		return nil, errors.New("the raft KV store is not supported, use the /distributor/failure_simulation endpoint instead")
+	}
+
+	client, err := kv.NewClient(kvCfg, ring.GetCodec(), nil, logger)
+	if err != nil {
+		closeKV()
+		return nil, nil, nil, err
+	}
+
+	value, err := client.Get(ctx, ingester.RingKey)
+	if err != nil {
+		closeKV()
+		return nil, nil, nil, err
+	}
+	desc, ok := value.(*ring.Desc)
+	if !ok || desc == nil || len(desc.Ingesters) == 0 {
+		closeKV()
+		return nil, nil, nil, errors.New("the ring is empty")
+	}
+	return client, desc, closeKV, nil
+}
+
+func printFailureSimulationReport(w io.Writer, report *ring.FailureSimulationReport) {
+	fmt.Fprintf(w, "Failed instances: %s\n\n", strings.Join(report.FailedInstances, ", "))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TENANT\tSHARD SIZE\tINSTANCES\tFAILED INSTANCES\tWRITES\tREADS")
+	for _, t := range report.Tenants {
+		writes := "ok"
+		if !t.WritesAvailable {
+			writes = fmt.Sprintf("failing for %.2f%% of series", t.WritesFailingPercent)
+		}
+
+		reads := string(t.Reads)
+		if t.ReadsError != "" {
+			reads = fmt.Sprintf("%s (%s)", reads, t.ReadsError)
+		}
+
+		failed := strings.Join(t.FailedInstances, ",")
+		if failed == "" {
+			failed = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\t%s\n", t.Tenant, t.ShardSize, t.Instances, failed, writes, reads)
+	}
+	_ = tw.Flush()
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexproject/cortex/pkg/ingester"
+	"github.com/cortexproject/cortex/pkg/ring"
+	"github.com/cortexproject/cortex/pkg/ring/kv"
+)
+
+func TestRunRingSimulate(t *testing.T) {
+	client, err := kv.NewClient(kv.Config{Store: "inmemory", Prefix: "collectors/"}, ring.GetCodec(), nil, log.NewNopLogger())
+	require.NoError(t, err)
+
+	// 6 instances across 3 zones.
+	desc := ring.NewDesc()
+	for i := range 6 {
+		id := fmt.Sprintf("ingester-%d", i)
+		zone := fmt.Sprintf("zone-%d", i%3)
+		desc.AddIngester(id, id, zone, []uint32{uint32(i) * 100, uint32(i)*100 + 50}, ring.ACTIVE, time.Now())
+	}
+	require.NoError(t, client.CAS(context.Background(), ingester.RingKey, func(any) (any, bool, error) {
+		return desc, true, nil
+	}))
+
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte(`
+distributor:
+  sharding_strategy: shuffle-sharding
+limits:
+  ingestion_tenant_shard_size: 3
+ingester:
+  lifecycler:
+    ring:
+      kvstore:
+        store: inmemory
+      replication_factor: 3
+      zone_awareness_enabled: true
+`), 0644))
+
+	// A single failed zone is tolerated.
+	var stdout, stderr bytes.Buffer
+	code := runRingSimulate([]string{"-config.file=" + configFile, "-fail-zones=zone-0", "-tenants=user-1,user-2:0"}, &stdout, &stderr)
+	assert.Equal(t, 0, code, stderr.String())
+	assert.Contains(t, stdout.String(), "Failed instances: ingester-0, ingester-3\n")
+	assert.Regexp(t, `user-1 +3 +3 +ingester-[03] +ok +complete\n`, stdout.String())
+	assert.Regexp(t, `user-2 +0 +6 +ingester-0,ingester-3 +ok +complete\n`, stdout.String())
+
+	// Two failed zones are not.
+	stdout.Reset()
+	code = runRingSimulate([]string{"-config.file=" + configFile, "-fail-zones=zone-0,zone-1", "-tenants=user-1", "-output=json"}, &stdout, &stderr)
+	assert.Equal(t, 1, code, stderr.String())
+
+	report := ring.FailureSimulationReport{}
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &report))
+	require.Len(t, report.Tenants, 1)
+	assert.False(t, report.Tenants[0].WritesAvailable)
+	assert.Equal(t, ring.ReadsFailed, report.Tenants[0].Reads)
+
+	stderr.Reset()
+	code = runRingSimulate([]string{"-config.file=" + configFile, "-fail-instances=unknown", "-tenants=user-1"}, &stdout, &stderr)
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr.String(), "instance unknown not found in the ring")
+
+	stderr.Reset()
+	code = runRingSimulate([]string{"-config.file=" + configFile, "-tenants=user-1"}, &stdout, &stderr)
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr.String(), "Usage: cortex ring-simulate")
+}
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/golang/snappy"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	remoteapi "github.com/prometheus/client_golang/exp/api/remote"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/histogram"
@@ -801,6 +802,92 @@ func TestIngest_StartAndCreatedTimestampIgnoredWhenDisabled(t *testing.T) {
 	})
 }
 
+func TestIngest_RemoteWriteV2RoundTrip(t *testing.T) {
+	const blockRangePeriod = 5 * time.Second
+
+	s, err := e2e.NewScenario(networkName)
+	require.NoError(t, err)
+	defer s.Close()
+
+	consul := e2edb.NewConsulWithName("consul")
+	require.NoError(t, s.StartAndWaitReady(consul))
+
+	flags := mergeFlags(
+		AlertmanagerLocalFlags(),
+		map[string]string{
+			"-store.engine":                                    blocksStorageEngine,
+			"-blocks-storage.backend":                          "filesystem",
+			"-blocks-storage.tsdb.head-compaction-interval":    "4m",
+			"-blocks-storage.bucket-store.sync-interval":       "15m",
+			"-blocks-storage.bucket-store.index-cache.backend": tsdb.IndexCacheBackendInMemory,
+			"-blocks-storage.tsdb.block-ranges-period":         blockRangePeriod.String(),
+			"-blocks-storage.tsdb.ship-interval":               "1s",
+			"-blocks-storage.tsdb.retention-period":            ((blockRangePeriod * 2) - 1).String(),
+			"-ring.store":                                      "consul",
+			"-consul.hostname":                                 consul.NetworkHTTPEndpoint(),
+			"-distributor.replication-factor":                  "1",
+			"-distributor.remote-writev2-enabled":              "true",
+			"-distributor.enable-start-timestamp":              "true",
+			"-distributor.enable-series-metadata":              "true",
+			"-store-gateway.sharding-enabled":                  "false",
+			"-alertmanager.web.external-url":                   "http://localhost/alertmanager",
+		},
+	)
+
+	require.NoError(t, writeFileToSharedDir(s, "alertmanager_configs", []byte{}))
+	path := path.Join(s.SharedDir(), "cortex-1")
+	flags = mergeFlags(flags, map[string]string{"-blocks-storage.filesystem.dir": path})
+
+	cortex := e2ecortex.NewSingleBinary("cortex", flags, "")
+	require.NoError(t, s.StartAndWaitReady(cortex))
+	require.NoError(t, cortex.WaitSumMetrics(e2e.Equals(float64(512)), "cortex_ring_tokens_total"))
+
+	c, err := e2ecortex.NewClient(cortex.HTTPEndpoint(), cortex.HTTPEndpoint(), "", "", "user-1")
+	require.NoError(t, err)
+
+	sampleTs := time.Now().Truncate(time.Second)
+	startTs := sampleTs.Add(-2 * time.Second)
+
+	// Push a counter with its start timestamp and per-series metadata.
+	symbols := []string{"", "__name__", "test_round_trip_total", "job", "test", "requests", "Total number of requests."}
+	series := []writev2.TimeSeries{{
+		LabelsRefs: []uint32{1, 2, 3, 4},
+		Samples: []writev2.Sample{{
+			Value:          42,
+			Timestamp:      e2e.TimeToMilliseconds(sampleTs),
+			StartTimestamp: e2e.TimeToMilliseconds(startTs),
+		}},
+		Metadata: writev2.Metadata{
+			Type:    writev2.Metadata_METRIC_TYPE_COUNTER,
+			UnitRef: 5,
+			HelpRef: 6,
+		},
+	}}
+
+	writeStats, err := c.PushV2(symbols, series)
+	require.NoError(t, err)
+	testPushHeader(t, writeStats, 1, 0, 0)
+
+	// The start timestamp is read back as a zero sample by the remote read.
+	res, err := c.RemoteRead([]*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, labels.MetricName, "test_round_trip_total")}, startTs, sampleTs, time.Second)
+	require.NoError(t, err)
+	require.Len(t, res.Results, 1)
+	require.Len(t, res.Results[0].Timeseries, 1)
+	assert.Equal(t, []prompb.Label{{Name: "__name__", Value: "test_round_trip_total"}, {Name: "job", Value: "test"}}, res.Results[0].Timeseries[0].Labels)
+	assert.Equal(t, []prompb.Sample{
+		{Value: 0, Timestamp: e2e.TimeToMilliseconds(startTs)},
+		{Value: 42, Timestamp: e2e.TimeToMilliseconds(sampleTs)},
+	}, res.Results[0].Timeseries[0].Samples)
+
+	// The per-series metadata is returned by the metadata API.
+	metadata, err := c.Metadata("test_round_trip_total", "")
+	require.NoError(t, err)
+	require.Len(t, metadata["test_round_trip_total"], 1)
+	assert.Equal(t, promv1.MetricType("counter"), metadata["test_round_trip_total"][0].Type)
+	assert.Equal(t, "requests", metadata["test_round_trip_total"][0].Unit)
+	assert.Equal(t, "Total number of requests.", metadata["test_round_trip_total"][0].Help)
+}
+
 func TestExemplar(t *testing.T) {
 	s, err := e2e.NewScenario(networkName)
 	require.NoError(t, err)
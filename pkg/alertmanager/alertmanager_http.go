@@ -81,6 +81,21 @@ func (am *MultitenantAlertmanager) RingHandler(w http.ResponseWriter, req *http.
 	am.ring.ServeHTTP(w, req)
 }
 
+// RingAdminHandler serves the JSON ring admin API of the alertmanager ring.
+func (am *MultitenantAlertmanager) RingAdminHandler(w http.ResponseWriter, req *http.Request) {
+	if !am.cfg.ShardingEnabled {
+		http.Error(w, "Alertmanager has no ring because sharding is disabled.", http.StatusNotFound)
+		return
+	}
+
+	if am.State() != services.Running {
+		http.Error(w, "Alertmanager is not running yet.", http.StatusServiceUnavailable)
+		return
+	}
+
+	am.ring.AdminHandler(w, req)
+}
+
 // GetStatusHandler returns the status handler for this multi-tenant
 // alertmanager.
 func (am *MultitenantAlertmanager) GetStatusHandler() StatusHandler {
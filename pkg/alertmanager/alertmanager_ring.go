@@ -78,6 +78,8 @@ type RingConfig struct {
 	DetailedMetricsEnabled     bool          `yaml:"detailed_metrics_enabled"`
 	DisableReplicaSetExtension bool          `yaml:"disable_replica_set_extension"`
 
+	TokensGenerator ring.TokensGeneratorConfig `yaml:",inline"`
+
 	FinalSleep                      time.Duration `yaml:"final_sleep"`
 	WaitInstanceStateTimeout        time.Duration `yaml:"wait_instance_state_timeout"`
 	KeepInstanceInTheRingOnShutdown bool          `yaml:"keep_instance_in_the_ring_on_shutdown"`
@@ -116,6 +118,7 @@ func (cfg *RingConfig) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&cfg.ReplicationFactor, rfprefix+"replication-factor", 3, "The replication factor to use when sharding the alertmanager.")
 	f.BoolVar(&cfg.ZoneAwarenessEnabled, rfprefix+"zone-awareness-enabled", false, "True to enable zone-awareness and replicate alerts across different availability zones.")
 	f.StringVar(&cfg.TokensFilePath, rfprefix+"tokens-file-path", "", "File path where tokens are stored. If empty, tokens are not stored at shutdown and restored at startup.")
+	cfg.TokensGenerator.RegisterFlagsWithPrefix(rfprefix, f)
 	f.BoolVar(&cfg.DetailedMetricsEnabled, rfprefix+"detailed-metrics-enabled", true, "Set to true to enable ring detailed metrics. These metrics provide detailed information, such as token count and ownership per tenant. Disabling them can significantly decrease the number of metrics emitted.")
 	f.BoolVar(&cfg.DisableReplicaSetExtension, rfprefix+"disable-replica-set-extension", false, "Disable extending the replica set when instances are unhealthy. This limits blast radius during config corruption incidents but reduces availability during normal failures.")
 
@@ -151,6 +154,9 @@ func (cfg *RingConfig) ToLifecyclerConfig(logger log.Logger) (ring.BasicLifecycl
 		TokensObservePeriod:             0,
 		Zone:                            cfg.InstanceZone,
 		NumTokens:                       RingNumTokens,
+		TokensGeneratorStrategy:         cfg.TokensGenerator.Strategy,
+		TokensMigrationInterval:         cfg.TokensGenerator.MigrationInterval,
+		TokensMigrationStep:             cfg.TokensGenerator.MigrationStep,
 		FinalSleep:                      cfg.FinalSleep,
 		KeepInstanceInTheRingOnShutdown: cfg.KeepInstanceInTheRingOnShutdown,
 	}, nil
@@ -158,6 +158,9 @@ func (cfg *MultitenantAlertmanagerConfig) Validate(storageCfg alertstore.Config)
 		if cfg.ShardingRing.ZoneAwarenessEnabled && cfg.ShardingRing.InstanceZone == "" {
 			return errZoneAwarenessEnabledWithoutZoneInfo
 		}
+		if err := cfg.ShardingRing.TokensGenerator.Validate(); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -242,6 +242,7 @@ func (a *API) RegisterAlertmanager(am *alertmanager.MultitenantAlertmanager, tar
 	a.RegisterRoute("/multitenant_alertmanager/status", am.GetStatusHandler(), false, "GET")
 	a.RegisterRoute("/multitenant_alertmanager/configs", http.HandlerFunc(am.ListAllConfigs), false, "GET")
 	a.RegisterRoute("/multitenant_alertmanager/ring", http.HandlerFunc(am.RingHandler), false, "GET", "POST")
+	a.RegisterRoute("/multitenant_alertmanager/ring/admin", http.HandlerFunc(am.RingAdminHandler), false, "GET", "POST")
 	a.RegisterRoute("/multitenant_alertmanager/delete_tenant_config", http.HandlerFunc(am.DeleteUserConfig), true, "POST")
 
 	// UI components lead to a large number of routes to support, utilize a path prefix instead
@@ -303,6 +304,12 @@ func (a *API) RegisterDistributor(d *distributor.Distributor, pushConfig distrib
 	a.RegisterRoute("/distributor/ring", d, false, "GET", "POST")
 	a.RegisterRoute("/distributor/all_user_stats", http.HandlerFunc(d.AllUserStatsHandler), false, "GET")
 	a.RegisterRoute("/distributor/ha_tracker", d.HATracker, false, "GET")
+	a.RegisterRoute("/distributor/ha_tracker/pin", http.HandlerFunc(d.HATracker.PinReplicaHandler), true, "POST")
+	a.RegisterRoute("/distributor/ha_tracker/unpin", http.HandlerFunc(d.HATracker.UnpinReplicaHandler), true, "POST")
+	a.RegisterRoute("/distributor/ha_tracker/failover", http.HandlerFunc(d.HATracker.FailoverHandler), true, "POST")
+	a.RegisterRoute("/distributor/tenant/{id}/top_series", http.HandlerFunc(d.TopSeriesHandler), false, "GET")
+	a.RegisterRoute("/distributor/tenant/{id}/discarded_series", http.HandlerFunc(d.DiscardedSeriesHandler), false, "GET")
+	a.RegisterRoute("/distributor/failure_simulation", http.HandlerFunc(d.FailureSimulationHandler), false, "GET")
 
 	// Legacy Routes
 	a.RegisterRoute(path.Join(a.cfg.LegacyHTTPPrefix, "/push"), push.Handler(pushConfig.RemoteWriteV2Enabled, pushConfig.AcceptUnknownRemoteWriteContentType, pushConfig.MaxRecvMsgSize, overrides, a.sourceIPs, a.cfg.wrapDistributorPush(d), requestTotal), true, "POST")
@@ -319,6 +326,8 @@ type Ingester interface {
 	RenewTokenHandler(http.ResponseWriter, *http.Request)
 	AllUserStatsHandler(http.ResponseWriter, *http.Request)
 	ModeHandler(http.ResponseWriter, *http.Request)
+	TopSeriesHandler(http.ResponseWriter, *http.Request)
+	DiscardedSeriesHandler(http.ResponseWriter, *http.Request)
 	Push(context.Context, *cortexpb.WriteRequest) (*cortexpb.WriteResponse, error)
 }
 
@@ -338,6 +347,8 @@ func (a *API) RegisterIngester(i Ingester, pushConfig distributor.Config, overri
 	a.RegisterRoute("/ingester/renewTokens", http.HandlerFunc(i.RenewTokenHandler), false, "GET", "POST")
 	a.RegisterRoute("/ingester/all_user_stats", http.HandlerFunc(i.AllUserStatsHandler), false, "GET")
 	a.RegisterRoute("/ingester/mode", http.HandlerFunc(i.ModeHandler), false, "GET", "POST")
+	a.RegisterRoute("/ingester/tenant/{id}/top_series", http.HandlerFunc(i.TopSeriesHandler), false, "GET")
+	a.RegisterRoute("/ingester/tenant/{id}/discarded_series", http.HandlerFunc(i.DiscardedSeriesHandler), false, "GET")
 	a.RegisterRoute("/ingester/push", push.Handler(pushConfig.RemoteWriteV2Enabled, pushConfig.AcceptUnknownRemoteWriteContentType, pushConfig.MaxRecvMsgSize, overrides, a.sourceIPs, i.Push, nil), true, "POST") // For testing and debugging.
 
 	// Legacy Routes
@@ -355,6 +366,7 @@ func (a *API) RegisterTenantDeletion(api *purger.TenantDeletionAPI) {
 func (a *API) RegisterRuler(r *ruler.Ruler) {
 	a.indexPage.AddLink(SectionAdminEndpoints, "/ruler/ring", "Ruler Ring Status")
 	a.RegisterRoute("/ruler/ring", r, false, "GET", "POST")
+	a.RegisterRoute("/ruler/ring/admin", http.HandlerFunc(r.RingAdminHandler), false, "GET", "POST")
 
 	// Administrative API, uses authentication to inform which user's configuration to delete.
 	a.RegisterRoute("/ruler/delete_tenant_config", http.HandlerFunc(r.DeleteTenantConfiguration), true, "POST")
@@ -407,29 +419,40 @@ func (a *API) RegisterOverrides(o *overrides.API) {
 func (a *API) RegisterRing(r *ring.Ring) {
 	a.indexPage.AddLink(SectionAdminEndpoints, "/ingester/ring", "Ingester Ring Status")
 	a.RegisterRoute("/ingester/ring", r, false, "GET", "POST")
+	a.RegisterRoute("/ingester/ring/admin", http.HandlerFunc(r.AdminHandler), false, "GET", "POST")
 
 	// Legacy Route
 	a.RegisterRoute("/ring", r, false, "GET", "POST")
 }
 
+// RegisterPartitionRing registers the ring UI page associated with the ingesters partition ring.
+func (a *API) RegisterPartitionRing(r *ring.PartitionRing) {
+	a.indexPage.AddLink(SectionAdminEndpoints, "/ingester/partition-ring", "Ingester Partition Ring Status")
+	a.RegisterRoute("/ingester/partition-ring", r, false, "GET")
+}
+
 // RegisterStoreGateway registers the ring UI page associated with the store-gateway.
 func (a *API) RegisterStoreGateway(s *storegateway.StoreGateway) {
 	storegatewaypb.RegisterStoreGatewayServer(a.server.GRPC, s)
 
 	a.indexPage.AddLink(SectionAdminEndpoints, "/store-gateway/ring", "Store Gateway Ring")
 	a.RegisterRoute("/store-gateway/ring", http.HandlerFunc(s.RingHandler), false, "GET", "POST")
+	a.RegisterRoute("/store-gateway/ring/admin", http.HandlerFunc(s.RingAdminHandler), false, "GET", "POST")
 }
 
 // RegisterCompactor registers the ring UI page associated with the compactor.
 func (a *API) RegisterCompactor(c *compactor.Compactor) {
 	a.indexPage.AddLink(SectionAdminEndpoints, "/compactor/ring", "Compactor Ring Status")
 	a.RegisterRoute("/compactor/ring", http.HandlerFunc(c.RingHandler), false, "GET", "POST")
+	a.RegisterRoute("/compactor/ring/admin", http.HandlerFunc(c.RingAdminHandler), false, "GET", "POST")
 }
 
-// RegisterParquetConverter registers the ring UI page associated with the parquet-converter.
+// RegisterParquetConverter registers the ring UI page and the conversion status API associated with the parquet-converter.
 func (a *API) RegisterParquetConverter(c *parquetconverter.Converter) {
 	a.indexPage.AddLink(SectionAdminEndpoints, "/parquet-converter/ring", "Parquet Converter Ring Status")
 	a.RegisterRoute("/parquet-converter/ring", http.HandlerFunc(c.RingHandler), false, "GET", "POST")
+	a.RegisterRoute("/parquet-converter/ring/admin", http.HandlerFunc(c.RingAdminHandler), false, "GET", "POST")
+	a.RegisterRoute("/parquet-converter/status", http.HandlerFunc(c.TenantStatusHandler), true, "GET")
 }
 
 type Distributor interface {
@@ -518,3 +541,8 @@ func (a *API) RegisterMemberlistKV(handler http.Handler) {
 	a.indexPage.AddLink(SectionAdminEndpoints, "/memberlist", "Memberlist Status")
 	a.RegisterRoute("/memberlist", handler, false, "GET")
 }
+
+func (a *API) RegisterRaftKV(handler http.Handler) {
+	a.indexPage.AddLink(SectionAdminEndpoints, "/raft-kv", "Raft KV Status")
+	a.RegisterRoute("/raft-kv", handler, false, "GET")
+}
@@ -18,6 +18,8 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/sony/gobreaker"
 	"github.com/thanos-io/thanos/pkg/discovery/dns"
+
+	"github.com/cortexproject/cortex/pkg/util/discovery"
 )
 
 // MemcachedClient interface exists for mocking memcacheClient.
@@ -43,7 +45,7 @@ type memcachedClient struct {
 	service  string
 
 	addresses []string
-	provider  *dns.Provider
+	provider  *discovery.Provider
 
 	cbs        map[ /*address*/ string]*gobreaker.CircuitBreaker
 	cbFailures uint
@@ -80,7 +82,7 @@ type MemcachedClientConfig struct {
 func (cfg *MemcachedClientConfig) RegisterFlagsWithPrefix(prefix, description string, f *flag.FlagSet) {
 	f.StringVar(&cfg.Host, prefix+"memcached.hostname", "", description+"Hostname for memcached service to use. If empty and if addresses is unset, no memcached will be used.")
 	f.StringVar(&cfg.Service, prefix+"memcached.service", "memcached", description+"SRV service used to discover memcache servers.")
-	f.StringVar(&cfg.Addresses, prefix+"memcached.addresses", "", description+"Comma separated addresses list in DNS Service Discovery format: https://cortexmetrics.io/docs/configuration/arguments/#dns-service-discovery")
+	f.StringVar(&cfg.Addresses, prefix+"memcached.addresses", "", description+"Comma separated addresses list in DNS, file or HTTP Service Discovery format: https://cortexmetrics.io/docs/configuration/arguments/#dns-service-discovery")
 	f.IntVar(&cfg.MaxIdleConns, prefix+"memcached.max-idle-conns", 16, description+"Maximum number of idle connections in pool.")
 	f.DurationVar(&cfg.Timeout, prefix+"memcached.timeout", 100*time.Millisecond, description+"Maximum time to wait before giving up on memcached requests.")
 	f.DurationVar(&cfg.UpdateInterval, prefix+"memcached.update-interval", 1*time.Minute, description+"Period with which to poll DNS for memcache servers.")
@@ -116,7 +118,7 @@ func NewMemcachedClient(cfg MemcachedClientConfig, name string, r prometheus.Reg
 		hostname:    cfg.Host,
 		service:     cfg.Service,
 		logger:      logger,
-		provider:    dns.NewProvider(logger, dnsProviderRegisterer, dns.GolangResolverType),
+		provider:    discovery.NewProvider(logger, dnsProviderRegisterer, dns.GolangResolverType),
 		cbs:         make(map[string]*gobreaker.CircuitBreaker),
 		cbFailures:  cfg.CBFailures,
 		cbInterval:  cfg.CBInterval,
@@ -190,6 +192,7 @@ func (c *memcachedClient) dialViaCircuitBreaker(_ context.Context, network, addr
 func (c *memcachedClient) Stop() {
 	close(c.quit)
 	c.wait.Wait()
+	_ = c.provider.Close()
 }
 
 func (c *memcachedClient) Set(item *memcache.Item) error {
@@ -224,6 +227,12 @@ func (c *memcachedClient) updateLoop(updateInterval time.Duration) {
 			if err != nil {
 				level.Warn(c.logger).Log("msg", "error updating memcache servers", "err", err)
 			}
+		case <-c.provider.Changes():
+			// A service discovery file changed, no need to wait for the next update.
+			err := c.updateMemcacheServers()
+			if err != nil {
+				level.Warn(c.logger).Log("msg", "error updating memcache servers", "err", err)
+			}
 		case <-c.quit:
 			ticker.Stop()
 			return
@@ -76,6 +76,7 @@ type BlocksCleaner struct {
 	tenantBlocks                      *prometheus.GaugeVec
 	tenantParquetBlocks               *prometheus.GaugeVec
 	tenantParquetUnConvertedBlocks    *prometheus.GaugeVec
+	tenantParquetIncompatibleBlocks   *prometheus.GaugeVec
 	tenantBlocksMarkedForDelete       *prometheus.GaugeVec
 	tenantBlocksMarkedForNoCompaction *prometheus.GaugeVec
 	tenantPartialBlocks               *prometheus.GaugeVec
@@ -167,6 +168,10 @@ func NewBlocksCleaner(
 			Name: "cortex_bucket_parquet_unconverted_blocks_count",
 			Help: "Total number of unconverted parquet blocks in the bucket. Blocks marked for deletion are included.",
 		}, commonLabels),
+		tenantParquetIncompatibleBlocks: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cortex_bucket_parquet_incompatible_blocks_count",
+			Help: "Total number of blocks in the bucket that can't be converted to parquet. Blocks marked for deletion are included.",
+		}, commonLabels),
 		tenantBlocksMarkedForDelete: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
 			Name: "cortex_bucket_blocks_marked_for_deletion_count",
 			Help: "Total number of blocks marked for deletion in the bucket.",
@@ -443,6 +448,7 @@ func (c *BlocksCleaner) deleteUserMetrics(userID string) {
 	c.tenantBlocks.DeleteLabelValues(userID)
 	c.tenantParquetBlocks.DeleteLabelValues(userID)
 	c.tenantParquetUnConvertedBlocks.DeleteLabelValues(userID)
+	c.tenantParquetIncompatibleBlocks.DeleteLabelValues(userID)
 	c.tenantBlocksMarkedForDelete.DeleteLabelValues(userID)
 	c.tenantBlocksMarkedForNoCompaction.DeleteLabelValues(userID)
 	c.tenantPartialBlocks.DeleteLabelValues(userID)
@@ -784,12 +790,19 @@ func (c *BlocksCleaner) updateBucketMetrics(userID string, parquetEnabled bool,
 	if parquetEnabled {
 		c.tenantParquetBlocks.WithLabelValues(userID).Set(float64(len(idx.ParquetBlocks())))
 		remainingBlocksToConvert := 0
+		incompatibleBlocks := 0
 		for _, b := range idx.NonParquetBlocks() {
+			// Incompatible blocks are never converted, so they are not part of the conversion backlog.
+			if b.IsParquetIncompatible() {
+				incompatibleBlocks++
+				continue
+			}
 			if cortex_parquet.ShouldConvertBlockToParquet(b.MinTime, b.MaxTime, c.cfg.BlockRanges) {
 				remainingBlocksToConvert++
 			}
 		}
 		c.tenantParquetUnConvertedBlocks.WithLabelValues(userID).Set(float64(remainingBlocksToConvert))
+		c.tenantParquetIncompatibleBlocks.WithLabelValues(userID).Set(float64(incompatibleBlocks))
 	}
 }
 
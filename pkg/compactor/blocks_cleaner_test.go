@@ -1209,6 +1209,12 @@ func TestBlocksCleaner_ParquetMetrics(t *testing.T) {
 				MaxTime: now.UnixMilli(),
 				Parquet: nil,
 			},
+			{
+				ID:      ulid.MustNew(ulid.Now(), rand.Reader),
+				MinTime: now.Add(-5 * time.Hour).UnixMilli(),
+				MaxTime: now.UnixMilli(),
+				Parquet: &parquet.ConverterMarkMeta{Incompatible: true},
+			},
 		},
 	}
 
@@ -1227,6 +1233,12 @@ func TestBlocksCleaner_ParquetMetrics(t *testing.T) {
 		# TYPE cortex_bucket_parquet_unconverted_blocks_count gauge
 		cortex_bucket_parquet_unconverted_blocks_count{user="user1"} 2
 	`)))
+
+	require.NoError(t, prom_testutil.CollectAndCompare(cleaner.tenantParquetIncompatibleBlocks, strings.NewReader(`
+		# HELP cortex_bucket_parquet_incompatible_blocks_count Total number of blocks in the bucket that can't be converted to parquet. Blocks marked for deletion are included.
+		# TYPE cortex_bucket_parquet_incompatible_blocks_count gauge
+		cortex_bucket_parquet_incompatible_blocks_count{user="user1"} 1
+	`)))
 }
 
 func TestBlocksCleaner_EmitUserMetrics(t *testing.T) {
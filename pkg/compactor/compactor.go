@@ -376,6 +376,12 @@ func (cfg *Config) Validate(limits validation.Limits) error {
 		}
 	}
 
+	if cfg.ShardingEnabled {
+		if err := cfg.ShardingRing.TokensGenerator.Validate(); err != nil {
+			return err
+		}
+	}
+
 	// Make sure a valid compaction strategy is being used
 	if !slices.Contains(supportedCompactionStrategies, cfg.CompactionStrategy) {
 		return errInvalidCompactionStrategy
@@ -1090,6 +1096,10 @@ func (c *Compactor) compactUser(ctx context.Context, userID string) error {
 
 	currentCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
+	compactionLifecycleCallback := c.compactionLifecycleCallbackFactory(currentCtx, bucket, ulogger, c.compactorCfg.MetaSyncConcurrency, c.compactDirForUser(userID), userID, c.compactorMetrics)
+	if c.storageCfg.TSDB.PersistExemplars {
+		compactionLifecycleCallback = NewExemplarsCompactionLifecycleCallback(compactionLifecycleCallback, bucket, c.compactDirForUser(userID))
+	}
 	compactor, err := compact.NewBucketCompactorWithCheckerAndCallback(
 		ulogger,
 		syncer,
@@ -1097,7 +1107,7 @@ func (c *Compactor) compactUser(ctx context.Context, userID string) error {
 		c.blocksPlannerFactory(currentCtx, bucket, ulogger, c.compactorCfg, noCompactMarkerFilter, c.ringLifecycler, userID, c.blockVisitMarkerReadFailed, c.blockVisitMarkerWriteFailed, c.compactorMetrics, ignoreDeletionMarkFilter),
 		c.blocksCompactor,
 		c.blockDeletableCheckerFactory(currentCtx, bucket, ulogger),
-		c.compactionLifecycleCallbackFactory(currentCtx, bucket, ulogger, c.compactorCfg.MetaSyncConcurrency, c.compactDirForUser(userID), userID, c.compactorMetrics),
+		compactionLifecycleCallback,
 		c.compactDirForUser(userID),
 		bucket,
 		c.compactorCfg.CompactionConcurrency,
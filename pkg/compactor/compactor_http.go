@@ -51,3 +51,18 @@ func (c *Compactor) RingHandler(w http.ResponseWriter, req *http.Request) {
 
 	c.ring.ServeHTTP(w, req)
 }
+
+// RingAdminHandler serves the JSON ring admin API of the compactor ring.
+func (c *Compactor) RingAdminHandler(w http.ResponseWriter, req *http.Request) {
+	if !c.compactorCfg.ShardingEnabled {
+		http.Error(w, "Compactor has no ring because sharding is disabled.", http.StatusNotFound)
+		return
+	}
+
+	if c.State() != services.Running {
+		http.Error(w, "Compactor is not running yet.", http.StatusServiceUnavailable)
+		return
+	}
+
+	c.ring.AdminHandler(w, req)
+}
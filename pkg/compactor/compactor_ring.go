@@ -24,6 +24,8 @@ type RingConfig struct {
 	AutoForgetDelay        time.Duration `yaml:"auto_forget_delay"`
 	DetailedMetricsEnabled bool          `yaml:"detailed_metrics_enabled"`
 
+	TokensGenerator ring.TokensGeneratorConfig `yaml:",inline"`
+
 	// Wait ring stability.
 	WaitStabilityMinDuration time.Duration `yaml:"wait_stability_min_duration"`
 	WaitStabilityMaxDuration time.Duration `yaml:"wait_stability_max_duration"`
@@ -56,6 +58,7 @@ func (cfg *RingConfig) RegisterFlags(f *flag.FlagSet) {
 	cfg.KVStore.RegisterFlagsWithPrefix("compactor.ring.", "collectors/", f)
 	f.DurationVar(&cfg.HeartbeatPeriod, "compactor.ring.heartbeat-period", 5*time.Second, "Period at which to heartbeat to the ring. 0 = disabled.")
 	f.DurationVar(&cfg.HeartbeatTimeout, "compactor.ring.heartbeat-timeout", time.Minute, "The heartbeat timeout after which compactors are considered unhealthy within the ring. 0 = never (timeout disabled).")
+	cfg.TokensGenerator.RegisterFlagsWithPrefix("compactor.ring.", f)
 	f.BoolVar(&cfg.DetailedMetricsEnabled, "compactor.ring.detailed-metrics-enabled", true, "Set to true to enable ring detailed metrics. These metrics provide detailed information, such as token count and ownership per tenant. Disabling them can significantly decrease the number of metrics emitted.")
 	f.DurationVar(&cfg.AutoForgetDelay, "compactor.auto-forget-delay", 2*cfg.HeartbeatTimeout, "Time since last heartbeat before compactor will be removed from ring. 0 to disable")
 
@@ -108,6 +111,9 @@ func (cfg *RingConfig) ToLifecyclerConfig() ring.LifecyclerConfig {
 	lc.MinReadyDuration = 0
 	lc.FinalSleep = 0
 	lc.TokensFilePath = cfg.TokensFilePath
+	lc.TokensGeneratorStrategy = cfg.TokensGenerator.Strategy
+	lc.TokensMigrationInterval = cfg.TokensGenerator.MigrationInterval
+	lc.TokensMigrationStep = cfg.TokensGenerator.MigrationStep
 
 	// We use a safe default instead of exposing to config option to the user
 	// in order to simplify the config.
@@ -24,6 +24,7 @@ func TestRingConfig_DefaultConfigToLifecyclerConfig(t *testing.T) {
 	expected.NumTokens = 512
 	expected.MinReadyDuration = 0
 	expected.FinalSleep = 0
+	expected.TokensMigrationStep = 8
 
 	assert.Equal(t, expected, cfg.ToLifecyclerConfig())
 }
@@ -42,6 +43,9 @@ func TestRingConfig_CustomConfigToLifecyclerConfig(t *testing.T) {
 	cfg.InstanceAddr = "1.2.3.4"
 	cfg.ListenPort = 10
 	cfg.TokensFilePath = "testFilePath"
+	cfg.TokensGenerator.Strategy = "minimize-spread"
+	cfg.TokensGenerator.MigrationInterval = time.Minute
+	cfg.TokensGenerator.MigrationStep = 16
 
 	// The lifecycler config should be generated based upon the compactor
 	// ring config
@@ -54,6 +58,9 @@ func TestRingConfig_CustomConfigToLifecyclerConfig(t *testing.T) {
 	expected.Addr = cfg.InstanceAddr
 	expected.ListenPort = cfg.ListenPort
 	expected.TokensFilePath = cfg.TokensFilePath
+	expected.TokensGeneratorStrategy = cfg.TokensGenerator.Strategy
+	expected.TokensMigrationInterval = cfg.TokensGenerator.MigrationInterval
+	expected.TokensMigrationStep = cfg.TokensGenerator.MigrationStep
 
 	// Hardcoded config
 	expected.RingConfig.ReplicationFactor = 1
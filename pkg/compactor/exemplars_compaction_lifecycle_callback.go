@@ -0,0 +1,117 @@
+package compactor
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/oklog/ulid/v2"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/tsdb/index"
+	"github.com/thanos-io/objstore"
+	"github.com/thanos-io/thanos/pkg/block"
+	"github.com/thanos-io/thanos/pkg/block/metadata"
+	"github.com/thanos-io/thanos/pkg/compact"
+
+	cortex_tsdb "github.com/cortexproject/cortex/pkg/storage/tsdb"
+	"github.com/cortexproject/cortex/pkg/util/runutil"
+)
+
+// ExemplarsCompactionLifecycleCallback wraps a compaction lifecycle callback to carry over the
+// exemplars persisted alongside the compacted blocks to the result blocks.
+type ExemplarsCompactionLifecycleCallback struct {
+	compact.CompactionLifecycleCallback
+
+	userBucket objstore.Bucket
+	compactDir string
+}
+
+func NewExemplarsCompactionLifecycleCallback(wrapped compact.CompactionLifecycleCallback, userBucket objstore.Bucket, compactDir string) *ExemplarsCompactionLifecycleCallback {
+	return &ExemplarsCompactionLifecycleCallback{
+		CompactionLifecycleCallback: wrapped,
+		userBucket:                  userBucket,
+		compactDir:                  compactDir,
+	}
+}
+
+func (c *ExemplarsCompactionLifecycleCallback) PostCompactionCallback(ctx context.Context, logger log.Logger, cg *compact.Group, blockID ulid.ULID) error {
+	if err := c.CompactionLifecycleCallback.PostCompactionCallback(ctx, logger, cg, blockID); err != nil {
+		return err
+	}
+
+	// Exemplars are best effort, so a failure doesn't fail the compaction.
+	if err := c.compactExemplars(ctx, logger, filepath.Join(c.compactDir, cg.Key(), blockID.String())); err != nil {
+		level.Warn(logger).Log("msg", "failed to persist exemplars of the compacted block", "block", blockID.String(), "err", err)
+	}
+	return nil
+}
+
+// compactExemplars merges the exemplars of the parents of the block in the input dir, keeping
+// only the ones of the block series and time range, and uploads them alongside the block.
+func (c *ExemplarsCompactionLifecycleCallback) compactExemplars(ctx context.Context, logger log.Logger, blockDir string) error {
+	meta, err := metadata.ReadFromDir(blockDir)
+	if err != nil {
+		return errors.Wrap(err, "read block meta")
+	}
+
+	sets := make([][]exemplar.QueryResult, 0, len(meta.Compaction.Parents))
+	for _, parent := range meta.Compaction.Parents {
+		series, err := cortex_tsdb.ReadBlockExemplars(ctx, logger, c.userBucket, parent.ULID)
+		if err != nil {
+			return err
+		}
+		sets = append(sets, series)
+	}
+
+	merged := cortex_tsdb.MergeExemplars(sets...)
+	if len(merged) == 0 {
+		return nil
+	}
+
+	blockSeries, err := readBlockSeries(ctx, blockDir)
+	if err != nil {
+		return err
+	}
+
+	filtered := cortex_tsdb.FilterExemplars(merged, meta.MinTime, meta.MaxTime-1, func(lbls labels.Labels) bool {
+		_, ok := blockSeries[lbls.String()]
+		return ok
+	})
+	if len(filtered) == 0 {
+		return nil
+	}
+
+	if err := cortex_tsdb.WriteBlockExemplars(blockDir, filtered); err != nil {
+		return err
+	}
+	_, err = cortex_tsdb.UploadBlockExemplars(ctx, logger, c.userBucket, blockDir)
+	return err
+}
+
+// readBlockSeries returns the labels of all the series in the index of the block in the input dir.
+func readBlockSeries(ctx context.Context, blockDir string) (_ map[string]struct{}, err error) {
+	reader, err := index.NewFileReader(filepath.Join(blockDir, block.IndexFilename), index.DecodePostingsRaw)
+	if err != nil {
+		return nil, errors.Wrap(err, "open block index")
+	}
+	defer runutil.CloseWithErrCapture(&err, reader, "close block index")
+
+	name, value := index.AllPostingsKey()
+	postings, err := reader.Postings(ctx, name, value)
+	if err != nil {
+		return nil, errors.Wrap(err, "read block postings")
+	}
+
+	series := map[string]struct{}{}
+	builder := labels.NewScratchBuilder(0)
+	for postings.Next() {
+		if err := reader.Series(postings.At(), &builder, nil); err != nil {
+			return nil, errors.Wrap(err, "read block series")
+		}
+		series[builder.Labels().String()] = struct{}{}
+	}
+	return series, errors.Wrap(postings.Err(), "iterate block postings")
+}
@@ -0,0 +1,82 @@
+package compactor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/oklog/ulid/v2"
+	"github.com/prometheus/common/promslog"
+	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/prometheus/prometheus/tsdb/chunks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+	"github.com/thanos-io/thanos/pkg/block/metadata"
+	"github.com/thanos-io/thanos/pkg/compact"
+
+	cortex_tsdb "github.com/cortexproject/cortex/pkg/storage/tsdb"
+)
+
+func TestExemplarsCompactionLifecycleCallback_PostCompactionCallback(t *testing.T) {
+	ctx := context.Background()
+	bkt := objstore.NewInMemBucket()
+	compactDir := t.TempDir()
+
+	series1 := labels.FromStrings("__name__", "series_1")
+	series2 := labels.FromStrings("__name__", "series_2")
+	series3 := labels.FromStrings("__name__", "series_3")
+
+	// Create the compacted block, made of series 1 and 2 within [0, 100).
+	blockDir, err := tsdb.CreateBlock([]storage.Series{
+		storage.NewListSeries(series1, chunks.GenerateSamples(0, 100)),
+		storage.NewListSeries(series2, chunks.GenerateSamples(0, 100)),
+	}, t.TempDir(), 0, promslog.NewNopLogger())
+	require.NoError(t, err)
+
+	parent1 := ulid.MustNew(1, nil)
+	parent2 := ulid.MustNew(2, nil)
+	meta, err := metadata.ReadFromDir(blockDir)
+	require.NoError(t, err)
+	meta.Compaction.Parents = []tsdb.BlockDesc{{ULID: parent1}, {ULID: parent2}}
+	require.NoError(t, meta.WriteToDir(log.NewNopLogger(), blockDir))
+
+	group, err := compact.NewGroup(log.NewNopLogger(), nil, "group", labels.EmptyLabels(), 0, true, true, nil, nil, nil, nil, nil, nil, nil, nil, metadata.NoneFunc, 1, 1)
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(compactDir, group.Key()), os.ModePerm))
+	require.NoError(t, os.Rename(blockDir, filepath.Join(compactDir, group.Key(), meta.ULID.String())))
+
+	// Persist the exemplars of the parents.
+	for parentID, series := range map[ulid.ULID][]exemplar.QueryResult{
+		parent1: {
+			{SeriesLabels: series1, Exemplars: []exemplar.Exemplar{{Labels: labels.FromStrings("trace_id", "1"), Value: 1, Ts: 10}}},
+			{SeriesLabels: series3, Exemplars: []exemplar.Exemplar{{Labels: labels.FromStrings("trace_id", "2"), Value: 2, Ts: 20}}},
+		},
+		parent2: {
+			{SeriesLabels: series1, Exemplars: []exemplar.Exemplar{{Labels: labels.FromStrings("trace_id", "3"), Value: 3, Ts: 200}}},
+			{SeriesLabels: series2, Exemplars: []exemplar.Exemplar{{Labels: labels.FromStrings("trace_id", "4"), Value: 4, Ts: 40}}},
+		},
+	} {
+		parentDir := filepath.Join(t.TempDir(), parentID.String())
+		require.NoError(t, os.Mkdir(parentDir, os.ModePerm))
+		require.NoError(t, cortex_tsdb.WriteBlockExemplars(parentDir, series))
+		_, err := cortex_tsdb.UploadBlockExemplars(ctx, log.NewNopLogger(), bkt, parentDir)
+		require.NoError(t, err)
+	}
+
+	callback := NewExemplarsCompactionLifecycleCallback(compact.DefaultCompactionLifecycleCallback{}, bkt, compactDir)
+	require.NoError(t, callback.PostCompactionCallback(ctx, log.NewNopLogger(), group, meta.ULID))
+
+	// Only the exemplars of the block series and time range have been kept.
+	series, err := cortex_tsdb.ReadBlockExemplars(ctx, log.NewNopLogger(), bkt, meta.ULID)
+	require.NoError(t, err)
+	assert.Equal(t, []exemplar.QueryResult{
+		{SeriesLabels: series1, Exemplars: []exemplar.Exemplar{{Labels: labels.FromStrings("trace_id", "1"), Value: 1, Ts: 10}}},
+		{SeriesLabels: series2, Exemplars: []exemplar.Exemplar{{Labels: labels.FromStrings("trace_id", "4"), Value: 4, Ts: 40}}},
+	}, series)
+}
@@ -48,6 +48,7 @@ import (
 	querier_worker "github.com/cortexproject/cortex/pkg/querier/worker"
 	"github.com/cortexproject/cortex/pkg/ring"
 	"github.com/cortexproject/cortex/pkg/ring/kv/memberlist"
+	"github.com/cortexproject/cortex/pkg/ring/kv/raft"
 	"github.com/cortexproject/cortex/pkg/ruler"
 	"github.com/cortexproject/cortex/pkg/ruler/rulestore"
 	"github.com/cortexproject/cortex/pkg/scheduler"
@@ -130,6 +131,7 @@ type Config struct {
 	AlertmanagerStorage alertstore.Config                          `yaml:"alertmanager_storage"`
 	RuntimeConfig       runtimeconfig.Config                       `yaml:"runtime_config"`
 	MemberlistKV        memberlist.KVConfig                        `yaml:"memberlist"`
+	RaftKV              raft.KVConfig                              `yaml:"raft_kv"`
 	QueryScheduler      scheduler.Config                           `yaml:"query_scheduler"`
 
 	Tracing tracing.Config `yaml:"tracing"`
@@ -181,6 +183,7 @@ func (c *Config) RegisterFlags(f *flag.FlagSet) {
 	c.AlertmanagerStorage.RegisterFlags(f)
 	c.RuntimeConfig.RegisterFlags(f)
 	c.MemberlistKV.RegisterFlags(f)
+	c.RaftKV.RegisterFlags(f)
 	c.QueryScheduler.RegisterFlags(f)
 	c.Tracing.RegisterFlags(f)
 }
@@ -250,6 +253,9 @@ func (c *Config) Validate(log log.Logger) error {
 	if err := c.Compactor.Validate(c.LimitsConfig); err != nil {
 		return errors.Wrap(err, "invalid compactor config")
 	}
+	if err := c.RaftKV.Validate(); err != nil {
+		return errors.Wrap(err, "invalid raft_kv config")
+	}
 	if err := c.AlertmanagerStorage.Validate(); err != nil {
 		return errors.Wrap(err, "invalid alertmanager storage config")
 	}
@@ -257,7 +263,7 @@ func (c *Config) Validate(log log.Logger) error {
 		return errors.Wrap(err, "invalid alertmanager config")
 	}
 
-	if err := c.Ingester.Validate(c.ResourceMonitor.Resources); err != nil {
+	if err := c.Ingester.Validate(c.ResourceMonitor.Resources, c.LimitsConfig, c.BlocksStorage.TSDB); err != nil {
 		return errors.Wrap(err, "invalid ingester config")
 	}
 
@@ -331,6 +337,7 @@ type Cortex struct {
 	API                      *api.API
 	Server                   *server.Server
 	Ring                     *ring.Ring
+	PartitionRing            *ring.PartitionRing
 	TenantLimits             validation.TenantLimits
 	OverridesConfig          *validation.Overrides
 	Overrides                *overrides.API
@@ -355,10 +362,15 @@ type Cortex struct {
 	Parquetconverter *parquetconverter.Converter
 	StoreGateway     *storegateway.StoreGateway
 	MemberlistKV     *memberlist.KVInitService
+	RaftKV           *raft.KVInitService
 
 	// Queryables that the querier should use to query the long
 	// term storage. It depends on the storage engine used.
 	StoreQueryables []querier.QueryableWithFilter
+
+	// Queryable that the querier should use to query the exemplars
+	// persisted in the long term storage, if enabled.
+	StoreExemplarQueryable prom_storage.ExemplarQueryable
 }
 
 // New makes a new Cortex.
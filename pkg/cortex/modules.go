@@ -52,10 +52,12 @@ import (
 	"github.com/cortexproject/cortex/pkg/ring"
 	"github.com/cortexproject/cortex/pkg/ring/kv/codec"
 	"github.com/cortexproject/cortex/pkg/ring/kv/memberlist"
+	"github.com/cortexproject/cortex/pkg/ring/kv/raft"
 	"github.com/cortexproject/cortex/pkg/ruler"
 	"github.com/cortexproject/cortex/pkg/scheduler"
 	"github.com/cortexproject/cortex/pkg/storage/bucket"
 	"github.com/cortexproject/cortex/pkg/storegateway"
+	"github.com/cortexproject/cortex/pkg/util/discovery"
 	"github.com/cortexproject/cortex/pkg/util/grpcclient"
 	util_log "github.com/cortexproject/cortex/pkg/util/log"
 	"github.com/cortexproject/cortex/pkg/util/modules"
@@ -70,6 +72,7 @@ import (
 const (
 	API                      string = "api"
 	Ring                     string = "ring"
+	PartitionRing            string = "partition-ring"
 	RuntimeConfig            string = "runtime-config"
 	OverridesConfig          string = "overrides-config"
 	Overrides                string = "overrides"
@@ -94,6 +97,7 @@ const (
 	ParquetConverter         string = "parquet-converter"
 	StoreGateway             string = "store-gateway"
 	MemberlistKV             string = "memberlist-kv"
+	RaftKV                   string = "raft-kv"
 	TenantDeletion           string = "tenant-deletion"
 	Purger                   string = "purger"
 	QueryScheduler           string = "query-scheduler"
@@ -162,6 +166,22 @@ func (t *Cortex) initRing() (serv services.Service, err error) {
 	return t.Ring, nil
 }
 
+func (t *Cortex) initPartitionRing() (serv services.Service, err error) {
+	if !t.Cfg.Ingester.LifecyclerConfig.RingConfig.PartitionRing.Enabled {
+		return nil, nil
+	}
+
+	t.Cfg.Ingester.LifecyclerConfig.RingConfig.KVStore.Multi.ConfigProvider = multiClientRuntimeConfigChannel(t.RuntimeConfig)
+	t.PartitionRing, err = ring.NewPartitionRing(t.Cfg.Ingester.LifecyclerConfig.RingConfig, "ingester", ingester.PartitionRingKey, util_log.Logger, prometheus.WrapRegistererWithPrefix("cortex_", prometheus.DefaultRegisterer))
+	if err != nil {
+		return nil, err
+	}
+
+	t.API.RegisterPartitionRing(t.PartitionRing)
+
+	return t.PartitionRing, nil
+}
+
 func (t *Cortex) initRuntimeConfig() (services.Service, error) {
 	if t.Cfg.RuntimeConfig.LoadPath == "" {
 		// no need to initialize module if load path is empty
@@ -256,7 +276,13 @@ func (t *Cortex) initDistributorService() (serv services.Service, err error) {
 	// ruler's dependency)
 	canJoinDistributorsRing := t.Cfg.isModuleEnabled(Distributor) || t.Cfg.isModuleEnabled(All)
 
-	t.Distributor, err = distributor.New(t.Cfg.Distributor, t.Cfg.IngesterClient, t.OverridesConfig, t.Ring, canJoinDistributorsRing, prometheus.DefaultRegisterer, util_log.Logger)
+	// When the partition ring is enabled, the series are sharded to the partitions instead of the ingesters.
+	var ingestersRing ring.ReadRing = t.Ring
+	if t.PartitionRing != nil {
+		ingestersRing = t.PartitionRing
+	}
+
+	t.Distributor, err = distributor.New(t.Cfg.Distributor, t.Cfg.IngesterClient, t.OverridesConfig, ingestersRing, canJoinDistributorsRing, prometheus.DefaultRegisterer, util_log.Logger)
 	if err != nil {
 		return
 	}
@@ -291,6 +317,11 @@ func (t *Cortex) initQueryable() (serv services.Service, err error) {
 	// Create a querier queryable and PromQL engine
 	t.QuerierQueryable, t.ExemplarQueryable, t.QuerierEngine = querier.New(t.Cfg.Querier, t.OverridesConfig, t.Distributor, t.StoreQueryables, querierRegisterer, util_log.Logger, t.OverridesConfig.QueryPartialData, t.ResourceMonitor)
 
+	// Query the exemplars persisted in the storage too, if enabled.
+	if t.StoreExemplarQueryable != nil {
+		t.ExemplarQueryable = querier.NewMergeExemplarQueryable(t.ExemplarQueryable, t.StoreExemplarQueryable)
+	}
+
 	// Use distributor as default MetadataQuerier
 	t.MetadataQuerier = t.Distributor
 
@@ -394,6 +425,7 @@ func (t *Cortex) initQuerier() (serv services.Service, err error) {
 		t.ExemplarQueryable,
 		t.QuerierEngine,
 		t.MetadataQuerier,
+		t.OverridesConfig,
 		prometheus.DefaultRegisterer,
 		util_log.Logger,
 	)
@@ -453,6 +485,9 @@ func (t *Cortex) initStoreQueryables() (services.Service, error) {
 		return nil, fmt.Errorf("failed to initialize querier: %v", err)
 	} else {
 		queriable = q
+		if t.Cfg.BlocksStorage.TSDB.PersistExemplars {
+			t.StoreExemplarQueryable = q
+		}
 		if t.Cfg.Querier.EnableParquetQueryable {
 			pq, err := querier.NewParquetQueryable(t.Cfg.Querier, t.Cfg.BlocksStorage, t.OverridesConfig, q, util_log.Logger, prometheus.DefaultRegisterer)
 			if err != nil {
@@ -500,6 +535,7 @@ func (t *Cortex) initIngesterService() (serv services.Service, err error) {
 	t.Cfg.Ingester.LifecyclerConfig.ListenPort = t.Cfg.Server.GRPCListenPort
 	t.Cfg.Ingester.DistributorShardingStrategy = t.Cfg.Distributor.ShardingStrategy
 	t.Cfg.Ingester.DistributorShardByAllLabels = t.Cfg.Distributor.ShardByAllLabels
+	t.Cfg.Ingester.IngesterClientConfig = t.Cfg.IngesterClient
 	t.Cfg.Ingester.InstanceLimitsFn = ingesterInstanceLimits(t.RuntimeConfig)
 	t.tsdbIngesterConfig()
 
@@ -833,6 +869,7 @@ func (t *Cortex) initMemberlistKV() (services.Service, error) {
 	t.Cfg.MemberlistKV.MetricsRegisterer = reg
 	t.Cfg.MemberlistKV.Codecs = []codec.Codec{
 		ring.GetCodec(),
+		ring.GetPartitionRingCodec(),
 		ha.GetReplicaDescCodec(),
 	}
 	dnsProviderReg := prometheus.WrapRegistererWithPrefix(
@@ -842,7 +879,15 @@ func (t *Cortex) initMemberlistKV() (services.Service, error) {
 			reg,
 		),
 	)
-	dnsProvider := dns.NewProvider(util_log.Logger, dnsProviderReg, dns.GolangResolverType)
+	dnsProvider := discovery.NewProvider(util_log.Logger, dnsProviderReg, dns.GolangResolverType)
+
+	// The peers serve the status page on the same HTTP server configuration as this instance.
+	t.Cfg.MemberlistKV.StatusPageHTTPPort = t.Cfg.Server.HTTPListenPort
+	t.Cfg.MemberlistKV.StatusPageHTTPScheme = "http"
+	if t.Cfg.Server.HTTPTLSConfig.TLSCertPath != "" && t.Cfg.Server.HTTPTLSConfig.TLSKeyPath != "" {
+		t.Cfg.MemberlistKV.StatusPageHTTPScheme = "https"
+	}
+
 	t.MemberlistKV = memberlist.NewKVInitService(&t.Cfg.MemberlistKV, util_log.Logger, dnsProvider, reg)
 	t.API.RegisterMemberlistKV(t.MemberlistKV)
 
@@ -859,6 +904,26 @@ func (t *Cortex) initMemberlistKV() (services.Service, error) {
 	return t.MemberlistKV, nil
 }
 
+func (t *Cortex) initRaftKV() (services.Service, error) {
+	t.Cfg.RaftKV.MetricsRegisterer = prometheus.DefaultRegisterer
+	t.Cfg.RaftKV.TLSEnabled = t.Cfg.MemberlistKV.TCPTransport.TLSEnabled
+	t.Cfg.RaftKV.TLS = t.Cfg.MemberlistKV.TCPTransport.TLS
+	t.RaftKV = raft.NewKVInitService(&t.Cfg.RaftKV, util_log.Logger)
+	t.API.RegisterRaftKV(t.RaftKV)
+
+	// Update the config.
+	t.Cfg.Distributor.DistributorRing.KVStore.RaftKV = t.RaftKV.GetRaftKV
+	t.Cfg.Distributor.HATrackerConfig.KVStore.RaftKV = t.RaftKV.GetRaftKV
+	t.Cfg.Ingester.LifecyclerConfig.RingConfig.KVStore.RaftKV = t.RaftKV.GetRaftKV
+	t.Cfg.StoreGateway.ShardingRing.KVStore.RaftKV = t.RaftKV.GetRaftKV
+	t.Cfg.Compactor.ShardingRing.KVStore.RaftKV = t.RaftKV.GetRaftKV
+	t.Cfg.Ruler.Ring.KVStore.RaftKV = t.RaftKV.GetRaftKV
+	t.Cfg.Alertmanager.ShardingRing.KVStore.RaftKV = t.RaftKV.GetRaftKV
+	t.Cfg.ParquetConverter.Ring.KVStore.RaftKV = t.RaftKV.GetRaftKV
+
+	return t.RaftKV, nil
+}
+
 func (t *Cortex) initTenantDeletionAPI() (services.Service, error) {
 	// t.RulerStorage can be nil when running in single-binary mode, and rule storage is not configured.
 	tenantDeletionAPI, err := purger.NewTenantDeletionAPI(t.Cfg.BlocksStorage, t.OverridesConfig, util_log.Logger, prometheus.DefaultRegisterer)
@@ -919,7 +984,9 @@ func (t *Cortex) setupModuleManager() error {
 	mm.RegisterModule(API, t.initAPI, modules.UserInvisibleModule)
 	mm.RegisterModule(RuntimeConfig, t.initRuntimeConfig, modules.UserInvisibleModule)
 	mm.RegisterModule(MemberlistKV, t.initMemberlistKV, modules.UserInvisibleModule)
+	mm.RegisterModule(RaftKV, t.initRaftKV, modules.UserInvisibleModule)
 	mm.RegisterModule(Ring, t.initRing, modules.UserInvisibleModule)
+	mm.RegisterModule(PartitionRing, t.initPartitionRing, modules.UserInvisibleModule)
 	mm.RegisterModule(OverridesConfig, t.initOverridesConfig, modules.UserInvisibleModule)
 	mm.RegisterModule(Overrides, t.initOverrides)
 	mm.RegisterModule(OverridesExporter, t.initOverridesExporter)
@@ -951,29 +1018,31 @@ func (t *Cortex) setupModuleManager() error {
 	deps := map[string][]string{
 		API:                      {Server},
 		MemberlistKV:             {API},
+		RaftKV:                   {API},
 		RuntimeConfig:            {API},
-		Ring:                     {API, RuntimeConfig, MemberlistKV},
+		Ring:                     {API, RuntimeConfig, MemberlistKV, RaftKV},
+		PartitionRing:            {API, RuntimeConfig, MemberlistKV, RaftKV},
 		OverridesConfig:          {RuntimeConfig},
 		Overrides:                {API, OverridesConfig},
 		OverridesExporter:        {RuntimeConfig},
 		Distributor:              {DistributorService, API, GrpcClientService},
-		DistributorService:       {Ring, OverridesConfig},
+		DistributorService:       {Ring, PartitionRing, OverridesConfig},
 		Ingester:                 {IngesterService, OverridesConfig, API},
-		IngesterService:          {OverridesConfig, RuntimeConfig, MemberlistKV, ResourceMonitor},
+		IngesterService:          {OverridesConfig, RuntimeConfig, MemberlistKV, RaftKV, ResourceMonitor},
 		Flusher:                  {OverridesConfig, API},
-		Queryable:                {OverridesConfig, DistributorService, OverridesConfig, Ring, API, StoreQueryable, MemberlistKV, ResourceMonitor},
+		Queryable:                {OverridesConfig, DistributorService, OverridesConfig, Ring, API, StoreQueryable, MemberlistKV, RaftKV, ResourceMonitor},
 		Querier:                  {TenantFederation},
-		StoreQueryable:           {OverridesConfig, OverridesConfig, MemberlistKV, GrpcClientService},
+		StoreQueryable:           {OverridesConfig, OverridesConfig, MemberlistKV, RaftKV, GrpcClientService},
 		QueryFrontendTripperware: {API, OverridesConfig},
 		QueryFrontend:            {QueryFrontendTripperware},
 		QueryScheduler:           {API, OverridesConfig},
 		Ruler:                    {DistributorService, OverridesConfig, StoreQueryable, RulerStorage},
 		RulerStorage:             {OverridesConfig},
 		Configs:                  {API},
-		AlertManager:             {API, MemberlistKV, OverridesConfig},
-		Compactor:                {API, MemberlistKV, OverridesConfig},
-		ParquetConverter:         {API, MemberlistKV, OverridesConfig},
-		StoreGateway:             {API, OverridesConfig, MemberlistKV, ResourceMonitor},
+		AlertManager:             {API, MemberlistKV, RaftKV, OverridesConfig},
+		Compactor:                {API, MemberlistKV, RaftKV, OverridesConfig},
+		ParquetConverter:         {API, MemberlistKV, RaftKV, OverridesConfig},
+		StoreGateway:             {API, OverridesConfig, MemberlistKV, RaftKV, ResourceMonitor},
 		TenantDeletion:           {API, OverridesConfig},
 		Purger:                   {TenantDeletion},
 		TenantFederation:         {Queryable},
@@ -83,6 +83,9 @@ func (l runtimeConfigLoader) load(r io.Reader) (any, error) {
 					if err := ul.ValidateQueryLimits(userID, l.cfg.BlocksStorage.TSDB.CloseIdleTSDBTimeout); err != nil {
 						return nil, err
 					}
+					if err := l.cfg.Distributor.Aggregation.ValidateLimits(*ul); err != nil {
+						return nil, err
+					}
 				}
 			}
 		}
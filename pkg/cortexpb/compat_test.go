@@ -102,6 +102,13 @@ func TestMetricMetadataToMetricTypeToMetricType(t *testing.T) {
 	}
 }
 
+func TestMetricTypeToMetricMetadataMetricType(t *testing.T) {
+	for _, mt := range []MetricMetadata_MetricType{UNKNOWN, COUNTER, GAUGE, HISTOGRAM, GAUGEHISTOGRAM, SUMMARY, INFO, STATESET} {
+		assert.Equal(t, mt, MetricTypeToMetricMetadataMetricType(MetricMetadataMetricTypeToMetricType(mt)))
+	}
+	assert.Equal(t, UNKNOWN, MetricTypeToMetricMetadataMetricType("invalid"))
+}
+
 func TestFromLabelAdaptersToLabels(t *testing.T) {
 	input := []LabelAdapter{{Name: "hello", Value: "world"}}
 	expected := labels.FromStrings("hello", "world")
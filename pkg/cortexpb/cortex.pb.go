@@ -124,7 +124,7 @@ var MetricMetadata_MetricType_value = map[string]int32{
 }
 
 func (MetricMetadata_MetricType) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_893a47d0a749d749, []int{11, 0}
+	return fileDescriptor_893a47d0a749d749, []int{12, 0}
 }
 
 type Histogram_ResetHint int32
@@ -151,7 +151,7 @@ var Histogram_ResetHint_value = map[string]int32{
 }
 
 func (Histogram_ResetHint) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_893a47d0a749d749, []int{14, 0}
+	return fileDescriptor_893a47d0a749d749, []int{15, 0}
 }
 
 func (m *MessageWithBufRef) Reset()      { *m = MessageWithBufRef{} }
@@ -617,6 +617,13 @@ type WriteResponse struct {
 	Histograms int64 `protobuf:"varint,4,opt,name=Histograms,proto3" json:"Histograms,omitempty"`
 	// Exemplars represents X-Prometheus-Remote-Write-Written-Exemplars
 	Exemplars int64 `protobuf:"varint,5,opt,name=Exemplars,proto3" json:"Exemplars,omitempty"`
+	// CpuUtilization and HeapUtilization report the utilization of the ingester resources,
+	// between 0 and 1, or 0 if not monitored.
+	CpuUtilization  float64 `protobuf:"fixed64,6,opt,name=cpu_utilization,json=cpuUtilization,proto3" json:"cpu_utilization,omitempty"`
+	HeapUtilization float64 `protobuf:"fixed64,7,opt,name=heap_utilization,json=heapUtilization,proto3" json:"heap_utilization,omitempty"`
+	// LoadHints are reported by the ingesters on the push stream, so that the distributors
+	// can pace the pushes before the ingester limits are reached.
+	LoadHints *LoadHints `protobuf:"bytes,8,opt,name=load_hints,json=loadHints,proto3" json:"load_hints,omitempty"`
 }
 
 func (m *WriteResponse) Reset()      { *m = WriteResponse{} }
@@ -686,6 +693,125 @@ func (m *WriteResponse) GetExemplars() int64 {
 	return 0
 }
 
+func (m *WriteResponse) GetCpuUtilization() float64 {
+	if m != nil {
+		return m.CpuUtilization
+	}
+	return 0
+}
+
+func (m *WriteResponse) GetHeapUtilization() float64 {
+	if m != nil {
+		return m.HeapUtilization
+	}
+	return 0
+}
+
+func (m *WriteResponse) GetLoadHints() *LoadHints {
+	if m != nil {
+		return m.LoadHints
+	}
+	return nil
+}
+
+// LoadHints report how close an ingester is to its instance limits. A max value
+// of 0 means the limit is disabled.
+type LoadHints struct {
+	// Push requests in progress in the ingester.
+	InflightPushRequests    int64 `protobuf:"varint,1,opt,name=inflight_push_requests,json=inflightPushRequests,proto3" json:"inflight_push_requests,omitempty"`
+	MaxInflightPushRequests int64 `protobuf:"varint,2,opt,name=max_inflight_push_requests,json=maxInflightPushRequests,proto3" json:"max_inflight_push_requests,omitempty"`
+	// In-memory series of the ingester across all tenants, checked against the
+	// max_in_memory_series instance limit. This is a series count, not a memory usage.
+	InMemorySeries    int64 `protobuf:"varint,3,opt,name=in_memory_series,json=inMemorySeries,proto3" json:"in_memory_series,omitempty"`
+	MaxInMemorySeries int64 `protobuf:"varint,4,opt,name=max_in_memory_series,json=maxInMemorySeries,proto3" json:"max_in_memory_series,omitempty"`
+	// Ingestion rate of the ingester, in samples per second.
+	IngestionRate    float64 `protobuf:"fixed64,5,opt,name=ingestion_rate,json=ingestionRate,proto3" json:"ingestion_rate,omitempty"`
+	MaxIngestionRate float64 `protobuf:"fixed64,6,opt,name=max_ingestion_rate,json=maxIngestionRate,proto3" json:"max_ingestion_rate,omitempty"`
+	// 99th percentile of the recent push stream append latency, in seconds.
+	AppendLatencyP99 float64 `protobuf:"fixed64,7,opt,name=append_latency_p99,json=appendLatencyP99,proto3" json:"append_latency_p99,omitempty"`
+}
+
+func (m *LoadHints) Reset()      { *m = LoadHints{} }
+func (*LoadHints) ProtoMessage() {}
+func (*LoadHints) Descriptor() ([]byte, []int) {
+	return fileDescriptor_893a47d0a749d749, []int{8}
+}
+func (m *LoadHints) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *LoadHints) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_LoadHints.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *LoadHints) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LoadHints.Merge(m, src)
+}
+func (m *LoadHints) XXX_Size() int {
+	return m.Size()
+}
+func (m *LoadHints) XXX_DiscardUnknown() {
+	xxx_messageInfo_LoadHints.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LoadHints proto.InternalMessageInfo
+
+func (m *LoadHints) GetInflightPushRequests() int64 {
+	if m != nil {
+		return m.InflightPushRequests
+	}
+	return 0
+}
+
+func (m *LoadHints) GetMaxInflightPushRequests() int64 {
+	if m != nil {
+		return m.MaxInflightPushRequests
+	}
+	return 0
+}
+
+func (m *LoadHints) GetInMemorySeries() int64 {
+	if m != nil {
+		return m.InMemorySeries
+	}
+	return 0
+}
+
+func (m *LoadHints) GetMaxInMemorySeries() int64 {
+	if m != nil {
+		return m.MaxInMemorySeries
+	}
+	return 0
+}
+
+func (m *LoadHints) GetIngestionRate() float64 {
+	if m != nil {
+		return m.IngestionRate
+	}
+	return 0
+}
+
+func (m *LoadHints) GetMaxIngestionRate() float64 {
+	if m != nil {
+		return m.MaxIngestionRate
+	}
+	return 0
+}
+
+func (m *LoadHints) GetAppendLatencyP99() float64 {
+	if m != nil {
+		return m.AppendLatencyP99
+	}
+	return 0
+}
+
 type TimeSeries struct {
 	Labels []LabelAdapter `protobuf:"bytes,1,rep,name=labels,proto3,customtype=LabelAdapter" json:"labels"`
 	// Sorted by time, oldest sample first.
@@ -697,7 +823,7 @@ type TimeSeries struct {
 func (m *TimeSeries) Reset()      { *m = TimeSeries{} }
 func (*TimeSeries) ProtoMessage() {}
 func (*TimeSeries) Descriptor() ([]byte, []int) {
-	return fileDescriptor_893a47d0a749d749, []int{8}
+	return fileDescriptor_893a47d0a749d749, []int{9}
 }
 func (m *TimeSeries) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -748,7 +874,7 @@ type LabelPair struct {
 func (m *LabelPair) Reset()      { *m = LabelPair{} }
 func (*LabelPair) ProtoMessage() {}
 func (*LabelPair) Descriptor() ([]byte, []int) {
-	return fileDescriptor_893a47d0a749d749, []int{9}
+	return fileDescriptor_893a47d0a749d749, []int{10}
 }
 func (m *LabelPair) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -800,7 +926,7 @@ type Sample struct {
 func (m *Sample) Reset()      { *m = Sample{} }
 func (*Sample) ProtoMessage() {}
 func (*Sample) Descriptor() ([]byte, []int) {
-	return fileDescriptor_893a47d0a749d749, []int{10}
+	return fileDescriptor_893a47d0a749d749, []int{11}
 }
 func (m *Sample) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -860,7 +986,7 @@ type MetricMetadata struct {
 func (m *MetricMetadata) Reset()      { *m = MetricMetadata{} }
 func (*MetricMetadata) ProtoMessage() {}
 func (*MetricMetadata) Descriptor() ([]byte, []int) {
-	return fileDescriptor_893a47d0a749d749, []int{11}
+	return fileDescriptor_893a47d0a749d749, []int{12}
 }
 func (m *MetricMetadata) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -924,7 +1050,7 @@ type Metric struct {
 func (m *Metric) Reset()      { *m = Metric{} }
 func (*Metric) ProtoMessage() {}
 func (*Metric) Descriptor() ([]byte, []int) {
-	return fileDescriptor_893a47d0a749d749, []int{12}
+	return fileDescriptor_893a47d0a749d749, []int{13}
 }
 func (m *Metric) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -963,7 +1089,7 @@ type Exemplar struct {
 func (m *Exemplar) Reset()      { *m = Exemplar{} }
 func (*Exemplar) ProtoMessage() {}
 func (*Exemplar) Descriptor() ([]byte, []int) {
-	return fileDescriptor_893a47d0a749d749, []int{13}
+	return fileDescriptor_893a47d0a749d749, []int{14}
 }
 func (m *Exemplar) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1085,7 +1211,7 @@ type Histogram struct {
 func (m *Histogram) Reset()      { *m = Histogram{} }
 func (*Histogram) ProtoMessage() {}
 func (*Histogram) Descriptor() ([]byte, []int) {
-	return fileDescriptor_893a47d0a749d749, []int{14}
+	return fileDescriptor_893a47d0a749d749, []int{15}
 }
 func (m *Histogram) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1300,7 +1426,7 @@ type BucketSpan struct {
 func (m *BucketSpan) Reset()      { *m = BucketSpan{} }
 func (*BucketSpan) ProtoMessage() {}
 func (*BucketSpan) Descriptor() ([]byte, []int) {
-	return fileDescriptor_893a47d0a749d749, []int{15}
+	return fileDescriptor_893a47d0a749d749, []int{16}
 }
 func (m *BucketSpan) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -1356,6 +1482,7 @@ func init() {
 	proto.RegisterType((*MetadataV2)(nil), "cortexpb.MetadataV2")
 	proto.RegisterType((*StreamWriteRequest)(nil), "cortexpb.StreamWriteRequest")
 	proto.RegisterType((*WriteResponse)(nil), "cortexpb.WriteResponse")
+	proto.RegisterType((*LoadHints)(nil), "cortexpb.LoadHints")
 	proto.RegisterType((*TimeSeries)(nil), "cortexpb.TimeSeries")
 	proto.RegisterType((*LabelPair)(nil), "cortexpb.LabelPair")
 	proto.RegisterType((*Sample)(nil), "cortexpb.Sample")
@@ -1369,104 +1496,117 @@ func init() {
 func init() { proto.RegisterFile("cortex.proto", fileDescriptor_893a47d0a749d749) }
 
 var fileDescriptor_893a47d0a749d749 = []byte{
-	// 1552 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xcc, 0x57, 0x3d, 0x6f, 0x1b, 0x47,
-	0x1a, 0xe6, 0xf2, 0x9b, 0x2f, 0x3f, 0xbc, 0x1a, 0xd3, 0xf6, 0x4a, 0xb6, 0x97, 0x32, 0x8d, 0xbb,
-	0x13, 0x7c, 0x86, 0xce, 0x90, 0x71, 0xbe, 0x83, 0x61, 0x1c, 0x40, 0xca, 0x94, 0x45, 0xd8, 0x24,
-	0x85, 0x21, 0x25, 0xc1, 0xd7, 0x2c, 0x56, 0xe4, 0x50, 0x5c, 0x98, 0xcb, 0xdd, 0xec, 0x0c, 0x0d,
-	0x2b, 0x55, 0xaa, 0xc0, 0xe9, 0xd2, 0xa4, 0x49, 0x17, 0xb8, 0x49, 0x9b, 0x3a, 0x7f, 0xc0, 0x5d,
-	0xd4, 0xc5, 0x30, 0x10, 0x21, 0x96, 0x1b, 0x27, 0x95, 0x7f, 0x42, 0x30, 0xb3, 0x9f, 0x14, 0x25,
-	0x38, 0x48, 0x5c, 0xa4, 0xdb, 0x79, 0x9e, 0x77, 0x66, 0x9e, 0x7d, 0x3f, 0x9e, 0x25, 0xa1, 0xd0,
-	0xb7, 0x1c, 0x46, 0x9e, 0xad, 0xda, 0x8e, 0xc5, 0x2c, 0x94, 0x75, 0x57, 0xf6, 0xde, 0x52, 0x79,
-	0xdf, 0xda, 0xb7, 0x04, 0xf8, 0x2f, 0xfe, 0xe4, 0xf2, 0xd5, 0x45, 0x58, 0x68, 0x11, 0x4a, 0xf5,
-	0x7d, 0xb2, 0x6b, 0xb0, 0x51, 0x7d, 0x3a, 0xc4, 0x64, 0x78, 0x37, 0xf9, 0xfe, 0x9b, 0x4a, 0xac,
-	0xfa, 0x45, 0x02, 0x0a, 0xbb, 0x8e, 0xc1, 0x08, 0x26, 0x9f, 0x4c, 0x09, 0x65, 0x68, 0x0b, 0x80,
-	0x19, 0x26, 0xa1, 0xc4, 0x31, 0x08, 0x55, 0xa4, 0xe5, 0xc4, 0x4a, 0x7e, 0xad, 0xbc, 0xea, 0x5f,
-	0xb0, 0xda, 0x33, 0x4c, 0xd2, 0x15, 0x5c, 0x7d, 0xe9, 0xe5, 0x51, 0x25, 0xf6, 0xfa, 0xa8, 0x82,
-	0xb6, 0x1c, 0xa2, 0x8f, 0xc7, 0x56, 0xbf, 0x17, 0xec, 0xc3, 0x91, 0x33, 0xd0, 0x4d, 0x48, 0x77,
-	0xad, 0xa9, 0xd3, 0x27, 0x4a, 0x7c, 0x59, 0x5a, 0x29, 0x45, 0x4f, 0x73, 0xf1, 0xc6, 0x64, 0x6a,
-	0x62, 0x2f, 0x06, 0xdd, 0x85, 0xac, 0x49, 0x98, 0x3e, 0xd0, 0x99, 0xae, 0x24, 0xc4, 0xed, 0x4a,
-	0x18, 0xdf, 0x22, 0xcc, 0x31, 0xfa, 0x2d, 0x8f, 0xaf, 0x27, 0x5f, 0x1e, 0x55, 0x24, 0x1c, 0xc4,
-	0xa3, 0x7b, 0xb0, 0x44, 0x9f, 0x18, 0xb6, 0x36, 0xd6, 0xf7, 0xc8, 0x58, 0x9b, 0xe8, 0x26, 0xd1,
-	0x9e, 0xea, 0x63, 0x63, 0xa0, 0x33, 0xc3, 0x9a, 0x28, 0xef, 0x32, 0xcb, 0xd2, 0x4a, 0x16, 0x5f,
-	0xe2, 0x21, 0x8f, 0x78, 0x44, 0x5b, 0x37, 0xc9, 0x4e, 0xc0, 0xa3, 0x16, 0x24, 0x30, 0x19, 0x2a,
-	0xbf, 0xf0, 0xb0, 0xfc, 0xda, 0xe5, 0xe8, 0xad, 0x27, 0x72, 0x57, 0xbf, 0xca, 0x5f, 0xfd, 0xf0,
-	0xa8, 0x22, 0xbd, 0x3e, 0xaa, 0xcc, 0xa7, 0x16, 0xf3, 0x73, 0xd0, 0x2d, 0x28, 0x0f, 0x0c, 0xda,
-	0xd7, 0x9d, 0x81, 0x66, 0x4d, 0x99, 0x66, 0x0d, 0x35, 0xcb, 0x19, 0x10, 0x47, 0xf9, 0xd5, 0x95,
-	0xb1, 0xe0, 0x91, 0x9d, 0x29, 0xeb, 0x0c, 0x3b, 0x9c, 0xa9, 0x7e, 0x1f, 0x87, 0x52, 0xb4, 0x16,
-	0x3b, 0x6b, 0x48, 0x81, 0x0c, 0x3d, 0x30, 0xf7, 0xac, 0x31, 0x55, 0x92, 0xcb, 0x89, 0x95, 0x1c,
-	0xf6, 0x97, 0xa8, 0x37, 0x53, 0xa7, 0x94, 0xc8, 0xd4, 0xc5, 0xd3, 0xea, 0xb4, 0xb3, 0x56, 0xbf,
-	0xe2, 0x55, 0xaa, 0x3c, 0x5f, 0xa9, 0x9d, 0xb5, 0x33, 0x6a, 0x95, 0xfe, 0x1d, 0xb5, 0xfa, 0x2b,
-	0xe5, 0xbb, 0xfa, 0x43, 0x1c, 0x0a, 0xd1, 0xb7, 0x46, 0x15, 0xc8, 0x0b, 0x61, 0x54, 0x73, 0xc8,
-	0xd0, 0x6d, 0xe5, 0x22, 0x06, 0x17, 0xc2, 0x64, 0x48, 0xd1, 0x2d, 0xc8, 0x50, 0xdd, 0xb4, 0xc7,
-	0x84, 0x2a, 0x71, 0x91, 0x3f, 0x39, 0xf2, 0xb6, 0x82, 0x10, 0x1d, 0x16, 0xc3, 0x7e, 0x18, 0x6a,
-	0x01, 0x8c, 0x0c, 0xca, 0xac, 0x7d, 0x47, 0x37, 0xa9, 0xd7, 0x9e, 0xe7, 0xc3, 0x4d, 0x9b, 0x3e,
-	0x57, 0x57, 0xbc, 0x8c, 0xcb, 0xbb, 0x8e, 0x6e, 0xdb, 0x64, 0x10, 0x30, 0x38, 0x72, 0x00, 0xfa,
-	0x2f, 0xe4, 0xc8, 0x33, 0x62, 0xda, 0x63, 0xdd, 0x71, 0xeb, 0x3b, 0x33, 0x6a, 0x0d, 0x8f, 0xda,
-	0x59, 0xf3, 0x64, 0x84, 0xc1, 0xe8, 0x4e, 0x64, 0x4a, 0x52, 0x22, 0x7f, 0xe5, 0x99, 0x29, 0x11,
-	0x4c, 0xb0, 0x31, 0x9c, 0x90, 0x7f, 0xc2, 0x42, 0xdf, 0x21, 0x3a, 0x23, 0x03, 0x4d, 0x54, 0x9d,
-	0xe9, 0xa6, 0x2d, 0x4a, 0x9d, 0xc0, 0xb2, 0x47, 0xf4, 0x7c, 0xbc, 0xaa, 0x03, 0x84, 0x1a, 0x3e,
-	0x9c, 0xce, 0x32, 0xa4, 0x9e, 0xea, 0xe3, 0xa9, 0x3b, 0xe6, 0x12, 0x76, 0x17, 0xe8, 0x0a, 0xe4,
-	0xc2, 0x9b, 0x12, 0xe2, 0xa6, 0x10, 0xa8, 0xfe, 0x18, 0x07, 0x08, 0xe5, 0xa2, 0xdb, 0x90, 0x64,
-	0x07, 0x36, 0x51, 0x24, 0xd1, 0x7c, 0x95, 0xd3, 0x5e, 0xc9, 0xf3, 0x80, 0xde, 0x81, 0x4d, 0xb0,
-	0x08, 0x46, 0x8b, 0x90, 0x1d, 0x91, 0xb1, 0xcd, 0x65, 0x89, 0x0b, 0x8a, 0x38, 0xc3, 0xd7, 0x7c,
-	0x06, 0x17, 0x21, 0x3b, 0x9d, 0x18, 0x4c, 0x50, 0x49, 0x97, 0xe2, 0x6b, 0xde, 0x2e, 0x3f, 0x49,
-	0xe2, 0x66, 0xef, 0x28, 0x74, 0x19, 0x2e, 0xb5, 0x1a, 0x3d, 0xdc, 0x5c, 0xd7, 0x7a, 0x8f, 0xb7,
-	0x1a, 0xda, 0x76, 0xbb, 0xbb, 0xd5, 0x58, 0x6f, 0x6e, 0x34, 0x1b, 0xf7, 0xe5, 0x18, 0xba, 0x04,
-	0xe7, 0xa3, 0xe4, 0x7a, 0x67, 0xbb, 0xdd, 0x6b, 0x60, 0x59, 0x42, 0x17, 0x60, 0x21, 0x4a, 0x3c,
-	0xa8, 0x6d, 0x3f, 0x68, 0xc8, 0x71, 0xb4, 0x08, 0x17, 0xa2, 0xf0, 0x66, 0xb3, 0xdb, 0xeb, 0x3c,
-	0xc0, 0xb5, 0x96, 0x9c, 0x40, 0x2a, 0x2c, 0xcd, 0xed, 0x08, 0xf9, 0xe4, 0xc9, 0xab, 0xba, 0xdb,
-	0xad, 0x56, 0x0d, 0x3f, 0x96, 0x53, 0xa8, 0x0c, 0x72, 0x94, 0x68, 0xb6, 0x37, 0x3a, 0x72, 0x1a,
-	0x29, 0x50, 0x9e, 0x09, 0xef, 0xd5, 0x7a, 0x8d, 0x6e, 0xa3, 0x27, 0x67, 0xaa, 0xdf, 0x49, 0x80,
-	0xba, 0xcc, 0x21, 0xba, 0x39, 0x63, 0xef, 0x4b, 0x90, 0xed, 0x91, 0x89, 0x3e, 0x61, 0xcd, 0xfb,
-	0x22, 0xcb, 0x39, 0x1c, 0xac, 0xf9, 0x3c, 0x78, 0x61, 0xa2, 0x84, 0x33, 0x7e, 0x12, 0x3d, 0x04,
-	0xfb, 0x61, 0xfe, 0x08, 0xbf, 0xfb, 0x48, 0x23, 0xfc, 0x95, 0x04, 0x45, 0xef, 0x22, 0x6a, 0x5b,
-	0x13, 0x4a, 0x10, 0x82, 0x64, 0xdf, 0x1a, 0xb8, 0x0d, 0x91, 0xc2, 0xe2, 0x99, 0x7b, 0xa2, 0xe9,
-	0xee, 0x17, 0x32, 0x73, 0xd8, 0x5f, 0x72, 0xa6, 0xeb, 0x0d, 0xb4, 0xdb, 0x69, 0xfe, 0x12, 0xa9,
-	0x00, 0x9b, 0xe1, 0xe0, 0x26, 0x05, 0x19, 0x41, 0x78, 0x97, 0x36, 0x82, 0x49, 0x4c, 0xb9, 0x5d,
-	0x1a, 0x00, 0xd5, 0xe7, 0x71, 0x80, 0xd0, 0x5a, 0x50, 0x0d, 0xd2, 0x6e, 0xdb, 0x7b, 0x9f, 0xc7,
-	0x88, 0x03, 0x08, 0x9f, 0xdb, 0xd2, 0x0d, 0xa7, 0x5e, 0xf6, 0x1c, 0xa0, 0x20, 0xa0, 0xda, 0x40,
-	0xb7, 0x19, 0x71, 0xb0, 0xb7, 0xf1, 0x0f, 0x58, 0xcf, 0x9d, 0xa8, 0x57, 0xb8, 0xce, 0x83, 0xe6,
-	0xbd, 0x62, 0xde, 0x29, 0x66, 0x2d, 0x2b, 0xf9, 0x27, 0x2d, 0xab, 0xfa, 0x6f, 0xc8, 0x05, 0xef,
-	0xc8, 0xab, 0xc3, 0x4d, 0x5f, 0x54, 0xa7, 0x80, 0xc5, 0xf3, 0xac, 0x0b, 0x14, 0x3c, 0x17, 0xa8,
-	0x5a, 0x90, 0x76, 0x5f, 0x2b, 0xe4, 0xa5, 0xa8, 0x4b, 0x5c, 0x83, 0x42, 0x60, 0x0a, 0x9a, 0x49,
-	0xc5, 0xe6, 0x04, 0xce, 0x07, 0x58, 0x8b, 0x7f, 0x9a, 0x10, 0x65, 0xba, 0xc3, 0xb4, 0x99, 0x40,
-	0xb7, 0xce, 0xb2, 0x60, 0x7a, 0x61, 0x74, 0xf5, 0xeb, 0x38, 0x94, 0x66, 0x7f, 0x2d, 0xa0, 0xff,
-	0xcc, 0x98, 0xcb, 0xf5, 0xb3, 0x7e, 0x55, 0xcc, 0x1b, 0xcc, 0x4d, 0x40, 0xa6, 0xc0, 0xb4, 0xa1,
-	0x6e, 0x1a, 0xe3, 0x03, 0xf1, 0xa5, 0xf3, 0x7a, 0x4f, 0x76, 0x99, 0x0d, 0x41, 0xf0, 0x0f, 0x1c,
-	0x4f, 0x0a, 0xb7, 0x1f, 0xd1, 0x64, 0x39, 0x2c, 0x9e, 0x39, 0xc6, 0x7d, 0x47, 0x74, 0x56, 0x0e,
-	0x8b, 0xe7, 0xea, 0xc1, 0x8c, 0xff, 0xe4, 0x21, 0xb3, 0xdd, 0x7e, 0xd8, 0xee, 0xec, 0xb6, 0xe5,
-	0x18, 0x5f, 0x84, 0x1e, 0x93, 0x83, 0x94, 0xef, 0x2b, 0x45, 0xc8, 0x45, 0xbd, 0x04, 0x41, 0x69,
-	0xce, 0x3f, 0xf2, 0x90, 0x09, 0x3d, 0x23, 0x0b, 0x49, 0xcf, 0x27, 0x0a, 0x90, 0x8d, 0x78, 0xc3,
-	0x43, 0x48, 0xbb, 0x57, 0x7f, 0x84, 0x56, 0xae, 0x7e, 0x2e, 0x41, 0xd6, 0x6f, 0xbf, 0x8f, 0x31,
-	0x1a, 0xa7, 0x7f, 0x46, 0x4e, 0x36, 0x48, 0x62, 0xae, 0x41, 0xaa, 0x2f, 0xd2, 0x90, 0x0b, 0x9a,
-	0x16, 0x5d, 0x85, 0x5c, 0xdf, 0x9a, 0x4e, 0x98, 0x66, 0x4c, 0x98, 0x28, 0x79, 0x72, 0x33, 0x86,
-	0xb3, 0x02, 0x6a, 0x4e, 0x18, 0xba, 0x06, 0x79, 0x97, 0x1e, 0x8e, 0x2d, 0xdd, 0xf5, 0x3b, 0x69,
-	0x33, 0x86, 0x41, 0x80, 0x1b, 0x1c, 0x43, 0x32, 0x24, 0xe8, 0xd4, 0x14, 0x37, 0x49, 0x98, 0x3f,
-	0xa2, 0x8b, 0x90, 0xa6, 0xfd, 0x11, 0x31, 0x75, 0x51, 0xdc, 0x05, 0xec, 0xad, 0xd0, 0xdf, 0xa0,
-	0xf4, 0x29, 0x71, 0x2c, 0x8d, 0x8d, 0x1c, 0x42, 0x47, 0xd6, 0x78, 0x20, 0x0a, 0x2d, 0xe1, 0x22,
-	0x47, 0x7b, 0x3e, 0x88, 0xfe, 0xee, 0x85, 0x85, 0xba, 0xd2, 0x42, 0x97, 0x84, 0x0b, 0x1c, 0x5f,
-	0xf7, 0xb5, 0xdd, 0x00, 0x39, 0x12, 0xe7, 0x0a, 0xcc, 0x08, 0x81, 0x12, 0x2e, 0x05, 0x91, 0xae,
-	0xc8, 0x1a, 0x94, 0x26, 0x64, 0x5f, 0x67, 0xc6, 0x53, 0xa2, 0x51, 0x5b, 0x9f, 0x50, 0x25, 0x7b,
-	0xf2, 0x77, 0x44, 0x7d, 0xda, 0x7f, 0x42, 0x58, 0xd7, 0xd6, 0x27, 0x9e, 0x3b, 0x14, 0xfd, 0x1d,
-	0x1c, 0xa3, 0xe8, 0x1f, 0x70, 0x2e, 0x38, 0x62, 0x40, 0xc6, 0x4c, 0xa7, 0x4a, 0x6e, 0x39, 0xb1,
-	0x82, 0x70, 0x70, 0xf2, 0x7d, 0x81, 0xce, 0x04, 0x0a, 0x6d, 0x54, 0x81, 0xe5, 0xc4, 0x8a, 0x14,
-	0x06, 0x0a, 0x61, 0xdc, 0x20, 0x4b, 0xb6, 0x45, 0x8d, 0x88, 0xa8, 0xfc, 0x87, 0x45, 0xf9, 0x3b,
-	0x02, 0x51, 0xc1, 0x11, 0x9e, 0xa8, 0x82, 0x2b, 0xca, 0x87, 0x43, 0x51, 0x41, 0xa0, 0x27, 0xaa,
-	0xe8, 0x8a, 0xf2, 0x61, 0x4f, 0xd4, 0x3d, 0x00, 0x87, 0x50, 0xc2, 0xb4, 0x11, 0xcf, 0x7c, 0x49,
-	0x98, 0xc0, 0xd5, 0x53, 0x8c, 0x70, 0x15, 0xf3, 0xa8, 0x4d, 0x63, 0xc2, 0x70, 0xce, 0xf1, 0x1f,
-	0xe7, 0xfa, 0xef, 0xdc, 0xbc, 0x41, 0x5d, 0x87, 0x62, 0x7f, 0x4a, 0x99, 0x65, 0x6a, 0xa2, 0x65,
-	0xa9, 0x22, 0x0b, 0x1d, 0x05, 0x17, 0xdc, 0x11, 0xd8, 0x19, 0x2e, 0xb6, 0x70, 0x86, 0x8b, 0xdd,
-	0x85, 0x5c, 0xa0, 0x66, 0xd6, 0x22, 0x32, 0x90, 0x78, 0xdc, 0xe8, 0xca, 0x12, 0x4a, 0x43, 0xbc,
-	0xdd, 0x91, 0xe3, 0xa1, 0x4d, 0x24, 0x96, 0x92, 0xcf, 0x5f, 0xa8, 0x52, 0x3d, 0x03, 0x29, 0x91,
-	0x8f, 0x7a, 0x01, 0x20, 0x6c, 0xa7, 0xea, 0x3d, 0x80, 0x30, 0xf7, 0xbc, 0xa3, 0xad, 0xe1, 0x90,
-	0x12, 0x77, 0x44, 0x16, 0xb0, 0xb7, 0xe2, 0xf8, 0x98, 0x4c, 0xf6, 0xd9, 0x48, 0x4c, 0x46, 0x11,
-	0x7b, 0xab, 0x1b, 0x15, 0x80, 0xf0, 0x7f, 0x00, 0x17, 0x51, 0xdb, 0x6a, 0xca, 0x31, 0x6e, 0x34,
-	0x78, 0xfb, 0x51, 0x43, 0x96, 0xea, 0xff, 0x3b, 0x7c, 0xa3, 0xc6, 0x5e, 0xbd, 0x51, 0x63, 0xef,
-	0xdf, 0xa8, 0xd2, 0x67, 0xc7, 0xaa, 0xf4, 0xed, 0xb1, 0x2a, 0xbd, 0x3c, 0x56, 0xa5, 0xc3, 0x63,
-	0x55, 0xfa, 0xf9, 0x58, 0x95, 0xde, 0x1d, 0xab, 0xb1, 0xf7, 0xc7, 0xaa, 0xf4, 0xe5, 0x5b, 0x35,
-	0x76, 0xf8, 0x56, 0x8d, 0xbd, 0x7a, 0xab, 0xc6, 0xfe, 0x1f, 0xfc, 0x81, 0xdd, 0x4b, 0x8b, 0x7f,
-	0xac, 0xb7, 0x7f, 0x0b, 0x00, 0x00, 0xff, 0xff, 0x99, 0x5c, 0xe6, 0x17, 0xe1, 0x0e, 0x00, 0x00,
+	// 1758 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xcc, 0x58, 0x4d, 0x6f, 0x1b, 0xc7,
+	0x19, 0xe6, 0x92, 0x14, 0x3f, 0x5e, 0x91, 0xf4, 0x6a, 0xc2, 0xd8, 0x6b, 0x25, 0x26, 0x15, 0x06,
+	0x6d, 0xd5, 0x34, 0x70, 0x02, 0xa5, 0x4d, 0x6b, 0xd7, 0x28, 0x20, 0xda, 0xb4, 0x45, 0xc4, 0x94,
+	0x84, 0x21, 0x25, 0xc3, 0xbd, 0x2c, 0xc6, 0xe4, 0x50, 0x5c, 0x64, 0xbf, 0xba, 0x33, 0x6b, 0x58,
+	0x39, 0xf5, 0x54, 0xa4, 0xb7, 0x9e, 0x0b, 0xf4, 0x50, 0xe4, 0xd2, 0x6b, 0xcf, 0xfd, 0x03, 0xbe,
+	0x55, 0xb7, 0x06, 0x41, 0x2b, 0xd4, 0xf2, 0xc5, 0xed, 0xc9, 0x3f, 0xa1, 0x98, 0x99, 0xfd, 0x14,
+	0x25, 0xa4, 0x68, 0x7d, 0xc8, 0x6d, 0xe6, 0x79, 0xde, 0x99, 0x79, 0x77, 0xde, 0x8f, 0x67, 0x48,
+	0x68, 0x4c, 0xbd, 0x80, 0xd3, 0x67, 0x37, 0xfd, 0xc0, 0xe3, 0x1e, 0xaa, 0xa9, 0x99, 0xff, 0x64,
+	0xbd, 0x7d, 0xe4, 0x1d, 0x79, 0x12, 0xfc, 0x48, 0x8c, 0x14, 0xdf, 0xbb, 0x0e, 0x6b, 0x23, 0xca,
+	0x18, 0x39, 0xa2, 0x8f, 0x2c, 0xbe, 0xe8, 0x87, 0x73, 0x4c, 0xe7, 0xb7, 0xcb, 0xaf, 0xff, 0xd8,
+	0x2d, 0xf4, 0x7e, 0x5b, 0x82, 0xc6, 0xa3, 0xc0, 0xe2, 0x14, 0xd3, 0x5f, 0x85, 0x94, 0x71, 0xb4,
+	0x0f, 0xc0, 0x2d, 0x87, 0x32, 0x1a, 0x58, 0x94, 0x19, 0xda, 0x46, 0x69, 0x73, 0x75, 0xab, 0x7d,
+	0x33, 0x3e, 0xe0, 0xe6, 0xc4, 0x72, 0xe8, 0x58, 0x72, 0xfd, 0xf5, 0xe7, 0xa7, 0xdd, 0xc2, 0x37,
+	0xa7, 0x5d, 0xb4, 0x1f, 0x50, 0x62, 0xdb, 0xde, 0x74, 0x92, 0xac, 0xc3, 0x99, 0x3d, 0xd0, 0x87,
+	0x50, 0x19, 0x7b, 0x61, 0x30, 0xa5, 0x46, 0x71, 0x43, 0xdb, 0x6c, 0x65, 0x77, 0x53, 0xf8, 0xc0,
+	0x0d, 0x1d, 0x1c, 0xd9, 0xa0, 0xdb, 0x50, 0x73, 0x28, 0x27, 0x33, 0xc2, 0x89, 0x51, 0x92, 0xa7,
+	0x1b, 0xa9, 0xfd, 0x88, 0xf2, 0xc0, 0x9a, 0x8e, 0x22, 0xbe, 0x5f, 0x7e, 0x7e, 0xda, 0xd5, 0x70,
+	0x62, 0x8f, 0xee, 0xc0, 0x3a, 0xfb, 0xdc, 0xf2, 0x4d, 0x9b, 0x3c, 0xa1, 0xb6, 0xe9, 0x12, 0x87,
+	0x9a, 0x4f, 0x89, 0x6d, 0xcd, 0x08, 0xb7, 0x3c, 0xd7, 0x78, 0x55, 0xdd, 0xd0, 0x36, 0x6b, 0xf8,
+	0x9a, 0x30, 0x79, 0x28, 0x2c, 0x76, 0x89, 0x43, 0x0f, 0x13, 0x1e, 0x8d, 0xa0, 0x84, 0xe9, 0xdc,
+	0xf8, 0x97, 0x30, 0x5b, 0xdd, 0x7a, 0x27, 0x7b, 0xea, 0xb9, 0xbb, 0xeb, 0xdf, 0x10, 0x9f, 0x7e,
+	0x72, 0xda, 0xd5, 0xbe, 0x39, 0xed, 0x2e, 0x5f, 0x2d, 0x16, 0xfb, 0xa0, 0x8f, 0xa1, 0x3d, 0xb3,
+	0xd8, 0x94, 0x04, 0x33, 0xd3, 0x0b, 0xb9, 0xe9, 0xcd, 0x4d, 0x2f, 0x98, 0xd1, 0xc0, 0xf8, 0xb7,
+	0x72, 0x63, 0x2d, 0x22, 0xf7, 0x42, 0xbe, 0x37, 0xdf, 0x13, 0x4c, 0xef, 0x2f, 0x45, 0x68, 0x65,
+	0x63, 0x71, 0xb8, 0x85, 0x0c, 0xa8, 0xb2, 0x63, 0xe7, 0x89, 0x67, 0x33, 0xa3, 0xbc, 0x51, 0xda,
+	0xac, 0xe3, 0x78, 0x8a, 0x26, 0xb9, 0x38, 0xad, 0xc8, 0x9b, 0xba, 0x7a, 0x51, 0x9c, 0x0e, 0xb7,
+	0xfa, 0xef, 0x46, 0x91, 0x6a, 0x2f, 0x47, 0xea, 0x70, 0xeb, 0x92, 0x58, 0x55, 0xfe, 0x8b, 0x58,
+	0x7d, 0x97, 0xee, 0xbb, 0xf7, 0xd7, 0x22, 0x34, 0xb2, 0x5f, 0x8d, 0xba, 0xb0, 0x2a, 0x1d, 0x63,
+	0x66, 0x40, 0xe7, 0x2a, 0x95, 0x9b, 0x18, 0x14, 0x84, 0xe9, 0x9c, 0xa1, 0x8f, 0xa1, 0xca, 0x88,
+	0xe3, 0xdb, 0x94, 0x19, 0x45, 0x79, 0x7f, 0x7a, 0xe6, 0x6b, 0x25, 0x21, 0x33, 0xac, 0x80, 0x63,
+	0x33, 0x34, 0x02, 0x58, 0x58, 0x8c, 0x7b, 0x47, 0x01, 0x71, 0x58, 0x94, 0x9e, 0x6f, 0xa5, 0x8b,
+	0x76, 0x62, 0xae, 0x6f, 0x44, 0x37, 0xae, 0x3f, 0x0a, 0x88, 0xef, 0xd3, 0x59, 0xc2, 0xe0, 0xcc,
+	0x06, 0xe8, 0x67, 0x50, 0xa7, 0xcf, 0xa8, 0xe3, 0xdb, 0x24, 0x50, 0xf1, 0xcd, 0x95, 0xda, 0x20,
+	0xa2, 0x0e, 0xb7, 0x22, 0x37, 0x52, 0x63, 0xf4, 0x69, 0xa6, 0x4a, 0x56, 0x36, 0xb4, 0xfc, 0xc2,
+	0xb8, 0x3e, 0x92, 0x85, 0x69, 0x85, 0xfc, 0x08, 0xd6, 0xa6, 0x01, 0x25, 0x9c, 0xce, 0x4c, 0x19,
+	0x75, 0x4e, 0x1c, 0x5f, 0x86, 0xba, 0x84, 0xf5, 0x88, 0x98, 0xc4, 0x78, 0x8f, 0x00, 0xa4, 0x3e,
+	0x7c, 0xfb, 0x75, 0xb6, 0x61, 0xe5, 0x29, 0xb1, 0x43, 0x55, 0xe6, 0x1a, 0x56, 0x13, 0xf4, 0x2e,
+	0xd4, 0xd3, 0x93, 0x4a, 0xf2, 0xa4, 0x14, 0xe8, 0xfd, 0xad, 0x08, 0x90, 0xba, 0x8b, 0x3e, 0x81,
+	0x32, 0x3f, 0xf6, 0xa9, 0xa1, 0xc9, 0xe4, 0xeb, 0x5e, 0xf4, 0x49, 0x51, 0x0f, 0x98, 0x1c, 0xfb,
+	0x14, 0x4b, 0x63, 0x74, 0x1d, 0x6a, 0x0b, 0x6a, 0xfb, 0xc2, 0x2d, 0x79, 0x40, 0x13, 0x57, 0xc5,
+	0x5c, 0xd4, 0xe0, 0x75, 0xa8, 0x85, 0xae, 0xc5, 0x25, 0x55, 0x56, 0x94, 0x98, 0x8b, 0x74, 0xf9,
+	0x87, 0x06, 0x90, 0x6e, 0x85, 0xde, 0x81, 0x6b, 0xa3, 0xc1, 0x04, 0x0f, 0xef, 0x9a, 0x93, 0xc7,
+	0xfb, 0x03, 0xf3, 0x60, 0x77, 0xbc, 0x3f, 0xb8, 0x3b, 0xbc, 0x3f, 0x1c, 0xdc, 0xd3, 0x0b, 0xe8,
+	0x1a, 0xbc, 0x95, 0x25, 0xef, 0xee, 0x1d, 0xec, 0x4e, 0x06, 0x58, 0xd7, 0xd0, 0xdb, 0xb0, 0x96,
+	0x25, 0x1e, 0x6c, 0x1f, 0x3c, 0x18, 0xe8, 0x45, 0x74, 0x1d, 0xde, 0xce, 0xc2, 0x3b, 0xc3, 0xf1,
+	0x64, 0xef, 0x01, 0xde, 0x1e, 0xe9, 0x25, 0xd4, 0x81, 0xf5, 0xa5, 0x15, 0x29, 0x5f, 0x3e, 0x7f,
+	0xd4, 0xf8, 0x60, 0x34, 0xda, 0xc6, 0x8f, 0xf5, 0x15, 0xd4, 0x06, 0x3d, 0x4b, 0x0c, 0x77, 0xef,
+	0xef, 0xe9, 0x15, 0x64, 0x40, 0x3b, 0x67, 0x3e, 0xd9, 0x9e, 0x0c, 0xc6, 0x83, 0x89, 0x5e, 0xed,
+	0xfd, 0x59, 0x03, 0x34, 0xe6, 0x01, 0x25, 0x4e, 0xae, 0xbd, 0xaf, 0x43, 0x6d, 0x42, 0x5d, 0xe2,
+	0xf2, 0xe1, 0x3d, 0x79, 0xcb, 0x75, 0x9c, 0xcc, 0x45, 0x3d, 0x44, 0x66, 0x32, 0x84, 0xb9, 0x7e,
+	0x92, 0xdd, 0x04, 0xc7, 0x66, 0x71, 0x09, 0xbf, 0x7a, 0x43, 0x25, 0xfc, 0x87, 0x22, 0x34, 0xa3,
+	0x83, 0x98, 0xef, 0xb9, 0x8c, 0x22, 0x04, 0xe5, 0xa9, 0x37, 0x53, 0x09, 0xb1, 0x82, 0xe5, 0x58,
+	0xf4, 0x44, 0x47, 0xad, 0x97, 0x6e, 0xd6, 0x71, 0x3c, 0x15, 0xcc, 0x38, 0x2a, 0x68, 0x95, 0x69,
+	0xf1, 0x14, 0x75, 0x00, 0x76, 0xd2, 0xc2, 0x2d, 0x4b, 0x32, 0x83, 0x88, 0x2c, 0x1d, 0x24, 0x95,
+	0xb8, 0xa2, 0xb2, 0x34, 0x01, 0xd0, 0x0f, 0xe0, 0xca, 0xd4, 0x0f, 0xcd, 0x90, 0x5b, 0xb6, 0xf5,
+	0x85, 0x6a, 0x6e, 0x15, 0x99, 0xe3, 0xad, 0xa9, 0x1f, 0x1e, 0xa4, 0x28, 0xfa, 0x21, 0xe8, 0x0b,
+	0x4a, 0xfc, 0x9c, 0x65, 0x55, 0x5a, 0x5e, 0x11, 0x78, 0xd6, 0x74, 0x0b, 0xc0, 0xf6, 0xc8, 0xcc,
+	0x5c, 0x58, 0x2e, 0x67, 0x46, 0x6d, 0x43, 0xcb, 0xb7, 0x92, 0x87, 0x1e, 0x99, 0xed, 0x08, 0x0a,
+	0xd7, 0xed, 0x78, 0xd8, 0xfb, 0x7b, 0x11, 0xea, 0x09, 0x81, 0x7e, 0x0c, 0x57, 0x2d, 0x77, 0x6e,
+	0x5b, 0x47, 0x0b, 0x6e, 0xfa, 0x21, 0x5b, 0x98, 0x81, 0x8a, 0x0a, 0x93, 0xb7, 0x55, 0xc2, 0xed,
+	0x98, 0xdd, 0x0f, 0xd9, 0x22, 0x8a, 0x18, 0x43, 0x3f, 0x87, 0x75, 0x87, 0x3c, 0x33, 0x2f, 0x59,
+	0x59, 0x94, 0x2b, 0xaf, 0x39, 0xe4, 0xd9, 0xf0, 0xa2, 0xc5, 0x9b, 0xa0, 0x5b, 0xae, 0xe9, 0x50,
+	0xc7, 0x0b, 0x8e, 0xcd, 0x48, 0x7a, 0xd4, 0x4d, 0xb7, 0x2c, 0x77, 0x24, 0x61, 0xd5, 0x7e, 0xd1,
+	0x47, 0xd0, 0x56, 0xc7, 0x9c, 0xb3, 0x56, 0x57, 0xbf, 0x26, 0x0f, 0xc8, 0x2d, 0xf8, 0x1e, 0xb4,
+	0x2c, 0xf7, 0x88, 0x32, 0x71, 0x39, 0x66, 0x40, 0x38, 0x95, 0x61, 0xd0, 0x70, 0x33, 0x41, 0x31,
+	0xe1, 0x14, 0x7d, 0x08, 0x48, 0xed, 0x9b, 0x33, 0x55, 0xd1, 0xd0, 0xe5, 0xae, 0xe7, 0xac, 0x45,
+	0xfb, 0x75, 0x67, 0xa6, 0x4d, 0x38, 0x75, 0xa7, 0xc7, 0xa6, 0x7f, 0xeb, 0x56, 0x14, 0x11, 0x5d,
+	0x31, 0x0f, 0x15, 0xb1, 0x7f, 0xeb, 0x56, 0xef, 0xcb, 0x22, 0x40, 0xaa, 0x20, 0x68, 0x1b, 0x2a,
+	0xaa, 0xbb, 0x45, 0xaf, 0xa0, 0x6c, 0x74, 0x04, 0xbe, 0x4f, 0xac, 0xa0, 0xdf, 0x8e, 0x1a, 0x7d,
+	0x43, 0x42, 0xdb, 0x33, 0xe2, 0x73, 0x1a, 0xe0, 0x68, 0xe1, 0xff, 0xa0, 0x30, 0x9f, 0x66, 0x25,
+	0x41, 0x09, 0x0c, 0x5a, 0x96, 0x84, 0x65, 0x41, 0xc8, 0x2b, 0x53, 0xf9, 0xff, 0x54, 0xa6, 0xde,
+	0x4f, 0xa0, 0x9e, 0x7c, 0xa3, 0x28, 0x42, 0xa1, 0xed, 0x32, 0xad, 0x1a, 0x58, 0x8e, 0xf3, 0xcd,
+	0xbe, 0x11, 0x35, 0xfb, 0x9e, 0x07, 0x15, 0xf5, 0x59, 0x29, 0xaf, 0x65, 0xc5, 0xe0, 0x3d, 0x68,
+	0x24, 0xbd, 0xdf, 0x74, 0xe2, 0x74, 0x5b, 0x4d, 0xb0, 0x91, 0x78, 0x81, 0x20, 0xc6, 0x49, 0xc0,
+	0xcd, 0x9c, 0xa1, 0x4a, 0x32, 0x5d, 0x32, 0x93, 0xd4, 0xba, 0xf7, 0xfb, 0x22, 0xb4, 0xf2, 0x8f,
+	0x42, 0xf4, 0xd3, 0x9c, 0x86, 0xbc, 0x7f, 0xd9, 0xe3, 0x71, 0x59, 0x47, 0x44, 0x6a, 0x49, 0xcc,
+	0x9c, 0x13, 0xc7, 0xb2, 0x8f, 0xe5, 0x83, 0x26, 0x6a, 0x31, 0xba, 0x62, 0xee, 0x4b, 0x42, 0xbc,
+	0x63, 0xc4, 0xa5, 0x08, 0x95, 0x91, 0x09, 0x5d, 0xc7, 0x72, 0x2c, 0x30, 0x21, 0x2f, 0x32, 0x73,
+	0xeb, 0x58, 0x8e, 0x7b, 0xc7, 0x39, 0x99, 0x59, 0x85, 0xea, 0xc1, 0xee, 0x67, 0xbb, 0x7b, 0x8f,
+	0x76, 0xf5, 0x82, 0x98, 0xa4, 0x52, 0x52, 0x87, 0x95, 0x58, 0x3e, 0x9a, 0x50, 0xcf, 0x4a, 0x06,
+	0x82, 0xd6, 0x92, 0x4c, 0xac, 0x42, 0x35, 0x95, 0x86, 0x1a, 0x94, 0x23, 0x39, 0x68, 0x40, 0x2d,
+	0x23, 0x01, 0x9f, 0x41, 0x45, 0x1d, 0xfd, 0x06, 0x52, 0xb9, 0xf7, 0x1b, 0x0d, 0x6a, 0x71, 0xfa,
+	0xbd, 0x89, 0xd2, 0xb8, 0xf8, 0xb5, 0x70, 0x3e, 0x41, 0x4a, 0x4b, 0x09, 0xd2, 0xfb, 0xaa, 0x02,
+	0xf5, 0x24, 0x69, 0xd1, 0x0d, 0xa8, 0x4f, 0xbd, 0xd0, 0xe5, 0xa6, 0xe5, 0x72, 0x19, 0xf2, 0xf2,
+	0x4e, 0x01, 0xd7, 0x24, 0x34, 0x74, 0x39, 0x7a, 0x0f, 0x56, 0x15, 0x3d, 0xb7, 0x3d, 0xa2, 0x64,
+	0x4d, 0xdb, 0x29, 0x60, 0x90, 0xe0, 0x7d, 0x81, 0x21, 0x1d, 0x4a, 0x2c, 0x74, 0xe4, 0x49, 0x1a,
+	0x16, 0x43, 0x74, 0x15, 0x2a, 0x6c, 0xba, 0xa0, 0x0e, 0x91, 0xc1, 0x5d, 0xc3, 0xd1, 0x4c, 0xb4,
+	0xa8, 0x2f, 0x68, 0xe0, 0x99, 0x7c, 0x11, 0x50, 0xb6, 0xf0, 0xec, 0x59, 0xdc, 0xa2, 0x04, 0x3a,
+	0x89, 0x41, 0xf4, 0xfd, 0xc8, 0x2c, 0xf5, 0xab, 0x22, 0xfd, 0xd2, 0x70, 0x43, 0xe0, 0x77, 0x63,
+	0xdf, 0x3e, 0x00, 0x3d, 0x63, 0xa7, 0x1c, 0x94, 0xad, 0x69, 0x47, 0xc3, 0xad, 0xc4, 0x52, 0x39,
+	0xb9, 0x0d, 0x2d, 0x97, 0x1e, 0x11, 0x6e, 0x3d, 0xa5, 0x26, 0xf3, 0x89, 0x2b, 0x14, 0xe3, 0xdc,
+	0x73, 0xb1, 0x1f, 0x4e, 0x3f, 0xa7, 0x7c, 0xec, 0x13, 0x37, 0xea, 0x0e, 0xcd, 0x78, 0x85, 0xc0,
+	0xa4, 0x88, 0x25, 0x5b, 0xcc, 0xa8, 0xcd, 0x09, 0x33, 0xea, 0x1b, 0xa5, 0x4d, 0x84, 0x93, 0x9d,
+	0xef, 0x49, 0x34, 0x67, 0x28, 0x7d, 0x63, 0x06, 0x6c, 0x94, 0x84, 0xda, 0xc5, 0xb0, 0x74, 0x4c,
+	0x34, 0xc8, 0x96, 0xef, 0x31, 0x2b, 0xe3, 0xd4, 0xea, 0xb7, 0x3b, 0x15, 0xaf, 0x48, 0x9c, 0x4a,
+	0xb6, 0x88, 0x9c, 0x6a, 0x28, 0xa7, 0x62, 0x38, 0x75, 0x2a, 0x31, 0x8c, 0x9c, 0x6a, 0x2a, 0xa7,
+	0x62, 0x38, 0x72, 0xea, 0x0e, 0x40, 0x40, 0x19, 0xe5, 0x52, 0x58, 0x8d, 0x96, 0x6c, 0x02, 0x37,
+	0x2e, 0x68, 0x84, 0x37, 0xb1, 0xb0, 0x12, 0x4a, 0x8a, 0xeb, 0x41, 0x3c, 0x5c, 0xca, 0xbf, 0x2b,
+	0xcb, 0x0d, 0xea, 0x7d, 0x68, 0x4e, 0x43, 0xc6, 0x3d, 0xc7, 0x94, 0x29, 0xcb, 0x0c, 0x5d, 0xfa,
+	0xd1, 0x50, 0xe0, 0xa1, 0xc4, 0x2e, 0xe9, 0x62, 0x6b, 0x97, 0x74, 0xb1, 0xdb, 0x50, 0x4f, 0xbc,
+	0xc9, 0xb7, 0x88, 0x2a, 0x94, 0x1e, 0x0f, 0xc6, 0xba, 0x86, 0x2a, 0x50, 0xdc, 0xdd, 0xd3, 0x8b,
+	0x69, 0x9b, 0x28, 0xad, 0x97, 0xbf, 0xfc, 0xaa, 0xa3, 0xf5, 0xab, 0xb0, 0x22, 0xef, 0xa3, 0xdf,
+	0x00, 0x48, 0xd3, 0xa9, 0x77, 0x07, 0x20, 0xbd, 0x7b, 0x91, 0xd1, 0xde, 0x7c, 0xce, 0xa8, 0x2a,
+	0x91, 0x35, 0x1c, 0xcd, 0x04, 0x6e, 0x53, 0xf7, 0x88, 0x2f, 0x64, 0x65, 0x34, 0x71, 0x34, 0xfb,
+	0xa0, 0x0b, 0x90, 0xfe, 0xdc, 0x13, 0x4e, 0x6c, 0xef, 0x0f, 0xf5, 0x82, 0x68, 0x34, 0xf8, 0xe0,
+	0xe1, 0x40, 0xd7, 0xfa, 0xbf, 0x38, 0x79, 0xd1, 0x29, 0x7c, 0xfd, 0xa2, 0x53, 0x78, 0xfd, 0xa2,
+	0xa3, 0xfd, 0xfa, 0xac, 0xa3, 0xfd, 0xe9, 0xac, 0xa3, 0x3d, 0x3f, 0xeb, 0x68, 0x27, 0x67, 0x1d,
+	0xed, 0x9f, 0x67, 0x1d, 0xed, 0xd5, 0x59, 0xa7, 0xf0, 0xfa, 0xac, 0xa3, 0xfd, 0xee, 0x65, 0xa7,
+	0x70, 0xf2, 0xb2, 0x53, 0xf8, 0xfa, 0x65, 0xa7, 0xf0, 0xcb, 0xe4, 0x7f, 0x8a, 0x27, 0x15, 0xf9,
+	0xc7, 0xc4, 0x27, 0xff, 0x19, 0x00, 0x2b, 0x6f, 0xa1, 0xa4, 0xc8, 0x10, 0x00, 0x00,
 }
 
 func (x SourceEnum) String() string {
@@ -1801,6 +1941,57 @@ func (this *WriteResponse) Equal(that interface{}) bool {
 	if this.Exemplars != that1.Exemplars {
 		return false
 	}
+	if this.CpuUtilization != that1.CpuUtilization {
+		return false
+	}
+	if this.HeapUtilization != that1.HeapUtilization {
+		return false
+	}
+	if !this.LoadHints.Equal(that1.LoadHints) {
+		return false
+	}
+	return true
+}
+func (this *LoadHints) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*LoadHints)
+	if !ok {
+		that2, ok := that.(LoadHints)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.InflightPushRequests != that1.InflightPushRequests {
+		return false
+	}
+	if this.MaxInflightPushRequests != that1.MaxInflightPushRequests {
+		return false
+	}
+	if this.InMemorySeries != that1.InMemorySeries {
+		return false
+	}
+	if this.MaxInMemorySeries != that1.MaxInMemorySeries {
+		return false
+	}
+	if this.IngestionRate != that1.IngestionRate {
+		return false
+	}
+	if this.MaxIngestionRate != that1.MaxIngestionRate {
+		return false
+	}
+	if this.AppendLatencyP99 != that1.AppendLatencyP99 {
+		return false
+	}
 	return true
 }
 func (this *TimeSeries) Equal(that interface{}) bool {
@@ -2355,13 +2546,34 @@ func (this *WriteResponse) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 9)
+	s := make([]string, 0, 12)
 	s = append(s, "&cortexpb.WriteResponse{")
 	s = append(s, "Code: "+fmt.Sprintf("%#v", this.Code)+",\n")
 	s = append(s, "Message: "+fmt.Sprintf("%#v", this.Message)+",\n")
 	s = append(s, "Samples: "+fmt.Sprintf("%#v", this.Samples)+",\n")
 	s = append(s, "Histograms: "+fmt.Sprintf("%#v", this.Histograms)+",\n")
 	s = append(s, "Exemplars: "+fmt.Sprintf("%#v", this.Exemplars)+",\n")
+	s = append(s, "CpuUtilization: "+fmt.Sprintf("%#v", this.CpuUtilization)+",\n")
+	s = append(s, "HeapUtilization: "+fmt.Sprintf("%#v", this.HeapUtilization)+",\n")
+	if this.LoadHints != nil {
+		s = append(s, "LoadHints: "+fmt.Sprintf("%#v", this.LoadHints)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *LoadHints) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 11)
+	s = append(s, "&cortexpb.LoadHints{")
+	s = append(s, "InflightPushRequests: "+fmt.Sprintf("%#v", this.InflightPushRequests)+",\n")
+	s = append(s, "MaxInflightPushRequests: "+fmt.Sprintf("%#v", this.MaxInflightPushRequests)+",\n")
+	s = append(s, "InMemorySeries: "+fmt.Sprintf("%#v", this.InMemorySeries)+",\n")
+	s = append(s, "MaxInMemorySeries: "+fmt.Sprintf("%#v", this.MaxInMemorySeries)+",\n")
+	s = append(s, "IngestionRate: "+fmt.Sprintf("%#v", this.IngestionRate)+",\n")
+	s = append(s, "MaxIngestionRate: "+fmt.Sprintf("%#v", this.MaxIngestionRate)+",\n")
+	s = append(s, "AppendLatencyP99: "+fmt.Sprintf("%#v", this.AppendLatencyP99)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -2991,6 +3203,30 @@ func (m *WriteResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.LoadHints != nil {
+		{
+			size, err := m.LoadHints.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintCortex(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x42
+	}
+	if m.HeapUtilization != 0 {
+		i -= 8
+		encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(math.Float64bits(float64(m.HeapUtilization))))
+		i--
+		dAtA[i] = 0x39
+	}
+	if m.CpuUtilization != 0 {
+		i -= 8
+		encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(math.Float64bits(float64(m.CpuUtilization))))
+		i--
+		dAtA[i] = 0x31
+	}
 	if m.Exemplars != 0 {
 		i = encodeVarintCortex(dAtA, i, uint64(m.Exemplars))
 		i--
@@ -3021,6 +3257,67 @@ func (m *WriteResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *LoadHints) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *LoadHints) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *LoadHints) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.AppendLatencyP99 != 0 {
+		i -= 8
+		encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(math.Float64bits(float64(m.AppendLatencyP99))))
+		i--
+		dAtA[i] = 0x39
+	}
+	if m.MaxIngestionRate != 0 {
+		i -= 8
+		encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(math.Float64bits(float64(m.MaxIngestionRate))))
+		i--
+		dAtA[i] = 0x31
+	}
+	if m.IngestionRate != 0 {
+		i -= 8
+		encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(math.Float64bits(float64(m.IngestionRate))))
+		i--
+		dAtA[i] = 0x29
+	}
+	if m.MaxInMemorySeries != 0 {
+		i = encodeVarintCortex(dAtA, i, uint64(m.MaxInMemorySeries))
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.InMemorySeries != 0 {
+		i = encodeVarintCortex(dAtA, i, uint64(m.InMemorySeries))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.MaxInflightPushRequests != 0 {
+		i = encodeVarintCortex(dAtA, i, uint64(m.MaxInflightPushRequests))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.InflightPushRequests != 0 {
+		i = encodeVarintCortex(dAtA, i, uint64(m.InflightPushRequests))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
 func (m *TimeSeries) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -3339,9 +3636,9 @@ func (m *Histogram) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	}
 	if len(m.CustomValues) > 0 {
 		for iNdEx := len(m.CustomValues) - 1; iNdEx >= 0; iNdEx-- {
-			f10 := math.Float64bits(float64(m.CustomValues[iNdEx]))
+			f11 := math.Float64bits(float64(m.CustomValues[iNdEx]))
 			i -= 8
-			encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(f10))
+			encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(f11))
 		}
 		i = encodeVarintCortex(dAtA, i, uint64(len(m.CustomValues)*8))
 		i--
@@ -3361,30 +3658,30 @@ func (m *Histogram) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	}
 	if len(m.PositiveCounts) > 0 {
 		for iNdEx := len(m.PositiveCounts) - 1; iNdEx >= 0; iNdEx-- {
-			f11 := math.Float64bits(float64(m.PositiveCounts[iNdEx]))
+			f12 := math.Float64bits(float64(m.PositiveCounts[iNdEx]))
 			i -= 8
-			encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(f11))
+			encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(f12))
 		}
 		i = encodeVarintCortex(dAtA, i, uint64(len(m.PositiveCounts)*8))
 		i--
 		dAtA[i] = 0x6a
 	}
 	if len(m.PositiveDeltas) > 0 {
-		var j12 int
-		dAtA14 := make([]byte, len(m.PositiveDeltas)*10)
+		var j13 int
+		dAtA15 := make([]byte, len(m.PositiveDeltas)*10)
 		for _, num := range m.PositiveDeltas {
-			x13 := (uint64(num) << 1) ^ uint64((num >> 63))
-			for x13 >= 1<<7 {
-				dAtA14[j12] = uint8(uint64(x13)&0x7f | 0x80)
-				j12++
-				x13 >>= 7
-			}
-			dAtA14[j12] = uint8(x13)
-			j12++
-		}
-		i -= j12
-		copy(dAtA[i:], dAtA14[:j12])
-		i = encodeVarintCortex(dAtA, i, uint64(j12))
+			x14 := (uint64(num) << 1) ^ uint64((num >> 63))
+			for x14 >= 1<<7 {
+				dAtA15[j13] = uint8(uint64(x14)&0x7f | 0x80)
+				j13++
+				x14 >>= 7
+			}
+			dAtA15[j13] = uint8(x14)
+			j13++
+		}
+		i -= j13
+		copy(dAtA[i:], dAtA15[:j13])
+		i = encodeVarintCortex(dAtA, i, uint64(j13))
 		i--
 		dAtA[i] = 0x62
 	}
@@ -3404,30 +3701,30 @@ func (m *Histogram) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	}
 	if len(m.NegativeCounts) > 0 {
 		for iNdEx := len(m.NegativeCounts) - 1; iNdEx >= 0; iNdEx-- {
-			f15 := math.Float64bits(float64(m.NegativeCounts[iNdEx]))
+			f16 := math.Float64bits(float64(m.NegativeCounts[iNdEx]))
 			i -= 8
-			encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(f15))
+			encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(f16))
 		}
 		i = encodeVarintCortex(dAtA, i, uint64(len(m.NegativeCounts)*8))
 		i--
 		dAtA[i] = 0x52
 	}
 	if len(m.NegativeDeltas) > 0 {
-		var j16 int
-		dAtA18 := make([]byte, len(m.NegativeDeltas)*10)
+		var j17 int
+		dAtA19 := make([]byte, len(m.NegativeDeltas)*10)
 		for _, num := range m.NegativeDeltas {
-			x17 := (uint64(num) << 1) ^ uint64((num >> 63))
-			for x17 >= 1<<7 {
-				dAtA18[j16] = uint8(uint64(x17)&0x7f | 0x80)
-				j16++
-				x17 >>= 7
-			}
-			dAtA18[j16] = uint8(x17)
-			j16++
-		}
-		i -= j16
-		copy(dAtA[i:], dAtA18[:j16])
-		i = encodeVarintCortex(dAtA, i, uint64(j16))
+			x18 := (uint64(num) << 1) ^ uint64((num >> 63))
+			for x18 >= 1<<7 {
+				dAtA19[j17] = uint8(uint64(x18)&0x7f | 0x80)
+				j17++
+				x18 >>= 7
+			}
+			dAtA19[j17] = uint8(x18)
+			j17++
+		}
+		i -= j17
+		copy(dAtA[i:], dAtA19[:j17])
+		i = encodeVarintCortex(dAtA, i, uint64(j17))
 		i--
 		dAtA[i] = 0x4a
 	}
@@ -3763,6 +4060,46 @@ func (m *WriteResponse) Size() (n int) {
 	if m.Exemplars != 0 {
 		n += 1 + sovCortex(uint64(m.Exemplars))
 	}
+	if m.CpuUtilization != 0 {
+		n += 9
+	}
+	if m.HeapUtilization != 0 {
+		n += 9
+	}
+	if m.LoadHints != nil {
+		l = m.LoadHints.Size()
+		n += 1 + l + sovCortex(uint64(l))
+	}
+	return n
+}
+
+func (m *LoadHints) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.InflightPushRequests != 0 {
+		n += 1 + sovCortex(uint64(m.InflightPushRequests))
+	}
+	if m.MaxInflightPushRequests != 0 {
+		n += 1 + sovCortex(uint64(m.MaxInflightPushRequests))
+	}
+	if m.InMemorySeries != 0 {
+		n += 1 + sovCortex(uint64(m.InMemorySeries))
+	}
+	if m.MaxInMemorySeries != 0 {
+		n += 1 + sovCortex(uint64(m.MaxInMemorySeries))
+	}
+	if m.IngestionRate != 0 {
+		n += 9
+	}
+	if m.MaxIngestionRate != 0 {
+		n += 9
+	}
+	if m.AppendLatencyP99 != 0 {
+		n += 9
+	}
 	return n
 }
 
@@ -4133,6 +4470,25 @@ func (this *WriteResponse) String() string {
 		`Samples:` + fmt.Sprintf("%v", this.Samples) + `,`,
 		`Histograms:` + fmt.Sprintf("%v", this.Histograms) + `,`,
 		`Exemplars:` + fmt.Sprintf("%v", this.Exemplars) + `,`,
+		`CpuUtilization:` + fmt.Sprintf("%v", this.CpuUtilization) + `,`,
+		`HeapUtilization:` + fmt.Sprintf("%v", this.HeapUtilization) + `,`,
+		`LoadHints:` + strings.Replace(this.LoadHints.String(), "LoadHints", "LoadHints", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *LoadHints) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&LoadHints{`,
+		`InflightPushRequests:` + fmt.Sprintf("%v", this.InflightPushRequests) + `,`,
+		`MaxInflightPushRequests:` + fmt.Sprintf("%v", this.MaxInflightPushRequests) + `,`,
+		`InMemorySeries:` + fmt.Sprintf("%v", this.InMemorySeries) + `,`,
+		`MaxInMemorySeries:` + fmt.Sprintf("%v", this.MaxInMemorySeries) + `,`,
+		`IngestionRate:` + fmt.Sprintf("%v", this.IngestionRate) + `,`,
+		`MaxIngestionRate:` + fmt.Sprintf("%v", this.MaxIngestionRate) + `,`,
+		`AppendLatencyP99:` + fmt.Sprintf("%v", this.AppendLatencyP99) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -5611,6 +5967,226 @@ func (m *WriteResponse) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 6:
+			if wireType != 1 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CpuUtilization", wireType)
+			}
+			var v uint64
+			if (iNdEx + 8) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+			iNdEx += 8
+			m.CpuUtilization = float64(math.Float64frombits(v))
+		case 7:
+			if wireType != 1 {
+				return fmt.Errorf("proto: wrong wireType = %d for field HeapUtilization", wireType)
+			}
+			var v uint64
+			if (iNdEx + 8) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+			iNdEx += 8
+			m.HeapUtilization = float64(math.Float64frombits(v))
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LoadHints", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCortex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthCortex
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthCortex
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.LoadHints == nil {
+				m.LoadHints = &LoadHints{}
+			}
+			if err := m.LoadHints.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipCortex(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthCortex
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthCortex
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *LoadHints) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowCortex
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: LoadHints: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: LoadHints: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field InflightPushRequests", wireType)
+			}
+			m.InflightPushRequests = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCortex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.InflightPushRequests |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxInflightPushRequests", wireType)
+			}
+			m.MaxInflightPushRequests = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCortex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxInflightPushRequests |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field InMemorySeries", wireType)
+			}
+			m.InMemorySeries = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCortex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.InMemorySeries |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxInMemorySeries", wireType)
+			}
+			m.MaxInMemorySeries = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowCortex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxInMemorySeries |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 1 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IngestionRate", wireType)
+			}
+			var v uint64
+			if (iNdEx + 8) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+			iNdEx += 8
+			m.IngestionRate = float64(math.Float64frombits(v))
+		case 6:
+			if wireType != 1 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxIngestionRate", wireType)
+			}
+			var v uint64
+			if (iNdEx + 8) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+			iNdEx += 8
+			m.MaxIngestionRate = float64(math.Float64frombits(v))
+		case 7:
+			if wireType != 1 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AppendLatencyP99", wireType)
+			}
+			var v uint64
+			if (iNdEx + 8) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+			iNdEx += 8
+			m.AppendLatencyP99 = float64(math.Float64frombits(v))
 		default:
 			iNdEx = preIndex
 			skippy, err := skipCortex(dAtA[iNdEx:])
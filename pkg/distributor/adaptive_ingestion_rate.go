@@ -0,0 +1,393 @@
+package distributor
+
+import (
+	"context"
+	"flag"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/weaveworks/common/httpgrpc"
+	"go.uber.org/atomic"
+	"golang.org/x/time/rate"
+
+	"github.com/cortexproject/cortex/pkg/cortexpb"
+	"github.com/cortexproject/cortex/pkg/util/limiter"
+	"github.com/cortexproject/cortex/pkg/util/services"
+)
+
+const (
+	// The utilization reported by an ingester is ignored if it hasn't pushed a response
+	// within this number of update intervals.
+	adaptiveIngestionRateStaleIntervals = 3
+
+	// Factor by which the lowered limits are raised at each update once the ingesters
+	// aren't under pressure anymore.
+	adaptiveIngestionRateRecoveryFactor = 1.25
+
+	// Weight of the latest utilization reported by an ingester in its moving average.
+	adaptiveIngestionRateSmoothingWeight = 0.5
+)
+
+var (
+	errInvalidAdaptiveIngestionRateThreshold      = errors.New("invalid adaptive ingestion rate utilization threshold, it must be between 0 and 1")
+	errMissingAdaptiveIngestionRateThreshold      = errors.New("at least one of the adaptive ingestion rate CPU and heap utilization thresholds must be set")
+	errInvalidAdaptiveIngestionRateMinRateFactor  = errors.New("invalid adaptive ingestion rate min rate factor, it must be greater than 0 and lower than or equal to 1")
+	errInvalidAdaptiveIngestionRateUpdateInterval = errors.New("invalid adaptive ingestion rate update interval, it must be greater than 0")
+)
+
+// AdaptiveIngestionRateConfig configures the lowering of the tenants ingestion rate limits
+// while the ingesters are under pressure.
+type AdaptiveIngestionRateConfig struct {
+	Enabled                  bool          `yaml:"enabled"`
+	CPUUtilizationThreshold  float64       `yaml:"cpu_utilization_threshold"`
+	HeapUtilizationThreshold float64       `yaml:"heap_utilization_threshold"`
+	MinRateFactor            float64       `yaml:"min_rate_factor"`
+	UpdateInterval           time.Duration `yaml:"update_interval"`
+}
+
+// RegisterFlags adds the flags required to config this to the given FlagSet
+func (cfg *AdaptiveIngestionRateConfig) RegisterFlags(f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, "distributor.adaptive-ingestion-rate.enabled", false, "EXPERIMENTAL: If enabled, the distributor lowers the ingestion rate limits of the heaviest tenants first while the CPU or heap utilization reported by the ingesters in the push responses is above the thresholds, and sends a Retry-After header with the rate limited requests. The ingesters report their utilization only if -resource-monitor.resources is set.")
+	f.Float64Var(&cfg.CPUUtilizationThreshold, "distributor.adaptive-ingestion-rate.cpu-utilization-threshold", 0.8, "EXPERIMENTAL: CPU utilization of the most loaded ingester, between 0 and 1, above which the ingestion rate limits are lowered. The limits are lowered proportionally to the utilization above the threshold. 0 to ignore the CPU utilization.")
+	f.Float64Var(&cfg.HeapUtilizationThreshold, "distributor.adaptive-ingestion-rate.heap-utilization-threshold", 0.8, "EXPERIMENTAL: Heap utilization of the most loaded ingester, between 0 and 1, above which the ingestion rate limits are lowered. The limits are lowered proportionally to the utilization above the threshold. 0 to ignore the heap utilization.")
+	f.Float64Var(&cfg.MinRateFactor, "distributor.adaptive-ingestion-rate.min-rate-factor", 0.2, "EXPERIMENTAL: The ingestion rate limit of a tenant is never lowered below this fraction of its configured limit.")
+	f.DurationVar(&cfg.UpdateInterval, "distributor.adaptive-ingestion-rate.update-interval", 10*time.Second, "EXPERIMENTAL: Interval at which the ingestion rate limits are lowered or raised back, based on the ingesters utilization. It's also the Retry-After delay sent to the clients.")
+}
+
+// Validate config and returns error on failure
+func (cfg *AdaptiveIngestionRateConfig) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	for _, threshold := range []float64{cfg.CPUUtilizationThreshold, cfg.HeapUtilizationThreshold} {
+		if threshold < 0 || threshold >= 1 {
+			return errInvalidAdaptiveIngestionRateThreshold
+		}
+	}
+	if cfg.CPUUtilizationThreshold == 0 && cfg.HeapUtilizationThreshold == 0 {
+		return errMissingAdaptiveIngestionRateThreshold
+	}
+	if cfg.MinRateFactor <= 0 || cfg.MinRateFactor > 1 {
+		return errInvalidAdaptiveIngestionRateMinRateFactor
+	}
+	if cfg.UpdateInterval <= 0 {
+		return errInvalidAdaptiveIngestionRateUpdateInterval
+	}
+	return nil
+}
+
+// ingesterUtilization is the exponentially weighted moving average of the utilization
+// reported by an ingester. It's updated lock-free on every push response.
+type ingesterUtilization struct {
+	cpu, heap atomic.Float64
+
+	// Unix nanoseconds of the latest report.
+	reportedAt atomic.Int64
+}
+
+// observe adds the reported utilization to the moving average. The average is reset if the
+// previous report is stale.
+func (u *ingesterUtilization) observe(cpu, heap float64, now time.Time, staleAfter time.Duration) {
+	prev := u.reportedAt.Swap(now.UnixNano())
+	reset := prev == 0 || now.Sub(time.Unix(0, prev)) > staleAfter
+
+	smooth := func(v *atomic.Float64, sample float64) {
+		if reset {
+			v.Store(sample)
+			return
+		}
+		for {
+			old := v.Load()
+			if v.CompareAndSwap(old, old+adaptiveIngestionRateSmoothingWeight*(sample-old)) {
+				return
+			}
+		}
+	}
+	smooth(&u.cpu, cpu)
+	smooth(&u.heap, heap)
+}
+
+type adaptiveTenant struct {
+	// Samples accepted since the last update.
+	samples atomic.Int64
+
+	// Lowered limit, or 0 if the configured limit applies. Guarded by the adaptiveIngestionRate mutex.
+	limit float64
+}
+
+// adaptiveIngestionRate is an ingestion rate strategy lowering the limits of the wrapped strategy
+// while the ingesters are under pressure. When the utilization of the most loaded ingester is
+// above the threshold, the total ingestion rate observed by the distributor is lowered
+// proportionally to the utilization above the threshold, by capping the rate of the heaviest
+// tenants first. The lowered limits are raised back gradually once the pressure is gone.
+type adaptiveIngestionRate struct {
+	services.Service
+
+	cfg      AdaptiveIngestionRateConfig
+	strategy limiter.RateLimiterStrategy
+	logger   log.Logger
+
+	// Utilization per ingester address, updated without taking the mutex.
+	ingesters sync.Map // map[string]*ingesterUtilization
+
+	mtx     sync.RWMutex
+	tenants map[string]*adaptiveTenant
+	lastRun time.Time
+
+	pressure      prometheus.Gauge
+	loweredLimits *prometheus.GaugeVec
+}
+
+func newAdaptiveIngestionRate(cfg AdaptiveIngestionRateConfig, strategy limiter.RateLimiterStrategy, reg prometheus.Registerer, logger log.Logger) *adaptiveIngestionRate {
+	a := &adaptiveIngestionRate{
+		cfg:      cfg,
+		strategy: strategy,
+		logger:   logger,
+		tenants:  map[string]*adaptiveTenant{},
+
+		pressure: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name: "cortex_distributor_adaptive_ingestion_rate_pressure",
+			Help: "Utilization of the most loaded ingester above the adaptive ingestion rate threshold, between 0 and 1. The ingestion rate limits are lowered while it's greater than 0.",
+		}),
+		loweredLimits: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cortex_distributor_adaptive_ingestion_rate_limit",
+			Help: "Ingestion rate limit in samples per second lowered because the ingesters are under pressure, per user.",
+		}, []string{"user"}),
+	}
+
+	a.Service = services.NewTimerService(cfg.UpdateInterval, a.starting, a.iteration, nil)
+	return a
+}
+
+func (a *adaptiveIngestionRate) starting(_ context.Context) error {
+	a.lastRun = time.Now()
+	return nil
+}
+
+func (a *adaptiveIngestionRate) iteration(_ context.Context) error {
+	a.update(time.Now())
+	return nil
+}
+
+// Limit implements limiter.RateLimiterStrategy.
+func (a *adaptiveIngestionRate) Limit(tenantID string) float64 {
+	limit := a.strategy.Limit(tenantID)
+	if lowered := a.loweredLimit(tenantID); lowered > 0 && lowered < limit {
+		return lowered
+	}
+	return limit
+}
+
+// Burst implements limiter.RateLimiterStrategy.
+func (a *adaptiveIngestionRate) Burst(tenantID string) int {
+	return a.strategy.Burst(tenantID)
+}
+
+func (a *adaptiveIngestionRate) loweredLimit(tenantID string) float64 {
+	if a == nil {
+		return 0
+	}
+
+	a.mtx.RLock()
+	defer a.mtx.RUnlock()
+
+	if t, ok := a.tenants[tenantID]; ok {
+		return t.limit
+	}
+	return 0
+}
+
+// observe records the samples of the tenant accepted by the distributor.
+func (a *adaptiveIngestionRate) observe(tenantID string, samples int) {
+	if a == nil {
+		return
+	}
+
+	a.mtx.RLock()
+	t, ok := a.tenants[tenantID]
+	a.mtx.RUnlock()
+
+	if !ok {
+		a.mtx.Lock()
+		if t, ok = a.tenants[tenantID]; !ok {
+			t = &adaptiveTenant{}
+			a.tenants[tenantID] = t
+		}
+		a.mtx.Unlock()
+	}
+
+	t.samples.Add(int64(samples))
+}
+
+// observeIngester records the utilization reported by the ingester in the push response.
+// It's called for every push response, so it doesn't take the mutex.
+func (a *adaptiveIngestionRate) observeIngester(addr string, resp *cortexpb.WriteResponse, now time.Time) {
+	if a == nil || resp == nil {
+		return
+	}
+
+	u, ok := a.ingesters.Load(addr)
+	if !ok {
+		u, _ = a.ingesters.LoadOrStore(addr, &ingesterUtilization{})
+	}
+	u.(*ingesterUtilization).observe(resp.CpuUtilization, resp.HeapUtilization, now, a.staleAfter())
+}
+
+// staleAfter returns the duration after which the utilization reported by an ingester is ignored.
+func (a *adaptiveIngestionRate) staleAfter() time.Duration {
+	return adaptiveIngestionRateStaleIntervals * a.cfg.UpdateInterval
+}
+
+// update lowers or raises back the tenants limits based on the current ingesters pressure,
+// and the tenants ingestion rate since the last update.
+func (a *adaptiveIngestionRate) update(now time.Time) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	elapsed := now.Sub(a.lastRun).Seconds()
+	a.lastRun = now
+	if elapsed <= 0 {
+		return
+	}
+
+	pressure := a.currentPressure(now)
+	a.pressure.Set(pressure)
+
+	type tenantRate struct {
+		id   string
+		rate float64
+	}
+	rates := make([]tenantRate, 0, len(a.tenants))
+	total := 0.0
+	for id, t := range a.tenants {
+		rate := float64(t.samples.Swap(0)) / elapsed
+		rates = append(rates, tenantRate{id: id, rate: rate})
+		total += rate
+	}
+
+	if pressure == 0 {
+		for _, r := range rates {
+			t := a.tenants[r.id]
+			if t.limit == 0 {
+				continue
+			}
+			t.limit *= adaptiveIngestionRateRecoveryFactor
+			if t.limit >= a.strategy.Limit(r.id) {
+				t.limit = 0
+				a.loweredLimits.DeleteLabelValues(r.id)
+				level.Info(a.logger).Log("msg", "ingestion rate limit restored", "user", r.id)
+				continue
+			}
+			a.loweredLimits.WithLabelValues(r.id).Set(t.limit)
+		}
+		return
+	}
+
+	// Find the rate cap which lowers the total rate by the pressure, when applied to the
+	// heaviest tenants.
+	slices.SortFunc(rates, func(a, b tenantRate) int {
+		switch {
+		case a.rate > b.rate:
+			return -1
+		case a.rate < b.rate:
+			return 1
+		default:
+			return 0
+		}
+	})
+	excess := total * pressure
+	rateCap := 0.0
+	heaviest := 0
+	sum := 0.0
+	for heaviest < len(rates) {
+		sum += rates[heaviest].rate
+		heaviest++
+		rateCap = max(0, (sum-excess)/float64(heaviest))
+		if heaviest == len(rates) || rateCap >= rates[heaviest].rate {
+			break
+		}
+	}
+
+	for _, r := range rates[:heaviest] {
+		if r.rate <= rateCap {
+			continue
+		}
+		// The rate limiter ignores the limits of the tenants which aren't rate limited.
+		base := a.strategy.Limit(r.id)
+		if base == float64(rate.Inf) {
+			continue
+		}
+		limit := max(rateCap, a.cfg.MinRateFactor*base)
+		if limit >= base {
+			continue
+		}
+
+		t := a.tenants[r.id]
+		if t.limit == 0 {
+			level.Warn(a.logger).Log("msg", "lowering ingestion rate limit because the ingesters are under pressure", "user", r.id, "rate", r.rate, "limit", limit, "pressure", pressure)
+		}
+		t.limit = limit
+		a.loweredLimits.WithLabelValues(r.id).Set(limit)
+	}
+}
+
+// currentPressure returns how much the utilization of the most loaded ingester is above
+// the threshold, between 0 and 1. Must be called with the mutex held.
+func (a *adaptiveIngestionRate) currentPressure(now time.Time) float64 {
+	pressureOf := func(utilization, threshold float64) float64 {
+		if threshold <= 0 || utilization <= threshold {
+			return 0
+		}
+		return min(1, (utilization-threshold)/(1-threshold))
+	}
+
+	pressure := 0.0
+	a.ingesters.Range(func(addr, v any) bool {
+		u := v.(*ingesterUtilization)
+		if now.Sub(time.Unix(0, u.reportedAt.Load())) > a.staleAfter() {
+			a.ingesters.CompareAndDelete(addr, u)
+			return true
+		}
+		pressure = max(pressure, pressureOf(u.cpu.Load(), a.cfg.CPUUtilizationThreshold), pressureOf(u.heap.Load(), a.cfg.HeapUtilizationThreshold))
+		return true
+	})
+	return pressure
+}
+
+// rateLimitedError returns the error of the requests exceeding the ingestion rate limit of the
+// tenant, with a Retry-After header if the limit is lowered because the ingesters are under pressure.
+func (a *adaptiveIngestionRate) rateLimitedError(tenantID, msg string) error {
+	if a.loweredLimit(tenantID) == 0 {
+		return httpgrpc.Errorf(http.StatusTooManyRequests, "%s", msg)
+	}
+
+	retryAfter := int(math.Ceil(a.cfg.UpdateInterval.Seconds()))
+	return httpgrpc.ErrorFromHTTPResponse(&httpgrpc.HTTPResponse{
+		Code: http.StatusTooManyRequests,
+		Body: []byte(msg + ", the limit is lowered because the ingesters are under pressure"),
+		Headers: []*httpgrpc.Header{
+			{Key: "Retry-After", Values: []string{strconv.Itoa(retryAfter)}},
+		},
+	})
+}
+
+func (a *adaptiveIngestionRate) cleanupInactiveUser(userID string) {
+	if a == nil {
+		return
+	}
+
+	a.mtx.Lock()
+	delete(a.tenants, userID)
+	a.mtx.Unlock()
+
+	a.loweredLimits.DeleteLabelValues(userID)
+}
@@ -0,0 +1,258 @@
+package distributor
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/httpgrpc"
+
+	"github.com/cortexproject/cortex/pkg/cortexpb"
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+)
+
+type staticIngestionRateStrategy struct {
+	limit float64
+}
+
+func (s *staticIngestionRateStrategy) Limit(string) float64 {
+	return s.limit
+}
+
+func (s *staticIngestionRateStrategy) Burst(string) int {
+	return int(s.limit)
+}
+
+func TestAdaptiveIngestionRateConfig_Validate(t *testing.T) {
+	cfg := AdaptiveIngestionRateConfig{}
+	flagext.DefaultValues(&cfg)
+	require.NoError(t, cfg.Validate())
+
+	cfg.Enabled = true
+	require.NoError(t, cfg.Validate())
+
+	cfg.CPUUtilizationThreshold = 1
+	require.Equal(t, errInvalidAdaptiveIngestionRateThreshold, cfg.Validate())
+
+	cfg.CPUUtilizationThreshold = 0
+	require.NoError(t, cfg.Validate())
+
+	cfg.HeapUtilizationThreshold = 0
+	require.Equal(t, errMissingAdaptiveIngestionRateThreshold, cfg.Validate())
+
+	flagext.DefaultValues(&cfg)
+	cfg.Enabled = true
+	cfg.MinRateFactor = 0
+	require.Equal(t, errInvalidAdaptiveIngestionRateMinRateFactor, cfg.Validate())
+
+	flagext.DefaultValues(&cfg)
+	cfg.Enabled = true
+	cfg.UpdateInterval = 0
+	require.Equal(t, errInvalidAdaptiveIngestionRateUpdateInterval, cfg.Validate())
+}
+
+func TestAdaptiveIngestionRate(t *testing.T) {
+	cfg := AdaptiveIngestionRateConfig{}
+	flagext.DefaultValues(&cfg)
+	cfg.Enabled = true
+
+	reg := prometheus.NewPedanticRegistry()
+	a := newAdaptiveIngestionRate(cfg, &staticIngestionRateStrategy{limit: 100}, reg, log.NewNopLogger())
+	now := time.Now()
+	a.lastRun = now
+
+	observe := func(samples map[string]int) {
+		for userID, n := range samples {
+			a.observe(userID, n)
+		}
+	}
+
+	// The limits aren't lowered while the ingesters aren't under pressure.
+	observe(map[string]int{"user-1": 800, "user-2": 150, "user-3": 50})
+	a.observeIngester("ingester-1", &cortexpb.WriteResponse{CpuUtilization: 0.5, HeapUtilization: 0.7}, now)
+	now = now.Add(cfg.UpdateInterval)
+	a.update(now)
+
+	for _, userID := range []string{"user-1", "user-2", "user-3"} {
+		assert.Equal(t, 100.0, a.Limit(userID))
+	}
+	assert.Equal(t, 100, a.Burst("user-1"))
+
+	// The most loaded ingester is half way between the threshold and full utilization, so the
+	// total rate (100/s) is halved by capping the heaviest tenant.
+	observe(map[string]int{"user-1": 800, "user-2": 150, "user-3": 50})
+	a.observeIngester("ingester-1", &cortexpb.WriteResponse{CpuUtilization: 0.5, HeapUtilization: 0.7}, now)
+	a.observeIngester("ingester-2", &cortexpb.WriteResponse{CpuUtilization: 0.9, HeapUtilization: 0.7}, now)
+	now = now.Add(cfg.UpdateInterval)
+	a.update(now)
+
+	assert.Equal(t, 30.0, a.Limit("user-1"))
+	assert.Equal(t, 100.0, a.Limit("user-2"))
+	assert.Equal(t, 100.0, a.Limit("user-3"))
+
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+		# HELP cortex_distributor_adaptive_ingestion_rate_limit Ingestion rate limit in samples per second lowered because the ingesters are under pressure, per user.
+		# TYPE cortex_distributor_adaptive_ingestion_rate_limit gauge
+		cortex_distributor_adaptive_ingestion_rate_limit{user="user-1"} 30
+		# HELP cortex_distributor_adaptive_ingestion_rate_pressure Utilization of the most loaded ingester above the adaptive ingestion rate threshold, between 0 and 1. The ingestion rate limits are lowered while it's greater than 0.
+		# TYPE cortex_distributor_adaptive_ingestion_rate_pressure gauge
+		cortex_distributor_adaptive_ingestion_rate_pressure 0.5
+	`)))
+
+	// The rate limited requests of the tenants with a lowered limit get a Retry-After hint.
+	resp, ok := httpgrpc.HTTPResponseFromError(a.rateLimitedError("user-1", "ingestion rate limit exceeded"))
+	require.True(t, ok)
+	assert.Equal(t, int32(http.StatusTooManyRequests), resp.Code)
+	assert.Equal(t, "ingestion rate limit exceeded, the limit is lowered because the ingesters are under pressure", string(resp.Body))
+	assert.Equal(t, []*httpgrpc.Header{{Key: "Retry-After", Values: []string{"10"}}}, resp.Headers)
+
+	resp, ok = httpgrpc.HTTPResponseFromError(a.rateLimitedError("user-2", "ingestion rate limit exceeded"))
+	require.True(t, ok)
+	assert.Equal(t, int32(http.StatusTooManyRequests), resp.Code)
+	assert.Empty(t, resp.Headers)
+
+	// The limits are never lowered below the min rate factor.
+	observe(map[string]int{"user-1": 300, "user-2": 150, "user-3": 50})
+	a.observeIngester("ingester-3", &cortexpb.WriteResponse{CpuUtilization: 0.5, HeapUtilization: 0.98}, now)
+	now = now.Add(cfg.UpdateInterval)
+	a.update(now)
+
+	assert.Equal(t, 20.0, a.Limit("user-1"))
+	assert.Equal(t, 20.0, a.Limit("user-2"))
+	assert.Equal(t, 20.0, a.Limit("user-3"))
+
+	// The limits are raised back gradually once the moving average of the utilization reported
+	// by the ingesters is below the thresholds.
+	a.observeIngester("ingester-2", &cortexpb.WriteResponse{CpuUtilization: 0.5, HeapUtilization: 0.5}, now)
+	a.observeIngester("ingester-3", &cortexpb.WriteResponse{CpuUtilization: 0.5, HeapUtilization: 0.5}, now)
+	now = now.Add(cfg.UpdateInterval)
+	a.update(now)
+
+	assert.Equal(t, 25.0, a.Limit("user-1"))
+	assert.Equal(t, 25.0, a.Limit("user-2"))
+	assert.Equal(t, 25.0, a.Limit("user-3"))
+
+	// Stale utilization reports are ignored.
+	a.observeIngester("ingester-2", &cortexpb.WriteResponse{CpuUtilization: 1, HeapUtilization: 1}, now.Add(-adaptiveIngestionRateStaleIntervals*cfg.UpdateInterval))
+	for range 7 {
+		now = now.Add(cfg.UpdateInterval)
+		a.update(now)
+	}
+
+	for _, userID := range []string{"user-1", "user-2", "user-3"} {
+		assert.Equal(t, 100.0, a.Limit(userID))
+	}
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+		# HELP cortex_distributor_adaptive_ingestion_rate_pressure Utilization of the most loaded ingester above the adaptive ingestion rate threshold, between 0 and 1. The ingestion rate limits are lowered while it's greater than 0.
+		# TYPE cortex_distributor_adaptive_ingestion_rate_pressure gauge
+		cortex_distributor_adaptive_ingestion_rate_pressure 0
+	`)))
+
+	a.cleanupInactiveUser("user-1")
+	assert.NotContains(t, a.tenants, "user-1")
+}
+
+func TestAdaptiveIngestionRate_ShouldSmoothTheIngestersUtilization(t *testing.T) {
+	cfg := AdaptiveIngestionRateConfig{}
+	flagext.DefaultValues(&cfg)
+	cfg.Enabled = true
+
+	a := newAdaptiveIngestionRate(cfg, &staticIngestionRateStrategy{limit: 100}, nil, log.NewNopLogger())
+	now := time.Now()
+	a.lastRun = now
+
+	utilization := func(addr string) (float64, float64) {
+		v, ok := a.ingesters.Load(addr)
+		require.True(t, ok)
+		u := v.(*ingesterUtilization)
+		return u.cpu.Load(), u.heap.Load()
+	}
+
+	a.observeIngester("ingester-1", &cortexpb.WriteResponse{CpuUtilization: 0.2, HeapUtilization: 0.4}, now)
+	cpu, heap := utilization("ingester-1")
+	assert.Equal(t, 0.2, cpu)
+	assert.Equal(t, 0.4, heap)
+
+	// A single spike is smoothed.
+	a.observeIngester("ingester-1", &cortexpb.WriteResponse{CpuUtilization: 1, HeapUtilization: 0.4}, now)
+	cpu, heap = utilization("ingester-1")
+	assert.InDelta(t, 0.6, cpu, 1e-9)
+	assert.InDelta(t, 0.4, heap, 1e-9)
+
+	a.update(now.Add(cfg.UpdateInterval))
+	assert.Equal(t, 100.0, a.Limit("user-1"))
+
+	// The average is reset once the previous report is stale.
+	now = now.Add(adaptiveIngestionRateStaleIntervals*cfg.UpdateInterval + time.Second)
+	a.observeIngester("ingester-1", &cortexpb.WriteResponse{CpuUtilization: 0.1, HeapUtilization: 0.1}, now)
+	cpu, heap = utilization("ingester-1")
+	assert.Equal(t, 0.1, cpu)
+	assert.Equal(t, 0.1, heap)
+}
+
+func TestAdaptiveIngestionRate_ConcurrentObserveAndUpdate(t *testing.T) {
+	cfg := AdaptiveIngestionRateConfig{}
+	flagext.DefaultValues(&cfg)
+	cfg.Enabled = true
+
+	a := newAdaptiveIngestionRate(cfg, &staticIngestionRateStrategy{limit: 100}, nil, log.NewNopLogger())
+	now := time.Now()
+	a.lastRun = now
+
+	wg := sync.WaitGroup{}
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 1000 {
+				a.observe("user-1", 1)
+				a.observeIngester(fmt.Sprintf("ingester-%d", i%3), &cortexpb.WriteResponse{CpuUtilization: 1, HeapUtilization: 1}, now)
+			}
+		}()
+	}
+	for i := range 10 {
+		a.update(now.Add(time.Duration(i+1) * time.Millisecond))
+	}
+	wg.Wait()
+
+	a.observe("user-1", 1000)
+	a.update(now.Add(cfg.UpdateInterval))
+	assert.Less(t, a.Limit("user-1"), 100.0)
+}
+
+func TestAdaptiveIngestionRate_ShouldNotLowerUnlimitedTenants(t *testing.T) {
+	cfg := AdaptiveIngestionRateConfig{}
+	flagext.DefaultValues(&cfg)
+	cfg.Enabled = true
+
+	a := newAdaptiveIngestionRate(cfg, newInfiniteIngestionRateStrategy(), nil, log.NewNopLogger())
+	now := time.Now()
+	a.lastRun = now
+
+	a.observe("user-1", 1000)
+	a.observeIngester("ingester-1", &cortexpb.WriteResponse{CpuUtilization: 1}, now)
+	a.update(now.Add(cfg.UpdateInterval))
+
+	assert.Equal(t, newInfiniteIngestionRateStrategy().Limit("user-1"), a.Limit("user-1"))
+}
+
+func TestAdaptiveIngestionRate_Nil(t *testing.T) {
+	var a *adaptiveIngestionRate
+
+	a.observe("user-1", 10)
+	a.observeIngester("ingester-1", &cortexpb.WriteResponse{CpuUtilization: 1}, time.Now())
+	a.cleanupInactiveUser("user-1")
+
+	resp, ok := httpgrpc.HTTPResponseFromError(a.rateLimitedError("user-1", "ingestion rate limit exceeded"))
+	require.True(t, ok)
+	assert.Equal(t, int32(http.StatusTooManyRequests), resp.Code)
+	assert.Empty(t, resp.Headers)
+}
@@ -0,0 +1,635 @@
+package distributor
+
+import (
+	"context"
+	"flag"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/weaveworks/common/user"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/cortexproject/cortex/pkg/cortexpb"
+	"github.com/cortexproject/cortex/pkg/distributor/distributorpb"
+	ingester_client "github.com/cortexproject/cortex/pkg/ingester/client"
+	"github.com/cortexproject/cortex/pkg/ring"
+	ring_client "github.com/cortexproject/cortex/pkg/ring/client"
+	"github.com/cortexproject/cortex/pkg/util"
+	"github.com/cortexproject/cortex/pkg/util/grpcclient"
+	"github.com/cortexproject/cortex/pkg/util/services"
+	"github.com/cortexproject/cortex/pkg/util/users"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+const (
+	// Number of aggregation intervals after which an aggregation group or one of its
+	// input series is considered stale and removed.
+	aggregationStalenessIntervals = 5
+
+	aggregationFailureReasonRing    = "ring"
+	aggregationFailureReasonForward = "forward"
+	aggregationFailureReasonPush    = "push"
+
+	aggregationDropReasonQueueFull = "queue_full"
+	aggregationDropReasonFailed    = "failed"
+)
+
+var (
+	errInvalidAggregationInterval  = errors.New("invalid aggregation interval, it must be greater than 0")
+	errAggregationShardingDisabled = errors.New("aggregation rules require the aggregation sharding to be enabled")
+	errInvalidAggregationQueue     = errors.New("invalid aggregation forward queue capacity, it must be greater than 0")
+	errInvalidAggregationWorkers   = errors.New("invalid number of aggregation forward workers, it must be greater than 0")
+)
+
+// AggregationConfig configures how the distributor evaluates the aggregation rules
+// configured in the tenants' limits.
+type AggregationConfig struct {
+	Interval             time.Duration `yaml:"interval"`
+	ShardingEnabled      bool          `yaml:"sharding_enabled"`
+	ForwardQueueCapacity int           `yaml:"forward_queue_capacity"`
+	ForwardWorkers       int           `yaml:"forward_workers"`
+	ForwardDrainTimeout  time.Duration `yaml:"forward_drain_timeout"`
+}
+
+// RegisterFlags adds the flags required to config this to the given FlagSet
+func (cfg *AggregationConfig) RegisterFlags(f *flag.FlagSet) {
+	f.DurationVar(&cfg.Interval, "distributor.aggregation.interval", time.Minute, "EXPERIMENTAL: Interval at which the series computed by the aggregation rules are pushed.")
+	f.BoolVar(&cfg.ShardingEnabled, "distributor.aggregation.sharding-enabled", false, "EXPERIMENTAL: If true, the aggregated series are sharded across the distributors using the distributors ring, and the input samples are sent to the distributor owning the aggregated series. It must be enabled when aggregation rules are configured, otherwise each distributor pushes a partial aggregation of the samples it receives.")
+	f.IntVar(&cfg.ForwardQueueCapacity, "distributor.aggregation.forward-queue-capacity", 1000, "EXPERIMENTAL: Maximum number of requests queued to be sent to the distributors owning the aggregated series. Once the queue is full, the input series to send are dropped.")
+	f.IntVar(&cfg.ForwardWorkers, "distributor.aggregation.forward-workers", 4, "EXPERIMENTAL: Number of concurrent requests sent to the distributors owning the aggregated series.")
+	f.DurationVar(&cfg.ForwardDrainTimeout, "distributor.aggregation.forward-drain-timeout", 10*time.Second, "EXPERIMENTAL: Maximum time to wait on shutdown for the queued input series to be sent to the distributors owning the aggregated series. The input series not sent once the timeout expires are dropped.")
+}
+
+// Validate config and returns error on failure
+func (cfg *AggregationConfig) Validate() error {
+	if cfg.Interval <= 0 {
+		return errInvalidAggregationInterval
+	}
+	if cfg.ForwardQueueCapacity <= 0 {
+		return errInvalidAggregationQueue
+	}
+	if cfg.ForwardWorkers <= 0 {
+		return errInvalidAggregationWorkers
+	}
+	return nil
+}
+
+// ValidateLimits returns an error if the given limits configure aggregation rules which
+// can't be evaluated with this config.
+func (cfg *AggregationConfig) ValidateLimits(limits validation.Limits) error {
+	if len(limits.AggregationRules) > 0 && !cfg.ShardingEnabled {
+		return errAggregationShardingDisabled
+	}
+	return nil
+}
+
+type aggregationContextKey int
+
+const aggregationOutputKey aggregationContextKey = 0
+
+// withAggregationOutput marks the context of a push of aggregated series, which must not
+// be aggregated again.
+func withAggregationOutput(ctx context.Context) context.Context {
+	return context.WithValue(ctx, aggregationOutputKey, true)
+}
+
+func isAggregationOutput(ctx context.Context) bool {
+	v, ok := ctx.Value(aggregationOutputKey).(bool)
+	return ok && v
+}
+
+// aggregationInput is the state of an input series of an aggregation group.
+type aggregationInput struct {
+	value     float64
+	timestamp int64
+	lastSeen  time.Time
+}
+
+// aggregationGroupKey identifies an aggregation group by the operation of its rule and the
+// hash of its output labels.
+type aggregationGroupKey struct {
+	operation  string
+	labelsHash uint64
+}
+
+// aggregationGroup is the state of an aggregated series.
+type aggregationGroup struct {
+	labels     labels.Labels
+	operation  string
+	total      float64
+	inputs     map[uint64]*aggregationInput
+	lastUpdate time.Time
+}
+
+func (g *aggregationGroup) add(input uint64, samples []cortexpb.Sample, now time.Time) {
+	g.lastUpdate = now
+
+	in := g.inputs[input]
+	for _, s := range samples {
+		if in == nil {
+			// The first sample of a series is the baseline of its increase.
+			in = &aggregationInput{value: s.Value, timestamp: s.TimestampMs}
+			g.inputs[input] = in
+			continue
+		}
+		// Out of order samples are ignored.
+		if s.TimestampMs <= in.timestamp {
+			continue
+		}
+		if g.operation == validation.AggregationSumIncrease {
+			if s.Value >= in.value {
+				g.total += s.Value - in.value
+			} else {
+				// Counter reset.
+				g.total += s.Value
+			}
+		}
+		in.value = s.Value
+		in.timestamp = s.TimestampMs
+	}
+	if in != nil {
+		in.lastSeen = now
+	}
+}
+
+// value removes the input series not seen since the given deadline and returns the
+// current value of the aggregated series.
+func (g *aggregationGroup) value(deadline time.Time) float64 {
+	sum := 0.0
+	for key, in := range g.inputs {
+		if in.lastSeen.Before(deadline) {
+			delete(g.inputs, key)
+			continue
+		}
+		sum += in.value
+	}
+	if g.operation == validation.AggregationSumIncrease {
+		return g.total
+	}
+	return sum
+}
+
+// aggregationForwardRequest holds the input series to send to the distributor owning
+// their aggregation groups.
+type aggregationForwardRequest struct {
+	userID  string
+	addr    string
+	req     *cortexpb.WriteRequest
+	samples int
+}
+
+// aggregator evaluates the tenants' aggregation rules on the pushed series and periodically
+// pushes the aggregated series. When sharding is enabled, each aggregated series is owned by
+// a single distributor and the input series are sent to it by the other distributors, through
+// a bounded queue consumed by a fixed number of workers.
+type aggregator struct {
+	services.Service
+
+	cfg     AggregationConfig
+	log     log.Logger
+	push    func(context.Context, *cortexpb.WriteRequest) (*cortexpb.WriteResponse, error)
+	timeout time.Duration
+
+	// Only set when sharding is enabled. The lifecycler is nil if this distributor can't
+	// join the distributors ring, in which case all the input series are sent to the owners.
+	ring       ring.ReadRing
+	lifecycler *ring.Lifecycler
+	pool       *ring_client.Pool
+
+	// Context used by the forward workers, canceled once the queue is drained or the drain
+	// timeout expires when the aggregator stops.
+	ctx          context.Context
+	cancel       context.CancelFunc
+	forwardQueue chan aggregationForwardRequest
+	// Closed when the aggregator stops, for the forward workers to drain the queue and exit.
+	draining chan struct{}
+	wg       sync.WaitGroup
+
+	mtx     sync.Mutex
+	tenants map[string]map[aggregationGroupKey]*aggregationGroup
+
+	inputSamples  *prometheus.CounterVec
+	outputSamples *prometheus.CounterVec
+	failures      *prometheus.CounterVec
+	dropped       *prometheus.CounterVec
+}
+
+func newAggregator(cfg AggregationConfig, push func(context.Context, *cortexpb.WriteRequest) (*cortexpb.WriteResponse, error), timeout time.Duration, reg prometheus.Registerer, logger log.Logger) *aggregator {
+	a := &aggregator{
+		cfg:     cfg,
+		log:     logger,
+		push:    push,
+		timeout: timeout,
+		tenants: map[string]map[aggregationGroupKey]*aggregationGroup{},
+
+		forwardQueue: make(chan aggregationForwardRequest, cfg.ForwardQueueCapacity),
+		draining:     make(chan struct{}),
+
+		inputSamples: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_distributor_aggregation_input_samples_total",
+			Help: "The total number of samples aggregated by the aggregation rules.",
+		}, []string{"user"}),
+		outputSamples: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_distributor_aggregation_output_samples_total",
+			Help: "The total number of aggregated samples pushed by the aggregation rules.",
+		}, []string{"user"}),
+		failures: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_distributor_aggregation_failures_total",
+			Help: "The total number of failures evaluating the aggregation rules.",
+		}, []string{"user", "reason"}),
+		dropped: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_distributor_aggregation_dropped_input_samples_total",
+			Help: "The total number of input samples which failed to be sent to the distributors owning their aggregation.",
+		}, []string{"user", "reason"}),
+	}
+	a.ctx, a.cancel = context.WithCancel(context.Background())
+
+	a.Service = services.NewTimerService(cfg.Interval, a.starting, a.iteration, a.stopping)
+	return a
+}
+
+func (a *aggregator) starting(_ context.Context) error {
+	for range a.cfg.ForwardWorkers {
+		a.wg.Add(1)
+		go a.runForwardWorker()
+	}
+	return nil
+}
+
+func (a *aggregator) stopping(_ error) error {
+	close(a.draining)
+	if !waitWithTimeout(&a.wg, a.cfg.ForwardDrainTimeout) {
+		level.Warn(a.log).Log("msg", "timed out sending the queued input series to the distributors owning their aggregation on shutdown, dropping the remaining ones", "timeout", a.cfg.ForwardDrainTimeout)
+	}
+	// The requests still in-flight or queued fail right away once the context is canceled.
+	a.cancel()
+	a.wg.Wait()
+
+	// Requests queued after the workers exited.
+	for {
+		select {
+		case r := <-a.forwardQueue:
+			a.dropped.WithLabelValues(r.userID, aggregationDropReasonFailed).Add(float64(r.samples))
+		default:
+			return nil
+		}
+	}
+}
+
+// withSharding shards the aggregated series across the distributors of the given ring.
+func (a *aggregator) withSharding(r ring.ReadRing, lifecycler *ring.Lifecycler, pool *ring_client.Pool) {
+	a.ring = r
+	a.lifecycler = lifecycler
+	a.pool = pool
+}
+
+// process feeds the samples of the series matching the given rules to the aggregation
+// groups. If forward is true, the series aggregated into groups owned by other distributors
+// are sent to them, otherwise the groups owned by other distributors are skipped. It returns
+// which series must be dropped, or nil if none.
+func (a *aggregator) process(userID string, rules validation.AggregationRules, series []cortexpb.PreallocTimeseries, forward bool) []bool {
+	var (
+		dropped  []bool
+		remote   map[string]*cortexpb.WriteRequest
+		owners   []string
+		builder  = labels.NewBuilder(labels.EmptyLabels())
+		bufDescs []ring.InstanceDesc
+		bufHosts []string
+		bufZones map[string]int
+		now      = time.Now()
+		samples  = 0
+	)
+	if a.ring != nil {
+		bufDescs, bufHosts, bufZones = ring.MakeBuffersForGet()
+	}
+
+	for i, ts := range series {
+		// Only float samples are aggregated.
+		if len(ts.Samples) == 0 {
+			continue
+		}
+
+		lbls := cortexpb.FromLabelAdaptersToLabels(ts.Labels)
+		input, hashed := uint64(0), false
+		owners = owners[:0]
+
+		for r := range rules {
+			rule := &rules[r]
+			if !rule.Matches(lbls) {
+				continue
+			}
+			if rule.DropInput {
+				if dropped == nil {
+					dropped = make([]bool, len(series))
+				}
+				dropped[i] = true
+			}
+
+			out := rule.OutputLabels(builder, lbls)
+			if a.ring != nil {
+				rs, err := a.ring.Get(ingester_client.ShardByAllLabels(userID, cortexpb.FromLabelsToLabelAdapters(out)), ring.WriteNoExtend, bufDescs, bufHosts, bufZones)
+				if err != nil || len(rs.Instances) == 0 {
+					a.failures.WithLabelValues(userID, aggregationFailureReasonRing).Inc()
+					continue
+				}
+				if addr := rs.Instances[0].Addr; a.lifecycler == nil || addr != a.lifecycler.Addr {
+					if forward && !slices.Contains(owners, addr) {
+						owners = append(owners, addr)
+					}
+					continue
+				}
+			}
+
+			if !hashed {
+				input, hashed = lbls.Hash(), true
+			}
+			a.add(userID, rule.Operation, out, input, ts.Samples, now)
+			samples += len(ts.Samples)
+		}
+
+		for _, addr := range owners {
+			if remote == nil {
+				remote = map[string]*cortexpb.WriteRequest{}
+			}
+			req, ok := remote[addr]
+			if !ok {
+				req = &cortexpb.WriteRequest{Source: cortexpb.API}
+				remote[addr] = req
+			}
+			req.Timeseries = append(req.Timeseries, copyAggregationInput(ts))
+		}
+	}
+
+	if samples > 0 {
+		a.inputSamples.WithLabelValues(userID).Add(float64(samples))
+	}
+	for addr, req := range remote {
+		a.enqueueForward(aggregationForwardRequest{userID: userID, addr: addr, req: req, samples: countAggregationSamples(req)})
+	}
+	return dropped
+}
+
+func (a *aggregator) add(userID, operation string, out labels.Labels, input uint64, samples []cortexpb.Sample, now time.Time) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	groups, ok := a.tenants[userID]
+	if !ok {
+		groups = map[aggregationGroupKey]*aggregationGroup{}
+		a.tenants[userID] = groups
+	}
+
+	key := aggregationGroupKey{operation: operation, labelsHash: out.Hash()}
+	g, ok := groups[key]
+	if !ok {
+		// The records of the rules are unique, so the same output labels are only computed
+		// with another operation if the rule has been changed. The group of the previous
+		// operation is dropped, since both groups would push the same series.
+		for _, op := range []string{validation.AggregationSum, validation.AggregationSumIncrease} {
+			if op != operation {
+				delete(groups, aggregationGroupKey{operation: op, labelsHash: key.labelsHash})
+			}
+		}
+
+		// The output labels reference the request buffer, which is reused once the push completes.
+		g = &aggregationGroup{
+			labels:    cortexpb.FromLabelAdaptersToLabelsWithCopy(cortexpb.FromLabelsToLabelAdapters(out)),
+			operation: operation,
+			inputs:    map[uint64]*aggregationInput{},
+		}
+		groups[key] = g
+	}
+	g.add(input, samples, now)
+}
+
+// enqueueForward queues the input series to send to the distributor owning their aggregation
+// groups. It never blocks: if the queue is full, the input series are dropped.
+func (a *aggregator) enqueueForward(r aggregationForwardRequest) {
+	select {
+	case a.forwardQueue <- r:
+	default:
+		a.dropped.WithLabelValues(r.userID, aggregationDropReasonQueueFull).Add(float64(r.samples))
+	}
+}
+
+func (a *aggregator) runForwardWorker() {
+	defer a.wg.Done()
+
+	for {
+		select {
+		case r := <-a.forwardQueue:
+			a.forward(r)
+		case <-a.draining:
+			// Send the requests still queued before exiting.
+			for {
+				select {
+				case r := <-a.forwardQueue:
+					a.forward(r)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// forward sends the input series to the distributor owning their aggregation groups.
+func (a *aggregator) forward(r aggregationForwardRequest) {
+	ctx, cancel := context.WithTimeout(user.InjectOrgID(a.ctx, r.userID), a.timeout)
+	defer cancel()
+
+	c, err := a.pool.GetClientFor(r.addr)
+	if err == nil {
+		_, err = c.(distributorpb.DistributorClient).Aggregate(ctx, r.req)
+	}
+	if err != nil {
+		a.failures.WithLabelValues(r.userID, aggregationFailureReasonForward).Inc()
+		a.dropped.WithLabelValues(r.userID, aggregationDropReasonFailed).Add(float64(r.samples))
+		level.Warn(a.log).Log("msg", "failed to send series to the distributor owning their aggregation", "user", r.userID, "addr", r.addr, "err", err)
+	}
+}
+
+func countAggregationSamples(req *cortexpb.WriteRequest) int {
+	samples := 0
+	for _, ts := range req.Timeseries {
+		samples += len(ts.Samples)
+	}
+	return samples
+}
+
+func (a *aggregator) iteration(ctx context.Context) error {
+	a.flush(ctx, time.Now())
+	return nil
+}
+
+// flush pushes the current value of the aggregation groups, removing the stale ones.
+func (a *aggregator) flush(ctx context.Context, now time.Time) {
+	deadline := now.Add(-aggregationStalenessIntervals * a.cfg.Interval)
+	timestamp := now.UnixMilli()
+	requests := map[string]*cortexpb.WriteRequest{}
+
+	a.mtx.Lock()
+	for userID, groups := range a.tenants {
+		for key, g := range groups {
+			if g.lastUpdate.Before(deadline) {
+				delete(groups, key)
+				continue
+			}
+
+			req, ok := requests[userID]
+			if !ok {
+				req = &cortexpb.WriteRequest{Source: cortexpb.API}
+				requests[userID] = req
+			}
+			// The labels are copied because the series are reused once the push completes.
+			req.Timeseries = append(req.Timeseries, cortexpb.PreallocTimeseries{
+				TimeSeries: &cortexpb.TimeSeries{
+					Labels:  slices.Clone(cortexpb.FromLabelsToLabelAdapters(g.labels)),
+					Samples: []cortexpb.Sample{{Value: g.value(deadline), TimestampMs: timestamp}},
+				},
+			})
+		}
+		if len(groups) == 0 {
+			delete(a.tenants, userID)
+		}
+	}
+	a.mtx.Unlock()
+
+	for userID, req := range requests {
+		samples := len(req.Timeseries)
+		_, err := a.push(withAggregationOutput(user.InjectOrgID(ctx, userID)), req)
+		if err != nil {
+			a.failures.WithLabelValues(userID, aggregationFailureReasonPush).Inc()
+			level.Warn(a.log).Log("msg", "failed to push aggregated series", "user", userID, "err", err)
+			continue
+		}
+		a.outputSamples.WithLabelValues(userID).Add(float64(samples))
+	}
+}
+
+func (a *aggregator) cleanupInactiveUser(userID string) {
+	a.mtx.Lock()
+	delete(a.tenants, userID)
+	a.mtx.Unlock()
+
+	a.inputSamples.DeleteLabelValues(userID)
+	a.outputSamples.DeleteLabelValues(userID)
+	if err := util.DeleteMatchingLabels(a.failures, map[string]string{"user": userID}); err != nil {
+		level.Warn(a.log).Log("msg", "failed to remove cortex_distributor_aggregation_failures_total metric for user", "user", userID, "err", err)
+	}
+	if err := util.DeleteMatchingLabels(a.dropped, map[string]string{"user": userID}); err != nil {
+		level.Warn(a.log).Log("msg", "failed to remove cortex_distributor_aggregation_dropped_input_samples_total metric for user", "user", userID, "err", err)
+	}
+}
+
+// copyAggregationInput copies the labels and float samples of a series, because the series
+// is sent asynchronously while the push request is reused once it completes.
+func copyAggregationInput(ts cortexpb.PreallocTimeseries) cortexpb.PreallocTimeseries {
+	lbls := make([]cortexpb.LabelAdapter, 0, len(ts.Labels))
+	for _, l := range ts.Labels {
+		lbls = append(lbls, cortexpb.LabelAdapter{Name: strings.Clone(l.Name), Value: strings.Clone(l.Value)})
+	}
+	return cortexpb.PreallocTimeseries{
+		TimeSeries: &cortexpb.TimeSeries{
+			Labels:  lbls,
+			Samples: slices.Clone(ts.Samples),
+		},
+	}
+}
+
+// filterDroppedSeries removes the dropped series and their keys.
+func filterDroppedSeries(keys []uint32, series []cortexpb.PreallocTimeseries, dropped []bool) ([]uint32, []cortexpb.PreallocTimeseries) {
+	n := 0
+	for i := range series {
+		if dropped[i] {
+			continue
+		}
+		keys[n] = keys[i]
+		series[n] = series[i]
+		n++
+	}
+	return keys[:n], series[:n]
+}
+
+// Aggregate implements distributorpb.DistributorServer. It aggregates the series sent by the
+// other distributors into the aggregation groups owned by this distributor.
+func (d *Distributor) Aggregate(ctx context.Context, req *cortexpb.WriteRequest) (*cortexpb.WriteResponse, error) {
+	defer func() {
+		cortexpb.ReuseSlice(req.Timeseries)
+		req.Free()
+	}()
+
+	userID, err := users.TenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	d.aggregator.process(userID, d.limits.AggregationRules(userID), req.Timeseries, false)
+	return &cortexpb.WriteResponse{}, nil
+}
+
+func newDistributorClientPool(clientCfg grpcclient.ConfigWithHealthCheck, logger log.Logger, reg prometheus.Registerer) *ring_client.Pool {
+	// We prefer sane defaults instead of exposing further config options.
+	poolCfg := ring_client.PoolConfig{
+		CheckInterval:      time.Minute,
+		HealthCheckEnabled: true,
+		HealthCheckTimeout: 10 * time.Second,
+	}
+
+	clientsCount := promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+		Name: "cortex_distributor_aggregation_clients",
+		Help: "The current number of distributor clients in the pool.",
+	})
+	requestDuration := promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "cortex_distributor_aggregation_client_request_duration_seconds",
+		Help:    "Time spent sending series to the distributors owning their aggregation.",
+		Buckets: prometheus.ExponentialBuckets(0.008, 4, 7),
+	}, []string{"operation", "status_code"})
+
+	factory := func(addr string) (ring_client.PoolClient, error) {
+		opts, err := clientCfg.DialOption(grpcclient.Instrument(requestDuration))
+		if err != nil {
+			return nil, err
+		}
+
+		conn, err := grpc.NewClient(addr, opts...)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to dial distributor %s", addr)
+		}
+
+		return &distributorClient{
+			DistributorClient: distributorpb.NewDistributorClient(conn),
+			HealthClient:      grpc_health_v1.NewHealthClient(conn),
+			conn:              conn,
+		}, nil
+	}
+
+	return ring_client.NewPool("distributor", poolCfg, nil, factory, clientsCount, logger)
+}
+
+type distributorClient struct {
+	distributorpb.DistributorClient
+	grpc_health_v1.HealthClient
+	conn *grpc.ClientConn
+}
+
+func (c *distributorClient) Close() error {
+	return c.conn.Close()
+}
+
+func (c *distributorClient) String() string {
+	return c.RemoteAddress()
+}
+
+func (c *distributorClient) RemoteAddress() string {
+	return c.conn.Target()
+}
@@ -0,0 +1,302 @@
+package distributor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/cortexproject/cortex/pkg/cortexpb"
+	"github.com/cortexproject/cortex/pkg/distributor/distributorpb"
+	ring_client "github.com/cortexproject/cortex/pkg/ring/client"
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+	"github.com/cortexproject/cortex/pkg/util/services"
+	"github.com/cortexproject/cortex/pkg/util/test"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+func TestAggregationConfig_Validate(t *testing.T) {
+	cfg := AggregationConfig{}
+	flagext.DefaultValues(&cfg)
+	require.NoError(t, cfg.Validate())
+
+	cfg.Interval = 0
+	assert.ErrorIs(t, cfg.Validate(), errInvalidAggregationInterval)
+}
+
+func TestAggregationGroup(t *testing.T) {
+	now := time.Now()
+	samples := func(values ...float64) []cortexpb.Sample {
+		s := make([]cortexpb.Sample, 0, len(values))
+		for i, v := range values {
+			s = append(s, cortexpb.Sample{Value: v, TimestampMs: int64(i + 1)})
+		}
+		return s
+	}
+
+	t.Run("sum", func(t *testing.T) {
+		g := &aggregationGroup{operation: validation.AggregationSum, inputs: map[uint64]*aggregationInput{}}
+		g.add(1, samples(1, 5), now)
+		g.add(2, samples(3), now)
+		assert.Equal(t, 8.0, g.value(now))
+
+		// Out of order samples are ignored.
+		g.add(2, []cortexpb.Sample{{Value: 100, TimestampMs: 0}}, now)
+		assert.Equal(t, 8.0, g.value(now))
+
+		// Stale input series are removed.
+		g.add(1, []cortexpb.Sample{{Value: 2, TimestampMs: 10}}, now.Add(time.Minute))
+		assert.Equal(t, 2.0, g.value(now.Add(time.Second)))
+		assert.Len(t, g.inputs, 1)
+	})
+
+	t.Run("sum_increase", func(t *testing.T) {
+		g := &aggregationGroup{operation: validation.AggregationSumIncrease, inputs: map[uint64]*aggregationInput{}}
+		// The first sample of a series is the baseline.
+		g.add(1, samples(10, 15, 20), now)
+		g.add(2, samples(100), now)
+		assert.Equal(t, 10.0, g.value(now))
+
+		// Counter reset.
+		g.add(2, []cortexpb.Sample{{Value: 3, TimestampMs: 10}}, now)
+		assert.Equal(t, 13.0, g.value(now))
+
+		// The aggregated counter doesn't decrease when an input series goes away.
+		assert.Equal(t, 13.0, g.value(now.Add(time.Second)))
+		assert.Empty(t, g.inputs)
+	})
+}
+
+type aggregationPushMock struct {
+	mtx      sync.Mutex
+	requests map[string]*cortexpb.WriteRequest
+}
+
+func (m *aggregationPushMock) push(ctx context.Context, req *cortexpb.WriteRequest) (*cortexpb.WriteResponse, error) {
+	userID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !isAggregationOutput(ctx) {
+		return nil, errors.New("missing aggregation output marker")
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.requests[userID] = req
+	return &cortexpb.WriteResponse{}, nil
+}
+
+func TestAggregator_ProcessAndFlush(t *testing.T) {
+	rules := validation.AggregationRules{
+		{Record: "job:foo:sum", Matchers: `{__name__="foo"}`, By: []string{"job"}},
+		{Record: "foo:sum_increase", Matchers: `{__name__="foo"}`, Operation: validation.AggregationSumIncrease, DropInput: true},
+		{Record: "bar:sum", Matchers: `{__name__="bar"}`, Without: []string{"pod"}},
+	}
+	require.NoError(t, rules.Validate())
+
+	pusher := &aggregationPushMock{requests: map[string]*cortexpb.WriteRequest{}}
+	reg := prometheus.NewPedanticRegistry()
+	a := newAggregator(AggregationConfig{Interval: time.Minute}, pusher.push, time.Second, reg, log.NewNopLogger())
+
+	series := func(value float64, ts int64, lbls ...string) cortexpb.PreallocTimeseries {
+		return cortexpb.PreallocTimeseries{TimeSeries: &cortexpb.TimeSeries{
+			Labels:  cortexpb.FromLabelsToLabelAdapters(labels.FromStrings(lbls...)),
+			Samples: []cortexpb.Sample{{Value: value, TimestampMs: ts}},
+		}}
+	}
+
+	dropped := a.process("user", rules, []cortexpb.PreallocTimeseries{
+		series(1, 1, labels.MetricName, "foo", "job", "a", "pod", "1"),
+		series(2, 1, labels.MetricName, "foo", "job", "a", "pod", "2"),
+		series(4, 1, labels.MetricName, "foo", "job", "b", "pod", "1"),
+		series(8, 1, labels.MetricName, "bar", "job", "a", "pod", "1"),
+		series(16, 1, labels.MetricName, "baz", "job", "a", "pod", "1"),
+	}, true)
+	assert.Equal(t, []bool{true, true, true, false, false}, dropped)
+
+	dropped = a.process("user", rules, []cortexpb.PreallocTimeseries{
+		series(3, 2, labels.MetricName, "foo", "job", "a", "pod", "1"),
+		series(5, 2, labels.MetricName, "foo", "job", "a", "pod", "2"),
+	}, true)
+	assert.Equal(t, []bool{true, true}, dropped)
+
+	now := time.Now()
+	a.flush(context.Background(), now)
+
+	req := pusher.requests["user"]
+	require.NotNil(t, req)
+
+	actual := map[string]cortexpb.Sample{}
+	for _, ts := range req.Timeseries {
+		require.Len(t, ts.Samples, 1)
+		actual[cortexpb.FromLabelAdaptersToLabels(ts.Labels).String()] = ts.Samples[0]
+	}
+	assert.Equal(t, map[string]cortexpb.Sample{
+		`{__name__="job:foo:sum", job="a"}`: {Value: 8, TimestampMs: now.UnixMilli()},
+		`{__name__="job:foo:sum", job="b"}`: {Value: 4, TimestampMs: now.UnixMilli()},
+		`{__name__="foo:sum_increase"}`:     {Value: 5, TimestampMs: now.UnixMilli()},
+		`{__name__="bar:sum", job="a"}`:     {Value: 8, TimestampMs: now.UnixMilli()},
+	}, actual)
+
+	// Each sample is counted once per matching rule.
+	assert.Equal(t, 11.0, testutil.ToFloat64(a.inputSamples.WithLabelValues("user")))
+	assert.Equal(t, 4.0, testutil.ToFloat64(a.outputSamples.WithLabelValues("user")))
+
+	// Stale aggregation groups are removed.
+	pusher.requests = map[string]*cortexpb.WriteRequest{}
+	a.flush(context.Background(), now.Add(aggregationStalenessIntervals*time.Minute+time.Second))
+	assert.Empty(t, pusher.requests)
+	assert.Empty(t, a.tenants)
+}
+
+func TestAggregator_ShouldKeyGroupsByOperationAndLabels(t *testing.T) {
+	a := newAggregator(AggregationConfig{Interval: time.Minute}, nil, time.Second, prometheus.NewPedanticRegistry(), log.NewNopLogger())
+	out := labels.FromStrings(labels.MetricName, "foo:sum")
+	now := time.Now()
+
+	a.add("user", validation.AggregationSum, out, 1, []cortexpb.Sample{{Value: 1, TimestampMs: 1}}, now)
+	a.add("user", validation.AggregationSum, out, 2, []cortexpb.Sample{{Value: 2, TimestampMs: 1}}, now)
+	require.Len(t, a.tenants["user"], 1)
+	g := a.tenants["user"][aggregationGroupKey{operation: validation.AggregationSum, labelsHash: out.Hash()}]
+	require.NotNil(t, g)
+	assert.Len(t, g.inputs, 2)
+
+	// Once the operation of the rule is changed, the group of the previous operation is
+	// dropped, since both groups would push the same series.
+	a.add("user", validation.AggregationSumIncrease, out, 1, []cortexpb.Sample{{Value: 1, TimestampMs: 2}}, now)
+	require.Len(t, a.tenants["user"], 1)
+	g = a.tenants["user"][aggregationGroupKey{operation: validation.AggregationSumIncrease, labelsHash: out.Hash()}]
+	require.NotNil(t, g)
+	assert.Equal(t, validation.AggregationSumIncrease, g.operation)
+}
+
+func TestDistributor_Push_AggregationRules(t *testing.T) {
+	t.Parallel()
+
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.AggregationRules = validation.AggregationRules{
+		{Record: "foo:sum", Matchers: `{__name__="foo"}`, By: []string{"bar"}, DropInput: true},
+	}
+	require.NoError(t, limits.AggregationRules.Validate())
+
+	ds, ingesters, _, _ := prepare(t, prepConfig{
+		numIngesters:     3,
+		happyIngesters:   3,
+		numDistributors:  1,
+		shardByAllLabels: true,
+		limits:           limits,
+	})
+
+	ctx := user.InjectOrgID(context.Background(), "user")
+	_, err := ds[0].Push(ctx, makeWriteRequest(0, 5, 0, 0))
+	require.NoError(t, err)
+
+	// The input series are dropped.
+	for _, ing := range ingesters {
+		assert.Empty(t, ing.series())
+	}
+
+	ds[0].aggregator.flush(context.Background(), time.Now())
+
+	test.Poll(t, 5*time.Second, []string{`{__name__="foo:sum", bar="baz"}=10`}, func() any {
+		series := map[string]struct{}{}
+		for _, ing := range ingesters {
+			for _, ts := range ing.series() {
+				series[fmt.Sprintf("%s=%v", cortexpb.FromLabelAdaptersToLabels(ts.Labels), ts.Samples[0].Value)] = struct{}{}
+			}
+		}
+		return slices.Collect(maps.Keys(series))
+	})
+}
+
+type aggregateClientMock struct {
+	distributorpb.DistributorClient
+	grpc_health_v1.HealthClient
+
+	mtx      sync.Mutex
+	requests []*cortexpb.WriteRequest
+}
+
+func (c *aggregateClientMock) Aggregate(_ context.Context, req *cortexpb.WriteRequest, _ ...grpc.CallOption) (*cortexpb.WriteResponse, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.requests = append(c.requests, req)
+	return &cortexpb.WriteResponse{}, nil
+}
+
+func (c *aggregateClientMock) Close() error { return nil }
+
+func TestAggregator_ForwardQueue(t *testing.T) {
+	client := &aggregateClientMock{}
+	pool := ring_client.NewPool("distributor", ring_client.PoolConfig{}, nil, func(string) (ring_client.PoolClient, error) {
+		return client, nil
+	}, prometheus.NewGauge(prometheus.GaugeOpts{Name: "clients"}), log.NewNopLogger())
+
+	a := newAggregator(AggregationConfig{Interval: time.Minute, ForwardQueueCapacity: 1, ForwardWorkers: 1}, nil, time.Second, prometheus.NewPedanticRegistry(), log.NewNopLogger())
+	a.withSharding(nil, nil, pool)
+
+	req := func(samples int) aggregationForwardRequest {
+		ts := cortexpb.PreallocTimeseries{TimeSeries: &cortexpb.TimeSeries{Samples: make([]cortexpb.Sample, samples)}}
+		r := &cortexpb.WriteRequest{Timeseries: []cortexpb.PreallocTimeseries{ts}}
+		return aggregationForwardRequest{userID: "user", addr: "distributor-1", req: r, samples: countAggregationSamples(r)}
+	}
+
+	// The workers are not running yet, so the requests exceeding the queue capacity are dropped.
+	a.enqueueForward(req(1))
+	a.enqueueForward(req(2))
+	a.enqueueForward(req(3))
+	assert.Equal(t, 5.0, testutil.ToFloat64(a.dropped.WithLabelValues("user", aggregationDropReasonQueueFull)))
+
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), a))
+	t.Cleanup(func() {
+		require.NoError(t, services.StopAndAwaitTerminated(context.Background(), a))
+	})
+
+	test.Poll(t, 5*time.Second, 1, func() any {
+		client.mtx.Lock()
+		defer client.mtx.Unlock()
+		return len(client.requests)
+	})
+	assert.Len(t, client.requests[0].Timeseries[0].Samples, 1)
+}
+
+func TestAggregator_ShouldDrainTheForwardQueueOnShutdown(t *testing.T) {
+	client := &aggregateClientMock{}
+	pool := ring_client.NewPool("distributor", ring_client.PoolConfig{}, nil, func(string) (ring_client.PoolClient, error) {
+		return client, nil
+	}, prometheus.NewGauge(prometheus.GaugeOpts{Name: "clients"}), log.NewNopLogger())
+
+	a := newAggregator(AggregationConfig{Interval: time.Minute, ForwardQueueCapacity: 10, ForwardWorkers: 1, ForwardDrainTimeout: 5 * time.Second}, nil, time.Second, prometheus.NewPedanticRegistry(), log.NewNopLogger())
+	a.withSharding(nil, nil, pool)
+
+	for range 3 {
+		ts := cortexpb.PreallocTimeseries{TimeSeries: &cortexpb.TimeSeries{Samples: make([]cortexpb.Sample, 1)}}
+		r := &cortexpb.WriteRequest{Timeseries: []cortexpb.PreallocTimeseries{ts}}
+		a.enqueueForward(aggregationForwardRequest{userID: "user", addr: "distributor-1", req: r, samples: countAggregationSamples(r)})
+	}
+
+	// The requests queued when the aggregator stops are still sent.
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), a))
+	require.NoError(t, services.StopAndAwaitTerminated(context.Background(), a))
+
+	client.mtx.Lock()
+	defer client.mtx.Unlock()
+	assert.Len(t, client.requests, 3)
+	assert.Equal(t, 0.0, testutil.ToFloat64(a.dropped.WithLabelValues("user", aggregationDropReasonFailed)))
+}
@@ -28,6 +28,8 @@ import (
 	"github.com/weaveworks/common/instrument"
 	"github.com/weaveworks/common/user"
 	"go.uber.org/atomic"
+	"google.golang.org/grpc"
+	grpcmetadata "google.golang.org/grpc/metadata"
 
 	"github.com/cortexproject/cortex/pkg/cortexpb"
 	"github.com/cortexproject/cortex/pkg/ha"
@@ -36,7 +38,7 @@ import (
 	"github.com/cortexproject/cortex/pkg/ring"
 	ring_client "github.com/cortexproject/cortex/pkg/ring/client"
 	"github.com/cortexproject/cortex/pkg/util"
-	"github.com/cortexproject/cortex/pkg/util/extract"
+	"github.com/cortexproject/cortex/pkg/util/discardedseries"
 	"github.com/cortexproject/cortex/pkg/util/flagext"
 	"github.com/cortexproject/cortex/pkg/util/labelset"
 	"github.com/cortexproject/cortex/pkg/util/limiter"
@@ -99,6 +101,19 @@ type Distributor struct {
 	// For handling HA replicas.
 	HATracker *ha.HATracker
 
+	// For forwarding series to the tenants' remote write endpoints.
+	forwarder  *forwarder
+	aggregator *aggregator
+
+	// For buffering the write requests while the ingesters are unavailable, nil if disabled.
+	writeBuffer *writeBuffer
+
+	// For sampling the discarded series of the tenants.
+	discardedSeriesSampler *discardedseries.Sampler
+
+	// For lowering the ingestion rate limits while the ingesters are under pressure, nil if disabled.
+	adaptiveIngestionRate *adaptiveIngestionRate
+
 	// Per-user rate limiter.
 	ingestionRateLimiter                *limiter.RateLimiter
 	nativeHistogramIngestionRateLimiter *limiter.RateLimiter
@@ -190,6 +205,12 @@ type Config struct {
 	// OTLPConfig
 	OTLPConfig OTLPConfig `yaml:"otlp"`
 
+	Forwarding  ForwardingConfig  `yaml:"forwarding"`
+	Aggregation AggregationConfig `yaml:"aggregation"`
+	WriteBuffer WriteBufferConfig `yaml:"write_buffer"`
+
+	AdaptiveIngestionRate AdaptiveIngestionRateConfig `yaml:"adaptive_ingestion_rate"`
+
 	// Inject from global config
 	NameValidationScheme model.ValidationScheme `yaml:"-"`
 }
@@ -213,6 +234,10 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	cfg.PoolConfig.RegisterFlags(f)
 	cfg.HATrackerConfig.RegisterFlagsWithPrefix("distributor.", "", f)
 	cfg.DistributorRing.RegisterFlags(f)
+	cfg.Forwarding.RegisterFlags(f)
+	cfg.Aggregation.RegisterFlags(f)
+	cfg.WriteBuffer.RegisterFlags(f)
+	cfg.AdaptiveIngestionRate.RegisterFlags(f)
 
 	f.IntVar(&cfg.MaxRecvMsgSize, "distributor.max-recv-msg-size", 100<<20, "remote_write API max receive message size (bytes).")
 	f.IntVar(&cfg.OTLPMaxRecvMsgSize, "distributor.otlp-max-recv-msg-size", 100<<20, "Maximum OTLP request size in bytes that the Distributor can accept.")
@@ -254,6 +279,26 @@ func (cfg *Config) Validate(limits validation.Limits) error {
 		return err
 	}
 
+	if err := cfg.Forwarding.Validate(); err != nil {
+		return err
+	}
+
+	if err := cfg.Aggregation.Validate(); err != nil {
+		return err
+	}
+
+	if err := cfg.Aggregation.ValidateLimits(limits); err != nil {
+		return err
+	}
+
+	if err := cfg.WriteBuffer.Validate(); err != nil {
+		return err
+	}
+
+	if err := cfg.AdaptiveIngestionRate.Validate(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -293,21 +338,29 @@ func New(cfg Config, clientConfig ingester_client.Config, limits *validation.Ove
 	var distributorsLifeCycler *ring.Lifecycler
 	var distributorsRing *ring.Ring
 
-	if !canJoinDistributorsRing {
-		ingestionRateStrategy = newInfiniteIngestionRateStrategy()
-		nativeHistogramIngestionRateStrategy = newInfiniteIngestionRateStrategy()
-	} else if limits.IngestionRateStrategy() == validation.GlobalIngestionRateStrategy {
+	// The distributors ring is also used to shard the aggregation rules. In case this
+	// distributor can't join it, it only uses the ring to find the owners of the aggregations.
+	joinDistributorsRing := canJoinDistributorsRing && (limits.IngestionRateStrategy() == validation.GlobalIngestionRateStrategy || cfg.Aggregation.ShardingEnabled)
+	if joinDistributorsRing {
 		distributorsLifeCycler, err = ring.NewLifecycler(cfg.DistributorRing.ToLifecyclerConfig(), nil, "distributor", ringKey, true, true, log, prometheus.WrapRegistererWithPrefix("cortex_", reg))
 		if err != nil {
 			return nil, err
 		}
+		subservices = append(subservices, distributorsLifeCycler)
+	}
 
+	if joinDistributorsRing || cfg.Aggregation.ShardingEnabled {
 		distributorsRing, err = ring.New(cfg.DistributorRing.ToRingConfig(), "distributor", ringKey, log, prometheus.WrapRegistererWithPrefix("cortex_", reg))
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to initialize distributors' ring client")
 		}
-		subservices = append(subservices, distributorsLifeCycler, distributorsRing)
+		subservices = append(subservices, distributorsRing)
+	}
 
+	if !canJoinDistributorsRing {
+		ingestionRateStrategy = newInfiniteIngestionRateStrategy()
+		nativeHistogramIngestionRateStrategy = newInfiniteIngestionRateStrategy()
+	} else if limits.IngestionRateStrategy() == validation.GlobalIngestionRateStrategy {
 		ingestionRateStrategy = newGlobalIngestionRateStrategy(limits, distributorsLifeCycler)
 		nativeHistogramIngestionRateStrategy = newGlobalNativeHistogramIngestionRateStrategy(limits, distributorsLifeCycler)
 	} else {
@@ -315,6 +368,13 @@ func New(cfg Config, clientConfig ingester_client.Config, limits *validation.Ove
 		nativeHistogramIngestionRateStrategy = newLocalNativeHistogramIngestionRateStrategy(limits)
 	}
 
+	var adaptiveRate *adaptiveIngestionRate
+	if canJoinDistributorsRing && cfg.AdaptiveIngestionRate.Enabled {
+		adaptiveRate = newAdaptiveIngestionRate(cfg.AdaptiveIngestionRate, ingestionRateStrategy, reg, log)
+		ingestionRateStrategy = adaptiveRate
+		subservices = append(subservices, adaptiveRate)
+	}
+
 	d := &Distributor{
 		cfg:                                 cfg,
 		log:                                 log,
@@ -326,6 +386,9 @@ func New(cfg Config, clientConfig ingester_client.Config, limits *validation.Ove
 		ingestionRateLimiter:                limiter.NewRateLimiter(ingestionRateStrategy, 10*time.Second),
 		nativeHistogramIngestionRateLimiter: limiter.NewRateLimiter(nativeHistogramIngestionRateStrategy, 10*time.Second),
 		HATracker:                           haTracker,
+		forwarder:                           newForwarder(cfg.Forwarding, reg, log),
+		discardedSeriesSampler:              discardedseries.NewSampler(limits),
+		adaptiveIngestionRate:               adaptiveRate,
 		ingestionRate:                       util_math.NewEWMARate(0.2, instanceIngestionRateTickInterval),
 
 		queryDuration: instrument.NewHistogramCollector(promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
@@ -483,7 +546,19 @@ func New(cfg Config, clientConfig ingester_client.Config, limits *validation.Ove
 	d.replicationFactor.Set(float64(ingestersRing.ReplicationFactor()))
 	d.activeUsers = users.NewActiveUsersCleanupWithDefaultValues(d.cleanupInactiveUser)
 
-	subservices = append(subservices, d.ingesterPool, d.activeUsers)
+	d.aggregator = newAggregator(cfg.Aggregation, d.Push, cfg.RemoteTimeout, reg, log)
+	if cfg.Aggregation.ShardingEnabled {
+		pool := newDistributorClientPool(clientConfig.GRPCClientConfig, log, reg)
+		d.aggregator.withSharding(distributorsRing, distributorsLifeCycler, pool)
+		subservices = append(subservices, pool)
+	}
+
+	subservices = append(subservices, d.ingesterPool, d.activeUsers, d.forwarder, d.aggregator)
+	if cfg.WriteBuffer.Dir != "" {
+		d.writeBuffer = newWriteBuffer(cfg.WriteBuffer, d.pushBuffered, limits.WriteBufferMaxBytes, reg, log)
+		subservices = append(subservices, d.writeBuffer)
+	}
+
 	d.subservices, err = services.NewManager(subservices...)
 	if err != nil {
 		return nil, err
@@ -534,6 +609,11 @@ func (d *Distributor) cleanupInactiveUser(userID string) {
 	d.ingestersRing.CleanupShuffleShardCache(userID)
 
 	d.HATracker.CleanupHATrackerMetricsForUser(userID)
+	d.forwarder.cleanupInactiveUser(userID)
+	d.aggregator.cleanupInactiveUser(userID)
+	d.writeBuffer.cleanupInactiveUser(userID)
+	d.discardedSeriesSampler.RemoveUser(userID)
+	d.adaptiveIngestionRate.cleanupInactiveUser(userID)
 
 	d.receivedSamples.DeleteLabelValues(userID, sampleMetricTypeFloat)
 	d.receivedSamples.DeleteLabelValues(userID, sampleMetricTypeHistogram)
@@ -566,49 +646,18 @@ func (d *Distributor) stopping(_ error) error {
 }
 
 func (d *Distributor) tokenForLabels(userID string, labels []cortexpb.LabelAdapter) (uint32, error) {
-	if d.cfg.ShardByAllLabels {
-		return shardByAllLabels(userID, labels), nil
-	}
-
-	unsafeMetricName, err := extract.UnsafeMetricNameFromLabelAdapters(labels)
-	if err != nil {
-		return 0, err
-	}
-	return shardByMetricName(userID, unsafeMetricName), nil
+	return ingester_client.TokenForLabels(userID, labels, d.cfg.ShardByAllLabels)
 }
 
+// tokenForMetadata returns the token of the metadata of the given metric. If the series metadata
+// is enabled for the user, the metadata is always sharded by metric name, so that the ingesters
+// owning the series of the metric also get its metadata when the series are sharded by metric name.
 func (d *Distributor) tokenForMetadata(userID string, metricName string) uint32 {
-	if d.cfg.ShardByAllLabels {
-		return shardByMetricName(userID, metricName)
+	if d.cfg.ShardByAllLabels || d.limits.EnableSeriesMetadata(userID) {
+		return ingester_client.ShardByMetricName(userID, metricName)
 	}
 
-	return shardByUser(userID)
-}
-
-// shardByMetricName returns the token for the given metric. The provided metricName
-// is guaranteed to not be retained.
-func shardByMetricName(userID string, metricName string) uint32 {
-	h := shardByUser(userID)
-	h = ingester_client.HashAdd32(h, metricName)
-	return h
-}
-
-func shardByUser(userID string) uint32 {
-	h := ingester_client.HashNew32()
-	h = ingester_client.HashAdd32(h, userID)
-	return h
-}
-
-// This function generates different values for different order of same labels.
-func shardByAllLabels(userID string, labels []cortexpb.LabelAdapter) uint32 {
-	h := shardByUser(userID)
-	for _, label := range labels {
-		if len(label.Value) > 0 {
-			h = ingester_client.HashAdd32(h, label.Name)
-			h = ingester_client.HashAdd32(h, label.Value)
-		}
-	}
-	return h
+	return ingester_client.ShardByUser(userID)
 }
 
 // Remove the label labelname from a slice of LabelPairs if it exists.
@@ -728,6 +777,26 @@ func (d *Distributor) validateSeries(ts cortexpb.PreallocTimeseries, userID stri
 		nil
 }
 
+// sampleDiscardedSeries records the discarded series for debugging, if enabled for the tenant.
+func (d *Distributor) sampleDiscardedSeries(userID, reason string, ts *cortexpb.TimeSeries, err error) bool {
+	timestampMs := int64(0)
+	if len(ts.Samples) > 0 {
+		timestampMs = ts.Samples[0].TimestampMs
+	} else if len(ts.Histograms) > 0 {
+		timestampMs = ts.Histograms[0].TimestampMs
+	}
+	return d.discardedSeriesSampler.Sample(userID, reason, ts.Labels, timestampMs, err, time.Now())
+}
+
+// sampleDiscardedTimeseries records the series of a discarded request until the tenant's sampling rate is exceeded.
+func (d *Distributor) sampleDiscardedTimeseries(userID, reason string, timeseries []cortexpb.PreallocTimeseries, err error) {
+	for _, ts := range timeseries {
+		if !d.sampleDiscardedSeries(userID, reason, ts.TimeSeries, err) {
+			return
+		}
+	}
+}
+
 // Push implements client.IngesterServer
 func (d *Distributor) Push(ctx context.Context, req *cortexpb.WriteRequest) (*cortexpb.WriteResponse, error) {
 	var validationError = true
@@ -847,23 +916,35 @@ func (d *Distributor) Push(ctx context.Context, req *cortexpb.WriteRequest) (*co
 		// Return a 429 here to tell the client it is going too fast.
 		// Client may discard the data or slow down and re-send.
 		// Prometheus v2.26 added a remote-write option 'retry_on_http_429'.
-		return nil, httpgrpc.Errorf(http.StatusTooManyRequests, "ingestion rate limit (%v) exceeded while adding %d samples and %d metadata", d.ingestionRateLimiter.Limit(now, userID), totalSamples, len(validatedMetadata))
+		err := d.adaptiveIngestionRate.rateLimitedError(userID, fmt.Sprintf("ingestion rate limit (%v) exceeded while adding %d samples and %d metadata", d.ingestionRateLimiter.Limit(now, userID), totalSamples, len(validatedMetadata)))
+		d.sampleDiscardedTimeseries(userID, validation.RateLimited, validatedTimeseries, err)
+		d.sampleDiscardedTimeseries(userID, validation.RateLimited, nhValidatedTimeseries, err)
+		return nil, err
 	}
 
 	// totalN included samples and metadata. Ingester follows this pattern when computing its ingestion rate.
 	d.ingestionRate.Add(int64(totalN))
+	d.adaptiveIngestionRate.observe(userID, totalN)
 
 	var nativeHistogramErr error
 
 	if !d.nativeHistogramIngestionRateLimiter.AllowN(now, userID, validatedHistogramSamples) {
 		d.validateMetrics.DiscardedSamples.WithLabelValues(validation.NativeHistogramRateLimited, userID).Add(float64(validatedHistogramSamples))
 		nativeHistogramErr = httpgrpc.Errorf(http.StatusTooManyRequests, "native histogram ingestion rate limit (%v) exceeded while adding %d native histogram samples", d.nativeHistogramIngestionRateLimiter.Limit(now, userID), validatedHistogramSamples)
+		d.sampleDiscardedTimeseries(userID, validation.NativeHistogramRateLimited, nhValidatedTimeseries, nativeHistogramErr)
 		validatedHistogramSamples = 0
 	} else {
 		seriesKeys = append(seriesKeys, nhSeriesKeys...)
 		validatedTimeseries = append(validatedTimeseries, nhValidatedTimeseries...)
 	}
 
+	// The aggregated series pushed by the aggregator are not aggregated again.
+	if len(limits.AggregationRules) > 0 && !isAggregationOutput(ctx) {
+		if dropped := d.aggregator.process(userID, limits.AggregationRules, validatedTimeseries, true); dropped != nil {
+			seriesKeys, validatedTimeseries = filterDroppedSeries(seriesKeys, validatedTimeseries, dropped)
+		}
+	}
+
 	subRing := d.ingestersRing
 
 	// Obtain a subring if required.
@@ -878,12 +959,63 @@ func (d *Distributor) Push(ctx context.Context, req *cortexpb.WriteRequest) (*co
 		return nil, nativeHistogramErr
 	}
 
-	//DoBatch will be responsible to call cleanup after all async ingester requests finish.
-	validationError = false
+	// All the series may have been dropped by the aggregation rules.
+	if len(keys) == 0 {
+		return &cortexpb.WriteResponse{}, firstPartialErr
+	}
+
+	// Forwarding is asynchronous and never fails the push, but the series must be
+	// copied before the request is released by doBatch.
+	d.forwarder.forward(userID, limits.ForwardingEndpoints, validatedTimeseries, validatedMetadata)
 
-	err = d.doBatch(ctx, req, subRing, keys, initialMetadataIndex, validatedMetadata, validatedTimeseries, userID)
-	if err != nil {
-		return nil, err
+	// The request is only marshaled when it's buffered.
+	bufferEnabled := d.writeBuffer.enabled(userID)
+	marshalBufferReq := func() ([]byte, error) {
+		bufferReq := cortexpb.WriteRequest{Timeseries: validatedTimeseries, Metadata: validatedMetadata, Source: req.Source, DiscardOutOfOrder: req.DiscardOutOfOrder}
+		return bufferReq.Marshal()
+	}
+
+	// While the tenant has buffered requests, the new ones are buffered too so that they're
+	// replayed in order.
+	buffered := false
+	if bufferEnabled {
+		if buffered, err = d.writeBuffer.addIfPending(userID, marshalBufferReq, now); err != nil {
+			return nil, httpgrpc.Errorf(http.StatusServiceUnavailable, "failed to buffer write request: %s", err.Error())
+		}
+	}
+
+	if !buffered {
+		//DoBatch will be responsible to call cleanup after all async ingester requests finish.
+		validationError = false
+
+		release := func() {
+			cortexpb.ReuseSlice(req.Timeseries)
+			req.Free()
+		}
+		// When the request may be buffered, the series are only released once both the
+		// ingester requests have finished and the request has been buffered if the push failed.
+		if bufferEnabled {
+			refs := atomic.NewInt32(2)
+			free := release
+			release = func() {
+				if refs.Dec() == 0 {
+					free()
+				}
+			}
+		}
+
+		err = d.doBatch(ctx, req, subRing, keys, initialMetadataIndex, validatedMetadata, validatedTimeseries, userID, release)
+		if bufferEnabled {
+			// Requests failing because the ingesters are unavailable are buffered, to be
+			// replayed once they're reachable again.
+			if err != nil && isBufferableError(err) && d.bufferWriteRequest(userID, marshalBufferReq, now) == nil {
+				err = nil
+			}
+			release()
+		}
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	resp := &cortexpb.WriteResponse{}
@@ -962,7 +1094,19 @@ func (d *Distributor) cleanStaleIngesterMetrics() {
 	}
 }
 
-func (d *Distributor) doBatch(ctx context.Context, req *cortexpb.WriteRequest, subRing ring.ReadRing, keys []uint32, initialMetadataIndex int, validatedMetadata []*cortexpb.MetricMetadata, validatedTimeseries []cortexpb.PreallocTimeseries, userID string) error {
+// bufferWriteRequest marshals a write request failed because the ingesters are unavailable
+// and adds it to the write buffer of the tenant.
+func (d *Distributor) bufferWriteRequest(userID string, marshal func() ([]byte, error), now time.Time) error {
+	payload, err := marshal()
+	if err != nil {
+		return err
+	}
+	return d.writeBuffer.add(userID, payload, now)
+}
+
+// doBatch pushes the series and metadata to the ingesters. The release function is called
+// once all the ingester requests have finished, to release the series of the request.
+func (d *Distributor) doBatch(ctx context.Context, req *cortexpb.WriteRequest, subRing ring.ReadRing, keys []uint32, initialMetadataIndex int, validatedMetadata []*cortexpb.MetricMetadata, validatedTimeseries []cortexpb.PreallocTimeseries, userID string, release func()) error {
 	span, _ := opentracing.StartSpanFromContext(ctx, "doBatch")
 	defer span.Finish()
 
@@ -1005,9 +1149,35 @@ func (d *Distributor) doBatch(ctx context.Context, req *cortexpb.WriteRequest, s
 
 		return d.send(localCtx, ingester, timeseries, metadata, req.Source, req.DiscardOutOfOrder)
 	}, func() {
+		release()
+		cancel()
+	})
+}
+
+// pushBuffered pushes to the ingesters a write request replayed from the write buffer. The
+// request has already been validated when it's been received.
+func (d *Distributor) pushBuffered(ctx context.Context, userID string, req *cortexpb.WriteRequest) error {
+	keys := make([]uint32, 0, len(req.Timeseries)+len(req.Metadata))
+	for _, ts := range req.Timeseries {
+		key, err := d.tokenForLabels(userID, ts.Labels)
+		if err != nil {
+			cortexpb.ReuseSlice(req.Timeseries)
+			return httpgrpc.Errorf(http.StatusBadRequest, "%s", err.Error())
+		}
+		keys = append(keys, key)
+	}
+	for _, m := range req.Metadata {
+		keys = append(keys, d.tokenForMetadata(userID, m.MetricFamilyName))
+	}
+
+	subRing := d.ingestersRing
+	if d.cfg.ShardingStrategy == util.ShardingStrategyShuffle {
+		subRing = d.ingestersRing.ShuffleShard(userID, d.limits.IngestionTenantShardSize(userID))
+	}
+
+	return d.doBatch(ctx, req, subRing, keys, len(req.Timeseries), req.Metadata, req.Timeseries, userID, func() {
 		cortexpb.ReuseSlice(req.Timeseries)
 		req.Free()
-		cancel()
 	})
 }
 
@@ -1157,6 +1327,7 @@ func (d *Distributor) prepareSeriesKeys(ctx context.Context, req *cortexpb.Write
 			}
 			d.validateMetrics.DiscardedSamples.WithLabelValues(reason, userID).Add(samplesCount)
 			d.validateMetrics.DiscardedExemplars.WithLabelValues(reason, userID).Add(exemplarsCount)
+			d.sampleDiscardedSeries(userID, reason, ts.TimeSeries, validationErr)
 			continue
 		}
 
@@ -1183,6 +1354,21 @@ func (d *Distributor) prepareSeriesKeys(ctx context.Context, req *cortexpb.Write
 		// later in the validation phase, we ignore them here.
 		sortLabelsIfNeeded(ts.Labels)
 
+		if validationErr, reason := validation.ValidateSeriesPolicy(limits, ts.Labels); reason != "" {
+			samplesCount := float64(len(ts.Samples) + len(ts.Histograms))
+			if limits.SeriesPolicy.WarnOnly {
+				d.validateMetrics.SeriesPolicyWarnings.WithLabelValues(reason, userID).Add(samplesCount)
+			} else {
+				if firstPartialErr == nil {
+					firstPartialErr = httpgrpc.Errorf(http.StatusBadRequest, "%s", validationErr.Error())
+				}
+				d.validateMetrics.DiscardedSamples.WithLabelValues(reason, userID).Add(samplesCount)
+				d.validateMetrics.DiscardedExemplars.WithLabelValues(reason, userID).Add(float64(len(ts.Exemplars)))
+				d.sampleDiscardedSeries(userID, reason, ts.TimeSeries, validationErr)
+				continue
+			}
+		}
+
 		// Generate the sharding token based on the series labels without the HA replica
 		// label and dropped labels (if any)
 		key, err := d.tokenForLabels(userID, ts.Labels)
@@ -1198,6 +1384,9 @@ func (d *Distributor) prepareSeriesKeys(ctx context.Context, req *cortexpb.Write
 			// use case because we format it calling Error() and then we discard it.
 			firstPartialErr = httpgrpc.Errorf(http.StatusBadRequest, "%s", validationErr.Error())
 		}
+		if validationErr != nil {
+			d.sampleDiscardedSeries(userID, validation.DiscardReason(validationErr), ts.TimeSeries, validationErr)
+		}
 
 		// validateSeries would have returned an emptyPreallocSeries if there were no valid samples.
 		if validatedSeries == emptyPreallocSeries {
@@ -1287,6 +1476,7 @@ func (d *Distributor) send(ctx context.Context, ingester ring.InstanceDesc, time
 	d.inflightClientRequests.Inc()
 	defer d.inflightClientRequests.Dec()
 
+	var resp *cortexpb.WriteResponse
 	if d.cfg.UseStreamPush {
 		req := &cortexpb.WriteRequest{
 			Timeseries:        timeseries,
@@ -1294,7 +1484,7 @@ func (d *Distributor) send(ctx context.Context, ingester ring.InstanceDesc, time
 			Source:            source,
 			DiscardOutOfOrder: discardOutOfOrder,
 		}
-		_, err = c.PushStreamConnection(ctx, req)
+		resp, err = c.PushStreamConnection(ctx, req)
 	} else {
 		req := cortexpb.PreallocWriteRequestFromPool()
 		req.Timeseries = timeseries
@@ -1302,7 +1492,12 @@ func (d *Distributor) send(ctx context.Context, ingester ring.InstanceDesc, time
 		req.Source = source
 		req.DiscardOutOfOrder = discardOutOfOrder
 
-		_, err = c.PushPreAlloc(ctx, req)
+		var trailer grpcmetadata.MD
+		resp, err = c.PushPreAlloc(ctx, req, grpc.Trailer(&trailer))
+		if err != nil {
+			// The ingester reports its utilization in the trailer along with a failed push.
+			resp = ingester_client.UtilizationFromTrailer(trailer)
+		}
 
 		// We should not reuse the req in case of errors:
 		// See: https://github.com/grpc/grpc-go/issues/6355
@@ -1311,9 +1506,18 @@ func (d *Distributor) send(ctx context.Context, ingester ring.InstanceDesc, time
 		}
 	}
 
+	// The utilization is recorded on failed pushes too, since they're likely to be caused by the
+	// ingester pressure. The stream push reports it in the response sent along with the error.
+	d.adaptiveIngestionRate.observeIngester(ingester.Addr, resp, time.Now())
+
 	if len(metadata) > 0 {
 		d.ingesterAppends.WithLabelValues(id, typeMetadata).Inc()
-		if err != nil {
+
+		// The metadata is sent along with the first batch of a stream push split by the flow
+		// control, so it may have been accepted even if some series failed.
+		var partialErr *ingester_client.PartialPushError
+		metadataAccepted := errors.As(err, &partialErr) && len(partialErr.Remaining().Metadata) == 0
+		if err != nil && !metadataAccepted {
 			d.ingesterAppendFailures.WithLabelValues(id, typeMetadata, getErrorStatus(err)).Inc()
 		}
 	}
@@ -1685,16 +1889,92 @@ func (d *Distributor) UserStats(ctx context.Context) (*ingester.UserStats, error
 		totalStats.RuleIngestionRate += r.RuleIngestionRate
 		totalStats.NumSeries += r.NumSeries
 		totalStats.ActiveSeries += r.ActiveSeries
+		totalStats.SeriesChurn += r.SeriesChurn
 	}
 
 	factor := d.ingestersRing.ReplicationFactor()
 	totalStats.IngestionRate /= float64(factor)
 	totalStats.NumSeries /= uint64(factor)
 	totalStats.ActiveSeries /= uint64(factor)
+	totalStats.SeriesChurn /= uint64(factor)
 
 	return totalStats, nil
 }
 
+// TopSeries returns the top metric names and label pairs of the current user, by active series and
+// by series churn, aggregated across the ingesters. The per-ingester entries are estimates, and an
+// entry which is not in the top entries of every ingester can be underestimated.
+func (d *Distributor) TopSeries(ctx context.Context, limit int) (*ingester.TopSeries, error) {
+	replicationSet, err := d.GetIngestersForMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Make sure we get a successful response from all of them.
+	replicationSet.MaxErrors = 0
+
+	req := &ingester_client.TopSeriesRequest{Limit: uint32(limit)}
+	resps, err := d.ForReplicationSet(ctx, replicationSet, false, false, func(ctx context.Context, client ingester_client.IngesterClient) (any, error) {
+		return client.TopSeries(ctx, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	all := make([]ingester.TopSeries, 0, len(resps))
+	for _, resp := range resps {
+		all = append(all, ingester.TopSeriesFromProto(resp.(*ingester_client.TopSeriesResponse)))
+	}
+
+	factor := uint64(d.ingestersRing.ReplicationFactor())
+	merge := func(get func(ingester.TopSeries) []ingester.TopSeriesEntry) []ingester.TopSeriesEntry {
+		return mergeTopSeriesEntries(all, get, factor, limit)
+	}
+
+	return &ingester.TopSeries{
+		ActiveMetricNames: merge(func(t ingester.TopSeries) []ingester.TopSeriesEntry { return t.ActiveMetricNames }),
+		ActiveLabelPairs:  merge(func(t ingester.TopSeries) []ingester.TopSeriesEntry { return t.ActiveLabelPairs }),
+		ChurnMetricNames:  merge(func(t ingester.TopSeries) []ingester.TopSeriesEntry { return t.ChurnMetricNames }),
+		ChurnLabelPairs:   merge(func(t ingester.TopSeries) []ingester.TopSeriesEntry { return t.ChurnLabelPairs }),
+	}, nil
+}
+
+// mergeTopSeriesEntries sums the counts of the same entry across ingesters, divides them by the
+// replication factor and returns up to limit entries with the highest count. A limit <= 0 keeps the
+// number of entries returned by the ingesters.
+func mergeTopSeriesEntries(all []ingester.TopSeries, get func(ingester.TopSeries) []ingester.TopSeriesEntry, factor uint64, limit int) []ingester.TopSeriesEntry {
+	type key struct{ name, value string }
+
+	var (
+		totals     = map[key]uint64{}
+		maxEntries = 0
+	)
+	for _, t := range all {
+		entries := get(t)
+		maxEntries = max(maxEntries, len(entries))
+		for _, e := range entries {
+			totals[key{e.Name, e.Value}] += e.Count
+		}
+	}
+
+	merged := make([]ingester.TopSeriesEntry, 0, len(totals))
+	for k, count := range totals {
+		if count /= factor; count == 0 {
+			continue
+		}
+		merged = append(merged, ingester.TopSeriesEntry{Name: k.name, Value: k.value, Count: count})
+	}
+
+	ingester.SortTopSeriesEntries(merged)
+	if limit <= 0 {
+		limit = maxEntries
+	}
+	if len(merged) > limit {
+		merged = merged[:limit]
+	}
+	return merged
+}
+
 // AllUserStats returns statistics about all users.
 // Note it does not divide by the ReplicationFactor like UserStats()
 func (d *Distributor) AllUserStats(ctx context.Context) ([]ingester.UserIDStats, int, error) {
@@ -1730,6 +2010,7 @@ func (d *Distributor) AllUserStats(ctx context.Context) ([]ingester.UserIDStats,
 			s.NumSeries += u.Data.NumSeries
 			s.ActiveSeries += u.Data.ActiveSeries
 			s.LoadedBlocks += u.Data.LoadedBlocks
+			s.SeriesChurn += u.Data.SeriesChurn
 			s.QueriedIngesters += 1
 			perUserTotals[u.UserId] = s
 		}
@@ -1748,6 +2029,7 @@ func (d *Distributor) AllUserStats(ctx context.Context) ([]ingester.UserIDStats,
 				ActiveSeries:      stats.ActiveSeries,
 				LoadedBlocks:      stats.LoadedBlocks,
 				QueriedIngesters:  stats.QueriedIngesters,
+				SeriesChurn:       stats.SeriesChurn,
 			},
 		})
 	}
@@ -2,12 +2,14 @@ package distributor
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"maps"
 	"math"
 	"math/rand"
 	"net/http"
+	"net/http/httptest"
 	"sort"
 	"strconv"
 	"strings"
@@ -114,6 +116,29 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			expected: errInvalidTenantShardSize,
 		},
+		"should fail because the forwarding config is invalid": {
+			initConfig: func(cfg *Config) {
+				cfg.Forwarding.Workers = 0
+			},
+			initLimits: func(_ *validation.Limits) {},
+			expected:   errInvalidForwardingWorkers,
+		},
+		"should fail if aggregation rules are configured without aggregation sharding": {
+			initConfig: func(_ *Config) {},
+			initLimits: func(limits *validation.Limits) {
+				limits.AggregationRules = validation.AggregationRules{{Record: "foo:sum", Matchers: `{__name__="foo"}`}}
+			},
+			expected: errAggregationShardingDisabled,
+		},
+		"should pass if aggregation rules are configured with aggregation sharding": {
+			initConfig: func(cfg *Config) {
+				cfg.Aggregation.ShardingEnabled = true
+			},
+			initLimits: func(limits *validation.Limits) {
+				limits.AggregationRules = validation.AggregationRules{{Record: "foo:sum", Matchers: `{__name__="foo"}`}}
+			},
+			expected: nil,
+		},
 	}
 
 	for testName, testData := range tests {
@@ -2189,6 +2214,119 @@ func TestDistributor_Push_LabelRemoval_RemovingNameLabelWillError(t *testing.T)
 	assert.Equal(t, "rpc error: code = Code(400) desc = sample missing metric name", err.Error())
 }
 
+func TestDistributor_Push_SeriesPolicy(t *testing.T) {
+	t.Parallel()
+
+	for name, warnOnly := range map[string]bool{"enforced": false, "warn only": true} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			limits := &validation.Limits{}
+			flagext.DefaultValues(limits)
+			limits.SeriesPolicy = validation.SeriesPolicy{RequiredLabels: []string{"team"}, WarnOnly: warnOnly}
+			require.NoError(t, limits.SeriesPolicy.Validate())
+
+			ds, ingesters, regs, _ := prepare(t, prepConfig{
+				numIngesters:     3,
+				happyIngesters:   3,
+				numDistributors:  1,
+				shardByAllLabels: true,
+				limits:           limits,
+			})
+
+			req := mockWriteRequest([]labels.Labels{
+				labels.FromStrings(labels.MetricName, "foo", "team", "a"),
+				labels.FromStrings(labels.MetricName, "bar"),
+			}, 1, 1000, false)
+
+			ctx := user.InjectOrgID(context.Background(), "user")
+			_, err := ds[0].Push(ctx, req)
+
+			expectedSeries := 2
+			expectedMetrics := `
+				# HELP cortex_series_policy_warnings_total The total number of samples and metadata violating the series policy which were not discarded because the policy is in warn-only mode.
+				# TYPE cortex_series_policy_warnings_total counter
+				cortex_series_policy_warnings_total{reason="series_policy_missing_required_label",user="user"} 1
+			`
+			if warnOnly {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				resp, ok := httpgrpc.HTTPResponseFromError(err)
+				require.True(t, ok)
+				require.Equal(t, int32(http.StatusBadRequest), resp.Code)
+				require.Contains(t, string(resp.Body), `series policy violation (missing required label): "team"`)
+
+				expectedSeries = 1
+				expectedMetrics = `
+					# HELP cortex_discarded_samples_total The total number of samples that were discarded.
+					# TYPE cortex_discarded_samples_total counter
+					cortex_discarded_samples_total{reason="series_policy_missing_required_label",user="user"} 1
+				`
+			}
+
+			test.Poll(t, 5*time.Second, expectedSeries, func() any {
+				return len(ingesters[0].series())
+			})
+			assert.NoError(t, testutil.GatherAndCompare(regs[0], strings.NewReader(expectedMetrics), "cortex_discarded_samples_total", "cortex_series_policy_warnings_total"))
+		})
+	}
+}
+
+func TestDistributor_Push_ShouldSampleDiscardedSeries(t *testing.T) {
+	t.Parallel()
+
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.IngestionRate = 1
+	limits.IngestionBurstSize = 1
+	limits.DiscardedSeriesSamplingRate = 10
+
+	ds, _, _, _ := prepare(t, prepConfig{
+		numIngesters:     3,
+		happyIngesters:   3,
+		numDistributors:  1,
+		shardByAllLabels: true,
+		limits:           limits,
+	})
+	ctx := user.InjectOrgID(context.Background(), "user")
+
+	// The series with an invalid label name fails the validation.
+	_, err := ds[0].Push(ctx, mockWriteRequest([]labels.Labels{
+		labels.FromStrings(labels.MetricName, "foo"),
+		labels.FromStrings(labels.MetricName, "bar", "0invalid", "a"),
+	}, 1, 1000, false))
+	require.Error(t, err)
+
+	// The request exceeds the ingestion rate limit.
+	_, err = ds[0].Push(ctx, mockWriteRequest([]labels.Labels{
+		labels.FromStrings(labels.MetricName, "foo"),
+		labels.FromStrings(labels.MetricName, "baz"),
+	}, 1, 2000, false))
+	require.Error(t, err)
+
+	samples := ds[0].discardedSeriesSampler.Samples("user")
+	require.Len(t, samples, 3)
+
+	for i, expected := range []struct {
+		series      labels.Labels
+		timestampMs int64
+		reason      string
+	}{
+		{series: labels.FromStrings(labels.MetricName, "baz"), timestampMs: 2000, reason: validation.RateLimited},
+		{series: labels.FromStrings(labels.MetricName, "foo"), timestampMs: 2000, reason: validation.RateLimited},
+		{series: labels.FromStrings(labels.MetricName, "bar", "0invalid", "a"), timestampMs: 1000, reason: "label_invalid"},
+	} {
+		assert.Equal(t, expected.series, samples[i].Labels)
+		assert.Equal(t, expected.timestampMs, samples[i].TimestampMs)
+		assert.Equal(t, expected.reason, samples[i].Reason)
+		assert.NotEmpty(t, samples[i].Error)
+	}
+
+	// Nothing is sampled for the tenants without sampling rate.
+	assert.Empty(t, ds[0].discardedSeriesSampler.Samples("other"))
+}
+
 func TestDistributor_Push_ShouldGuaranteeShardingTokenConsistencyOverTheTime(t *testing.T) {
 	t.Parallel()
 	ctx := user.InjectOrgID(context.Background(), "user")
@@ -3263,6 +3401,7 @@ type prepConfig struct {
 	useStreamPush                bool
 	nameValidationScheme         model.ValidationScheme
 	remoteTimeout                time.Duration
+	writeBufferDir               string
 }
 
 type prepState struct {
@@ -3378,6 +3517,11 @@ func prepare(tb testing.TB, cfg prepConfig) ([]*Distributor, []*mockIngester, []
 		distributorCfg.InstanceLimits.MaxInflightClientRequests = cfg.maxInflightClientRequests
 		distributorCfg.InstanceLimits.MaxIngestionRate = cfg.maxIngestionRate
 		distributorCfg.UseStreamPush = cfg.useStreamPush
+		if cfg.writeBufferDir != "" {
+			distributorCfg.WriteBuffer.Dir = cfg.writeBufferDir
+			// Replays are triggered by the tests.
+			distributorCfg.WriteBuffer.ReplayInterval = time.Hour
+		}
 		distributorCfg.NameValidationScheme = model.LegacyValidation
 		if cfg.nameValidationScheme == model.UTF8Validation {
 			distributorCfg.NameValidationScheme = cfg.nameValidationScheme
@@ -3799,7 +3943,7 @@ func (i *mockIngester) Push(ctx context.Context, req *cortexpb.WriteRequest, opt
 
 	for j := range req.Timeseries {
 		series := req.Timeseries[j]
-		hash := shardByAllLabels(orgid, series.Labels)
+		hash := client.ShardByAllLabels(orgid, series.Labels)
 		existing, ok := i.timeseries[hash]
 		if !ok {
 			// Make a copy because the request Timeseries are reused
@@ -3818,7 +3962,7 @@ func (i *mockIngester) Push(ctx context.Context, req *cortexpb.WriteRequest, opt
 	}
 
 	for _, m := range req.Metadata {
-		hash := shardByMetricName(orgid, m.MetricFamilyName)
+		hash := client.ShardByMetricName(orgid, m.MetricFamilyName)
 		set, ok := i.metadata[hash]
 		if !ok {
 			set = map[cortexpb.MetricMetadata]struct{}{}
@@ -4299,13 +4443,13 @@ func TestRemoveReplicaLabel(t *testing.T) {
 // This is not great, but we deal with unsorted labels when validating labels.
 func TestShardByAllLabelsReturnsWrongResultsForUnsortedLabels(t *testing.T) {
 	t.Parallel()
-	val1 := shardByAllLabels("test", []cortexpb.LabelAdapter{
+	val1 := client.ShardByAllLabels("test", []cortexpb.LabelAdapter{
 		{Name: "__name__", Value: "foo"},
 		{Name: "bar", Value: "baz"},
 		{Name: "sample", Value: "1"},
 	})
 
-	val2 := shardByAllLabels("test", []cortexpb.LabelAdapter{
+	val2 := client.ShardByAllLabels("test", []cortexpb.LabelAdapter{
 		{Name: "__name__", Value: "foo"},
 		{Name: "sample", Value: "1"},
 		{Name: "bar", Value: "baz"},
@@ -4971,3 +5115,97 @@ func TestDistributor_ReceivedHistogramBucketsMetric(t *testing.T) {
 	// GenerateTestHistogram(0): 4 positive + 4 negative + 1 zero = 9 buckets
 	require.Equal(t, float64(9), m.GetHistogram().GetSampleSum())
 }
+
+func TestMergeTopSeriesEntries(t *testing.T) {
+	t.Parallel()
+
+	entry := func(value string, count uint64) ingester.TopSeriesEntry {
+		return ingester.TopSeriesEntry{Name: "__name__", Value: value, Count: count}
+	}
+	all := []ingester.TopSeries{
+		{ActiveMetricNames: []ingester.TopSeriesEntry{entry("a", 30), entry("b", 20), entry("c", 3)}},
+		{ActiveMetricNames: []ingester.TopSeriesEntry{entry("b", 25), entry("a", 24), entry("d", 12)}},
+		{ActiveMetricNames: []ingester.TopSeriesEntry{entry("b", 15), entry("d", 12), entry("a", 6)}},
+	}
+	get := func(t ingester.TopSeries) []ingester.TopSeriesEntry { return t.ActiveMetricNames }
+
+	tests := map[string]struct {
+		factor   uint64
+		limit    int
+		expected []ingester.TopSeriesEntry
+	}{
+		"no replication": {
+			factor:   1,
+			limit:    2,
+			expected: []ingester.TopSeriesEntry{entry("a", 60), entry("b", 60)},
+		},
+		"counts are divided by the replication factor": {
+			factor:   3,
+			limit:    3,
+			expected: []ingester.TopSeriesEntry{entry("a", 20), entry("b", 20), entry("d", 8)},
+		},
+		"entries with a count dropped to zero are skipped": {
+			factor:   6,
+			limit:    10,
+			expected: []ingester.TopSeriesEntry{entry("a", 10), entry("b", 10), entry("d", 4)},
+		},
+		"no limit keeps the number of entries returned by the ingesters": {
+			factor:   1,
+			expected: []ingester.TopSeriesEntry{entry("a", 60), entry("b", 60), entry("d", 24)},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.expected, mergeTopSeriesEntries(all, get, tc.factor, tc.limit))
+		})
+	}
+}
+
+func TestDistributor_FailureSimulationHandler(t *testing.T) {
+	ds, _, _, _ := prepare(t, prepConfig{
+		numIngesters:        6,
+		happyIngesters:      6,
+		numDistributors:     1,
+		shardByAllLabels:    true,
+		shuffleShardEnabled: true,
+		shuffleShardSize:    3,
+	})
+	d := ds[0]
+
+	// Failing every instance makes all the tenants lose both writes and reads.
+	rec := httptest.NewRecorder()
+	d.FailureSimulationHandler(rec, httptest.NewRequest(http.MethodGet, "/distributor/failure_simulation?instance=ingester-0,ingester-1&instance=ingester-2,ingester-3,ingester-4,ingester-5&tenant=user-1&tenant=user-2", nil))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	report := ring.FailureSimulationReport{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, []string{"ingester-0", "ingester-1", "ingester-2", "ingester-3", "ingester-4", "ingester-5"}, report.FailedInstances)
+	require.Len(t, report.Tenants, 2)
+	for i, tenant := range report.Tenants {
+		assert.Equal(t, fmt.Sprintf("user-%d", i+1), tenant.Tenant)
+		assert.Equal(t, 3, tenant.ShardSize)
+		assert.Equal(t, 3, tenant.Instances)
+		assert.False(t, tenant.WritesAvailable)
+		assert.Equal(t, ring.ReadsFailed, tenant.Reads)
+	}
+
+	// The tenants which recently pushed are reported by default.
+	ctx := user.InjectOrgID(context.Background(), "user-3")
+	_, err := d.Push(ctx, cortexpb.ToWriteRequest([]labels.Labels{labels.FromStrings(labels.MetricName, "foo")}, []cortexpb.Sample{{Value: 1, TimestampMs: time.Now().UnixMilli()}}, nil, nil, cortexpb.API))
+	require.NoError(t, err)
+
+	rec = httptest.NewRecorder()
+	d.FailureSimulationHandler(rec, httptest.NewRequest(http.MethodGet, "/distributor/failure_simulation?instance=ingester-0", nil))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	require.Len(t, report.Tenants, 1)
+	assert.Equal(t, "user-3", report.Tenants[0].Tenant)
+
+	for _, query := range []string{"", "instance=unknown", "zone=unknown"} {
+		rec = httptest.NewRecorder()
+		d.FailureSimulationHandler(rec, httptest.NewRequest(http.MethodGet, "/distributor/failure_simulation?"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+}
@@ -0,0 +1,448 @@
+package distributor
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/exp/api/remote"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/prometheus/model/labels"
+	writev2 "github.com/prometheus/prometheus/prompb/io/prometheus/write/v2"
+	"github.com/weaveworks/common/user"
+	"go.uber.org/atomic"
+
+	"github.com/cortexproject/cortex/pkg/cortexpb"
+	"github.com/cortexproject/cortex/pkg/util"
+	"github.com/cortexproject/cortex/pkg/util/services"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+const (
+	forwardingIdleCheckInterval = time.Minute
+	forwardingIdleTimeout       = 10 * time.Minute
+
+	forwardingDropReasonQueueFull = "queue_full"
+	forwardingDropReasonFailed    = "failed"
+)
+
+var (
+	errInvalidForwardingQueueCapacity = errors.New("invalid forwarding queue capacity, it must be greater than 0")
+	errInvalidForwardingWorkers       = errors.New("invalid number of forwarding workers, it must be greater than 0")
+	errInvalidForwardingMaxRetries    = errors.New("invalid forwarding max retries, it must be greater than 0")
+)
+
+// ForwardingConfig configures how the distributor forwards series to the forwarding
+// endpoints configured in the tenants' limits.
+type ForwardingConfig struct {
+	QueueCapacity int           `yaml:"queue_capacity"`
+	Workers       int           `yaml:"workers"`
+	Timeout       time.Duration `yaml:"timeout"`
+	MinBackoff    time.Duration `yaml:"min_backoff"`
+	MaxBackoff    time.Duration `yaml:"max_backoff"`
+	MaxRetries    int           `yaml:"max_retries"`
+	DrainTimeout  time.Duration `yaml:"drain_timeout"`
+}
+
+// RegisterFlags adds the flags required to config this to the given FlagSet
+func (cfg *ForwardingConfig) RegisterFlags(f *flag.FlagSet) {
+	f.IntVar(&cfg.QueueCapacity, "distributor.forwarding.queue-capacity", 1000, "EXPERIMENTAL: Maximum number of requests queued for each forwarding endpoint. Once the queue is full, the series to forward are dropped.")
+	f.IntVar(&cfg.Workers, "distributor.forwarding.workers", 4, "EXPERIMENTAL: Number of concurrent requests sent to each forwarding endpoint.")
+	f.DurationVar(&cfg.Timeout, "distributor.forwarding.timeout", 30*time.Second, "EXPERIMENTAL: Timeout for forwarding a request to an endpoint, including retries.")
+	f.DurationVar(&cfg.MinBackoff, "distributor.forwarding.min-backoff", 100*time.Millisecond, "EXPERIMENTAL: Minimum backoff period between retries of a failed forwarding request.")
+	f.DurationVar(&cfg.MaxBackoff, "distributor.forwarding.max-backoff", 5*time.Second, "EXPERIMENTAL: Maximum backoff period between retries of a failed forwarding request.")
+	f.IntVar(&cfg.MaxRetries, "distributor.forwarding.max-retries", 3, "EXPERIMENTAL: Maximum number of retries of a failed forwarding request. Requests are retried on network errors, 5xx and 429 responses.")
+	f.DurationVar(&cfg.DrainTimeout, "distributor.forwarding.drain-timeout", 10*time.Second, "EXPERIMENTAL: Maximum time to wait on shutdown for the queued requests to be forwarded. The requests not forwarded once the timeout expires are dropped.")
+}
+
+// Validate config and returns error on failure
+func (cfg *ForwardingConfig) Validate() error {
+	if cfg.QueueCapacity <= 0 {
+		return errInvalidForwardingQueueCapacity
+	}
+	if cfg.Workers <= 0 {
+		return errInvalidForwardingWorkers
+	}
+	if cfg.MaxRetries <= 0 {
+		return errInvalidForwardingMaxRetries
+	}
+	return nil
+}
+
+// forwardingRequest is a remote write request to forward, already marshaled
+// because the series it's built from are released once the push completes.
+type forwardingRequest struct {
+	userID  string
+	payload encodedRequest
+	samples int
+}
+
+// encodedRequest is a marshaled remote write request, sent as is by the remote write API client.
+type encodedRequest []byte
+
+func (r encodedRequest) Size() int {
+	return len(r)
+}
+
+func (r encodedRequest) MarshalToSizedBuffer(buf []byte) (int, error) {
+	return copy(buf, r), nil
+}
+
+type forwardingQueue struct {
+	endpoint string
+	msgType  remote.WriteMessageType
+	api      *remote.API
+	ch       chan forwardingRequest
+	lastUsed atomic.Int64
+}
+
+// forwarder asynchronously forwards series to remote write endpoints. Each endpoint
+// has its own bounded queue, so a slow or unavailable endpoint never blocks the
+// ingestion nor the forwarding to other endpoints.
+type forwarder struct {
+	services.Service
+
+	cfg    ForwardingConfig
+	log    log.Logger
+	client *http.Client
+
+	// Context used by the workers, canceled when the forwarder stops.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mtx     sync.RWMutex
+	queues  map[string]*forwardingQueue
+	stopped bool
+	wg      sync.WaitGroup
+
+	forwardedSamples *prometheus.CounterVec
+	droppedSamples   *prometheus.CounterVec
+	retries          *prometheus.CounterVec
+	queueLength      *prometheus.GaugeVec
+}
+
+func newForwarder(cfg ForwardingConfig, reg prometheus.Registerer, logger log.Logger) *forwarder {
+	f := &forwarder{
+		cfg: cfg,
+		log: logger,
+		client: &http.Client{
+			Transport: orgIDRoundTripper{next: http.DefaultTransport},
+		},
+		queues: map[string]*forwardingQueue{},
+
+		forwardedSamples: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_distributor_forwarded_samples_total",
+			Help: "The total number of samples successfully forwarded to remote write endpoints.",
+		}, []string{"user", "endpoint"}),
+		droppedSamples: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_distributor_forwarding_dropped_samples_total",
+			Help: "The total number of samples which failed to be forwarded to remote write endpoints.",
+		}, []string{"user", "endpoint", "reason"}),
+		retries: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_distributor_forwarding_retries_total",
+			Help: "The total number of retried requests to remote write endpoints.",
+		}, []string{"endpoint"}),
+		queueLength: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cortex_distributor_forwarding_queue_length",
+			Help: "The number of requests queued to be forwarded to remote write endpoints.",
+		}, []string{"endpoint"}),
+	}
+	f.ctx, f.cancel = context.WithCancel(context.Background())
+
+	f.Service = services.NewTimerService(forwardingIdleCheckInterval, nil, f.iteration, f.stopping)
+	return f
+}
+
+func (f *forwarder) iteration(_ context.Context) error {
+	f.removeIdleQueues(time.Now().Add(-forwardingIdleTimeout))
+	return nil
+}
+
+func (f *forwarder) stopping(_ error) error {
+	// The workers exit once they've sent the requests queued before the queues are closed.
+	f.mtx.Lock()
+	f.stopped = true
+	for key, q := range f.queues {
+		close(q.ch)
+		delete(f.queues, key)
+	}
+	f.mtx.Unlock()
+
+	if !waitWithTimeout(&f.wg, f.cfg.DrainTimeout) {
+		level.Warn(f.log).Log("msg", "timed out forwarding the queued requests on shutdown, dropping the remaining ones", "timeout", f.cfg.DrainTimeout)
+	}
+	// The requests still in-flight or queued fail right away once the context is canceled.
+	f.cancel()
+	f.wg.Wait()
+	return nil
+}
+
+// waitWithTimeout waits for the wait group until the timeout expires, and returns whether
+// the wait group is done.
+func waitWithTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// removeIdleQueues stops the workers of the endpoints which haven't been forwarded any
+// request since the given deadline, e.g. because they've been removed from the limits.
+func (f *forwarder) removeIdleQueues(deadline time.Time) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	for key, q := range f.queues {
+		if q.lastUsed.Load() < deadline.UnixNano() {
+			close(q.ch)
+			delete(f.queues, key)
+			f.queueLength.DeleteLabelValues(q.endpoint)
+		}
+	}
+}
+
+// forward enqueues the series and metadata matching each of the given endpoints. It never
+// blocks: if the queue of an endpoint is full, the data to forward to it is dropped.
+func (f *forwarder) forward(userID string, endpoints validation.ForwardingEndpointsConfig, timeseries []cortexpb.PreallocTimeseries, metadata []*cortexpb.MetricMetadata) {
+	if len(endpoints) == 0 {
+		return
+	}
+
+	seriesLabels := make([]labels.Labels, len(timeseries))
+	for i, ts := range timeseries {
+		seriesLabels[i] = cortexpb.FromLabelAdaptersToLabels(ts.Labels)
+	}
+
+	for i := range endpoints {
+		endpoint := &endpoints[i]
+
+		var (
+			matchedSeries   []cortexpb.PreallocTimeseries
+			matchedMetadata []*cortexpb.MetricMetadata
+			samples         int
+		)
+		for j, ts := range timeseries {
+			if endpoint.MatchesSeries(seriesLabels[j]) {
+				matchedSeries = append(matchedSeries, ts)
+				samples += len(ts.Samples) + len(ts.Histograms)
+			}
+		}
+		for _, m := range metadata {
+			if endpoint.MatchesMetricFamily(m.MetricFamilyName) {
+				matchedMetadata = append(matchedMetadata, m)
+			}
+		}
+
+		var (
+			payload []byte
+			err     error
+		)
+		if endpoint.ProtocolVersion == validation.ForwardingProtocolV2 {
+			// Remote write 2.0 has no standalone metadata, so metadata is only forwarded
+			// along with the series of the same metric family.
+			if len(matchedSeries) == 0 {
+				continue
+			}
+			payload, err = forwardingRequestV2(matchedSeries, matchedMetadata).Marshal()
+		} else {
+			if len(matchedSeries) == 0 && len(matchedMetadata) == 0 {
+				continue
+			}
+			req := cortexpb.WriteRequest{Timeseries: matchedSeries, Metadata: matchedMetadata, Source: cortexpb.API}
+			payload, err = req.Marshal()
+		}
+
+		if err != nil {
+			level.Warn(f.log).Log("msg", "failed to marshal request to forward", "endpoint", redactedURL(endpoint.URL), "user", userID, "err", err)
+			f.droppedSamples.WithLabelValues(userID, redactedURL(endpoint.URL), forwardingDropReasonFailed).Add(float64(samples))
+			continue
+		}
+
+		if err := f.enqueue(endpoint, forwardingRequest{userID: userID, payload: payload, samples: samples}); err != nil {
+			level.Warn(f.log).Log("msg", "failed to create forwarding queue", "endpoint", redactedURL(endpoint.URL), "user", userID, "err", err)
+			f.droppedSamples.WithLabelValues(userID, redactedURL(endpoint.URL), forwardingDropReasonFailed).Add(float64(samples))
+		}
+	}
+}
+
+func (f *forwarder) enqueue(endpoint *validation.ForwardingEndpointConfig, req forwardingRequest) error {
+	msgType := remote.WriteV1MessageType
+	if endpoint.ProtocolVersion == validation.ForwardingProtocolV2 {
+		msgType = remote.WriteV2MessageType
+	}
+	key := string(msgType) + " " + endpoint.URL
+
+	for {
+		f.mtx.RLock()
+		if f.stopped {
+			f.mtx.RUnlock()
+			return nil
+		}
+
+		if q, ok := f.queues[key]; ok {
+			q.lastUsed.Store(time.Now().UnixNano())
+			select {
+			case q.ch <- req:
+				f.queueLength.WithLabelValues(q.endpoint).Inc()
+			default:
+				f.droppedSamples.WithLabelValues(req.userID, q.endpoint, forwardingDropReasonQueueFull).Add(float64(req.samples))
+			}
+			f.mtx.RUnlock()
+			return nil
+		}
+		f.mtx.RUnlock()
+
+		if err := f.createQueue(key, endpoint.URL, msgType); err != nil {
+			return err
+		}
+	}
+}
+
+func (f *forwarder) createQueue(key, endpointURL string, msgType remote.WriteMessageType) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	if _, ok := f.queues[key]; ok || f.stopped {
+		return nil
+	}
+
+	api, err := remote.NewAPI(endpointURL,
+		remote.WithAPIHTTPClient(f.client),
+		remote.WithAPIPath(""),
+		remote.WithAPIBackoff(remote.BackoffConfig{
+			Min:        f.cfg.MinBackoff,
+			Max:        f.cfg.MaxBackoff,
+			MaxRetries: f.cfg.MaxRetries,
+		}),
+	)
+	if err != nil {
+		// The URL is validated with the limits, so this is only expected if the
+		// validation and the remote write client disagree.
+		return err
+	}
+
+	q := &forwardingQueue{
+		endpoint: redactedURL(endpointURL),
+		msgType:  msgType,
+		api:      api,
+		ch:       make(chan forwardingRequest, f.cfg.QueueCapacity),
+	}
+	q.lastUsed.Store(time.Now().UnixNano())
+	f.queues[key] = q
+
+	for range f.cfg.Workers {
+		f.wg.Add(1)
+		go f.runWorker(q)
+	}
+	return nil
+}
+
+func (f *forwarder) runWorker(q *forwardingQueue) {
+	defer f.wg.Done()
+
+	for req := range q.ch {
+		f.queueLength.WithLabelValues(q.endpoint).Dec()
+		f.send(q, req)
+	}
+}
+
+func (f *forwarder) send(q *forwardingQueue, req forwardingRequest) {
+	ctx, cancel := context.WithTimeout(user.InjectOrgID(f.ctx, req.userID), f.cfg.Timeout)
+	defer cancel()
+
+	_, err := q.api.Write(ctx, q.msgType, req.payload, remote.WithWriteRetryCallback(func(error) {
+		f.retries.WithLabelValues(q.endpoint).Inc()
+	}))
+	if err != nil {
+		level.Warn(f.log).Log("msg", "failed to forward series", "endpoint", q.endpoint, "user", req.userID, "err", err)
+		f.droppedSamples.WithLabelValues(req.userID, q.endpoint, forwardingDropReasonFailed).Add(float64(req.samples))
+		return
+	}
+	f.forwardedSamples.WithLabelValues(req.userID, q.endpoint).Add(float64(req.samples))
+}
+
+func (f *forwarder) cleanupInactiveUser(userID string) {
+	if err := util.DeleteMatchingLabels(f.forwardedSamples, map[string]string{"user": userID}); err != nil {
+		level.Warn(f.log).Log("msg", "failed to remove cortex_distributor_forwarded_samples_total metric for user", "user", userID, "err", err)
+	}
+	if err := util.DeleteMatchingLabels(f.droppedSamples, map[string]string{"user": userID}); err != nil {
+		level.Warn(f.log).Log("msg", "failed to remove cortex_distributor_forwarding_dropped_samples_total metric for user", "user", userID, "err", err)
+	}
+}
+
+// forwardingRequestV2 converts the series to a remote write 2.0 request, attaching the
+// metadata of their metric family.
+func forwardingRequestV2(timeseries []cortexpb.PreallocTimeseries, metadata []*cortexpb.MetricMetadata) *cortexpb.WriteRequestV2 {
+	metadataByFamily := make(map[string]*cortexpb.MetricMetadata, len(metadata))
+	for _, m := range metadata {
+		metadataByFamily[m.MetricFamilyName] = m
+	}
+
+	symbols := writev2.NewSymbolTable()
+	req := &cortexpb.WriteRequestV2{
+		Timeseries: make([]cortexpb.PreallocTimeseriesV2, 0, len(timeseries)),
+	}
+	for _, ts := range timeseries {
+		lbls := cortexpb.FromLabelAdaptersToLabels(ts.Labels)
+		tsV2 := &cortexpb.TimeSeriesV2{
+			LabelsRefs: symbols.SymbolizeLabels(lbls, nil),
+			Samples:    ts.Samples,
+			Histograms: ts.Histograms,
+		}
+		for _, e := range ts.Exemplars {
+			tsV2.Exemplars = append(tsV2.Exemplars, cortexpb.ExemplarV2{
+				LabelsRefs: symbols.SymbolizeLabels(cortexpb.FromLabelAdaptersToLabels(e.Labels), nil),
+				Value:      e.Value,
+				Timestamp:  e.TimestampMs,
+			})
+		}
+		if m, ok := metadataByFamily[lbls.Get(labels.MetricName)]; ok {
+			tsV2.Metadata = cortexpb.MetadataV2{
+				Type:    cortexpb.MetadataV2_MetricType(m.Type),
+				HelpRef: symbols.Symbolize(m.Help),
+				UnitRef: symbols.Symbolize(m.Unit),
+			}
+		}
+		req.Timeseries = append(req.Timeseries, cortexpb.PreallocTimeseriesV2{TimeSeriesV2: tsV2})
+	}
+	req.Symbols = symbols.Symbols()
+	return req
+}
+
+// redactedURL returns the URL with its password redacted, to be safely used in logs and metrics.
+func redactedURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return u.Redacted()
+}
+
+// orgIDRoundTripper sends the tenant ID of the forwarded request, so that the series can
+// be forwarded to another multi-tenant cluster.
+type orgIDRoundTripper struct {
+	next http.RoundTripper
+}
+
+func (rt orgIDRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if err := user.InjectOrgIDIntoHTTPRequest(req.Context(), req); err != nil {
+		return nil, err
+	}
+	return rt.next.RoundTrip(req)
+}
@@ -0,0 +1,371 @@
+package distributor
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+	"go.uber.org/atomic"
+
+	"github.com/cortexproject/cortex/pkg/cortexpb"
+	"github.com/cortexproject/cortex/pkg/util"
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+	"github.com/cortexproject/cortex/pkg/util/services"
+	"github.com/cortexproject/cortex/pkg/util/test"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+type forwardedRequest struct {
+	userID string
+	v1     *cortexpb.WriteRequest
+	v2     *cortexpb.WriteRequestV2
+}
+
+type forwardingServerMock struct {
+	*httptest.Server
+
+	mtx      sync.Mutex
+	requests []forwardedRequest
+}
+
+func newForwardingServerMock(t *testing.T) *forwardingServerMock {
+	s := &forwardingServerMock{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := forwardedRequest{userID: r.Header.Get("X-Scope-OrgID")}
+		if r.Header.Get("Content-Type") == "application/x-protobuf;proto=io.prometheus.write.v2.Request" {
+			req.v2 = &cortexpb.WriteRequestV2{}
+			require.NoError(t, util.ParseProtoReader(r.Context(), r.Body, int(r.ContentLength), 1<<20, req.v2, util.RawSnappy))
+
+			samples := 0
+			for _, ts := range req.v2.Timeseries {
+				samples += len(ts.Samples)
+			}
+			w.Header().Set("X-Prometheus-Remote-Write-Samples-Written", strconv.Itoa(samples))
+		} else {
+			req.v1 = &cortexpb.WriteRequest{}
+			require.NoError(t, util.ParseProtoReader(r.Context(), r.Body, int(r.ContentLength), 1<<20, req.v1, util.RawSnappy))
+		}
+
+		s.mtx.Lock()
+		s.requests = append(s.requests, req)
+		s.mtx.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *forwardingServerMock) received() []forwardedRequest {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return append([]forwardedRequest(nil), s.requests...)
+}
+
+func newTestForwarder(t *testing.T, cfg ForwardingConfig, reg prometheus.Registerer) *forwarder {
+	f := newForwarder(cfg, reg, log.NewNopLogger())
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), f))
+	t.Cleanup(func() {
+		require.NoError(t, services.StopAndAwaitTerminated(context.Background(), f))
+	})
+	return f
+}
+
+func TestForwardingConfig_Validate(t *testing.T) {
+	cfg := ForwardingConfig{}
+	flagext.DefaultValues(&cfg)
+	require.NoError(t, cfg.Validate())
+
+	cfg.QueueCapacity = 0
+	require.Equal(t, errInvalidForwardingQueueCapacity, cfg.Validate())
+
+	flagext.DefaultValues(&cfg)
+	cfg.Workers = 0
+	require.Equal(t, errInvalidForwardingWorkers, cfg.Validate())
+
+	flagext.DefaultValues(&cfg)
+	cfg.MaxRetries = 0
+	require.Equal(t, errInvalidForwardingMaxRetries, cfg.Validate())
+}
+
+func TestForwarder_Forward(t *testing.T) {
+	serverV1 := newForwardingServerMock(t)
+	serverV2 := newForwardingServerMock(t)
+
+	endpoints := validation.ForwardingEndpointsConfig{
+		{URL: serverV1.URL + "/api/v1/push", Matchers: []string{`{__name__="foo"}`}},
+		{URL: serverV2.URL + "/api/v1/push", ProtocolVersion: validation.ForwardingProtocolV2, Matchers: []string{`{__name__=~"foo|bar", job="a"}`}},
+	}
+	require.NoError(t, endpoints.Validate())
+
+	cfg := ForwardingConfig{}
+	flagext.DefaultValues(&cfg)
+	reg := prometheus.NewPedanticRegistry()
+	f := newTestForwarder(t, cfg, reg)
+
+	req := cortexpb.ToWriteRequest([]labels.Labels{
+		labels.FromStrings(labels.MetricName, "foo", "job", "a"),
+		labels.FromStrings(labels.MetricName, "foo", "job", "b"),
+		labels.FromStrings(labels.MetricName, "bar", "job", "a"),
+	}, []cortexpb.Sample{{Value: 1, TimestampMs: 1}, {Value: 2, TimestampMs: 2}, {Value: 3, TimestampMs: 3}}, nil, nil, cortexpb.API)
+	metadata := []*cortexpb.MetricMetadata{
+		{MetricFamilyName: "foo", Type: cortexpb.COUNTER, Help: "foo help"},
+		{MetricFamilyName: "baz", Type: cortexpb.GAUGE, Help: "baz help"},
+	}
+	f.forward("user-1", endpoints, req.Timeseries, metadata)
+
+	test.Poll(t, 5*time.Second, 2, func() any {
+		return len(serverV1.received()) + len(serverV2.received())
+	})
+
+	// The v1 endpoint receives the matching series and metadata as is.
+	receivedV1 := serverV1.received()
+	require.Len(t, receivedV1, 1)
+	assert.Equal(t, "user-1", receivedV1[0].userID)
+	require.Len(t, receivedV1[0].v1.Timeseries, 2)
+	assert.Equal(t, labels.FromStrings(labels.MetricName, "foo", "job", "a"), cortexpb.FromLabelAdaptersToLabels(receivedV1[0].v1.Timeseries[0].Labels))
+	assert.Equal(t, labels.FromStrings(labels.MetricName, "foo", "job", "b"), cortexpb.FromLabelAdaptersToLabels(receivedV1[0].v1.Timeseries[1].Labels))
+	assert.Equal(t, []*cortexpb.MetricMetadata{metadata[0]}, receivedV1[0].v1.Metadata)
+
+	// The v2 endpoint receives the matching series, with the metadata of their metric family.
+	receivedV2 := serverV2.received()
+	require.Len(t, receivedV2, 1)
+	assert.Equal(t, "user-1", receivedV2[0].userID)
+	reqV2 := receivedV2[0].v2
+	require.Len(t, reqV2.Timeseries, 2)
+
+	b := labels.NewScratchBuilder(0)
+	lbls, err := reqV2.Timeseries[0].ToLabels(&b, reqV2.Symbols)
+	require.NoError(t, err)
+	assert.Equal(t, labels.FromStrings(labels.MetricName, "foo", "job", "a"), lbls)
+	assert.Equal(t, []cortexpb.Sample{{Value: 1, TimestampMs: 1}}, reqV2.Timeseries[0].Samples)
+	assert.Equal(t, cortexpb.METRIC_TYPE_COUNTER, reqV2.Timeseries[0].Metadata.Type)
+	assert.Equal(t, "foo help", reqV2.Symbols[reqV2.Timeseries[0].Metadata.HelpRef])
+
+	lbls, err = reqV2.Timeseries[1].ToLabels(&b, reqV2.Symbols)
+	require.NoError(t, err)
+	assert.Equal(t, labels.FromStrings(labels.MetricName, "bar", "job", "a"), lbls)
+	assert.Equal(t, cortexpb.METRIC_TYPE_UNSPECIFIED, reqV2.Timeseries[1].Metadata.Type)
+
+	test.Poll(t, 5*time.Second, nil, func() any {
+		return testutil.GatherAndCompare(reg, bytes.NewBufferString(`
+			# HELP cortex_distributor_forwarded_samples_total The total number of samples successfully forwarded to remote write endpoints.
+			# TYPE cortex_distributor_forwarded_samples_total counter
+			cortex_distributor_forwarded_samples_total{endpoint="`+serverV1.URL+`/api/v1/push",user="user-1"} 2
+			cortex_distributor_forwarded_samples_total{endpoint="`+serverV2.URL+`/api/v1/push",user="user-1"} 2
+		`), "cortex_distributor_forwarded_samples_total")
+	})
+}
+
+func TestForwarder_ShouldDropSamplesWhenQueueIsFullOrEndpointFails(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(server.Close)
+
+	endpoints := validation.ForwardingEndpointsConfig{{URL: server.URL}}
+	require.NoError(t, endpoints.Validate())
+
+	cfg := ForwardingConfig{}
+	flagext.DefaultValues(&cfg)
+	cfg.QueueCapacity = 1
+	cfg.Workers = 1
+	cfg.MaxRetries = 1
+	cfg.MinBackoff = time.Millisecond
+	cfg.MaxBackoff = time.Millisecond
+	reg := prometheus.NewPedanticRegistry()
+	f := newTestForwarder(t, cfg, reg)
+
+	req := cortexpb.ToWriteRequest([]labels.Labels{labels.FromStrings(labels.MetricName, "foo")}, []cortexpb.Sample{{Value: 1, TimestampMs: 1}}, nil, nil, cortexpb.API)
+
+	// The first request is in-flight, the second one is queued and the following ones are dropped.
+	f.forward("user-1", endpoints, req.Timeseries, nil)
+	test.Poll(t, 5*time.Second, 0.0, func() any {
+		return testutil.ToFloat64(f.queueLength.WithLabelValues(server.URL))
+	})
+	f.forward("user-1", endpoints, req.Timeseries, nil)
+	f.forward("user-1", endpoints, req.Timeseries, nil)
+	f.forward("user-1", endpoints, req.Timeseries, nil)
+
+	close(release)
+
+	test.Poll(t, 5*time.Second, nil, func() any {
+		return testutil.GatherAndCompare(reg, bytes.NewBufferString(`
+			# HELP cortex_distributor_forwarding_dropped_samples_total The total number of samples which failed to be forwarded to remote write endpoints.
+			# TYPE cortex_distributor_forwarding_dropped_samples_total counter
+			cortex_distributor_forwarding_dropped_samples_total{endpoint="`+server.URL+`",reason="failed",user="user-1"} 2
+			cortex_distributor_forwarding_dropped_samples_total{endpoint="`+server.URL+`",reason="queue_full",user="user-1"} 2
+			# HELP cortex_distributor_forwarding_retries_total The total number of retried requests to remote write endpoints.
+			# TYPE cortex_distributor_forwarding_retries_total counter
+			cortex_distributor_forwarding_retries_total{endpoint="`+server.URL+`"} 2
+		`), "cortex_distributor_forwarding_dropped_samples_total", "cortex_distributor_forwarding_retries_total")
+	})
+}
+
+func TestForwarder_ShouldDropSamplesWhenQueueCannotBeCreated(t *testing.T) {
+	// The endpoint bypasses the limits validation, so the remote write client fails to parse it.
+	endpoints := validation.ForwardingEndpointsConfig{{URL: "http://invalid host"}}
+
+	cfg := ForwardingConfig{}
+	flagext.DefaultValues(&cfg)
+	reg := prometheus.NewPedanticRegistry()
+	f := newTestForwarder(t, cfg, reg)
+
+	req := cortexpb.ToWriteRequest([]labels.Labels{labels.FromStrings(labels.MetricName, "foo")}, []cortexpb.Sample{{Value: 1, TimestampMs: 1}}, nil, nil, cortexpb.API)
+	require.NotPanics(t, func() {
+		f.forward("user-1", endpoints, req.Timeseries, nil)
+	})
+
+	require.NoError(t, testutil.GatherAndCompare(reg, bytes.NewBufferString(`
+		# HELP cortex_distributor_forwarding_dropped_samples_total The total number of samples which failed to be forwarded to remote write endpoints.
+		# TYPE cortex_distributor_forwarding_dropped_samples_total counter
+		cortex_distributor_forwarding_dropped_samples_total{endpoint="http://invalid host",reason="failed",user="user-1"} 1
+	`), "cortex_distributor_forwarding_dropped_samples_total"))
+
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+	assert.Empty(t, f.queues)
+}
+
+func TestForwarder_ShouldDrainTheQueuesOnShutdown(t *testing.T) {
+	release := make(chan struct{})
+	var received atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		<-release
+		received.Inc()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	endpoints := validation.ForwardingEndpointsConfig{{URL: server.URL}}
+	require.NoError(t, endpoints.Validate())
+
+	cfg := ForwardingConfig{}
+	flagext.DefaultValues(&cfg)
+	cfg.Workers = 1
+	reg := prometheus.NewPedanticRegistry()
+	f := newForwarder(cfg, reg, log.NewNopLogger())
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), f))
+
+	req := cortexpb.ToWriteRequest([]labels.Labels{labels.FromStrings(labels.MetricName, "foo")}, []cortexpb.Sample{{Value: 1, TimestampMs: 1}}, nil, nil, cortexpb.API)
+	for range 3 {
+		f.forward("user-1", endpoints, req.Timeseries, nil)
+	}
+
+	// The requests queued when the forwarder stops are still forwarded.
+	f.StopAsync()
+	close(release)
+	require.NoError(t, f.AwaitTerminated(context.Background()))
+	assert.Equal(t, int32(3), received.Load())
+	assert.Equal(t, 3.0, testutil.ToFloat64(f.forwardedSamples.WithLabelValues("user-1", server.URL)))
+}
+
+func TestForwarder_ShouldDropTheQueuedRequestsOnceTheDrainTimeoutExpires(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+
+	endpoints := validation.ForwardingEndpointsConfig{{URL: server.URL}}
+	require.NoError(t, endpoints.Validate())
+
+	cfg := ForwardingConfig{}
+	flagext.DefaultValues(&cfg)
+	cfg.Workers = 1
+	cfg.DrainTimeout = 100 * time.Millisecond
+	reg := prometheus.NewPedanticRegistry()
+	f := newForwarder(cfg, reg, log.NewNopLogger())
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), f))
+
+	req := cortexpb.ToWriteRequest([]labels.Labels{labels.FromStrings(labels.MetricName, "foo")}, []cortexpb.Sample{{Value: 1, TimestampMs: 1}}, nil, nil, cortexpb.API)
+	for range 3 {
+		f.forward("user-1", endpoints, req.Timeseries, nil)
+	}
+
+	require.NoError(t, services.StopAndAwaitTerminated(context.Background(), f))
+	assert.Equal(t, 3.0, testutil.ToFloat64(f.droppedSamples.WithLabelValues("user-1", server.URL, forwardingDropReasonFailed)))
+}
+
+func TestForwarder_ShouldRemoveIdleQueues(t *testing.T) {
+	server := newForwardingServerMock(t)
+	endpoints := validation.ForwardingEndpointsConfig{{URL: server.URL}}
+	require.NoError(t, endpoints.Validate())
+
+	cfg := ForwardingConfig{}
+	flagext.DefaultValues(&cfg)
+	f := newTestForwarder(t, cfg, prometheus.NewPedanticRegistry())
+
+	req := cortexpb.ToWriteRequest([]labels.Labels{labels.FromStrings(labels.MetricName, "foo")}, []cortexpb.Sample{{Value: 1, TimestampMs: 1}}, nil, nil, cortexpb.API)
+	f.forward("user-1", endpoints, req.Timeseries, nil)
+
+	f.removeIdleQueues(time.Now().Add(-time.Minute))
+	f.mtx.RLock()
+	assert.Len(t, f.queues, 1)
+	f.mtx.RUnlock()
+
+	f.removeIdleQueues(time.Now().Add(time.Minute))
+	f.mtx.RLock()
+	assert.Len(t, f.queues, 0)
+	f.mtx.RUnlock()
+
+	// The queue is created again when series are forwarded to the endpoint.
+	f.forward("user-1", endpoints, req.Timeseries, nil)
+	test.Poll(t, 5*time.Second, 2, func() any {
+		return len(server.received())
+	})
+}
+
+func TestDistributor_Push_ShouldNotFailWhenForwardingEndpointIsDown(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.ForwardingEndpoints = validation.ForwardingEndpointsConfig{{URL: server.URL}}
+	require.NoError(t, limits.ForwardingEndpoints.Validate())
+
+	ds, ingesters, _, _ := prepare(t, prepConfig{
+		numIngesters:     3,
+		happyIngesters:   3,
+		numDistributors:  1,
+		shardByAllLabels: true,
+		limits:           limits,
+	})
+
+	ctx := user.InjectOrgID(context.Background(), "user")
+	_, err := ds[0].Push(ctx, makeWriteRequest(0, 5, 0, 0))
+	require.NoError(t, err)
+
+	test.Poll(t, 5*time.Second, 5, func() any {
+		series := map[uint32]struct{}{}
+		for _, ing := range ingesters {
+			for key := range ing.series() {
+				series[key] = struct{}{}
+			}
+		}
+		return len(series)
+	})
+}
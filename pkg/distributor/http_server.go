@@ -1,8 +1,15 @@
 package distributor
 
 import (
+	"fmt"
 	"net/http"
+	"strings"
 
+	"github.com/gorilla/mux"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/ingester"
+	"github.com/cortexproject/cortex/pkg/ring"
 	"github.com/cortexproject/cortex/pkg/util"
 )
 
@@ -16,3 +23,91 @@ func (d *Distributor) UserStatsHandler(w http.ResponseWriter, r *http.Request) {
 
 	util.WriteJSONResponse(w, stats)
 }
+
+// TopSeriesHandler shows the top metric names and label pairs of the tenant in the URL path,
+// aggregated across the ingesters.
+func (d *Distributor) TopSeriesHandler(w http.ResponseWriter, r *http.Request) {
+	limit, err := ingester.ParseTopSeriesLimit(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := user.InjectOrgID(r.Context(), mux.Vars(r)["id"])
+	topSeries, err := d.TopSeries(ctx, limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	util.WriteJSONResponse(w, topSeries)
+}
+
+// DiscardedSeriesHandler shows the latest discarded series sampled by the distributor for the tenant in the URL path.
+func (d *Distributor) DiscardedSeriesHandler(w http.ResponseWriter, r *http.Request) {
+	util.WriteJSONResponse(w, d.discardedSeriesSampler.Samples(mux.Vars(r)["id"]))
+}
+
+// FailureSimulationHandler reports, for each tenant, whether writes keep quorum and whether queries
+// return complete results if the instances and zones of the ingesters ring given with the "instance"
+// and "zone" parameters failed. The tenants can be given with the "tenant" parameter, otherwise the
+// tenants which recently pushed to this distributor are reported. Each parameter can be repeated,
+// or given a comma-separated list of values.
+func (d *Distributor) FailureSimulationHandler(w http.ResponseWriter, r *http.Request) {
+	ingestersRing, ok := d.ingestersRing.(*ring.Ring)
+	if !ok {
+		http.Error(w, "failure simulation is not supported by the ingesters ring in use", http.StatusNotImplemented)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sim := ring.FailureSimulation{
+		FailedInstances: formValues(r, "instance"),
+		FailedZones:     formValues(r, "zone"),
+		Tenants:         map[string]int{},
+		WriteOp:         ring.WriteNoExtend,
+	}
+	if len(sim.FailedInstances) == 0 && len(sim.FailedZones) == 0 {
+		http.Error(w, "at least one instance or zone is required", http.StatusBadRequest)
+		return
+	}
+	if d.cfg.ExtendWrites {
+		sim.WriteOp = ring.Write
+	}
+
+	tenants := formValues(r, "tenant")
+	if len(tenants) == 0 {
+		tenants = d.activeUsers.ActiveUsers()
+	}
+	for _, tenant := range tenants {
+		shardSize := 0
+		if d.cfg.ShardingStrategy == util.ShardingStrategyShuffle {
+			shardSize = d.limits.IngestionTenantShardSize(tenant)
+		}
+		sim.Tenants[tenant] = shardSize
+	}
+
+	report, err := ingestersRing.SimulateFailure(sim)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to simulate the failure: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	util.WriteJSONResponse(w, report)
+}
+
+func formValues(r *http.Request, key string) []string {
+	var values []string
+	for _, v := range r.Form[key] {
+		for value := range strings.SplitSeq(v, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
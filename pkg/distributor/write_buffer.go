@@ -0,0 +1,734 @@
+package distributor
+
+import (
+	"context"
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/cortexpb"
+	"github.com/cortexproject/cortex/pkg/util"
+	"github.com/cortexproject/cortex/pkg/util/services"
+)
+
+const (
+	// Size after which a new segment file of a tenant's write buffer is created. Segments
+	// are deleted once all their requests have been replayed or dropped.
+	writeBufferSegmentSize = 8 << 20
+
+	// Each buffered request is prefixed by its length, the CRC32 of the rest of the entry,
+	// and the time it's been buffered at.
+	writeBufferEntryHeaderSize = 16
+
+	// Maximum size of the buffered requests merged into a single replayed write request.
+	// A batch always contains at least one request.
+	writeBufferReplayBatchSize = 1 << 20
+
+	writeBufferDropReasonFull      = "buffer_full"
+	writeBufferDropReasonTooOld    = "too_old"
+	writeBufferDropReasonRejected  = "rejected"
+	writeBufferDropReasonCorrupted = "corrupted"
+	writeBufferDropReasonFailed    = "failed"
+)
+
+var (
+	errInvalidWriteBufferMaxAge         = errors.New("invalid write buffer max age, it must be greater than 0")
+	errInvalidWriteBufferReplayInterval = errors.New("invalid write buffer replay interval, it must be greater than 0")
+	errWriteBufferFull                  = errors.New("write buffer full")
+
+	writeBufferCastagnoli = crc32.MakeTable(crc32.Castagnoli)
+)
+
+// WriteBufferConfig configures the disk-backed buffer of the write requests which can't
+// be pushed to the ingesters.
+type WriteBufferConfig struct {
+	Dir            string        `yaml:"dir"`
+	MaxAge         time.Duration `yaml:"max_age"`
+	ReplayInterval time.Duration `yaml:"replay_interval"`
+}
+
+// RegisterFlags adds the flags required to config this to the given FlagSet
+func (cfg *WriteBufferConfig) RegisterFlags(f *flag.FlagSet) {
+	f.StringVar(&cfg.Dir, "distributor.write-buffer.dir", "", "EXPERIMENTAL: Directory where the distributor buffers the write requests which fail because less than a quorum of ingesters is reachable, for the tenants with a -distributor.write-buffer-max-bytes limit. The buffered requests are replayed in order once the ingesters are reachable again. If empty, the write buffer is disabled.")
+	f.DurationVar(&cfg.MaxAge, "distributor.write-buffer.max-age", 5*time.Minute, "EXPERIMENTAL: Maximum time a write request is kept in the write buffer. Older requests are dropped instead of being replayed.")
+	f.DurationVar(&cfg.ReplayInterval, "distributor.write-buffer.replay-interval", 5*time.Second, "EXPERIMENTAL: Interval at which the distributor tries to replay the buffered write requests.")
+}
+
+// Validate config and returns error on failure
+func (cfg *WriteBufferConfig) Validate() error {
+	if cfg.Dir == "" {
+		return nil
+	}
+	if cfg.MaxAge <= 0 {
+		return errInvalidWriteBufferMaxAge
+	}
+	if cfg.ReplayInterval <= 0 {
+		return errInvalidWriteBufferReplayInterval
+	}
+	return nil
+}
+
+// isBufferableError returns whether a write request failed with the given error should be
+// buffered: client errors returned by the ingesters would fail again when replayed.
+func isBufferableError(err error) bool {
+	return getErrorStatus(err) == "5xx"
+}
+
+type writeBufferSegment struct {
+	id   int
+	size int64
+}
+
+// tenantWriteBuffer is the write buffer of a tenant: an append-only sequence of segment
+// files, read in order from the start of the first segment.
+type tenantWriteBuffer struct {
+	mtx      sync.Mutex
+	dir      string
+	segments []writeBufferSegment
+	head     *os.File
+	size     int64
+
+	// Requests are synced to disk by group commit: appended counts the requests written to the
+	// segments and synced the ones synced to disk, and syncMtx serializes the syncs, each of which
+	// covers all the requests written before it starts.
+	syncMtx  sync.Mutex
+	appended uint64
+	synced   uint64
+
+	// Position of the next request to replay in the first segment.
+	reader    *os.File
+	readerID  int
+	readerOff int64
+}
+
+func (t *tenantWriteBuffer) empty() bool {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	return len(t.segments) == 0
+}
+
+// append buffers the request and returns once it's synced to disk.
+func (t *tenantWriteBuffer) append(payload []byte, now time.Time, maxBytes int64, segmentSize int64) error {
+	t.mtx.Lock()
+	seq, err := t.appendLocked(payload, now, maxBytes, segmentSize)
+	t.mtx.Unlock()
+	if err != nil {
+		return err
+	}
+	return t.sync(seq)
+}
+
+// appendIfPending buffers the request, marshaled by the given function, only if the buffer has
+// requests not replayed yet. The check is done under the same lock used by the replay to remove
+// the replayed requests, so that a request is never buffered after the replay switched the tenant
+// back to direct pushes.
+func (t *tenantWriteBuffer) appendIfPending(marshal func() ([]byte, error), now time.Time, maxBytes int64, segmentSize int64) (bool, error) {
+	t.mtx.Lock()
+	if len(t.segments) == 0 {
+		t.mtx.Unlock()
+		return false, nil
+	}
+
+	payload, err := marshal()
+	var seq uint64
+	if err == nil {
+		seq, err = t.appendLocked(payload, now, maxBytes, segmentSize)
+	}
+	t.mtx.Unlock()
+	if err != nil {
+		return true, err
+	}
+	return true, t.sync(seq)
+}
+
+// appendLocked writes the request to the head segment, without syncing it, and returns its
+// sequence number to sync it with.
+func (t *tenantWriteBuffer) appendLocked(payload []byte, now time.Time, maxBytes int64, segmentSize int64) (uint64, error) {
+	entrySize := int64(writeBufferEntryHeaderSize + len(payload))
+	if t.size+entrySize > maxBytes {
+		return 0, errWriteBufferFull
+	}
+
+	if t.head == nil || t.segments[len(t.segments)-1].size >= segmentSize {
+		if err := t.cutSegment(); err != nil {
+			return 0, err
+		}
+	}
+
+	entry := make([]byte, entrySize)
+	binary.BigEndian.PutUint32(entry[0:], uint32(len(payload)))
+	binary.BigEndian.PutUint64(entry[8:], uint64(now.UnixMilli()))
+	copy(entry[writeBufferEntryHeaderSize:], payload)
+	binary.BigEndian.PutUint32(entry[4:], crc32.Checksum(entry[8:], writeBufferCastagnoli))
+
+	if _, err := t.head.Write(entry); err != nil {
+		// The segment may end with a partially written request, which is never read because
+		// it's beyond the size of the segment: new requests are written to a new segment.
+		t.closeHead()
+		return 0, err
+	}
+
+	t.segments[len(t.segments)-1].size += entrySize
+	t.size += entrySize
+	t.appended++
+	return t.appended, nil
+}
+
+// sync returns once the request with the given sequence number is synced to disk. A single
+// sync of the head segment covers all the requests written concurrently. If the sync fails,
+// the request is still in the buffer and may be replayed.
+func (t *tenantWriteBuffer) sync(seq uint64) error {
+	t.syncMtx.Lock()
+	defer t.syncMtx.Unlock()
+
+	t.mtx.Lock()
+	if t.synced >= seq {
+		t.mtx.Unlock()
+		return nil
+	}
+	head, target := t.head, t.appended
+	t.mtx.Unlock()
+
+	if head == nil {
+		// The segment has been closed, and failed to be synced.
+		return errors.New("write buffer segment closed before being synced")
+	}
+	err := head.Sync()
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	// The segment may have been synced when it's been closed in the meantime.
+	if t.synced >= seq {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	t.synced = target
+	return nil
+}
+
+func (t *tenantWriteBuffer) cutSegment() error {
+	t.closeHead()
+
+	if err := os.MkdirAll(t.dir, 0o750); err != nil {
+		return err
+	}
+
+	id := 0
+	if len(t.segments) > 0 {
+		id = t.segments[len(t.segments)-1].id + 1
+	}
+	f, err := os.OpenFile(filepath.Join(t.dir, segmentFileName(id)), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o640)
+	if err != nil {
+		return err
+	}
+
+	t.head = f
+	t.segments = append(t.segments, writeBufferSegment{id: id})
+	return nil
+}
+
+// closeHead syncs and closes the head segment. The requests written to it are synced once
+// it's closed.
+func (t *tenantWriteBuffer) closeHead() {
+	if t.head != nil {
+		if err := t.head.Sync(); err == nil {
+			t.synced = t.appended
+		}
+		_ = t.head.Close()
+		t.head = nil
+	}
+}
+
+// next returns the oldest buffered request, along with the time it's been buffered at and
+// its size, or a nil request if the buffer is empty. Corrupted requests are skipped and
+// returned as the number of dropped requests.
+func (t *tenantWriteBuffer) next() (payload []byte, bufferedAt time.Time, size int64, corrupted int, err error) {
+	return t.nextAt(0)
+}
+
+// nextAt returns the request buffered the given number of bytes after the oldest one, in the
+// same segment. A nil request is returned once the end of the segment, or a corrupted request,
+// is reached: the replay moves past them when the following requests are read with offset 0.
+func (t *tenantWriteBuffer) nextAt(offset int64) (payload []byte, bufferedAt time.Time, size int64, corrupted int, err error) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	for len(t.segments) > 0 {
+		seg := t.segments[0]
+		if offset > 0 && t.readerOff+offset >= seg.size {
+			return nil, time.Time{}, 0, 0, nil
+		}
+		if t.readerOff >= seg.size {
+			if len(t.segments) == 1 {
+				// All the requests have been replayed: the head segment is removed too,
+				// and the next buffered request creates a new one.
+				t.closeHead()
+			}
+			if err := t.removeFirstSegment(); err != nil {
+				return nil, time.Time{}, 0, corrupted, err
+			}
+			continue
+		}
+
+		if t.reader == nil || t.readerID != seg.id {
+			t.closeReader()
+			f, err := os.Open(filepath.Join(t.dir, segmentFileName(seg.id)))
+			if err != nil {
+				return nil, time.Time{}, 0, corrupted, err
+			}
+			t.reader, t.readerID = f, seg.id
+		}
+
+		// The rest of a segment with a corrupted request is skipped.
+		off := t.readerOff + offset
+		skipCorrupted := func() bool {
+			if offset > 0 {
+				return false
+			}
+			corrupted++
+			t.readerOff = seg.size
+			return true
+		}
+
+		header := make([]byte, writeBufferEntryHeaderSize)
+		if _, err := t.reader.ReadAt(header, off); err != nil {
+			if skipCorrupted() {
+				continue
+			}
+			return nil, time.Time{}, 0, 0, nil
+		}
+		length := int64(binary.BigEndian.Uint32(header[0:]))
+		if off+writeBufferEntryHeaderSize+length > seg.size {
+			if skipCorrupted() {
+				continue
+			}
+			return nil, time.Time{}, 0, 0, nil
+		}
+
+		entry := make([]byte, 8+length)
+		copy(entry, header[8:])
+		_, err := t.reader.ReadAt(entry[8:], off+writeBufferEntryHeaderSize)
+		if (err != nil && !errors.Is(err, io.EOF)) || crc32.Checksum(entry, writeBufferCastagnoli) != binary.BigEndian.Uint32(header[4:]) {
+			if skipCorrupted() {
+				continue
+			}
+			return nil, time.Time{}, 0, 0, nil
+		}
+
+		bufferedAt := time.UnixMilli(int64(binary.BigEndian.Uint64(header[8:])))
+		return entry[8:], bufferedAt, writeBufferEntryHeaderSize + length, corrupted, nil
+	}
+
+	return nil, time.Time{}, 0, corrupted, nil
+}
+
+// advance moves past the request returned by next().
+func (t *tenantWriteBuffer) advance(size int64) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.readerOff += size
+}
+
+func (t *tenantWriteBuffer) removeFirstSegment() error {
+	seg := t.segments[0]
+	if t.readerID == seg.id {
+		t.closeReader()
+	}
+	if err := os.Remove(filepath.Join(t.dir, segmentFileName(seg.id))); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	t.segments = t.segments[1:]
+	t.size -= seg.size
+	t.readerOff = 0
+	return nil
+}
+
+func (t *tenantWriteBuffer) closeReader() {
+	if t.reader != nil {
+		_ = t.reader.Close()
+		t.reader = nil
+	}
+}
+
+func (t *tenantWriteBuffer) close() {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.closeHead()
+	t.closeReader()
+}
+
+func (t *tenantWriteBuffer) bytes() int64 {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	return t.size
+}
+
+// pendingBytes returns the size of the requests not replayed yet.
+func (t *tenantWriteBuffer) pendingBytes() int64 {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	return t.size - t.readerOff
+}
+
+func segmentFileName(id int) string {
+	return fmt.Sprintf("%08d", id)
+}
+
+// writeBuffer buffers on disk the write requests which can't be pushed to the ingesters
+// because less than a quorum of them is reachable, and replays them in order once the
+// ingesters are reachable again. While a tenant has buffered requests, its new write
+// requests are buffered too, so that the samples are pushed in order.
+type writeBuffer struct {
+	services.Service
+
+	cfg         WriteBufferConfig
+	push        func(ctx context.Context, userID string, req *cortexpb.WriteRequest) error
+	maxBytes    func(userID string) int
+	log         log.Logger
+	segmentSize int64
+	batchSize   int64
+
+	mtx     sync.RWMutex
+	tenants map[string]*tenantWriteBuffer
+
+	bufferedRequests *prometheus.CounterVec
+	replayedRequests *prometheus.CounterVec
+	droppedRequests  *prometheus.CounterVec
+	bufferSize       *prometheus.GaugeVec
+}
+
+func newWriteBuffer(cfg WriteBufferConfig, push func(ctx context.Context, userID string, req *cortexpb.WriteRequest) error, maxBytes func(userID string) int, reg prometheus.Registerer, logger log.Logger) *writeBuffer {
+	b := &writeBuffer{
+		cfg:         cfg,
+		push:        push,
+		maxBytes:    maxBytes,
+		log:         logger,
+		segmentSize: writeBufferSegmentSize,
+		batchSize:   writeBufferReplayBatchSize,
+		tenants:     map[string]*tenantWriteBuffer{},
+
+		bufferedRequests: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_distributor_write_buffer_buffered_requests_total",
+			Help: "The total number of write requests buffered on disk while the ingesters are unavailable.",
+		}, []string{"user"}),
+		replayedRequests: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_distributor_write_buffer_replayed_requests_total",
+			Help: "The total number of buffered write requests successfully replayed to the ingesters.",
+		}, []string{"user"}),
+		droppedRequests: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_distributor_write_buffer_dropped_requests_total",
+			Help: "The total number of write requests which couldn't be buffered or replayed.",
+		}, []string{"user", "reason"}),
+		bufferSize: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cortex_distributor_write_buffer_size_bytes",
+			Help: "The size on disk of the buffered write requests.",
+		}, []string{"user"}),
+	}
+
+	b.Service = services.NewTimerService(cfg.ReplayInterval, b.starting, b.iteration, b.stopping)
+	return b
+}
+
+// enabled returns whether the write requests of the tenant are buffered.
+func (b *writeBuffer) enabled(userID string) bool {
+	return b != nil && b.maxBytes(userID) > 0
+}
+
+// starting loads the requests buffered before a restart. Requests of a segment which was
+// being replayed are replayed again, since the position in a segment isn't persisted.
+func (b *writeBuffer) starting(_ context.Context) error {
+	if err := os.MkdirAll(b.cfg.Dir, 0o750); err != nil {
+		return errors.Wrap(err, "failed to create write buffer directory")
+	}
+
+	userDirs, err := os.ReadDir(b.cfg.Dir)
+	if err != nil {
+		return errors.Wrap(err, "failed to read write buffer directory")
+	}
+
+	for _, userDir := range userDirs {
+		if !userDir.IsDir() {
+			continue
+		}
+		userID := userDir.Name()
+		t := &tenantWriteBuffer{dir: filepath.Join(b.cfg.Dir, userID)}
+
+		files, err := os.ReadDir(t.dir)
+		if err != nil {
+			return errors.Wrapf(err, "failed to read write buffer directory of user %s", userID)
+		}
+		for _, file := range files {
+			id, err := strconv.Atoi(file.Name())
+			if err != nil || file.IsDir() {
+				continue
+			}
+			info, err := file.Info()
+			if err != nil {
+				return errors.Wrapf(err, "failed to read write buffer segment of user %s", userID)
+			}
+			t.segments = append(t.segments, writeBufferSegment{id: id, size: info.Size()})
+			t.size += info.Size()
+		}
+		slices.SortFunc(t.segments, func(a, b writeBufferSegment) int { return a.id - b.id })
+
+		if len(t.segments) == 0 {
+			_ = os.Remove(t.dir)
+			continue
+		}
+		b.tenants[userID] = t
+		b.bufferSize.WithLabelValues(userID).Set(float64(t.size))
+		level.Info(b.log).Log("msg", "loaded buffered write requests", "user", userID, "segments", len(t.segments), "bytes", t.size)
+	}
+	return nil
+}
+
+func (b *writeBuffer) iteration(ctx context.Context) error {
+	b.replay(ctx, time.Now())
+	return nil
+}
+
+func (b *writeBuffer) stopping(_ error) error {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	for _, t := range b.tenants {
+		t.close()
+	}
+	return nil
+}
+
+func (b *writeBuffer) tenant(userID string) *tenantWriteBuffer {
+	b.mtx.RLock()
+	t := b.tenants[userID]
+	b.mtx.RUnlock()
+	return t
+}
+
+// pending returns whether the tenant has buffered requests not replayed yet.
+func (b *writeBuffer) pending(userID string) bool {
+	t := b.tenant(userID)
+	return t != nil && !t.empty()
+}
+
+// add buffers a marshaled write request of the tenant.
+func (b *writeBuffer) add(userID string, payload []byte, now time.Time) error {
+	t := b.tenant(userID)
+	if t == nil {
+		b.mtx.Lock()
+		if t = b.tenants[userID]; t == nil {
+			t = &tenantWriteBuffer{dir: filepath.Join(b.cfg.Dir, userID)}
+			b.tenants[userID] = t
+		}
+		b.mtx.Unlock()
+	}
+
+	return b.handleAppend(userID, t, t.append(payload, now, int64(b.maxBytes(userID)), b.segmentSize))
+}
+
+// addIfPending buffers a write request of the tenant, marshaled by the given function, only if
+// the tenant has buffered requests not replayed yet, so that they're replayed in order. Otherwise
+// the request isn't marshaled and must be pushed directly.
+func (b *writeBuffer) addIfPending(userID string, marshal func() ([]byte, error), now time.Time) (bool, error) {
+	t := b.tenant(userID)
+	if t == nil {
+		return false, nil
+	}
+
+	buffered, err := t.appendIfPending(marshal, now, int64(b.maxBytes(userID)), b.segmentSize)
+	if !buffered {
+		return false, nil
+	}
+	return true, b.handleAppend(userID, t, err)
+}
+
+func (b *writeBuffer) handleAppend(userID string, t *tenantWriteBuffer, err error) error {
+	if err != nil {
+		reason := writeBufferDropReasonFailed
+		if errors.Is(err, errWriteBufferFull) {
+			reason = writeBufferDropReasonFull
+		}
+		level.Warn(b.log).Log("msg", "failed to buffer write request", "user", userID, "err", err)
+		b.droppedRequests.WithLabelValues(userID, reason).Inc()
+		return err
+	}
+
+	b.bufferedRequests.WithLabelValues(userID).Inc()
+	b.bufferSize.WithLabelValues(userID).Set(float64(t.bytes()))
+	return nil
+}
+
+// replay pushes the buffered requests of each tenant in order, one batch per tenant at a time,
+// until every tenant is caught up or a batch fails with an error which would not occur once the
+// ingesters are reachable again, in which case the replay of the tenant is retried at the next
+// iteration.
+func (b *writeBuffer) replay(ctx context.Context, now time.Time) {
+	b.mtx.RLock()
+	userIDs := make([]string, 0, len(b.tenants))
+	for userID := range b.tenants {
+		userIDs = append(userIDs, userID)
+	}
+	b.mtx.RUnlock()
+
+	for len(userIDs) > 0 && ctx.Err() == nil {
+		remaining := userIDs[:0]
+		for _, userID := range userIDs {
+			if ctx.Err() != nil {
+				return
+			}
+			if b.replayTenantBatch(ctx, userID, b.tenant(userID), now) {
+				remaining = append(remaining, userID)
+			}
+		}
+		userIDs = remaining
+	}
+}
+
+// replayTenantBatch merges the oldest buffered requests of the tenant, up to the replay batch
+// size, into a single write request and pushes it. It returns whether the tenant has more
+// requests to replay in this iteration.
+func (b *writeBuffer) replayTenantBatch(ctx context.Context, userID string, t *tenantWriteBuffer, now time.Time) bool {
+	defer func() {
+		b.bufferSize.WithLabelValues(userID).Set(float64(t.bytes()))
+	}()
+
+	b.enforceMaxBytes(userID, t)
+
+	var (
+		batch     *cortexpb.PreallocWriteRequest
+		requests  int
+		batchSize int64
+	)
+	for batchSize < b.batchSize {
+		payload, bufferedAt, size, corrupted, err := t.nextAt(batchSize)
+		if corrupted > 0 {
+			level.Warn(b.log).Log("msg", "dropped corrupted buffered write requests", "user", userID, "count", corrupted)
+			b.droppedRequests.WithLabelValues(userID, writeBufferDropReasonCorrupted).Add(float64(corrupted))
+		}
+		if err != nil {
+			level.Warn(b.log).Log("msg", "failed to read buffered write request", "user", userID, "err", err)
+			return false
+		}
+		if payload == nil {
+			break
+		}
+
+		// Requests which can't be replayed are only dropped when they're the first of the batch,
+		// so that the batch always starts at the oldest request.
+		if now.Sub(bufferedAt) > b.cfg.MaxAge {
+			if batch != nil {
+				break
+			}
+			b.droppedRequests.WithLabelValues(userID, writeBufferDropReasonTooOld).Inc()
+			t.advance(size)
+			continue
+		}
+
+		// The series are released by the push.
+		req := &cortexpb.PreallocWriteRequest{}
+		if err := req.Unmarshal(payload); err != nil {
+			if batch != nil {
+				break
+			}
+			b.droppedRequests.WithLabelValues(userID, writeBufferDropReasonCorrupted).Inc()
+			t.advance(size)
+			continue
+		}
+
+		if batch == nil {
+			batch = req
+		} else if req.Source != batch.Source || req.DiscardOutOfOrder != batch.DiscardOutOfOrder {
+			cortexpb.ReuseSlice(req.Timeseries)
+			break
+		} else {
+			batch.Timeseries = append(batch.Timeseries, req.Timeseries...)
+			batch.Metadata = append(batch.Metadata, req.Metadata...)
+		}
+		requests++
+		batchSize += size
+	}
+
+	if batch == nil {
+		// The replayed requests have been removed: new requests are pushed directly again.
+		return !t.empty()
+	}
+
+	err := b.push(user.InjectOrgID(ctx, userID), userID, &batch.WriteRequest)
+	if err != nil && isBufferableError(err) {
+		level.Debug(b.log).Log("msg", "failed to replay buffered write requests, will retry", "user", userID, "err", err)
+		return false
+	}
+	if err != nil {
+		level.Warn(b.log).Log("msg", "buffered write requests rejected by the ingesters", "user", userID, "requests", requests, "err", err)
+		b.droppedRequests.WithLabelValues(userID, writeBufferDropReasonRejected).Add(float64(requests))
+	} else {
+		b.replayedRequests.WithLabelValues(userID).Add(float64(requests))
+	}
+	t.advance(batchSize)
+	return true
+}
+
+// enforceMaxBytes drops the oldest buffered requests of the tenant exceeding its current write
+// buffer size limit, in case the limit has been lowered since they've been buffered.
+func (b *writeBuffer) enforceMaxBytes(userID string, t *tenantWriteBuffer) {
+	maxBytes := int64(b.maxBytes(userID))
+	if maxBytes <= 0 {
+		// The buffered requests are still replayed after the buffer is disabled for the tenant.
+		return
+	}
+
+	for t.pendingBytes() > maxBytes {
+		payload, _, size, corrupted, err := t.next()
+		if corrupted > 0 {
+			b.droppedRequests.WithLabelValues(userID, writeBufferDropReasonCorrupted).Add(float64(corrupted))
+		}
+		if err != nil || payload == nil {
+			return
+		}
+		b.droppedRequests.WithLabelValues(userID, writeBufferDropReasonFull).Inc()
+		t.advance(size)
+	}
+}
+
+func (b *writeBuffer) cleanupInactiveUser(userID string) {
+	if b == nil {
+		return
+	}
+
+	// The metrics of tenants with buffered requests are kept, since they're still replayed.
+	b.mtx.Lock()
+	if t, ok := b.tenants[userID]; ok {
+		if !t.empty() {
+			b.mtx.Unlock()
+			return
+		}
+		t.close()
+		_ = os.Remove(t.dir)
+		delete(b.tenants, userID)
+	}
+	b.mtx.Unlock()
+
+	b.bufferedRequests.DeleteLabelValues(userID)
+	b.replayedRequests.DeleteLabelValues(userID)
+	b.bufferSize.DeleteLabelValues(userID)
+	if err := util.DeleteMatchingLabels(b.droppedRequests, map[string]string{"user": userID}); err != nil {
+		level.Warn(b.log).Log("msg", "failed to remove cortex_distributor_write_buffer_dropped_requests_total metric for user", "user", userID, "err", err)
+	}
+}
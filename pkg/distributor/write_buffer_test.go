@@ -0,0 +1,454 @@
+package distributor
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/httpgrpc"
+	"github.com/weaveworks/common/user"
+	"go.uber.org/atomic"
+
+	"github.com/cortexproject/cortex/pkg/cortexpb"
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+	"github.com/cortexproject/cortex/pkg/util/services"
+	"github.com/cortexproject/cortex/pkg/util/test"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+func TestWriteBufferConfig_Validate(t *testing.T) {
+	cfg := WriteBufferConfig{}
+	flagext.DefaultValues(&cfg)
+	require.NoError(t, cfg.Validate())
+
+	cfg.Dir = t.TempDir()
+	require.NoError(t, cfg.Validate())
+
+	cfg.MaxAge = 0
+	require.Equal(t, errInvalidWriteBufferMaxAge, cfg.Validate())
+
+	flagext.DefaultValues(&cfg)
+	cfg.Dir = t.TempDir()
+	cfg.ReplayInterval = 0
+	require.Equal(t, errInvalidWriteBufferReplayInterval, cfg.Validate())
+}
+
+func TestTenantWriteBuffer(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	buf := &tenantWriteBuffer{dir: filepath.Join(t.TempDir(), "user")}
+	defer buf.close()
+
+	payload, _, _, _, err := buf.next()
+	require.NoError(t, err)
+	require.Nil(t, payload)
+
+	// Each segment holds 2 requests.
+	for i := range 5 {
+		require.NoError(t, buf.append([]byte("request-"+strconv.Itoa(i)), now.Add(time.Duration(i)*time.Second), 1000, 40))
+	}
+	files, err := os.ReadDir(buf.dir)
+	require.NoError(t, err)
+	require.Len(t, files, 3)
+
+	// The buffer is full.
+	require.ErrorIs(t, buf.append(bytes.Repeat([]byte("a"), 1000), now, 1000, 40), errWriteBufferFull)
+
+	for i := range 5 {
+		payload, bufferedAt, size, corrupted, err := buf.next()
+		require.NoError(t, err)
+		require.Zero(t, corrupted)
+		require.Equal(t, "request-"+strconv.Itoa(i), string(payload))
+		require.Equal(t, now.Add(time.Duration(i)*time.Second).UnixMilli(), bufferedAt.UnixMilli())
+
+		// The request is returned until the replay moves past it.
+		again, _, _, _, err := buf.next()
+		require.NoError(t, err)
+		require.Equal(t, payload, again)
+
+		buf.advance(size)
+	}
+
+	payload, _, _, _, err = buf.next()
+	require.NoError(t, err)
+	require.Nil(t, payload)
+	require.True(t, buf.empty())
+	require.Zero(t, buf.bytes())
+
+	// The replayed segments are removed.
+	files, err = os.ReadDir(buf.dir)
+	require.NoError(t, err)
+	require.Empty(t, files)
+
+	// The buffer can be used again once empty.
+	require.NoError(t, buf.append([]byte("request-5"), now, 1000, 40))
+	payload, _, _, _, err = buf.next()
+	require.NoError(t, err)
+	require.Equal(t, "request-5", string(payload))
+}
+
+func TestTenantWriteBuffer_ShouldSyncConcurrentlyAppendedRequests(t *testing.T) {
+	t.Parallel()
+
+	buf := &tenantWriteBuffer{dir: filepath.Join(t.TempDir(), "user")}
+	defer buf.close()
+
+	// Requests are appended concurrently across segments, and each one is synced once append
+	// returns.
+	wg := sync.WaitGroup{}
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, buf.append([]byte("request-"+strconv.Itoa(i)), time.Now(), 1<<20, 100))
+		}()
+	}
+	wg.Wait()
+
+	buf.mtx.Lock()
+	assert.Equal(t, uint64(50), buf.appended)
+	assert.Equal(t, uint64(50), buf.synced)
+	buf.mtx.Unlock()
+
+	received := map[string]bool{}
+	for {
+		payload, _, size, corrupted, err := buf.next()
+		require.NoError(t, err)
+		require.Zero(t, corrupted)
+		if payload == nil {
+			break
+		}
+		received[string(payload)] = true
+		buf.advance(size)
+	}
+	assert.Len(t, received, 50)
+}
+
+func TestTenantWriteBuffer_ShouldSkipCorruptedRequests(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	buf := &tenantWriteBuffer{dir: filepath.Join(t.TempDir(), "user")}
+	defer buf.close()
+
+	require.NoError(t, buf.append([]byte("request-0"), now, 1000, 40))
+	require.NoError(t, buf.append([]byte("request-1"), now, 1000, 40))
+	require.NoError(t, buf.append([]byte("request-2"), now, 1000, 40))
+
+	// Corrupt the first request of the first segment.
+	f, err := os.OpenFile(filepath.Join(buf.dir, segmentFileName(0)), os.O_WRONLY, 0)
+	require.NoError(t, err)
+	_, err = f.WriteAt([]byte("x"), writeBufferEntryHeaderSize)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	// The rest of the corrupted segment is skipped.
+	payload, _, _, corrupted, err := buf.next()
+	require.NoError(t, err)
+	require.Equal(t, 1, corrupted)
+	require.Equal(t, "request-2", string(payload))
+}
+
+type writeBufferPushMock struct {
+	mtx      sync.Mutex
+	err      error
+	pushes   int
+	requests []string
+
+	// Called after each push, without holding the lock.
+	onPush func()
+}
+
+func (m *writeBufferPushMock) push(_ context.Context, _ string, req *cortexpb.WriteRequest) error {
+	defer func() {
+		if m.onPush != nil {
+			m.onPush()
+		}
+	}()
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if m.err != nil {
+		return m.err
+	}
+	m.pushes++
+	for _, md := range req.Metadata {
+		if md.MetricFamilyName == "rejected" {
+			return httpgrpc.Errorf(http.StatusBadRequest, "rejected")
+		}
+	}
+	for _, md := range req.Metadata {
+		m.requests = append(m.requests, md.MetricFamilyName)
+	}
+	return nil
+}
+
+func (m *writeBufferPushMock) setError(err error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.err = err
+}
+
+func writeBufferTestPayload(t *testing.T, metricName string) []byte {
+	req := cortexpb.WriteRequest{Metadata: []*cortexpb.MetricMetadata{{MetricFamilyName: metricName}}}
+	payload, err := req.Marshal()
+	require.NoError(t, err)
+	return payload
+}
+
+func writeBufferTestMaxBytes(maxBytes int) func(string) int {
+	return func(string) int { return maxBytes }
+}
+
+func TestWriteBuffer_Replay(t *testing.T) {
+	t.Parallel()
+
+	cfg := WriteBufferConfig{}
+	flagext.DefaultValues(&cfg)
+	cfg.Dir = t.TempDir()
+	cfg.ReplayInterval = time.Hour
+
+	mock := &writeBufferPushMock{}
+	reg := prometheus.NewPedanticRegistry()
+	b := newWriteBuffer(cfg, mock.push, writeBufferTestMaxBytes(1000), reg, log.NewNopLogger())
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), b))
+
+	now := time.Now()
+	require.False(t, b.pending("user"))
+	require.NoError(t, b.add("user", writeBufferTestPayload(t, "too_old"), now.Add(-cfg.MaxAge-time.Second)))
+	require.NoError(t, b.add("user", writeBufferTestPayload(t, "first"), now))
+	require.NoError(t, b.add("user", writeBufferTestPayload(t, "second"), now))
+	require.Error(t, b.add("user", bytes.Repeat([]byte("a"), 1000), now))
+	require.True(t, b.pending("user"))
+
+	// The ingesters are still unavailable, so the replay stops at the first batch.
+	mock.setError(errFail)
+	b.replay(context.Background(), now)
+	assert.Empty(t, mock.requests)
+	require.True(t, b.pending("user"))
+
+	// The buffered requests are merged into a single batch.
+	mock.setError(nil)
+	b.replay(context.Background(), now)
+	assert.Equal(t, []string{"first", "second"}, mock.requests)
+	assert.Equal(t, 1, mock.pushes)
+	require.False(t, b.pending("user"))
+
+	// Requests rejected by the ingesters are dropped.
+	require.NoError(t, b.add("user", writeBufferTestPayload(t, "rejected"), now))
+	b.replay(context.Background(), now)
+	assert.Equal(t, []string{"first", "second"}, mock.requests)
+	require.False(t, b.pending("user"))
+
+	require.NoError(t, services.StopAndAwaitTerminated(context.Background(), b))
+
+	assert.NoError(t, testutil.GatherAndCompare(reg, bytes.NewBufferString(`
+		# HELP cortex_distributor_write_buffer_buffered_requests_total The total number of write requests buffered on disk while the ingesters are unavailable.
+		# TYPE cortex_distributor_write_buffer_buffered_requests_total counter
+		cortex_distributor_write_buffer_buffered_requests_total{user="user"} 4
+		# HELP cortex_distributor_write_buffer_dropped_requests_total The total number of write requests which couldn't be buffered or replayed.
+		# TYPE cortex_distributor_write_buffer_dropped_requests_total counter
+		cortex_distributor_write_buffer_dropped_requests_total{reason="buffer_full",user="user"} 1
+		cortex_distributor_write_buffer_dropped_requests_total{reason="rejected",user="user"} 1
+		cortex_distributor_write_buffer_dropped_requests_total{reason="too_old",user="user"} 1
+		# HELP cortex_distributor_write_buffer_replayed_requests_total The total number of buffered write requests successfully replayed to the ingesters.
+		# TYPE cortex_distributor_write_buffer_replayed_requests_total counter
+		cortex_distributor_write_buffer_replayed_requests_total{user="user"} 2
+		# HELP cortex_distributor_write_buffer_size_bytes The size on disk of the buffered write requests.
+		# TYPE cortex_distributor_write_buffer_size_bytes gauge
+		cortex_distributor_write_buffer_size_bytes{user="user"} 0
+	`)))
+}
+
+func TestWriteBuffer_ShouldReplayInBatchesUntilCaughtUp(t *testing.T) {
+	t.Parallel()
+
+	cfg := WriteBufferConfig{}
+	flagext.DefaultValues(&cfg)
+	cfg.Dir = t.TempDir()
+	cfg.ReplayInterval = time.Hour
+
+	mock := &writeBufferPushMock{}
+	b := newWriteBuffer(cfg, mock.push, writeBufferTestMaxBytes(1<<20), nil, log.NewNopLogger())
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), b))
+	defer services.StopAndAwaitTerminated(context.Background(), b) //nolint:errcheck
+
+	// Each batch holds 2 requests.
+	payloadSize := int64(writeBufferEntryHeaderSize + len(writeBufferTestPayload(t, "request-0")))
+	b.batchSize = 2 * payloadSize
+
+	now := time.Now()
+	var expected []string
+	for i := range 4 {
+		name := "request-" + strconv.Itoa(i)
+		require.NoError(t, b.add("user", writeBufferTestPayload(t, name), now))
+		expected = append(expected, name)
+	}
+
+	// The tenant keeps receiving requests while it's replayed, which are buffered as long as
+	// the replay isn't caught up.
+	next := 4
+	mock.onPush = func() {
+		if next >= 8 {
+			return
+		}
+		name := "request-" + strconv.Itoa(next)
+		buffered, err := b.addIfPending("user", func() ([]byte, error) { return writeBufferTestPayload(t, name), nil }, now)
+		require.NoError(t, err)
+		require.True(t, buffered)
+		expected = append(expected, name)
+		next++
+	}
+
+	b.replay(context.Background(), now)
+	assert.Equal(t, expected, mock.requests)
+	assert.Equal(t, 5, mock.pushes)
+	require.False(t, b.pending("user"))
+
+	// Once caught up, the new requests are pushed directly, without being marshaled.
+	buffered, err := b.addIfPending("user", func() ([]byte, error) {
+		require.Fail(t, "the request must not be marshaled")
+		return nil, nil
+	}, now)
+	require.NoError(t, err)
+	require.False(t, buffered)
+	require.False(t, b.pending("user"))
+}
+
+func TestWriteBuffer_ShouldEnforceTheTenantMaxBytesWhenReplaying(t *testing.T) {
+	t.Parallel()
+
+	cfg := WriteBufferConfig{}
+	flagext.DefaultValues(&cfg)
+	cfg.Dir = t.TempDir()
+	cfg.ReplayInterval = time.Hour
+
+	maxBytes := atomic.NewInt64(1000)
+	mock := &writeBufferPushMock{}
+	reg := prometheus.NewPedanticRegistry()
+	b := newWriteBuffer(cfg, mock.push, func(string) int { return int(maxBytes.Load()) }, reg, log.NewNopLogger())
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), b))
+	defer services.StopAndAwaitTerminated(context.Background(), b) //nolint:errcheck
+
+	now := time.Now()
+	for _, name := range []string{"first", "second", "third"} {
+		require.NoError(t, b.add("user", writeBufferTestPayload(t, name), now))
+	}
+
+	// The limit is lowered so that only the last request fits in the buffer.
+	maxBytes.Store(int64(writeBufferEntryHeaderSize + len(writeBufferTestPayload(t, "third"))))
+	b.replay(context.Background(), now)
+	assert.Equal(t, []string{"third"}, mock.requests)
+
+	assert.NoError(t, testutil.GatherAndCompare(reg, bytes.NewBufferString(`
+		# HELP cortex_distributor_write_buffer_dropped_requests_total The total number of write requests which couldn't be buffered or replayed.
+		# TYPE cortex_distributor_write_buffer_dropped_requests_total counter
+		cortex_distributor_write_buffer_dropped_requests_total{reason="buffer_full",user="user"} 2
+	`), "cortex_distributor_write_buffer_dropped_requests_total"))
+}
+
+func TestWriteBuffer_ShouldReplayRequestsBufferedBeforeRestart(t *testing.T) {
+	t.Parallel()
+
+	cfg := WriteBufferConfig{}
+	flagext.DefaultValues(&cfg)
+	cfg.Dir = t.TempDir()
+	cfg.ReplayInterval = time.Hour
+
+	mock := &writeBufferPushMock{}
+	b := newWriteBuffer(cfg, mock.push, writeBufferTestMaxBytes(1000), nil, log.NewNopLogger())
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), b))
+	require.NoError(t, b.add("user", writeBufferTestPayload(t, "first"), time.Now()))
+	require.NoError(t, b.add("user", writeBufferTestPayload(t, "second"), time.Now()))
+	require.NoError(t, services.StopAndAwaitTerminated(context.Background(), b))
+
+	b = newWriteBuffer(cfg, mock.push, writeBufferTestMaxBytes(1000), nil, log.NewNopLogger())
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), b))
+	defer services.StopAndAwaitTerminated(context.Background(), b) //nolint:errcheck
+
+	require.True(t, b.pending("user"))
+	b.replay(context.Background(), time.Now())
+	assert.Equal(t, []string{"first", "second"}, mock.requests)
+	require.False(t, b.pending("user"))
+}
+
+func TestDistributor_Push_ShouldBufferWriteRequestsWhileIngestersAreUnavailable(t *testing.T) {
+	t.Parallel()
+
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.WriteBufferMaxBytes = 1 << 20
+
+	ds, ingesters, regs, _ := prepare(t, prepConfig{
+		numIngesters:     3,
+		happyIngesters:   1,
+		numDistributors:  1,
+		shardByAllLabels: true,
+		limits:           limits,
+		writeBufferDir:   t.TempDir(),
+	})
+
+	ctx := user.InjectOrgID(context.Background(), "user")
+	_, err := ds[0].Push(ctx, makeWriteRequest(0, 5, 0, 0))
+	require.NoError(t, err)
+	require.True(t, ds[0].writeBuffer.pending("user"))
+
+	// While requests are buffered, the new ones are buffered too.
+	_, err = ds[0].Push(ctx, makeWriteRequest(5, 5, 0, 0))
+	require.NoError(t, err)
+
+	for _, ing := range ingesters {
+		ing.happy.Store(true)
+	}
+	ds[0].writeBuffer.replay(context.Background(), time.Now())
+	require.False(t, ds[0].writeBuffer.pending("user"))
+
+	// The ingesters which were unavailable receive the samples of both requests.
+	test.Poll(t, 5*time.Second, 10, func() any {
+		samples := 0
+		for _, ts := range ingesters[2].series() {
+			samples += len(ts.Samples)
+		}
+		return samples
+	})
+
+	assert.NoError(t, testutil.GatherAndCompare(regs[0], bytes.NewBufferString(`
+		# HELP cortex_distributor_write_buffer_buffered_requests_total The total number of write requests buffered on disk while the ingesters are unavailable.
+		# TYPE cortex_distributor_write_buffer_buffered_requests_total counter
+		cortex_distributor_write_buffer_buffered_requests_total{user="user"} 2
+		# HELP cortex_distributor_write_buffer_replayed_requests_total The total number of buffered write requests successfully replayed to the ingesters.
+		# TYPE cortex_distributor_write_buffer_replayed_requests_total counter
+		cortex_distributor_write_buffer_replayed_requests_total{user="user"} 2
+	`), "cortex_distributor_write_buffer_buffered_requests_total", "cortex_distributor_write_buffer_replayed_requests_total"))
+}
+
+func TestDistributor_Push_ShouldNotBufferWriteRequestsOfTenantsWithoutLimit(t *testing.T) {
+	t.Parallel()
+
+	ds, _, _, _ := prepare(t, prepConfig{
+		numIngesters:     3,
+		happyIngesters:   1,
+		numDistributors:  1,
+		shardByAllLabels: true,
+		writeBufferDir:   t.TempDir(),
+	})
+
+	ctx := user.InjectOrgID(context.Background(), "user")
+	_, err := ds[0].Push(ctx, makeWriteRequest(0, 5, 0, 0))
+	require.Error(t, err)
+	require.Equal(t, "5xx", getErrorStatus(err))
+	require.False(t, ds[0].writeBuffer.pending("user"))
+}
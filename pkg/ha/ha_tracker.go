@@ -6,6 +6,7 @@ import (
 	"flag"
 	"fmt"
 	"maps"
+	"math"
 	"math/rand"
 	"slices"
 	"strings"
@@ -32,6 +33,11 @@ const (
 
 var (
 	errNegativeUpdateTimeoutJitterMax = errors.New("HA tracker max update timeout jitter shouldn't be negative")
+
+	// ErrHATrackerDisabled is returned by the admin operations when the HA tracker is disabled.
+	ErrHATrackerDisabled = errors.New("HA tracker is disabled")
+	// ErrReplicaGroupNotFound is returned by the admin operations when no replica is elected for the replica group.
+	ErrReplicaGroupNotFound = errors.New("no elected replica found for the replica group")
 )
 
 // nolint:revive
@@ -146,6 +152,7 @@ func (d *ReplicaDesc) apply(other *ReplicaDesc) *ReplicaDesc {
 	d.Replica = other.Replica
 	d.ReceivedAt = other.ReceivedAt
 	d.DeletedAt = other.DeletedAt
+	d.PinnedUntil = other.PinnedUntil
 	return proto.Clone(d).(*ReplicaDesc)
 }
 
@@ -164,6 +171,14 @@ func (d *ReplicaDesc) RemoveTombstones(_ time.Time) (total, removed int) {
 	return
 }
 
+// Tombstones returns the replica if it has been marked as deleted, along with the deletion time.
+func (d *ReplicaDesc) Tombstones() map[string]time.Time {
+	if d.DeletedAt <= 0 {
+		return nil
+	}
+	return map[string]time.Time{d.Replica: timestamp.Time(d.DeletedAt)}
+}
+
 // RegisterFlags adds the flags required to config this to the given FlagSet.
 func (cfg *HATrackerConfig) RegisterFlagsWithPrefix(flagPrefix string, kvPrefix string, f *flag.FlagSet) {
 	finalFlagPrefix := ""
@@ -545,8 +560,8 @@ func (c *HATracker) cleanupOldReplicas(ctx context.Context, deadline time.Time)
 			continue
 		}
 
-		// Not marked as deleted yet.
-		if desc.DeletedAt == 0 && timestamp.Time(desc.ReceivedAt).Before(deadline) {
+		// Not marked as deleted yet. Pinned replicas are kept until the pin expires.
+		if desc.DeletedAt == 0 && timestamp.Time(desc.ReceivedAt).Before(deadline) && !desc.isPinned(time.Now()) {
 			err := c.client.CAS(ctx, key, func(in any) (out any, retry bool, err error) {
 				d, ok := in.(*ReplicaDesc)
 				if !ok || d == nil || d.DeletedAt > 0 || !timestamp.Time(desc.ReceivedAt).Before(deadline) {
@@ -587,6 +602,11 @@ func (c *HATracker) CheckReplica(ctx context.Context, userID, replicaGroup, repl
 	replicaGroups := len(c.replicaGroups[userID])
 	c.electedLock.RUnlock()
 
+	// Samples from other replicas are rejected while the elected one is pinned.
+	if ok && entry.isPinned(now) && entry.Replica != replica {
+		return ReplicasNotMatchError{replica: replica, elected: entry.Replica}
+	}
+
 	if ok && now.Sub(timestamp.Time(entry.ReceivedAt)) < c.cfg.UpdateTimeout+c.updateTimeoutJitter {
 		if entry.Replica != replica {
 			return ReplicasNotMatchError{replica: replica, elected: entry.Replica}
@@ -625,10 +645,20 @@ func (c *HATracker) checkKVStore(ctx context.Context, key, replica, userID strin
 			}
 
 			// We shouldn't failover to accepting a new replica if the timestamp we've received this sample at
-			// is less than failover timeout amount of time since the timestamp in the KV store.
-			if desc.Replica != replica && now.Sub(timestamp.Time(desc.ReceivedAt)) < c.limits.HATrackerFailoverTimeout(userID) {
+			// is less than failover timeout amount of time since the timestamp in the KV store, or if the
+			// elected replica is pinned.
+			if desc.Replica != replica && (desc.isPinned(now) || now.Sub(timestamp.Time(desc.ReceivedAt)) < c.limits.HATrackerFailoverTimeout(userID)) {
 				return nil, false, ReplicasNotMatchError{replica: replica, elected: desc.Replica}
 			}
+
+			// Keep the pin while refreshing the timestamp of the pinned replica.
+			if desc.Replica == replica && desc.isPinned(now) {
+				return &ReplicaDesc{
+					Replica:     replica,
+					ReceivedAt:  timestamp.FromTime(now),
+					PinnedUntil: desc.PinnedUntil,
+				}, true, nil
+			}
 		}
 
 		// There was either invalid or no data for the key, so we now accept samples
@@ -642,6 +672,83 @@ func (c *HATracker) checkKVStore(ctx context.Context, key, replica, userID strin
 	})
 }
 
+// isPinned returns whether the replica is pinned at the given time.
+func (d *ReplicaDesc) isPinned(now time.Time) bool {
+	return d.PinnedUntil > timestamp.FromTime(now)
+}
+
+// PinReplica elects the given replica for the user's replica group and pins it until the given
+// time, or indefinitely if zero: samples from the other replicas are rejected regardless of the
+// failover timeout until the pin expires or is removed.
+func (c *HATracker) PinReplica(ctx context.Context, userID, replicaGroup, replica string, until, now time.Time) error {
+	if !c.cfg.EnableHATracker {
+		return ErrHATrackerDisabled
+	}
+
+	pinnedUntil := int64(math.MaxInt64)
+	if !until.IsZero() {
+		pinnedUntil = timestamp.FromTime(until)
+	}
+
+	return c.client.CAS(ctx, fmt.Sprintf("%s/%s", userID, replicaGroup), func(_ any) (out any, retry bool, err error) {
+		return &ReplicaDesc{
+			Replica:     replica,
+			ReceivedAt:  timestamp.FromTime(now),
+			PinnedUntil: pinnedUntil,
+		}, true, nil
+	})
+}
+
+// UnpinReplica removes the pin of the elected replica of the user's replica group, which is then
+// subject to the failover timeout again.
+func (c *HATracker) UnpinReplica(ctx context.Context, userID, replicaGroup string, now time.Time) error {
+	if !c.cfg.EnableHATracker {
+		return ErrHATrackerDisabled
+	}
+
+	return c.client.CAS(ctx, fmt.Sprintf("%s/%s", userID, replicaGroup), func(in any) (out any, retry bool, err error) {
+		desc, ok := in.(*ReplicaDesc)
+		if !ok || desc == nil || desc.DeletedAt > 0 {
+			return nil, false, ErrReplicaGroupNotFound
+		}
+
+		return &ReplicaDesc{
+			Replica:    desc.Replica,
+			ReceivedAt: timestamp.FromTime(now),
+		}, true, nil
+	})
+}
+
+// ForceFailover fails over the user's replica group regardless of the failover timeout and of any
+// pin. If replica is empty, the elected replica is removed and the next replica pushing samples is
+// elected, otherwise the given replica is elected.
+func (c *HATracker) ForceFailover(ctx context.Context, userID, replicaGroup, replica string, now time.Time) error {
+	if !c.cfg.EnableHATracker {
+		return ErrHATrackerDisabled
+	}
+
+	return c.client.CAS(ctx, fmt.Sprintf("%s/%s", userID, replicaGroup), func(in any) (out any, retry bool, err error) {
+		desc, ok := in.(*ReplicaDesc)
+		if !ok || desc == nil || desc.DeletedAt > 0 {
+			return nil, false, ErrReplicaGroupNotFound
+		}
+
+		if replica == "" {
+			// Marking the entry as deleted removes it from the distributors' memory, like the cleanup does.
+			return &ReplicaDesc{
+				Replica:    desc.Replica,
+				ReceivedAt: desc.ReceivedAt,
+				DeletedAt:  timestamp.FromTime(now),
+			}, true, nil
+		}
+
+		return &ReplicaDesc{
+			Replica:    replica,
+			ReceivedAt: timestamp.FromTime(now),
+		}, true, nil
+	})
+}
+
 func (c *HATracker) Cfg() HATrackerConfig {
 	return c.cfg
 }
@@ -706,9 +813,10 @@ func (c *HATracker) SnapshotElectedReplicas() map[string]ReplicaDesc {
 	electedCopy := make(map[string]ReplicaDesc)
 	for key, desc := range c.elected {
 		electedCopy[key] = ReplicaDesc{
-			Replica:    desc.Replica,
-			ReceivedAt: desc.ReceivedAt,
-			DeletedAt:  desc.DeletedAt,
+			Replica:     desc.Replica,
+			ReceivedAt:  desc.ReceivedAt,
+			DeletedAt:   desc.DeletedAt,
+			PinnedUntil: desc.PinnedUntil,
 		}
 	}
 	return electedCopy
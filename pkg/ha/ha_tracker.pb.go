@@ -34,6 +34,9 @@ type ReplicaDesc struct {
 	// already remove entry from memory. Actual deletion from KV store does *not* trigger
 	// "watch" notification with a key for all KV stores.
 	DeletedAt int64 `protobuf:"varint,3,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	// Unix timestamp in milliseconds until which the replica is pinned: samples from other
+	// replicas are rejected regardless of the failover timeout. 0 if not pinned.
+	PinnedUntil int64 `protobuf:"varint,4,opt,name=pinned_until,json=pinnedUntil,proto3" json:"pinned_until,omitempty"`
 }
 
 func (m *ReplicaDesc) Reset()      { *m = ReplicaDesc{} }
@@ -89,6 +92,13 @@ func (m *ReplicaDesc) GetDeletedAt() int64 {
 	return 0
 }
 
+func (m *ReplicaDesc) GetPinnedUntil() int64 {
+	if m != nil {
+		return m.PinnedUntil
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*ReplicaDesc)(nil), "ha.ReplicaDesc")
 }
@@ -96,20 +106,21 @@ func init() {
 func init() { proto.RegisterFile("ha_tracker.proto", fileDescriptor_86f0e7bcf71d860b) }
 
 var fileDescriptor_86f0e7bcf71d860b = []byte{
-	// 197 bytes of a gzipped FileDescriptorProto
+	// 220 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x12, 0xc8, 0x48, 0x8c, 0x2f,
 	0x29, 0x4a, 0x4c, 0xce, 0x4e, 0x2d, 0xd2, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0xca, 0x48,
-	0x94, 0x12, 0x49, 0xcf, 0x4f, 0xcf, 0x07, 0x73, 0xf5, 0x41, 0x2c, 0x88, 0x8c, 0x52, 0x3a, 0x17,
-	0x77, 0x50, 0x6a, 0x41, 0x4e, 0x66, 0x72, 0xa2, 0x4b, 0x6a, 0x71, 0xb2, 0x90, 0x04, 0x17, 0x7b,
-	0x11, 0x84, 0x2b, 0xc1, 0xa8, 0xc0, 0xa8, 0xc1, 0x19, 0x04, 0xe3, 0x0a, 0xc9, 0x73, 0x71, 0x17,
-	0xa5, 0x26, 0xa7, 0x66, 0x96, 0xa5, 0xa6, 0xc4, 0x27, 0x96, 0x48, 0x30, 0x29, 0x30, 0x6a, 0x30,
-	0x07, 0x71, 0xc1, 0x84, 0x1c, 0x4b, 0x84, 0x64, 0xb9, 0xb8, 0x52, 0x52, 0x73, 0x52, 0x4b, 0x20,
-	0xf2, 0xcc, 0x60, 0x79, 0x4e, 0xa8, 0x88, 0x63, 0x89, 0x93, 0xc9, 0x85, 0x87, 0x72, 0x0c, 0x37,
-	0x1e, 0xca, 0x31, 0x7c, 0x78, 0x28, 0xc7, 0xd8, 0xf0, 0x48, 0x8e, 0x71, 0xc5, 0x23, 0x39, 0xc6,
-	0x13, 0x8f, 0xe4, 0x18, 0x2f, 0x3c, 0x92, 0x63, 0x7c, 0xf0, 0x48, 0x8e, 0xf1, 0xc5, 0x23, 0x39,
-	0x86, 0x0f, 0x8f, 0xe4, 0x18, 0x27, 0x3c, 0x96, 0x63, 0xb8, 0xf0, 0x58, 0x8e, 0xe1, 0xc6, 0x63,
-	0x39, 0x86, 0x24, 0x36, 0xb0, 0x2b, 0x8d, 0x01, 0x01, 0x00, 0x00, 0xff, 0xff, 0xb4, 0x1b, 0xc6,
-	0x42, 0xd3, 0x00, 0x00, 0x00,
+	0x94, 0x12, 0x49, 0xcf, 0x4f, 0xcf, 0x07, 0x73, 0xf5, 0x41, 0x2c, 0x88, 0x8c, 0x52, 0x17, 0x23,
+	0x17, 0x77, 0x50, 0x6a, 0x41, 0x4e, 0x66, 0x72, 0xa2, 0x4b, 0x6a, 0x71, 0xb2, 0x90, 0x04, 0x17,
+	0x7b, 0x11, 0x84, 0x2b, 0xc1, 0xa8, 0xc0, 0xa8, 0xc1, 0x19, 0x04, 0xe3, 0x0a, 0xc9, 0x73, 0x71,
+	0x17, 0xa5, 0x26, 0xa7, 0x66, 0x96, 0xa5, 0xa6, 0xc4, 0x27, 0x96, 0x48, 0x30, 0x29, 0x30, 0x6a,
+	0x30, 0x07, 0x71, 0xc1, 0x84, 0x1c, 0x4b, 0x84, 0x64, 0xb9, 0xb8, 0x52, 0x52, 0x73, 0x52, 0x4b,
+	0x20, 0xf2, 0xcc, 0x60, 0x79, 0x4e, 0xa8, 0x88, 0x63, 0x89, 0x90, 0x22, 0x17, 0x4f, 0x41, 0x66,
+	0x5e, 0x5e, 0x6a, 0x4a, 0x7c, 0x69, 0x5e, 0x49, 0x66, 0x8e, 0x04, 0x0b, 0x58, 0x01, 0x37, 0x44,
+	0x2c, 0x14, 0x24, 0xe4, 0x64, 0x72, 0xe1, 0xa1, 0x1c, 0xc3, 0x8d, 0x87, 0x72, 0x0c, 0x1f, 0x1e,
+	0xca, 0x31, 0x36, 0x3c, 0x92, 0x63, 0x5c, 0xf1, 0x48, 0x8e, 0xf1, 0xc4, 0x23, 0x39, 0xc6, 0x0b,
+	0x8f, 0xe4, 0x18, 0x1f, 0x3c, 0x92, 0x63, 0x7c, 0xf1, 0x48, 0x8e, 0xe1, 0xc3, 0x23, 0x39, 0xc6,
+	0x09, 0x8f, 0xe5, 0x18, 0x2e, 0x3c, 0x96, 0x63, 0xb8, 0xf1, 0x58, 0x8e, 0x21, 0x89, 0x0d, 0xec,
+	0x13, 0x63, 0xc0, 0x00, 0xc0, 0xb8, 0x36, 0x41, 0xf7, 0x00, 0x00, 0x00,
 }
 
 func (this *ReplicaDesc) Equal(that interface{}) bool {
@@ -140,17 +151,21 @@ func (this *ReplicaDesc) Equal(that interface{}) bool {
 	if this.DeletedAt != that1.DeletedAt {
 		return false
 	}
+	if this.PinnedUntil != that1.PinnedUntil {
+		return false
+	}
 	return true
 }
 func (this *ReplicaDesc) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 7)
+	s := make([]string, 0, 8)
 	s = append(s, "&ha.ReplicaDesc{")
 	s = append(s, "Replica: "+fmt.Sprintf("%#v", this.Replica)+",\n")
 	s = append(s, "ReceivedAt: "+fmt.Sprintf("%#v", this.ReceivedAt)+",\n")
 	s = append(s, "DeletedAt: "+fmt.Sprintf("%#v", this.DeletedAt)+",\n")
+	s = append(s, "PinnedUntil: "+fmt.Sprintf("%#v", this.PinnedUntil)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -182,6 +197,11 @@ func (m *ReplicaDesc) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.PinnedUntil != 0 {
+		i = encodeVarintHaTracker(dAtA, i, uint64(m.PinnedUntil))
+		i--
+		dAtA[i] = 0x20
+	}
 	if m.DeletedAt != 0 {
 		i = encodeVarintHaTracker(dAtA, i, uint64(m.DeletedAt))
 		i--
@@ -229,6 +249,9 @@ func (m *ReplicaDesc) Size() (n int) {
 	if m.DeletedAt != 0 {
 		n += 1 + sovHaTracker(uint64(m.DeletedAt))
 	}
+	if m.PinnedUntil != 0 {
+		n += 1 + sovHaTracker(uint64(m.PinnedUntil))
+	}
 	return n
 }
 
@@ -246,6 +269,7 @@ func (this *ReplicaDesc) String() string {
 		`Replica:` + fmt.Sprintf("%v", this.Replica) + `,`,
 		`ReceivedAt:` + fmt.Sprintf("%v", this.ReceivedAt) + `,`,
 		`DeletedAt:` + fmt.Sprintf("%v", this.DeletedAt) + `,`,
+		`PinnedUntil:` + fmt.Sprintf("%v", this.PinnedUntil) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -357,6 +381,25 @@ func (m *ReplicaDesc) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PinnedUntil", wireType)
+			}
+			m.PinnedUntil = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowHaTracker
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.PinnedUntil |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipHaTracker(dAtA[iNdEx:])
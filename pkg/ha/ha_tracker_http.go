@@ -1,15 +1,19 @@
 package ha
 
 import (
+	"errors"
 	"html/template"
+	"math"
 	"net/http"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/timestamp"
 
 	"github.com/cortexproject/cortex/pkg/util"
+	"github.com/cortexproject/cortex/pkg/util/users"
 )
 
 const trackerTpl = `
@@ -31,6 +35,7 @@ const trackerTpl = `
 					<th>Elected Time</th>
 					<th>Time Until Update</th>
 					<th>Time Until Failover</th>
+					<th>Pinned Until</th>
 				</tr>
 			</thead>
 			<tbody>
@@ -42,6 +47,7 @@ const trackerTpl = `
 					<td>{{ .ElectedAt }}</td>
 					<td>{{ .UpdateTime }}</td>
 					<td>{{ .FailoverTime }}</td>
+					<td>{{ .PinnedUntil }}</td>
 				</tr>
 				{{ end }}
 			</tbody>
@@ -70,6 +76,7 @@ func (h *HATracker) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		ElectedAt    time.Time     `json:"electedAt"`
 		UpdateTime   time.Duration `json:"updateDuration"`
 		FailoverTime time.Duration `json:"failoverDuration"`
+		PinnedUntil  string        `json:"pinnedUntil,omitempty"`
 	}
 
 	electedReplicas := []replica{}
@@ -83,6 +90,7 @@ func (h *HATracker) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 			ElectedAt:    timestamp.Time(desc.ReceivedAt),
 			UpdateTime:   time.Until(timestamp.Time(desc.ReceivedAt).Add(h.cfg.UpdateTimeout)),
 			FailoverTime: time.Until(timestamp.Time(desc.ReceivedAt).Add(h.limits.HATrackerFailoverTimeout(chunks[0]))),
+			PinnedUntil:  formatPinnedUntil(desc),
 		})
 	}
 	h.electedLock.RUnlock()
@@ -107,3 +115,89 @@ func (h *HATracker) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		Config:  h.trackerStatusConfig,
 	}, trackerTmpl, req)
 }
+
+func formatPinnedUntil(desc ReplicaDesc) string {
+	switch {
+	case !desc.isPinned(time.Now()):
+		return ""
+	case desc.PinnedUntil == math.MaxInt64:
+		return "forever"
+	default:
+		return timestamp.Time(desc.PinnedUntil).String()
+	}
+}
+
+// PinReplicaHandler pins the replica of the tenant's replica group given by the "cluster" and
+// "replica" form values. The optional "ttl" form value is the duration of the pin.
+func (h *HATracker) PinReplicaHandler(w http.ResponseWriter, req *http.Request) {
+	userID, cluster, ok := h.parseAdminRequest(w, req)
+	if !ok {
+		return
+	}
+
+	replica := req.FormValue("replica")
+	if replica == "" {
+		http.Error(w, "replica is required", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	var until time.Time
+	if ttl := req.FormValue("ttl"); ttl != "" {
+		d, err := model.ParseDuration(ttl)
+		if err != nil || d <= 0 {
+			http.Error(w, "invalid ttl: must be a positive duration", http.StatusBadRequest)
+			return
+		}
+		until = now.Add(time.Duration(d))
+	}
+
+	h.writeAdminResponse(w, h.PinReplica(req.Context(), userID, cluster, replica, until, now))
+}
+
+// UnpinReplicaHandler removes the pin of the tenant's replica group given by the "cluster" form value.
+func (h *HATracker) UnpinReplicaHandler(w http.ResponseWriter, req *http.Request) {
+	userID, cluster, ok := h.parseAdminRequest(w, req)
+	if !ok {
+		return
+	}
+
+	h.writeAdminResponse(w, h.UnpinReplica(req.Context(), userID, cluster, time.Now()))
+}
+
+// FailoverHandler forces the failover of the tenant's replica group given by the "cluster" form
+// value, to the replica given by the optional "replica" form value.
+func (h *HATracker) FailoverHandler(w http.ResponseWriter, req *http.Request) {
+	userID, cluster, ok := h.parseAdminRequest(w, req)
+	if !ok {
+		return
+	}
+
+	h.writeAdminResponse(w, h.ForceFailover(req.Context(), userID, cluster, req.FormValue("replica"), time.Now()))
+}
+
+func (h *HATracker) parseAdminRequest(w http.ResponseWriter, req *http.Request) (string, string, bool) {
+	userID, err := users.TenantID(req.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return "", "", false
+	}
+
+	cluster := req.FormValue("cluster")
+	if cluster == "" {
+		http.Error(w, "cluster is required", http.StatusBadRequest)
+		return "", "", false
+	}
+	return userID, cluster, true
+}
+
+func (h *HATracker) writeAdminResponse(w http.ResponseWriter, err error) {
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusNoContent)
+	case errors.Is(err, ErrHATrackerDisabled), errors.Is(err, ErrReplicaGroupNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,89 @@
+package ha
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/prometheus/model/timestamp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/ring/kv"
+	"github.com/cortexproject/cortex/pkg/util/services"
+)
+
+func TestHATracker_AdminHandlers(t *testing.T) {
+	ctx := context.Background()
+	c, err := NewHATracker(HATrackerConfig{
+		EnableHATracker: true,
+		KVStore:         kv.Config{Store: "inmemory"},
+		UpdateTimeout:   time.Second,
+	}, trackerLimits{maxReplicaGroups: 100, failoverTimeout: time.Minute}, haTrackerStatusConfig, nil, "test-ha-tracker", log.NewNopLogger())
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(ctx, c))
+	defer services.StopAndAwaitTerminated(ctx, c) //nolint:errcheck
+
+	require.NoError(t, c.CheckReplica(ctx, "user", "c1", "replica1", time.Now()))
+
+	do := func(handler http.HandlerFunc, orgID string, form url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if orgID != "" {
+			req = req.WithContext(user.InjectOrgID(req.Context(), orgID))
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+	get := func() *ReplicaDesc {
+		v, err := c.client.Get(ctx, "user/c1")
+		require.NoError(t, err)
+		return v.(*ReplicaDesc)
+	}
+
+	t.Run("requires a tenant", func(t *testing.T) {
+		rec := do(c.PinReplicaHandler, "", url.Values{"cluster": {"c1"}, "replica": {"replica2"}})
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, do(c.PinReplicaHandler, "user", url.Values{"replica": {"replica2"}}).Code)
+		assert.Equal(t, http.StatusBadRequest, do(c.PinReplicaHandler, "user", url.Values{"cluster": {"c1"}}).Code)
+		assert.Equal(t, http.StatusBadRequest, do(c.PinReplicaHandler, "user", url.Values{"cluster": {"c1"}, "replica": {"replica2"}, "ttl": {"-1m"}}).Code)
+		assert.Equal(t, http.StatusNotFound, do(c.FailoverHandler, "user", url.Values{"cluster": {"unknown"}}).Code)
+	})
+
+	t.Run("pin with ttl", func(t *testing.T) {
+		before := time.Now()
+		rec := do(c.PinReplicaHandler, "user", url.Values{"cluster": {"c1"}, "replica": {"replica2"}, "ttl": {"1h"}})
+		require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+
+		desc := get()
+		assert.Equal(t, "replica2", desc.Replica)
+		assert.GreaterOrEqual(t, desc.PinnedUntil, timestamp.FromTime(before.Add(time.Hour)))
+		assert.Less(t, desc.PinnedUntil, int64(math.MaxInt64))
+	})
+
+	t.Run("unpin", func(t *testing.T) {
+		rec := do(c.UnpinReplicaHandler, "user", url.Values{"cluster": {"c1"}})
+		require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+
+		desc := get()
+		assert.Equal(t, "replica2", desc.Replica)
+		assert.Zero(t, desc.PinnedUntil)
+	})
+
+	t.Run("failover", func(t *testing.T) {
+		rec := do(c.FailoverHandler, "user", url.Values{"cluster": {"c1"}, "replica": {"replica1"}})
+		require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+		assert.Equal(t, "replica1", get().Replica)
+	})
+}
@@ -1354,3 +1354,81 @@ func TestReplicaDesc_MergeContent(t *testing.T) {
 	emptyContent := emptyDesc.MergeContent()
 	require.Nil(t, emptyContent)
 }
+
+func TestHATracker_PinReplica(t *testing.T) {
+	t.Parallel()
+	user := "userPinReplica"
+	ctx := context.Background()
+
+	c, err := NewHATracker(HATrackerConfig{
+		EnableHATracker: true,
+		KVStore:         kv.Config{Store: "inmemory"},
+		UpdateTimeout:   100 * time.Millisecond,
+	}, trackerLimits{maxReplicaGroups: 100, failoverTimeout: time.Second}, haTrackerStatusConfig, nil, "test-ha-tracker", log.NewNopLogger())
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(ctx, c))
+	defer services.StopAndAwaitTerminated(ctx, c) //nolint:errcheck
+
+	now := time.Now()
+	require.NoError(t, c.CheckReplica(ctx, user, "c1", "replica1", now))
+
+	// Pin replica2 for one minute.
+	require.NoError(t, c.PinReplica(ctx, user, "c1", "replica2", now.Add(time.Minute), now))
+
+	// Samples from replica1 are rejected even after the failover timeout.
+	assert.ErrorIs(t, c.CheckReplica(ctx, user, "c1", "replica1", now.Add(10*time.Second)), ReplicasNotMatchError{})
+	assert.NoError(t, c.CheckReplica(ctx, user, "c1", "replica2", now.Add(10*time.Second)))
+
+	// The pin is kept when the timestamp of the pinned replica is refreshed.
+	assert.ErrorIs(t, c.CheckReplica(ctx, user, "c1", "replica1", now.Add(20*time.Second)), ReplicasNotMatchError{})
+
+	// Once the pin expires, the failover timeout applies again.
+	assert.NoError(t, c.CheckReplica(ctx, user, "c1", "replica1", now.Add(2*time.Minute)))
+
+	// Pin without expiry, then unpin.
+	require.NoError(t, c.PinReplica(ctx, user, "c1", "replica2", time.Time{}, now.Add(2*time.Minute)))
+	assert.ErrorIs(t, c.CheckReplica(ctx, user, "c1", "replica1", now.Add(time.Hour)), ReplicasNotMatchError{})
+	require.NoError(t, c.UnpinReplica(ctx, user, "c1", now.Add(time.Hour)))
+	assert.NoError(t, c.CheckReplica(ctx, user, "c1", "replica1", now.Add(time.Hour+2*time.Second)))
+
+	assert.ErrorIs(t, c.UnpinReplica(ctx, user, "unknown", now), ErrReplicaGroupNotFound)
+}
+
+func TestHATracker_ForceFailover(t *testing.T) {
+	t.Parallel()
+	user := "userForceFailover"
+	ctx := context.Background()
+
+	c, err := NewHATracker(HATrackerConfig{
+		EnableHATracker: true,
+		KVStore:         kv.Config{Store: "inmemory"},
+		UpdateTimeout:   100 * time.Millisecond,
+	}, trackerLimits{maxReplicaGroups: 100, failoverTimeout: time.Second}, haTrackerStatusConfig, nil, "test-ha-tracker", log.NewNopLogger())
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(ctx, c))
+	defer services.StopAndAwaitTerminated(ctx, c) //nolint:errcheck
+
+	now := time.Now()
+	require.NoError(t, c.CheckReplica(ctx, user, "c1", "replica1", now))
+	assert.ErrorIs(t, c.ForceFailover(ctx, user, "unknown", "", now), ErrReplicaGroupNotFound)
+
+	// Failover to replica2 before the failover timeout.
+	require.NoError(t, c.ForceFailover(ctx, user, "c1", "replica2", now.Add(time.Millisecond)))
+	checkReplicaTimestamp(t, time.Second, c, user, "c1", "replica2", now.Add(time.Millisecond))
+	assert.ErrorIs(t, c.CheckReplica(ctx, user, "c1", "replica1", now.Add(500*time.Millisecond)), ReplicasNotMatchError{})
+
+	// Without a replica, the next replica pushing samples is elected.
+	require.NoError(t, c.ForceFailover(ctx, user, "c1", "", now.Add(600*time.Millisecond)))
+	assert.NoError(t, c.CheckReplica(ctx, user, "c1", "replica1", now.Add(700*time.Millisecond)))
+	checkReplicaTimestamp(t, time.Second, c, user, "c1", "replica1", now.Add(700*time.Millisecond))
+}
+
+func TestHATracker_AdminOperationsWhenDisabled(t *testing.T) {
+	c, err := NewHATracker(HATrackerConfig{EnableHATracker: false}, nil, haTrackerStatusConfig, nil, "test-ha-tracker", log.NewNopLogger())
+	require.NoError(t, err)
+
+	now := time.Now()
+	assert.ErrorIs(t, c.PinReplica(context.Background(), "user", "c1", "replica1", time.Time{}, now), ErrHATrackerDisabled)
+	assert.ErrorIs(t, c.UnpinReplica(context.Background(), "user", "c1", now), ErrHATrackerDisabled)
+	assert.ErrorIs(t, c.ForceFailover(context.Background(), "user", "c1", "", now), ErrHATrackerDisabled)
+}
@@ -16,6 +16,16 @@ const (
 // ActiveSeries is keeping track of recently active series for a single tenant.
 type ActiveSeries struct {
 	stripes [numActiveSeriesStripes]activeSeriesStripe
+
+	// Optional, notified when series become active or get purged.
+	observer activeSeriesObserver
+}
+
+// activeSeriesObserver is notified when a series becomes active and when it is purged because
+// it's not active anymore. Functions are called while holding the stripe lock.
+type activeSeriesObserver interface {
+	seriesActivated(series labels.Labels)
+	seriesDeactivated(series labels.Labels)
 }
 
 // activeSeriesStripe holds a subset of the series timestamps for a single tenant.
@@ -53,14 +63,14 @@ func NewActiveSeries() *ActiveSeries {
 func (c *ActiveSeries) UpdateSeries(series labels.Labels, hash uint64, now time.Time, nativeHistogram bool, labelsCopy func(labels.Labels) labels.Labels) {
 	stripeID := hash % numActiveSeriesStripes
 
-	c.stripes[stripeID].updateSeriesTimestamp(now, series, hash, nativeHistogram, labelsCopy)
+	c.stripes[stripeID].updateSeriesTimestamp(now, series, hash, nativeHistogram, labelsCopy, c.observer)
 }
 
 // Purge removes expired entries from the cache. This function should be called
 // periodically to avoid memory leaks.
 func (c *ActiveSeries) Purge(keepUntil time.Time) {
 	for s := range numActiveSeriesStripes {
-		c.stripes[s].purge(keepUntil)
+		c.stripes[s].purge(keepUntil, c.observer)
 	}
 }
 
@@ -87,13 +97,13 @@ func (c *ActiveSeries) ActiveNativeHistogram() int {
 	return total
 }
 
-func (s *activeSeriesStripe) updateSeriesTimestamp(now time.Time, series labels.Labels, fingerprint uint64, nativeHistogram bool, labelsCopy func(labels.Labels) labels.Labels) {
+func (s *activeSeriesStripe) updateSeriesTimestamp(now time.Time, series labels.Labels, fingerprint uint64, nativeHistogram bool, labelsCopy func(labels.Labels) labels.Labels, observer activeSeriesObserver) {
 	nowNanos := now.UnixNano()
 
 	e := s.findEntryForSeries(fingerprint, series)
 	entryTimeSet := false
 	if e == nil {
-		e, entryTimeSet = s.findOrCreateEntryForSeries(fingerprint, series, nowNanos, nativeHistogram, labelsCopy)
+		e, entryTimeSet = s.findOrCreateEntryForSeries(fingerprint, series, nowNanos, nativeHistogram, labelsCopy, observer)
 	}
 
 	if !entryTimeSet {
@@ -127,7 +137,7 @@ func (s *activeSeriesStripe) findEntryForSeries(fingerprint uint64, series label
 	return nil
 }
 
-func (s *activeSeriesStripe) findOrCreateEntryForSeries(fingerprint uint64, series labels.Labels, nowNanos int64, nativeHistogram bool, labelsCopy func(labels.Labels) labels.Labels) (*atomic.Int64, bool) {
+func (s *activeSeriesStripe) findOrCreateEntryForSeries(fingerprint uint64, series labels.Labels, nowNanos int64, nativeHistogram bool, labelsCopy func(labels.Labels) labels.Labels, observer activeSeriesObserver) (*atomic.Int64, bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -149,6 +159,9 @@ func (s *activeSeriesStripe) findOrCreateEntryForSeries(fingerprint uint64, seri
 	}
 
 	s.refs[fingerprint] = append(s.refs[fingerprint], e)
+	if observer != nil {
+		observer.seriesActivated(e.lbs)
+	}
 
 	return e.nanos, true
 }
@@ -163,7 +176,7 @@ func (s *activeSeriesStripe) clear() {
 	s.active = 0
 }
 
-func (s *activeSeriesStripe) purge(keepUntil time.Time) {
+func (s *activeSeriesStripe) purge(keepUntil time.Time, observer activeSeriesObserver) {
 	keepUntilNanos := keepUntil.UnixNano()
 	if oldest := s.oldestEntryTs.Load(); oldest > 0 && keepUntilNanos <= oldest {
 		// Nothing to do.
@@ -183,6 +196,9 @@ func (s *activeSeriesStripe) purge(keepUntil time.Time) {
 		if len(entries) == 1 {
 			ts := entries[0].nanos.Load()
 			if ts < keepUntilNanos {
+				if observer != nil {
+					observer.seriesDeactivated(entries[0].lbs)
+				}
 				delete(s.refs, fp)
 				continue
 			}
@@ -202,6 +218,9 @@ func (s *activeSeriesStripe) purge(keepUntil time.Time) {
 		for i := 0; i < len(entries); {
 			ts := entries[i].nanos.Load()
 			if ts < keepUntilNanos {
+				if observer != nil {
+					observer.seriesDeactivated(entries[i].lbs)
+				}
 				entries = append(entries[:i], entries[i+1:]...)
 			} else {
 				if ts < oldest {
@@ -74,6 +74,8 @@ type closableHealthAndIngesterClient struct {
 	streamPushChan          chan *streamWriteJob
 	streamCtx               context.Context
 	streamCancel            context.CancelFunc
+	// Nil if the stream flow control is disabled.
+	flowControl *streamFlowController
 }
 
 func (c *closableHealthAndIngesterClient) PushPreAlloc(ctx context.Context, in *cortexpb.PreallocWriteRequest, opts ...grpc.CallOption) (*cortexpb.WriteResponse, error) {
@@ -106,26 +108,33 @@ func (c *closableHealthAndIngesterClient) PushStreamConnection(ctx context.Conte
 			return nil, err
 		}
 
-		streamReq := &cortexpb.StreamWriteRequest{
-			TenantID: tenantID,
-			Request:  in,
+		if c.flowControl != nil {
+			return c.pushWithFlowControl(ctx, tenantID, in)
 		}
 
-		job := &streamWriteJob{
-			req:      streamReq,
-			sendDone: make(chan struct{}),
-		}
-		c.streamPushChan <- job
-		select {
-		case <-job.sendDone:
-			return job.resp, job.err
-		case <-ctx.Done():
-			<-job.sendDone
-			return nil, ctx.Err()
-		}
+		return c.sendStreamJob(ctx, &cortexpb.StreamWriteRequest{
+			TenantID: tenantID,
+			Request:  in,
+		})
 	})
 }
 
+// sendStreamJob sends the request to the stream workers and waits for its response.
+func (c *closableHealthAndIngesterClient) sendStreamJob(ctx context.Context, streamReq *cortexpb.StreamWriteRequest) (*cortexpb.WriteResponse, error) {
+	job := &streamWriteJob{
+		req:      streamReq,
+		sendDone: make(chan struct{}),
+	}
+	c.streamPushChan <- job
+	select {
+	case <-job.sendDone:
+		return job.resp, job.err
+	case <-ctx.Done():
+		<-job.sendDone
+		return nil, ctx.Err()
+	}
+}
+
 func (c *closableHealthAndIngesterClient) handlePushRequest(mainFunc func() (*cortexpb.WriteResponse, error)) (*cortexpb.WriteResponse, error) {
 	currentInflight := c.inflightRequests.Inc()
 	c.inflightPushRequests.WithLabelValues(c.addr).Set(float64(currentInflight))
@@ -161,6 +170,10 @@ func MakeIngesterClient(addr string, cfg Config, useStreamConnection bool) (Heal
 		inflightPushRequests:    ingesterClientInflightPushRequests,
 	}
 	if useStreamConnection {
+		if cfg.StreamFlowControl.Enabled {
+			c.flowControl = newStreamFlowController(cfg.StreamFlowControl, addr, INGESTER_CLIENT_STREAM_WORKER_COUNT)
+		}
+
 		streamCtx, streamCancel := context.WithCancel(context.Background())
 		err = c.Run(make(chan *streamWriteJob, INGESTER_CLIENT_STREAM_WORKER_COUNT), streamCtx, streamCancel)
 		if err != nil {
@@ -172,6 +185,9 @@ func MakeIngesterClient(addr string, cfg Config, useStreamConnection bool) (Heal
 
 func (c *closableHealthAndIngesterClient) Close() error {
 	c.inflightPushRequests.DeleteLabelValues(c.addr)
+	if c.flowControl != nil {
+		c.flowControl.deleteMetrics()
+	}
 
 	if c.streamCancel != nil {
 		c.streamCancel()
@@ -277,14 +293,19 @@ func (c *closableHealthAndIngesterClient) processJob(stream Ingester_PushStreamC
 type Config struct {
 	GRPCClientConfig        grpcclient.ConfigWithHealthCheck `yaml:"grpc_client_config"`
 	MaxInflightPushRequests int64                            `yaml:"max_inflight_push_requests"`
+	StreamFlowControl       StreamFlowControlConfig          `yaml:"stream_flow_control"`
 }
 
 // RegisterFlags registers configuration settings used by the ingester client config.
 func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	cfg.GRPCClientConfig.RegisterFlagsWithPrefix("ingester.client", snappyblock.Name, f)
 	f.Int64Var(&cfg.MaxInflightPushRequests, "ingester.client.max-inflight-push-requests", 0, "Max inflight push requests that this ingester client can handle. This limit is per-ingester-client. Additional requests will be rejected. 0 = unlimited.")
+	cfg.StreamFlowControl.RegisterFlagsWithPrefix("ingester.client.stream-flow-control.", f)
 }
 
 func (cfg *Config) Validate(log log.Logger) error {
+	if err := cfg.StreamFlowControl.Validate(); err != nil {
+		return err
+	}
 	return cfg.GRPCClientConfig.Validate(log)
 }
@@ -77,6 +77,21 @@ func (m *IngesterServerMock) MetricsForLabelMatchersStream(r *MetricsForLabelMat
 	return args.Error(0)
 }
 
+func (m *IngesterServerMock) TopSeries(ctx context.Context, r *TopSeriesRequest) (*TopSeriesResponse, error) {
+	args := m.Called(ctx, r)
+	return args.Get(0).(*TopSeriesResponse), args.Error(1)
+}
+
+func (m *IngesterServerMock) TransferChunks(srv Ingester_TransferChunksServer) error {
+	args := m.Called(srv)
+	return args.Error(0)
+}
+
+func (m *IngesterServerMock) TransferTSDB(srv Ingester_TransferTSDBServer) error {
+	args := m.Called(srv)
+	return args.Error(0)
+}
+
 func (m *IngesterServerMock) MetricsMetadata(ctx context.Context, r *MetricsMetadataRequest) (*MetricsMetadataResponse, error) {
 	args := m.Called(ctx, r)
 	return args.Get(0).(*MetricsMetadataResponse), args.Error(1)
@@ -0,0 +1,421 @@
+package client
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc/status"
+
+	"github.com/cortexproject/cortex/pkg/cortexpb"
+)
+
+const (
+	// Minimum interval between two adjustments of the concurrency and batch size of the pushes
+	// to an ingester, so that they don't swing on every response.
+	streamFlowControlAdjustInterval = 250 * time.Millisecond
+
+	// Factor by which the concurrency and the batch size are lowered while an ingester is highly
+	// loaded, and raised back once the load is below half of the high load threshold.
+	streamFlowControlDecreaseFactor = 0.75
+	streamFlowControlIncreaseFactor = 1.25
+)
+
+var (
+	errStreamPushShed = errors.New("ingester is overloaded, push shed by the ingester client")
+
+	errInvalidStreamFlowControlThresholds     = errors.New("invalid stream flow control thresholds, the high load threshold must be greater than 0 and lower than the shed load threshold")
+	errInvalidStreamFlowControlMinConcurrency = errors.New("invalid stream flow control min concurrency, it must be between 1 and the number of stream workers")
+	errInvalidStreamFlowControlBatchSize      = errors.New("invalid stream flow control batch size, the min batch size must be greater than 0 and lower than or equal to the max batch size")
+	errInvalidStreamFlowControlDuration       = errors.New("invalid stream flow control target append latency or max pacing delay, they must not be negative")
+)
+
+var (
+	ingesterClientStreamPushLoad = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "cortex",
+		Name:      "ingester_client_stream_push_load",
+		Help:      "Load of the ingester computed from the load hints reported on the push stream, where 1 means the ingester reached one of its limits.",
+	}, []string{"ingester"})
+	ingesterClientStreamPushConcurrency = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "cortex",
+		Name:      "ingester_client_stream_push_concurrency",
+		Help:      "Maximum number of concurrent pushes sent to the ingester on the push stream.",
+	}, []string{"ingester"})
+	ingesterClientStreamPushBatchSize = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "cortex",
+		Name:      "ingester_client_stream_push_batch_size",
+		Help:      "Maximum number of series sent to the ingester in a single push stream message.",
+	}, []string{"ingester"})
+	ingesterClientStreamPushShed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "cortex",
+		Name:      "ingester_client_stream_push_shed_total",
+		Help:      "Total number of pushes shed by the ingester client because the ingester is overloaded.",
+	}, []string{"ingester"})
+)
+
+// StreamFlowControlConfig configures how the pushes sent on the push stream adapt to the load
+// hints reported by the ingesters.
+type StreamFlowControlConfig struct {
+	Enabled             bool          `yaml:"enabled"`
+	TargetAppendLatency time.Duration `yaml:"target_append_latency"`
+	HighLoadThreshold   float64       `yaml:"high_load_threshold"`
+	ShedLoadThreshold   float64       `yaml:"shed_load_threshold"`
+	MinConcurrency      int           `yaml:"min_concurrency"`
+	MinBatchSize        int           `yaml:"min_batch_size"`
+	MaxBatchSize        int           `yaml:"max_batch_size"`
+	MaxPacingDelay      time.Duration `yaml:"max_pacing_delay"`
+}
+
+// RegisterFlagsWithPrefix registers the flags of the stream flow control with the given prefix.
+func (cfg *StreamFlowControlConfig) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, prefix+"enabled", false, "EXPERIMENTAL: If enabled, the concurrency, the batch size and the pacing of the pushes sent to each ingester on the push stream adapt to the load hints reported by the ingester, and pushes are shed before the ingester limits are reached. Only used when -distributor.use-stream-push is enabled.")
+	f.DurationVar(&cfg.TargetAppendLatency, prefix+"target-append-latency", time.Second, "EXPERIMENTAL: 99th percentile of the ingester append latency at which the ingester is considered fully loaded. 0 to ignore the append latency.")
+	f.Float64Var(&cfg.HighLoadThreshold, prefix+"high-load-threshold", 0.8, "EXPERIMENTAL: Load of the ingester, as a fraction of its instance limits or target append latency, above which the concurrency and batch size of the pushes are lowered and the pushes are paced.")
+	f.Float64Var(&cfg.ShedLoadThreshold, prefix+"shed-load-threshold", 0.95, "EXPERIMENTAL: Load of the ingester above which the pushes exceeding the lowered concurrency are shed instead of waiting.")
+	f.IntVar(&cfg.MinConcurrency, prefix+"min-concurrency", 4, "EXPERIMENTAL: Minimum number of concurrent pushes sent to an ingester on the push stream.")
+	f.IntVar(&cfg.MinBatchSize, prefix+"min-batch-size", 100, "EXPERIMENTAL: Minimum number of series sent to an ingester in a single push stream message.")
+	f.IntVar(&cfg.MaxBatchSize, prefix+"max-batch-size", 1000, "EXPERIMENTAL: Maximum number of series sent to an ingester in a single push stream message while the ingester is under pressure. Pushes are only split, and their batches sent concurrently, while the ingester is highly loaded or until the batch size is raised back to this value.")
+	f.DurationVar(&cfg.MaxPacingDelay, prefix+"max-pacing-delay", 100*time.Millisecond, "EXPERIMENTAL: Maximum delay added before sending a push to a highly loaded ingester. The delay grows with the load, from 0 at the high load threshold up to this value at the shed load threshold.")
+}
+
+// Validate the config and returns an error on failure.
+func (cfg *StreamFlowControlConfig) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.HighLoadThreshold <= 0 || cfg.HighLoadThreshold >= cfg.ShedLoadThreshold {
+		return errInvalidStreamFlowControlThresholds
+	}
+	if cfg.MinConcurrency < 1 || cfg.MinConcurrency > INGESTER_CLIENT_STREAM_WORKER_COUNT {
+		return errInvalidStreamFlowControlMinConcurrency
+	}
+	if cfg.MinBatchSize <= 0 || cfg.MinBatchSize > cfg.MaxBatchSize {
+		return errInvalidStreamFlowControlBatchSize
+	}
+	if cfg.TargetAppendLatency < 0 || cfg.MaxPacingDelay < 0 {
+		return errInvalidStreamFlowControlDuration
+	}
+	return nil
+}
+
+// streamFlowController adapts the pushes sent to an ingester on the push stream to the load
+// hints reported by the ingester. While the ingester is highly loaded, the number of concurrent
+// pushes and the number of series per push are lowered, and each push is delayed in proportion
+// to the load. Once the load reaches the shed threshold, the pushes exceeding the lowered
+// concurrency fail right away instead of piling up on the ingester, so that the distributor
+// can rely on the other replicas. The concurrency and batch size are raised back gradually
+// once the load is gone.
+type streamFlowController struct {
+	cfg            StreamFlowControlConfig
+	addr           string
+	maxConcurrency int
+
+	mtx         sync.Mutex
+	load        float64
+	concurrency int
+	batchSize   int
+	inflight    int
+	lastAdjust  time.Time
+	// Closed and replaced each time a slot is released or the concurrency is raised.
+	slotReleased chan struct{}
+}
+
+func newStreamFlowController(cfg StreamFlowControlConfig, addr string, maxConcurrency int) *streamFlowController {
+	c := &streamFlowController{
+		cfg:            cfg,
+		addr:           addr,
+		maxConcurrency: maxConcurrency,
+		concurrency:    maxConcurrency,
+		batchSize:      cfg.MaxBatchSize,
+		slotReleased:   make(chan struct{}),
+	}
+	c.updateMetrics()
+	return c
+}
+
+// acquire waits for a push slot, and for the pacing delay when the ingester is highly loaded.
+// It fails right away if the ingester is overloaded and no slot is available.
+func (c *streamFlowController) acquire(ctx context.Context) error {
+	for {
+		c.mtx.Lock()
+		if c.inflight < c.concurrency {
+			c.inflight++
+			delay := c.pacingDelay()
+			c.mtx.Unlock()
+
+			if delay <= 0 {
+				return nil
+			}
+			select {
+			case <-time.After(delay):
+				return nil
+			case <-ctx.Done():
+				c.release()
+				return ctx.Err()
+			}
+		}
+
+		if c.load >= c.cfg.ShedLoadThreshold {
+			c.mtx.Unlock()
+			ingesterClientStreamPushShed.WithLabelValues(c.addr).Inc()
+			return errStreamPushShed
+		}
+
+		released := c.slotReleased
+		c.mtx.Unlock()
+
+		select {
+		case <-released:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (c *streamFlowController) release() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.inflight--
+	c.notifyWaiters()
+}
+
+// pacingDelay must be called with the mutex held.
+func (c *streamFlowController) pacingDelay() time.Duration {
+	if c.load <= c.cfg.HighLoadThreshold {
+		return 0
+	}
+	overload := min(1, (c.load-c.cfg.HighLoadThreshold)/(c.cfg.ShedLoadThreshold-c.cfg.HighLoadThreshold))
+	return time.Duration(overload * float64(c.cfg.MaxPacingDelay))
+}
+
+// notifyWaiters must be called with the mutex held.
+func (c *streamFlowController) notifyWaiters() {
+	close(c.slotReleased)
+	c.slotReleased = make(chan struct{})
+}
+
+// getBatchSize returns the number of series to send in a single push, or 0 if the pushes don't
+// need to be split because the ingester is not under pressure.
+func (c *streamFlowController) getBatchSize() int {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if c.load <= c.cfg.HighLoadThreshold && c.batchSize >= c.cfg.MaxBatchSize {
+		return 0
+	}
+	return c.batchSize
+}
+
+// update records the load hints reported by the ingester, and adjusts the concurrency and the
+// batch size at most once per adjust interval.
+func (c *streamFlowController) update(hints *cortexpb.LoadHints, now time.Time) {
+	if hints == nil {
+		return
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.load = ingesterLoad(hints, c.cfg.TargetAppendLatency)
+	if now.Sub(c.lastAdjust) < streamFlowControlAdjustInterval {
+		return
+	}
+	c.lastAdjust = now
+
+	switch {
+	case c.load > c.cfg.HighLoadThreshold:
+		c.concurrency = max(c.cfg.MinConcurrency, int(float64(c.concurrency)*streamFlowControlDecreaseFactor))
+		c.batchSize = max(c.cfg.MinBatchSize, int(float64(c.batchSize)*streamFlowControlDecreaseFactor))
+	case c.load < c.cfg.HighLoadThreshold/2:
+		concurrency := min(c.maxConcurrency, max(c.concurrency+1, int(float64(c.concurrency)*streamFlowControlIncreaseFactor)))
+		if concurrency > c.concurrency {
+			c.concurrency = concurrency
+			c.notifyWaiters()
+		}
+		c.batchSize = min(c.cfg.MaxBatchSize, max(c.batchSize+1, int(float64(c.batchSize)*streamFlowControlIncreaseFactor)))
+	}
+	c.updateMetrics()
+}
+
+func (c *streamFlowController) updateMetrics() {
+	ingesterClientStreamPushLoad.WithLabelValues(c.addr).Set(c.load)
+	ingesterClientStreamPushConcurrency.WithLabelValues(c.addr).Set(float64(c.concurrency))
+	ingesterClientStreamPushBatchSize.WithLabelValues(c.addr).Set(float64(c.batchSize))
+}
+
+func (c *streamFlowController) deleteMetrics() {
+	ingesterClientStreamPushLoad.DeleteLabelValues(c.addr)
+	ingesterClientStreamPushConcurrency.DeleteLabelValues(c.addr)
+	ingesterClientStreamPushBatchSize.DeleteLabelValues(c.addr)
+	ingesterClientStreamPushShed.DeleteLabelValues(c.addr)
+}
+
+// ingesterLoad returns how close the ingester is to the closest of its limits, where 1 means
+// the limit is reached.
+func ingesterLoad(hints *cortexpb.LoadHints, targetAppendLatency time.Duration) float64 {
+	load := 0.0
+	if hints.MaxInflightPushRequests > 0 {
+		load = max(load, float64(hints.InflightPushRequests)/float64(hints.MaxInflightPushRequests))
+	}
+	if hints.MaxInMemorySeries > 0 {
+		load = max(load, float64(hints.InMemorySeries)/float64(hints.MaxInMemorySeries))
+	}
+	if hints.MaxIngestionRate > 0 {
+		load = max(load, hints.IngestionRate/hints.MaxIngestionRate)
+	}
+	if targetAppendLatency > 0 {
+		load = max(load, hints.AppendLatencyP99/targetAppendLatency.Seconds())
+	}
+	return load
+}
+
+// splitWriteRequest splits the request in requests of at most batchSize series. The metadata
+// is sent along with the first request.
+func splitWriteRequest(req *cortexpb.WriteRequest, batchSize int) []*cortexpb.WriteRequest {
+	if batchSize <= 0 || len(req.Timeseries) <= batchSize {
+		return []*cortexpb.WriteRequest{req}
+	}
+
+	batches := make([]*cortexpb.WriteRequest, 0, (len(req.Timeseries)+batchSize-1)/batchSize)
+	for start := 0; start < len(req.Timeseries); start += batchSize {
+		batch := &cortexpb.WriteRequest{
+			Timeseries:              req.Timeseries[start:min(start+batchSize, len(req.Timeseries))],
+			Source:                  req.Source,
+			SkipLabelNameValidation: req.SkipLabelNameValidation,
+			DiscardOutOfOrder:       req.DiscardOutOfOrder,
+		}
+		if start == 0 {
+			batch.Metadata = req.Metadata
+		}
+		batches = append(batches, batch)
+	}
+	return batches
+}
+
+// PartialPushError is returned by a push sent on the push stream when the push was split in
+// batches and only some of them were accepted by the ingester. The series of the accepted
+// batches are written, so a retry should only send the Remaining ones.
+type PartialPushError struct {
+	err       error
+	accepted  int
+	remaining *cortexpb.WriteRequest
+}
+
+func (e *PartialPushError) Error() string {
+	return fmt.Sprintf("push partially accepted by the ingester, %d series not written: %s", len(e.remaining.Timeseries), e.err.Error())
+}
+
+func (e *PartialPushError) Unwrap() error {
+	return e.err
+}
+
+// GRPCStatus returns the status of the wrapped error, so that the status code of the failed
+// batches is preserved.
+func (e *PartialPushError) GRPCStatus() *status.Status {
+	s, _ := status.FromError(e.err)
+	return s
+}
+
+// Accepted returns the number of series accepted by the ingester.
+func (e *PartialPushError) Accepted() int {
+	return e.accepted
+}
+
+// Remaining returns the request with the series and the metadata of the batches which were
+// not accepted by the ingester.
+func (e *PartialPushError) Remaining() *cortexpb.WriteRequest {
+	return e.remaining
+}
+
+// pushWithFlowControl sends the request on the push stream under the control of the flow
+// controller. While the ingester is under pressure, the request is split in batches of the
+// current batch size, sent concurrently within the slots allowed by the flow controller. The
+// written counts of the responses are summed, and if some batches failed while others were
+// accepted, a *PartialPushError wrapping the first error is returned.
+func (c *closableHealthAndIngesterClient) pushWithFlowControl(ctx context.Context, tenantID string, in *cortexpb.WriteRequest) (*cortexpb.WriteResponse, error) {
+	batches := splitWriteRequest(in, c.flowControl.getBatchSize())
+	if len(batches) == 1 {
+		return c.pushBatchWithFlowControl(ctx, tenantID, in)
+	}
+
+	var (
+		wg    sync.WaitGroup
+		resps = make([]*cortexpb.WriteResponse, len(batches))
+		errs  = make([]error, len(batches))
+	)
+	for idx, batch := range batches {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resps[idx], errs[idx] = c.pushBatchWithFlowControl(ctx, tenantID, batch)
+		}()
+	}
+	wg.Wait()
+
+	return mergeBatchResponses(in, batches, resps, errs)
+}
+
+func (c *closableHealthAndIngesterClient) pushBatchWithFlowControl(ctx context.Context, tenantID string, batch *cortexpb.WriteRequest) (*cortexpb.WriteResponse, error) {
+	if err := c.flowControl.acquire(ctx); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.sendStreamJob(ctx, &cortexpb.StreamWriteRequest{TenantID: tenantID, Request: batch})
+	c.flowControl.release()
+
+	if resp != nil {
+		c.flowControl.update(resp.LoadHints, time.Now())
+	}
+	return resp, err
+}
+
+// mergeBatchResponses merges the responses of the batches the request was split in. The
+// returned error is nil if all batches were accepted, the first error if none was, and a
+// *PartialPushError otherwise.
+func mergeBatchResponses(req *cortexpb.WriteRequest, batches []*cortexpb.WriteRequest, resps []*cortexpb.WriteResponse, errs []error) (*cortexpb.WriteResponse, error) {
+	var (
+		merged    *cortexpb.WriteResponse
+		firstErr  error
+		accepted  int
+		remaining = &cortexpb.WriteRequest{
+			Source:                  req.Source,
+			SkipLabelNameValidation: req.SkipLabelNameValidation,
+			DiscardOutOfOrder:       req.DiscardOutOfOrder,
+		}
+	)
+
+	for idx, batch := range batches {
+		if resp := resps[idx]; resp != nil {
+			if merged == nil {
+				merged = resp
+			} else {
+				merged.Samples += resp.Samples
+				merged.Histograms += resp.Histograms
+				merged.Exemplars += resp.Exemplars
+				merged.CpuUtilization = max(merged.CpuUtilization, resp.CpuUtilization)
+				merged.HeapUtilization = max(merged.HeapUtilization, resp.HeapUtilization)
+			}
+		}
+
+		if err := errs[idx]; err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			remaining.Timeseries = append(remaining.Timeseries, batch.Timeseries...)
+			remaining.Metadata = append(remaining.Metadata, batch.Metadata...)
+			continue
+		}
+		accepted += len(batch.Timeseries)
+	}
+
+	if firstErr == nil || accepted == 0 && len(remaining.Metadata) == len(req.Metadata) {
+		return merged, firstErr
+	}
+	return merged, &PartialPushError{err: firstErr, accepted: accepted, remaining: remaining}
+}
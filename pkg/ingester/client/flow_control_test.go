@@ -0,0 +1,363 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/httpgrpc"
+	"go.uber.org/atomic"
+
+	"github.com/cortexproject/cortex/pkg/cortexpb"
+)
+
+func TestStreamFlowControlConfig_Validate(t *testing.T) {
+	tests := map[string]struct {
+		setup    func(cfg *StreamFlowControlConfig)
+		expected error
+	}{
+		"should pass with defaults": {
+			setup: func(cfg *StreamFlowControlConfig) {},
+		},
+		"should not validate when disabled": {
+			setup: func(cfg *StreamFlowControlConfig) {
+				cfg.Enabled = false
+				cfg.MinBatchSize = 0
+			},
+		},
+		"should fail if the high load threshold is not lower than the shed load threshold": {
+			setup: func(cfg *StreamFlowControlConfig) {
+				cfg.HighLoadThreshold = 0.95
+			},
+			expected: errInvalidStreamFlowControlThresholds,
+		},
+		"should fail if the min concurrency is greater than the number of workers": {
+			setup: func(cfg *StreamFlowControlConfig) {
+				cfg.MinConcurrency = INGESTER_CLIENT_STREAM_WORKER_COUNT + 1
+			},
+			expected: errInvalidStreamFlowControlMinConcurrency,
+		},
+		"should fail if the min batch size is greater than the max batch size": {
+			setup: func(cfg *StreamFlowControlConfig) {
+				cfg.MinBatchSize = cfg.MaxBatchSize + 1
+			},
+			expected: errInvalidStreamFlowControlBatchSize,
+		},
+		"should fail if the max pacing delay is negative": {
+			setup: func(cfg *StreamFlowControlConfig) {
+				cfg.MaxPacingDelay = -time.Second
+			},
+			expected: errInvalidStreamFlowControlDuration,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := defaultStreamFlowControlConfig()
+			testData.setup(&cfg)
+			assert.Equal(t, testData.expected, cfg.Validate())
+		})
+	}
+}
+
+func TestIngesterLoad(t *testing.T) {
+	hints := &cortexpb.LoadHints{
+		InflightPushRequests:    10,
+		MaxInflightPushRequests: 100,
+		InMemorySeries:          500,
+		MaxInMemorySeries:       1000,
+		IngestionRate:           100,
+		MaxIngestionRate:        0,
+		AppendLatencyP99:        0.25,
+	}
+
+	assert.InDelta(t, 0.5, ingesterLoad(hints, time.Second), 1e-9)
+	assert.InDelta(t, 0.5, ingesterLoad(hints, 0), 1e-9)
+	assert.InDelta(t, 2.5, ingesterLoad(hints, 100*time.Millisecond), 1e-9)
+	assert.Equal(t, 0.0, ingesterLoad(&cortexpb.LoadHints{}, time.Second))
+}
+
+func TestSplitWriteRequest(t *testing.T) {
+	req := &cortexpb.WriteRequest{
+		Timeseries:              make([]cortexpb.PreallocTimeseries, 5),
+		Metadata:                []*cortexpb.MetricMetadata{{MetricFamilyName: "test"}},
+		Source:                  cortexpb.RULE,
+		SkipLabelNameValidation: true,
+		DiscardOutOfOrder:       true,
+	}
+
+	assert.Equal(t, []*cortexpb.WriteRequest{req}, splitWriteRequest(req, 5))
+	assert.Equal(t, []*cortexpb.WriteRequest{req}, splitWriteRequest(req, 0))
+
+	batches := splitWriteRequest(req, 2)
+	require.Len(t, batches, 3)
+	for i, batch := range batches {
+		assert.Equal(t, cortexpb.RULE, batch.Source)
+		assert.True(t, batch.SkipLabelNameValidation)
+		assert.True(t, batch.DiscardOutOfOrder)
+		if i == 0 {
+			assert.Equal(t, req.Metadata, batch.Metadata)
+		} else {
+			assert.Empty(t, batch.Metadata)
+		}
+	}
+	assert.Len(t, batches[0].Timeseries, 2)
+	assert.Len(t, batches[1].Timeseries, 2)
+	assert.Len(t, batches[2].Timeseries, 1)
+}
+
+func TestStreamFlowController_ShouldAdaptToLoadHints(t *testing.T) {
+	cfg := defaultStreamFlowControlConfig()
+	c := newStreamFlowController(cfg, "test-adapt", 16)
+	defer c.deleteMetrics()
+
+	highLoad := &cortexpb.LoadHints{InflightPushRequests: 90, MaxInflightPushRequests: 100}
+	lowLoad := &cortexpb.LoadHints{InflightPushRequests: 10, MaxInflightPushRequests: 100}
+	now := time.Now()
+
+	// The concurrency and batch size are lowered while the ingester is highly loaded, down to the minimums.
+	for i := 0; i < 20; i++ {
+		now = now.Add(streamFlowControlAdjustInterval)
+		c.update(highLoad, now)
+	}
+	assert.Equal(t, cfg.MinConcurrency, c.concurrency)
+	assert.Equal(t, cfg.MinBatchSize, c.getBatchSize())
+
+	// Updates within the adjust interval only record the load.
+	c.update(lowLoad, now)
+	assert.Equal(t, cfg.MinConcurrency, c.concurrency)
+	assert.InDelta(t, 0.1, c.load, 1e-9)
+
+	// The concurrency and batch size are raised back once the load is gone, up to the maximums.
+	for i := 0; i < 50; i++ {
+		now = now.Add(streamFlowControlAdjustInterval)
+		c.update(lowLoad, now)
+	}
+	assert.Equal(t, 16, c.concurrency)
+	assert.Equal(t, cfg.MaxBatchSize, c.batchSize)
+
+	// Once the batch size is raised back and the ingester is not under pressure, pushes are not split.
+	assert.Equal(t, 0, c.getBatchSize())
+}
+
+func TestStreamFlowController_ShouldShedWhenOverloaded(t *testing.T) {
+	cfg := defaultStreamFlowControlConfig()
+	cfg.MaxPacingDelay = 0
+	cfg.MinConcurrency = 1
+	c := newStreamFlowController(cfg, "test-shed", 1)
+	defer c.deleteMetrics()
+
+	c.update(&cortexpb.LoadHints{InflightPushRequests: 99, MaxInflightPushRequests: 100}, time.Now())
+
+	// A slot is still available, so the push goes through.
+	require.NoError(t, c.acquire(context.Background()))
+
+	// No slot is available and the ingester is overloaded, so the push is shed.
+	assert.ErrorIs(t, c.acquire(context.Background()), errStreamPushShed)
+
+	// Once the slot is released, pushes go through again.
+	c.release()
+	require.NoError(t, c.acquire(context.Background()))
+	c.release()
+}
+
+func TestStreamFlowController_ShouldWaitForSlotWhenHighlyLoaded(t *testing.T) {
+	cfg := defaultStreamFlowControlConfig()
+	cfg.MaxPacingDelay = 0
+	cfg.MinConcurrency = 1
+	c := newStreamFlowController(cfg, "test-wait", 1)
+	defer c.deleteMetrics()
+
+	c.update(&cortexpb.LoadHints{InflightPushRequests: 85, MaxInflightPushRequests: 100}, time.Now())
+	require.NoError(t, c.acquire(context.Background()))
+
+	// The ingester is not overloaded, so the push waits for a slot instead of being shed.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, c.acquire(ctx), context.DeadlineExceeded)
+
+	acquired := make(chan error)
+	go func() {
+		acquired <- c.acquire(context.Background())
+	}()
+	c.release()
+	require.NoError(t, <-acquired)
+	c.release()
+}
+
+func TestStreamFlowController_ShouldPaceHighlyLoadedIngester(t *testing.T) {
+	cfg := defaultStreamFlowControlConfig()
+	cfg.MaxPacingDelay = 100 * time.Millisecond
+	c := newStreamFlowController(cfg, "test-pace", 16)
+	defer c.deleteMetrics()
+
+	c.mtx.Lock()
+	assert.Equal(t, time.Duration(0), c.pacingDelay())
+	c.load = (cfg.HighLoadThreshold + cfg.ShedLoadThreshold) / 2
+	assert.InDelta(t, float64(50*time.Millisecond), float64(c.pacingDelay()), float64(time.Millisecond))
+	c.load = 2
+	assert.Equal(t, cfg.MaxPacingDelay, c.pacingDelay())
+	c.mtx.Unlock()
+}
+
+func TestPushWithFlowControl_ShouldNotSplitWithoutPressure(t *testing.T) {
+	cfg := defaultStreamFlowControlConfig()
+	cfg.MaxBatchSize = 2
+	cfg.MinBatchSize = 1
+
+	var received atomic.Int32
+	c := newFlowControlTestClient(t, cfg, "test-no-split", func(req *cortexpb.WriteRequest) (*cortexpb.WriteResponse, error) {
+		received.Inc()
+		return &cortexpb.WriteResponse{Samples: int64(len(req.Timeseries))}, nil
+	})
+
+	resp, err := c.pushWithFlowControl(context.Background(), "user", flowControlTestRequest(5))
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), resp.Samples)
+	assert.Equal(t, int32(1), received.Load())
+}
+
+func TestPushWithFlowControl_ShouldSendBatchesConcurrentlyUnderPressure(t *testing.T) {
+	cfg := defaultStreamFlowControlConfig()
+	cfg.MaxPacingDelay = 0
+	cfg.MaxBatchSize = 2
+	cfg.MinBatchSize = 1
+
+	// Each batch is held until all of them are received, so the push only completes if the
+	// batches are sent concurrently.
+	var (
+		received    atomic.Int32
+		allReceived = make(chan struct{})
+	)
+	c := newFlowControlTestClient(t, cfg, "test-concurrent", func(req *cortexpb.WriteRequest) (*cortexpb.WriteResponse, error) {
+		if received.Inc() == 3 {
+			close(allReceived)
+		}
+		select {
+		case <-allReceived:
+		case <-time.After(5 * time.Second):
+			return nil, errors.New("batches not sent concurrently")
+		}
+		return &cortexpb.WriteResponse{Samples: int64(len(req.Timeseries))}, nil
+	})
+	// The ingester is highly loaded, but the batch size is not lowered yet.
+	c.flowControl.load = 0.85
+
+	resp, err := c.pushWithFlowControl(context.Background(), "user", flowControlTestRequest(5))
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), resp.Samples)
+	assert.Equal(t, int32(3), received.Load())
+}
+
+func TestPushWithFlowControl_ShouldReportTheAcceptedBatchesOnPartialFailure(t *testing.T) {
+	cfg := defaultStreamFlowControlConfig()
+	cfg.MaxPacingDelay = 0
+	cfg.MaxBatchSize = 2
+	cfg.MinBatchSize = 1
+
+	pushErr := httpgrpc.Errorf(http.StatusServiceUnavailable, "unavailable")
+	c := newFlowControlTestClient(t, cfg, "test-partial", func(req *cortexpb.WriteRequest) (*cortexpb.WriteResponse, error) {
+		// The batch with the metadata and the last batch fail.
+		if len(req.Metadata) > 0 || req.Timeseries[0].Labels[0].Value == "series-4" {
+			return nil, pushErr
+		}
+		return &cortexpb.WriteResponse{Samples: int64(len(req.Timeseries))}, nil
+	})
+	// The ingester is highly loaded, but the batch size is not lowered yet.
+	c.flowControl.load = 0.85
+
+	req := flowControlTestRequest(5)
+	resp, err := c.pushWithFlowControl(context.Background(), "user", req)
+	assert.Equal(t, int64(2), resp.Samples)
+
+	var partialErr *PartialPushError
+	require.ErrorAs(t, err, &partialErr)
+	assert.ErrorIs(t, err, pushErr)
+	assert.Equal(t, 2, partialErr.Accepted())
+
+	// A retry only sends the series and the metadata which were not accepted.
+	remaining := partialErr.Remaining()
+	assert.Equal(t, []cortexpb.PreallocTimeseries{req.Timeseries[0], req.Timeseries[1], req.Timeseries[4]}, remaining.Timeseries)
+	assert.Equal(t, req.Metadata, remaining.Metadata)
+	assert.Equal(t, req.Source, remaining.Source)
+
+	httpResp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	assert.Equal(t, int32(http.StatusServiceUnavailable), httpResp.Code)
+}
+
+func TestPushWithFlowControl_ShouldReturnTheErrorAsIsWhenNothingIsAccepted(t *testing.T) {
+	cfg := defaultStreamFlowControlConfig()
+	cfg.MaxPacingDelay = 0
+	cfg.MaxBatchSize = 2
+	cfg.MinBatchSize = 1
+
+	pushErr := errors.New("push failed")
+	c := newFlowControlTestClient(t, cfg, "test-failed", func(req *cortexpb.WriteRequest) (*cortexpb.WriteResponse, error) {
+		return nil, pushErr
+	})
+	// The ingester is highly loaded, but the batch size is not lowered yet.
+	c.flowControl.load = 0.85
+
+	_, err := c.pushWithFlowControl(context.Background(), "user", flowControlTestRequest(5))
+	assert.Equal(t, pushErr, err)
+}
+
+// newFlowControlTestClient returns a client whose stream worker hands each push to the handler
+// in its own goroutine.
+func newFlowControlTestClient(t *testing.T, cfg StreamFlowControlConfig, addr string, handler func(*cortexpb.WriteRequest) (*cortexpb.WriteResponse, error)) *closableHealthAndIngesterClient {
+	c := &closableHealthAndIngesterClient{
+		addr:           addr,
+		streamPushChan: make(chan *streamWriteJob),
+		flowControl:    newStreamFlowController(cfg, addr, INGESTER_CLIENT_STREAM_WORKER_COUNT),
+	}
+	t.Cleanup(c.flowControl.deleteMetrics)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case job := <-c.streamPushChan:
+				go func() {
+					defer close(job.sendDone)
+					job.resp, job.err = handler(job.req.Request)
+				}()
+			}
+		}
+	}()
+	return c
+}
+
+func flowControlTestRequest(numSeries int) *cortexpb.WriteRequest {
+	req := &cortexpb.WriteRequest{
+		Metadata: []*cortexpb.MetricMetadata{{MetricFamilyName: "test"}},
+		Source:   cortexpb.API,
+	}
+	for i := 0; i < numSeries; i++ {
+		req.Timeseries = append(req.Timeseries, cortexpb.PreallocTimeseries{TimeSeries: &cortexpb.TimeSeries{
+			Labels: []cortexpb.LabelAdapter{{Name: "series", Value: fmt.Sprintf("series-%d", i)}},
+		}})
+	}
+	return req
+}
+
+func defaultStreamFlowControlConfig() StreamFlowControlConfig {
+	return StreamFlowControlConfig{
+		Enabled:             true,
+		TargetAppendLatency: time.Second,
+		HighLoadThreshold:   0.8,
+		ShedLoadThreshold:   0.95,
+		MinConcurrency:      4,
+		MinBatchSize:        100,
+		MaxBatchSize:        1000,
+		MaxPacingDelay:      100 * time.Millisecond,
+	}
+}
@@ -150,7 +150,8 @@ func (m *ReadResponse) GetResults() []*QueryResponse {
 }
 
 type QueryResponse struct {
-	Timeseries []cortexpb.TimeSeries `protobuf:"bytes,1,rep,name=timeseries,proto3" json:"timeseries"`
+	Timeseries []cortexpb.TimeSeries      `protobuf:"bytes,1,rep,name=timeseries,proto3" json:"timeseries"`
+	Metadata   []*cortexpb.MetricMetadata `protobuf:"bytes,2,rep,name=metadata,proto3" json:"metadata,omitempty"`
 }
 
 func (m *QueryResponse) Reset()      { *m = QueryResponse{} }
@@ -192,6 +193,13 @@ func (m *QueryResponse) GetTimeseries() []cortexpb.TimeSeries {
 	return nil
 }
 
+func (m *QueryResponse) GetMetadata() []*cortexpb.MetricMetadata {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
 type QueryRequest struct {
 	StartTimestampMs                                               int64           `protobuf:"varint,1,opt,name=start_timestamp_ms,json=startTimestampMs,proto3" json:"start_timestamp_ms,omitempty"`
 	EndTimestampMs                                                 int64           `protobuf:"varint,2,opt,name=end_timestamp_ms,json=endTimestampMs,proto3" json:"end_timestamp_ms,omitempty"`
@@ -754,6 +762,7 @@ type UserStatsResponse struct {
 	RuleIngestionRate float64 `protobuf:"fixed64,4,opt,name=rule_ingestion_rate,json=ruleIngestionRate,proto3" json:"rule_ingestion_rate,omitempty"`
 	ActiveSeries      uint64  `protobuf:"varint,5,opt,name=active_series,json=activeSeries,proto3" json:"active_series,omitempty"`
 	LoadedBlocks      uint64  `protobuf:"varint,6,opt,name=loaded_blocks,json=loadedBlocks,proto3" json:"loaded_blocks,omitempty"`
+	SeriesChurn       uint64  `protobuf:"varint,7,opt,name=series_churn,json=seriesChurn,proto3" json:"series_churn,omitempty"`
 }
 
 func (m *UserStatsResponse) Reset()      { *m = UserStatsResponse{} }
@@ -830,6 +839,13 @@ func (m *UserStatsResponse) GetLoadedBlocks() uint64 {
 	return 0
 }
 
+func (m *UserStatsResponse) GetSeriesChurn() uint64 {
+	if m != nil {
+		return m.SeriesChurn
+	}
+	return 0
+}
+
 type UserIDStatsResponse struct {
 	UserId string             `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
 	Data   *UserStatsResponse `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
@@ -1475,6 +1491,301 @@ func (m *TimeSeriesFile) GetData() []byte {
 	return nil
 }
 
+type TopSeriesRequest struct {
+	Limit uint32 `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+}
+
+func (m *TopSeriesRequest) Reset()      { *m = TopSeriesRequest{} }
+func (*TopSeriesRequest) ProtoMessage() {}
+func (*TopSeriesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f6df4f3586b478, []int{27}
+}
+func (m *TopSeriesRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *TopSeriesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_TopSeriesRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *TopSeriesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TopSeriesRequest.Merge(m, src)
+}
+func (m *TopSeriesRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *TopSeriesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_TopSeriesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TopSeriesRequest proto.InternalMessageInfo
+
+func (m *TopSeriesRequest) GetLimit() uint32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type TopSeriesResponse struct {
+	ActiveMetricNames []TopSeriesEntry `protobuf:"bytes,1,rep,name=active_metric_names,json=activeMetricNames,proto3" json:"active_metric_names"`
+	ActiveLabelPairs  []TopSeriesEntry `protobuf:"bytes,2,rep,name=active_label_pairs,json=activeLabelPairs,proto3" json:"active_label_pairs"`
+	ChurnMetricNames  []TopSeriesEntry `protobuf:"bytes,3,rep,name=churn_metric_names,json=churnMetricNames,proto3" json:"churn_metric_names"`
+	ChurnLabelPairs   []TopSeriesEntry `protobuf:"bytes,4,rep,name=churn_label_pairs,json=churnLabelPairs,proto3" json:"churn_label_pairs"`
+}
+
+func (m *TopSeriesResponse) Reset()      { *m = TopSeriesResponse{} }
+func (*TopSeriesResponse) ProtoMessage() {}
+func (*TopSeriesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f6df4f3586b478, []int{28}
+}
+func (m *TopSeriesResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *TopSeriesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_TopSeriesResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *TopSeriesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TopSeriesResponse.Merge(m, src)
+}
+func (m *TopSeriesResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *TopSeriesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_TopSeriesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TopSeriesResponse proto.InternalMessageInfo
+
+func (m *TopSeriesResponse) GetActiveMetricNames() []TopSeriesEntry {
+	if m != nil {
+		return m.ActiveMetricNames
+	}
+	return nil
+}
+
+func (m *TopSeriesResponse) GetActiveLabelPairs() []TopSeriesEntry {
+	if m != nil {
+		return m.ActiveLabelPairs
+	}
+	return nil
+}
+
+func (m *TopSeriesResponse) GetChurnMetricNames() []TopSeriesEntry {
+	if m != nil {
+		return m.ChurnMetricNames
+	}
+	return nil
+}
+
+func (m *TopSeriesResponse) GetChurnLabelPairs() []TopSeriesEntry {
+	if m != nil {
+		return m.ChurnLabelPairs
+	}
+	return nil
+}
+
+type TopSeriesEntry struct {
+	Name  string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Value string `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	Count uint64 `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
+}
+
+func (m *TopSeriesEntry) Reset()      { *m = TopSeriesEntry{} }
+func (*TopSeriesEntry) ProtoMessage() {}
+func (*TopSeriesEntry) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f6df4f3586b478, []int{29}
+}
+func (m *TopSeriesEntry) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *TopSeriesEntry) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_TopSeriesEntry.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *TopSeriesEntry) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TopSeriesEntry.Merge(m, src)
+}
+func (m *TopSeriesEntry) XXX_Size() int {
+	return m.Size()
+}
+func (m *TopSeriesEntry) XXX_DiscardUnknown() {
+	xxx_messageInfo_TopSeriesEntry.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TopSeriesEntry proto.InternalMessageInfo
+
+func (m *TopSeriesEntry) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *TopSeriesEntry) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+func (m *TopSeriesEntry) GetCount() uint64 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+type TransferChunksResponse struct {
+	Series           uint64 `protobuf:"varint,1,opt,name=series,proto3" json:"series,omitempty"`
+	Samples          uint64 `protobuf:"varint,2,opt,name=samples,proto3" json:"samples,omitempty"`
+	Histograms       uint64 `protobuf:"varint,3,opt,name=histograms,proto3" json:"histograms,omitempty"`
+	FailedSamples    uint64 `protobuf:"varint,4,opt,name=failed_samples,json=failedSamples,proto3" json:"failed_samples,omitempty"`
+	FailedHistograms uint64 `protobuf:"varint,5,opt,name=failed_histograms,json=failedHistograms,proto3" json:"failed_histograms,omitempty"`
+}
+
+func (m *TransferChunksResponse) Reset()      { *m = TransferChunksResponse{} }
+func (*TransferChunksResponse) ProtoMessage() {}
+func (*TransferChunksResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f6df4f3586b478, []int{30}
+}
+func (m *TransferChunksResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *TransferChunksResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_TransferChunksResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *TransferChunksResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TransferChunksResponse.Merge(m, src)
+}
+func (m *TransferChunksResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *TransferChunksResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_TransferChunksResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TransferChunksResponse proto.InternalMessageInfo
+
+func (m *TransferChunksResponse) GetSeries() uint64 {
+	if m != nil {
+		return m.Series
+	}
+	return 0
+}
+
+func (m *TransferChunksResponse) GetSamples() uint64 {
+	if m != nil {
+		return m.Samples
+	}
+	return 0
+}
+
+func (m *TransferChunksResponse) GetHistograms() uint64 {
+	if m != nil {
+		return m.Histograms
+	}
+	return 0
+}
+
+func (m *TransferChunksResponse) GetFailedSamples() uint64 {
+	if m != nil {
+		return m.FailedSamples
+	}
+	return 0
+}
+
+func (m *TransferChunksResponse) GetFailedHistograms() uint64 {
+	if m != nil {
+		return m.FailedHistograms
+	}
+	return 0
+}
+
+type TransferTSDBResponse struct {
+	Files uint64 `protobuf:"varint,1,opt,name=files,proto3" json:"files,omitempty"`
+	Bytes uint64 `protobuf:"varint,2,opt,name=bytes,proto3" json:"bytes,omitempty"`
+}
+
+func (m *TransferTSDBResponse) Reset()      { *m = TransferTSDBResponse{} }
+func (*TransferTSDBResponse) ProtoMessage() {}
+func (*TransferTSDBResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_60f6df4f3586b478, []int{31}
+}
+func (m *TransferTSDBResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *TransferTSDBResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_TransferTSDBResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *TransferTSDBResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TransferTSDBResponse.Merge(m, src)
+}
+func (m *TransferTSDBResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *TransferTSDBResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_TransferTSDBResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TransferTSDBResponse proto.InternalMessageInfo
+
+func (m *TransferTSDBResponse) GetFiles() uint64 {
+	if m != nil {
+		return m.Files
+	}
+	return 0
+}
+
+func (m *TransferTSDBResponse) GetBytes() uint64 {
+	if m != nil {
+		return m.Bytes
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterEnum("cortex.MatchType", MatchType_name, MatchType_value)
 	proto.RegisterType((*ReadRequest)(nil), "cortex.ReadRequest")
@@ -1504,102 +1815,127 @@ func init() {
 	proto.RegisterType((*LabelMatchers)(nil), "cortex.LabelMatchers")
 	proto.RegisterType((*LabelMatcher)(nil), "cortex.LabelMatcher")
 	proto.RegisterType((*TimeSeriesFile)(nil), "cortex.TimeSeriesFile")
+	proto.RegisterType((*TopSeriesRequest)(nil), "cortex.TopSeriesRequest")
+	proto.RegisterType((*TopSeriesResponse)(nil), "cortex.TopSeriesResponse")
+	proto.RegisterType((*TopSeriesEntry)(nil), "cortex.TopSeriesEntry")
+	proto.RegisterType((*TransferChunksResponse)(nil), "cortex.TransferChunksResponse")
+	proto.RegisterType((*TransferTSDBResponse)(nil), "cortex.TransferTSDBResponse")
 }
 
 func init() { proto.RegisterFile("ingester.proto", fileDescriptor_60f6df4f3586b478) }
 
 var fileDescriptor_60f6df4f3586b478 = []byte{
-	// 1439 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x58, 0x4b, 0x73, 0x13, 0x47,
-	0x10, 0xd6, 0xea, 0x65, 0xa9, 0xf5, 0x40, 0x1e, 0x1b, 0x2c, 0x44, 0x58, 0xc1, 0x52, 0x24, 0xaa,
-	0x24, 0xc8, 0xe0, 0x24, 0x55, 0x90, 0x07, 0x94, 0x05, 0x06, 0x0c, 0x18, 0xc3, 0xda, 0x40, 0x2a,
-	0x95, 0xd4, 0xd6, 0x5a, 0x1a, 0xcb, 0x1b, 0x76, 0xb5, 0xcb, 0xce, 0x88, 0x02, 0x4e, 0x49, 0xe5,
-	0x07, 0x24, 0x87, 0xfc, 0x81, 0xdc, 0x72, 0x4d, 0x55, 0x7e, 0x04, 0x47, 0x1f, 0x72, 0xa0, 0x38,
-	0xb8, 0x82, 0xb8, 0x24, 0x37, 0xf2, 0x0f, 0x52, 0x3b, 0x33, 0xfb, 0xb4, 0xfc, 0x20, 0x05, 0xb9,
-	0x69, 0xbb, 0xbf, 0xe9, 0xe9, 0xfe, 0xe6, 0x9b, 0xe9, 0xb6, 0xa1, 0x6a, 0x0c, 0xfa, 0x98, 0x50,
-	0xec, 0xb6, 0x1d, 0xd7, 0xa6, 0x36, 0xca, 0x77, 0x6d, 0x97, 0xe2, 0x47, 0x8d, 0xe9, 0xbe, 0xdd,
-	0xb7, 0x99, 0x69, 0xd6, 0xfb, 0xc5, 0xbd, 0x8d, 0x73, 0x7d, 0x83, 0x6e, 0x0c, 0xd7, 0xda, 0x5d,
-	0xdb, 0x9a, 0xe5, 0x40, 0xc7, 0xb5, 0xbf, 0xc5, 0x5d, 0x2a, 0xbe, 0x66, 0x9d, 0xfb, 0x7d, 0xdf,
-	0xb1, 0x26, 0x7e, 0xf0, 0xa5, 0xca, 0x17, 0x50, 0x52, 0xb1, 0xde, 0x53, 0xf1, 0x83, 0x21, 0x26,
-	0x14, 0xb5, 0x61, 0xe2, 0xc1, 0x10, 0xbb, 0x06, 0x26, 0x75, 0xe9, 0x58, 0xa6, 0x55, 0x9a, 0x9b,
-	0x6e, 0x0b, 0xf8, 0xed, 0x21, 0x76, 0x1f, 0x0b, 0x98, 0xea, 0x83, 0x94, 0x0b, 0x50, 0xe6, 0xcb,
-	0x89, 0x63, 0x0f, 0x08, 0x46, 0xb3, 0x30, 0xe1, 0x62, 0x32, 0x34, 0xa9, 0xbf, 0xfe, 0x60, 0x62,
-	0x3d, 0xc7, 0xa9, 0x3e, 0x4a, 0xb9, 0x0e, 0x95, 0x98, 0x07, 0x7d, 0x0a, 0x40, 0x0d, 0x0b, 0x93,
-	0x71, 0x49, 0x38, 0x6b, 0xed, 0x55, 0xc3, 0xc2, 0x2b, 0xcc, 0xd7, 0xc9, 0x3e, 0xdd, 0x6a, 0xa6,
-	0xd4, 0x08, 0x5a, 0xf9, 0x39, 0x0d, 0xe5, 0x68, 0x9e, 0xe8, 0x43, 0x40, 0x84, 0xea, 0x2e, 0xd5,
-	0x18, 0x88, 0xea, 0x96, 0xa3, 0x59, 0x5e, 0x50, 0xa9, 0x95, 0x51, 0x6b, 0xcc, 0xb3, 0xea, 0x3b,
-	0x96, 0x08, 0x6a, 0x41, 0x0d, 0x0f, 0x7a, 0x71, 0x6c, 0x9a, 0x61, 0xab, 0x78, 0xd0, 0x8b, 0x22,
-	0x4f, 0x43, 0xc1, 0xd2, 0x69, 0x77, 0x03, 0xbb, 0xa4, 0x9e, 0x89, 0xf3, 0x74, 0x43, 0x5f, 0xc3,
-	0xe6, 0x12, 0x77, 0xaa, 0x01, 0x0a, 0x3d, 0x81, 0x8c, 0x8a, 0xd7, 0xeb, 0x7f, 0x4f, 0x1c, 0x93,
-	0x5a, 0xa5, 0xb9, 0x23, 0x61, 0x41, 0x4b, 0x98, 0x10, 0xbd, 0x8f, 0xef, 0x19, 0x74, 0xa3, 0x33,
-	0x5c, 0x57, 0xf1, 0x7a, 0xe7, 0x9a, 0x57, 0xd7, 0xe6, 0x56, 0x53, 0x7a, 0xbe, 0xd5, 0x3c, 0xff,
-	0x3a, 0x27, 0xbb, 0x3d, 0x96, 0xea, 0x6d, 0xaa, 0xfc, 0x22, 0xc1, 0xf4, 0xc2, 0x23, 0x6c, 0x39,
-	0xa6, 0xee, 0xfe, 0x2f, 0xf4, 0x9c, 0xd9, 0x46, 0xcf, 0xc1, 0x71, 0xf4, 0x90, 0x90, 0x1f, 0xe5,
-	0x6b, 0x98, 0x62, 0xa9, 0xad, 0x50, 0x17, 0xeb, 0x56, 0xa0, 0x86, 0x0b, 0x50, 0xea, 0x6e, 0x0c,
-	0x07, 0xf7, 0x63, 0x72, 0x98, 0xf1, 0x83, 0x85, 0x62, 0xb8, 0xe8, 0x81, 0x84, 0x22, 0xa2, 0x2b,
-	0xae, 0x65, 0x0b, 0xe9, 0x5a, 0x46, 0x59, 0x81, 0x83, 0x09, 0x02, 0xde, 0x80, 0xda, 0xfe, 0x90,
-	0x00, 0xb1, 0x72, 0xee, 0xea, 0xe6, 0x10, 0x13, 0x9f, 0xd4, 0xa3, 0x00, 0xa6, 0x67, 0xd5, 0x06,
-	0xba, 0x85, 0x19, 0x99, 0x45, 0xb5, 0xc8, 0x2c, 0x37, 0x75, 0x0b, 0xef, 0xc0, 0x79, 0xfa, 0x35,
-	0x38, 0xcf, 0xec, 0xc9, 0x79, 0x96, 0x89, 0x6c, 0x2f, 0xce, 0xd1, 0x34, 0xe4, 0x4c, 0xc3, 0x32,
-	0x68, 0x3d, 0xc7, 0x22, 0xf2, 0x0f, 0xe5, 0x2c, 0x4c, 0xc5, 0xaa, 0x12, 0x4c, 0x1d, 0x87, 0x32,
-	0x2f, 0xeb, 0x21, 0xb3, 0x33, 0xae, 0x8a, 0x6a, 0xc9, 0x0c, 0xa1, 0xca, 0x79, 0x38, 0x1c, 0x59,
-	0x99, 0x38, 0xc9, 0x7d, 0xac, 0xff, 0x5d, 0x82, 0xc9, 0x1b, 0x3e, 0x51, 0xe4, 0x6d, 0x8b, 0x34,
-	0xa8, 0x3e, 0x13, 0xa9, 0xfe, 0x3f, 0xd0, 0xa8, 0x7c, 0x22, 0x64, 0x20, 0xb2, 0x16, 0xf5, 0x36,
-	0xa1, 0x14, 0xca, 0xc0, 0x2f, 0x17, 0x02, 0x1d, 0x10, 0xe5, 0x33, 0xa8, 0x87, 0xcb, 0x12, 0x64,
-	0xed, 0xb9, 0x18, 0x41, 0xed, 0x0e, 0xc1, 0xee, 0x0a, 0xd5, 0xa9, 0x4f, 0x94, 0xf2, 0x7d, 0x1a,
-	0x26, 0x23, 0x46, 0x11, 0xea, 0xa4, 0xdf, 0x4b, 0x0c, 0x7b, 0xa0, 0xb9, 0x3a, 0xe5, 0x92, 0x94,
-	0xd4, 0x4a, 0x60, 0x55, 0x75, 0x8a, 0x3d, 0xd5, 0x0e, 0x86, 0x96, 0x26, 0x2e, 0x82, 0xc7, 0x58,
-	0x56, 0x2d, 0x0e, 0x86, 0x16, 0x57, 0xbf, 0x77, 0x08, 0xba, 0x63, 0x68, 0x89, 0x48, 0x19, 0x16,
-	0xa9, 0xa6, 0x3b, 0xc6, 0x62, 0x2c, 0x58, 0x1b, 0xa6, 0xdc, 0xa1, 0x89, 0x93, 0xf0, 0x2c, 0x83,
-	0x4f, 0x7a, 0xae, 0x38, 0xfe, 0x04, 0x54, 0xf4, 0x2e, 0x35, 0x1e, 0x62, 0x7f, 0xff, 0x1c, 0xdb,
-	0xbf, 0xcc, 0x8d, 0x22, 0x85, 0x13, 0x50, 0x31, 0x6d, 0xbd, 0x87, 0x7b, 0xda, 0x9a, 0x69, 0x77,
-	0xef, 0x93, 0x7a, 0x9e, 0x83, 0xb8, 0xb1, 0xc3, 0x6c, 0xca, 0x37, 0x30, 0xe5, 0x51, 0xb0, 0x78,
-	0x29, 0x4e, 0xc2, 0x0c, 0x4c, 0x0c, 0x09, 0x76, 0x35, 0xa3, 0x27, 0x2e, 0x64, 0xde, 0xfb, 0x5c,
-	0xec, 0xa1, 0x53, 0x90, 0xed, 0xe9, 0x54, 0x67, 0x05, 0x97, 0xe6, 0x0e, 0xfb, 0x47, 0xbd, 0x8d,
-	0x46, 0x95, 0xc1, 0x94, 0x2b, 0x80, 0x3c, 0x17, 0x89, 0x47, 0x3f, 0x03, 0x39, 0xe2, 0x19, 0xc4,
-	0xfb, 0x71, 0x24, 0x1a, 0x25, 0x91, 0x89, 0xca, 0x91, 0xca, 0x53, 0x09, 0xe4, 0x25, 0x4c, 0x5d,
-	0xa3, 0x4b, 0x2e, 0xdb, 0x6e, 0x5c, 0x59, 0x6f, 0x59, 0xf7, 0x67, 0xa1, 0xec, 0x4b, 0x57, 0x23,
-	0x98, 0xee, 0xfe, 0x40, 0x97, 0x7c, 0xe8, 0x0a, 0xa6, 0xe1, 0x8d, 0xc9, 0x46, 0xdf, 0x8b, 0xeb,
-	0xd0, 0xdc, 0xb1, 0x12, 0x41, 0x50, 0x0b, 0xf2, 0x16, 0x83, 0x08, 0x86, 0x6a, 0xd1, 0xf6, 0xe7,
-	0xd9, 0x55, 0xe1, 0x57, 0x6e, 0xc3, 0xc9, 0x1d, 0x82, 0x25, 0x6e, 0xc8, 0xfe, 0x43, 0x3a, 0x70,
-	0x48, 0x84, 0x5c, 0xc2, 0x54, 0xf7, 0x8e, 0xd1, 0x67, 0x38, 0xa8, 0x47, 0x8a, 0xbe, 0x00, 0x2d,
-	0xa8, 0xb1, 0x1f, 0x9a, 0x83, 0x5d, 0x4d, 0xec, 0x21, 0x98, 0x64, 0xf6, 0x5b, 0xd8, 0xe5, 0xf1,
-	0xd0, 0xa1, 0x20, 0x87, 0x0c, 0x17, 0x95, 0xd8, 0x71, 0x19, 0x66, 0xb6, 0xed, 0x28, 0xd2, 0xfe,
-	0x18, 0x0a, 0x96, 0xb0, 0x89, 0xc4, 0xeb, 0xc9, 0xc4, 0x83, 0x35, 0x01, 0x52, 0xf9, 0x47, 0x82,
-	0x03, 0x89, 0x5e, 0xe7, 0xa5, 0xb9, 0xee, 0xda, 0x96, 0xe6, 0x0f, 0x8a, 0xa1, 0xb6, 0xab, 0x9e,
-	0x7d, 0x51, 0x98, 0x17, 0x7b, 0x51, 0xf1, 0xa7, 0x63, 0xe2, 0x1f, 0x40, 0x9e, 0x3d, 0x29, 0x7e,
-	0x93, 0x9e, 0x0a, 0x53, 0x61, 0xd4, 0xdf, 0xd2, 0x0d, 0xb7, 0x33, 0xef, 0xf5, 0xbd, 0xe7, 0x5b,
-	0xcd, 0xd7, 0x9a, 0x31, 0xf9, 0xfa, 0xf9, 0x9e, 0xee, 0x50, 0xec, 0xaa, 0x62, 0x17, 0xf4, 0x01,
-	0xe4, 0x79, 0x6b, 0xae, 0x67, 0xd9, 0x7e, 0x15, 0x5f, 0x73, 0xd1, 0xee, 0x2d, 0x20, 0xca, 0x8f,
-	0x12, 0xe4, 0x78, 0xa5, 0x6f, 0xeb, 0x22, 0x34, 0xa0, 0x80, 0x07, 0x5d, 0xbb, 0x67, 0x0c, 0xfa,
-	0xec, 0x00, 0x73, 0x6a, 0xf0, 0x8d, 0x90, 0x78, 0x17, 0x3c, 0xa5, 0x97, 0xc5, 0xe5, 0x9f, 0x87,
-	0x4a, 0x4c, 0x91, 0xb1, 0x29, 0x50, 0xda, 0xcf, 0x14, 0xa8, 0x68, 0x50, 0x8e, 0x7a, 0xd0, 0x49,
-	0xc8, 0xd2, 0xc7, 0x0e, 0x7f, 0x92, 0xab, 0x73, 0x93, 0xfe, 0x6a, 0xe6, 0x5e, 0x7d, 0xec, 0x60,
-	0x95, 0xb9, 0xbd, 0x6c, 0xd8, 0x30, 0xc1, 0x8f, 0x8f, 0xfd, 0xf6, 0xc4, 0xcb, 0x3a, 0xa9, 0xd0,
-	0x1e, 0xff, 0x50, 0x7e, 0x90, 0xa0, 0x1a, 0x2a, 0xe5, 0xb2, 0x61, 0xe2, 0x37, 0x21, 0x94, 0x06,
-	0x14, 0xd6, 0x0d, 0x13, 0xb3, 0x1c, 0xf8, 0x76, 0xc1, 0xf7, 0x38, 0xa6, 0xde, 0xbf, 0x06, 0xc5,
-	0xa0, 0x04, 0x54, 0x84, 0xdc, 0xc2, 0xed, 0x3b, 0xf3, 0x37, 0x6a, 0x29, 0x54, 0x81, 0xe2, 0xcd,
-	0xe5, 0x55, 0x8d, 0x7f, 0x4a, 0xe8, 0x00, 0x94, 0xd4, 0x85, 0x2b, 0x0b, 0x5f, 0x6a, 0x4b, 0xf3,
-	0xab, 0x17, 0xaf, 0xd6, 0xd2, 0x08, 0x41, 0x95, 0x1b, 0x6e, 0x2e, 0x0b, 0x5b, 0x66, 0xee, 0xb7,
-	0x02, 0x14, 0xfc, 0x1c, 0xd1, 0x39, 0xc8, 0xde, 0x1a, 0x92, 0x0d, 0x74, 0x28, 0x54, 0xea, 0x3d,
-	0xd7, 0xa0, 0x58, 0xdc, 0xe8, 0xc6, 0xcc, 0x36, 0x3b, 0xbf, 0x77, 0x4a, 0x0a, 0x2d, 0x02, 0x78,
-	0x4b, 0xf9, 0x33, 0x82, 0xde, 0x09, 0x81, 0xdc, 0xb2, 0xcf, 0x30, 0x2d, 0xe9, 0xb4, 0x84, 0x2e,
-	0x41, 0x29, 0x32, 0xab, 0xa2, 0xb1, 0x7f, 0x22, 0x35, 0x8e, 0xc4, 0xac, 0xf1, 0xd7, 0x4b, 0x49,
-	0x9d, 0x96, 0xd0, 0x32, 0x54, 0x99, 0xcb, 0x1f, 0x4c, 0x49, 0x90, 0x54, 0x7b, 0xdc, 0xb0, 0xde,
-	0x38, 0xba, 0x83, 0x37, 0xa8, 0xf0, 0x2a, 0x94, 0x22, 0xe3, 0x17, 0x6a, 0xc4, 0xb4, 0x18, 0x9b,
-	0x51, 0xc3, 0xe4, 0xc6, 0x4c, 0x7a, 0x4a, 0x0a, 0xdd, 0x15, 0x73, 0x58, 0x74, 0x90, 0xdb, 0x35,
-	0xde, 0xf1, 0x31, 0xbe, 0x31, 0x25, 0x2f, 0x00, 0x84, 0x23, 0x0f, 0x3a, 0x1c, 0x5b, 0x14, 0x9d,
-	0xf9, 0x1a, 0x8d, 0x71, 0xae, 0x20, 0xbd, 0x15, 0xa8, 0x25, 0x27, 0xa7, 0xdd, 0x82, 0x1d, 0xdb,
-	0xee, 0x1a, 0x93, 0x5b, 0x07, 0x8a, 0x41, 0xd7, 0x47, 0xf5, 0x31, 0x83, 0x00, 0x0f, 0xb6, 0xf3,
-	0x88, 0xa0, 0xa4, 0xd0, 0x65, 0x28, 0xcf, 0x9b, 0xe6, 0x7e, 0xc2, 0x34, 0xa2, 0x1e, 0x92, 0x8c,
-	0x63, 0x06, 0x0d, 0x24, 0xd9, 0x05, 0xd1, 0xbb, 0xc1, 0x1b, 0xb1, 0xeb, 0xf4, 0xd0, 0x78, 0x6f,
-	0x4f, 0x5c, 0xb0, 0xdb, 0x13, 0x38, 0xba, 0x6b, 0xcf, 0xdd, 0xf7, 0x9e, 0xa7, 0xf6, 0xc0, 0x8d,
-	0x61, 0x7d, 0x15, 0x0e, 0x24, 0x5a, 0x25, 0x92, 0x13, 0x51, 0x12, 0x5d, 0xbb, 0xd1, 0xdc, 0xd1,
-	0xef, 0xc7, 0xed, 0x7c, 0xbe, 0xf9, 0x42, 0x4e, 0x3d, 0x7b, 0x21, 0xa7, 0x5e, 0xbd, 0x90, 0xa5,
-	0xef, 0x46, 0xb2, 0xf4, 0xeb, 0x48, 0x96, 0x9e, 0x8e, 0x64, 0x69, 0x73, 0x24, 0x4b, 0x7f, 0x8e,
-	0x64, 0xe9, 0xaf, 0x91, 0x9c, 0x7a, 0x35, 0x92, 0xa5, 0x9f, 0x5e, 0xca, 0xa9, 0xcd, 0x97, 0x72,
-	0xea, 0xd9, 0x4b, 0x39, 0xf5, 0x55, 0xbe, 0x6b, 0x1a, 0x78, 0x40, 0xd7, 0xf2, 0xec, 0x3f, 0x23,
-	0x1f, 0xfd, 0x1b, 0x00, 0x00, 0xff, 0xff, 0x3c, 0x76, 0xeb, 0xb3, 0x84, 0x11, 0x00, 0x00,
+	// 1750 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x58, 0xcd, 0x73, 0x1b, 0x49,
+	0x15, 0xd7, 0xe8, 0xcb, 0xd6, 0xd3, 0x87, 0xa5, 0xb6, 0x63, 0xcb, 0xe3, 0xcd, 0xd8, 0x3b, 0x5b,
+	0x01, 0x15, 0xcb, 0xda, 0x59, 0x03, 0x55, 0xbb, 0x7c, 0xec, 0x96, 0xb5, 0x71, 0xd6, 0x36, 0x76,
+	0xec, 0x8c, 0xb4, 0xbb, 0x14, 0x05, 0x35, 0x35, 0x96, 0xda, 0xf2, 0x90, 0xf9, 0xda, 0x99, 0x56,
+	0x2a, 0xce, 0x09, 0x8a, 0x3b, 0x70, 0xe0, 0x1f, 0xa0, 0x8a, 0x03, 0x7f, 0x00, 0x67, 0x2e, 0x5c,
+	0x72, 0xf4, 0x81, 0x43, 0x2a, 0x07, 0x17, 0x71, 0x2e, 0x70, 0x0b, 0xff, 0x01, 0x35, 0xdd, 0x3d,
+	0x9f, 0x1e, 0xd9, 0x0a, 0x10, 0x6e, 0xea, 0xf7, 0xf1, 0xeb, 0xd7, 0x6f, 0x7e, 0xfd, 0xde, 0x6b,
+	0x41, 0x43, 0xb7, 0x46, 0xd8, 0x23, 0xd8, 0x5d, 0x77, 0x5c, 0x9b, 0xd8, 0xa8, 0x3c, 0xb0, 0x5d,
+	0x82, 0x9f, 0x88, 0x0b, 0x23, 0x7b, 0x64, 0x53, 0xd1, 0x86, 0xff, 0x8b, 0x69, 0xc5, 0x8f, 0x47,
+	0x3a, 0x39, 0x1d, 0x1f, 0xaf, 0x0f, 0x6c, 0x73, 0x83, 0x19, 0x3a, 0xae, 0xfd, 0x0b, 0x3c, 0x20,
+	0x7c, 0xb5, 0xe1, 0x3c, 0x1a, 0x05, 0x8a, 0x63, 0xfe, 0x83, 0xb9, 0xca, 0x3f, 0x82, 0xaa, 0x82,
+	0xb5, 0xa1, 0x82, 0xbf, 0x1e, 0x63, 0x8f, 0xa0, 0x75, 0x98, 0xf9, 0x7a, 0x8c, 0x5d, 0x1d, 0x7b,
+	0x6d, 0x61, 0xad, 0xd0, 0xa9, 0x6e, 0x2e, 0xac, 0x73, 0xf3, 0x87, 0x63, 0xec, 0x9e, 0x71, 0x33,
+	0x25, 0x30, 0x92, 0x3f, 0x85, 0x1a, 0x73, 0xf7, 0x1c, 0xdb, 0xf2, 0x30, 0xda, 0x80, 0x19, 0x17,
+	0x7b, 0x63, 0x83, 0x04, 0xfe, 0xb7, 0x52, 0xfe, 0xcc, 0x4e, 0x09, 0xac, 0xe4, 0x5f, 0x09, 0x50,
+	0x4f, 0xa8, 0xd0, 0xf7, 0x01, 0x88, 0x6e, 0x62, 0x2f, 0x2b, 0x0a, 0xe7, 0x78, 0xbd, 0xaf, 0x9b,
+	0xb8, 0x47, 0x75, 0xdd, 0xe2, 0xb3, 0x8b, 0xd5, 0x9c, 0x12, 0xb3, 0x46, 0xdf, 0x85, 0x59, 0x13,
+	0x13, 0x6d, 0xa8, 0x11, 0xad, 0x9d, 0xa7, 0x9e, 0xed, 0xc8, 0xf3, 0x00, 0x13, 0x57, 0x1f, 0x1c,
+	0x70, 0xbd, 0x12, 0x5a, 0xca, 0xbf, 0xcf, 0x43, 0x2d, 0x7e, 0x3c, 0xf4, 0x6d, 0x40, 0x1e, 0xd1,
+	0x5c, 0xa2, 0x52, 0x68, 0xa2, 0x99, 0x8e, 0x6a, 0xfa, 0xa1, 0x08, 0x9d, 0x82, 0xd2, 0xa4, 0x9a,
+	0x7e, 0xa0, 0x38, 0xf0, 0x50, 0x07, 0x9a, 0xd8, 0x1a, 0x26, 0x6d, 0xf3, 0xd4, 0xb6, 0x81, 0xad,
+	0x61, 0xdc, 0xf2, 0x2e, 0xcc, 0x9a, 0x1a, 0x19, 0x9c, 0x62, 0xd7, 0x6b, 0x17, 0x92, 0xe9, 0xdd,
+	0xd7, 0x8e, 0xb1, 0x71, 0xc0, 0x94, 0x4a, 0x68, 0x85, 0x9e, 0x42, 0x41, 0xc1, 0x27, 0xed, 0x7f,
+	0xce, 0xac, 0x09, 0x9d, 0xea, 0xe6, 0x4a, 0xfc, 0x30, 0x9e, 0xa7, 0x8d, 0xf0, 0x57, 0x3a, 0x39,
+	0xed, 0x8e, 0x4f, 0x14, 0x7c, 0xd2, 0xdd, 0xf3, 0xb3, 0x71, 0x7e, 0xb1, 0x2a, 0xbc, 0xb8, 0x58,
+	0xfd, 0xe4, 0x4d, 0x08, 0x71, 0x15, 0x4b, 0xf1, 0x37, 0x95, 0xff, 0x20, 0xc0, 0xc2, 0xf6, 0x13,
+	0x6c, 0x3a, 0x86, 0xe6, 0xfe, 0x5f, 0xd2, 0xf3, 0xe1, 0x95, 0xf4, 0xdc, 0xca, 0x4a, 0x8f, 0x17,
+	0xe5, 0x47, 0xfe, 0x19, 0xcc, 0xd3, 0xd0, 0x7a, 0xc4, 0xc5, 0x9a, 0x19, 0x72, 0xe8, 0x53, 0xa8,
+	0x0e, 0x4e, 0xc7, 0xd6, 0xa3, 0x04, 0x89, 0x96, 0x02, 0xb0, 0x88, 0x42, 0x9f, 0xf9, 0x46, 0x9c,
+	0x47, 0x71, 0x8f, 0xbd, 0xe2, 0x6c, 0xbe, 0x59, 0x90, 0x7b, 0x70, 0x2b, 0x95, 0x80, 0xff, 0x9e,
+	0xa3, 0xf2, 0xdf, 0x04, 0x40, 0xf4, 0x38, 0x5f, 0x6a, 0xc6, 0x18, 0x7b, 0x41, 0x52, 0x6f, 0x03,
+	0x18, 0xbe, 0x54, 0xb5, 0x34, 0x13, 0xd3, 0x64, 0x56, 0x94, 0x0a, 0x95, 0x3c, 0xd0, 0x4c, 0x3c,
+	0x21, 0xe7, 0xf9, 0x37, 0xc8, 0x79, 0xe1, 0xc6, 0x9c, 0x17, 0xd7, 0x84, 0x29, 0x72, 0x8e, 0x16,
+	0xa0, 0x64, 0xe8, 0xa6, 0x4e, 0xda, 0x25, 0x8a, 0xc8, 0x16, 0xf2, 0x47, 0x30, 0x9f, 0x38, 0x15,
+	0xcf, 0xd4, 0xbb, 0x50, 0x63, 0xc7, 0x7a, 0x4c, 0xe5, 0x34, 0x57, 0x15, 0xa5, 0x6a, 0x44, 0xa6,
+	0xf2, 0x27, 0xb0, 0x1c, 0xf3, 0x4c, 0x7d, 0xc9, 0x29, 0xfc, 0xff, 0x2c, 0x40, 0x6b, 0x3f, 0x48,
+	0x94, 0xf7, 0xb6, 0x49, 0x1a, 0x9e, 0xbe, 0x10, 0x3b, 0xfd, 0x7f, 0x90, 0x46, 0xf9, 0x7b, 0x80,
+	0xe2, 0x51, 0xf3, 0xf3, 0xae, 0x42, 0x35, 0xa2, 0x41, 0x70, 0x5c, 0x08, 0x79, 0xe0, 0xc9, 0x3f,
+	0x80, 0x76, 0xe4, 0x96, 0x4a, 0xd6, 0x8d, 0xce, 0x08, 0x9a, 0x5f, 0x78, 0xd8, 0xed, 0x11, 0x8d,
+	0x04, 0x89, 0x92, 0xff, 0x98, 0x87, 0x56, 0x4c, 0xc8, 0xa1, 0xee, 0x04, 0x2d, 0x48, 0xb7, 0x2d,
+	0xd5, 0xd5, 0x08, 0xa3, 0xa4, 0xa0, 0xd4, 0x43, 0xa9, 0xa2, 0x11, 0xec, 0xb3, 0xd6, 0x1a, 0x9b,
+	0x2a, 0xbf, 0x08, 0x7e, 0xc6, 0x8a, 0x4a, 0xc5, 0x1a, 0x9b, 0x8c, 0xfd, 0xfe, 0x47, 0xd0, 0x1c,
+	0x5d, 0x4d, 0x21, 0x15, 0x28, 0x52, 0x53, 0x73, 0xf4, 0xdd, 0x04, 0xd8, 0x3a, 0xcc, 0xbb, 0x63,
+	0x03, 0xa7, 0xcd, 0x8b, 0xd4, 0xbc, 0xe5, 0xab, 0x92, 0xf6, 0xef, 0x41, 0x5d, 0x1b, 0x10, 0xfd,
+	0x31, 0x0e, 0xf6, 0x2f, 0xd1, 0xfd, 0x6b, 0x4c, 0xc8, 0x43, 0x78, 0x0f, 0xea, 0x86, 0xad, 0x0d,
+	0xf1, 0x50, 0x3d, 0x36, 0xec, 0xc1, 0x23, 0xaf, 0x5d, 0x66, 0x46, 0x4c, 0xd8, 0xa5, 0x32, 0x9f,
+	0x65, 0x0c, 0x42, 0x1d, 0x9c, 0x8e, 0x5d, 0xab, 0x3d, 0x43, 0x6d, 0xaa, 0x5e, 0x50, 0x24, 0x5c,
+	0x4b, 0xfe, 0x39, 0xcc, 0xfb, 0x59, 0xda, 0xbd, 0x97, 0xcc, 0xd3, 0x12, 0xcc, 0x8c, 0x3d, 0xec,
+	0xaa, 0xfa, 0x90, 0xdf, 0xd9, 0xb2, 0xbf, 0xdc, 0x1d, 0xa2, 0x0f, 0xa0, 0xc8, 0xdb, 0x90, 0xcf,
+	0x86, 0xe5, 0x80, 0x0d, 0x57, 0x32, 0xad, 0x50, 0x33, 0xf9, 0x73, 0x40, 0xbe, 0xca, 0x4b, 0xa2,
+	0x7f, 0x08, 0x25, 0xcf, 0x17, 0xf0, 0x12, 0xb3, 0x12, 0x47, 0x49, 0x45, 0xa2, 0x30, 0x4b, 0xf9,
+	0x99, 0x00, 0x12, 0xeb, 0x74, 0xde, 0x7d, 0xdb, 0x4d, 0x92, 0xef, 0x2d, 0x5f, 0x8d, 0x8f, 0xa0,
+	0x16, 0xb0, 0x5b, 0xf5, 0x30, 0xb9, 0xbe, 0x86, 0x57, 0x03, 0xd3, 0x1e, 0x26, 0xd1, 0xa5, 0x2a,
+	0xc6, 0x4b, 0xca, 0x8f, 0x61, 0x75, 0xe2, 0x49, 0x78, 0x82, 0x3a, 0x50, 0x36, 0xa9, 0x09, 0xcf,
+	0x50, 0x33, 0xdd, 0xee, 0x15, 0xae, 0x97, 0x1f, 0xc2, 0x9d, 0x09, 0x60, 0xa9, 0x4b, 0x34, 0x3d,
+	0xa4, 0x03, 0x8b, 0x1c, 0x32, 0x1c, 0x2a, 0x78, 0x86, 0xc3, 0xf3, 0x08, 0xf1, 0x22, 0xd1, 0x81,
+	0x26, 0xfd, 0xa1, 0x3a, 0xd8, 0x55, 0xf9, 0x1e, 0x3c, 0x93, 0x54, 0x7e, 0x84, 0x5d, 0x86, 0x87,
+	0x16, 0xc3, 0x18, 0x0a, 0x8c, 0x54, 0x7c, 0xc7, 0x43, 0x58, 0xba, 0xb2, 0x23, 0x0f, 0x3b, 0x3e,
+	0xfa, 0x08, 0x53, 0x8f, 0x3e, 0xff, 0x12, 0x60, 0x2e, 0xd5, 0x0e, 0xfd, 0x30, 0x4f, 0x5c, 0xdb,
+	0x54, 0x83, 0x11, 0x34, 0xe2, 0x76, 0xc3, 0x97, 0xef, 0x72, 0xf1, 0xee, 0x30, 0x4e, 0xfe, 0x7c,
+	0x82, 0xfc, 0x16, 0x94, 0x69, 0xd5, 0x09, 0xfa, 0xf8, 0x7c, 0x14, 0x0a, 0x4d, 0xfd, 0x91, 0xa6,
+	0xbb, 0xdd, 0x2d, 0xbf, 0x35, 0xbe, 0xb8, 0x58, 0x7d, 0xa3, 0xe9, 0x95, 0xf9, 0x6f, 0x0d, 0x35,
+	0x87, 0x60, 0x57, 0xe1, 0xbb, 0xa0, 0xf7, 0xa1, 0xcc, 0xba, 0x77, 0xbb, 0x48, 0xf7, 0xab, 0x07,
+	0x9c, 0x8b, 0x37, 0x78, 0x6e, 0x22, 0xff, 0x56, 0x80, 0x12, 0x3b, 0xe9, 0xdb, 0xba, 0x08, 0x22,
+	0xcc, 0x62, 0x6b, 0x60, 0x0f, 0x75, 0x6b, 0x44, 0x3f, 0x60, 0x49, 0x09, 0xd7, 0x08, 0xf1, 0xba,
+	0xe0, 0x33, 0xbd, 0xc6, 0x2f, 0xff, 0x16, 0xd4, 0x13, 0x8c, 0x4c, 0x0c, 0x8a, 0xc2, 0x34, 0x83,
+	0xa2, 0xac, 0x42, 0x2d, 0xae, 0x41, 0x77, 0xa0, 0x48, 0xce, 0x1c, 0x56, 0xb5, 0x1b, 0x9b, 0xad,
+	0xc0, 0x9b, 0xaa, 0xfb, 0x67, 0x0e, 0x56, 0xa8, 0xda, 0x8f, 0x86, 0xce, 0x1b, 0xec, 0xf3, 0xd1,
+	0xdf, 0x3e, 0x79, 0x69, 0xb3, 0xe5, 0xdc, 0x63, 0x0b, 0xf9, 0xd7, 0x02, 0x34, 0x22, 0xa6, 0xdc,
+	0xd7, 0x0d, 0xfc, 0xbf, 0x20, 0x8a, 0x08, 0xb3, 0x27, 0xba, 0x81, 0x69, 0x0c, 0x6c, 0xbb, 0x70,
+	0x9d, 0x99, 0xa9, 0x0e, 0x34, 0xfb, 0xb6, 0xc3, 0x62, 0xc8, 0xbc, 0x6c, 0xf5, 0xa0, 0x78, 0xfc,
+	0x35, 0x0f, 0xad, 0x98, 0x29, 0xbf, 0x25, 0xfb, 0x30, 0xcf, 0x5b, 0x06, 0xbb, 0x51, 0xb1, 0x4e,
+	0x59, 0xdd, 0x5c, 0x0c, 0x07, 0xc4, 0xc0, 0x6f, 0xdb, 0x22, 0xee, 0x19, 0xa7, 0x4f, 0x8b, 0x39,
+	0xb2, 0xab, 0x44, 0xdb, 0x29, 0xda, 0x03, 0xc4, 0xd1, 0x58, 0xdb, 0x75, 0x34, 0xdd, 0xf5, 0xda,
+	0xf9, 0x29, 0xc0, 0x9a, 0xcc, 0x2f, 0xbc, 0x0c, 0x14, 0x8b, 0xf6, 0x9e, 0x64, 0x60, 0x85, 0x69,
+	0xb0, 0xa8, 0x5f, 0x3c, 0xae, 0x1d, 0x68, 0x31, 0xac, 0x78, 0x58, 0xc5, 0x29, 0xa0, 0xe6, 0xa8,
+	0x5b, 0x14, 0x95, 0x7c, 0x04, 0x8d, 0xa4, 0x61, 0xc8, 0x18, 0x21, 0x8b, 0x31, 0xf9, 0x18, 0x63,
+	0x7c, 0xe9, 0xc0, 0x1e, 0x5b, 0x6c, 0x52, 0x2a, 0x2a, 0x6c, 0x21, 0xff, 0x45, 0x80, 0xc5, 0xbe,
+	0xab, 0x59, 0xde, 0x09, 0x76, 0xe9, 0x2d, 0x8c, 0x3e, 0xce, 0x22, 0x94, 0xc3, 0x89, 0xda, 0xf7,
+	0xe0, 0x2b, 0xd4, 0x86, 0x19, 0x4f, 0x33, 0x1d, 0x23, 0x9c, 0x30, 0x82, 0x25, 0x92, 0x00, 0x4e,
+	0x75, 0x8f, 0xd8, 0x23, 0x57, 0xe3, 0x13, 0x6e, 0x51, 0x89, 0x49, 0xfc, 0x29, 0xe6, 0x44, 0xd3,
+	0x0d, 0x3c, 0x54, 0x03, 0x80, 0x22, 0xb5, 0xa9, 0x33, 0x69, 0x8f, 0xc3, 0xbc, 0x0f, 0x2d, 0x6e,
+	0x16, 0x43, 0x63, 0xc3, 0x44, 0x93, 0x29, 0x76, 0x42, 0xb9, 0xdc, 0x85, 0x85, 0x20, 0xfe, 0x7e,
+	0xef, 0x5e, 0x37, 0x8c, 0x7e, 0x01, 0x4a, 0x3e, 0x75, 0x83, 0xe0, 0xd9, 0xc2, 0x97, 0x1e, 0x9f,
+	0x91, 0x30, 0x72, 0xb6, 0xf8, 0xd6, 0x1e, 0x54, 0xc2, 0x9b, 0x88, 0x2a, 0x50, 0xda, 0x7e, 0xf8,
+	0xc5, 0xd6, 0x7e, 0x33, 0x87, 0xea, 0x50, 0x79, 0x70, 0xd8, 0x57, 0xd9, 0x52, 0x40, 0x73, 0x50,
+	0x55, 0xb6, 0x3f, 0xdf, 0xfe, 0x89, 0x7a, 0xb0, 0xd5, 0xff, 0x6c, 0xa7, 0x99, 0x47, 0x08, 0x1a,
+	0x4c, 0xf0, 0xe0, 0x90, 0xcb, 0x0a, 0x9b, 0xbf, 0x01, 0x98, 0x0d, 0xae, 0x1a, 0xfa, 0x18, 0x8a,
+	0x47, 0x63, 0xef, 0x14, 0x2d, 0x46, 0x05, 0xf7, 0x2b, 0x57, 0x27, 0x98, 0xdf, 0x15, 0x71, 0xe9,
+	0x8a, 0x9c, 0x45, 0x2f, 0xe7, 0xd0, 0x2e, 0x80, 0xef, 0xca, 0xba, 0x21, 0x7a, 0x27, 0x32, 0x64,
+	0x92, 0x29, 0x61, 0x3a, 0xc2, 0x5d, 0x01, 0xdd, 0x83, 0x6a, 0xec, 0x55, 0x86, 0x32, 0xff, 0x43,
+	0x10, 0x57, 0x12, 0xd2, 0x64, 0x13, 0x96, 0x73, 0x77, 0x05, 0x74, 0x08, 0x0d, 0xaa, 0x0a, 0x9e,
+	0x60, 0x5e, 0x18, 0xd4, 0x7a, 0xd6, 0xb3, 0x54, 0xbc, 0x3d, 0x41, 0x1b, 0x9e, 0x70, 0x07, 0xaa,
+	0xb1, 0x87, 0x06, 0x12, 0x13, 0x25, 0x35, 0xf1, 0x1a, 0x13, 0x57, 0x32, 0x75, 0x21, 0xd2, 0x97,
+	0xd0, 0x8a, 0x29, 0xf8, 0x31, 0xaf, 0xc3, 0x7b, 0x37, 0x43, 0x97, 0x71, 0xe4, 0x6d, 0x80, 0x68,
+	0xb8, 0x47, 0xcb, 0x09, 0xa7, 0xf8, 0xeb, 0x46, 0x14, 0xb3, 0x54, 0x61, 0x78, 0x3d, 0x68, 0xa6,
+	0xdf, 0x08, 0xd7, 0x81, 0xad, 0x5d, 0x55, 0x65, 0xc4, 0xd6, 0x85, 0x4a, 0x38, 0xbc, 0xa2, 0x76,
+	0xc6, 0x3c, 0xcb, 0xc0, 0x26, 0x4f, 0xba, 0x72, 0x0e, 0xdd, 0x87, 0xda, 0x96, 0x61, 0x4c, 0x03,
+	0x23, 0xc6, 0x35, 0x5e, 0x1a, 0xc7, 0x80, 0xa5, 0x09, 0xc3, 0x1c, 0xfa, 0x46, 0xd8, 0xea, 0xae,
+	0x1d, 0x82, 0xc5, 0x6f, 0xde, 0x68, 0x17, 0xee, 0xf6, 0x14, 0x6e, 0x5f, 0x3b, 0x3a, 0x4e, 0xbd,
+	0xe7, 0x07, 0x37, 0xd8, 0x65, 0x64, 0xbd, 0x0f, 0x73, 0xa9, 0x89, 0x0f, 0x49, 0x29, 0x94, 0xd4,
+	0xf0, 0x29, 0xae, 0x4e, 0xd4, 0x87, 0x27, 0xea, 0x42, 0x25, 0x2c, 0xeb, 0xd1, 0x47, 0x48, 0x77,
+	0x56, 0x71, 0x39, 0x43, 0x13, 0x62, 0x1c, 0x40, 0x23, 0x59, 0xc7, 0xd1, 0xa4, 0x3f, 0x58, 0xc4,
+	0x30, 0xe2, 0xec, 0xc2, 0xef, 0x57, 0x0d, 0xb4, 0x03, 0xb5, 0x78, 0x59, 0x45, 0x8b, 0x57, 0xc1,
+	0xfc, 0xa1, 0x43, 0x7c, 0x27, 0x8d, 0x15, 0x2f, 0xc2, 0x3e, 0x52, 0xf7, 0x87, 0xe7, 0x2f, 0xa5,
+	0xdc, 0xf3, 0x97, 0x52, 0xee, 0xf5, 0x4b, 0x49, 0xf8, 0xe5, 0xa5, 0x24, 0xfc, 0xe9, 0x52, 0x12,
+	0x9e, 0x5d, 0x4a, 0xc2, 0xf9, 0xa5, 0x24, 0xfc, 0xfd, 0x52, 0x12, 0xfe, 0x71, 0x29, 0xe5, 0x5e,
+	0x5f, 0x4a, 0xc2, 0xef, 0x5e, 0x49, 0xb9, 0xf3, 0x57, 0x52, 0xee, 0xf9, 0x2b, 0x29, 0xf7, 0xd3,
+	0xf2, 0xc0, 0xd0, 0xb1, 0x45, 0x8e, 0xcb, 0xf4, 0x7f, 0xd1, 0xef, 0xfc, 0x7b, 0x00, 0x4f, 0x86,
+	0x69, 0x99, 0x82, 0x15, 0x00, 0x00,
 }
 
 func (x MatchType) String() string {
@@ -1694,6 +2030,14 @@ func (this *QueryResponse) Equal(that interface{}) bool {
 			return false
 		}
 	}
+	if len(this.Metadata) != len(that1.Metadata) {
+		return false
+	}
+	for i := range this.Metadata {
+		if !this.Metadata[i].Equal(that1.Metadata[i]) {
+			return false
+		}
+	}
 	return true
 }
 func (this *QueryRequest) Equal(that interface{}) bool {
@@ -2070,6 +2414,9 @@ func (this *UserStatsResponse) Equal(that interface{}) bool {
 	if this.LoadedBlocks != that1.LoadedBlocks {
 		return false
 	}
+	if this.SeriesChurn != that1.SeriesChurn {
+		return false
+	}
 	return true
 }
 func (this *UserIDStatsResponse) Equal(that interface{}) bool {
@@ -2451,45 +2798,218 @@ func (this *TimeSeriesFile) Equal(that interface{}) bool {
 	}
 	return true
 }
-func (this *ReadRequest) GoString() string {
-	if this == nil {
-		return "nil"
+func (this *TopSeriesRequest) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
 	}
-	s := make([]string, 0, 5)
-	s = append(s, "&client.ReadRequest{")
-	if this.Queries != nil {
-		s = append(s, "Queries: "+fmt.Sprintf("%#v", this.Queries)+",\n")
+
+	that1, ok := that.(*TopSeriesRequest)
+	if !ok {
+		that2, ok := that.(TopSeriesRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
 	}
-	s = append(s, "}")
-	return strings.Join(s, "")
-}
-func (this *ReadResponse) GoString() string {
-	if this == nil {
-		return "nil"
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
 	}
-	s := make([]string, 0, 5)
-	s = append(s, "&client.ReadResponse{")
-	if this.Results != nil {
-		s = append(s, "Results: "+fmt.Sprintf("%#v", this.Results)+",\n")
+	if this.Limit != that1.Limit {
+		return false
 	}
-	s = append(s, "}")
-	return strings.Join(s, "")
+	return true
 }
-func (this *QueryResponse) GoString() string {
-	if this == nil {
-		return "nil"
-	}
-	s := make([]string, 0, 5)
-	s = append(s, "&client.QueryResponse{")
-	if this.Timeseries != nil {
-		vs := make([]*cortexpb.TimeSeries, len(this.Timeseries))
-		for i := range vs {
-			vs[i] = &this.Timeseries[i]
-		}
-		s = append(s, "Timeseries: "+fmt.Sprintf("%#v", vs)+",\n")
+func (this *TopSeriesResponse) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
 	}
-	s = append(s, "}")
-	return strings.Join(s, "")
+
+	that1, ok := that.(*TopSeriesResponse)
+	if !ok {
+		that2, ok := that.(TopSeriesResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if len(this.ActiveMetricNames) != len(that1.ActiveMetricNames) {
+		return false
+	}
+	for i := range this.ActiveMetricNames {
+		if !this.ActiveMetricNames[i].Equal(&that1.ActiveMetricNames[i]) {
+			return false
+		}
+	}
+	if len(this.ActiveLabelPairs) != len(that1.ActiveLabelPairs) {
+		return false
+	}
+	for i := range this.ActiveLabelPairs {
+		if !this.ActiveLabelPairs[i].Equal(&that1.ActiveLabelPairs[i]) {
+			return false
+		}
+	}
+	if len(this.ChurnMetricNames) != len(that1.ChurnMetricNames) {
+		return false
+	}
+	for i := range this.ChurnMetricNames {
+		if !this.ChurnMetricNames[i].Equal(&that1.ChurnMetricNames[i]) {
+			return false
+		}
+	}
+	if len(this.ChurnLabelPairs) != len(that1.ChurnLabelPairs) {
+		return false
+	}
+	for i := range this.ChurnLabelPairs {
+		if !this.ChurnLabelPairs[i].Equal(&that1.ChurnLabelPairs[i]) {
+			return false
+		}
+	}
+	return true
+}
+func (this *TopSeriesEntry) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*TopSeriesEntry)
+	if !ok {
+		that2, ok := that.(TopSeriesEntry)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Name != that1.Name {
+		return false
+	}
+	if this.Value != that1.Value {
+		return false
+	}
+	if this.Count != that1.Count {
+		return false
+	}
+	return true
+}
+func (this *TransferChunksResponse) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*TransferChunksResponse)
+	if !ok {
+		that2, ok := that.(TransferChunksResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Series != that1.Series {
+		return false
+	}
+	if this.Samples != that1.Samples {
+		return false
+	}
+	if this.Histograms != that1.Histograms {
+		return false
+	}
+	if this.FailedSamples != that1.FailedSamples {
+		return false
+	}
+	if this.FailedHistograms != that1.FailedHistograms {
+		return false
+	}
+	return true
+}
+func (this *TransferTSDBResponse) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*TransferTSDBResponse)
+	if !ok {
+		that2, ok := that.(TransferTSDBResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if this.Files != that1.Files {
+		return false
+	}
+	if this.Bytes != that1.Bytes {
+		return false
+	}
+	return true
+}
+func (this *ReadRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&client.ReadRequest{")
+	if this.Queries != nil {
+		s = append(s, "Queries: "+fmt.Sprintf("%#v", this.Queries)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *ReadResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&client.ReadResponse{")
+	if this.Results != nil {
+		s = append(s, "Results: "+fmt.Sprintf("%#v", this.Results)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *QueryResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 6)
+	s = append(s, "&client.QueryResponse{")
+	if this.Timeseries != nil {
+		vs := make([]*cortexpb.TimeSeries, len(this.Timeseries))
+		for i := range vs {
+			vs[i] = &this.Timeseries[i]
+		}
+		s = append(s, "Timeseries: "+fmt.Sprintf("%#v", vs)+",\n")
+	}
+	if this.Metadata != nil {
+		s = append(s, "Metadata: "+fmt.Sprintf("%#v", this.Metadata)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
 }
 func (this *QueryRequest) GoString() string {
 	if this == nil {
@@ -2636,7 +3156,7 @@ func (this *UserStatsResponse) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 10)
+	s := make([]string, 0, 11)
 	s = append(s, "&client.UserStatsResponse{")
 	s = append(s, "IngestionRate: "+fmt.Sprintf("%#v", this.IngestionRate)+",\n")
 	s = append(s, "NumSeries: "+fmt.Sprintf("%#v", this.NumSeries)+",\n")
@@ -2644,6 +3164,7 @@ func (this *UserStatsResponse) GoString() string {
 	s = append(s, "RuleIngestionRate: "+fmt.Sprintf("%#v", this.RuleIngestionRate)+",\n")
 	s = append(s, "ActiveSeries: "+fmt.Sprintf("%#v", this.ActiveSeries)+",\n")
 	s = append(s, "LoadedBlocks: "+fmt.Sprintf("%#v", this.LoadedBlocks)+",\n")
+	s = append(s, "SeriesChurn: "+fmt.Sprintf("%#v", this.SeriesChurn)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -2804,6 +3325,90 @@ func (this *TimeSeriesFile) GoString() string {
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
+func (this *TopSeriesRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&client.TopSeriesRequest{")
+	s = append(s, "Limit: "+fmt.Sprintf("%#v", this.Limit)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *TopSeriesResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 8)
+	s = append(s, "&client.TopSeriesResponse{")
+	if this.ActiveMetricNames != nil {
+		vs := make([]*TopSeriesEntry, len(this.ActiveMetricNames))
+		for i := range vs {
+			vs[i] = &this.ActiveMetricNames[i]
+		}
+		s = append(s, "ActiveMetricNames: "+fmt.Sprintf("%#v", vs)+",\n")
+	}
+	if this.ActiveLabelPairs != nil {
+		vs := make([]*TopSeriesEntry, len(this.ActiveLabelPairs))
+		for i := range vs {
+			vs[i] = &this.ActiveLabelPairs[i]
+		}
+		s = append(s, "ActiveLabelPairs: "+fmt.Sprintf("%#v", vs)+",\n")
+	}
+	if this.ChurnMetricNames != nil {
+		vs := make([]*TopSeriesEntry, len(this.ChurnMetricNames))
+		for i := range vs {
+			vs[i] = &this.ChurnMetricNames[i]
+		}
+		s = append(s, "ChurnMetricNames: "+fmt.Sprintf("%#v", vs)+",\n")
+	}
+	if this.ChurnLabelPairs != nil {
+		vs := make([]*TopSeriesEntry, len(this.ChurnLabelPairs))
+		for i := range vs {
+			vs[i] = &this.ChurnLabelPairs[i]
+		}
+		s = append(s, "ChurnLabelPairs: "+fmt.Sprintf("%#v", vs)+",\n")
+	}
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *TopSeriesEntry) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 7)
+	s = append(s, "&client.TopSeriesEntry{")
+	s = append(s, "Name: "+fmt.Sprintf("%#v", this.Name)+",\n")
+	s = append(s, "Value: "+fmt.Sprintf("%#v", this.Value)+",\n")
+	s = append(s, "Count: "+fmt.Sprintf("%#v", this.Count)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *TransferChunksResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 9)
+	s = append(s, "&client.TransferChunksResponse{")
+	s = append(s, "Series: "+fmt.Sprintf("%#v", this.Series)+",\n")
+	s = append(s, "Samples: "+fmt.Sprintf("%#v", this.Samples)+",\n")
+	s = append(s, "Histograms: "+fmt.Sprintf("%#v", this.Histograms)+",\n")
+	s = append(s, "FailedSamples: "+fmt.Sprintf("%#v", this.FailedSamples)+",\n")
+	s = append(s, "FailedHistograms: "+fmt.Sprintf("%#v", this.FailedHistograms)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *TransferTSDBResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 6)
+	s = append(s, "&client.TransferTSDBResponse{")
+	s = append(s, "Files: "+fmt.Sprintf("%#v", this.Files)+",\n")
+	s = append(s, "Bytes: "+fmt.Sprintf("%#v", this.Bytes)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
 func valueToGoStringIngester(v interface{}, typ string) string {
 	rv := reflect.ValueOf(v)
 	if rv.IsNil() {
@@ -2838,6 +3443,9 @@ type IngesterClient interface {
 	MetricsForLabelMatchers(ctx context.Context, in *MetricsForLabelMatchersRequest, opts ...grpc.CallOption) (*MetricsForLabelMatchersResponse, error)
 	MetricsForLabelMatchersStream(ctx context.Context, in *MetricsForLabelMatchersRequest, opts ...grpc.CallOption) (Ingester_MetricsForLabelMatchersStreamClient, error)
 	MetricsMetadata(ctx context.Context, in *MetricsMetadataRequest, opts ...grpc.CallOption) (*MetricsMetadataResponse, error)
+	TopSeries(ctx context.Context, in *TopSeriesRequest, opts ...grpc.CallOption) (*TopSeriesResponse, error)
+	TransferChunks(ctx context.Context, opts ...grpc.CallOption) (Ingester_TransferChunksClient, error)
+	TransferTSDB(ctx context.Context, opts ...grpc.CallOption) (Ingester_TransferTSDBClient, error)
 }
 
 type ingesterClient struct {
@@ -3079,41 +3687,121 @@ func (c *ingesterClient) MetricsMetadata(ctx context.Context, in *MetricsMetadat
 	return out, nil
 }
 
-// IngesterServer is the server API for Ingester service.
-type IngesterServer interface {
-	Push(context.Context, *cortexpb.WriteRequest) (*cortexpb.WriteResponse, error)
-	PushStream(Ingester_PushStreamServer) error
-	QueryStream(*QueryRequest, Ingester_QueryStreamServer) error
-	QueryExemplars(context.Context, *ExemplarQueryRequest) (*ExemplarQueryResponse, error)
-	LabelValues(context.Context, *LabelValuesRequest) (*LabelValuesResponse, error)
-	LabelValuesStream(*LabelValuesRequest, Ingester_LabelValuesStreamServer) error
-	LabelNames(context.Context, *LabelNamesRequest) (*LabelNamesResponse, error)
-	LabelNamesStream(*LabelNamesRequest, Ingester_LabelNamesStreamServer) error
-	UserStats(context.Context, *UserStatsRequest) (*UserStatsResponse, error)
-	AllUserStats(context.Context, *UserStatsRequest) (*UsersStatsResponse, error)
-	MetricsForLabelMatchers(context.Context, *MetricsForLabelMatchersRequest) (*MetricsForLabelMatchersResponse, error)
-	MetricsForLabelMatchersStream(*MetricsForLabelMatchersRequest, Ingester_MetricsForLabelMatchersStreamServer) error
-	MetricsMetadata(context.Context, *MetricsMetadataRequest) (*MetricsMetadataResponse, error)
+func (c *ingesterClient) TopSeries(ctx context.Context, in *TopSeriesRequest, opts ...grpc.CallOption) (*TopSeriesResponse, error) {
+	out := new(TopSeriesResponse)
+	err := c.cc.Invoke(ctx, "/cortex.Ingester/TopSeries", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-// UnimplementedIngesterServer can be embedded to have forward compatible implementations.
-type UnimplementedIngesterServer struct {
+func (c *ingesterClient) TransferChunks(ctx context.Context, opts ...grpc.CallOption) (Ingester_TransferChunksClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Ingester_serviceDesc.Streams[5], "/cortex.Ingester/TransferChunks", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &ingesterTransferChunksClient{stream}
+	return x, nil
 }
 
-func (*UnimplementedIngesterServer) Push(ctx context.Context, req *cortexpb.WriteRequest) (*cortexpb.WriteResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Push not implemented")
+type Ingester_TransferChunksClient interface {
+	Send(*TimeSeriesChunk) error
+	CloseAndRecv() (*TransferChunksResponse, error)
+	grpc.ClientStream
 }
-func (*UnimplementedIngesterServer) PushStream(srv Ingester_PushStreamServer) error {
-	return status.Errorf(codes.Unimplemented, "method PushStream not implemented")
+
+type ingesterTransferChunksClient struct {
+	grpc.ClientStream
 }
-func (*UnimplementedIngesterServer) QueryStream(req *QueryRequest, srv Ingester_QueryStreamServer) error {
-	return status.Errorf(codes.Unimplemented, "method QueryStream not implemented")
+
+func (x *ingesterTransferChunksClient) Send(m *TimeSeriesChunk) error {
+	return x.ClientStream.SendMsg(m)
 }
-func (*UnimplementedIngesterServer) QueryExemplars(ctx context.Context, req *ExemplarQueryRequest) (*ExemplarQueryResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method QueryExemplars not implemented")
+
+func (x *ingesterTransferChunksClient) CloseAndRecv() (*TransferChunksResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(TransferChunksResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
 }
-func (*UnimplementedIngesterServer) LabelValues(ctx context.Context, req *LabelValuesRequest) (*LabelValuesResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method LabelValues not implemented")
+
+func (c *ingesterClient) TransferTSDB(ctx context.Context, opts ...grpc.CallOption) (Ingester_TransferTSDBClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Ingester_serviceDesc.Streams[6], "/cortex.Ingester/TransferTSDB", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &ingesterTransferTSDBClient{stream}
+	return x, nil
+}
+
+type Ingester_TransferTSDBClient interface {
+	Send(*TimeSeriesFile) error
+	CloseAndRecv() (*TransferTSDBResponse, error)
+	grpc.ClientStream
+}
+
+type ingesterTransferTSDBClient struct {
+	grpc.ClientStream
+}
+
+func (x *ingesterTransferTSDBClient) Send(m *TimeSeriesFile) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *ingesterTransferTSDBClient) CloseAndRecv() (*TransferTSDBResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(TransferTSDBResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// IngesterServer is the server API for Ingester service.
+type IngesterServer interface {
+	Push(context.Context, *cortexpb.WriteRequest) (*cortexpb.WriteResponse, error)
+	PushStream(Ingester_PushStreamServer) error
+	QueryStream(*QueryRequest, Ingester_QueryStreamServer) error
+	QueryExemplars(context.Context, *ExemplarQueryRequest) (*ExemplarQueryResponse, error)
+	LabelValues(context.Context, *LabelValuesRequest) (*LabelValuesResponse, error)
+	LabelValuesStream(*LabelValuesRequest, Ingester_LabelValuesStreamServer) error
+	LabelNames(context.Context, *LabelNamesRequest) (*LabelNamesResponse, error)
+	LabelNamesStream(*LabelNamesRequest, Ingester_LabelNamesStreamServer) error
+	UserStats(context.Context, *UserStatsRequest) (*UserStatsResponse, error)
+	AllUserStats(context.Context, *UserStatsRequest) (*UsersStatsResponse, error)
+	MetricsForLabelMatchers(context.Context, *MetricsForLabelMatchersRequest) (*MetricsForLabelMatchersResponse, error)
+	MetricsForLabelMatchersStream(*MetricsForLabelMatchersRequest, Ingester_MetricsForLabelMatchersStreamServer) error
+	MetricsMetadata(context.Context, *MetricsMetadataRequest) (*MetricsMetadataResponse, error)
+	TopSeries(context.Context, *TopSeriesRequest) (*TopSeriesResponse, error)
+	TransferChunks(Ingester_TransferChunksServer) error
+	TransferTSDB(Ingester_TransferTSDBServer) error
+}
+
+// UnimplementedIngesterServer can be embedded to have forward compatible implementations.
+type UnimplementedIngesterServer struct {
+}
+
+func (*UnimplementedIngesterServer) Push(ctx context.Context, req *cortexpb.WriteRequest) (*cortexpb.WriteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Push not implemented")
+}
+func (*UnimplementedIngesterServer) PushStream(srv Ingester_PushStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method PushStream not implemented")
+}
+func (*UnimplementedIngesterServer) QueryStream(req *QueryRequest, srv Ingester_QueryStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method QueryStream not implemented")
+}
+func (*UnimplementedIngesterServer) QueryExemplars(ctx context.Context, req *ExemplarQueryRequest) (*ExemplarQueryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryExemplars not implemented")
+}
+func (*UnimplementedIngesterServer) LabelValues(ctx context.Context, req *LabelValuesRequest) (*LabelValuesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LabelValues not implemented")
 }
 func (*UnimplementedIngesterServer) LabelValuesStream(req *LabelValuesRequest, srv Ingester_LabelValuesStreamServer) error {
 	return status.Errorf(codes.Unimplemented, "method LabelValuesStream not implemented")
@@ -3139,6 +3827,15 @@ func (*UnimplementedIngesterServer) MetricsForLabelMatchersStream(req *MetricsFo
 func (*UnimplementedIngesterServer) MetricsMetadata(ctx context.Context, req *MetricsMetadataRequest) (*MetricsMetadataResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method MetricsMetadata not implemented")
 }
+func (*UnimplementedIngesterServer) TopSeries(ctx context.Context, req *TopSeriesRequest) (*TopSeriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TopSeries not implemented")
+}
+func (*UnimplementedIngesterServer) TransferChunks(srv Ingester_TransferChunksServer) error {
+	return status.Errorf(codes.Unimplemented, "method TransferChunks not implemented")
+}
+func (*UnimplementedIngesterServer) TransferTSDB(srv Ingester_TransferTSDBServer) error {
+	return status.Errorf(codes.Unimplemented, "method TransferTSDB not implemented")
+}
 
 func RegisterIngesterServer(s *grpc.Server, srv IngesterServer) {
 	s.RegisterService(&_Ingester_serviceDesc, srv)
@@ -3398,6 +4095,76 @@ func _Ingester_MetricsMetadata_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Ingester_TopSeries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TopSeriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IngesterServer).TopSeries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/cortex.Ingester/TopSeries",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IngesterServer).TopSeries(ctx, req.(*TopSeriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ingester_TransferChunks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(IngesterServer).TransferChunks(&ingesterTransferChunksServer{stream})
+}
+
+type Ingester_TransferChunksServer interface {
+	SendAndClose(*TransferChunksResponse) error
+	Recv() (*TimeSeriesChunk, error)
+	grpc.ServerStream
+}
+
+type ingesterTransferChunksServer struct {
+	grpc.ServerStream
+}
+
+func (x *ingesterTransferChunksServer) SendAndClose(m *TransferChunksResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *ingesterTransferChunksServer) Recv() (*TimeSeriesChunk, error) {
+	m := new(TimeSeriesChunk)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Ingester_TransferTSDB_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(IngesterServer).TransferTSDB(&ingesterTransferTSDBServer{stream})
+}
+
+type Ingester_TransferTSDBServer interface {
+	SendAndClose(*TransferTSDBResponse) error
+	Recv() (*TimeSeriesFile, error)
+	grpc.ServerStream
+}
+
+type ingesterTransferTSDBServer struct {
+	grpc.ServerStream
+}
+
+func (x *ingesterTransferTSDBServer) SendAndClose(m *TransferTSDBResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *ingesterTransferTSDBServer) Recv() (*TimeSeriesFile, error) {
+	m := new(TimeSeriesFile)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 var _Ingester_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "cortex.Ingester",
 	HandlerType: (*IngesterServer)(nil),
@@ -3434,6 +4201,10 @@ var _Ingester_serviceDesc = grpc.ServiceDesc{
 			MethodName: "MetricsMetadata",
 			Handler:    _Ingester_MetricsMetadata_Handler,
 		},
+		{
+			MethodName: "TopSeries",
+			Handler:    _Ingester_TopSeries_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -3462,6 +4233,16 @@ var _Ingester_serviceDesc = grpc.ServiceDesc{
 			Handler:       _Ingester_MetricsForLabelMatchersStream_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "TransferChunks",
+			Handler:       _Ingester_TransferChunks_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "TransferTSDB",
+			Handler:       _Ingester_TransferTSDB_Handler,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "ingester.proto",
 }
@@ -3560,6 +4341,20 @@ func (m *QueryResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.Metadata) > 0 {
+		for iNdEx := len(m.Metadata) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Metadata[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintIngester(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x12
+		}
+	}
 	if len(m.Timeseries) > 0 {
 		for iNdEx := len(m.Timeseries) - 1; iNdEx >= 0; iNdEx-- {
 			{
@@ -4035,6 +4830,11 @@ func (m *UserStatsResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.SeriesChurn != 0 {
+		i = encodeVarintIngester(dAtA, i, uint64(m.SeriesChurn))
+		i--
+		dAtA[i] = 0x38
+	}
 	if m.LoadedBlocks != 0 {
 		i = encodeVarintIngester(dAtA, i, uint64(m.LoadedBlocks))
 		i--
@@ -4593,86 +5393,322 @@ func (m *TimeSeriesFile) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
-func encodeVarintIngester(dAtA []byte, offset int, v uint64) int {
-	offset -= sovIngester(v)
-	base := offset
-	for v >= 1<<7 {
-		dAtA[offset] = uint8(v&0x7f | 0x80)
-		v >>= 7
-		offset++
+func (m *TopSeriesRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
 	}
-	dAtA[offset] = uint8(v)
-	return base
+	return dAtA[:n], nil
 }
-func (m *ReadRequest) Size() (n int) {
-	if m == nil {
-		return 0
-	}
-	var l int
-	_ = l
-	if len(m.Queries) > 0 {
-		for _, e := range m.Queries {
-			l = e.Size()
-			n += 1 + l + sovIngester(uint64(l))
-		}
-	}
-	return n
+
+func (m *TopSeriesRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
-func (m *ReadResponse) Size() (n int) {
-	if m == nil {
-		return 0
-	}
+func (m *TopSeriesRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
 	var l int
 	_ = l
-	if len(m.Results) > 0 {
-		for _, e := range m.Results {
-			l = e.Size()
-			n += 1 + l + sovIngester(uint64(l))
-		}
+	if m.Limit != 0 {
+		i = encodeVarintIngester(dAtA, i, uint64(m.Limit))
+		i--
+		dAtA[i] = 0x8
 	}
-	return n
+	return len(dAtA) - i, nil
 }
 
-func (m *QueryResponse) Size() (n int) {
-	if m == nil {
-		return 0
-	}
-	var l int
-	_ = l
-	if len(m.Timeseries) > 0 {
-		for _, e := range m.Timeseries {
-			l = e.Size()
-			n += 1 + l + sovIngester(uint64(l))
-		}
+func (m *TopSeriesResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
 	}
-	return n
+	return dAtA[:n], nil
 }
 
-func (m *QueryRequest) Size() (n int) {
-	if m == nil {
-		return 0
-	}
+func (m *TopSeriesResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *TopSeriesResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
 	var l int
 	_ = l
-	if m.StartTimestampMs != 0 {
-		n += 1 + sovIngester(uint64(m.StartTimestampMs))
+	if len(m.ChurnLabelPairs) > 0 {
+		for iNdEx := len(m.ChurnLabelPairs) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.ChurnLabelPairs[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintIngester(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x22
+		}
 	}
-	if m.EndTimestampMs != 0 {
-		n += 1 + sovIngester(uint64(m.EndTimestampMs))
+	if len(m.ChurnMetricNames) > 0 {
+		for iNdEx := len(m.ChurnMetricNames) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.ChurnMetricNames[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintIngester(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x1a
+		}
 	}
-	if len(m.Matchers) > 0 {
-		for _, e := range m.Matchers {
-			l = e.Size()
-			n += 1 + l + sovIngester(uint64(l))
+	if len(m.ActiveLabelPairs) > 0 {
+		for iNdEx := len(m.ActiveLabelPairs) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.ActiveLabelPairs[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintIngester(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x12
 		}
 	}
-	l = m.MessageWithBufRef.Size()
-	n += 2 + l + sovIngester(uint64(l))
-	return n
+	if len(m.ActiveMetricNames) > 0 {
+		for iNdEx := len(m.ActiveMetricNames) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.ActiveMetricNames[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintIngester(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
 }
 
-func (m *ExemplarQueryRequest) Size() (n int) {
+func (m *TopSeriesEntry) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *TopSeriesEntry) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *TopSeriesEntry) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Count != 0 {
+		i = encodeVarintIngester(dAtA, i, uint64(m.Count))
+		i--
+		dAtA[i] = 0x18
+	}
+	if len(m.Value) > 0 {
+		i -= len(m.Value)
+		copy(dAtA[i:], m.Value)
+		i = encodeVarintIngester(dAtA, i, uint64(len(m.Value)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Name) > 0 {
+		i -= len(m.Name)
+		copy(dAtA[i:], m.Name)
+		i = encodeVarintIngester(dAtA, i, uint64(len(m.Name)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *TransferChunksResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *TransferChunksResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *TransferChunksResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.FailedHistograms != 0 {
+		i = encodeVarintIngester(dAtA, i, uint64(m.FailedHistograms))
+		i--
+		dAtA[i] = 0x28
+	}
+	if m.FailedSamples != 0 {
+		i = encodeVarintIngester(dAtA, i, uint64(m.FailedSamples))
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.Histograms != 0 {
+		i = encodeVarintIngester(dAtA, i, uint64(m.Histograms))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.Samples != 0 {
+		i = encodeVarintIngester(dAtA, i, uint64(m.Samples))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.Series != 0 {
+		i = encodeVarintIngester(dAtA, i, uint64(m.Series))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *TransferTSDBResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *TransferTSDBResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *TransferTSDBResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Bytes != 0 {
+		i = encodeVarintIngester(dAtA, i, uint64(m.Bytes))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.Files != 0 {
+		i = encodeVarintIngester(dAtA, i, uint64(m.Files))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintIngester(dAtA []byte, offset int, v uint64) int {
+	offset -= sovIngester(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *ReadRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Queries) > 0 {
+		for _, e := range m.Queries {
+			l = e.Size()
+			n += 1 + l + sovIngester(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *ReadResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Results) > 0 {
+		for _, e := range m.Results {
+			l = e.Size()
+			n += 1 + l + sovIngester(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *QueryResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Timeseries) > 0 {
+		for _, e := range m.Timeseries {
+			l = e.Size()
+			n += 1 + l + sovIngester(uint64(l))
+		}
+	}
+	if len(m.Metadata) > 0 {
+		for _, e := range m.Metadata {
+			l = e.Size()
+			n += 1 + l + sovIngester(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *QueryRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.StartTimestampMs != 0 {
+		n += 1 + sovIngester(uint64(m.StartTimestampMs))
+	}
+	if m.EndTimestampMs != 0 {
+		n += 1 + sovIngester(uint64(m.EndTimestampMs))
+	}
+	if len(m.Matchers) > 0 {
+		for _, e := range m.Matchers {
+			l = e.Size()
+			n += 1 + l + sovIngester(uint64(l))
+		}
+	}
+	l = m.MessageWithBufRef.Size()
+	n += 2 + l + sovIngester(uint64(l))
+	return n
+}
+
+func (m *ExemplarQueryRequest) Size() (n int) {
 	if m == nil {
 		return 0
 	}
@@ -4864,6 +5900,9 @@ func (m *UserStatsResponse) Size() (n int) {
 	if m.LoadedBlocks != 0 {
 		n += 1 + sovIngester(uint64(m.LoadedBlocks))
 	}
+	if m.SeriesChurn != 0 {
+		n += 1 + sovIngester(uint64(m.SeriesChurn))
+	}
 	return n
 }
 
@@ -5098,60 +6137,170 @@ func (m *TimeSeriesFile) Size() (n int) {
 	return n
 }
 
-func sovIngester(x uint64) (n int) {
-	return (math_bits.Len64(x|1) + 6) / 7
-}
-func sozIngester(x uint64) (n int) {
-	return sovIngester(uint64((x << 1) ^ uint64((int64(x) >> 63))))
-}
-func (this *ReadRequest) String() string {
-	if this == nil {
-		return "nil"
+func (m *TopSeriesRequest) Size() (n int) {
+	if m == nil {
+		return 0
 	}
-	repeatedStringForQueries := "[]*QueryRequest{"
-	for _, f := range this.Queries {
-		repeatedStringForQueries += strings.Replace(f.String(), "QueryRequest", "QueryRequest", 1) + ","
+	var l int
+	_ = l
+	if m.Limit != 0 {
+		n += 1 + sovIngester(uint64(m.Limit))
 	}
-	repeatedStringForQueries += "}"
-	s := strings.Join([]string{`&ReadRequest{`,
-		`Queries:` + repeatedStringForQueries + `,`,
-		`}`,
-	}, "")
-	return s
+	return n
 }
-func (this *ReadResponse) String() string {
-	if this == nil {
-		return "nil"
+
+func (m *TopSeriesResponse) Size() (n int) {
+	if m == nil {
+		return 0
 	}
-	repeatedStringForResults := "[]*QueryResponse{"
-	for _, f := range this.Results {
-		repeatedStringForResults += strings.Replace(f.String(), "QueryResponse", "QueryResponse", 1) + ","
+	var l int
+	_ = l
+	if len(m.ActiveMetricNames) > 0 {
+		for _, e := range m.ActiveMetricNames {
+			l = e.Size()
+			n += 1 + l + sovIngester(uint64(l))
+		}
 	}
-	repeatedStringForResults += "}"
-	s := strings.Join([]string{`&ReadResponse{`,
-		`Results:` + repeatedStringForResults + `,`,
-		`}`,
-	}, "")
-	return s
-}
-func (this *QueryResponse) String() string {
-	if this == nil {
-		return "nil"
+	if len(m.ActiveLabelPairs) > 0 {
+		for _, e := range m.ActiveLabelPairs {
+			l = e.Size()
+			n += 1 + l + sovIngester(uint64(l))
+		}
 	}
-	repeatedStringForTimeseries := "[]TimeSeries{"
-	for _, f := range this.Timeseries {
-		repeatedStringForTimeseries += fmt.Sprintf("%v", f) + ","
+	if len(m.ChurnMetricNames) > 0 {
+		for _, e := range m.ChurnMetricNames {
+			l = e.Size()
+			n += 1 + l + sovIngester(uint64(l))
+		}
 	}
-	repeatedStringForTimeseries += "}"
-	s := strings.Join([]string{`&QueryResponse{`,
-		`Timeseries:` + repeatedStringForTimeseries + `,`,
-		`}`,
-	}, "")
-	return s
-}
-func (this *QueryRequest) String() string {
-	if this == nil {
-		return "nil"
+	if len(m.ChurnLabelPairs) > 0 {
+		for _, e := range m.ChurnLabelPairs {
+			l = e.Size()
+			n += 1 + l + sovIngester(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *TopSeriesEntry) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovIngester(uint64(l))
+	}
+	l = len(m.Value)
+	if l > 0 {
+		n += 1 + l + sovIngester(uint64(l))
+	}
+	if m.Count != 0 {
+		n += 1 + sovIngester(uint64(m.Count))
+	}
+	return n
+}
+
+func (m *TransferChunksResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Series != 0 {
+		n += 1 + sovIngester(uint64(m.Series))
+	}
+	if m.Samples != 0 {
+		n += 1 + sovIngester(uint64(m.Samples))
+	}
+	if m.Histograms != 0 {
+		n += 1 + sovIngester(uint64(m.Histograms))
+	}
+	if m.FailedSamples != 0 {
+		n += 1 + sovIngester(uint64(m.FailedSamples))
+	}
+	if m.FailedHistograms != 0 {
+		n += 1 + sovIngester(uint64(m.FailedHistograms))
+	}
+	return n
+}
+
+func (m *TransferTSDBResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Files != 0 {
+		n += 1 + sovIngester(uint64(m.Files))
+	}
+	if m.Bytes != 0 {
+		n += 1 + sovIngester(uint64(m.Bytes))
+	}
+	return n
+}
+
+func sovIngester(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozIngester(x uint64) (n int) {
+	return sovIngester(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (this *ReadRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	repeatedStringForQueries := "[]*QueryRequest{"
+	for _, f := range this.Queries {
+		repeatedStringForQueries += strings.Replace(f.String(), "QueryRequest", "QueryRequest", 1) + ","
+	}
+	repeatedStringForQueries += "}"
+	s := strings.Join([]string{`&ReadRequest{`,
+		`Queries:` + repeatedStringForQueries + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *ReadResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	repeatedStringForResults := "[]*QueryResponse{"
+	for _, f := range this.Results {
+		repeatedStringForResults += strings.Replace(f.String(), "QueryResponse", "QueryResponse", 1) + ","
+	}
+	repeatedStringForResults += "}"
+	s := strings.Join([]string{`&ReadResponse{`,
+		`Results:` + repeatedStringForResults + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *QueryResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	repeatedStringForTimeseries := "[]TimeSeries{"
+	for _, f := range this.Timeseries {
+		repeatedStringForTimeseries += fmt.Sprintf("%v", f) + ","
+	}
+	repeatedStringForTimeseries += "}"
+	repeatedStringForMetadata := "[]*MetricMetadata{"
+	for _, f := range this.Metadata {
+		repeatedStringForMetadata += strings.Replace(fmt.Sprintf("%v", f), "MetricMetadata", "cortexpb.MetricMetadata", 1) + ","
+	}
+	repeatedStringForMetadata += "}"
+	s := strings.Join([]string{`&QueryResponse{`,
+		`Timeseries:` + repeatedStringForTimeseries + `,`,
+		`Metadata:` + repeatedStringForMetadata + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *QueryRequest) String() string {
+	if this == nil {
+		return "nil"
 	}
 	repeatedStringForMatchers := "[]*LabelMatcher{"
 	for _, f := range this.Matchers {
@@ -5301,6 +6450,7 @@ func (this *UserStatsResponse) String() string {
 		`RuleIngestionRate:` + fmt.Sprintf("%v", this.RuleIngestionRate) + `,`,
 		`ActiveSeries:` + fmt.Sprintf("%v", this.ActiveSeries) + `,`,
 		`LoadedBlocks:` + fmt.Sprintf("%v", this.LoadedBlocks) + `,`,
+		`SeriesChurn:` + fmt.Sprintf("%v", this.SeriesChurn) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -5477,6 +6627,86 @@ func (this *TimeSeriesFile) String() string {
 	}, "")
 	return s
 }
+func (this *TopSeriesRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&TopSeriesRequest{`,
+		`Limit:` + fmt.Sprintf("%v", this.Limit) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *TopSeriesResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	repeatedStringForActiveMetricNames := "[]TopSeriesEntry{"
+	for _, f := range this.ActiveMetricNames {
+		repeatedStringForActiveMetricNames += strings.Replace(strings.Replace(f.String(), "TopSeriesEntry", "TopSeriesEntry", 1), `&`, ``, 1) + ","
+	}
+	repeatedStringForActiveMetricNames += "}"
+	repeatedStringForActiveLabelPairs := "[]TopSeriesEntry{"
+	for _, f := range this.ActiveLabelPairs {
+		repeatedStringForActiveLabelPairs += strings.Replace(strings.Replace(f.String(), "TopSeriesEntry", "TopSeriesEntry", 1), `&`, ``, 1) + ","
+	}
+	repeatedStringForActiveLabelPairs += "}"
+	repeatedStringForChurnMetricNames := "[]TopSeriesEntry{"
+	for _, f := range this.ChurnMetricNames {
+		repeatedStringForChurnMetricNames += strings.Replace(strings.Replace(f.String(), "TopSeriesEntry", "TopSeriesEntry", 1), `&`, ``, 1) + ","
+	}
+	repeatedStringForChurnMetricNames += "}"
+	repeatedStringForChurnLabelPairs := "[]TopSeriesEntry{"
+	for _, f := range this.ChurnLabelPairs {
+		repeatedStringForChurnLabelPairs += strings.Replace(strings.Replace(f.String(), "TopSeriesEntry", "TopSeriesEntry", 1), `&`, ``, 1) + ","
+	}
+	repeatedStringForChurnLabelPairs += "}"
+	s := strings.Join([]string{`&TopSeriesResponse{`,
+		`ActiveMetricNames:` + repeatedStringForActiveMetricNames + `,`,
+		`ActiveLabelPairs:` + repeatedStringForActiveLabelPairs + `,`,
+		`ChurnMetricNames:` + repeatedStringForChurnMetricNames + `,`,
+		`ChurnLabelPairs:` + repeatedStringForChurnLabelPairs + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *TopSeriesEntry) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&TopSeriesEntry{`,
+		`Name:` + fmt.Sprintf("%v", this.Name) + `,`,
+		`Value:` + fmt.Sprintf("%v", this.Value) + `,`,
+		`Count:` + fmt.Sprintf("%v", this.Count) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *TransferChunksResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&TransferChunksResponse{`,
+		`Series:` + fmt.Sprintf("%v", this.Series) + `,`,
+		`Samples:` + fmt.Sprintf("%v", this.Samples) + `,`,
+		`Histograms:` + fmt.Sprintf("%v", this.Histograms) + `,`,
+		`FailedSamples:` + fmt.Sprintf("%v", this.FailedSamples) + `,`,
+		`FailedHistograms:` + fmt.Sprintf("%v", this.FailedHistograms) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *TransferTSDBResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&TransferTSDBResponse{`,
+		`Files:` + fmt.Sprintf("%v", this.Files) + `,`,
+		`Bytes:` + fmt.Sprintf("%v", this.Bytes) + `,`,
+		`}`,
+	}, "")
+	return s
+}
 func valueToStringIngester(v interface{}) string {
 	rv := reflect.ValueOf(v)
 	if rv.IsNil() {
@@ -5722,6 +6952,40 @@ func (m *QueryResponse) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Metadata", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthIngester
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Metadata = append(m.Metadata, &cortexpb.MetricMetadata{})
+			if err := m.Metadata[len(m.Metadata)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipIngester(dAtA[iNdEx:])
@@ -7039,6 +8303,25 @@ func (m *UserStatsResponse) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SeriesChurn", wireType)
+			}
+			m.SeriesChurn = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.SeriesChurn |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipIngester(dAtA[iNdEx:])
@@ -8534,6 +9817,642 @@ func (m *TimeSeriesFile) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
+func (m *TopSeriesRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIngester
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TopSeriesRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TopSeriesRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Limit", wireType)
+			}
+			m.Limit = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Limit |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIngester(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *TopSeriesResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIngester
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TopSeriesResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TopSeriesResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ActiveMetricNames", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthIngester
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ActiveMetricNames = append(m.ActiveMetricNames, TopSeriesEntry{})
+			if err := m.ActiveMetricNames[len(m.ActiveMetricNames)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ActiveLabelPairs", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthIngester
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ActiveLabelPairs = append(m.ActiveLabelPairs, TopSeriesEntry{})
+			if err := m.ActiveLabelPairs[len(m.ActiveLabelPairs)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ChurnMetricNames", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthIngester
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ChurnMetricNames = append(m.ChurnMetricNames, TopSeriesEntry{})
+			if err := m.ChurnMetricNames[len(m.ChurnMetricNames)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ChurnLabelPairs", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthIngester
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ChurnLabelPairs = append(m.ChurnLabelPairs, TopSeriesEntry{})
+			if err := m.ChurnLabelPairs[len(m.ChurnLabelPairs)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIngester(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *TopSeriesEntry) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIngester
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TopSeriesEntry: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TopSeriesEntry: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthIngester
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthIngester
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Value = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Count", wireType)
+			}
+			m.Count = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Count |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIngester(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *TransferChunksResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIngester
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TransferChunksResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TransferChunksResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Series", wireType)
+			}
+			m.Series = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Series |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Samples", wireType)
+			}
+			m.Samples = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Samples |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Histograms", wireType)
+			}
+			m.Histograms = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Histograms |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FailedSamples", wireType)
+			}
+			m.FailedSamples = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.FailedSamples |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FailedHistograms", wireType)
+			}
+			m.FailedHistograms = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.FailedHistograms |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIngester(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *TransferTSDBResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIngester
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TransferTSDBResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TransferTSDBResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Files", wireType)
+			}
+			m.Files = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Files |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Bytes", wireType)
+			}
+			m.Bytes = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIngester
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Bytes |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIngester(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) < 0 {
+				return ErrInvalidLengthIngester
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func skipIngester(dAtA []byte) (n int, err error) {
 	l := len(dAtA)
 	iNdEx := 0
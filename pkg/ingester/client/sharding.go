@@ -0,0 +1,48 @@
+package client
+
+import (
+	"github.com/cortexproject/cortex/pkg/cortexpb"
+	"github.com/cortexproject/cortex/pkg/util/extract"
+)
+
+// TokenForLabels returns the token of the series used to find its ingesters in the ring. The series
+// is sharded by all its labels if shardByAllLabels is true, otherwise by its metric name.
+func TokenForLabels(userID string, labels []cortexpb.LabelAdapter, shardByAllLabels bool) (uint32, error) {
+	if shardByAllLabels {
+		return ShardByAllLabels(userID, labels), nil
+	}
+
+	unsafeMetricName, err := extract.UnsafeMetricNameFromLabelAdapters(labels)
+	if err != nil {
+		return 0, err
+	}
+	return ShardByMetricName(userID, unsafeMetricName), nil
+}
+
+// ShardByMetricName returns the token for the given metric. The provided metricName
+// is guaranteed to not be retained.
+func ShardByMetricName(userID string, metricName string) uint32 {
+	h := ShardByUser(userID)
+	h = HashAdd32(h, metricName)
+	return h
+}
+
+// ShardByUser returns the token for the given user.
+func ShardByUser(userID string) uint32 {
+	h := HashNew32()
+	h = HashAdd32(h, userID)
+	return h
+}
+
+// ShardByAllLabels returns the token for the given labels.
+// This function generates different values for different order of same labels.
+func ShardByAllLabels(userID string, labels []cortexpb.LabelAdapter) uint32 {
+	h := ShardByUser(userID)
+	for _, label := range labels {
+		if len(label.Value) > 0 {
+			h = HashAdd32(h, label.Name)
+			h = HashAdd32(h, label.Value)
+		}
+	}
+	return h
+}
@@ -0,0 +1,42 @@
+package client
+
+import (
+	"strconv"
+
+	"google.golang.org/grpc/metadata"
+
+	"github.com/cortexproject/cortex/pkg/cortexpb"
+)
+
+const (
+	cpuUtilizationTrailer  = "cortex-cpu-utilization"
+	heapUtilizationTrailer = "cortex-heap-utilization"
+)
+
+// UtilizationTrailer returns the gRPC trailer reporting the utilization of the ingester resources
+// along with a failed push, whose response isn't sent to the client.
+func UtilizationTrailer(resp *cortexpb.WriteResponse) metadata.MD {
+	return metadata.Pairs(
+		cpuUtilizationTrailer, strconv.FormatFloat(resp.CpuUtilization, 'g', -1, 64),
+		heapUtilizationTrailer, strconv.FormatFloat(resp.HeapUtilization, 'g', -1, 64),
+	)
+}
+
+// UtilizationFromTrailer returns a response holding the utilization reported in the given gRPC
+// trailer, or nil if the trailer doesn't report it.
+func UtilizationFromTrailer(md metadata.MD) *cortexpb.WriteResponse {
+	cpu, cpuErr := parseUtilization(md, cpuUtilizationTrailer)
+	heap, heapErr := parseUtilization(md, heapUtilizationTrailer)
+	if cpuErr != nil || heapErr != nil {
+		return nil
+	}
+	return &cortexpb.WriteResponse{CpuUtilization: cpu, HeapUtilization: heap}
+}
+
+func parseUtilization(md metadata.MD, key string) (float64, error) {
+	values := md.Get(key)
+	if len(values) == 0 {
+		return 0, strconv.ErrSyntax
+	}
+	return strconv.ParseFloat(values[0], 64)
+}
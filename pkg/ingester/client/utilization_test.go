@@ -0,0 +1,78 @@
+package client
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/httpgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/cortexproject/cortex/pkg/cortexpb"
+)
+
+type utilizationIngesterServer struct {
+	UnimplementedIngesterServer
+}
+
+func (*utilizationIngesterServer) Push(ctx context.Context, _ *cortexpb.WriteRequest) (*cortexpb.WriteResponse, error) {
+	if err := grpc.SetTrailer(ctx, UtilizationTrailer(&cortexpb.WriteResponse{CpuUtilization: 0.75, HeapUtilization: 0.5})); err != nil {
+		return nil, err
+	}
+	return nil, httpgrpc.Errorf(500, "push failed")
+}
+
+func TestUtilizationTrailer_ShouldBeReceivedAlongWithAFailedPush(t *testing.T) {
+	listen := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	RegisterIngesterServer(server, &utilizationIngesterServer{})
+	go func() {
+		_ = server.Serve(listen)
+	}()
+	defer server.Stop()
+
+	bufDialer := func(context.Context, string) (net.Conn, error) {
+		return listen.Dial()
+	}
+	conn, err := grpc.NewClient("passthrough://bufnet", grpc.WithContextDialer(bufDialer), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	var trailer metadata.MD
+	_, err = NewIngesterClient(conn).Push(context.Background(), &cortexpb.WriteRequest{}, grpc.Trailer(&trailer))
+	require.Error(t, err)
+
+	assert.Equal(t, &cortexpb.WriteResponse{CpuUtilization: 0.75, HeapUtilization: 0.5}, UtilizationFromTrailer(trailer))
+}
+
+func TestUtilizationFromTrailer(t *testing.T) {
+	tests := map[string]struct {
+		trailer  metadata.MD
+		expected *cortexpb.WriteResponse
+	}{
+		"empty trailer": {
+			trailer: metadata.MD{},
+		},
+		"missing heap utilization": {
+			trailer: metadata.Pairs(cpuUtilizationTrailer, "0.5"),
+		},
+		"invalid cpu utilization": {
+			trailer: metadata.Pairs(cpuUtilizationTrailer, "foo", heapUtilizationTrailer, "0.5"),
+		},
+		"valid utilization": {
+			trailer:  metadata.Pairs(cpuUtilizationTrailer, "0.25", heapUtilizationTrailer, "0.5"),
+			expected: &cortexpb.WriteResponse{CpuUtilization: 0.25, HeapUtilization: 0.5},
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			assert.Equal(t, testData.expected, UtilizationFromTrailer(testData.trailer))
+		})
+	}
+}
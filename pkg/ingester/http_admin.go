@@ -36,6 +36,7 @@ const tpl = `
 						<th>Loaded Blocks</th>
 						<th># Series</th>
 						<th># Active Series</th>
+						<th># Series Churn</th>
 						<th>Total Ingest Rate</th>
 						<th>API Ingest Rate</th>
 						<th>Rule Ingest Rate</th>
@@ -49,6 +50,7 @@ const tpl = `
 						<td align='right'>{{ .UserStats.LoadedBlocks }}</td>
 						<td align='right'>{{ .UserStats.NumSeries }}</td>
 						<td align='right'>{{ .UserStats.ActiveSeries }}</td>
+						<td align='right'>{{ .UserStats.SeriesChurn }}</td>
 						<td align='right'>{{ printf "%.2f" .UserStats.IngestionRate }}</td>
 						<td align='right'>{{ printf "%.2f" .UserStats.APIIngestionRate }}</td>
 						<td align='right'>{{ printf "%.2f" .UserStats.RuleIngestionRate }}</td>
@@ -92,6 +94,7 @@ type UserStats struct {
 	ActiveSeries      uint64  `json:"activeSeries"`
 	LoadedBlocks      uint64  `json:"loadedBlocks"`
 	QueriedIngesters  uint64  `json:"queriedIngesters"`
+	SeriesChurn       uint64  `json:"seriesChurn"`
 }
 
 // AllUserStatsRender render data for all users or return in json format.
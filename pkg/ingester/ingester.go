@@ -21,6 +21,7 @@ import (
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/gogo/status"
+	"github.com/gorilla/mux"
 	"github.com/oklog/ulid/v2"
 	"github.com/opentracing/opentracing-go"
 	"github.com/pkg/errors"
@@ -31,10 +32,12 @@ import (
 	"github.com/prometheus/prometheus/model/exemplar"
 	"github.com/prometheus/prometheus/model/histogram"
 	"github.com/prometheus/prometheus/model/labels"
+	prom_metadata "github.com/prometheus/prometheus/model/metadata"
 	"github.com/prometheus/prometheus/storage"
 	"github.com/prometheus/prometheus/tsdb"
 	"github.com/prometheus/prometheus/tsdb/chunkenc"
 	"github.com/prometheus/prometheus/tsdb/chunks"
+	tsdb_errors "github.com/prometheus/prometheus/tsdb/errors"
 	"github.com/prometheus/prometheus/util/compression"
 	"github.com/prometheus/prometheus/util/zeropool"
 	"github.com/thanos-io/objstore"
@@ -46,6 +49,7 @@ import (
 	"github.com/weaveworks/common/user"
 	"go.uber.org/atomic"
 	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 
 	"github.com/cortexproject/cortex/pkg/chunk/encoding"
@@ -59,6 +63,7 @@ import (
 	"github.com/cortexproject/cortex/pkg/storage/tsdb/bucketindex"
 	"github.com/cortexproject/cortex/pkg/util"
 	"github.com/cortexproject/cortex/pkg/util/concurrency"
+	"github.com/cortexproject/cortex/pkg/util/discardedseries"
 	"github.com/cortexproject/cortex/pkg/util/extract"
 	"github.com/cortexproject/cortex/pkg/util/flagext"
 	"github.com/cortexproject/cortex/pkg/util/limiter"
@@ -75,6 +80,9 @@ import (
 const (
 	// RingKey is the key under which we store the ingesters ring in the KVStore.
 	RingKey = "ring"
+
+	// PartitionRingKey is the key under which we store the ingesters partition ring in the KVStore.
+	PartitionRingKey = "partition-ring"
 )
 
 const (
@@ -103,6 +111,11 @@ const (
 	maxInflightRequestResetPeriod = 1 * time.Minute
 
 	labelSetMetricsTickInterval = 30 * time.Second
+
+	// Period at which to purge the sample deduplication state of idle series, and the minimum
+	// time the state of a series is retained after its last accepted sample.
+	sampleDedupPurgePeriod     = time.Minute
+	sampleDedupMinIdleDuration = 5 * time.Minute
 )
 
 var (
@@ -136,6 +149,10 @@ type Config struct {
 	ActiveQueriedSeriesMetricsSampleRate     float64                  `yaml:"active_queried_series_metrics_sample_rate"`
 	ActiveQueriedSeriesMetricsWindows        cortex_tsdb.DurationList `yaml:"active_queried_series_metrics_windows"`
 
+	TopSeriesEnabled     bool          `yaml:"top_series_enabled"`
+	TopSeriesLimit       int           `yaml:"top_series_limit"`
+	TopSeriesChurnWindow time.Duration `yaml:"top_series_churn_window"`
+
 	// Use blocks storage.
 	BlocksStorageConfig cortex_tsdb.BlocksStorageConfig `yaml:"-"`
 
@@ -147,6 +164,13 @@ type Config struct {
 	DistributorShardingStrategy string `yaml:"-"`
 	DistributorShardByAllLabels bool   `yaml:"-"`
 
+	// Injected at runtime and read from the ingester client config, required
+	// to hand off the head series to other ingesters on shutdown.
+	IngesterClientConfig client.Config `yaml:"-"`
+
+	HandoffOnShutdown bool          `yaml:"handoff_on_shutdown"`
+	HandoffTimeout    time.Duration `yaml:"handoff_timeout"`
+
 	DefaultLimits    InstanceLimits         `yaml:"instance_limits"`
 	InstanceLimitsFn func() *InstanceLimits `yaml:"-"`
 
@@ -202,6 +226,13 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	cfg.ActiveQueriedSeriesMetricsWindows = cortex_tsdb.DurationList{2 * time.Hour}
 	f.Var(&cfg.ActiveQueriedSeriesMetricsWindows, "ingester.active-queried-series-metrics-windows", "Time windows to expose queried series metric. Each window tracks queried series within that time period.")
 
+	f.BoolVar(&cfg.TopSeriesEnabled, "ingester.top-series-enabled", false, "Experimental: Enable tracking of the top metric names and label pairs per tenant, by number of active series and by series churn. The top entries are estimated using sketches and exposed by the /ingester/tenant/{tenant}/top_series endpoint. Tracking by active series requires -ingester.active-series-metrics-enabled.")
+	f.IntVar(&cfg.TopSeriesLimit, "ingester.top-series-limit", 20, "Maximum number of top metric names and label pairs returned per tenant for each category.")
+	f.DurationVar(&cfg.TopSeriesChurnWindow, "ingester.top-series-churn-window", time.Hour, "Sliding window over which series churn is measured, as the number of series created in the ingester.")
+
+	f.BoolVar(&cfg.HandoffOnShutdown, "ingester.handoff-on-shutdown", false, "Experimental: When the ingester is required to flush on shutdown, first try to hand off its data to other ingesters, and only flush it if the hand-off fails. If an ingester is waiting in the PENDING state to replace this one (see -ingester.join-after), the TSDB of each tenant, WAL included, is transferred to it and it takes over the tokens. Otherwise, the head series are transferred to the ingesters taking over the series tokens: this requires the out-of-order time window (see -ingester.out-of-order-time-window) to cover the time range of the head, because the receiving ingesters get new samples for the transferred series while the transfer is in progress, so the default out-of-order time window must be at least 1.5 times the smallest TSDB block range. The ingester falls back to flush for the tenants whose out-of-order time window override is shorter than their head. Not supported with the partition ring.")
+	f.DurationVar(&cfg.HandoffTimeout, "ingester.handoff-timeout", 10*time.Minute, "Maximum time to hand off the data to the other ingesters on shutdown, before falling back to flush.")
+
 	f.BoolVar(&cfg.UploadCompactedBlocksEnabled, "ingester.upload-compacted-blocks-enabled", true, "Enable uploading compacted blocks.")
 	f.StringVar(&cfg.IgnoreSeriesLimitForMetricNames, "ingester.ignore-series-limit-for-metric-names", "", "Comma-separated list of metric names, for which -ingester.max-series-per-metric and -ingester.max-global-series-per-metric limits will be ignored. Does not affect max-series-per-user or max-global-series-per-metric limits.")
 	f.StringVar(&cfg.AdminLimitMessage, "ingester.admin-limit-message", "please contact administrator to raise it", "Customize the message contained in limit errors")
@@ -216,7 +247,7 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	cfg.QueryProtection.RegisterFlagsWithPrefix(f, "ingester.")
 }
 
-func (cfg *Config) Validate(monitoredResources flagext.StringSliceCSV) error {
+func (cfg *Config) Validate(monitoredResources flagext.StringSliceCSV, limits validation.Limits, tsdbCfg cortex_tsdb.TSDBConfig) error {
 	if err := cfg.LifecyclerConfig.Validate(); err != nil {
 		return err
 	}
@@ -260,6 +291,36 @@ func (cfg *Config) Validate(monitoredResources flagext.StringSliceCSV) error {
 		}
 	}
 
+	if cfg.HandoffOnShutdown {
+		logutil.WarnExperimentalUse("Ingester hand-off on shutdown")
+
+		if cfg.HandoffTimeout <= 0 {
+			return fmt.Errorf("ingester hand-off timeout must be > 0, got %v", cfg.HandoffTimeout)
+		}
+
+		// The series are owned by the partitions, whose other instances already hold the series of a leaving ingester.
+		if cfg.LifecyclerConfig.RingConfig.PartitionRing.Enabled {
+			return errors.New("ingester hand-off on shutdown is not supported when the partition ring is enabled")
+		}
+
+		// The receivers of the head series append the samples older than their own head through the
+		// out-of-order head, and the head is compacted once it spans 1.5 times the smallest block range.
+		if len(tsdbCfg.BlockRanges) > 0 {
+			if minWindow := tsdbCfg.BlockRanges[0] * 3 / 2; time.Duration(limits.OutOfOrderTimeWindow) < minWindow {
+				return fmt.Errorf("ingester hand-off on shutdown requires the out-of-order time window to be at least 1.5 times the smallest TSDB block range (%v), got %v", minWindow, time.Duration(limits.OutOfOrderTimeWindow))
+			}
+		}
+	}
+
+	if cfg.TopSeriesEnabled {
+		if cfg.TopSeriesLimit <= 0 {
+			return fmt.Errorf("top series limit must be > 0, got %v", cfg.TopSeriesLimit)
+		}
+		if cfg.TopSeriesChurnWindow <= 0 {
+			return fmt.Errorf("top series churn window must be > 0, got %v", cfg.TopSeriesChurnWindow)
+		}
+	}
+
 	return nil
 }
 
@@ -294,13 +355,21 @@ type Ingester struct {
 	metrics         *ingesterMetrics
 	validateMetrics *validation.ValidateMetrics
 
+	// For sampling the discarded series of the tenants, nil in the flusher.
+	discardedSeriesSampler *discardedseries.Sampler
+
 	logger log.Logger
 
 	lifecycler           *ring.Lifecycler
+	partitionLifecycler  *ring.PartitionLifecycler
 	limits               *validation.Overrides
 	limiter              *Limiter
 	resourceBasedLimiter *limiter.ResourceBasedLimiter
-	subservicesWatcher   *services.FailureWatcher
+
+	// Reports the utilization of the resources in the push responses, nil if not monitored.
+	resourceMonitor resource.IMonitor
+
+	subservicesWatcher *services.FailureWatcher
 
 	stoppedMtx sync.RWMutex // protects stopped
 	stopped    bool         // protected by stoppedMtx
@@ -314,6 +383,7 @@ type Ingester struct {
 
 	// Rate of pushed samples. Only used by V2-ingester to limit global samples push rate.
 	ingestionRate           *util_math.EwmaRate
+	appendLatency           *latencyTracker
 	inflightPushRequests    atomic.Int64
 	maxInflightPushRequests util_math.MaxTracker
 
@@ -411,6 +481,15 @@ type userTSDB struct {
 
 	// Tracks active series per configured tracker pattern.
 	trackerCounter *trackerCounter
+
+	// Tracks the top metric names and label pairs. Nil if disabled.
+	topSeries *topSeriesTracker
+
+	// Counts the series created within the series churn window. Nil until the WAL has been replayed.
+	seriesChurn *seriesChurnCounter
+
+	// Deduplicates the samples of HA pairs without replica labels.
+	sampleDedup *sampleDeduplicator
 }
 
 // Explicitly wrapping the tsdb.DB functions that we use.
@@ -516,6 +595,13 @@ func (u *userTSDB) PreCreation(metric labels.Labels) error {
 		return err
 	}
 
+	// Series churn limit.
+	if u.seriesChurn != nil {
+		if err := u.limiter.AssertMaxSeriesChurnPerUser(u.userID, u.seriesChurn.count(time.Now())); err != nil {
+			return err
+		}
+	}
+
 	// Total native histogram series limit.
 	if err := u.limiter.AssertMaxNativeHistogramSeriesPerUser(u.userID, u.activeSeries.ActiveNativeHistogram()); err != nil {
 		return err
@@ -553,6 +639,12 @@ func (u *userTSDB) PostCreation(metric labels.Labels) {
 	u.seriesInMetric.increaseSeriesForMetric(metricName)
 	u.labelSetCounter.increaseSeriesLabelSet(u, metric)
 	u.trackerCounter.increase(metric)
+	if u.seriesChurn != nil {
+		u.seriesChurn.increase(time.Now())
+	}
+	if u.topSeries != nil {
+		u.topSeries.seriesCreated(metric, time.Now())
+	}
 
 	if u.postingCache != nil {
 		u.postingCache.ExpireSeries(metric)
@@ -640,6 +732,65 @@ func (u *userTSDB) getCachedShippedBlocks() map[ulid.ULID]struct{} {
 	return u.shippedBlocks
 }
 
+// blockIDs returns the IDs of the blocks currently loaded by the TSDB.
+func (u *userTSDB) blockIDs() map[ulid.ULID]struct{} {
+	ids := map[ulid.ULID]struct{}{}
+	for _, b := range u.Blocks() {
+		ids[b.Meta().ULID] = struct{}{}
+	}
+	return ids
+}
+
+// writeBlocksExemplars writes the in-memory exemplars of the blocks not in the input set to
+// their block exemplars file, in order to ship them alongside the blocks. It's expected to be
+// called right after the head compaction, while the exemplars are still in memory.
+func (u *userTSDB) writeBlocksExemplars(ctx context.Context, previous map[ulid.ULID]struct{}) error {
+	q, err := u.ExemplarQuerier(ctx)
+	if err != nil {
+		return err
+	}
+
+	allSeries := []*labels.Matcher{labels.MustNewMatcher(labels.MatchRegexp, model.MetricNameLabel, ".+")}
+	for _, b := range u.Blocks() {
+		meta := b.Meta()
+		if _, ok := previous[meta.ULID]; ok {
+			continue
+		}
+
+		// Block max time is exclusive.
+		series, err := q.Select(meta.MinTime, meta.MaxTime-1, allSeries)
+		if err != nil {
+			return errors.Wrapf(err, "query exemplars of block %s", meta.ULID.String())
+		}
+		if err := cortex_tsdb.WriteBlockExemplars(b.Dir(), series); err != nil {
+			return errors.Wrapf(err, "write exemplars of block %s", meta.ULID.String())
+		}
+	}
+
+	return nil
+}
+
+// uploadBlocksExemplars uploads the block exemplars file of the blocks not shipped yet, so that
+// the exemplars are already in the storage once the shipper uploads the blocks. It returns the
+// number of blocks whose exemplars failed to upload, along with the errors.
+func (u *userTSDB) uploadBlocksExemplars(ctx context.Context, bkt objstore.Bucket, logger log.Logger) (int, error) {
+	shippedBlocks := u.getCachedShippedBlocks()
+	errs := tsdb_errors.NewMulti()
+	failed := 0
+
+	for _, b := range u.Blocks() {
+		if _, ok := shippedBlocks[b.Meta().ULID]; ok {
+			continue
+		}
+		if _, err := cortex_tsdb.UploadBlockExemplars(ctx, logger, bkt, b.Dir()); err != nil {
+			errs.Add(errors.Wrapf(err, "block %s", b.Meta().ULID.String()))
+			failed++
+		}
+	}
+
+	return failed, errs.Err()
+}
+
 // getOldestUnshippedBlockTime returns the unix timestamp with milliseconds precision of the oldest
 // TSDB block not shipped to the storage yet, or 0 if all blocks have been shipped.
 func (u *userTSDB) getOldestUnshippedBlockTime() uint64 {
@@ -799,6 +950,7 @@ func New(cfg Config, limits *validation.Overrides, registerer prometheus.Registe
 		TSDBState:                    newTSDBState(bucketClient, registerer),
 		logger:                       logger,
 		ingestionRate:                util_math.NewEWMARate(0.2, instanceIngestionRateTickInterval),
+		appendLatency:                newLatencyTracker(appendLatencyWindow),
 		expandedPostingsCacheFactory: cortex_tsdb.NewExpandedPostingsCacheFactory(cfg.BlocksStorageConfig.TSDB.PostingsCache),
 		matchersCache:                storecache.NoopMatchersCache,
 	}
@@ -827,6 +979,7 @@ func New(cfg Config, limits *validation.Overrides, registerer prometheus.Registe
 		cfg.BlocksStorageConfig.TSDB.PostingsCache.Blocks.Enabled || cfg.BlocksStorageConfig.TSDB.PostingsCache.Head.Enabled,
 		cfg.EnableRegexMatcherLimits)
 	i.validateMetrics = validation.NewValidateMetrics(registerer)
+	i.discardedSeriesSampler = discardedseries.NewSampler(limits)
 
 	// Replace specific metrics which we can't directly track but we need to read
 	// them from the underlying system (ie. TSDB).
@@ -851,6 +1004,15 @@ func New(cfg Config, limits *validation.Overrides, registerer prometheus.Registe
 	i.subservicesWatcher = services.NewFailureWatcher()
 	i.subservicesWatcher.WatchService(i.lifecycler)
 
+	// The ingester joins both the token ring and the partition ring, if enabled.
+	if cfg.LifecyclerConfig.RingConfig.PartitionRing.Enabled {
+		i.partitionLifecycler, err = ring.NewPartitionLifecycler(cfg.LifecyclerConfig, "ingester", PartitionRingKey, logger, prometheus.WrapRegistererWithPrefix("cortex_", registerer))
+		if err != nil {
+			return nil, err
+		}
+		i.subservicesWatcher.WatchService(i.partitionLifecycler)
+	}
+
 	// Init the limter and instantiate the user states which depend on it
 	i.limiter = NewLimiter(
 		limits,
@@ -869,6 +1031,8 @@ func New(cfg Config, limits *validation.Overrides, registerer prometheus.Registe
 	level.Info(i.logger).Log("msg", "TSDB idle compaction timeout set", "timeout", i.TSDBState.compactionIdleTimeout)
 
 	if resourceMonitor != nil {
+		i.resourceMonitor = resourceMonitor
+
 		resourceLimits := make(map[resource.Type]float64)
 		if cfg.QueryProtection.Rejection.Threshold.CPUUtilization > 0 {
 			resourceLimits[resource.CPU] = cfg.QueryProtection.Rejection.Threshold.CPUUtilization
@@ -964,6 +1128,14 @@ func (i *Ingester) starting(ctx context.Context) error {
 
 	i.lifecycler.Join()
 
+	// The partition is joined once the TSDBs are open, so that the ingester is ready to receive
+	// the series of the partition.
+	if i.partitionLifecycler != nil {
+		if err := services.StartAndAwaitRunning(context.Background(), i.partitionLifecycler); err != nil {
+			return errors.Wrap(err, "failed to start partition lifecycler")
+		}
+	}
+
 	// let's start the rest of subservices via manager
 	servs := []services.Service(nil)
 
@@ -1023,6 +1195,14 @@ func (i *Ingester) stopping(_ error) error {
 		level.Warn(i.logger).Log("msg", "failed to stop ingester subservices", "err", err)
 	}
 
+	// Leave the partition first, so that the series are written to the other partitions
+	// while the ingester exits the ring.
+	if i.partitionLifecycler != nil {
+		if err := services.StopAndAwaitTerminated(context.Background(), i.partitionLifecycler); err != nil {
+			level.Warn(i.logger).Log("msg", "failed to stop ingester partition lifecycler", "err", err)
+		}
+	}
+
 	// Next initiate our graceful exit from the ring.
 	if err := services.StopAndAwaitTerminated(context.Background(), i.lifecycler); err != nil {
 		level.Warn(i.logger).Log("msg", "failed to stop ingester lifecycler", "err", err)
@@ -1068,10 +1248,14 @@ func (i *Ingester) updateLoop(ctx context.Context) error {
 	labelSetMetricsTicker := time.NewTicker(labelSetMetricsTickInterval)
 	defer labelSetMetricsTicker.Stop()
 
+	sampleDedupPurgeTicker := time.NewTicker(sampleDedupPurgePeriod)
+	defer sampleDedupPurgeTicker.Stop()
+
 	for {
 		select {
 		case <-metadataPurgeTicker.C:
 			i.purgeUserMetricsMetadata()
+			i.snapshotAllUserMetadata()
 		case <-ingestionRateTicker.C:
 			i.ingestionRate.Tick()
 		case <-rateUpdateTicker.C:
@@ -1093,6 +1277,8 @@ func (i *Ingester) updateLoop(ctx context.Context) error {
 			i.updateUserTSDBConfigs()
 		case <-labelSetMetricsTicker.C:
 			i.updateLabelSetMetrics()
+		case <-sampleDedupPurgeTicker.C:
+			i.purgeSampleDedup(time.Now())
 		case <-ctx.Done():
 			return nil
 		case err := <-i.subservicesWatcher.Chan():
@@ -1275,6 +1461,16 @@ func (i *Ingester) isLabelSetOutOfOrder(lbls labels.Labels) bool {
 
 // Push adds metrics to a block
 func (i *Ingester) Push(ctx context.Context, req *cortexpb.WriteRequest) (*cortexpb.WriteResponse, error) {
+	resp, err := i.push(ctx, req)
+	if err != nil && i.resourceMonitor != nil {
+		// The response isn't sent along with an error, so the utilization is reported in the
+		// trailer for the distributors to adapt the ingestion rate limits on failures too.
+		_ = grpc.SetTrailer(ctx, client.UtilizationTrailer(i.writeResponse()))
+	}
+	return resp, err
+}
+
+func (i *Ingester) push(ctx context.Context, req *cortexpb.WriteRequest) (*cortexpb.WriteResponse, error) {
 	if err := i.checkRunning(); err != nil {
 		return nil, err
 	}
@@ -1336,6 +1532,11 @@ func (i *Ingester) Push(ctx context.Context, req *cortexpb.WriteRequest) (*corte
 	// process it before samples. Otherwise, we risk returning an error before ingestion.
 	ingestedMetadata := i.pushMetadata(ctx, userID, req.GetMetadata())
 
+	var seriesMetadata map[string]prom_metadata.Metadata
+	if i.limits.EnableSeriesMetadata(userID) {
+		seriesMetadata = seriesMetadataByName(req.GetMetadata())
+	}
+
 	reasonCounter := newLabelSetReasonCounters()
 
 	// Keep track of some stats which are tracked only if the samples will be
@@ -1356,9 +1557,13 @@ func (i *Ingester) Push(ctx context.Context, req *cortexpb.WriteRequest) (*corte
 		newValueForTimestampCount              = 0
 		perUserSeriesLimitCount                = 0
 		perUserNativeHistogramSeriesLimitCount = 0
+		perUserSeriesChurnLimitCount           = 0
 		perLabelSetSeriesLimitCount            = 0
 		perMetricSeriesLimitCount              = 0
 		discardedNativeHistogramCount          = 0
+		sampleDeduplicatedCount                = 0
+		sampleDedupWindowMs                    = i.limits.SampleDedupWindow(userID).Milliseconds()
+		sampleDedupAppended                    []sampleDedupKey
 
 		updateFirstPartial = func(errFn func() error) {
 			if firstPartialErr == nil {
@@ -1367,6 +1572,11 @@ func (i *Ingester) Push(ctx context.Context, req *cortexpb.WriteRequest) (*corte
 		}
 
 		handleAppendFailure = func(err error, timestampMs int64, lbls []cortexpb.LabelAdapter, copiedLabels labels.Labels, matchedLabelSetLimits []validation.LimitsPerLabelSet) (rollback bool) {
+			discardSeries := func(reason string) {
+				i.validateMetrics.DiscardedSeriesTracker.Track(reason, userID, copiedLabels.Hash())
+				i.discardedSeriesSampler.Sample(userID, reason, lbls, timestampMs, err, startAppend)
+			}
+
 			// Check if the error is a soft error we can proceed on. If so, we keep track
 			// of it, so that we can return it back to the distributor, which will return a
 			// 400 error to the client. The client (Prometheus) will not retry on 400, and
@@ -1374,47 +1584,55 @@ func (i *Ingester) Push(ctx context.Context, req *cortexpb.WriteRequest) (*corte
 			switch cause := errors.Cause(err); {
 			case errors.Is(cause, storage.ErrOutOfBounds):
 				sampleOutOfBoundsCount++
-				i.validateMetrics.DiscardedSeriesTracker.Track(sampleOutOfBounds, userID, copiedLabels.Hash())
+				discardSeries(sampleOutOfBounds)
 				updateFirstPartial(func() error { return wrappedTSDBIngestErr(err, model.Time(timestampMs), lbls) })
 
 			case errors.Is(cause, storage.ErrOutOfOrderSample):
 				sampleOutOfOrderCount++
-				i.validateMetrics.DiscardedSeriesTracker.Track(sampleOutOfOrder, userID, copiedLabels.Hash())
+				discardSeries(sampleOutOfOrder)
 				updateFirstPartial(func() error { return wrappedTSDBIngestErr(err, model.Time(timestampMs), lbls) })
 
 			case errors.Is(cause, storage.ErrDuplicateSampleForTimestamp):
 				newValueForTimestampCount++
-				i.validateMetrics.DiscardedSeriesTracker.Track(newValueForTimestamp, userID, copiedLabels.Hash())
+				discardSeries(newValueForTimestamp)
 				updateFirstPartial(func() error { return wrappedTSDBIngestErr(err, model.Time(timestampMs), lbls) })
 
 			case errors.Is(cause, storage.ErrTooOldSample):
 				sampleTooOldCount++
-				i.validateMetrics.DiscardedSeriesTracker.Track(sampleTooOld, userID, copiedLabels.Hash())
+				discardSeries(sampleTooOld)
 				updateFirstPartial(func() error { return wrappedTSDBIngestErr(err, model.Time(timestampMs), lbls) })
 
 			case errors.Is(cause, errMaxSeriesPerUserLimitExceeded):
 				perUserSeriesLimitCount++
-				i.validateMetrics.DiscardedSeriesTracker.Track(perUserSeriesLimit, userID, copiedLabels.Hash())
+				discardSeries(perUserSeriesLimit)
 				updateFirstPartial(func() error {
 					return makeLimitError(perUserSeriesLimit, i.limiter.FormatError(userID, cause, copiedLabels))
 				})
 
+			case errors.Is(cause, errMaxSeriesChurnPerUserLimitExceeded):
+				perUserSeriesChurnLimitCount++
+				discardSeries(perUserSeriesChurnLimit)
+				updateFirstPartial(func() error {
+					return makeLimitError(perUserSeriesChurnLimit, i.limiter.FormatError(userID, cause, copiedLabels))
+				})
+
 			case errors.Is(cause, errMaxNativeHistogramSeriesPerUserLimitExceeded):
 				perUserNativeHistogramSeriesLimitCount++
+				i.discardedSeriesSampler.Sample(userID, perUserNativeHistogramSeriesLimit, lbls, timestampMs, err, startAppend)
 				updateFirstPartial(func() error {
 					return makeLimitError(perUserSeriesLimit, i.limiter.FormatError(userID, cause, copiedLabels))
 				})
 
 			case errors.Is(cause, errMaxSeriesPerMetricLimitExceeded):
 				perMetricSeriesLimitCount++
-				i.validateMetrics.DiscardedSeriesTracker.Track(perMetricSeriesLimit, userID, copiedLabels.Hash())
+				discardSeries(perMetricSeriesLimit)
 				updateFirstPartial(func() error {
 					return makeMetricLimitError(perMetricSeriesLimit, copiedLabels, i.limiter.FormatError(userID, cause, copiedLabels))
 				})
 
 			case errors.As(cause, &errMaxSeriesPerLabelSetLimitExceeded{}):
 				perLabelSetSeriesLimitCount++
-				i.validateMetrics.DiscardedSeriesTracker.Track(perLabelsetSeriesLimit, userID, copiedLabels.Hash())
+				discardSeries(perLabelsetSeriesLimit)
 				for _, matchedLabelset := range matchedLabelSetLimits {
 					i.validateMetrics.DiscardedSeriesPerLabelsetTracker.Track(userID, copiedLabels.Hash(), matchedLabelset.Hash, matchedLabelset.Id)
 				}
@@ -1451,6 +1669,8 @@ func (i *Ingester) Push(ctx context.Context, req *cortexpb.WriteRequest) (*corte
 		if rollbackErr := app.Rollback(); rollbackErr != nil {
 			level.Warn(logutil.WithContext(ctx, i.logger)).Log("msg", "failed to rollback appender on early return", "user", userID, "err", rollbackErr)
 		}
+		// The samples haven't been ingested, so their deduplication buckets are released.
+		db.sampleDedup.release(sampleDedupAppended...)
 	}()
 
 	// Even when OOO is enabled globally, we want to reject OOO samples in some cases.
@@ -1489,6 +1709,18 @@ func (i *Ingester) Push(ctx context.Context, req *cortexpb.WriteRequest) (*corte
 		for _, s := range ts.Samples {
 			var err error
 
+			// The samples of a new series can't be deduplicated before the series is created,
+			// so the bucket of its first sample is only reserved once appended.
+			var dedupKey sampleDedupKey
+			dedupChecked := sampleDedupWindowMs > 0 && ref != 0
+			if dedupChecked {
+				var accepted bool
+				if dedupKey, accepted = db.sampleDedup.reserve(ref, s.TimestampMs, sampleDedupWindowMs, startAppend); !accepted {
+					sampleDeduplicatedCount++
+					continue
+				}
+			}
+
 			if s.StartTimestampMs != 0 && s.TimestampMs != 0 {
 				// TODO(SungJin1212): Change to AppendSTZeroSample after update the Prometheus v3.9.0+
 				if _, err = app.AppendCTZeroSample(ref, copiedLabels, s.TimestampMs, s.StartTimestampMs); err != nil && !errors.Is(err, storage.ErrOutOfOrderCT) {
@@ -1499,11 +1731,7 @@ func (i *Ingester) Push(ctx context.Context, req *cortexpb.WriteRequest) (*corte
 
 			// If the cached reference exists, we try to use it.
 			if ref != 0 {
-				if _, err = app.Append(ref, copiedLabels, s.TimestampMs, s.Value); err == nil {
-					succeededSamplesCount++
-					continue
-				}
-
+				_, err = app.Append(ref, copiedLabels, s.TimestampMs, s.Value)
 			} else {
 				// Retain the reference in case there are multiple samples for the series.
 				if ref, err = app.Append(0, copiedLabels, s.TimestampMs, s.Value); err == nil {
@@ -1511,12 +1739,23 @@ func (i *Ingester) Push(ctx context.Context, req *cortexpb.WriteRequest) (*corte
 					if db.postingCache != nil {
 						newSeries = append(newSeries, copiedLabels)
 					}
-					succeededSamplesCount++
-					continue
 				}
 			}
+			if err == nil {
+				if sampleDedupWindowMs > 0 && !dedupChecked {
+					dedupKey, _ = db.sampleDedup.reserve(ref, s.TimestampMs, sampleDedupWindowMs, startAppend)
+				}
+				if dedupKey.reserved() {
+					sampleDedupAppended = append(sampleDedupAppended, dedupKey)
+				}
+				succeededSamplesCount++
+				continue
+			}
 
 			failedSamplesCount++
+			if dedupKey.reserved() {
+				db.sampleDedup.release(dedupKey)
+			}
 
 			if rollback := handleAppendFailure(err, s.TimestampMs, ts.Labels, copiedLabels, matchedLabelSetLimits); !rollback {
 				continue
@@ -1535,6 +1774,16 @@ func (i *Ingester) Push(ctx context.Context, req *cortexpb.WriteRequest) (*corte
 					fh  *histogram.FloatHistogram
 				)
 
+				var dedupKey sampleDedupKey
+				dedupChecked := sampleDedupWindowMs > 0 && ref != 0
+				if dedupChecked {
+					var accepted bool
+					if dedupKey, accepted = db.sampleDedup.reserve(ref, hp.TimestampMs, sampleDedupWindowMs, startAppend); !accepted {
+						sampleDeduplicatedCount++
+						continue
+					}
+				}
+
 				if hp.GetCountFloat() > 0 {
 					fh = cortexpb.FloatHistogramProtoToFloatHistogram(hp.Histogram)
 				} else {
@@ -1550,11 +1799,7 @@ func (i *Ingester) Push(ctx context.Context, req *cortexpb.WriteRequest) (*corte
 				}
 
 				if ref != 0 {
-					if _, err = app.AppendHistogram(ref, copiedLabels, hp.TimestampMs, h, fh); err == nil {
-						succeededHistogramsCount++
-						ingestedBucketsObserver.Observe(float64(hp.BucketCount()))
-						continue
-					}
+					_, err = app.AppendHistogram(ref, copiedLabels, hp.TimestampMs, h, fh)
 				} else {
 					// Copy the label set because both TSDB and the active series tracker may retain it.
 					copiedLabels = cortexpb.FromLabelAdaptersToLabelsWithCopy(ts.Labels)
@@ -1563,13 +1808,24 @@ func (i *Ingester) Push(ctx context.Context, req *cortexpb.WriteRequest) (*corte
 						if db.postingCache != nil {
 							newSeries = append(newSeries, copiedLabels)
 						}
-						succeededHistogramsCount++
-						ingestedBucketsObserver.Observe(float64(hp.BucketCount()))
-						continue
 					}
 				}
+				if err == nil {
+					if sampleDedupWindowMs > 0 && !dedupChecked {
+						dedupKey, _ = db.sampleDedup.reserve(ref, hp.TimestampMs, sampleDedupWindowMs, startAppend)
+					}
+					if dedupKey.reserved() {
+						sampleDedupAppended = append(sampleDedupAppended, dedupKey)
+					}
+					succeededHistogramsCount++
+					ingestedBucketsObserver.Observe(float64(hp.BucketCount()))
+					continue
+				}
 
 				failedHistogramsCount++
+				if dedupKey.reserved() {
+					db.sampleDedup.release(dedupKey)
+				}
 
 				if rollback := handleAppendFailure(err, hp.TimestampMs, ts.Labels, copiedLabels, matchedLabelSetLimits); !rollback {
 					continue
@@ -1582,6 +1838,11 @@ func (i *Ingester) Push(ctx context.Context, req *cortexpb.WriteRequest) (*corte
 			discardedNativeHistogramCount += len(ts.Histograms)
 		}
 
+		if m, ok := seriesMetadata[tsLabels.Get(labels.MetricName)]; ok && ref != 0 {
+			// Metadata is best effort, so that errors don't fail the push.
+			_, _ = app.UpdateMetadata(ref, copiedLabels, m)
+		}
+
 		isNHAppended := succeededHistogramsCount > oldSucceededHistogramsCount
 		shouldUpdateSeries := (succeededSamplesCount > oldSucceededSamplesCount) || isNHAppended
 		if i.cfg.ActiveSeriesMetricsEnabled && shouldUpdateSeries {
@@ -1643,8 +1904,10 @@ func (i *Ingester) Push(ctx context.Context, req *cortexpb.WriteRequest) (*corte
 	// the deferred Rollback must not fire afterwards.
 	committed = true
 	if err := app.Commit(); err != nil {
+		db.sampleDedup.release(sampleDedupAppended...)
 		return nil, wrapWithUser(err, userID)
 	}
+	db.sampleDedup.commit(sampleDedupAppended, startAppend)
 
 	// This is a workaround of https://github.com/prometheus/prometheus/pull/15579
 	// Calling expire here may result in the series names being expired multiple times,
@@ -1691,6 +1954,9 @@ func (i *Ingester) Push(ctx context.Context, req *cortexpb.WriteRequest) (*corte
 	if perUserNativeHistogramSeriesLimitCount > 0 {
 		i.validateMetrics.DiscardedSamples.WithLabelValues(perUserNativeHistogramSeriesLimit, userID).Add(float64(perUserNativeHistogramSeriesLimitCount))
 	}
+	if perUserSeriesChurnLimitCount > 0 {
+		i.validateMetrics.DiscardedSamples.WithLabelValues(perUserSeriesChurnLimit, userID).Add(float64(perUserSeriesChurnLimitCount))
+	}
 	if perMetricSeriesLimitCount > 0 {
 		i.validateMetrics.DiscardedSamples.WithLabelValues(perMetricSeriesLimit, userID).Add(float64(perMetricSeriesLimitCount))
 	}
@@ -1701,6 +1967,9 @@ func (i *Ingester) Push(ctx context.Context, req *cortexpb.WriteRequest) (*corte
 	if !i.limits.EnableNativeHistograms(userID) && discardedNativeHistogramCount > 0 {
 		i.validateMetrics.DiscardedSamples.WithLabelValues(nativeHistogramSample, userID).Add(float64(discardedNativeHistogramCount))
 	}
+	if sampleDeduplicatedCount > 0 {
+		i.validateMetrics.DiscardedSamples.WithLabelValues(sampleDeduplicated, userID).Add(float64(sampleDeduplicatedCount))
+	}
 
 	for h, counter := range reasonCounter.counters {
 		labelStr := counter.lbls.String()
@@ -1729,10 +1998,23 @@ func (i *Ingester) Push(ctx context.Context, req *cortexpb.WriteRequest) (*corte
 			code = ve.code
 		}
 		level.Debug(logutil.WithContext(ctx, i.logger)).Log("msg", "partial failures to push", "totalSamples", succeededSamplesCount+failedSamplesCount, "failedSamples", failedSamplesCount, "totalHistograms", succeededHistogramsCount+failedHistogramsCount, "failedHistograms", failedHistogramsCount, "firstPartialErr", firstPartialErr)
-		return &cortexpb.WriteResponse{}, httpgrpc.Errorf(code, "%s", wrapWithUser(firstPartialErr, userID).Error())
+		return i.writeResponse(), httpgrpc.Errorf(code, "%s", wrapWithUser(firstPartialErr, userID).Error())
 	}
 
-	return &cortexpb.WriteResponse{}, nil
+	return i.writeResponse(), nil
+}
+
+// writeResponse returns the response of a push, reporting the utilization of the ingester
+// resources so that the distributors can adapt the ingestion rate limits.
+func (i *Ingester) writeResponse() *cortexpb.WriteResponse {
+	if i.resourceMonitor == nil {
+		return &cortexpb.WriteResponse{}
+	}
+
+	return &cortexpb.WriteResponse{
+		CpuUtilization:  i.resourceMonitor.GetCPUUtilization(),
+		HeapUtilization: i.resourceMonitor.GetHeapUtilization(),
+	}
 }
 
 func (i *Ingester) PushStream(srv client.Ingester_PushStreamServer) error {
@@ -1765,11 +2047,15 @@ func (i *Ingester) PushStream(srv client.Ingester_PushStreamServer) error {
 		}
 
 		pushCtx := user.InjectOrgID(ctx, req.TenantID)
-		resp, err := i.Push(pushCtx, req.Request)
+		start := time.Now()
+		resp, err := i.push(pushCtx, req.Request)
+		i.appendLatency.observe(time.Now(), time.Since(start))
 		if resp == nil {
-			resp = &cortexpb.WriteResponse{}
+			// The response is sent along with the error on the stream, so it reports the utilization too.
+			resp = i.writeResponse()
 		}
 		resp.Code = http.StatusOK
+		resp.LoadHints = i.loadHints()
 		if err != nil {
 			httpResponse, isGRPCError := httpgrpc.HTTPResponseFromError(err)
 			if !isGRPCError {
@@ -2376,6 +2662,7 @@ func (i *Ingester) UserStats(ctx context.Context, req *client.UserStatsRequest)
 		RuleIngestionRate: userStat.RuleIngestionRate,
 		ActiveSeries:      userStat.ActiveSeries,
 		LoadedBlocks:      userStat.LoadedBlocks,
+		SeriesChurn:       userStat.SeriesChurn,
 	}, nil
 }
 
@@ -2424,12 +2711,62 @@ func (i *Ingester) AllUserStats(_ context.Context, _ *client.UserStatsRequest) (
 				RuleIngestionRate: userStat.RuleIngestionRate,
 				ActiveSeries:      userStat.ActiveSeries,
 				LoadedBlocks:      userStat.LoadedBlocks,
+				SeriesChurn:       userStat.SeriesChurn,
 			},
 		})
 	}
 	return response, nil
 }
 
+// TopSeries returns the top metric names and label pairs of the tenant, by active series and by series churn.
+func (i *Ingester) TopSeries(ctx context.Context, req *client.TopSeriesRequest) (*client.TopSeriesResponse, error) {
+	if err := i.checkRunning(); err != nil {
+		return nil, err
+	}
+
+	userID, err := users.TenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.topSeries(userID, int(req.Limit)).toProto(), nil
+}
+
+// TopSeriesHandler shows the top metric names and label pairs of the tenant in the URL path.
+func (i *Ingester) TopSeriesHandler(w http.ResponseWriter, r *http.Request) {
+	if !i.cfg.TopSeriesEnabled {
+		http.Error(w, "top series tracking is disabled", http.StatusNotFound)
+		return
+	}
+
+	limit, err := ParseTopSeriesLimit(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	util.WriteJSONResponse(w, i.topSeries(mux.Vars(r)["id"], limit))
+}
+
+func (i *Ingester) topSeries(userID string, limit int) TopSeries {
+	db, err := i.getTSDB(userID)
+	if err != nil || db == nil || db.topSeries == nil {
+		return TopSeries{
+			ActiveMetricNames: []TopSeriesEntry{},
+			ActiveLabelPairs:  []TopSeriesEntry{},
+			ChurnMetricNames:  []TopSeriesEntry{},
+			ChurnLabelPairs:   []TopSeriesEntry{},
+		}
+	}
+
+	return db.topSeries.topSeries(limit, time.Now())
+}
+
+// DiscardedSeriesHandler shows the latest discarded series sampled by the ingester for the tenant in the URL path.
+func (i *Ingester) DiscardedSeriesHandler(w http.ResponseWriter, r *http.Request) {
+	util.WriteJSONResponse(w, i.discardedSeriesSampler.Samples(mux.Vars(r)["id"]))
+}
+
 func createUserStats(db *userTSDB, activeSeriesMetricsEnabled bool) UserStats {
 	apiRate := db.ingestedAPISamples.Rate()
 	ruleRate := db.ingestedRuleSamples.Rate()
@@ -2439,6 +2776,11 @@ func createUserStats(db *userTSDB, activeSeriesMetricsEnabled bool) UserStats {
 		activeSeries = uint64(db.activeSeries.Active())
 	}
 
+	var seriesChurn uint64
+	if db.seriesChurn != nil {
+		seriesChurn = uint64(db.seriesChurn.count(time.Now()))
+	}
+
 	return UserStats{
 		IngestionRate:     apiRate + ruleRate,
 		APIIngestionRate:  apiRate,
@@ -2446,6 +2788,7 @@ func createUserStats(db *userTSDB, activeSeriesMetricsEnabled bool) UserStats {
 		NumSeries:         db.Head().NumSeries(),
 		ActiveSeries:      activeSeries,
 		LoadedBlocks:      uint64(len(db.Blocks())),
+		SeriesChurn:       seriesChurn,
 	}
 }
 
@@ -2924,6 +3267,7 @@ func (i *Ingester) createTSDB(userID string) (*userTSDB, error) {
 		seriesInMetric:      newMetricCounter(i.limiter, i.cfg.getIgnoreSeriesLimitForMetricNamesMap()),
 		labelSetCounter:     newLabelSetCounter(i.limiter),
 		trackerCounter:      newTrackerCounter(),
+		sampleDedup:         newSampleDeduplicator(),
 		ingestedAPISamples:  util_math.NewEWMARate(0.2, i.cfg.RateUpdatePeriod),
 		ingestedRuleSamples: util_math.NewEWMARate(0.2, i.cfg.RateUpdatePeriod),
 
@@ -2975,6 +3319,10 @@ func (i *Ingester) createTSDB(userID string) (*userTSDB, error) {
 	}
 	db.DisableCompactions() // we will compact on our own schedule
 
+	if i.limits.EnableSeriesMetadata(userID) {
+		i.restoreUserMetadata(userID, udir, userLogger)
+	}
+
 	// Run compaction before using this TSDB. If there is data in head that needs to be put into blocks,
 	// this will actually create the blocks. If there is no data (empty TSDB), this is a no-op, although
 	// local blocks compaction may still take place if configured.
@@ -2992,6 +3340,14 @@ func (i *Ingester) createTSDB(userID string) (*userTSDB, error) {
 	// series during WAL replay.
 	userDB.limiter = i.limiter
 
+	// Like the limiter, the series churn counter and the top series tracker are
+	// set after the WAL replay so that replayed series are not accounted as churn.
+	userDB.seriesChurn = newSeriesChurnCounter(func() time.Duration { return i.limits.SeriesChurnWindow(userID) }, time.Now())
+	if i.cfg.TopSeriesEnabled {
+		userDB.topSeries = newTopSeriesTracker(i.cfg.TopSeriesLimit, i.cfg.TopSeriesChurnWindow, time.Now())
+		userDB.activeSeries.observer = userDB.topSeries
+	}
+
 	if db.Head().NumSeries() > 0 {
 		// If there are series in the head, use max time from head. If this time is too old,
 		// TSDB will be eligible for flushing and closing sooner, unless more data is pushed to it quickly.
@@ -3044,6 +3400,8 @@ func (i *Ingester) closeAllTSDB() {
 		go func(db *userTSDB) {
 			defer wg.Done()
 
+			i.snapshotUserMetadata(userID, db.db.Dir())
+
 			if err := db.Close(); err != nil {
 				level.Warn(i.logger).Log("msg", "unable to close TSDB", "err", err, "user", userID)
 				return
@@ -3286,6 +3644,16 @@ func (i *Ingester) shipBlocks(ctx context.Context, allowed *users.AllowedTenants
 			}
 		}
 
+		if i.cfg.BlocksStorageConfig.TSDB.PersistExemplars {
+			userBkt := bucket.NewUserBucketClient(userID, i.TSDBState.bucket, i.limits)
+			// A failure to upload the exemplars doesn't block the shipping of the blocks, which
+			// are queryable without their exemplars.
+			if failed, err := userDB.uploadBlocksExemplars(ctx, userBkt, logutil.WithContext(ctx, i.logger)); err != nil {
+				i.metrics.blockExemplarsUploadFailures.Add(float64(failed))
+				level.Warn(logutil.WithContext(ctx, i.logger)).Log("msg", "failed to upload exemplars of the TSDB blocks to the storage", "user", userID, "failed", failed, "err", err)
+			}
+		}
+
 		uploaded, err := userDB.shipper.Sync(ctx)
 		if err != nil {
 			level.Warn(logutil.WithContext(ctx, i.logger)).Log("msg", "shipper failed to synchronize TSDB blocks with the storage", "user", userID, "uploaded", uploaded, "err", err)
@@ -3369,6 +3737,12 @@ func (i *Ingester) compactBlocks(ctx context.Context, force bool, allowed *users
 
 		i.TSDBState.compactionsTriggered.Inc()
 
+		// Keep track of the existing blocks, to persist the exemplars of the new ones only.
+		var previousBlocks map[ulid.ULID]struct{}
+		if i.cfg.BlocksStorageConfig.TSDB.PersistExemplars {
+			previousBlocks = userDB.blockIDs()
+		}
+
 		reason := ""
 		switch {
 		case force:
@@ -3392,6 +3766,13 @@ func (i *Ingester) compactBlocks(ctx context.Context, force bool, allowed *users
 			level.Debug(logutil.WithContext(ctx, i.logger)).Log("msg", "TSDB blocks compaction completed successfully", "user", userID, "compactReason", reason)
 		}
 
+		// The exemplars of the blocks compacted before a failure are persisted too.
+		if i.cfg.BlocksStorageConfig.TSDB.PersistExemplars {
+			if err := userDB.writeBlocksExemplars(ctx, previousBlocks); err != nil {
+				level.Warn(logutil.WithContext(ctx, i.logger)).Log("msg", "failed to persist exemplars of the compacted TSDB blocks", "user", userID, "err", err)
+			}
+		}
+
 		return nil
 	})
 }
@@ -3492,6 +3873,7 @@ func (i *Ingester) closeAndDeleteUserTSDBIfIdle(userID string) tsdbCloseCheckRes
 	i.metrics.deletePerUserMetrics(userID)
 
 	validation.DeletePerUserValidationMetrics(i.validateMetrics, userID, i.logger)
+	i.discardedSeriesSampler.RemoveUser(userID)
 
 	// And delete local data.
 	if err := os.RemoveAll(dir); err != nil {
@@ -3553,6 +3935,54 @@ func (i *Ingester) appendMetadata(userID string, m *cortexpb.MetricMetadata) err
 	return userMetadata.add(m.GetMetricFamilyName(), m)
 }
 
+// restoreUserMetadata loads in memory the metadata of the last snapshot of the user's TSDB, except
+// the one which hasn't been received within the metadata retain period.
+func (i *Ingester) restoreUserMetadata(userID, dir string, logger log.Logger) {
+	entries, err := readMetadataSnapshot(dir)
+	if err != nil {
+		level.Warn(logger).Log("msg", "failed to restore metadata from the snapshot", "err", err)
+	}
+
+	deadline := time.Now().Add(-i.cfg.MetadataRetainPeriod)
+	restored := 0
+	for _, e := range entries {
+		if e.LastSeen.Before(deadline) {
+			continue
+		}
+		if err := i.getOrCreateUserMetadata(userID).addAt(e.Metadata.GetMetricFamilyName(), &e.Metadata, e.LastSeen); err == nil {
+			restored++
+		}
+	}
+	if restored > 0 {
+		level.Info(logger).Log("msg", "restored metadata from the snapshot", "metadata", restored)
+	}
+}
+
+// snapshotUserMetadata writes the in-memory metadata of the user to its TSDB directory, to restore
+// it when the TSDB is opened again.
+func (i *Ingester) snapshotUserMetadata(userID, dir string) {
+	userMetadata := i.getUserMetadata(userID)
+	if userMetadata == nil || !i.limits.EnableSeriesMetadata(userID) {
+		return
+	}
+
+	if err := writeMetadataSnapshot(dir, userMetadata.snapshot()); err != nil {
+		level.Warn(i.logger).Log("msg", "failed to snapshot metadata", "err", err, "user", userID)
+	}
+}
+
+// snapshotAllUserMetadata periodically snapshots the in-memory metadata of the open TSDBs, so that
+// it's restored also if the ingester isn't gracefully shut down.
+func (i *Ingester) snapshotAllUserMetadata() {
+	for _, userID := range i.getTSDBUsers() {
+		db, err := i.getTSDB(userID)
+		if err != nil || db == nil {
+			continue
+		}
+		i.snapshotUserMetadata(userID, db.db.Dir())
+	}
+}
+
 func (i *Ingester) getOrCreateUserMetadata(userID string) *userMetricsMetadata {
 	userMetadata := i.getUserMetadata(userID)
 	if userMetadata != nil {
@@ -3616,6 +4046,20 @@ func (i *Ingester) purgeUserMetricsMetadata() {
 	}
 }
 
+// purgeSampleDedup removes the sample deduplication state of the series which haven't
+// received samples for twice the deduplication window.
+func (i *Ingester) purgeSampleDedup(now time.Time) {
+	for _, userID := range i.getTSDBUsers() {
+		userDB, err := i.getTSDB(userID)
+		if err != nil || userDB == nil {
+			continue
+		}
+
+		idle := max(2*i.limits.SampleDedupWindow(userID), sampleDedupMinIdleDuration)
+		userDB.sampleDedup.purge(now.Add(-idle))
+	}
+}
+
 // This method will flush all data. It is called as part of Lifecycler's shutdown (if flush on shutdown is configured), or from the flusher.
 //
 // When called as during Lifecycler shutdown, this happens as part of normal Ingester shutdown (see stopping method).
@@ -3763,6 +4207,23 @@ func (i *Ingester) ModeHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The mode of the ingester applies to its whole partition, if the partition ring is enabled.
+	if i.partitionLifecycler != nil {
+		partitionState := ring.PARTITION_ACTIVE
+		if reqMode == "READONLY" {
+			partitionState = ring.PARTITION_READONLY
+		}
+
+		if err := i.partitionLifecycler.ChangePartitionState(r.Context(), partitionState); err != nil {
+			respMsg := fmt.Sprintf("failed to change partition state: %s", err)
+			level.Warn(logutil.WithContext(r.Context(), i.logger)).Log("msg", respMsg)
+			w.WriteHeader(http.StatusBadRequest)
+			// We ignore errors here, because we cannot do anything about them.
+			_, _ = w.Write([]byte(respMsg))
+			return
+		}
+	}
+
 	respMsg := fmt.Sprintf("Ingester mode %s", i.lifecycler.GetState())
 	level.Info(logutil.WithContext(r.Context(), i.logger)).Log("msg", respMsg)
 	w.WriteHeader(http.StatusOK)
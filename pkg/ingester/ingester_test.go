@@ -23,11 +23,13 @@ import (
 	"time"
 
 	"github.com/go-kit/log"
+	"github.com/gorilla/mux"
 	"github.com/oklog/ulid/v2"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/common/promslog"
+	"github.com/prometheus/prometheus/model/exemplar"
 	"github.com/prometheus/prometheus/model/histogram"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/storage"
@@ -39,6 +41,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"github.com/thanos-io/objstore"
+	"github.com/thanos-io/thanos/pkg/block/metadata"
 	"github.com/thanos-io/thanos/pkg/runutil"
 	"github.com/thanos-io/thanos/pkg/shipper"
 	storecache "github.com/thanos-io/thanos/pkg/store/cache"
@@ -61,6 +64,7 @@ import (
 	"github.com/cortexproject/cortex/pkg/storage/tsdb/bucketindex"
 	"github.com/cortexproject/cortex/pkg/util"
 	"github.com/cortexproject/cortex/pkg/util/chunkcompat"
+	"github.com/cortexproject/cortex/pkg/util/discardedseries"
 	"github.com/cortexproject/cortex/pkg/util/limiter"
 	"github.com/cortexproject/cortex/pkg/util/resource"
 	"github.com/cortexproject/cortex/pkg/util/services"
@@ -870,6 +874,156 @@ func TestIngesterUserLimitExceeded(t *testing.T) {
 
 }
 
+func TestIngesterSeriesChurnLimitExceeded(t *testing.T) {
+	limits := defaultLimitsTestConfig()
+	limits.MaxLocalSeriesChurnPerUser = 1
+	limits.SeriesChurnWindow = model.Duration(time.Hour)
+
+	userID := "1"
+	labels1 := labels.FromStrings(labels.MetricName, "testmetric", "foo", "bar")
+	labels3 := labels.FromStrings(labels.MetricName, "testmetric", "foo", "biz")
+	sample1 := cortexpb.Sample{TimestampMs: 0, Value: 1}
+	sample2 := cortexpb.Sample{TimestampMs: 1, Value: 2}
+
+	reg := prometheus.NewRegistry()
+	ing, err := prepareIngesterWithBlocksStorageAndLimits(t, defaultIngesterTestConfig(t), limits, nil, t.TempDir(), reg)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), ing))
+	defer services.StopAndAwaitTerminated(context.Background(), ing) //nolint:errcheck
+
+	// Wait until it's ACTIVE
+	test.Poll(t, time.Second, ring.ACTIVE, func() any {
+		return ing.lifecycler.GetState()
+	})
+
+	// Create one series first, expect no error.
+	ctx := user.InjectOrgID(context.Background(), userID)
+	_, err = ing.Push(ctx, cortexpb.ToWriteRequest([]labels.Labels{labels1}, []cortexpb.Sample{sample1}, nil, nil, cortexpb.API))
+	require.NoError(t, err)
+
+	// Appending to an existing series is still allowed, while creating a new one exceeds the churn limit.
+	_, err = ing.Push(ctx, cortexpb.ToWriteRequest([]labels.Labels{labels1, labels3}, []cortexpb.Sample{sample2, sample2}, nil, nil, cortexpb.API))
+	httpResp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok, "returned error is not an httpgrpc response")
+	assert.Equal(t, http.StatusBadRequest, int(httpResp.Code))
+	assert.Equal(t, wrapWithUser(makeLimitError(perUserSeriesChurnLimit, ing.limiter.FormatError(userID, errMaxSeriesChurnPerUserLimitExceeded, labels3)), userID).Error(), string(httpResp.Body))
+
+	res, _, err := runTestQuery(ctx, t, ing, labels.MatchEqual, model.MetricNameLabel, "testmetric")
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Len(t, res[0].Values, 2)
+
+	require.NoError(t, testutil.GatherAndCompare(reg, bytes.NewBufferString(`
+		# HELP cortex_discarded_samples_total The total number of samples that were discarded.
+		# TYPE cortex_discarded_samples_total counter
+		cortex_discarded_samples_total{reason="per_user_series_churn_limit",user="1"} 1
+	`), "cortex_discarded_samples_total"))
+}
+
+func TestIngesterSampleDedup(t *testing.T) {
+	limits := defaultLimitsTestConfig()
+	limits.SampleDedupWindow = model.Duration(10 * time.Second)
+
+	userID := "1"
+	series := labels.FromStrings(labels.MetricName, "testmetric", "foo", "bar")
+
+	reg := prometheus.NewRegistry()
+	ing, err := prepareIngesterWithBlocksStorageAndLimits(t, defaultIngesterTestConfig(t), limits, nil, t.TempDir(), reg)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), ing))
+	defer services.StopAndAwaitTerminated(context.Background(), ing) //nolint:errcheck
+
+	// Wait until it's ACTIVE
+	test.Poll(t, time.Second, ring.ACTIVE, func() any {
+		return ing.lifecycler.GetState()
+	})
+
+	// Two senders push the same stream with slightly different timestamps.
+	ctx := user.InjectOrgID(context.Background(), userID)
+	for _, ts := range []int64{1000, 1003, 16000, 15998, 26000} {
+		_, err = ing.Push(ctx, cortexpb.ToWriteRequest([]labels.Labels{series}, []cortexpb.Sample{{TimestampMs: ts, Value: 1}}, nil, nil, cortexpb.API))
+		require.NoError(t, err)
+	}
+
+	res, _, err := runTestQuery(ctx, t, ing, labels.MatchEqual, model.MetricNameLabel, "testmetric")
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, []model.SamplePair{{Timestamp: 1000, Value: 1}, {Timestamp: 16000, Value: 1}, {Timestamp: 26000, Value: 1}}, res[0].Values)
+
+	require.NoError(t, testutil.GatherAndCompare(reg, bytes.NewBufferString(`
+		# HELP cortex_discarded_samples_total The total number of samples that were discarded.
+		# TYPE cortex_discarded_samples_total counter
+		cortex_discarded_samples_total{reason="sample-deduplicated",user="1"} 2
+	`), "cortex_discarded_samples_total"))
+}
+
+func TestIngesterSampleDedup_OutOfOrderSamples(t *testing.T) {
+	userID := "1"
+	series := labels.FromStrings(labels.MetricName, "testmetric", "foo", "bar")
+
+	for name, tc := range map[string]struct {
+		oooTimeWindow   time.Duration
+		expectedSamples []model.SamplePair
+		expectedBuckets []int64
+	}{
+		"a sample failing to be appended doesn't mark its bucket as deduplicated": {
+			expectedSamples: []model.SamplePair{{Timestamp: 21000, Value: 1}},
+			expectedBuckets: []int64{2},
+		},
+		"out of order samples older than the latest bucket are left to the TSDB": {
+			oooTimeWindow:   time.Hour,
+			expectedSamples: []model.SamplePair{{Timestamp: 12000, Value: 1}, {Timestamp: 21000, Value: 1}},
+			expectedBuckets: []int64{2},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			limits := defaultLimitsTestConfig()
+			limits.SampleDedupWindow = model.Duration(10 * time.Second)
+			limits.OutOfOrderTimeWindow = model.Duration(tc.oooTimeWindow)
+
+			ing, err := prepareIngesterWithBlocksStorageAndLimits(t, defaultIngesterTestConfig(t), limits, nil, t.TempDir(), prometheus.NewRegistry())
+			require.NoError(t, err)
+			require.NoError(t, services.StartAndAwaitRunning(context.Background(), ing))
+			defer services.StopAndAwaitTerminated(context.Background(), ing) //nolint:errcheck
+
+			// Wait until it's ACTIVE
+			test.Poll(t, time.Second, ring.ACTIVE, func() any {
+				return ing.lifecycler.GetState()
+			})
+
+			ctx := user.InjectOrgID(context.Background(), userID)
+			_, err = ing.Push(ctx, cortexpb.ToWriteRequest([]labels.Labels{series}, []cortexpb.Sample{{TimestampMs: 21000, Value: 1}}, nil, nil, cortexpb.API))
+			require.NoError(t, err)
+
+			// The out of order sample is in a bucket which hasn't been seen yet.
+			_, err = ing.Push(ctx, cortexpb.ToWriteRequest([]labels.Labels{series}, []cortexpb.Sample{{TimestampMs: 12000, Value: 1}}, nil, nil, cortexpb.API))
+			if tc.oooTimeWindow == 0 {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			res, _, err := runTestQuery(ctx, t, ing, labels.MatchEqual, model.MetricNameLabel, "testmetric")
+			require.NoError(t, err)
+			require.Len(t, res, 1)
+			assert.Equal(t, tc.expectedSamples, res[0].Values)
+
+			db, err := ing.getTSDB(userID)
+			require.NoError(t, err)
+			var buckets []int64
+			for i := range db.sampleDedup.stripes {
+				st := &db.sampleDedup.stripes[i]
+				st.mtx.Lock()
+				for _, s := range st.series {
+					buckets = append(buckets, s.bucket)
+				}
+				st.mtx.Unlock()
+			}
+			assert.Equal(t, tc.expectedBuckets, buckets)
+		})
+	}
+}
+
 func TestIngesterUserLimitExceededForNativeHistogram(t *testing.T) {
 	limits := defaultLimitsTestConfig()
 	limits.EnableNativeHistograms = true
@@ -2281,6 +2435,120 @@ func TestIngester_Push_StartTimestampAppendFailureMetrics(t *testing.T) {
 	}
 }
 
+func TestIngester_Push_SeriesMetadata(t *testing.T) {
+	metadata := []*cortexpb.MetricMetadata{
+		{MetricFamilyName: "test_requests_total", Type: cortexpb.COUNTER, Unit: "requests", Help: "Total number of requests."},
+		{MetricFamilyName: "test_temperature", Type: cortexpb.GAUGE, Unit: "celsius", Help: "Current temperature."},
+	}
+
+	for _, enabled := range []bool{false, true} {
+		t.Run(fmt.Sprintf("enabled=%t", enabled), func(t *testing.T) {
+			cfg := defaultIngesterTestConfig(t)
+			cfg.LifecyclerConfig.JoinAfter = 0
+
+			limits := defaultLimitsTestConfig()
+			limits.EnableSeriesMetadata = enabled
+			limits.MaxExemplars = 10
+			dataDir := t.TempDir()
+
+			ing, err := prepareIngesterWithBlocksStorageAndLimits(t, cfg, limits, nil, dataDir, prometheus.NewRegistry())
+			require.NoError(t, err)
+			require.NoError(t, services.StartAndAwaitRunning(context.Background(), ing))
+
+			test.Poll(t, 100*time.Millisecond, ring.ACTIVE, func() any {
+				return ing.lifecycler.GetState()
+			})
+
+			ctx := user.InjectOrgID(context.Background(), "test")
+			req := cortexpb.ToWriteRequest(
+				[]labels.Labels{
+					labels.FromStrings(labels.MetricName, "test_requests_total", "job", "test"),
+					labels.FromStrings(labels.MetricName, "test_temperature", "job", "test"),
+				},
+				[]cortexpb.Sample{
+					{Value: 10, TimestampMs: 2000, StartTimestampMs: 1000},
+					{Value: 20, TimestampMs: 2000},
+				},
+				metadata,
+				nil,
+				cortexpb.API,
+			)
+			// The request is returned to the pool once pushed, so the exemplars are built twice.
+			exemplars := func() []cortexpb.Exemplar {
+				return []cortexpb.Exemplar{
+					{Labels: cortexpb.FromLabelsToLabelAdapters(labels.FromStrings("trace_id", "abc")), Value: 10, TimestampMs: 2000},
+				}
+			}
+			req.Timeseries[0].Exemplars = append(req.Timeseries[0].Exemplars, exemplars()...)
+			_, err = ing.Push(ctx, req)
+			require.NoError(t, err)
+
+			metadataReq := &client.MetricsMetadataRequest{Limit: -1, LimitPerMetric: -1, Metric: ""}
+			res, err := ing.MetricsMetadata(ctx, metadataReq)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, metadata, res.Metadata)
+
+			// The metadata is periodically snapshotted only if stored in the TSDB.
+			db, err := ing.getTSDB("test")
+			require.NoError(t, err)
+			snapshotPath := filepath.Join(db.db.Dir(), seriesMetadataSnapshotFilename)
+			ing.snapshotAllUserMetadata()
+			if enabled {
+				require.FileExists(t, snapshotPath)
+				require.NoError(t, os.Remove(snapshotPath))
+			} else {
+				require.NoFileExists(t, snapshotPath)
+			}
+
+			// Restart the ingester, the metadata is snapshotted when closing the TSDB and restored.
+			require.NoError(t, services.StopAndAwaitTerminated(context.Background(), ing))
+			if enabled {
+				require.FileExists(t, snapshotPath)
+			} else {
+				require.NoFileExists(t, snapshotPath)
+			}
+			ing, err = prepareIngesterWithBlocksStorageAndLimits(t, cfg, limits, nil, dataDir, prometheus.NewRegistry())
+			require.NoError(t, err)
+			require.NoError(t, services.StartAndAwaitRunning(context.Background(), ing))
+			defer services.StopAndAwaitTerminated(context.Background(), ing) //nolint:errcheck
+
+			res, err = ing.MetricsMetadata(ctx, metadataReq)
+			require.NoError(t, err)
+			if enabled {
+				assert.ElementsMatch(t, metadata, res.Metadata)
+			} else {
+				assert.Empty(t, res.Metadata)
+			}
+
+			// The start timestamp and exemplars are replayed from the WAL in any case.
+			s := &mockQueryStreamServer{ctx: ctx}
+			err = ing.QueryStream(&client.QueryRequest{
+				StartTimestampMs: math.MinInt64,
+				EndTimestampMs:   math.MaxInt64,
+				Matchers:         []*client.LabelMatcher{{Type: client.EQUAL, Name: labels.MetricName, Value: "test_requests_total"}},
+			}, s)
+			require.NoError(t, err)
+			set, err := seriesSetFromResponseStream(s)
+			require.NoError(t, err)
+			queryRes, err := client.SeriesSetToQueryResponse(set)
+			require.NoError(t, err)
+			require.Len(t, queryRes.Timeseries, 1)
+			assert.Equal(t, []cortexpb.Sample{{Value: 0, TimestampMs: 1000}, {Value: 10, TimestampMs: 2000}}, queryRes.Timeseries[0].Samples)
+
+			exemplarRes, err := ing.QueryExemplars(ctx, &client.ExemplarQueryRequest{
+				StartTimestampMs: math.MinInt64,
+				EndTimestampMs:   math.MaxInt64,
+				Matchers: []*client.LabelMatchers{
+					{Matchers: []*client.LabelMatcher{{Type: client.EQUAL, Name: labels.MetricName, Value: "test_requests_total"}}},
+				},
+			})
+			require.NoError(t, err)
+			require.Len(t, exemplarRes.Timeseries, 1)
+			assert.Equal(t, exemplars(), exemplarRes.Timeseries[0].Exemplars)
+		})
+	}
+}
+
 // Referred from https://github.com/prometheus/prometheus/blob/v3.9.1/model/histogram/histogram_test.go#L1384.
 func TestIngester_PushNativeHistogramErrors(t *testing.T) {
 	metricLabelAdapters := []cortexpb.LabelAdapter{{Name: labels.MetricName, Value: "test"}}
@@ -3568,6 +3836,32 @@ func Test_Ingester_Query_ResourceThresholdBreached(t *testing.T) {
 	require.ErrorIs(t, err, limiter.ErrResourceLimitReached)
 }
 
+func Test_Ingester_Push_ShouldReportResourceUtilization(t *testing.T) {
+	i, err := prepareIngesterWithBlocksStorage(t, defaultIngesterTestConfig(t), prometheus.NewRegistry())
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), i))
+	defer services.StopAndAwaitTerminated(context.Background(), i) //nolint:errcheck
+
+	// Wait until it's ACTIVE
+	test.Poll(t, 1*time.Second, ring.ACTIVE, func() any {
+		return i.lifecycler.GetState()
+	})
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+	req, _ := mockWriteRequest(t, labels.FromStrings("__name__", "test_1"), 1, 100000)
+
+	// Nothing is reported if the resources aren't monitored.
+	resp, err := i.Push(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, &cortexpb.WriteResponse{}, resp)
+
+	i.resourceMonitor = &mockResourceMonitor{cpu: 0.4, heap: 0.6}
+	req, _ = mockWriteRequest(t, labels.FromStrings("__name__", "test_1"), 2, 200000)
+	resp, err = i.Push(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, &cortexpb.WriteResponse{CpuUtilization: 0.4, HeapUtilization: 0.6}, resp)
+}
+
 func TestIngester_LabelValues_ShouldNotCreateTSDBIfDoesNotExists(t *testing.T) {
 	i, err := prepareIngesterWithBlocksStorage(t, defaultIngesterTestConfig(t), prometheus.NewRegistry())
 	require.NoError(t, err)
@@ -4879,6 +5173,133 @@ func TestIngester_dontShipBlocksWhenTenantDeletionMarkerIsPresent(t *testing.T)
 	require.Equal(t, tsdbTenantMarkedForDeletion, i.closeAndDeleteUserTSDBIfIdle(userID))
 }
 
+func TestIngester_shipBlocks_ShouldPersistExemplars(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		t.Run(fmt.Sprintf("enabled=%t", enabled), func(t *testing.T) {
+			cfg := defaultIngesterTestConfig(t)
+			cfg.LifecyclerConfig.JoinAfter = 0
+			cfg.BlocksStorageConfig.TSDB.ShipConcurrency = 1
+			cfg.BlocksStorageConfig.TSDB.PersistExemplars = enabled
+
+			limits := defaultLimitsTestConfig()
+			limits.MaxExemplars = 10
+			i, err := prepareIngesterWithBlocksStorageAndLimits(t, cfg, limits, nil, "", prometheus.NewRegistry())
+			require.NoError(t, err)
+
+			// Use in-memory bucket.
+			bucket := objstore.NewInMemBucket()
+
+			i.TSDBState.bucket = bucket
+			require.NoError(t, services.StartAndAwaitRunning(context.Background(), i))
+			defer services.StopAndAwaitTerminated(context.Background(), i) //nolint:errcheck
+
+			// Wait until it's ACTIVE
+			test.Poll(t, 1*time.Second, ring.ACTIVE, func() any {
+				return i.lifecycler.GetState()
+			})
+
+			ctx := user.InjectOrgID(context.Background(), userID)
+			req, _ := mockWriteRequest(t, labels.FromStrings(labels.MetricName, "test", "job", "test"), 1, 1000)
+			req.Timeseries[0].Exemplars = []cortexpb.Exemplar{
+				{Labels: cortexpb.FromLabelsToLabelAdapters(labels.FromStrings("trace_id", "abc")), Value: 1, TimestampMs: 1000},
+			}
+			_, err = i.Push(ctx, req)
+			require.NoError(t, err)
+
+			i.compactBlocks(context.Background(), true, nil)
+			i.shipBlocks(context.Background(), nil)
+
+			db, err := i.getTSDB(userID)
+			require.NoError(t, err)
+			blocks := db.Blocks()
+			require.Len(t, blocks, 1)
+			require.Len(t, db.getCachedShippedBlocks(), 1)
+
+			series, err := cortex_tsdb.ReadBlockExemplars(context.Background(), log.NewNopLogger(), objstore.NewPrefixedBucket(bucket, userID), blocks[0].Meta().ULID)
+			require.NoError(t, err)
+			if !enabled {
+				assert.Empty(t, series)
+				return
+			}
+
+			assert.Equal(t, []exemplar.QueryResult{{
+				SeriesLabels: labels.FromStrings(labels.MetricName, "test", "job", "test"),
+				Exemplars:    []exemplar.Exemplar{{Labels: labels.FromStrings("trace_id", "abc"), Value: 1, Ts: 1000, HasTs: true}},
+			}}, series)
+		})
+	}
+}
+
+// failingExemplarsUploadBucket fails the uploads of the block exemplars files while failUploads is set.
+type failingExemplarsUploadBucket struct {
+	objstore.Bucket
+	failUploads atomic.Bool
+}
+
+func (b *failingExemplarsUploadBucket) Upload(ctx context.Context, name string, r io.Reader, opts ...objstore.ObjectUploadOption) error {
+	if b.failUploads.Load() && strings.HasSuffix(name, cortex_tsdb.BlockExemplarsFilename) {
+		return errors.New("upload failed")
+	}
+	return b.Bucket.Upload(ctx, name, r, opts...)
+}
+
+func TestIngester_shipBlocks_ShouldShipBlocksWhenExemplarsUploadFails(t *testing.T) {
+	cfg := defaultIngesterTestConfig(t)
+	cfg.LifecyclerConfig.JoinAfter = 0
+	cfg.BlocksStorageConfig.TSDB.ShipConcurrency = 1
+	cfg.BlocksStorageConfig.TSDB.PersistExemplars = true
+
+	limits := defaultLimitsTestConfig()
+	limits.MaxExemplars = 10
+	registry := prometheus.NewRegistry()
+	i, err := prepareIngesterWithBlocksStorageAndLimits(t, cfg, limits, nil, "", registry)
+	require.NoError(t, err)
+
+	bucket := &failingExemplarsUploadBucket{Bucket: objstore.NewInMemBucket()}
+	bucket.failUploads.Store(true)
+
+	i.TSDBState.bucket = bucket
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), i))
+	defer services.StopAndAwaitTerminated(context.Background(), i) //nolint:errcheck
+
+	// Wait until it's ACTIVE
+	test.Poll(t, 1*time.Second, ring.ACTIVE, func() any {
+		return i.lifecycler.GetState()
+	})
+
+	ctx := user.InjectOrgID(context.Background(), userID)
+	req, _ := mockWriteRequest(t, labels.FromStrings(labels.MetricName, "test", "job", "test"), 1, 1000)
+	req.Timeseries[0].Exemplars = []cortexpb.Exemplar{
+		{Labels: cortexpb.FromLabelsToLabelAdapters(labels.FromStrings("trace_id", "abc")), Value: 1, TimestampMs: 1000},
+	}
+	_, err = i.Push(ctx, req)
+	require.NoError(t, err)
+
+	i.compactBlocks(context.Background(), true, nil)
+	i.shipBlocks(context.Background(), nil)
+
+	// The block is shipped even if its exemplars failed to upload.
+	db, err := i.getTSDB(userID)
+	require.NoError(t, err)
+	blocks := db.Blocks()
+	require.Len(t, blocks, 1)
+	require.Len(t, db.getCachedShippedBlocks(), 1)
+
+	exists, err := bucket.Exists(context.Background(), filepath.Join(userID, blocks[0].Meta().ULID.String(), metadata.MetaFilename))
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	exists, err = bucket.Exists(context.Background(), filepath.Join(userID, blocks[0].Meta().ULID.String(), cortex_tsdb.BlockExemplarsFilename))
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(`
+		# HELP cortex_ingester_block_exemplars_upload_failures_total The total number of TSDB blocks whose exemplars failed to upload to the storage.
+		# TYPE cortex_ingester_block_exemplars_upload_failures_total counter
+		cortex_ingester_block_exemplars_upload_failures_total 1
+	`), "cortex_ingester_block_exemplars_upload_failures_total"))
+}
+
 func TestIngester_seriesCountIsCorrectAfterClosingTSDBForDeletedTenant(t *testing.T) {
 	cfg := defaultIngesterTestConfig(t)
 	cfg.LifecyclerConfig.JoinAfter = 0
@@ -5537,6 +5958,7 @@ func Test_Ingester_UserStats(t *testing.T) {
 	assert.InDelta(t, 0.2, res.ApiIngestionRate, 0.0001)
 	assert.InDelta(t, float64(0), res.RuleIngestionRate, 0.0001)
 	assert.Equal(t, uint64(3), res.NumSeries)
+	assert.Equal(t, uint64(3), res.SeriesChurn)
 }
 
 func Test_Ingester_AllUserStats(t *testing.T) {
@@ -5590,6 +6012,7 @@ func Test_Ingester_AllUserStats(t *testing.T) {
 				RuleIngestionRate: 0,
 				ActiveSeries:      3,
 				LoadedBlocks:      0,
+				SeriesChurn:       3,
 			},
 		},
 		{
@@ -5601,6 +6024,7 @@ func Test_Ingester_AllUserStats(t *testing.T) {
 				RuleIngestionRate: 0,
 				ActiveSeries:      2,
 				LoadedBlocks:      0,
+				SeriesChurn:       2,
 			},
 		},
 	}
@@ -5668,6 +6092,7 @@ func Test_Ingester_AllUserStatsHandler(t *testing.T) {
 				RuleIngestionRate: 0,
 				ActiveSeries:      3,
 				LoadedBlocks:      1,
+				SeriesChurn:       3,
 			},
 		},
 		{
@@ -5679,12 +6104,127 @@ func Test_Ingester_AllUserStatsHandler(t *testing.T) {
 				RuleIngestionRate: 0,
 				ActiveSeries:      2,
 				LoadedBlocks:      1,
+				SeriesChurn:       2,
 			},
 		},
 	}
 	assert.ElementsMatch(t, expect, resp)
 }
 
+func Test_Ingester_TopSeries(t *testing.T) {
+	series := []labels.Labels{
+		labels.FromStrings("__name__", "test_1", "route", "get_user", "status", "200"),
+		labels.FromStrings("__name__", "test_1", "route", "get_user", "status", "500"),
+		labels.FromStrings("__name__", "test_1", "route", "get_order", "status", "200"),
+		labels.FromStrings("__name__", "test_2", "status", "200"),
+	}
+
+	cfg := defaultIngesterTestConfig(t)
+	cfg.TopSeriesEnabled = true
+	cfg.TopSeriesLimit = 2
+	cfg.TopSeriesChurnWindow = time.Hour
+
+	i, err := prepareIngesterWithBlocksStorage(t, cfg, prometheus.NewRegistry())
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), i))
+	defer services.StopAndAwaitTerminated(context.Background(), i) //nolint:errcheck
+
+	// Wait until it's ACTIVE
+	test.Poll(t, 1*time.Second, ring.ACTIVE, func() any {
+		return i.lifecycler.GetState()
+	})
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+	for _, lbls := range series {
+		req, _ := mockWriteRequest(t, lbls, 1, 100000)
+		_, err := i.Push(ctx, req)
+		require.NoError(t, err)
+	}
+
+	expected := TopSeries{
+		ActiveMetricNames: []TopSeriesEntry{{Name: "__name__", Value: "test_1", Count: 3}, {Name: "__name__", Value: "test_2", Count: 1}},
+		ActiveLabelPairs:  []TopSeriesEntry{{Name: "status", Value: "200", Count: 3}, {Name: "route", Value: "get_user", Count: 2}},
+		ChurnMetricNames:  []TopSeriesEntry{{Name: "__name__", Value: "test_1", Count: 3}, {Name: "__name__", Value: "test_2", Count: 1}},
+		ChurnLabelPairs:   []TopSeriesEntry{{Name: "status", Value: "200", Count: 3}, {Name: "route", Value: "get_user", Count: 2}},
+	}
+
+	t.Run("gRPC", func(t *testing.T) {
+		res, err := i.TopSeries(ctx, &client.TopSeriesRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, expected, TopSeriesFromProto(res))
+
+		res, err = i.TopSeries(ctx, &client.TopSeriesRequest{Limit: 1})
+		require.NoError(t, err)
+		assert.Equal(t, expected.ActiveMetricNames[:1], TopSeriesFromProto(res).ActiveMetricNames)
+	})
+
+	t.Run("HTTP", func(t *testing.T) {
+		for userID, expected := range map[string]TopSeries{
+			"test": expected,
+			"unknown": {
+				ActiveMetricNames: []TopSeriesEntry{},
+				ActiveLabelPairs:  []TopSeriesEntry{},
+				ChurnMetricNames:  []TopSeriesEntry{},
+				ChurnLabelPairs:   []TopSeriesEntry{},
+			},
+		} {
+			response := httptest.NewRecorder()
+			request := mux.SetURLVars(httptest.NewRequest("GET", "/ingester/tenant/"+userID+"/top_series", nil), map[string]string{"id": userID})
+			i.TopSeriesHandler(response, request)
+			require.Equal(t, http.StatusOK, response.Code)
+
+			var actual TopSeries
+			require.NoError(t, json.Unmarshal(response.Body.Bytes(), &actual))
+			assert.Equal(t, expected, actual)
+		}
+
+		response := httptest.NewRecorder()
+		request := mux.SetURLVars(httptest.NewRequest("GET", "/ingester/tenant/test/top_series?limit=x", nil), map[string]string{"id": "test"})
+		i.TopSeriesHandler(response, request)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+	})
+}
+
+func Test_Ingester_DiscardedSeries(t *testing.T) {
+	limits := defaultLimitsTestConfig()
+	limits.DiscardedSeriesSamplingRate = 10
+
+	i, err := prepareIngesterWithBlocksStorageAndLimits(t, defaultIngesterTestConfig(t), limits, nil, "", prometheus.NewRegistry())
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), i))
+	defer services.StopAndAwaitTerminated(context.Background(), i) //nolint:errcheck
+
+	// Wait until it's ACTIVE
+	test.Poll(t, 1*time.Second, ring.ACTIVE, func() any {
+		return i.lifecycler.GetState()
+	})
+
+	series := labels.FromStrings(labels.MetricName, "test_1", "status", "200")
+	ctx := user.InjectOrgID(context.Background(), "test")
+	for _, ts := range []int64{100000, 50000} {
+		req, _ := mockWriteRequest(t, series, 1, ts)
+		_, err = i.Push(ctx, req)
+	}
+	require.Error(t, err)
+
+	for userID, expected := range map[string]int{"test": 1, "unknown": 0} {
+		response := httptest.NewRecorder()
+		request := mux.SetURLVars(httptest.NewRequest("GET", "/ingester/tenant/"+userID+"/discarded_series", nil), map[string]string{"id": userID})
+		i.DiscardedSeriesHandler(response, request)
+		require.Equal(t, http.StatusOK, response.Code)
+
+		var actual []discardedseries.Sample
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &actual))
+		require.Len(t, actual, expected)
+		if expected > 0 {
+			assert.Equal(t, series, actual[0].Labels)
+			assert.Equal(t, int64(50000), actual[0].TimestampMs)
+			assert.Equal(t, sampleOutOfOrder, actual[0].Reason)
+			assert.Contains(t, actual[0].Error, "out of order sample")
+		}
+	}
+}
+
 func TestIngesterCompactIdleBlock(t *testing.T) {
 	cfg := defaultIngesterTestConfig(t)
 	cfg.LifecyclerConfig.JoinAfter = 0
@@ -5,6 +5,7 @@ import (
 	"math"
 
 	"github.com/pkg/errors"
+	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
 
 	"github.com/cortexproject/cortex/pkg/util"
@@ -17,6 +18,7 @@ var (
 	errMaxSeriesPerUserLimitExceeded                = errors.New("per-user series limit exceeded")
 	errMaxNativeHistogramSeriesPerUserLimitExceeded = errors.New("per-user native histogram series limit exceeded")
 	errMaxMetadataPerUserLimitExceeded              = errors.New("per-user metric metadata limit exceeded")
+	errMaxSeriesChurnPerUserLimitExceeded           = errors.New("per-user series churn limit exceeded")
 )
 
 type errMaxSeriesPerLabelSetLimitExceeded struct {
@@ -106,6 +108,16 @@ func (l *Limiter) AssertMaxNativeHistogramSeriesPerUser(userID string, series in
 	return errMaxNativeHistogramSeriesPerUserLimitExceeded
 }
 
+// AssertMaxSeriesChurnPerUser limit has not been reached compared to the current
+// number of series created within the series churn window and returns an error if so.
+func (l *Limiter) AssertMaxSeriesChurnPerUser(userID string, churn int) error {
+	if actualLimit := l.maxSeriesChurnPerUser(userID); churn < actualLimit {
+		return nil
+	}
+
+	return errMaxSeriesChurnPerUserLimitExceeded
+}
+
 // AssertMaxMetricsWithMetadataPerUser limit has not been reached compared to the current
 // number of metrics with metadata in input and returns an error if so.
 func (l *Limiter) AssertMaxMetricsWithMetadataPerUser(userID string, metrics int) error {
@@ -147,6 +159,8 @@ func (l *Limiter) FormatError(userID string, err error, lbls labels.Labels) erro
 		return l.formatMaxSeriesPerUserError(userID)
 	case errors.Is(err, errMaxNativeHistogramSeriesPerUserLimitExceeded):
 		return l.formatMaxNativeHistogramsSeriesPerUserError(userID)
+	case errors.Is(err, errMaxSeriesChurnPerUserLimitExceeded):
+		return l.formatMaxSeriesChurnPerUserError(userID)
 	case errors.Is(err, errMaxSeriesPerMetricLimitExceeded):
 		return l.formatMaxSeriesPerMetricError(userID, lbls.Get(labels.MetricName))
 	case errors.Is(err, errMaxMetadataPerUserLimitExceeded):
@@ -180,6 +194,15 @@ func (l *Limiter) formatMaxNativeHistogramsSeriesPerUserError(userID string) err
 		minNonZero(localLimit, globalLimit), l.AdminLimitMessage, localLimit, globalLimit, actualLimit)
 }
 
+func (l *Limiter) formatMaxSeriesChurnPerUserError(userID string) error {
+	actualLimit := l.maxSeriesChurnPerUser(userID)
+	localLimit := l.limits.MaxLocalSeriesChurnPerUser(userID)
+	globalLimit := l.limits.MaxGlobalSeriesChurnPerUser(userID)
+
+	return fmt.Errorf("per-user series churn limit of %d series created within %s exceeded, %s (local limit: %d global limit: %d actual local limit: %d)",
+		minNonZero(localLimit, globalLimit), model.Duration(l.limits.SeriesChurnWindow(userID)), l.AdminLimitMessage, localLimit, globalLimit, actualLimit)
+}
+
 func (l *Limiter) formatMaxSeriesPerMetricError(userID string, metric string) error {
 	actualLimit := l.maxSeriesPerMetric(userID)
 	localLimit := l.limits.MaxLocalSeriesPerMetric(userID)
@@ -278,6 +301,14 @@ func (l *Limiter) maxNativeHistogramSeriesPerUser(userID string) int {
 	)
 }
 
+func (l *Limiter) maxSeriesChurnPerUser(userID string) int {
+	return l.maxByLocalAndGlobal(
+		userID,
+		l.limits.MaxLocalSeriesChurnPerUser,
+		l.limits.MaxGlobalSeriesChurnPerUser,
+	)
+}
+
 func (l *Limiter) maxMetadataPerUser(userID string) int {
 	return l.maxByLocalAndGlobal(
 		userID,
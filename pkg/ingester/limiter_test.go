@@ -429,6 +429,76 @@ func TestLimiter_AssertMaxSeriesPerUser(t *testing.T) {
 	}
 }
 
+func TestLimiter_AssertMaxSeriesChurnPerUser(t *testing.T) {
+	tests := map[string]struct {
+		maxLocalSeriesChurnPerUser  int
+		maxGlobalSeriesChurnPerUser int
+		ringReplicationFactor       int
+		ringIngesterCount           int
+		shardByAllLabels            bool
+		churn                       int
+		expected                    error
+	}{
+		"both local and global limit are disabled": {
+			maxLocalSeriesChurnPerUser:  0,
+			maxGlobalSeriesChurnPerUser: 0,
+			ringReplicationFactor:       1,
+			ringIngesterCount:           1,
+			shardByAllLabels:            false,
+			churn:                       100,
+			expected:                    nil,
+		},
+		"current series churn is below the limit": {
+			maxLocalSeriesChurnPerUser:  0,
+			maxGlobalSeriesChurnPerUser: 1000,
+			ringReplicationFactor:       3,
+			ringIngesterCount:           10,
+			shardByAllLabels:            true,
+			churn:                       299,
+			expected:                    nil,
+		},
+		"current series churn is above the limit": {
+			maxLocalSeriesChurnPerUser:  0,
+			maxGlobalSeriesChurnPerUser: 1000,
+			ringReplicationFactor:       3,
+			ringIngesterCount:           10,
+			shardByAllLabels:            true,
+			churn:                       300,
+			expected:                    errMaxSeriesChurnPerUserLimitExceeded,
+		},
+		"local limit is more restrictive than the global one": {
+			maxLocalSeriesChurnPerUser:  100,
+			maxGlobalSeriesChurnPerUser: 1000,
+			ringReplicationFactor:       3,
+			ringIngesterCount:           10,
+			shardByAllLabels:            true,
+			churn:                       100,
+			expected:                    errMaxSeriesChurnPerUserLimitExceeded,
+		},
+	}
+
+	for testName, testData := range tests {
+
+		t.Run(testName, func(t *testing.T) {
+			// Mock the ring
+			ring := &ringCountMock{}
+			ring.On("HealthyInstancesCount").Return(testData.ringIngesterCount)
+			ring.On("ZonesCount").Return(1)
+
+			// Mock limits
+			limits := validation.NewOverrides(validation.Limits{
+				MaxLocalSeriesChurnPerUser:  testData.maxLocalSeriesChurnPerUser,
+				MaxGlobalSeriesChurnPerUser: testData.maxGlobalSeriesChurnPerUser,
+			}, nil)
+
+			limiter := NewLimiter(limits, ring, util.ShardingStrategyDefault, testData.shardByAllLabels, testData.ringReplicationFactor, false, "")
+			actual := limiter.AssertMaxSeriesChurnPerUser("test", testData.churn)
+
+			assert.Equal(t, testData.expected, actual)
+		})
+	}
+}
+
 func TestLimiter_AssertMaxNativeHistogramsSeriesPerUser(t *testing.T) {
 	tests := map[string]struct {
 		maxLocalNativeHistogramsSeriesPerUser  int
@@ -0,0 +1,111 @@
+package ingester
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/cortexproject/cortex/pkg/cortexpb"
+)
+
+// appendLatencyWindow is the period over which the append latency percentile reported in the
+// load hints is computed. The latencies of the previous window are also taken into account, so
+// that the percentile doesn't drop to 0 right after a rotation.
+const appendLatencyWindow = 10 * time.Second
+
+// appendLatencyBuckets are the upper bounds, in seconds, of the buckets used to estimate the
+// append latency percentile, from 1ms to ~16s.
+var appendLatencyBuckets = prometheus.ExponentialBuckets(0.001, 2, 15)
+
+// latencyTracker estimates the percentiles of the latencies observed over the last two windows.
+type latencyTracker struct {
+	mtx      sync.Mutex
+	window   time.Duration
+	rotateAt time.Time
+	// Number of latencies observed in each bucket, the last bucket counting the latencies
+	// greater than the highest upper bound.
+	curr, prev []uint64
+}
+
+func newLatencyTracker(window time.Duration) *latencyTracker {
+	return &latencyTracker{
+		window: window,
+		curr:   make([]uint64, len(appendLatencyBuckets)+1),
+		prev:   make([]uint64, len(appendLatencyBuckets)+1),
+	}
+}
+
+func (t *latencyTracker) observe(now time.Time, latency time.Duration) {
+	idx := sort.SearchFloat64s(appendLatencyBuckets, latency.Seconds())
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	t.rotate(now)
+	t.curr[idx]++
+}
+
+// quantile returns the upper bound of the bucket the q-quantile falls into, or 0 if no latency
+// has been observed recently.
+func (t *latencyTracker) quantile(now time.Time, q float64) float64 {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	t.rotate(now)
+
+	var total uint64
+	for i := range t.curr {
+		total += t.curr[i] + t.prev[i]
+	}
+	if total == 0 {
+		return 0
+	}
+
+	rank := q * float64(total)
+	var count uint64
+	for i := range t.curr {
+		count += t.curr[i] + t.prev[i]
+		if float64(count) >= rank && i < len(appendLatencyBuckets) {
+			return appendLatencyBuckets[i]
+		}
+	}
+	return appendLatencyBuckets[len(appendLatencyBuckets)-1]
+}
+
+// rotate must be called with the mutex held.
+func (t *latencyTracker) rotate(now time.Time) {
+	if now.Before(t.rotateAt) {
+		return
+	}
+
+	if now.Before(t.rotateAt.Add(t.window)) {
+		t.curr, t.prev = t.prev, t.curr
+	} else {
+		// Nothing was observed in the previous window either.
+		clear(t.prev)
+	}
+	clear(t.curr)
+	t.rotateAt = now.Add(t.window)
+}
+
+// loadHints returns how close the ingester is to its instance limits, reported to the distributors
+// on the push stream so that they can pace the pushes before the limits are reached.
+func (i *Ingester) loadHints() *cortexpb.LoadHints {
+	hints := &cortexpb.LoadHints{
+		InflightPushRequests: i.inflightPushRequests.Load(),
+		InMemorySeries:       i.TSDBState.seriesCount.Load(),
+		AppendLatencyP99:     i.appendLatency.quantile(time.Now(), 0.99),
+	}
+	if i.ingestionRate != nil {
+		hints.IngestionRate = i.ingestionRate.Rate()
+	}
+
+	if il := i.getInstanceLimits(); il != nil {
+		hints.MaxInflightPushRequests = il.MaxInflightPushRequests
+		hints.MaxInMemorySeries = il.MaxInMemorySeries
+		hints.MaxIngestionRate = il.MaxIngestionRate
+	}
+	return hints
+}
@@ -0,0 +1,86 @@
+package ingester
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/cortexpb"
+	"github.com/cortexproject/cortex/pkg/ring"
+	"github.com/cortexproject/cortex/pkg/util/services"
+	"github.com/cortexproject/cortex/pkg/util/test"
+)
+
+func TestLatencyTracker(t *testing.T) {
+	now := time.Now()
+	tracker := newLatencyTracker(10 * time.Second)
+
+	// No latency observed yet.
+	assert.Equal(t, 0.0, tracker.quantile(now, 0.99))
+
+	for i := 0; i < 98; i++ {
+		tracker.observe(now, time.Millisecond)
+	}
+	tracker.observe(now, 100*time.Millisecond)
+	tracker.observe(now, 30*time.Second)
+
+	assert.Equal(t, 0.001, tracker.quantile(now, 0.5))
+	assert.Equal(t, 0.128, tracker.quantile(now, 0.99))
+	// Latencies above the highest bucket are capped to its upper bound.
+	assert.Equal(t, appendLatencyBuckets[len(appendLatencyBuckets)-1], tracker.quantile(now, 1))
+
+	// The latencies of the previous window are still taken into account.
+	now = now.Add(15 * time.Second)
+	assert.Equal(t, 0.128, tracker.quantile(now, 0.99))
+
+	// The latencies are forgotten after two windows.
+	now = now.Add(10 * time.Second)
+	assert.Equal(t, 0.0, tracker.quantile(now, 0.99))
+
+	// The latencies are forgotten right away if nothing was observed during the previous window.
+	tracker.observe(now, time.Second)
+	now = now.Add(25 * time.Second)
+	assert.Equal(t, 0.0, tracker.quantile(now, 0.99))
+}
+
+func TestPushStream_ShouldReportLoadHints(t *testing.T) {
+	cfg := defaultIngesterTestConfig(t)
+	cfg.InstanceLimitsFn = func() *InstanceLimits {
+		return &InstanceLimits{
+			MaxInMemorySeries:       100,
+			MaxInflightPushRequests: 10,
+		}
+	}
+	ing, err := prepareIngesterWithBlocksStorage(t, cfg, prometheus.NewRegistry())
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), ing))
+	t.Cleanup(func() {
+		_ = services.StopAndAwaitTerminated(context.Background(), ing)
+	})
+	test.Poll(t, time.Second, ring.ACTIVE, func() any {
+		return ing.lifecycler.GetState()
+	})
+
+	srv := &pushStreamServer{
+		ctx: user.InjectOrgID(context.Background(), "user-1"),
+		requests: []*cortexpb.StreamWriteRequest{
+			{TenantID: "user-1", Request: makeWriteReq("series_1")},
+			{TenantID: "user-1", Request: makeWriteReq("series_2")},
+		},
+	}
+	require.NoError(t, ing.PushStream(srv))
+	require.Len(t, srv.sent, 2)
+
+	for i, resp := range srv.sent {
+		require.NotNil(t, resp.LoadHints)
+		assert.Equal(t, int64(i+1), resp.LoadHints.InMemorySeries)
+		assert.Equal(t, int64(100), resp.LoadHints.MaxInMemorySeries)
+		assert.Equal(t, int64(10), resp.LoadHints.MaxInflightPushRequests)
+		assert.Greater(t, resp.LoadHints.AppendLatencyP99, 0.0)
+	}
+}
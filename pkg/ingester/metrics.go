@@ -32,30 +32,34 @@ const (
 )
 
 type ingesterMetrics struct {
-	ingestedSamples          prometheus.Counter
-	ingestedHistograms       prometheus.Counter
-	ingestedExemplars        prometheus.Counter
-	ingestedMetadata         prometheus.Counter
-	ingestedSamplesFail      prometheus.Counter
-	ingestedHistogramsFail   prometheus.Counter
-	startTimestampFail       *prometheus.CounterVec
-	ingestedExemplarsFail    prometheus.Counter
-	ingestedMetadataFail     prometheus.Counter
-	ingestedHistogramBuckets *prometheus.HistogramVec
-	oooLabelsTotal           *prometheus.CounterVec
-	queries                  prometheus.Counter
-	queriedSamples           prometheus.Histogram
-	queriedExemplars         prometheus.Histogram
-	queriedSeries            prometheus.Histogram
-	queriedChunks            prometheus.Histogram
-	memSeries                prometheus.Gauge
-	memMetadata              prometheus.Gauge
-	memUsers                 prometheus.Gauge
-	memSeriesCreatedTotal    *prometheus.CounterVec
-	memMetadataCreatedTotal  *prometheus.CounterVec
-	memSeriesRemovedTotal    *prometheus.CounterVec
-	memMetadataRemovedTotal  *prometheus.CounterVec
-	pushErrorsTotal          *prometheus.CounterVec
+	ingestedSamples              prometheus.Counter
+	ingestedHistograms           prometheus.Counter
+	ingestedExemplars            prometheus.Counter
+	ingestedMetadata             prometheus.Counter
+	ingestedSamplesFail          prometheus.Counter
+	ingestedHistogramsFail       prometheus.Counter
+	startTimestampFail           *prometheus.CounterVec
+	ingestedExemplarsFail        prometheus.Counter
+	blockExemplarsUploadFailures prometheus.Counter
+	ingestedMetadataFail         prometheus.Counter
+	ingestedHistogramBuckets     *prometheus.HistogramVec
+	oooLabelsTotal               *prometheus.CounterVec
+	queries                      prometheus.Counter
+	queriedSamples               prometheus.Histogram
+	queriedExemplars             prometheus.Histogram
+	queriedSeries                prometheus.Histogram
+	queriedChunks                prometheus.Histogram
+	memSeries                    prometheus.Gauge
+	memMetadata                  prometheus.Gauge
+	memUsers                     prometheus.Gauge
+	memSeriesCreatedTotal        *prometheus.CounterVec
+	memMetadataCreatedTotal      *prometheus.CounterVec
+	memSeriesRemovedTotal        *prometheus.CounterVec
+	memMetadataRemovedTotal      *prometheus.CounterVec
+	pushErrorsTotal              *prometheus.CounterVec
+	transferredSeries            *prometheus.CounterVec
+	transferredSamples           *prometheus.CounterVec
+	transferredTSDBBytes         *prometheus.CounterVec
 
 	activeSeriesPerUser        *prometheus.GaugeVec
 	activeNHSeriesPerUser      *prometheus.GaugeVec
@@ -135,6 +139,10 @@ func newIngesterMetrics(r prometheus.Registerer,
 			Name: "cortex_ingester_ingested_exemplars_failures_total",
 			Help: "The total number of exemplars that errored on ingestion.",
 		}),
+		blockExemplarsUploadFailures: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_ingester_block_exemplars_upload_failures_total",
+			Help: "The total number of TSDB blocks whose exemplars failed to upload to the storage.",
+		}),
 		ingestedMetadataFail: promauto.With(r).NewCounter(prometheus.CounterOpts{
 			Name: "cortex_ingester_ingested_metadata_failures_total",
 			Help: "The total number of metadata that errored on ingestion.",
@@ -151,6 +159,18 @@ func newIngesterMetrics(r prometheus.Registerer,
 			Name: "cortex_ingester_out_of_order_labels_total",
 			Help: "The total number of out of order label found per user.",
 		}, []string{"user"}),
+		transferredSeries: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_ingester_transferred_series_total",
+			Help: "The total number of head series transferred to or from another ingester on shutdown.",
+		}, []string{"direction"}),
+		transferredSamples: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_ingester_transferred_samples_total",
+			Help: "The total number of samples and native histograms transferred to or from another ingester on shutdown.",
+		}, []string{"direction", "type"}),
+		transferredTSDBBytes: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_ingester_transferred_tsdb_bytes_total",
+			Help: "The total number of bytes of TSDB files, WAL included, transferred to or from another ingester on shutdown.",
+		}, []string{"direction"}),
 		queries: promauto.With(r).NewCounter(prometheus.CounterOpts{
 			Name: "cortex_ingester_queries_total",
 			Help: "The total number of queries the ingester has handled.",
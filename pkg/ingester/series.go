@@ -6,4 +6,5 @@ const (
 	sampleOutOfBounds     = "sample-out-of-bounds"
 	sampleTooOld          = "sample-too-old"
 	nativeHistogramSample = "native-histogram-sample"
+	sampleDeduplicated    = "sample-deduplicated"
 )
@@ -0,0 +1,390 @@
+package ingester
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+)
+
+const (
+	topSeriesSketchDepth = 4
+	topSeriesSketchWidth = 1024
+
+	// Number of candidates tracked by each sketch for every top entry that can be returned.
+	// Tracking more candidates than returned entries reduces the chance of missing a heavy
+	// hitter which grew after the candidates set was full.
+	topSeriesCandidatesFactor = 4
+)
+
+// TopSeriesEntry is a metric name or label pair with its estimated number of series.
+type TopSeriesEntry struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	Count uint64 `json:"count"`
+}
+
+// TopSeries models the top metric names and label pairs of a single tenant, both by number
+// of active series and by number of series created within the churn window.
+type TopSeries struct {
+	ActiveMetricNames []TopSeriesEntry `json:"activeMetricNames"`
+	ActiveLabelPairs  []TopSeriesEntry `json:"activeLabelPairs"`
+	ChurnMetricNames  []TopSeriesEntry `json:"churnMetricNames"`
+	ChurnLabelPairs   []TopSeriesEntry `json:"churnLabelPairs"`
+}
+
+// TopSeriesFromProto converts the response received from an ingester.
+func TopSeriesFromProto(resp *client.TopSeriesResponse) TopSeries {
+	return TopSeries{
+		ActiveMetricNames: topSeriesEntriesFromProto(resp.ActiveMetricNames),
+		ActiveLabelPairs:  topSeriesEntriesFromProto(resp.ActiveLabelPairs),
+		ChurnMetricNames:  topSeriesEntriesFromProto(resp.ChurnMetricNames),
+		ChurnLabelPairs:   topSeriesEntriesFromProto(resp.ChurnLabelPairs),
+	}
+}
+
+func topSeriesEntriesFromProto(entries []client.TopSeriesEntry) []TopSeriesEntry {
+	out := make([]TopSeriesEntry, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, TopSeriesEntry{Name: e.Name, Value: e.Value, Count: e.Count})
+	}
+	return out
+}
+
+func (t TopSeries) toProto() *client.TopSeriesResponse {
+	return &client.TopSeriesResponse{
+		ActiveMetricNames: topSeriesEntriesToProto(t.ActiveMetricNames),
+		ActiveLabelPairs:  topSeriesEntriesToProto(t.ActiveLabelPairs),
+		ChurnMetricNames:  topSeriesEntriesToProto(t.ChurnMetricNames),
+		ChurnLabelPairs:   topSeriesEntriesToProto(t.ChurnLabelPairs),
+	}
+}
+
+func topSeriesEntriesToProto(entries []TopSeriesEntry) []client.TopSeriesEntry {
+	out := make([]client.TopSeriesEntry, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, client.TopSeriesEntry{Name: e.Name, Value: e.Value, Count: e.Count})
+	}
+	return out
+}
+
+// ParseTopSeriesLimit parses the optional "limit" parameter of a top series HTTP request.
+// It returns 0 if the parameter is missing.
+func ParseTopSeriesLimit(r *http.Request) (int, error) {
+	value := r.FormValue("limit")
+	if value == "" {
+		return 0, nil
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 0 {
+		return 0, fmt.Errorf("invalid limit parameter %q", value)
+	}
+	return limit, nil
+}
+
+// SortTopSeriesEntries sorts entries by count in descending order, and then by name and value.
+func SortTopSeriesEntries(entries []TopSeriesEntry) {
+	slices.SortFunc(entries, func(a, b TopSeriesEntry) int {
+		if a.Count != b.Count {
+			if a.Count > b.Count {
+				return -1
+			}
+			return 1
+		}
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Value, b.Value)
+	})
+}
+
+// topSeriesTracker keeps per-tenant top-K sketches of metric names and label pairs, by number
+// of active series and by series churn (number of series created within a sliding window).
+type topSeriesTracker struct {
+	mtx sync.Mutex
+
+	limit             int
+	activeMetricNames *topKSketch
+	activeLabelPairs  *topKSketch
+	churnMetricNames  *windowedTopKSketch
+	churnLabelPairs   *windowedTopKSketch
+}
+
+func newTopSeriesTracker(limit int, churnWindow time.Duration, now time.Time) *topSeriesTracker {
+	capacity := limit * topSeriesCandidatesFactor
+
+	return &topSeriesTracker{
+		limit:             limit,
+		activeMetricNames: newTopKSketch(capacity),
+		activeLabelPairs:  newTopKSketch(capacity),
+		churnMetricNames:  newWindowedTopKSketch(capacity, churnWindow, now),
+		churnLabelPairs:   newWindowedTopKSketch(capacity, churnWindow, now),
+	}
+}
+
+// seriesActivated implements activeSeriesObserver.
+func (t *topSeriesTracker) seriesActivated(series labels.Labels) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	series.Range(func(l labels.Label) {
+		if l.Name == model.MetricNameLabel {
+			t.activeMetricNames.add(l, 1)
+		} else {
+			t.activeLabelPairs.add(l, 1)
+		}
+	})
+}
+
+// seriesDeactivated implements activeSeriesObserver.
+func (t *topSeriesTracker) seriesDeactivated(series labels.Labels) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	series.Range(func(l labels.Label) {
+		if l.Name == model.MetricNameLabel {
+			t.activeMetricNames.add(l, -1)
+		} else {
+			t.activeLabelPairs.add(l, -1)
+		}
+	})
+}
+
+// seriesCreated is called when a new series is created in the TSDB head.
+func (t *topSeriesTracker) seriesCreated(series labels.Labels, now time.Time) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	series.Range(func(l labels.Label) {
+		if l.Name == model.MetricNameLabel {
+			t.churnMetricNames.add(l, now)
+		} else {
+			t.churnLabelPairs.add(l, now)
+		}
+	})
+}
+
+// topSeries returns up to limit entries for each category. A limit <= 0 or greater than the
+// configured one is capped to the configured limit.
+func (t *topSeriesTracker) topSeries(limit int, now time.Time) TopSeries {
+	if limit <= 0 || limit > t.limit {
+		limit = t.limit
+	}
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	return TopSeries{
+		ActiveMetricNames: t.activeMetricNames.top(limit),
+		ActiveLabelPairs:  t.activeLabelPairs.top(limit),
+		ChurnMetricNames:  t.churnMetricNames.top(limit, now),
+		ChurnLabelPairs:   t.churnLabelPairs.top(limit, now),
+	}
+}
+
+// topKSketch estimates the count of each key with a count-min sketch, and keeps a bounded
+// set of candidates with the highest estimated counts. Counts can be decreased too, as long
+// as the count of a key never goes below zero. Not safe for concurrent use.
+type topKSketch struct {
+	counters [topSeriesSketchDepth][topSeriesSketchWidth]int64
+
+	capacity   int
+	candidates map[labels.Label]struct{}
+
+	// Lower bound of the smallest estimated count among the candidates, used to avoid
+	// scanning all candidates each time a key which is not a candidate is added.
+	minCandidate int64
+}
+
+func newTopKSketch(capacity int) *topKSketch {
+	return &topKSketch{
+		capacity:   capacity,
+		candidates: make(map[labels.Label]struct{}, capacity),
+	}
+}
+
+func topKSketchHashes(l labels.Label) (uint64, uint64) {
+	h := xxhash.New()
+	_, _ = h.WriteString(l.Name)
+	_, _ = h.Write([]byte{model.SeparatorByte})
+	_, _ = h.WriteString(l.Value)
+	sum := h.Sum64()
+
+	// Double hashing: derive the per-row indexes from the two halves of the hash.
+	return sum & math.MaxUint32, (sum >> 32) | 1
+}
+
+// add updates the count of the input key by delta, and returns its new estimated count.
+func (s *topKSketch) add(l labels.Label, delta int64) int64 {
+	h1, h2 := topKSketchHashes(l)
+
+	estimate := int64(math.MaxInt64)
+	for i := range topSeriesSketchDepth {
+		idx := (h1 + uint64(i)*h2) % topSeriesSketchWidth
+		s.counters[i][idx] += delta
+		estimate = min(estimate, s.counters[i][idx])
+	}
+
+	if _, ok := s.candidates[l]; ok {
+		s.minCandidate = min(s.minCandidate, estimate)
+		return estimate
+	}
+	if delta <= 0 || s.capacity <= 0 {
+		return estimate
+	}
+
+	if len(s.candidates) < s.capacity {
+		if len(s.candidates) == 0 || estimate < s.minCandidate {
+			s.minCandidate = estimate
+		}
+		s.addCandidate(l)
+		return estimate
+	}
+
+	if estimate <= s.minCandidate {
+		return estimate
+	}
+
+	// Replace the candidate with the lowest estimate, if lower than the input key one.
+	var (
+		minKey      labels.Label
+		minEstimate = int64(math.MaxInt64)
+	)
+	for c := range s.candidates {
+		if e := s.estimate(c); e < minEstimate {
+			minKey, minEstimate = c, e
+		}
+	}
+
+	if minEstimate < estimate {
+		delete(s.candidates, minKey)
+		s.addCandidate(l)
+		minEstimate = s.minEstimate()
+	}
+	s.minCandidate = minEstimate
+
+	return estimate
+}
+
+func (s *topKSketch) addCandidate(l labels.Label) {
+	// Copy the strings to not retain the memory of the series labels.
+	s.candidates[labels.Label{Name: strings.Clone(l.Name), Value: strings.Clone(l.Value)}] = struct{}{}
+}
+
+func (s *topKSketch) minEstimate() int64 {
+	minEstimate := int64(math.MaxInt64)
+	for c := range s.candidates {
+		minEstimate = min(minEstimate, s.estimate(c))
+	}
+	return minEstimate
+}
+
+// estimate returns the estimated count of the input key, which is never lower than the real one.
+func (s *topKSketch) estimate(l labels.Label) int64 {
+	h1, h2 := topKSketchHashes(l)
+
+	estimate := int64(math.MaxInt64)
+	for i := range topSeriesSketchDepth {
+		estimate = min(estimate, s.counters[i][(h1+uint64(i)*h2)%topSeriesSketchWidth])
+	}
+	return estimate
+}
+
+// top returns up to limit candidates with the highest estimated count.
+func (s *topKSketch) top(limit int) []TopSeriesEntry {
+	return topEntries(s.candidates, limit, func(l labels.Label) float64 {
+		return float64(s.estimate(l))
+	})
+}
+
+func (s *topKSketch) reset() {
+	s.counters = [topSeriesSketchDepth][topSeriesSketchWidth]int64{}
+	clear(s.candidates)
+	s.minCandidate = 0
+}
+
+// windowedTopKSketch estimates the top keys added within a sliding window. It keeps a sketch for
+// the current and the previous window, and weights the previous window counts by the fraction of
+// it still overlapping the sliding window. Not safe for concurrent use.
+type windowedTopKSketch struct {
+	window      time.Duration
+	windowStart time.Time
+	current     *topKSketch
+	previous    *topKSketch
+}
+
+func newWindowedTopKSketch(capacity int, window time.Duration, now time.Time) *windowedTopKSketch {
+	return &windowedTopKSketch{
+		window:      window,
+		windowStart: now,
+		current:     newTopKSketch(capacity),
+		previous:    newTopKSketch(capacity),
+	}
+}
+
+func (s *windowedTopKSketch) add(l labels.Label, now time.Time) {
+	s.rotate(now)
+	s.current.add(l, 1)
+}
+
+func (s *windowedTopKSketch) rotate(now time.Time) {
+	elapsed := now.Sub(s.windowStart)
+	if elapsed < s.window {
+		return
+	}
+
+	// Reuse the previous sketch for the new window.
+	s.previous, s.current = s.current, s.previous
+	s.current.reset()
+
+	if elapsed >= 2*s.window {
+		// Nothing has been added within the last window.
+		s.previous.reset()
+	}
+	s.windowStart = s.windowStart.Add(elapsed.Truncate(s.window))
+}
+
+func (s *windowedTopKSketch) top(limit int, now time.Time) []TopSeriesEntry {
+	s.rotate(now)
+
+	previousWeight := 1 - float64(now.Sub(s.windowStart))/float64(s.window)
+	candidates := make(map[labels.Label]struct{}, len(s.current.candidates)+len(s.previous.candidates))
+	for c := range s.current.candidates {
+		candidates[c] = struct{}{}
+	}
+	for c := range s.previous.candidates {
+		candidates[c] = struct{}{}
+	}
+
+	return topEntries(candidates, limit, func(l labels.Label) float64 {
+		return float64(s.current.estimate(l)) + previousWeight*float64(s.previous.estimate(l))
+	})
+}
+
+func topEntries(candidates map[labels.Label]struct{}, limit int, estimate func(labels.Label) float64) []TopSeriesEntry {
+	entries := make([]TopSeriesEntry, 0, len(candidates))
+	for c := range candidates {
+		count := uint64(math.Round(estimate(c)))
+		if count == 0 {
+			continue
+		}
+		entries = append(entries, TopSeriesEntry{Name: c.Name, Value: c.Value, Count: count})
+	}
+
+	SortTopSeriesEntries(entries)
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries
+}
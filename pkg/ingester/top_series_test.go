@@ -0,0 +1,132 @@
+package ingester
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopKSketch(t *testing.T) {
+	s := newTopKSketch(3)
+
+	add := func(name string, count int, delta int64) {
+		for range count {
+			s.add(labels.Label{Name: "__name__", Value: name}, delta)
+		}
+	}
+
+	add("a", 10, 1)
+	add("b", 5, 1)
+	add("c", 3, 1)
+	assert.Equal(t, []TopSeriesEntry{
+		{Name: "__name__", Value: "a", Count: 10},
+		{Name: "__name__", Value: "b", Count: 5},
+	}, s.top(2))
+
+	// A key which is not a candidate replaces the lowest candidate once its count gets higher.
+	add("d", 3, 1)
+	assert.Len(t, s.candidates, 3)
+	assert.NotContains(t, s.candidates, labels.Label{Name: "__name__", Value: "d"})
+	add("d", 1, 1)
+	assert.Len(t, s.candidates, 3)
+	assert.Contains(t, s.candidates, labels.Label{Name: "__name__", Value: "d"})
+	assert.NotContains(t, s.candidates, labels.Label{Name: "__name__", Value: "c"})
+
+	// Decreasing counts updates the ranking, and keys with no series are not returned.
+	add("a", 10, -1)
+	assert.Equal(t, []TopSeriesEntry{
+		{Name: "__name__", Value: "b", Count: 5},
+		{Name: "__name__", Value: "d", Count: 4},
+	}, s.top(3))
+
+	// A key with a count dropped to zero is the first to be replaced.
+	add("e", 1, 1)
+	assert.NotContains(t, s.candidates, labels.Label{Name: "__name__", Value: "a"})
+	assert.Equal(t, []TopSeriesEntry{
+		{Name: "__name__", Value: "b", Count: 5},
+		{Name: "__name__", Value: "d", Count: 4},
+		{Name: "__name__", Value: "e", Count: 1},
+	}, s.top(3))
+}
+
+func TestTopKSketch_ShouldFindHeavyHittersAmongManyKeys(t *testing.T) {
+	s := newTopKSketch(20)
+
+	// Interleave many keys with a single series with a few keys with many series.
+	for i := range 10000 {
+		s.add(labels.Label{Name: "pod", Value: fmt.Sprintf("pod-%d", i)}, 1)
+		if i%10 == 0 {
+			s.add(labels.Label{Name: "pod", Value: fmt.Sprintf("heavy-%d", i%50)}, 1)
+		}
+	}
+
+	top := s.top(5)
+	require.Len(t, top, 5)
+	for _, e := range top {
+		assert.Contains(t, e.Value, "heavy-")
+		// Count-min sketches never underestimate.
+		assert.GreaterOrEqual(t, e.Count, uint64(200))
+	}
+}
+
+func TestWindowedTopKSketch(t *testing.T) {
+	now := time.Now()
+	s := newWindowedTopKSketch(10, time.Hour, now)
+
+	a := labels.Label{Name: "__name__", Value: "a"}
+	b := labels.Label{Name: "__name__", Value: "b"}
+
+	for range 10 {
+		s.add(a, now)
+	}
+	assert.Equal(t, []TopSeriesEntry{{Name: "__name__", Value: "a", Count: 10}}, s.top(10, now.Add(30*time.Minute)))
+
+	// After a rotation, the previous window counts are weighted by the overlap with the sliding window.
+	for range 4 {
+		s.add(b, now.Add(time.Hour))
+	}
+	assert.Equal(t, []TopSeriesEntry{
+		{Name: "__name__", Value: "a", Count: 10},
+		{Name: "__name__", Value: "b", Count: 4},
+	}, s.top(10, now.Add(time.Hour)))
+	assert.Equal(t, []TopSeriesEntry{
+		{Name: "__name__", Value: "b", Count: 4},
+		{Name: "__name__", Value: "a", Count: 3},
+	}, s.top(10, now.Add(time.Hour+45*time.Minute)))
+
+	// Nothing is returned once the keys are older than the sliding window.
+	assert.Equal(t, []TopSeriesEntry{{Name: "__name__", Value: "b", Count: 2}}, s.top(10, now.Add(2*time.Hour+30*time.Minute)))
+	assert.Empty(t, s.top(10, now.Add(4*time.Hour)))
+}
+
+func TestTopSeriesTracker(t *testing.T) {
+	now := time.Now()
+	tracker := newTopSeriesTracker(2, time.Hour, now)
+
+	series := []labels.Labels{
+		labels.FromStrings("__name__", "up", "job", "a"),
+		labels.FromStrings("__name__", "up", "job", "b"),
+		labels.FromStrings("__name__", "requests_total", "job", "a"),
+	}
+	for _, s := range series {
+		tracker.seriesCreated(s, now)
+		tracker.seriesActivated(s)
+	}
+	tracker.seriesDeactivated(series[0])
+	tracker.seriesDeactivated(series[1])
+
+	assert.Equal(t, TopSeries{
+		ActiveMetricNames: []TopSeriesEntry{{Name: "__name__", Value: "requests_total", Count: 1}},
+		ActiveLabelPairs:  []TopSeriesEntry{{Name: "job", Value: "a", Count: 1}},
+		ChurnMetricNames:  []TopSeriesEntry{{Name: "__name__", Value: "up", Count: 2}, {Name: "__name__", Value: "requests_total", Count: 1}},
+		ChurnLabelPairs:   []TopSeriesEntry{{Name: "job", Value: "a", Count: 2}, {Name: "job", Value: "b", Count: 1}},
+	}, tracker.topSeries(0, now))
+
+	// The requested limit can't exceed the configured one.
+	assert.Len(t, tracker.topSeries(1, now).ChurnMetricNames, 1)
+	assert.Len(t, tracker.topSeries(10, now).ChurnMetricNames, 2)
+}
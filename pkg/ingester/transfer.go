@@ -0,0 +1,802 @@
+package ingester
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"github.com/prometheus/prometheus/tsdb/chunks"
+	"github.com/weaveworks/common/user"
+
+	"github.com/cortexproject/cortex/pkg/chunk/encoding"
+	"github.com/cortexproject/cortex/pkg/cortexpb"
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+	"github.com/cortexproject/cortex/pkg/ring"
+	"github.com/cortexproject/cortex/pkg/util"
+	"github.com/cortexproject/cortex/pkg/util/services"
+	"github.com/cortexproject/cortex/pkg/util/users"
+)
+
+const (
+	transferDirectionSent     = "sent"
+	transferDirectionReceived = "received"
+
+	// Max size of the data sent in a single message when transferring the TSDB files.
+	transferTSDBMessageSize = 1024 * 1024
+)
+
+// handoffOwnersOp returns the replicas of a series as they were before this ingester
+// started leaving the ring: it's the same as ring.Write, except that LEAVING instances
+// are considered healthy.
+var handoffOwnersOp = ring.NewOp([]ring.InstanceState{ring.ACTIVE, ring.LEAVING}, func(s ring.InstanceState) bool {
+	return s != ring.ACTIVE && s != ring.LEAVING
+})
+
+// transferStream is the TransferChunks stream opened with one of the receiving
+// ingesters, along with the number of series and samples sent through it.
+type transferStream struct {
+	client client.HealthAndIngesterClient
+	stream client.Ingester_TransferChunksClient
+	sent   client.TransferChunksResponse
+}
+
+// TransferOut implements ring.FlushTransferer. If an ingester is waiting in the PENDING state to
+// replace this one, the TSDB of each tenant, WAL included, is handed off to it and it takes over our
+// tokens. Otherwise, the head series of each tenant are streamed to the ingesters taking over the
+// series tokens, the receipt of all the samples is verified and the blocks compacted but not shipped
+// yet are shipped. If the hand-off fails, an error is returned and the lifecycler falls back to flush.
+func (i *Ingester) TransferOut(ctx context.Context) error {
+	if !i.cfg.HandoffOnShutdown {
+		return ring.ErrTransferDisabled
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, i.cfg.HandoffTimeout)
+	defer cancel()
+
+	// The receivers get the tenant from each series or file, but the server insists on having an org ID.
+	ctx = user.InjectOrgID(ctx, "-1")
+
+	target, err := i.findPendingIngester(ctx)
+	if err != nil {
+		return err
+	}
+	if target != nil {
+		err := i.transferTSDBOut(ctx, *target)
+		if err == nil {
+			return nil
+		}
+		level.Warn(i.logger).Log("msg", "failed to hand off the TSDB to the pending ingester, transferring the head series instead", "to", target.Addr, "err", err)
+	}
+
+	return i.transferSeriesOut(ctx)
+}
+
+// findPendingIngester returns a healthy ingester waiting in the PENDING state to replace this one,
+// in the same zone if the zone-awareness is enabled, or nil if there is none.
+func (i *Ingester) findPendingIngester(ctx context.Context) (*ring.InstanceDesc, error) {
+	desc, err := i.lifecycler.KVStore.Get(ctx, RingKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the ingesters ring")
+	}
+	ringDesc, ok := desc.(*ring.Desc)
+	if !ok || ringDesc == nil {
+		return nil, nil
+	}
+
+	pending := ringDesc.FindIngestersByState(ring.PENDING)
+	sort.Slice(pending, func(a, b int) bool { return pending[a].Addr < pending[b].Addr })
+
+	now := time.Now()
+	for _, instance := range pending {
+		if !instance.IsHeartbeatHealthy(i.cfg.LifecyclerConfig.RingConfig.HeartbeatTimeout, now) {
+			continue
+		}
+		if i.cfg.LifecyclerConfig.RingConfig.ZoneAwarenessEnabled && instance.Zone != i.lifecycler.Zone {
+			continue
+		}
+		return &instance, nil
+	}
+	return nil, nil
+}
+
+// transferTSDBOut closes the TSDB of each tenant and sends all its files, WAL included, to the pending
+// ingester replacing this one, which verifies that this ingester is LEAVING and claims its tokens. If the
+// transfer fails, the TSDBs are opened again, so that their series can be transferred or flushed.
+func (i *Ingester) transferTSDBOut(ctx context.Context, target ring.InstanceDesc) (err error) {
+	level.Info(i.logger).Log("msg", "starting to hand off the TSDB to the pending ingester", "to", target.Addr)
+
+	// The TSDBs are closed so that their WAL is complete and no longer written.
+	i.closeAllTSDB()
+	defer func() {
+		if err == nil {
+			return
+		}
+		if openErr := i.openExistingTSDB(context.Background()); openErr != nil {
+			level.Error(i.logger).Log("msg", "failed to open the TSDBs again after the failed hand-off", "err", openErr)
+		}
+	}()
+
+	c, err := i.cfg.ingesterClientFactory(target.Addr, i.cfg.IngesterClientConfig, false)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create client to ingester %s", target.Addr)
+	}
+	defer func() {
+		if err := c.Close(); err != nil {
+			level.Warn(i.logger).Log("msg", "failed to close client to the receiving ingester", "addr", target.Addr, "err", err)
+		}
+	}()
+
+	stream, err := c.TransferTSDB(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open TSDB transfer stream to ingester %s", target.Addr)
+	}
+
+	dir := i.cfg.BlocksStorageConfig.TSDB.Dir
+	sent := client.TransferTSDBResponse{}
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// The TSDB dir doesn't exist if the ingester has never received any series.
+			if os.IsNotExist(err) && path == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		// Top level directories are user TSDBs, other files at the top level are not transferred.
+		userID, filename, ok := strings.Cut(filepath.ToSlash(rel), "/")
+		if !ok {
+			return nil
+		}
+		return i.sendTSDBFile(stream, userID, filename, path, &sent)
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to send the TSDB files to ingester %s", target.Addr)
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return errors.Wrapf(err, "failed to complete the TSDB transfer to ingester %s", target.Addr)
+	}
+	if resp.Files != sent.Files || resp.Bytes != sent.Bytes {
+		return fmt.Errorf("sent %d files and %d bytes, but ingester %s received %d files and %d bytes", sent.Files, sent.Bytes, target.Addr, resp.Files, resp.Bytes)
+	}
+
+	i.metrics.transferredTSDBBytes.WithLabelValues(transferDirectionSent).Add(float64(sent.Bytes))
+	level.Info(i.logger).Log("msg", "handed off the TSDB to the pending ingester", "to", target.Addr, "files", sent.Files, "bytes", sent.Bytes)
+	return nil
+}
+
+// sendTSDBFile sends the file of the user TSDB through the stream, splitting it in multiple messages.
+func (i *Ingester) sendTSDBFile(stream client.Ingester_TransferTSDBClient, userID, filename, path string, sent *client.TransferTSDBResponse) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	buf := make([]byte, transferTSDBMessageSize)
+	for first := true; ; first = false {
+		n, err := io.ReadFull(f, buf)
+		if errors.Is(err, io.EOF) && !first {
+			break
+		}
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return err
+		}
+
+		// The first message is sent even if the file is empty, so that the receiver creates it.
+		if err := stream.Send(&client.TimeSeriesFile{
+			FromIngesterId: i.lifecycler.ID,
+			UserId:         userID,
+			Filename:       filename,
+			Data:           buf[:n],
+		}); err != nil {
+			return err
+		}
+		sent.Bytes += uint64(n)
+
+		if n < len(buf) {
+			break
+		}
+	}
+
+	sent.Files++
+	return nil
+}
+
+// transferSeriesOut streams the head series of each tenant to the ingesters taking over the series
+// tokens, verifies that all the samples have been appended by the receivers and ships the blocks
+// compacted but not shipped yet.
+func (i *Ingester) transferSeriesOut(ctx context.Context) error {
+	// The receivers append the samples older than their head through the out-of-order head, so the
+	// transfer can't succeed if the out-of-order time window doesn't cover the head of each tenant.
+	// The default window is validated at startup, but a tenant can override it with a shorter one.
+	userIDs := i.getTSDBUsers()
+	for _, userID := range userIDs {
+		if err := i.checkTransferOutOfOrderWindow(userID); err != nil {
+			return err
+		}
+	}
+
+	// The ingester doesn't watch the ring, so we create a temporary client sharing the
+	// lifecycler's KV store to find out the ingesters taking over our tokens.
+	ingestersRing, err := ring.NewWithStoreClientAndStrategy(i.cfg.LifecyclerConfig.RingConfig, "ingester", RingKey, i.lifecycler.KVStore, ring.NewDefaultReplicationStrategy(), nil, i.logger)
+	if err != nil {
+		return errors.Wrap(err, "failed to create the ingesters ring client")
+	}
+	if err := services.StartAndAwaitRunning(ctx, ingestersRing); err != nil {
+		return errors.Wrap(err, "failed to start the ingesters ring client")
+	}
+	defer services.StopAndAwaitTerminated(context.Background(), ingestersRing) //nolint:errcheck
+
+	streams := map[string]*transferStream{}
+	defer func() {
+		for addr, s := range streams {
+			if err := s.client.Close(); err != nil {
+				level.Warn(i.logger).Log("msg", "failed to close client to the receiving ingester", "addr", addr, "err", err)
+			}
+		}
+	}()
+
+	level.Info(i.logger).Log("msg", "starting to transfer the head series to the ingesters taking over our tokens")
+
+	for _, userID := range userIDs {
+		if err := i.transferUserOut(ctx, ingestersRing, userID, streams); err != nil {
+			return errors.Wrapf(err, "failed to transfer the head series of user %s", userID)
+		}
+	}
+
+	for addr, s := range streams {
+		resp, err := s.stream.CloseAndRecv()
+		if err != nil {
+			return errors.Wrapf(err, "failed to complete the transfer to ingester %s", addr)
+		}
+		if err := verifyTransfer(&s.sent, resp); err != nil {
+			return errors.Wrapf(err, "failed to verify the transfer to ingester %s", addr)
+		}
+
+		level.Info(i.logger).Log("msg", "transferred head series", "to", addr, "series", resp.Series, "samples", resp.Samples, "histograms", resp.Histograms)
+	}
+
+	// The blocks compacted from the head before the shutdown are not transferred, so we make
+	// sure they're in the storage before leaving the ring.
+	if i.cfg.BlocksStorageConfig.TSDB.IsBlocksShippingEnabled() {
+		i.shipBlocks(ctx, nil)
+		if err := ctx.Err(); err != nil {
+			return errors.Wrap(err, "failed to ship the blocks")
+		}
+	}
+
+	level.Info(i.logger).Log("msg", "finished transferring the head series", "receivers", len(streams))
+	return nil
+}
+
+// checkTransferOutOfOrderWindow returns an error if the out-of-order time window of the user is shorter
+// than the time range of its head series, which the receivers would partially reject.
+func (i *Ingester) checkTransferOutOfOrderWindow(userID string) error {
+	db, err := i.getTSDB(userID)
+	if err != nil || db == nil {
+		return nil
+	}
+
+	head := db.db.Head()
+	if head.NumSeries() == 0 {
+		return nil
+	}
+
+	mint := head.MinTime()
+	if oooMint := head.MinOOOTime(); oooMint < mint {
+		mint = oooMint
+	}
+
+	window := time.Duration(i.limits.OutOfOrderTimeWindow(userID))
+	if headRange := time.Duration(head.MaxTime()-mint) * time.Millisecond; headRange > window {
+		return fmt.Errorf("the out-of-order time window of user %s (%s) is shorter than the time range of its head series (%s), which the receiving ingesters would reject", userID, window, headRange)
+	}
+	return nil
+}
+
+// transferUserOut sends the head series of the user to the ingesters taking over them.
+func (i *Ingester) transferUserOut(ctx context.Context, ingestersRing *ring.Ring, userID string, streams map[string]*transferStream) error {
+	db, err := i.getTSDB(userID)
+	if err != nil || db == nil {
+		return nil
+	}
+
+	head := db.db.Head()
+	if head.NumSeries() == 0 {
+		return nil
+	}
+
+	var subRing ring.ReadRing = ingestersRing
+	if i.cfg.DistributorShardingStrategy == util.ShardingStrategyShuffle {
+		subRing = ingestersRing.ShuffleShard(userID, i.limits.IngestionTenantShardSize(userID))
+	}
+
+	// Include the out-of-order head, which may hold samples older than the in-order head.
+	mint := head.MinTime()
+	if oooMint := head.MinOOOTime(); oooMint < mint {
+		mint = oooMint
+	}
+
+	q, err := db.ChunkQuerier(mint, head.MaxTime())
+	if err != nil {
+		return err
+	}
+	defer q.Close()
+
+	var (
+		it       chunks.Iterator
+		targets  []string
+		bufDescs [ring.GetBufferSize]ring.InstanceDesc
+		bufHosts [ring.GetBufferSize]string
+		bufZones = make(map[string]int, ring.GetBufferSize)
+
+		numSeries, numSamples, numHistograms uint64
+	)
+
+	allSeries := q.Select(ctx, false, nil, labels.MustNewMatcher(labels.MatchRegexp, labels.MetricName, ".+"))
+	for allSeries.Next() {
+		series := allSeries.At()
+		lbls := series.Labels()
+
+		ts := client.TimeSeriesChunk{
+			FromIngesterId: i.lifecycler.ID,
+			UserId:         userID,
+			Labels:         cortexpb.FromLabelsToLabelAdapters(lbls),
+		}
+		var seriesSamples, seriesHistograms uint64
+
+		it = series.Iterator(it)
+		for it.Next() {
+			meta := it.At()
+			if meta.Chunk == nil {
+				return errors.Errorf("unfilled chunk returned from TSDB chunk querier")
+			}
+
+			enc, err := encoding.FromPromChunkEncoding(meta.Chunk.Encoding())
+			if err != nil {
+				return err
+			}
+			if meta.Chunk.Encoding() == chunkenc.EncXOR {
+				seriesSamples += uint64(meta.Chunk.NumSamples())
+			} else {
+				seriesHistograms += uint64(meta.Chunk.NumSamples())
+			}
+
+			ts.Chunks = append(ts.Chunks, client.Chunk{
+				StartTimestampMs: meta.MinTime,
+				EndTimestampMs:   meta.MaxTime,
+				Encoding:         int32(enc),
+				Data:             meta.Chunk.Bytes(),
+			})
+		}
+		if err := it.Err(); err != nil {
+			return err
+		}
+
+		targets, err = i.handoffTargets(subRing, userID, ts.Labels, bufDescs[:0], bufHosts[:0], bufZones, targets[:0])
+		if err != nil {
+			return err
+		}
+
+		for _, addr := range targets {
+			s, err := i.transferStreamTo(ctx, addr, streams)
+			if err != nil {
+				return err
+			}
+			if err := s.stream.Send(&ts); err != nil {
+				return errors.Wrapf(err, "failed to send series to ingester %s", addr)
+			}
+
+			s.sent.Series++
+			s.sent.Samples += seriesSamples
+			s.sent.Histograms += seriesHistograms
+		}
+
+		numSeries++
+		numSamples += seriesSamples
+		numHistograms += seriesHistograms
+	}
+	if err := allSeries.Err(); err != nil {
+		return err
+	}
+
+	i.metrics.transferredSeries.WithLabelValues(transferDirectionSent).Add(float64(numSeries))
+	i.metrics.transferredSamples.WithLabelValues(transferDirectionSent, sampleMetricTypeFloat).Add(float64(numSamples))
+	i.metrics.transferredSamples.WithLabelValues(transferDirectionSent, sampleMetricTypeHistogram).Add(float64(numHistograms))
+	return nil
+}
+
+// handoffTargets returns the addresses of the ingesters the series must be transferred to:
+// the replicas of the series which were not replicas before this ingester started leaving
+// the ring. If this ingester wasn't a replica of the series, because the ring changed since
+// the series has been created, the series is transferred to all its replicas.
+func (i *Ingester) handoffTargets(subRing ring.ReadRing, userID string, lbls []cortexpb.LabelAdapter, bufDescs []ring.InstanceDesc, bufHosts []string, bufZones map[string]int, targets []string) ([]string, error) {
+	token, err := client.TokenForLabels(userID, lbls, i.cfg.DistributorShardByAllLabels)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := subRing.Get(token, ring.Write, bufDescs, bufHosts, bufZones)
+	if err != nil {
+		return nil, err
+	}
+	for _, instance := range after.Instances {
+		if instance.Addr != i.lifecycler.Addr {
+			targets = append(targets, instance.Addr)
+		}
+	}
+
+	// Do not reuse the buffers, because the instances of the first replication set are still referenced.
+	before, err := subRing.Get(token, handoffOwnersOp, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !before.Includes(i.lifecycler.Addr) {
+		return targets, nil
+	}
+
+	return slices.DeleteFunc(targets, before.Includes), nil
+}
+
+// transferStreamTo returns the stream opened with the ingester at addr, opening it if needed.
+func (i *Ingester) transferStreamTo(ctx context.Context, addr string, streams map[string]*transferStream) (*transferStream, error) {
+	if s, ok := streams[addr]; ok {
+		return s, nil
+	}
+
+	c, err := i.cfg.ingesterClientFactory(addr, i.cfg.IngesterClientConfig, false)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create client to ingester %s", addr)
+	}
+
+	stream, err := c.TransferChunks(ctx)
+	if err != nil {
+		_ = c.Close()
+		return nil, errors.Wrapf(err, "failed to open transfer stream to ingester %s", addr)
+	}
+
+	s := &transferStream{client: c, stream: stream}
+	streams[addr] = s
+	return s, nil
+}
+
+// verifyTransfer checks that all the series and samples sent have been appended by the receiver.
+func verifyTransfer(sent, received *client.TransferChunksResponse) error {
+	if received.FailedSamples > 0 || received.FailedHistograms > 0 {
+		return fmt.Errorf("the receiver failed to append %d samples and %d histograms", received.FailedSamples, received.FailedHistograms)
+	}
+	if sent.Series != received.Series || sent.Samples != received.Samples || sent.Histograms != received.Histograms {
+		return fmt.Errorf("sent %d series, %d samples and %d histograms, but the receiver got %d series, %d samples and %d histograms",
+			sent.Series, sent.Samples, sent.Histograms, received.Series, received.Samples, received.Histograms)
+	}
+	return nil
+}
+
+// TransferChunks receives the head series of a leaving ingester, see TransferOut. The response
+// reports the number of series and samples appended, and the number of samples which failed to
+// be appended, so that the leaving ingester can verify the receipt before leaving the ring.
+func (i *Ingester) TransferChunks(stream client.Ingester_TransferChunksServer) (err error) {
+	defer recoverIngester(i.logger, &err)
+	if err := i.checkRunning(); err != nil {
+		return err
+	}
+
+	resp := &client.TransferChunksResponse{}
+	fromIngesterID := ""
+
+	for {
+		series, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to receive transferred series")
+		}
+
+		fromIngesterID = series.FromIngesterId
+		if err := i.appendTransferredSeries(stream.Context(), series, resp); err != nil {
+			return errors.Wrapf(err, "failed to append the series transferred by ingester %s for user %s", fromIngesterID, series.UserId)
+		}
+	}
+
+	i.metrics.transferredSeries.WithLabelValues(transferDirectionReceived).Add(float64(resp.Series))
+	i.metrics.transferredSamples.WithLabelValues(transferDirectionReceived, sampleMetricTypeFloat).Add(float64(resp.Samples))
+	i.metrics.transferredSamples.WithLabelValues(transferDirectionReceived, sampleMetricTypeHistogram).Add(float64(resp.Histograms))
+
+	level.Info(i.logger).Log("msg", "received head series from leaving ingester", "from_ingester", fromIngesterID, "series", resp.Series,
+		"samples", resp.Samples, "histograms", resp.Histograms, "failed_samples", resp.FailedSamples, "failed_histograms", resp.FailedHistograms)
+
+	return stream.SendAndClose(resp)
+}
+
+// appendTransferredSeries appends all the samples of the series chunks to the user TSDB. Samples
+// which can't be appended, for example because they're out of order, are counted as failed.
+func (i *Ingester) appendTransferredSeries(ctx context.Context, series *client.TimeSeriesChunk, resp *client.TransferChunksResponse) (err error) {
+	db, err := i.getOrCreateTSDB(series.UserId, false)
+	if err != nil {
+		return err
+	}
+	if err := db.acquireAppendLock(); err != nil {
+		return err
+	}
+	defer db.releaseAppendLock()
+
+	var (
+		lbls = cortexpb.FromLabelAdaptersToLabelsWithCopy(series.Labels)
+		app  = db.Appender(ctx)
+		ref  storage.SeriesRef
+		it   chunkenc.Iterator
+	)
+
+	defer func() {
+		if err != nil {
+			_ = app.Rollback()
+		}
+	}()
+
+	for _, c := range series.Chunks {
+		chk, err := chunkenc.FromData(encoding.Encoding(c.Encoding).PromChunkEncoding(), c.Data)
+		if err != nil {
+			return err
+		}
+
+		it = chk.Iterator(it)
+		for vt := it.Next(); vt != chunkenc.ValNone; vt = it.Next() {
+			var (
+				r         storage.SeriesRef
+				appendErr error
+			)
+
+			switch vt {
+			case chunkenc.ValFloat:
+				t, v := it.At()
+				r, appendErr = app.Append(ref, lbls, t, v)
+			case chunkenc.ValHistogram:
+				t, h := it.AtHistogram(nil)
+				r, appendErr = app.AppendHistogram(ref, lbls, t, h, nil)
+			case chunkenc.ValFloatHistogram:
+				t, fh := it.AtFloatHistogram(nil)
+				r, appendErr = app.AppendHistogram(ref, lbls, t, nil, fh)
+			}
+
+			switch {
+			case appendErr != nil && vt == chunkenc.ValFloat:
+				resp.FailedSamples++
+			case appendErr != nil:
+				resp.FailedHistograms++
+			case vt == chunkenc.ValFloat:
+				ref = r
+				resp.Samples++
+			default:
+				ref = r
+				resp.Histograms++
+			}
+		}
+		if err := it.Err(); err != nil {
+			return err
+		}
+	}
+
+	if err := app.Commit(); err != nil {
+		return err
+	}
+
+	resp.Series++
+	return nil
+}
+
+// TransferTSDB receives the TSDB of each tenant, WAL included, from a leaving ingester replaced by this
+// PENDING ingester, see TransferOut. Once all the files have been received, the TSDBs are opened, the
+// tokens of the leaving ingester are claimed and the ingester becomes ACTIVE. The response reports the
+// number of files and bytes received, so that the leaving ingester can verify the receipt.
+func (i *Ingester) TransferTSDB(stream client.Ingester_TransferTSDBServer) (err error) {
+	defer recoverIngester(i.logger, &err)
+	if err := i.checkRunning(); err != nil {
+		return err
+	}
+
+	// Entering the JOINING state is only allowed from PENDING, so the ingester receives a single
+	// transfer and doesn't join the ring on its own while receiving it.
+	ctx := stream.Context()
+	if err := i.lifecycler.ChangeState(ctx, ring.JOINING); err != nil {
+		return errors.Wrap(err, "failed to enter the JOINING state to receive the TSDB transfer")
+	}
+	defer func() {
+		if i.lifecycler.GetState() == ring.ACTIVE {
+			return
+		}
+		if err := i.lifecycler.ChangeState(context.Background(), ring.PENDING); err != nil {
+			level.Error(i.logger).Log("msg", "failed to go back to the PENDING state after the failed TSDB transfer", "err", err)
+		}
+	}()
+
+	if users := i.getTSDBUsers(); len(users) > 0 {
+		return fmt.Errorf("cannot receive a TSDB transfer with %d TSDBs already open", len(users))
+	}
+
+	// The files are received in a temporary dir next to the TSDB dir, so that they're moved on the same filesystem.
+	dir := filepath.Clean(i.cfg.BlocksStorageConfig.TSDB.Dir)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+	tmpDir, err := os.MkdirTemp(filepath.Dir(dir), "tsdb-transfer-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	resp, fromIngesterID, err := receiveTSDBFiles(stream, tmpDir)
+	if err != nil {
+		return errors.Wrap(err, "failed to receive the TSDB files")
+	}
+	if fromIngesterID == "" {
+		// The leaving ingester has no TSDB, there's nothing to take over.
+		return stream.SendAndClose(resp)
+	}
+
+	if err := i.checkTransferFromLeavingIngester(ctx, fromIngesterID); err != nil {
+		return err
+	}
+
+	userIDs, err := os.ReadDir(tmpDir)
+	if err != nil {
+		return err
+	}
+	var moved []string
+	defer func() {
+		if err == nil {
+			return
+		}
+		i.closeAllTSDB()
+		for _, udir := range moved {
+			if err := os.RemoveAll(udir); err != nil {
+				level.Warn(i.logger).Log("msg", "failed to remove the TSDB received by the failed transfer", "dir", udir, "err", err)
+			}
+		}
+	}()
+	for _, userID := range userIDs {
+		udir := i.cfg.BlocksStorageConfig.TSDB.BlocksDir(userID.Name())
+		if err := os.Rename(filepath.Join(tmpDir, userID.Name()), udir); err != nil {
+			return errors.Wrapf(err, "failed to move the TSDB of user %s", userID.Name())
+		}
+		moved = append(moved, udir)
+	}
+
+	// The TSDBs are opened before becoming ACTIVE, so that the pushed series are appended to them.
+	if err := i.openExistingTSDB(ctx); err != nil {
+		return errors.Wrap(err, "failed to open the transferred TSDBs")
+	}
+	if err := i.lifecycler.ClaimTokensFor(ctx, fromIngesterID); err != nil {
+		return errors.Wrapf(err, "failed to claim the tokens of ingester %s", fromIngesterID)
+	}
+	if err := i.lifecycler.ChangeState(ctx, ring.ACTIVE); err != nil {
+		return errors.Wrap(err, "failed to enter the ACTIVE state after the TSDB transfer")
+	}
+
+	i.metrics.transferredTSDBBytes.WithLabelValues(transferDirectionReceived).Add(float64(resp.Bytes))
+	level.Info(i.logger).Log("msg", "received TSDB from leaving ingester", "from_ingester", fromIngesterID, "files", resp.Files, "bytes", resp.Bytes)
+
+	return stream.SendAndClose(resp)
+}
+
+// receiveTSDBFiles writes the files received through the stream to the user TSDB dirs in dir, and
+// returns the number of files and bytes received along with the ID of the sending ingester.
+func receiveTSDBFiles(stream client.Ingester_TransferTSDBServer, dir string) (*client.TransferTSDBResponse, string, error) {
+	var (
+		resp           = &client.TransferTSDBResponse{}
+		fromIngesterID string
+		received       = map[string]struct{}{}
+		currPath       string
+		curr           *os.File
+	)
+
+	closeCurr := func() error {
+		if curr == nil {
+			return nil
+		}
+		f := curr
+		curr = nil
+		if err := f.Sync(); err != nil {
+			_ = f.Close()
+			return err
+		}
+		return f.Close()
+	}
+	defer closeCurr() //nolint:errcheck
+
+	for {
+		file, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, "", err
+		}
+
+		if file.UserId == "" {
+			return nil, "", fmt.Errorf("missing user ID of the file %s", file.Filename)
+		}
+		if err := users.ValidTenantID(file.UserId); err != nil {
+			return nil, "", err
+		}
+		filename := filepath.FromSlash(file.Filename)
+		if !filepath.IsLocal(filename) {
+			return nil, "", fmt.Errorf("invalid file name %s of user %s", file.Filename, file.UserId)
+		}
+		fromIngesterID = file.FromIngesterId
+
+		// The files are sent one after the other, split in multiple messages.
+		path := filepath.Join(dir, file.UserId, filename)
+		if path != currPath {
+			if _, ok := received[path]; ok {
+				return nil, "", fmt.Errorf("file %s of user %s received twice", file.Filename, file.UserId)
+			}
+			if err := closeCurr(); err != nil {
+				return nil, "", err
+			}
+			if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+				return nil, "", err
+			}
+			if curr, err = os.Create(path); err != nil {
+				return nil, "", err
+			}
+			currPath = path
+			received[path] = struct{}{}
+			resp.Files++
+		}
+
+		if _, err := curr.Write(file.Data); err != nil {
+			return nil, "", err
+		}
+		resp.Bytes += uint64(len(file.Data))
+	}
+
+	if err := closeCurr(); err != nil {
+		return nil, "", err
+	}
+	return resp, fromIngesterID, nil
+}
+
+// checkTransferFromLeavingIngester returns an error if the ingester is not LEAVING the ring, because
+// its tokens can be claimed only once it's leaving, see ring.Lifecycler.ClaimTokensFor.
+func (i *Ingester) checkTransferFromLeavingIngester(ctx context.Context, fromIngesterID string) error {
+	desc, err := i.lifecycler.KVStore.Get(ctx, RingKey)
+	if err != nil {
+		return errors.Wrap(err, "failed to read the ingesters ring")
+	}
+	ringDesc, ok := desc.(*ring.Desc)
+	if !ok || ringDesc == nil {
+		return fmt.Errorf("ingester %s not found in the ring", fromIngesterID)
+	}
+
+	instance, ok := ringDesc.Ingesters[fromIngesterID]
+	if !ok {
+		return fmt.Errorf("ingester %s not found in the ring", fromIngesterID)
+	}
+	if instance.State != ring.LEAVING {
+		return fmt.Errorf("ingester %s is %s, but it should be LEAVING to transfer its TSDB", fromIngesterID, instance.State)
+	}
+	return nil
+}
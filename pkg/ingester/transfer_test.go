@@ -0,0 +1,290 @@
+package ingester
+
+import (
+	"context"
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/middleware"
+	"github.com/weaveworks/common/user"
+	"google.golang.org/grpc"
+
+	"github.com/cortexproject/cortex/pkg/ingester/client"
+	"github.com/cortexproject/cortex/pkg/ring"
+	cortex_tsdb "github.com/cortexproject/cortex/pkg/storage/tsdb"
+	"github.com/cortexproject/cortex/pkg/util/services"
+	"github.com/cortexproject/cortex/pkg/util/test"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+func TestIngester_TransferOut(t *testing.T) {
+	const userID = "user-1"
+
+	var (
+		series1 = labels.FromStrings(labels.MetricName, "foo", "l", "1")
+		series2 = labels.FromStrings(labels.MetricName, "foo", "l", "2")
+		series3 = labels.FromStrings(labels.MetricName, "bar")
+	)
+
+	tests := map[string]struct {
+		leavingOOOTimeWindow  time.Duration
+		receiverOOOTimeWindow time.Duration
+		expectedErr           string
+	}{
+		"should transfer the head series to the ingester taking over the tokens": {
+			leavingOOOTimeWindow:  time.Hour,
+			receiverOOOTimeWindow: time.Hour,
+		},
+		"should fail if the out-of-order time window doesn't cover the head": {
+			receiverOOOTimeWindow: time.Hour,
+			expectedErr:           "the out-of-order time window of user user-1 (0s) is shorter than the time range of its head series",
+		},
+		"should fail if the receiving ingester rejects out-of-order samples": {
+			leavingOOOTimeWindow: time.Hour,
+			expectedErr:          "the receiver failed to append 10 samples and 0 histograms",
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			ctx := user.InjectOrgID(context.Background(), userID)
+
+			leaving, receiver := prepareHandoffIngesters(t, handoffTestLimits(testData.leavingOOOTimeWindow), handoffTestLimits(testData.receiverOOOTimeWindow), 0)
+
+			for ts := int64(1); ts <= 10; ts++ {
+				for _, lbls := range []labels.Labels{series1, series2} {
+					req, _ := mockWriteRequest(t, lbls, float64(ts), ts)
+					_, err := leaving.Push(ctx, req)
+					require.NoError(t, err)
+				}
+
+				req, _ := mockHistogramWriteRequest(t, series3, ts, ts, false)
+				_, err := leaving.Push(ctx, req)
+				require.NoError(t, err)
+			}
+
+			// The distributors write the new samples to the receiver once the ingester is leaving.
+			req, _ := mockWriteRequest(t, series1, 100, 100)
+			_, err := receiver.Push(ctx, req)
+			require.NoError(t, err)
+
+			require.NoError(t, leaving.lifecycler.ChangeState(context.Background(), ring.LEAVING))
+
+			err = leaving.TransferOut(context.Background())
+			if testData.expectedErr != "" {
+				require.ErrorContains(t, err, testData.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, map[string]int{
+				series1.String(): 11,
+				series2.String(): 10,
+				series3.String(): 10,
+			}, countTestSamples(t, receiver, userID))
+		})
+	}
+}
+
+func TestIngester_TransferOut_ShouldHandOffTheTSDBToThePendingIngester(t *testing.T) {
+	const userID = "user-1"
+
+	series := labels.FromStrings(labels.MetricName, "foo")
+	ctx := user.InjectOrgID(context.Background(), userID)
+
+	// The receiver waits in the PENDING state to replace the leaving ingester. No out-of-order
+	// samples are appended, so the out-of-order time window isn't required.
+	leaving, receiver := prepareHandoffIngesters(t, handoffTestLimits(0), handoffTestLimits(0), time.Hour)
+	for ts := int64(1); ts <= 10; ts++ {
+		req, _ := mockWriteRequest(t, series, float64(ts), ts)
+		_, err := leaving.Push(ctx, req)
+		require.NoError(t, err)
+	}
+	leavingTokens := ringTokensFor(t, leaving, leaving.lifecycler.ID)
+	require.NotEmpty(t, leavingTokens)
+
+	require.NoError(t, leaving.lifecycler.ChangeState(context.Background(), ring.LEAVING))
+	require.NoError(t, leaving.TransferOut(context.Background()))
+
+	// The receiver took over the tokens of the leaving ingester and replayed its WAL.
+	assert.Equal(t, ring.ACTIVE, receiver.lifecycler.GetState())
+	assert.Equal(t, leavingTokens, ringTokensFor(t, receiver, receiver.lifecycler.ID))
+	assert.Equal(t, map[string]int{series.String(): 10}, countTestSamples(t, receiver, userID))
+	assert.Empty(t, leaving.getTSDBUsers())
+
+	// The receiver keeps ingesting the series.
+	req, _ := mockWriteRequest(t, series, 11, 11)
+	_, err := receiver.Push(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{series.String(): 11}, countTestSamples(t, receiver, userID))
+}
+
+func TestIngester_TransferOut_ShouldReopenTheTSDBIfTheHandOffFails(t *testing.T) {
+	const userID = "user-1"
+
+	series := labels.FromStrings(labels.MetricName, "foo")
+	ctx := user.InjectOrgID(context.Background(), userID)
+
+	leaving, receiver := prepareHandoffIngesters(t, handoffTestLimits(0), handoffTestLimits(0), time.Hour)
+	for ts := int64(1); ts <= 10; ts++ {
+		req, _ := mockWriteRequest(t, series, float64(ts), ts)
+		_, err := leaving.Push(ctx, req)
+		require.NoError(t, err)
+	}
+
+	// The receiver rejects the TSDB of an ingester which isn't LEAVING, and the head series
+	// can't be transferred without the out-of-order time window.
+	err := leaving.TransferOut(context.Background())
+	require.ErrorContains(t, err, "the out-of-order time window of user user-1")
+
+	assert.Equal(t, ring.PENDING, receiver.lifecycler.GetState())
+	assert.Empty(t, receiver.getTSDBUsers())
+	assert.Equal(t, map[string]int{series.String(): 10}, countTestSamples(t, leaving, userID))
+}
+
+func TestIngester_TransferOut_ShouldReturnErrTransferDisabledIfDisabled(t *testing.T) {
+	cfg := defaultIngesterTestConfig(t)
+	i, err := prepareIngesterWithBlocksStorage(t, cfg, prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	assert.Equal(t, ring.ErrTransferDisabled, i.TransferOut(context.Background()))
+}
+
+func handoffTestLimits(oooTimeWindow time.Duration) validation.Limits {
+	limits := defaultLimitsTestConfig()
+	limits.EnableNativeHistograms = true
+	limits.OutOfOrderTimeWindow = model.Duration(oooTimeWindow)
+	return limits
+}
+
+// ringTokensFor returns the tokens of the instance in the ring of the ingester.
+func ringTokensFor(t *testing.T, i *Ingester, instanceID string) ring.Tokens {
+	desc, err := i.lifecycler.KVStore.Get(context.Background(), RingKey)
+	require.NoError(t, err)
+	tokens, _ := desc.(*ring.Desc).TokensFor(instanceID)
+	return tokens
+}
+
+// countTestSamples returns the number of samples of each series of the user TSDB.
+func countTestSamples(t *testing.T, i *Ingester, userID string) map[string]int {
+	db, err := i.getTSDB(userID)
+	require.NoError(t, err)
+	require.NotNil(t, db)
+	q, err := db.Querier(math.MinInt64, math.MaxInt64)
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, q.Close()) })
+
+	samples := map[string]int{}
+	ss := q.Select(context.Background(), false, nil, labels.MustNewMatcher(labels.MatchRegexp, labels.MetricName, ".+"))
+	for ss.Next() {
+		it := ss.At().Iterator(nil)
+		for it.Next() != chunkenc.ValNone {
+			samples[ss.At().Labels().String()]++
+		}
+		require.NoError(t, it.Err())
+	}
+	require.NoError(t, ss.Err())
+	return samples
+}
+
+// prepareHandoffIngesters returns a leaving ingester with the hand-off enabled and the ingester
+// receiving its series, both running in a ring with a replication factor of 1. The leaving ingester
+// is ACTIVE, while the receiver is ACTIVE too if receiverJoinAfter is 0, otherwise it's PENDING.
+func prepareHandoffIngesters(t *testing.T, leavingLimits, receiverLimits validation.Limits, receiverJoinAfter time.Duration) (*Ingester, *Ingester) {
+	receiverCfg := defaultIngesterTestConfig(t)
+	receiverCfg.LifecyclerConfig.JoinAfter = receiverJoinAfter
+	receiverCfg.LifecyclerConfig.RingConfig.ReplicationFactor = 1
+	receiverCfg.LifecyclerConfig.ID = "ingester-2"
+	receiverCfg.LifecyclerConfig.Addr = "ingester-2"
+
+	receiver, err := prepareIngesterWithBlocksStorageAndLimits(t, receiverCfg, receiverLimits, nil, "", prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	serv := grpc.NewServer(grpc.StreamInterceptor(middleware.StreamServerUserHeaderInterceptor))
+	t.Cleanup(serv.GracefulStop)
+	client.RegisterIngesterServer(serv, receiver)
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	go func() {
+		_ = serv.Serve(listener)
+	}()
+
+	leavingCfg := defaultIngesterTestConfig(t)
+	leavingCfg.LifecyclerConfig.RingConfig.KVStore.Mock = receiverCfg.LifecyclerConfig.RingConfig.KVStore.Mock
+	leavingCfg.LifecyclerConfig.RingConfig.ReplicationFactor = 1
+	leavingCfg.LifecyclerConfig.ID = "ingester-1"
+	leavingCfg.LifecyclerConfig.Addr = "ingester-1"
+	leavingCfg.HandoffOnShutdown = true
+	leavingCfg.IngesterClientConfig = defaultClientTestConfig()
+	leavingCfg.ingesterClientFactory = func(addr string, cfg client.Config, useStreamConnection bool) (client.HealthAndIngesterClient, error) {
+		require.Equal(t, "ingester-2:0", addr)
+		return client.MakeIngesterClient(listener.Addr().String(), cfg, useStreamConnection)
+	}
+
+	leaving, err := prepareIngesterWithBlocksStorageAndLimits(t, leavingCfg, leavingLimits, nil, "", prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	expectedReceiverState := ring.ACTIVE
+	if receiverJoinAfter > 0 {
+		expectedReceiverState = ring.PENDING
+	}
+	for i, expectedState := range map[*Ingester]ring.InstanceState{receiver: expectedReceiverState, leaving: ring.ACTIVE} {
+		require.NoError(t, services.StartAndAwaitRunning(context.Background(), i))
+		t.Cleanup(func() {
+			_ = services.StopAndAwaitTerminated(context.Background(), i)
+		})
+
+		test.Poll(t, time.Second, expectedState, func() any {
+			return i.lifecycler.GetState()
+		})
+	}
+
+	return leaving, receiver
+}
+
+func TestVerifyTransfer(t *testing.T) {
+	sent := &client.TransferChunksResponse{Series: 2, Samples: 10, Histograms: 5}
+
+	assert.NoError(t, verifyTransfer(sent, &client.TransferChunksResponse{Series: 2, Samples: 10, Histograms: 5}))
+	assert.Error(t, verifyTransfer(sent, &client.TransferChunksResponse{Series: 1, Samples: 10, Histograms: 5}))
+	assert.Error(t, verifyTransfer(sent, &client.TransferChunksResponse{Series: 2, Samples: 9, Histograms: 5, FailedSamples: 1}))
+	assert.Error(t, verifyTransfer(sent, &client.TransferChunksResponse{Series: 2, Samples: 10, Histograms: 4, FailedHistograms: 1}))
+}
+
+func TestConfig_Validate_ShouldRequireTheOutOfOrderTimeWindowToCoverTheHeadOnHandOff(t *testing.T) {
+	tsdbCfg := cortex_tsdb.TSDBConfig{BlockRanges: cortex_tsdb.DurationList{2 * time.Hour}}
+
+	cfg := defaultIngesterTestConfig(t)
+	cfg.HandoffOnShutdown = true
+	cfg.HandoffTimeout = time.Minute
+
+	for window, expectedErr := range map[time.Duration]string{
+		0:              "ingester hand-off on shutdown requires the out-of-order time window to be at least 1.5 times the smallest TSDB block range (3h0m0s), got 0s",
+		2 * time.Hour:  "ingester hand-off on shutdown requires the out-of-order time window to be at least 1.5 times the smallest TSDB block range (3h0m0s), got 2h0m0s",
+		3 * time.Hour:  "",
+		24 * time.Hour: "",
+	} {
+		limits := defaultLimitsTestConfig()
+		limits.OutOfOrderTimeWindow = model.Duration(window)
+
+		err := cfg.Validate(nil, limits, tsdbCfg)
+		if expectedErr == "" {
+			require.NoError(t, err, window)
+		} else {
+			require.EqualError(t, err, expectedErr, window)
+		}
+	}
+
+	// The out-of-order time window isn't required without the hand-off.
+	cfg.HandoffOnShutdown = false
+	require.NoError(t, cfg.Validate(nil, defaultLimitsTestConfig(), tsdbCfg))
+}
@@ -1,10 +1,15 @@
 package ingester
 
 import (
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/metadata"
 
 	"github.com/cortexproject/cortex/pkg/cortexpb"
 	"github.com/cortexproject/cortex/pkg/ingester/client"
@@ -42,6 +47,11 @@ func newMetadataMap(l *Limiter, m *ingesterMetrics, v *validation.ValidateMetric
 }
 
 func (mm *userMetricsMetadata) add(metric string, metadata *cortexpb.MetricMetadata) error {
+	return mm.addAt(metric, metadata, time.Now())
+}
+
+// addAt adds the metadata, as last received at the given time.
+func (mm *userMetricsMetadata) addAt(metric string, metadata *cortexpb.MetricMetadata, seen time.Time) error {
 	mm.mtx.Lock()
 	defer mm.mtx.Unlock()
 
@@ -71,7 +81,9 @@ func (mm *userMetricsMetadata) add(metric string, metadata *cortexpb.MetricMetad
 		mm.metrics.memMetadataCreatedTotal.WithLabelValues(mm.userID).Inc()
 	}
 
-	mm.metricToMetadata[metric][*metadata] = time.Now()
+	if seen.After(set[*metadata]) {
+		set[*metadata] = seen
+	}
 	return nil
 }
 
@@ -155,3 +167,78 @@ func (mms metricMetadataSet) purge(deadline time.Time) int {
 
 	return deleted
 }
+
+// seriesMetadataByName returns the metadata of the write request by metric name, to be stored
+// along the series in the TSDB. Only the last metadata of each metric is kept, as the TSDB holds
+// a single metadata per series.
+func seriesMetadataByName(req []*cortexpb.MetricMetadata) map[string]metadata.Metadata {
+	if len(req) == 0 {
+		return nil
+	}
+
+	res := make(map[string]metadata.Metadata, len(req))
+	for _, m := range req {
+		res[m.GetMetricFamilyName()] = metadata.Metadata{
+			Type: cortexpb.MetricMetadataMetricTypeToMetricType(m.GetType()),
+			Unit: m.GetUnit(),
+			Help: m.GetHelp(),
+		}
+	}
+	return res
+}
+
+// seriesMetadataSnapshotFilename is the file, in the user's TSDB directory, the in-memory metadata
+// is periodically written to, so that it's restored when the TSDB is opened again without
+// replaying the WAL a second time.
+const seriesMetadataSnapshotFilename = "series_metadata.json"
+
+// metadataSnapshotEntry is a metadata of the snapshot, with the last time it's been received.
+type metadataSnapshotEntry struct {
+	Metadata cortexpb.MetricMetadata `json:"metadata"`
+	LastSeen time.Time               `json:"last_seen"`
+}
+
+// snapshot returns all the metadata held in memory, regardless of the metadata query limits.
+func (mm *userMetricsMetadata) snapshot() []metadataSnapshotEntry {
+	mm.mtx.RLock()
+	defer mm.mtx.RUnlock()
+
+	var res []metadataSnapshotEntry
+	for _, set := range mm.metricToMetadata {
+		for m, t := range set {
+			res = append(res, metadataSnapshotEntry{Metadata: m, LastSeen: t})
+		}
+	}
+	return res
+}
+
+// writeMetadataSnapshot writes the metadata to the snapshot file of the given TSDB directory.
+func writeMetadataSnapshot(dir string, entries []metadataSnapshotEntry) error {
+	b, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	snapshotPath := filepath.Join(dir, seriesMetadataSnapshotFilename)
+	if err := os.WriteFile(snapshotPath+".tmp", b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(snapshotPath+".tmp", snapshotPath)
+}
+
+// readMetadataSnapshot returns the metadata of the snapshot file of the given TSDB directory, if any.
+func readMetadataSnapshot(dir string) ([]metadataSnapshotEntry, error) {
+	b, err := os.ReadFile(filepath.Join(dir, seriesMetadataSnapshotFilename))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []metadataSnapshotEntry
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return nil, errors.Wrap(err, "decode metadata snapshot")
+	}
+	return entries, nil
+}
@@ -3,8 +3,10 @@ package ingester
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/cortexproject/cortex/pkg/cortexpb"
@@ -137,3 +139,39 @@ func Test_UserMetricsMetadata(t *testing.T) {
 		})
 	}
 }
+
+func Test_UserMetricsMetadata_Snapshot(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	m := newIngesterMetrics(reg, false, false, false, func() *InstanceLimits { return &InstanceLimits{} }, util_math.NewEWMARate(0.2, instanceIngestionRateTickInterval), &util_math.MaxTracker{}, &util_math.MaxTracker{}, false, false)
+	limiter := NewLimiter(validation.NewOverrides(validation.Limits{}, nil), nil, util.ShardingStrategyDefault, true, 1, false, "")
+	userMetricsMetadata := newMetadataMap(limiter, m, validation.NewValidateMetrics(reg), "user-1", false)
+
+	now := time.Now().Truncate(time.Millisecond)
+	counter := &cortexpb.MetricMetadata{MetricFamilyName: "requests_total", Type: cortexpb.COUNTER, Help: "Total requests."}
+	gauge := &cortexpb.MetricMetadata{MetricFamilyName: "temperature", Type: cortexpb.GAUGE, Unit: "celsius"}
+	require.NoError(t, userMetricsMetadata.addAt(counter.MetricFamilyName, counter, now))
+	require.NoError(t, userMetricsMetadata.addAt(gauge.MetricFamilyName, gauge, now.Add(-time.Minute)))
+
+	// An older time doesn't overwrite the last time the metadata has been received.
+	require.NoError(t, userMetricsMetadata.addAt(counter.MetricFamilyName, counter, now.Add(-time.Hour)))
+
+	// All the metadata is snapshotted, regardless of the metadata query limits.
+	dir := t.TempDir()
+	require.NoError(t, writeMetadataSnapshot(dir, userMetricsMetadata.snapshot()))
+
+	entries, err := readMetadataSnapshot(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	for i := range entries {
+		entries[i].LastSeen = entries[i].LastSeen.UTC()
+	}
+	assert.ElementsMatch(t, []metadataSnapshotEntry{
+		{Metadata: *counter, LastSeen: now.UTC()},
+		{Metadata: *gauge, LastSeen: now.Add(-time.Minute).UTC()},
+	}, entries)
+
+	// A missing snapshot isn't an error.
+	entries, err = readMetadataSnapshot(t.TempDir())
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -2,10 +2,13 @@ package ingester
 
 import (
 	"context"
+	"math"
 	"sync"
+	"time"
 
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
 	"github.com/prometheus/prometheus/tsdb"
 	"github.com/prometheus/prometheus/tsdb/index"
 	"github.com/segmentio/fasthash/fnv1a"
@@ -20,6 +23,7 @@ const (
 	perUserNativeHistogramSeriesLimit = "per_user_native_histogram_series_limit"
 	perMetricSeriesLimit              = "per_metric_series_limit"
 	perLabelsetSeriesLimit            = "per_labelset_series_limit"
+	perUserSeriesChurnLimit           = "per_user_series_churn_limit"
 )
 
 const numMetricCounterShards = 128
@@ -86,6 +90,204 @@ func (m *metricCounter) increaseSeriesForMetric(metric string) {
 	shard.mtx.Unlock()
 }
 
+// seriesChurnCounter counts the series created in the TSDB head within a sliding time window.
+// The count is approximated by adding the count of the previous fixed window, weighted by its
+// overlap with the sliding window, to the count of the current fixed window.
+type seriesChurnCounter struct {
+	window func() time.Duration
+
+	mtx         sync.Mutex
+	windowStart time.Time
+	current     int
+	previous    int
+}
+
+func newSeriesChurnCounter(window func() time.Duration, now time.Time) *seriesChurnCounter {
+	return &seriesChurnCounter{
+		window:      window,
+		windowStart: now,
+	}
+}
+
+func (c *seriesChurnCounter) increase(now time.Time) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if window := c.window(); window > 0 {
+		c.rotate(now, window)
+		c.current++
+	}
+}
+
+func (c *seriesChurnCounter) count(now time.Time) int {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	window := c.window()
+	if window <= 0 {
+		return 0
+	}
+
+	c.rotate(now, window)
+	previousWeight := 1 - float64(now.Sub(c.windowStart))/float64(window)
+	return c.current + int(math.Round(previousWeight*float64(c.previous)))
+}
+
+func (c *seriesChurnCounter) rotate(now time.Time, window time.Duration) {
+	elapsed := now.Sub(c.windowStart)
+	if elapsed < window {
+		return
+	}
+
+	if elapsed < 2*window {
+		c.previous = c.current
+	} else {
+		// No series has been created within the last window.
+		c.previous = 0
+	}
+	c.current = 0
+	c.windowStart = c.windowStart.Add(elapsed.Truncate(window))
+}
+
+const numSampleDedupStripes = 512
+
+// sampleDeduplicator keeps at most one sample per series per time bucket of the deduplication
+// window, so that identical streams pushed by the replicas of an HA pair don't cause out of
+// order samples. Only the latest bucket of each series is tracked: a sample in an older bucket
+// isn't deduplicated and is left to the TSDB out of order handling. Series are identified by
+// their TSDB head reference and spread across stripes, each with its own lock.
+type sampleDeduplicator struct {
+	stripes [numSampleDedupStripes]sampleDedupStripe
+}
+
+type sampleDedupStripe struct {
+	mtx    sync.Mutex
+	series map[storage.SeriesRef]*sampleDedupSeries
+}
+
+type sampleDedupSeries struct {
+	// bucket is the latest bucket in which a sample has been accepted, or is being appended.
+	bucket   int64
+	lastSeen int64
+	// committed is false while none of the samples which reserved the bucket has been committed yet.
+	committed bool
+	// pending is the number of reservations of the bucket which haven't been committed or released yet.
+	pending int
+	// previous is the committed bucket replaced by the reservation, restored if it's released.
+	previous    int64
+	hasPrevious bool
+}
+
+// sampleDedupKey identifies a reserved bucket of the deduplication window of a series.
+type sampleDedupKey struct {
+	ref    storage.SeriesRef
+	bucket int64
+}
+
+// reserved returns whether the key references a reserved bucket.
+func (k sampleDedupKey) reserved() bool {
+	return k.ref != 0
+}
+
+func newSampleDeduplicator() *sampleDeduplicator {
+	d := &sampleDeduplicator{}
+	for i := range d.stripes {
+		d.stripes[i].series = map[storage.SeriesRef]*sampleDedupSeries{}
+	}
+	return d
+}
+
+func (d *sampleDeduplicator) stripe(ref storage.SeriesRef) *sampleDedupStripe {
+	return &d.stripes[uint64(ref)%numSampleDedupStripes]
+}
+
+// reserve returns whether the sample with the given timestamp of the series with the given
+// reference should be ingested: it's rejected if another sample has already been committed for
+// the series in the same bucket of the window. A sample in a bucket which is only reserved by
+// samples being appended is accepted as well, because these samples may still fail and be
+// released. When the sample is accepted in the latest bucket, the bucket is reserved until it's
+// either committed or released, and the returned key references it.
+func (d *sampleDeduplicator) reserve(ref storage.SeriesRef, timestampMs, windowMs int64, now time.Time) (sampleDedupKey, bool) {
+	key := sampleDedupKey{ref: ref, bucket: timestampMs / windowMs}
+
+	st := d.stripe(ref)
+	st.mtx.Lock()
+	defer st.mtx.Unlock()
+
+	s, ok := st.series[ref]
+	if !ok {
+		st.series[ref] = &sampleDedupSeries{bucket: key.bucket, lastSeen: now.UnixNano(), pending: 1}
+		return key, true
+	}
+	switch {
+	case key.bucket == s.bucket && s.committed:
+		return sampleDedupKey{}, false
+	case key.bucket == s.bucket:
+		s.pending++
+		return key, true
+	case key.bucket < s.bucket:
+		return sampleDedupKey{}, true
+	}
+
+	if s.committed {
+		s.previous, s.hasPrevious = s.bucket, true
+	}
+	s.bucket = key.bucket
+	s.committed = false
+	s.pending = 1
+	s.lastSeen = now.UnixNano()
+	return key, true
+}
+
+// commit marks the given reserved buckets as accepted, once their samples have been committed.
+func (d *sampleDeduplicator) commit(keys []sampleDedupKey, now time.Time) {
+	for _, key := range keys {
+		st := d.stripe(key.ref)
+		st.mtx.Lock()
+		if s, ok := st.series[key.ref]; ok && s.bucket == key.bucket {
+			s.committed = true
+			s.hasPrevious = false
+			s.pending = max(s.pending-1, 0)
+			s.lastSeen = now.UnixNano()
+		}
+		st.mtx.Unlock()
+	}
+}
+
+// release removes the given reserved buckets whose samples failed to be ingested, once none of
+// their other reservations is pending, so that the next sample of the same buckets is accepted.
+func (d *sampleDeduplicator) release(keys ...sampleDedupKey) {
+	for _, key := range keys {
+		st := d.stripe(key.ref)
+		st.mtx.Lock()
+		if s, ok := st.series[key.ref]; ok && s.bucket == key.bucket {
+			s.pending = max(s.pending-1, 0)
+		}
+		if s, ok := st.series[key.ref]; ok && !s.committed && s.pending == 0 && s.bucket == key.bucket {
+			if s.hasPrevious {
+				s.bucket, s.committed, s.hasPrevious = s.previous, true, false
+			} else {
+				delete(st.series, key.ref)
+			}
+		}
+		st.mtx.Unlock()
+	}
+}
+
+// purge removes the series which haven't had a sample accepted since the given deadline.
+func (d *sampleDeduplicator) purge(deadline time.Time) {
+	for i := range d.stripes {
+		st := &d.stripes[i]
+		st.mtx.Lock()
+		for ref, s := range st.series {
+			if s.lastSeen < deadline.UnixNano() {
+				delete(st.series, ref)
+			}
+		}
+		st.mtx.Unlock()
+	}
+}
+
 type labelSetCounterEntry struct {
 	count  int
 	labels labels.Labels
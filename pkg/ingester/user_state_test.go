@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/storage"
@@ -99,6 +100,142 @@ func TestMetricCounter(t *testing.T) {
 	}
 }
 
+func TestSeriesChurnCounter(t *testing.T) {
+	now := time.Now()
+	window := time.Hour
+	c := newSeriesChurnCounter(func() time.Duration { return window }, now)
+
+	for range 10 {
+		c.increase(now)
+	}
+	assert.Equal(t, 10, c.count(now.Add(30*time.Minute)))
+
+	// After a rotation, the previous window count is weighted by the overlap with the sliding window.
+	for range 4 {
+		c.increase(now.Add(time.Hour))
+	}
+	assert.Equal(t, 14, c.count(now.Add(time.Hour)))
+	assert.Equal(t, 7, c.count(now.Add(time.Hour+45*time.Minute)))
+
+	// Series created more than a window ago are not counted anymore.
+	assert.Equal(t, 2, c.count(now.Add(2*time.Hour+30*time.Minute)))
+	assert.Equal(t, 0, c.count(now.Add(4*time.Hour)))
+
+	// A disabled window doesn't count anything.
+	window = 0
+	c.increase(now.Add(4 * time.Hour))
+	assert.Equal(t, 0, c.count(now.Add(4*time.Hour)))
+}
+
+func TestSampleDeduplicator(t *testing.T) {
+	now := time.Now()
+	window := int64(10000)
+	d := newSampleDeduplicator()
+
+	accept := func(ref storage.SeriesRef, timestampMs int64, now time.Time) bool {
+		key, ok := d.reserve(ref, timestampMs, window, now)
+		if ok && key.reserved() {
+			d.commit([]sampleDedupKey{key}, now)
+		}
+		return ok
+	}
+	series := func() int {
+		n := 0
+		for i := range d.stripes {
+			n += len(d.stripes[i].series)
+		}
+		return n
+	}
+
+	// The first sample of each bucket is accepted, the other ones are rejected.
+	assert.True(t, accept(1, 1000, now))
+	assert.False(t, accept(1, 1003, now))
+	assert.True(t, accept(2, 1003, now))
+	assert.True(t, accept(1, 11000, now))
+	assert.False(t, accept(1, 10998, now))
+
+	// Samples in a bucket older than the latest one are not deduplicated.
+	assert.True(t, accept(1, 25000, now))
+	assert.False(t, accept(1, 25001, now))
+	assert.True(t, accept(1, 5000, now))
+	assert.True(t, accept(1, 5001, now))
+	assert.False(t, accept(1, 25002, now))
+
+	// Idle series are purged.
+	assert.True(t, accept(2, 21000, now.Add(time.Minute)))
+	d.purge(now.Add(time.Second))
+	assert.Equal(t, 1, series())
+	assert.True(t, accept(1, 25000, now))
+}
+
+func TestSampleDeduplicator_ShouldReleaseBucketsOfFailedSamples(t *testing.T) {
+	now := time.Now()
+	window := int64(10000)
+	d := newSampleDeduplicator()
+
+	// A bucket which is only reserved doesn't reject the other samples, and it's removed once
+	// all of its reservations are released.
+	key, ok := d.reserve(1, 1000, window, now)
+	require.True(t, ok)
+	other, ok := d.reserve(1, 1003, window, now)
+	require.True(t, ok)
+
+	d.release(key)
+	assert.NotEmpty(t, d.stripe(1).series)
+	d.release(other)
+	assert.Empty(t, d.stripe(1).series)
+
+	// Once released, the next sample of the bucket is accepted.
+	key, ok = d.reserve(1, 1003, window, now)
+	require.True(t, ok)
+	d.commit([]sampleDedupKey{key}, now)
+
+	// Committed buckets are not released.
+	d.release(key)
+	_, ok = d.reserve(1, 1005, window, now)
+	assert.False(t, ok)
+
+	// Releasing a newer bucket restores the previously committed one.
+	key, ok = d.reserve(1, 11000, window, now)
+	require.True(t, ok)
+	d.release(key)
+	_, ok = d.reserve(1, 1007, window, now)
+	assert.False(t, ok)
+	_, ok = d.reserve(1, 11001, window, now)
+	assert.True(t, ok)
+}
+
+func TestSampleDeduplicator_ShouldNotLoseTheSampleOfTheOtherReplicaWhenTheFirstPushFails(t *testing.T) {
+	now := time.Now()
+	window := int64(10000)
+	d := newSampleDeduplicator()
+
+	// The first replica reserves the bucket, and the push of the second replica happens while
+	// the first one is still being appended.
+	first, ok := d.reserve(1, 1000, window, now)
+	require.True(t, ok)
+	second, ok := d.reserve(1, 1000, window, now)
+	require.True(t, ok, "a sample must not be deduplicated against an uncommitted one")
+
+	// The first push fails, the sample of the second replica is committed.
+	d.release(first)
+	d.commit([]sampleDedupKey{second}, now)
+
+	_, ok = d.reserve(1, 1005, window, now)
+	assert.False(t, ok)
+
+	// When the second push fails instead, the committed bucket of the first one is kept.
+	first, ok = d.reserve(1, 11000, window, now)
+	require.True(t, ok)
+	second, ok = d.reserve(1, 11000, window, now)
+	require.True(t, ok)
+	d.commit([]sampleDedupKey{first}, now)
+	d.release(second)
+
+	_, ok = d.reserve(1, 11005, window, now)
+	assert.False(t, ok)
+}
+
 func TestGetCardinalityForLimitsPerLabelSet(t *testing.T) {
 	ctx := context.Background()
 	testErr := errors.New("err")
@@ -16,6 +16,7 @@ import (
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
+	"github.com/oklog/ulid/v2"
 	"github.com/parquet-go/parquet-go"
 	"github.com/pkg/errors"
 	"github.com/prometheus-community/parquet-common/convert"
@@ -49,6 +50,9 @@ const (
 	ringKey = "parquet-converter"
 
 	converterMetaPrefix = "converter-meta-"
+
+	// dataColDuration is the time range covered by each data column of the parquet chunks files.
+	dataColDuration = 8 * time.Hour
 )
 
 var RingOp = ring.NewOp([]ring.InstanceState{ring.ACTIVE}, nil)
@@ -99,6 +103,9 @@ type Converter struct {
 	// Keep track of the last owned users.
 	// This is not thread safe now.
 	lastOwnedUsers map[string]struct{}
+
+	// Keep track of the blocks which failed to be converted, used by the status API.
+	failures *conversionFailures
 }
 
 func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
@@ -138,8 +145,9 @@ func newConverter(cfg Config, bkt objstore.InstrumentedBucket, storageCfg cortex
 		fetcherMetrics: block.NewFetcherMetrics(registerer, nil, nil),
 		metrics:        newMetrics(registerer),
 		bkt:            bkt,
+		failures:       newConversionFailures(),
 		baseConverterOptions: []convert.ConvertOption{
-			convert.WithColDuration(time.Hour * 8),
+			convert.WithColDuration(dataColDuration),
 			convert.WithRowGroupSize(cfg.MaxRowsPerRowGroup),
 		},
 	}
@@ -310,11 +318,7 @@ func (c *Converter) discoverUsers(ctx context.Context) ([]string, error) {
 	return active, err
 }
 
-func (c *Converter) convertUser(ctx context.Context, logger log.Logger, ring ring.ReadRing, userID string) error {
-	level.Info(logger).Log("msg", "start converting user")
-
-	uBucket := bucket.NewUserBucketClient(userID, c.bkt, c.limits)
-
+func (c *Converter) fetchBlocks(ctx context.Context, logger log.Logger, uBucket objstore.InstrumentedBucket, userID string) ([]*metadata.Meta, error) {
 	var blockLister block.Lister
 	switch cortex_tsdb.BlockDiscoveryStrategy(c.storageCfg.BucketStore.BlockDiscoveryStrategy) {
 	case cortex_tsdb.ConcurrentDiscovery:
@@ -323,11 +327,11 @@ func (c *Converter) convertUser(ctx context.Context, logger log.Logger, ring rin
 		blockLister = block.NewRecursiveLister(logger, uBucket)
 	case cortex_tsdb.BucketIndexDiscovery:
 		if !c.storageCfg.BucketStore.BucketIndex.Enabled {
-			return cortex_tsdb.ErrInvalidBucketIndexBlockDiscoveryStrategy
+			return nil, cortex_tsdb.ErrInvalidBucketIndexBlockDiscoveryStrategy
 		}
 		blockLister = bucketindex.NewBlockLister(logger, c.bkt, userID, c.limits)
 	default:
-		return cortex_tsdb.ErrBlockDiscoveryStrategy
+		return nil, cortex_tsdb.ErrBlockDiscoveryStrategy
 	}
 
 	ignoreDeletionMarkFilter := block.NewIgnoreDeletionMarkFilter(
@@ -350,12 +354,12 @@ func (c *Converter) convertUser(ctx context.Context, logger log.Logger, ring rin
 		[]block.MetadataFilter{ignoreDeletionMarkFilter},
 	)
 	if err != nil {
-		return errors.Wrap(err, "error creating block fetcher")
+		return nil, errors.Wrap(err, "error creating block fetcher")
 	}
 
 	blks, _, err := fetcher.Fetch(ctx)
 	if err != nil {
-		return errors.Wrapf(err, "failed to fetch blocks for user %s", userID)
+		return nil, errors.Wrapf(err, "failed to fetch blocks for user %s", userID)
 	}
 
 	blocks := make([]*metadata.Meta, 0, len(blks))
@@ -367,6 +371,19 @@ func (c *Converter) convertUser(ctx context.Context, logger log.Logger, ring rin
 		return blocks[i].MinTime > blocks[j].MinTime
 	})
 
+	return blocks, nil
+}
+
+func (c *Converter) convertUser(ctx context.Context, logger log.Logger, ring ring.ReadRing, userID string) error {
+	level.Info(logger).Log("msg", "start converting user")
+
+	uBucket := bucket.NewUserBucketClient(userID, c.bkt, c.limits)
+
+	blocks, err := c.fetchBlocks(ctx, logger, uBucket, userID)
+	if err != nil {
+		return err
+	}
+
 	for _, b := range blocks {
 		if ctx.Err() != nil {
 			return ctx.Err()
@@ -398,7 +415,7 @@ func (c *Converter) convertUser(ctx context.Context, logger log.Logger, ring rin
 
 		if err := os.RemoveAll(c.compactRootDir()); err != nil {
 			level.Error(logger).Log("msg", "failed to remove work directory", "path", c.compactRootDir(), "err", err)
-			if c.checkConvertError(userID, err) {
+			if c.checkConvertError(userID, b.ULID, err) {
 				return err
 			}
 			continue
@@ -410,7 +427,7 @@ func (c *Converter) convertUser(ctx context.Context, logger log.Logger, ring rin
 
 		if err := block.Download(ctx, logger, uBucket, b.ULID, bdir, objstore.WithFetchConcurrency(10)); err != nil {
 			level.Error(logger).Log("msg", "failed to download block", "block", b.ULID.String(), "err", err)
-			if c.checkConvertError(userID, err) {
+			if c.checkConvertError(userID, b.ULID, err) {
 				return err
 			}
 			continue
@@ -419,13 +436,39 @@ func (c *Converter) convertUser(ctx context.Context, logger log.Logger, ring rin
 		tsdbBlock, err := tsdb.OpenBlock(logutil.GoKitLogToSlog(logger), bdir, c.pool, tsdb.DefaultPostingsDecoderFactory)
 		if err != nil {
 			level.Error(logger).Log("msg", "failed to open block", "block", b.ULID.String(), "err", err)
-			if c.checkConvertError(userID, err) {
+			if c.checkConvertError(userID, b.ULID, err) {
 				return err
 			}
 			continue
 		}
 
-		level.Info(logger).Log("msg", "converting block", "block", b.ULID.String(), "dir", bdir)
+		convertible := newConvertibleBlock(tsdbBlock)
+
+		reason, err := checkBlockCompatibility(ctx, convertible, dataColDuration.Milliseconds())
+		if err != nil {
+			_ = tsdbBlock.Close()
+			level.Error(logger).Log("msg", "failed to check block compatibility", "block", b.ULID.String(), "err", err)
+			if c.checkConvertError(userID, b.ULID, err) {
+				return err
+			}
+			continue
+		}
+		if reason != "" {
+			_ = tsdbBlock.Close()
+			level.Warn(logger).Log("msg", "block is not compatible with parquet format and will be queried from TSDB", "block", b.ULID.String(), "reason", reason)
+			if err := cortex_parquet.WriteIncompatibleConverterMark(ctx, b.ULID, uBucket, reason); err != nil {
+				level.Error(logger).Log("msg", "failed to write parquet converter marker", "block", b.ULID.String(), "err", err)
+				if c.checkConvertError(userID, b.ULID, err) {
+					return err
+				}
+				continue
+			}
+			c.failures.remove(userID, b.ULID)
+			c.metrics.incompatibleBlocks.WithLabelValues(userID).Inc()
+			continue
+		}
+
+		level.Info(logger).Log("msg", "converting block", "block", b.ULID.String(), "dir", bdir, "ooo", b.Compaction.FromOutOfOrder(), "histogram_samples", b.Stats.NumHistogramSamples)
 		start := time.Now()
 
 		converterOpts := append(c.baseConverterOptions, convert.WithName(b.ULID.String()))
@@ -444,7 +487,7 @@ func (c *Converter) convertUser(ctx context.Context, logger log.Logger, ring rin
 			uBucket,
 			tsdbBlock.MinTime(),
 			tsdbBlock.MaxTime(),
-			[]convert.Convertible{tsdbBlock},
+			[]convert.Convertible{convertible},
 			util_log.GoKitLogToSlog(logger),
 			converterOpts...,
 		)
@@ -453,7 +496,7 @@ func (c *Converter) convertUser(ctx context.Context, logger log.Logger, ring rin
 
 		if err != nil {
 			level.Error(logger).Log("msg", "failed to convert block", "block", b.ULID.String(), "err", err)
-			if c.checkConvertError(userID, err) {
+			if c.checkConvertError(userID, b.ULID, err) {
 				return err
 			}
 			continue
@@ -464,7 +507,7 @@ func (c *Converter) convertUser(ctx context.Context, logger log.Logger, ring rin
 
 		if err = cortex_parquet.WriteConverterMark(ctx, b.ULID, uBucket, numShards); err != nil {
 			level.Error(logger).Log("msg", "failed to write parquet converter marker", "block", b.ULID.String(), "err", err)
-			if c.checkConvertError(userID, err) {
+			if c.checkConvertError(userID, b.ULID, err) {
 				return err
 			}
 			continue
@@ -472,6 +515,7 @@ func (c *Converter) convertUser(ctx context.Context, logger log.Logger, ring rin
 		duration = time.Since(start)
 		level.Info(logger).Log("msg", "successfully uploaded parquet converter marker", "block", b.ULID.String(), "duration", duration)
 
+		c.failures.remove(userID, b.ULID)
 		c.metrics.convertedBlocks.WithLabelValues(userID).Inc()
 		metaAttrs, err := uBucket.Attributes(ctx, path.Join(b.ULID.String(), metadata.MetaFilename))
 		if err != nil {
@@ -486,11 +530,12 @@ func (c *Converter) convertUser(ctx context.Context, logger log.Logger, ring rin
 	return nil
 }
 
-func (c *Converter) checkConvertError(userID string, err error) (terminate bool) {
+func (c *Converter) checkConvertError(userID string, blockID ulid.ULID, err error) (terminate bool) {
 	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || c.isCausedByPermissionDenied(err) {
 		terminate = true
 	} else {
 		c.metrics.convertBlockFailures.WithLabelValues(userID).Inc()
+		c.failures.add(userID, blockID, err)
 	}
 	return
 }
@@ -557,9 +602,20 @@ func (c *Converter) RingHandler(w http.ResponseWriter, req *http.Request) {
 	c.ring.ServeHTTP(w, req)
 }
 
+// RingAdminHandler is an HTTP handler that serves the JSON ring admin API.
+func (c *Converter) RingAdminHandler(w http.ResponseWriter, req *http.Request) {
+	if c.State() != services.Running {
+		http.Error(w, "Parquet Converter is not running yet.", http.StatusServiceUnavailable)
+		return
+	}
+
+	c.ring.AdminHandler(w, req)
+}
+
 func (c *Converter) cleanupMetricsForNotOwnedUser(userID string) {
 	if _, ok := c.lastOwnedUsers[userID]; ok {
 		c.metrics.deleteMetricsForTenant(userID)
+		c.failures.deleteUser(userID)
 	}
 }
 
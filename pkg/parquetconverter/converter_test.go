@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"io"
 	"math/rand"
+	"net/http"
+	"net/http/httptest"
 	"path"
 	"strings"
 	"testing"
@@ -17,7 +19,9 @@ import (
 	"github.com/oklog/ulid/v2"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/promslog"
 	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
 	"github.com/prometheus/prometheus/tsdb"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -25,6 +29,7 @@ import (
 	"github.com/thanos-io/objstore/providers/filesystem"
 	"github.com/thanos-io/thanos/pkg/block"
 	"github.com/thanos-io/thanos/pkg/block/metadata"
+	user_ctx "github.com/weaveworks/common/user"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
@@ -488,3 +493,101 @@ func TestConverter_SkipBlocksWithExistingValidMarker(t *testing.T) {
 	// It should be 0 since the block was already converted
 	assert.Equal(t, 0.0, testutil.ToFloat64(c.metrics.convertedBlocks.WithLabelValues(user)))
 }
+
+func TestConverter_NativeHistogramsAndIncompatibleBlocks(t *testing.T) {
+	cfg := prepareConfig()
+	user := "user"
+	ringStore, closer := consul.NewInMemoryClient(ring.GetCodec(), log.NewNopLogger(), nil)
+	t.Cleanup(func() { assert.NoError(t, closer.Close()) })
+	dir := t.TempDir()
+
+	cfg.Ring.InstanceID = "parquet-converter-1"
+	cfg.Ring.InstanceAddr = "1.2.3.4"
+	cfg.Ring.KVStore.Mock = ringStore
+	bucketClient, err := filesystem.NewBucket(t.TempDir())
+	require.NoError(t, err)
+	userBucket := bucket.NewPrefixedBucketClient(bucketClient, user)
+	limits := &validation.Limits{}
+	flagext.DefaultValues(limits)
+	limits.ParquetConverterEnabled = true
+
+	c, logger, _ := prepare(t, cfg, objstore.WithNoopInstr(bucketClient), limits, nil)
+
+	ctx := context.Background()
+	lbls := labels.FromStrings("__name__", "test")
+	step := time.Minute.Milliseconds()
+	hour := time.Hour.Milliseconds()
+
+	// The first block only contains native histograms, while the second one has a series
+	// switching from float to native histogram samples within the same data column.
+	histogramBlock, err := tsdb.CreateBlock([]storage.Series{
+		storage.NewListSeries(lbls, histogramSamples(0, 3*hour, step)),
+	}, dir, 24*hour, promslog.NewNopLogger())
+	require.NoError(t, err)
+	mixedBlock, err := tsdb.CreateBlock([]storage.Series{
+		storage.NewListSeries(lbls, append(floatSamples(24*hour, 25*hour, step), histogramSamples(25*hour, 27*hour, step)...)),
+	}, dir, 24*hour, promslog.NewNopLogger())
+	require.NoError(t, err)
+
+	blocks := []ulid.ULID{}
+	for _, blockDir := range []string{histogramBlock, mixedBlock} {
+		_, err = metadata.InjectThanos(logger, blockDir, metadata.Thanos{
+			Labels: map[string]string{cortex_tsdb.IngesterIDExternalLabel: "ingester-0"},
+			Source: metadata.TestSource,
+		}, nil)
+		require.NoError(t, err)
+		require.NoError(t, block.Upload(ctx, logger, userBucket, blockDir, metadata.NoneFunc))
+		blocks = append(blocks, ulid.MustParse(path.Base(blockDir)))
+	}
+
+	err = services.StartAndAwaitRunning(context.Background(), c)
+	require.NoError(t, err)
+	defer services.StopAndAwaitTerminated(ctx, c) // nolint:errcheck
+
+	test.Poll(t, 3*time.Minute, true, func() any {
+		histogramMark, err := parquet.ReadConverterMark(ctx, blocks[0], userBucket, logger)
+		require.NoError(t, err)
+		mixedMark, err := parquet.ReadConverterMark(ctx, blocks[1], userBucket, logger)
+		require.NoError(t, err)
+		return histogramMark.Version == parquet.CurrentVersion && mixedMark.Incompatible
+	})
+
+	mixedMark, err := parquet.ReadConverterMark(ctx, blocks[1], userBucket, logger)
+	require.NoError(t, err)
+	require.Contains(t, mixedMark.Reason, "in the same parquet data column")
+
+	// The incompatible block is not converted.
+	ok, err := userBucket.Exists(ctx, fmt.Sprintf("%s/0.chunks.parquet", blocks[1].String()))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	require.Equal(t, 1.0, testutil.ToFloat64(c.metrics.convertedBlocks.WithLabelValues(user)))
+	require.Equal(t, 1.0, testutil.ToFloat64(c.metrics.incompatibleBlocks.WithLabelValues(user)))
+
+	// The tenant conversion status API is served from the bucket index.
+	req := httptest.NewRequest(http.MethodGet, "/parquet-converter/status", nil)
+	req = req.WithContext(user_ctx.InjectOrgID(req.Context(), user))
+	rec := httptest.NewRecorder()
+	c.TenantStatusHandler(rec, req)
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	idx, _, _, err := bucketindex.NewUpdater(bucketClient, user, nil, logger).EnableParquet().UpdateIndex(ctx, nil)
+	require.NoError(t, err)
+	require.NoError(t, bucketindex.WriteIndex(ctx, bucketClient, user, nil, idx))
+
+	rec = httptest.NewRecorder()
+	c.TenantStatusHandler(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	resp := TenantConversionStatus{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	statuses := map[string]BlockConversionStatus{}
+	for _, b := range resp.Blocks {
+		statuses[b.ID] = b
+	}
+	require.Len(t, statuses, 2)
+	assert.Equal(t, BlockStatusConverted, statuses[blocks[0].String()].Status)
+	assert.Equal(t, parquet.CurrentVersion, statuses[blocks[0].String()].Version)
+	assert.Equal(t, BlockStatusIncompatible, statuses[blocks[1].String()].Status)
+	assert.Equal(t, mixedMark.Reason, statuses[blocks[1].String()].Reason)
+}
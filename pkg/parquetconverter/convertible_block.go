@@ -0,0 +1,224 @@
+package parquetconverter
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"github.com/prometheus/prometheus/tsdb/chunks"
+	"github.com/prometheus/prometheus/tsdb/index"
+)
+
+// convertibleBlock wraps a TSDB block to expose it to the parquet converter.
+//
+// Blocks produced by out-of-order compaction can contain series with overlapping
+// chunks, while the parquet chunks encoder expects the samples of a series to be
+// in order. The wrapper merges overlapping chunks of a series when the series is
+// read from the index, and serves the merged chunks from memory.
+type convertibleBlock struct {
+	*tsdb.Block
+}
+
+func newConvertibleBlock(b *tsdb.Block) *convertibleBlock {
+	return &convertibleBlock{Block: b}
+}
+
+func (b *convertibleBlock) Index() (tsdb.IndexReader, error) {
+	ir, err := b.Block.Index()
+	if err != nil {
+		return nil, err
+	}
+	cr, err := b.Block.Chunks()
+	if err != nil {
+		_ = ir.Close()
+		return nil, err
+	}
+	return &mergingIndexReader{IndexReader: ir, chunkr: cr}, nil
+}
+
+func (b *convertibleBlock) Chunks() (tsdb.ChunkReader, error) {
+	cr, err := b.Block.Chunks()
+	if err != nil {
+		return nil, err
+	}
+	return &mergedChunksReader{ChunkReader: cr}, nil
+}
+
+// mergingIndexReader returns the chunks of a series merged and re-encoded if they
+// overlap. Merged chunks are returned with their data set, and must be read through
+// a mergedChunksReader.
+type mergingIndexReader struct {
+	tsdb.IndexReader
+	chunkr tsdb.ChunkReader
+}
+
+func (r *mergingIndexReader) Series(ref storage.SeriesRef, builder *labels.ScratchBuilder, chks *[]chunks.Meta) error {
+	if err := r.IndexReader.Series(ref, builder, chks); err != nil {
+		return err
+	}
+	if !hasOverlappingChunks(*chks) {
+		return nil
+	}
+
+	merged, err := mergeOverlappingChunks(r.chunkr, builder.Labels(), *chks)
+	if err != nil {
+		return fmt.Errorf("merge overlapping chunks for series %d: %w", ref, err)
+	}
+	*chks = merged
+	return nil
+}
+
+func (r *mergingIndexReader) Close() error {
+	err := r.IndexReader.Close()
+	if cErr := r.chunkr.Close(); err == nil {
+		err = cErr
+	}
+	return err
+}
+
+// mergedChunksReader serves the chunks merged by mergingIndexReader from memory.
+type mergedChunksReader struct {
+	tsdb.ChunkReader
+}
+
+func (r *mergedChunksReader) ChunkOrIterable(meta chunks.Meta) (chunkenc.Chunk, chunkenc.Iterable, error) {
+	if meta.Chunk != nil {
+		return meta.Chunk, nil, nil
+	}
+	return r.ChunkReader.ChunkOrIterable(meta)
+}
+
+func hasOverlappingChunks(chks []chunks.Meta) bool {
+	if len(chks) < 2 {
+		return false
+	}
+	byMinTime := func(a, b chunks.Meta) int {
+		return cmp.Compare(a.MinTime, b.MinTime)
+	}
+	if !slices.IsSortedFunc(chks, byMinTime) {
+		chks = slices.Clone(chks)
+		slices.SortFunc(chks, byMinTime)
+	}
+	maxt := chks[0].MaxTime
+	for _, c := range chks[1:] {
+		if c.MinTime <= maxt {
+			return true
+		}
+		maxt = max(maxt, c.MaxTime)
+	}
+	return false
+}
+
+// mergeOverlappingChunks reads the input chunks and merges them into non overlapping
+// chunks, deduplicating samples with the same timestamp.
+func mergeOverlappingChunks(cr tsdb.ChunkReader, lset labels.Labels, chks []chunks.Meta) ([]chunks.Meta, error) {
+	series := make([]storage.ChunkSeries, 0, len(chks))
+	for _, meta := range chks {
+		chk, iterable, err := cr.ChunkOrIterable(meta)
+		if err != nil {
+			return nil, err
+		}
+		if iterable != nil {
+			// Only chunks of the out-of-order head are returned as iterable, never the ones of a block.
+			return nil, fmt.Errorf("unexpected iterable chunk %d", meta.Ref)
+		}
+		meta.Chunk = chk
+		series = append(series, &storage.ChunkSeriesEntry{
+			Lset: lset,
+			ChunkIteratorFn: func(chunks.Iterator) chunks.Iterator {
+				return storage.NewListChunkSeriesIterator(meta)
+			},
+		})
+	}
+
+	merged := storage.NewCompactingChunkSeriesMerger(storage.ChainedSeriesMerge)(series...)
+	it := merged.Iterator(nil)
+
+	out := make([]chunks.Meta, 0, len(chks))
+	for it.Next() {
+		out = append(out, it.At())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// checkBlockCompatibility returns a non empty reason if the block contains data which
+// can't be correctly represented in parquet format.
+//
+// The parquet chunks encoder groups the chunks of each data column by encoding, so a
+// series switching between float and native histogram samples (or between integer and
+// float histograms) within the same data column would be stored with chunks out of order.
+func checkBlockCompatibility(ctx context.Context, b *convertibleBlock, colDurationMs int64) (string, error) {
+	if b.Meta().Stats.NumHistogramSamples == 0 {
+		// Only float samples, nothing to check.
+		return "", nil
+	}
+
+	ir, err := b.Index()
+	if err != nil {
+		return "", err
+	}
+	defer ir.Close()
+
+	cr, err := b.Chunks()
+	if err != nil {
+		return "", err
+	}
+	defer cr.Close()
+
+	k, v := index.AllPostingsKey()
+	postings, err := ir.Postings(ctx, k, v)
+	if err != nil {
+		return "", err
+	}
+
+	var (
+		builder  labels.ScratchBuilder
+		chks     []chunks.Meta
+		encoding = map[int]chunkenc.Encoding{}
+		mint     = b.Meta().MinTime
+	)
+	for postings.Next() {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		if err := ir.Series(postings.At(), &builder, &chks); err != nil {
+			return "", err
+		}
+
+		clear(encoding)
+		for _, meta := range chks {
+			chk, _, err := cr.ChunkOrIterable(meta)
+			if err != nil {
+				return "", err
+			}
+			if chk == nil {
+				continue
+			}
+			enc := chk.Encoding()
+			for col := dataColumnIdx(meta.MinTime, mint, colDurationMs); col <= dataColumnIdx(meta.MaxTime, mint, colDurationMs); col++ {
+				if prev, ok := encoding[col]; ok && prev != enc {
+					return fmt.Sprintf("series %s has both %s and %s chunks in the same parquet data column", builder.Labels().String(), prev, enc), nil
+				}
+				encoding[col] = enc
+			}
+		}
+	}
+	return "", postings.Err()
+}
+
+// dataColumnIdx returns the parquet data column holding the given timestamp. It
+// must be kept in sync with the parquet schema.
+func dataColumnIdx(t, mint, colDurationMs int64) int {
+	if t < mint {
+		return 0
+	}
+	return int((t - mint) / colDurationMs)
+}
@@ -0,0 +1,177 @@
+package parquetconverter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/promslog"
+	"github.com/prometheus/prometheus/model/histogram"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"github.com/prometheus/prometheus/tsdb/chunks"
+	"github.com/prometheus/prometheus/tsdb/tsdbutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testSample struct {
+	t int64
+	f float64
+	h *histogram.Histogram
+}
+
+func (s testSample) T() int64                      { return s.t }
+func (s testSample) F() float64                    { return s.f }
+func (s testSample) H() *histogram.Histogram       { return s.h }
+func (s testSample) FH() *histogram.FloatHistogram { return nil }
+func (s testSample) Copy() chunks.Sample           { return s }
+func (s testSample) Type() chunkenc.ValueType {
+	if s.h != nil {
+		return chunkenc.ValHistogram
+	}
+	return chunkenc.ValFloat
+}
+
+func floatSamples(mint, maxt, step int64) []chunks.Sample {
+	var samples []chunks.Sample
+	for ts := mint; ts < maxt; ts += step {
+		samples = append(samples, testSample{t: ts, f: float64(ts)})
+	}
+	return samples
+}
+
+func histogramSamples(mint, maxt, step int64) []chunks.Sample {
+	var samples []chunks.Sample
+	for ts := mint; ts < maxt; ts += step {
+		samples = append(samples, testSample{t: ts, h: tsdbutil.GenerateTestHistogram(ts / step)})
+	}
+	return samples
+}
+
+type chunksReaderMock struct {
+	chunks map[chunks.ChunkRef]chunkenc.Chunk
+}
+
+func (m *chunksReaderMock) ChunkOrIterable(meta chunks.Meta) (chunkenc.Chunk, chunkenc.Iterable, error) {
+	return m.chunks[meta.Ref], nil, nil
+}
+
+func (m *chunksReaderMock) Close() error { return nil }
+
+func TestHasOverlappingChunks(t *testing.T) {
+	tests := map[string]struct {
+		chks     []chunks.Meta
+		expected bool
+	}{
+		"no chunks": {
+			expected: false,
+		},
+		"single chunk": {
+			chks:     []chunks.Meta{{MinTime: 0, MaxTime: 10}},
+			expected: false,
+		},
+		"non overlapping chunks": {
+			chks:     []chunks.Meta{{MinTime: 0, MaxTime: 10}, {MinTime: 11, MaxTime: 20}},
+			expected: false,
+		},
+		"overlapping chunks": {
+			chks:     []chunks.Meta{{MinTime: 0, MaxTime: 10}, {MinTime: 10, MaxTime: 20}},
+			expected: true,
+		},
+		"overlapping chunks not sorted by min time": {
+			chks:     []chunks.Meta{{MinTime: 5, MaxTime: 8}, {MinTime: 12, MaxTime: 20}, {MinTime: 0, MaxTime: 15}},
+			expected: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, hasOverlappingChunks(tc.chks))
+		})
+	}
+}
+
+func TestMergeOverlappingChunks(t *testing.T) {
+	newChunk := func(ts ...int64) (chunkenc.Chunk, chunks.Meta) {
+		chk := chunkenc.NewXORChunk()
+		app, err := chk.Appender()
+		require.NoError(t, err)
+		for _, t := range ts {
+			app.Append(t, float64(t))
+		}
+		return chk, chunks.Meta{MinTime: ts[0], MaxTime: ts[len(ts)-1]}
+	}
+
+	chk1, meta1 := newChunk(0, 10, 20, 30)
+	chk2, meta2 := newChunk(5, 10, 25, 40)
+	meta1.Ref, meta2.Ref = 1, 2
+	cr := &chunksReaderMock{chunks: map[chunks.ChunkRef]chunkenc.Chunk{1: chk1, 2: chk2}}
+
+	merged, err := mergeOverlappingChunks(cr, labels.FromStrings("__name__", "test"), []chunks.Meta{meta1, meta2})
+	require.NoError(t, err)
+	require.False(t, hasOverlappingChunks(merged))
+
+	var (
+		timestamps []int64
+		mcr        = &mergedChunksReader{ChunkReader: cr}
+	)
+	for _, meta := range merged {
+		chk, _, err := mcr.ChunkOrIterable(meta)
+		require.NoError(t, err)
+		it := chk.Iterator(nil)
+		for it.Next() == chunkenc.ValFloat {
+			ts, v := it.At()
+			assert.Equal(t, float64(ts), v)
+			timestamps = append(timestamps, ts)
+		}
+		require.NoError(t, it.Err())
+	}
+	assert.Equal(t, []int64{0, 5, 10, 20, 25, 30, 40}, timestamps)
+}
+
+func TestCheckBlockCompatibility(t *testing.T) {
+	colDurationMs := dataColDuration.Milliseconds()
+	step := time.Minute.Milliseconds()
+
+	floats := func(mint, maxt int64) []chunks.Sample { return floatSamples(mint, maxt, step) }
+	histograms := func(mint, maxt int64) []chunks.Sample { return histogramSamples(mint, maxt, step) }
+
+	tests := map[string]struct {
+		samples        []chunks.Sample
+		expectedReason string
+	}{
+		"only float samples": {
+			samples: floats(0, 2*time.Hour.Milliseconds()),
+		},
+		"only histogram samples": {
+			samples: histograms(0, 2*time.Hour.Milliseconds()),
+		},
+		"float and histogram samples in different data columns": {
+			samples: append(floats(0, colDurationMs), histograms(colDurationMs, colDurationMs+time.Hour.Milliseconds())...),
+		},
+		"float and histogram samples in the same data column": {
+			samples:        append(floats(0, time.Hour.Milliseconds()), histograms(time.Hour.Milliseconds(), 2*time.Hour.Milliseconds())...),
+			expectedReason: `series {__name__="test"} has both XOR and histogram chunks in the same parquet data column`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			series := []storage.Series{storage.NewListSeries(labels.FromStrings("__name__", "test"), tc.samples)}
+			bdir, err := tsdb.CreateBlock(series, dir, 2*colDurationMs, promslog.NewNopLogger())
+			require.NoError(t, err)
+
+			b, err := tsdb.OpenBlock(promslog.NewNopLogger(), bdir, nil, nil)
+			require.NoError(t, err)
+			t.Cleanup(func() { require.NoError(t, b.Close()) })
+
+			reason, err := checkBlockCompatibility(context.Background(), newConvertibleBlock(b), colDurationMs)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedReason, reason)
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 type metrics struct {
 	convertedBlocks          *prometheus.CounterVec
 	convertBlockFailures     *prometheus.CounterVec
+	incompatibleBlocks       *prometheus.CounterVec
 	convertBlockDuration     *prometheus.GaugeVec
 	convertParquetBlockDelay prometheus.Histogram
 	ownedUsers               prometheus.Gauge
@@ -23,6 +24,10 @@ func newMetrics(reg prometheus.Registerer) *metrics {
 			Name: "cortex_parquet_converter_block_convert_failures_total",
 			Help: "Total number of failed block conversions per user.",
 		}, []string{"user"}),
+		incompatibleBlocks: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_parquet_converter_blocks_incompatible_total",
+			Help: "Total number of blocks per user flagged as not compatible with parquet format, which are queried from TSDB.",
+		}, []string{"user"}),
 		convertBlockDuration: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
 			Name: "cortex_parquet_converter_convert_block_duration_seconds",
 			Help: "Time taken to for the latest block conversion for the user.",
@@ -42,5 +47,6 @@ func newMetrics(reg prometheus.Registerer) *metrics {
 func (m *metrics) deleteMetricsForTenant(userID string) {
 	m.convertedBlocks.DeleteLabelValues(userID)
 	m.convertBlockFailures.DeleteLabelValues(userID)
+	m.incompatibleBlocks.DeleteLabelValues(userID)
 	m.convertBlockDuration.DeleteLabelValues(userID)
 }
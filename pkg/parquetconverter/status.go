@@ -0,0 +1,191 @@
+package parquetconverter
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/oklog/ulid/v2"
+	"github.com/pkg/errors"
+
+	cortex_parquet "github.com/cortexproject/cortex/pkg/storage/parquet"
+	"github.com/cortexproject/cortex/pkg/storage/tsdb/bucketindex"
+	"github.com/cortexproject/cortex/pkg/util"
+	util_log "github.com/cortexproject/cortex/pkg/util/log"
+	"github.com/cortexproject/cortex/pkg/util/services"
+	"github.com/cortexproject/cortex/pkg/util/users"
+)
+
+const (
+	// BlockStatusConverted means the block is available in parquet format.
+	BlockStatusConverted = "converted"
+	// BlockStatusPending means the block is eligible for conversion but hasn't been converted yet.
+	BlockStatusPending = "pending"
+	// BlockStatusFailed means the last conversion attempt of the block failed. It will be retried.
+	BlockStatusFailed = "failed"
+	// BlockStatusIncompatible means the block can't be converted and is queried from TSDB.
+	BlockStatusIncompatible = "incompatible"
+	// BlockStatusNotEligible means the block is not going to be converted, because of its time range.
+	BlockStatusNotEligible = "not_eligible"
+)
+
+// TenantConversionStatus is the response of the tenant conversion status API.
+type TenantConversionStatus struct {
+	Blocks []BlockConversionStatus `json:"blocks"`
+}
+
+// BlockConversionStatus holds the parquet conversion status of a single block.
+type BlockConversionStatus struct {
+	ID      string `json:"block_id"`
+	MinTime int64  `json:"min_time"`
+	MaxTime int64  `json:"max_time"`
+	Status  string `json:"status"`
+	Version int    `json:"version,omitempty"`
+	Shards  int    `json:"shards,omitempty"`
+	// Reason is the reason why the block is incompatible, or the error of the last failed conversion.
+	Reason      string    `json:"reason,omitempty"`
+	Failures    int       `json:"failures,omitempty"`
+	LastFailure time.Time `json:"last_failure,omitzero"`
+}
+
+type conversionFailure struct {
+	count int
+	last  time.Time
+	err   string
+}
+
+// conversionFailures keeps track of the blocks which failed to be converted by this
+// converter instance.
+type conversionFailures struct {
+	mtx   sync.Mutex
+	users map[string]map[ulid.ULID]*conversionFailure
+}
+
+func newConversionFailures() *conversionFailures {
+	return &conversionFailures{users: map[string]map[ulid.ULID]*conversionFailure{}}
+}
+
+func (f *conversionFailures) add(userID string, blockID ulid.ULID, err error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	blocks, ok := f.users[userID]
+	if !ok {
+		blocks = map[ulid.ULID]*conversionFailure{}
+		f.users[userID] = blocks
+	}
+	failure, ok := blocks[blockID]
+	if !ok {
+		failure = &conversionFailure{}
+		blocks[blockID] = failure
+	}
+	failure.count++
+	failure.last = time.Now()
+	failure.err = err.Error()
+}
+
+func (f *conversionFailures) remove(userID string, blockID ulid.ULID) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	if blocks, ok := f.users[userID]; ok {
+		delete(blocks, blockID)
+		if len(blocks) == 0 {
+			delete(f.users, userID)
+		}
+	}
+}
+
+func (f *conversionFailures) get(userID string, blockID ulid.ULID) (conversionFailure, bool) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	failure, ok := f.users[userID][blockID]
+	if !ok {
+		return conversionFailure{}, false
+	}
+	return *failure, true
+}
+
+func (f *conversionFailures) deleteUser(userID string) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	delete(f.users, userID)
+}
+
+// TenantStatusHandler is an HTTP handler returning the parquet conversion status of
+// each block of the tenant. The blocks and their converter markers are read from the
+// bucket index, so a block shows up once the index has been updated. Failures are only
+// known by the converter instance which attempted the conversion, so they are reported
+// only when the request is served by it.
+func (c *Converter) TenantStatusHandler(w http.ResponseWriter, r *http.Request) {
+	if c.State() != services.Running {
+		http.Error(w, "Parquet Converter is not running yet.", http.StatusServiceUnavailable)
+		return
+	}
+
+	userID, err := users.TenantID(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	logger := util_log.WithUserID(userID, c.logger)
+
+	idx, err := bucketindex.ReadIndex(r.Context(), c.bkt, userID, c.limits, logger)
+	if errors.Is(err, bucketindex.ErrIndexNotFound) {
+		http.Error(w, "bucket index not found, the conversion status is available once the bucket index of the tenant has been written", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		level.Error(logger).Log("msg", "failed to read bucket index for conversion status", "err", err)
+		http.Error(w, fmt.Sprintf("failed to read bucket index: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	deleted := make(map[ulid.ULID]struct{}, len(idx.BlockDeletionMarks))
+	for _, id := range idx.BlockDeletionMarks.GetULIDs() {
+		deleted[id] = struct{}{}
+	}
+
+	resp := TenantConversionStatus{Blocks: make([]BlockConversionStatus, 0, len(idx.Blocks))}
+	for _, b := range idx.Blocks {
+		if _, ok := deleted[b.ID]; ok {
+			continue
+		}
+
+		status := BlockConversionStatus{
+			ID:      b.ID.String(),
+			MinTime: b.MinTime,
+			MaxTime: b.MaxTime,
+		}
+
+		switch {
+		case b.IsParquetIncompatible():
+			status.Status = BlockStatusIncompatible
+			status.Version = b.Parquet.Version
+			status.Reason = b.Parquet.Reason
+		case b.IsParquet() && cortex_parquet.ValidConverterMarkVersion(b.Parquet.Version):
+			status.Status = BlockStatusConverted
+			status.Version = b.Parquet.Version
+			status.Shards = b.Parquet.Shards
+		case !cortex_parquet.ShouldConvertBlockToParquet(b.MinTime, b.MaxTime, c.blockRanges):
+			status.Status = BlockStatusNotEligible
+		default:
+			status.Status = BlockStatusPending
+			if failure, ok := c.failures.get(userID, b.ID); ok {
+				status.Status = BlockStatusFailed
+				status.Reason = failure.err
+				status.Failures = failure.count
+				status.LastFailure = failure.last
+			}
+		}
+
+		resp.Blocks = append(resp.Blocks, status)
+	}
+
+	util.WriteJSONResponse(w, resp)
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/thanos-io/thanos/pkg/extprom"
 
 	"github.com/cortexproject/cortex/pkg/ring/client"
+	"github.com/cortexproject/cortex/pkg/util/discovery"
 	"github.com/cortexproject/cortex/pkg/util/services"
 )
 
@@ -27,7 +28,7 @@ type blocksStoreBalancedSet struct {
 
 	serviceAddresses []string
 	clientsPool      *client.Pool
-	dnsProvider      *dns.Provider
+	dnsProvider      *discovery.Provider
 
 	logger log.Logger
 }
@@ -39,7 +40,7 @@ func newBlocksStoreBalancedSet(serviceAddresses []string, clientConfig ClientCon
 
 	s := &blocksStoreBalancedSet{
 		serviceAddresses: serviceAddresses,
-		dnsProvider:      dns.NewProvider(logger, dnsProviderReg, dns.GolangResolverType),
+		dnsProvider:      discovery.NewProvider(logger, dnsProviderReg, dns.GolangResolverType),
 		clientsPool:      newStoreGatewayClientPool(nil, clientConfig, logger, reg),
 		logger:           logger,
 	}
@@ -20,6 +20,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/prometheus/model/exemplar"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/storage"
 	"github.com/prometheus/prometheus/util/annotations"
@@ -312,6 +313,27 @@ func (q *BlocksStoreQueryable) Querier(mint, maxt int64) (storage.Querier, error
 	}, nil
 }
 
+// ExemplarQuerier returns a new exemplar querier on the exemplars persisted alongside the blocks.
+func (q *BlocksStoreQueryable) ExemplarQuerier(ctx context.Context) (storage.ExemplarQuerier, error) {
+	if s := q.State(); s != services.Running {
+		return nil, errors.Errorf("BlocksStoreQueryable is not running: %v", s)
+	}
+
+	return &blocksStoreExemplarQuerier{
+		ctx: ctx,
+		querier: &blocksStoreQuerier{
+			finder:                                  q.finder,
+			stores:                                  q.stores,
+			metrics:                                 q.metrics,
+			limits:                                  q.limits,
+			consistency:                             q.consistency,
+			logger:                                  q.logger,
+			storeGatewayConsistencyCheckMaxAttempts: q.storeGatewayConsistencyCheckMaxAttempts,
+			nowFn:                                   time.Now,
+		},
+	}, nil
+}
+
 type blocksStoreQuerier struct {
 	minT, maxT  int64
 	finder      BlocksFinder
@@ -1069,6 +1091,152 @@ func (q *blocksStoreQuerier) fetchLabelValuesFromStore(
 	return valueSets, warnings, queriedBlocks, nil, merr.Err()
 }
 
+type blocksStoreExemplarQuerier struct {
+	ctx     context.Context
+	querier *blocksStoreQuerier
+}
+
+// Select implements storage.ExemplarQuerier interface.
+func (q *blocksStoreExemplarQuerier) Select(start, end int64, matchers ...[]*labels.Matcher) ([]exemplar.QueryResult, error) {
+	userID, err := users.TenantID(q.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	spanLog, spanCtx := spanlogger.New(q.ctx, "blocksStoreExemplarQuerier.Select")
+	defer spanLog.Finish()
+
+	convertedMatchers := make([]storegatewaypb.ExemplarsMatchers, 0, len(matchers))
+	for _, m := range matchers {
+		convertedMatchers = append(convertedMatchers, storegatewaypb.ExemplarsMatchers{Matchers: convertMatchersToLabelMatcher(m)})
+	}
+
+	var (
+		resMtx  sync.Mutex
+		resSets [][]exemplar.QueryResult
+	)
+
+	queryFunc := func(clients map[BlocksStoreClient][]ulid.ULID, minT, maxT int64) ([]ulid.ULID, error, error) {
+		sets, queriedBlocks, err, retryableError := q.querier.fetchExemplarsFromStores(spanCtx, userID, clients, minT, maxT, convertedMatchers)
+		if err != nil {
+			return nil, err, retryableError
+		}
+
+		resMtx.Lock()
+		resSets = append(resSets, sets...)
+		resMtx.Unlock()
+
+		return queriedBlocks, nil, retryableError
+	}
+
+	if err := q.querier.queryWithConsistencyCheck(spanCtx, spanLog, start, end, nil, userID, queryFunc); err != nil {
+		return nil, err
+	}
+
+	return cortex_tsdb.MergeExemplars(resSets...), nil
+}
+
+func (q *blocksStoreQuerier) fetchExemplarsFromStores(
+	ctx context.Context,
+	userID string,
+	clients map[BlocksStoreClient][]ulid.ULID,
+	minT int64,
+	maxT int64,
+	matchers []storegatewaypb.ExemplarsMatchers,
+) ([][]exemplar.QueryResult, []ulid.ULID, error, error) {
+	var (
+		reqCtx        = grpc_metadata.AppendToOutgoingContext(ctx, cortex_tsdb.TenantIDExternalLabel, userID)
+		g, gCtx       = errgroup.WithContext(reqCtx)
+		mtx           = sync.Mutex{}
+		sets          = [][]exemplar.QueryResult{}
+		queriedBlocks = []ulid.ULID(nil)
+		spanLog       = spanlogger.FromContext(ctx)
+		merrMtx       = sync.Mutex{}
+		merr          = multierror.MultiError{}
+		queryLimiter  = limiter.QueryLimiterFromContextWithFallback(ctx)
+	)
+
+	// Concurrently fetch exemplars from all clients.
+	for c, blockIDs := range clients {
+		g.Go(func() error {
+			req := &storegatewaypb.ExemplarsRequest{
+				MinTime:  minT,
+				MaxTime:  maxT,
+				Matchers: matchers,
+				BlockIds: convertULIDsToString(blockIDs),
+			}
+
+			resp, err := c.Exemplars(gCtx, req)
+			if err != nil {
+				if isRetryableError(err) {
+					level.Warn(spanLog).Log("err", errors.Wrapf(err, "failed to fetch exemplars from %s due to retryable error", c.RemoteAddress()))
+					merrMtx.Lock()
+					merr.Add(err)
+					merrMtx.Unlock()
+					return nil
+				}
+
+				s, ok := status.FromError(err)
+				if !ok {
+					s, ok = status.FromError(errors.Cause(err))
+				}
+
+				if ok {
+					if s.Code() == codes.ResourceExhausted {
+						return validation.LimitError(s.Message())
+					}
+
+					if s.Code() == codes.PermissionDenied {
+						return validation.AccessDeniedError(s.Message())
+					}
+				}
+				return errors.Wrapf(err, "failed to fetch exemplars from %s", c.RemoteAddress())
+			}
+			if dataBytesLimitErr := queryLimiter.AddDataBytes(resp.Size()); dataBytesLimitErr != nil {
+				return validation.LimitError(dataBytesLimitErr.Error())
+			}
+
+			myQueriedBlocks := make([]ulid.ULID, 0, len(resp.QueriedBlocks))
+			for _, id := range resp.QueriedBlocks {
+				blockID, err := ulid.Parse(id)
+				if err != nil {
+					return errors.Wrapf(err, "failed to parse queried block IDs from received response")
+				}
+				myQueriedBlocks = append(myQueriedBlocks, blockID)
+			}
+
+			mySet := make([]exemplar.QueryResult, 0, len(resp.Timeseries))
+			for _, ts := range resp.Timeseries {
+				mySet = append(mySet, exemplar.QueryResult{
+					SeriesLabels: cortexpb.FromLabelAdaptersToLabels(ts.Labels),
+					Exemplars:    cortexpb.FromExemplarProtosToExemplars(ts.Exemplars),
+				})
+			}
+
+			level.Debug(spanLog).Log("msg", "received exemplars from store-gateway",
+				"instance", c.RemoteAddress(),
+				"num series", len(resp.Timeseries),
+				"requested blocks", strings.Join(convertULIDsToString(blockIDs), " "),
+				"queried blocks", strings.Join(convertULIDsToString(myQueriedBlocks), " "))
+
+			// Store the result.
+			mtx.Lock()
+			sets = append(sets, mySet)
+			queriedBlocks = append(queriedBlocks, myQueriedBlocks...)
+			mtx.Unlock()
+
+			return nil
+		})
+	}
+
+	// Wait until all client requests complete.
+	if err := g.Wait(); err != nil {
+		return nil, nil, err, merr.Err()
+	}
+
+	return sets, queriedBlocks, nil, merr.Err()
+}
+
 func createSeriesRequest(minT, maxT, limit int64, matchers []storepb.LabelMatcher, selectHints *storage.SelectHints, shardingInfo *storepb.ShardInfo, skipChunks bool, blockIDs []ulid.ULID, aggrs []storepb.Aggr, batchSize int64) (*storepb.SeriesRequest, error) {
 	// Selectively query only specific blocks.
 	hints := &hintspb.SeriesRequestHints{
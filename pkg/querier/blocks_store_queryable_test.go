@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"sort"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -17,6 +18,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/exemplar"
 	"github.com/prometheus/prometheus/model/histogram"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/promql"
@@ -2374,6 +2376,128 @@ func TestBlocksStoreQuerier_Labels(t *testing.T) {
 	}
 }
 
+func TestBlocksStoreExemplarQuerier_Select(t *testing.T) {
+	t.Parallel()
+
+	const (
+		minT = int64(10)
+		maxT = int64(20)
+	)
+
+	var (
+		block1  = ulid.MustNew(1, nil)
+		block2  = ulid.MustNew(2, nil)
+		series1 = labels.FromStrings(labels.MetricName, "series_1")
+		series2 = labels.FromStrings(labels.MetricName, "series_2")
+	)
+
+	mockExemplarsResponse := func(queriedBlocks []ulid.ULID, series labels.Labels, exemplarTs ...int64) *storegatewaypb.ExemplarsResponse {
+		ts := cortexpb.TimeSeries{Labels: cortexpb.FromLabelsToLabelAdapters(series)}
+		for _, t := range exemplarTs {
+			ts.Exemplars = append(ts.Exemplars, cortexpb.Exemplar{Labels: cortexpb.FromLabelsToLabelAdapters(labels.FromStrings("trace_id", strconv.FormatInt(t, 10))), Value: float64(t), TimestampMs: t})
+		}
+		return &storegatewaypb.ExemplarsResponse{
+			Timeseries:    []cortexpb.TimeSeries{ts},
+			QueriedBlocks: convertULIDsToString(queriedBlocks),
+		}
+	}
+
+	exemplarsOf := func(series labels.Labels, exemplarTs ...int64) exemplar.QueryResult {
+		res := exemplar.QueryResult{SeriesLabels: series}
+		for _, t := range exemplarTs {
+			res.Exemplars = append(res.Exemplars, exemplar.Exemplar{Labels: labels.FromStrings("trace_id", strconv.FormatInt(t, 10)), Value: float64(t), Ts: t})
+		}
+		return res
+	}
+
+	tests := map[string]struct {
+		finderResult      bucketindex.Blocks
+		storeSetResponses []any
+		expectedResult    []exemplar.QueryResult
+		expectedErr       string
+	}{
+		"no block in the storage matching the query time range": {
+			finderResult:   nil,
+			expectedResult: []exemplar.QueryResult{},
+		},
+		"exemplars of the same series returned by multiple store-gateways are merged": {
+			finderResult: bucketindex.Blocks{
+				&bucketindex.Block{ID: block1},
+				&bucketindex.Block{ID: block2},
+			},
+			storeSetResponses: []any{
+				map[BlocksStoreClient][]ulid.ULID{
+					&storeGatewayClientMock{remoteAddr: "1.1.1.1", mockedExemplarsResponse: mockExemplarsResponse([]ulid.ULID{block1}, series1, 11, 12)}: {block1},
+					&storeGatewayClientMock{remoteAddr: "2.2.2.2", mockedExemplarsResponse: mockExemplarsResponse([]ulid.ULID{block2}, series1, 12, 13)}: {block2},
+				},
+			},
+			expectedResult: []exemplar.QueryResult{exemplarsOf(series1, 11, 12, 13)},
+		},
+		"a missing block is fetched from another store-gateway": {
+			finderResult: bucketindex.Blocks{
+				&bucketindex.Block{ID: block1},
+				&bucketindex.Block{ID: block2},
+			},
+			storeSetResponses: []any{
+				map[BlocksStoreClient][]ulid.ULID{
+					&storeGatewayClientMock{remoteAddr: "1.1.1.1", mockedExemplarsResponse: mockExemplarsResponse([]ulid.ULID{block1}, series1, 11)}: {block1, block2},
+				},
+				map[BlocksStoreClient][]ulid.ULID{
+					&storeGatewayClientMock{remoteAddr: "2.2.2.2", mockedExemplarsResponse: mockExemplarsResponse([]ulid.ULID{block2}, series2, 15)}: {block2},
+				},
+			},
+			expectedResult: []exemplar.QueryResult{exemplarsOf(series1, 11), exemplarsOf(series2, 15)},
+		},
+		"a non-retryable store-gateway error fails the query": {
+			finderResult: bucketindex.Blocks{
+				&bucketindex.Block{ID: block1},
+			},
+			storeSetResponses: []any{
+				map[BlocksStoreClient][]ulid.ULID{
+					&storeGatewayClientMock{remoteAddr: "1.1.1.1", mockedExemplarsErr: status.Error(codes.PermissionDenied, "access denied")}: {block1},
+				},
+			},
+			expectedErr: "access denied",
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := user.InjectOrgID(context.Background(), "user-1")
+			stores := &blocksStoreSetMock{mockedResponses: testData.storeSetResponses}
+			finder := &blocksFinderMock{}
+			finder.On("GetBlocks", mock.Anything, "user-1", minT, maxT, mock.Anything).Return(testData.finderResult, map[ulid.ULID]*bucketindex.BlockDeletionMark(nil), nil)
+
+			q := &blocksStoreExemplarQuerier{
+				ctx: ctx,
+				querier: &blocksStoreQuerier{
+					finder:      finder,
+					stores:      stores,
+					consistency: NewBlocksConsistencyChecker(0, 0, log.NewNopLogger(), nil),
+					logger:      log.NewNopLogger(),
+					metrics:     newBlocksStoreQueryableMetrics(prometheus.NewPedanticRegistry()),
+					limits:      &blocksStoreLimitsMock{},
+					nowFn:       time.Now,
+
+					storeGatewayConsistencyCheckMaxAttempts: 3,
+				},
+			}
+
+			res, err := q.Select(minT, maxT, []*labels.Matcher{labels.MustNewMatcher(labels.MatchRegexp, labels.MetricName, "series_.+")})
+			if testData.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), testData.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, testData.expectedResult, res)
+		})
+	}
+}
+
 func TestBlocksStoreQuerier_SelectSortedShouldHonorQueryStoreAfter(t *testing.T) {
 
 	now := time.Now()
@@ -2660,6 +2784,8 @@ type storeGatewayClientMock struct {
 	mockedLabelNamesResponse  *storepb.LabelNamesResponse
 	mockedLabelValuesResponse *storepb.LabelValuesResponse
 	mockedLabelValuesErr      error
+	mockedExemplarsResponse   *storegatewaypb.ExemplarsResponse
+	mockedExemplarsErr        error
 	lastSeriesRequest         *storepb.SeriesRequest // capture the last received SeriesRequest to use test.
 }
 
@@ -2689,6 +2815,13 @@ func (m *storeGatewayClientMock) LabelValues(_ context.Context, r *storepb.Label
 	return m.mockedLabelValuesResponse, m.mockedLabelValuesErr
 }
 
+func (m *storeGatewayClientMock) Exemplars(_ context.Context, r *storegatewaypb.ExemplarsRequest, _ ...grpc.CallOption) (*storegatewaypb.ExemplarsResponse, error) {
+	if m.mockedExemplarsResponse == nil {
+		return &storegatewaypb.ExemplarsResponse{QueriedBlocks: r.BlockIds}, m.mockedExemplarsErr
+	}
+	return m.mockedExemplarsResponse, m.mockedExemplarsErr
+}
+
 func (m *storeGatewayClientMock) RemoteAddress() string {
 	return m.remoteAddr
 }
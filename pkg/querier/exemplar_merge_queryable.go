@@ -0,0 +1,65 @@
+package querier
+
+import (
+	"context"
+
+	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/cortexproject/cortex/pkg/storage/tsdb"
+)
+
+// NewMergeExemplarQueryable returns an exemplar queryable which queries all the input
+// queryables concurrently and merges their results, deduplicating the exemplars.
+func NewMergeExemplarQueryable(queryables ...storage.ExemplarQueryable) storage.ExemplarQueryable {
+	return &mergeExemplarQueryable{queryables: queryables}
+}
+
+type mergeExemplarQueryable struct {
+	queryables []storage.ExemplarQueryable
+}
+
+func (m *mergeExemplarQueryable) ExemplarQuerier(ctx context.Context) (storage.ExemplarQuerier, error) {
+	queriers := make([]storage.ExemplarQuerier, 0, len(m.queryables))
+	for _, q := range m.queryables {
+		querier, err := q.ExemplarQuerier(ctx)
+		if err != nil {
+			return nil, err
+		}
+		queriers = append(queriers, querier)
+	}
+
+	return &mergeExemplarQuerier{ctx: ctx, queriers: queriers}, nil
+}
+
+type mergeExemplarQuerier struct {
+	ctx      context.Context
+	queriers []storage.ExemplarQuerier
+}
+
+// Select implements storage.ExemplarQuerier interface.
+func (m *mergeExemplarQuerier) Select(start, end int64, matchers ...[]*labels.Matcher) ([]exemplar.QueryResult, error) {
+	if len(m.queriers) == 1 {
+		return m.queriers[0].Select(start, end, matchers...)
+	}
+
+	sets := make([][]exemplar.QueryResult, len(m.queriers))
+	g, _ := errgroup.WithContext(m.ctx)
+	for i, q := range m.queriers {
+		g.Go(func() error {
+			res, err := q.Select(start, end, matchers...)
+			if err != nil {
+				return err
+			}
+			sets[i] = res
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return tsdb.MergeExemplars(sets...), nil
+}
@@ -0,0 +1,68 @@
+package querier
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type exemplarQueryableMock struct {
+	results []exemplar.QueryResult
+	err     error
+}
+
+func (m *exemplarQueryableMock) ExemplarQuerier(context.Context) (storage.ExemplarQuerier, error) {
+	return m, nil
+}
+
+func (m *exemplarQueryableMock) Select(int64, int64, ...[]*labels.Matcher) ([]exemplar.QueryResult, error) {
+	return m.results, m.err
+}
+
+func TestMergeExemplarQueryable(t *testing.T) {
+	ingesters := &exemplarQueryableMock{results: []exemplar.QueryResult{
+		{
+			SeriesLabels: labels.FromStrings("__name__", "series_1"),
+			Exemplars: []exemplar.Exemplar{
+				{Labels: labels.FromStrings("trace_id", "2"), Value: 2, Ts: 20},
+				{Labels: labels.FromStrings("trace_id", "3"), Value: 3, Ts: 30},
+			},
+		},
+	}}
+	store := &exemplarQueryableMock{results: []exemplar.QueryResult{
+		{
+			SeriesLabels: labels.FromStrings("__name__", "series_1"),
+			Exemplars: []exemplar.Exemplar{
+				{Labels: labels.FromStrings("trace_id", "1"), Value: 1, Ts: 10},
+				{Labels: labels.FromStrings("trace_id", "2"), Value: 2, Ts: 20},
+			},
+		},
+	}}
+
+	q, err := NewMergeExemplarQueryable(ingesters, store).ExemplarQuerier(context.Background())
+	require.NoError(t, err)
+
+	res, err := q.Select(0, 100, []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "__name__", "series_1")})
+	require.NoError(t, err)
+	assert.Equal(t, []exemplar.QueryResult{
+		{
+			SeriesLabels: labels.FromStrings("__name__", "series_1"),
+			Exemplars: []exemplar.Exemplar{
+				{Labels: labels.FromStrings("trace_id", "1"), Value: 1, Ts: 10},
+				{Labels: labels.FromStrings("trace_id", "2"), Value: 2, Ts: 20},
+				{Labels: labels.FromStrings("trace_id", "3"), Value: 3, Ts: 30},
+			},
+		},
+	}, res)
+
+	// An error from any queryable fails the query.
+	store.err = errors.New("store failure")
+	_, err = q.Select(0, 100)
+	require.EqualError(t, err, "store failure")
+}
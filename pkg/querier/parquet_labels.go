@@ -0,0 +1,166 @@
+package querier
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	"github.com/prometheus-community/parquet-common/queryable"
+	"github.com/prometheus-community/parquet-common/schema"
+	"github.com/prometheus-community/parquet-common/search"
+	"github.com/prometheus-community/parquet-common/util"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/util/annotations"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/cortexproject/cortex/pkg/util/parquetutil"
+	"github.com/cortexproject/cortex/pkg/util/users"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+// parquetLabelsQueryable wraps the parquet queryable to serve the label names and values
+// requests with matchers from the label columns of the matching rows, like the store gateway
+// does. The matching rows are reserved in the row count quota before being read, while the
+// requests without matchers are served by the wrapped queryable from the schema and the
+// dictionary pages only.
+type parquetLabelsQueryable struct {
+	storage.Queryable
+
+	shardsFinder  queryable.ShardsFinderFunction
+	chunksDecoder *schema.PrometheusParquetChunksDecoder
+	limits        *validation.Overrides
+	concurrency   int
+}
+
+func newParquetLabelsQueryable(q storage.Queryable, shardsFinder queryable.ShardsFinderFunction, chunksDecoder *schema.PrometheusParquetChunksDecoder, limits *validation.Overrides) *parquetLabelsQueryable {
+	return &parquetLabelsQueryable{
+		Queryable:     q,
+		shardsFinder:  shardsFinder,
+		chunksDecoder: chunksDecoder,
+		limits:        limits,
+		concurrency:   runtime.GOMAXPROCS(0),
+	}
+}
+
+func (p *parquetLabelsQueryable) Querier(mint, maxt int64) (storage.Querier, error) {
+	q, err := p.Queryable.Querier(mint, maxt)
+	if err != nil {
+		return nil, err
+	}
+	return &parquetLabelsQuerier{Querier: q, queryable: p, mint: mint, maxt: maxt}, nil
+}
+
+type parquetLabelsQuerier struct {
+	storage.Querier
+
+	queryable  *parquetLabelsQueryable
+	mint, maxt int64
+}
+
+func (q *parquetLabelsQuerier) LabelNames(ctx context.Context, hints *storage.LabelHints, matchers ...*labels.Matcher) ([]string, annotations.Annotations, error) {
+	if len(matchers) == 0 {
+		return q.Querier.LabelNames(ctx, hints, matchers...)
+	}
+
+	result, err := q.materializeLabels(ctx, hints, matchers, func(ctx context.Context, m *search.Materializer, rgi int, rr []search.RowRange) ([]string, error) {
+		return m.MaterializeLabelNames(ctx, rgi, rr)
+	})
+	return result, nil, err
+}
+
+func (q *parquetLabelsQuerier) LabelValues(ctx context.Context, name string, hints *storage.LabelHints, matchers ...*labels.Matcher) ([]string, annotations.Annotations, error) {
+	if len(matchers) == 0 {
+		return q.Querier.LabelValues(ctx, name, hints, matchers...)
+	}
+
+	result, err := q.materializeLabels(ctx, hints, matchers, func(ctx context.Context, m *search.Materializer, rgi int, rr []search.RowRange) ([]string, error) {
+		return m.MaterializeLabelValues(ctx, name, rgi, rr)
+	})
+	return result, nil, err
+}
+
+// materializeLabels runs the given function on the rows matching the matchers in each row
+// group of the queried shards, and merges the results.
+func (q *parquetLabelsQuerier) materializeLabels(ctx context.Context, hints *storage.LabelHints, matchers []*labels.Matcher, fn func(context.Context, *search.Materializer, int, []search.RowRange) ([]string, error)) ([]string, error) {
+	userID, err := users.TenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	shards, err := q.queryable.shardsFinder(ctx, q.mint, q.maxt)
+	if err != nil {
+		return nil, err
+	}
+
+	limits := q.queryable.limits
+	rowCountQuota := search.NewQuota(int64(limits.ParquetMaxFetchedRowCount(userID)))
+	chunkBytesQuota := search.NewQuota(int64(limits.ParquetMaxFetchedChunkBytes(userID)))
+	dataBytesQuota := search.NewQuota(int64(limits.ParquetMaxFetchedDataBytes(userID)))
+
+	// The materializers and the constraints of all the shards are built upfront, so that
+	// no row group is read if any of them fails.
+	materializers := make([]*search.Materializer, len(shards))
+	constraints := make([][]search.Constraint, len(shards))
+	for i, shard := range shards {
+		s, err := shard.TSDBSchema()
+		if err != nil {
+			return nil, err
+		}
+		materializers[i], err = search.NewMaterializer(s, q.queryable.chunksDecoder, shard, q.queryable.concurrency, rowCountQuota, chunkBytesQuota, dataBytesQuota, search.NoopMaterializedSeriesFunc, search.NoopMaterializedLabelsFilterCallback, false)
+		if err != nil {
+			return nil, err
+		}
+		constraints[i], err = search.MatchersToConstraints(matchers...)
+		if err != nil {
+			return nil, err
+		}
+		if err := search.Initialize(shard.LabelsFile(), constraints[i]...); err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		resultsMtx sync.Mutex
+		results    [][]string
+	)
+
+	errGroup, ctx := errgroup.WithContext(ctx)
+	errGroup.SetLimit(q.queryable.concurrency)
+
+	for i, shard := range shards {
+		for rgi := range shard.LabelsFile().RowGroups() {
+			errGroup.Go(func() error {
+				rr, err := search.Filter(ctx, shard, rgi, nil, constraints[i]...)
+				if err != nil {
+					return err
+				}
+				if len(rr) == 0 {
+					return nil
+				}
+				if err := parquetutil.ReserveRows(rowCountQuota, rr); err != nil {
+					return err
+				}
+				res, err := fn(ctx, materializers[i], rgi, rr)
+				if err != nil {
+					return err
+				}
+
+				resultsMtx.Lock()
+				results = append(results, res)
+				resultsMtx.Unlock()
+				return nil
+			})
+		}
+	}
+
+	if err := errGroup.Wait(); err != nil {
+		return nil, err
+	}
+
+	limit := 0
+	if hints != nil {
+		limit = hints.Limit
+	}
+	return util.MergeUnsortedSlices(limit, results...), nil
+}
@@ -198,7 +198,7 @@ func NewParquetQueryable(
 			return nil
 		}),
 	}
-	parquetQueryable, err := queryable.NewParquetQueryable(func(ctx context.Context, mint, maxt int64) ([]parquet_storage.ParquetShard, error) {
+	shardsFinder := func(ctx context.Context, mint, maxt int64) ([]parquet_storage.ParquetShard, error) {
 		userID, err := users.TenantID(ctx)
 		if err != nil {
 			return nil, err
@@ -272,7 +272,15 @@ func NewParquetQueryable(
 		}
 
 		return shards, errGroup.Wait()
-	}, nil, cDecoder, parquetQueryableOpts...)
+	}
+
+	parquetQueryable, err := queryable.NewParquetQueryable(shardsFinder, nil, cDecoder, parquetQueryableOpts...)
+	if err != nil {
+		return nil, err
+	}
+	// Label names and values requests with matchers are served from the label columns
+	// of the matching rows, accounted in the row count quota.
+	parquetQueryable = newParquetLabelsQueryable(parquetQueryable, shardsFinder, cDecoder, limits)
 
 	p := &parquetQueryableWithFallback{
 		subservices:           manager,
@@ -388,14 +396,16 @@ func (q *parquetQuerierWithFallback) LabelValues(ctx context.Context, name strin
 		rAnnotations annotations.Annotations
 	)
 
-	if len(remaining) > 0 && q.fallbackDisabled {
-		return nil, nil, parquetConsistencyCheckError(remaining)
+	if q.fallbackDisabled {
+		if err := parquetConsistencyCheck(remaining); err != nil {
+			return nil, nil, err
+		}
 	}
 
 	if len(parquet) > 0 {
 		res, ann, qErr := q.parquetQuerier.LabelValues(InjectBlocksIntoContext(ctx, parquet...), name, hints, matchers...)
 		if qErr != nil {
-			return nil, nil, err
+			return nil, nil, qErr
 		}
 		result = res
 		rAnnotations = ann
@@ -404,7 +414,7 @@ func (q *parquetQuerierWithFallback) LabelValues(ctx context.Context, name strin
 	if len(remaining) > 0 {
 		res, ann, qErr := q.blocksStoreQuerier.LabelValues(InjectBlocksIntoContext(ctx, remaining...), name, hints, matchers...)
 		if qErr != nil {
-			return nil, nil, err
+			return nil, nil, qErr
 		}
 
 		if len(result) == 0 {
@@ -413,9 +423,7 @@ func (q *parquetQuerierWithFallback) LabelValues(ctx context.Context, name strin
 			result = strutil.MergeSlices(limit, result, res)
 		}
 
-		if rAnnotations != nil {
-			rAnnotations = rAnnotations.Merge(ann)
-		}
+		rAnnotations = rAnnotations.Merge(ann)
 	}
 
 	return result, rAnnotations, nil
@@ -442,14 +450,16 @@ func (q *parquetQuerierWithFallback) LabelNames(ctx context.Context, hints *stor
 		rAnnotations annotations.Annotations
 	)
 
-	if len(remaining) > 0 && q.fallbackDisabled {
-		return nil, nil, parquetConsistencyCheckError(remaining)
+	if q.fallbackDisabled {
+		if err := parquetConsistencyCheck(remaining); err != nil {
+			return nil, nil, err
+		}
 	}
 
 	if len(parquet) > 0 {
 		res, ann, qErr := q.parquetQuerier.LabelNames(InjectBlocksIntoContext(ctx, parquet...), hints, matchers...)
 		if qErr != nil {
-			return nil, nil, err
+			return nil, nil, qErr
 		}
 		result = res
 		rAnnotations = ann
@@ -458,7 +468,7 @@ func (q *parquetQuerierWithFallback) LabelNames(ctx context.Context, hints *stor
 	if len(remaining) > 0 {
 		res, ann, qErr := q.blocksStoreQuerier.LabelNames(InjectBlocksIntoContext(ctx, remaining...), hints, matchers...)
 		if qErr != nil {
-			return nil, nil, err
+			return nil, nil, qErr
 		}
 
 		if len(result) == 0 {
@@ -467,9 +477,7 @@ func (q *parquetQuerierWithFallback) LabelNames(ctx context.Context, hints *stor
 			result = strutil.MergeSlices(limit, result, res)
 		}
 
-		if rAnnotations != nil {
-			rAnnotations = rAnnotations.Merge(ann)
-		}
+		rAnnotations = rAnnotations.Merge(ann)
 	}
 
 	return result, rAnnotations, nil
@@ -510,9 +518,10 @@ func (q *parquetQuerierWithFallback) Select(ctx context.Context, sortSeries bool
 		return storage.ErrSeriesSet(err)
 	}
 
-	if len(remaining) > 0 && q.fallbackDisabled {
-		err = parquetConsistencyCheckError(remaining)
-		return storage.ErrSeriesSet(err)
+	if q.fallbackDisabled {
+		if err := parquetConsistencyCheck(remaining); err != nil {
+			return storage.ErrSeriesSet(err)
+		}
 	}
 
 	// Lets sort the series to merge
@@ -616,7 +625,8 @@ func (q *parquetQuerierWithFallback) getBlocks(ctx context.Context, minT, maxT i
 	parquetBlocks := make([]*bucketindex.Block, 0, len(blocks))
 	remaining := make([]*bucketindex.Block, 0, len(blocks))
 	for _, b := range blocks {
-		if useParquet && b.Parquet != nil {
+		// Blocks flagged as incompatible with parquet are always queried from TSDB.
+		if useParquet && b.IsParquet() {
 			parquetBlocks = append(parquetBlocks, b)
 			continue
 		}
@@ -690,6 +700,22 @@ func extractShardInfoFromContext(ctx context.Context) (*storepb.ShardInfo, bool)
 	return nil, false
 }
 
+// parquetConsistencyCheck returns an error if any of the input blocks, which are going to be
+// queried from TSDB, is not flagged as incompatible with parquet, meaning that it's expected
+// to be converted.
+func parquetConsistencyCheck(blocks []*bucketindex.Block) error {
+	notConverted := make([]*bucketindex.Block, 0, len(blocks))
+	for _, b := range blocks {
+		if !b.IsParquetIncompatible() {
+			notConverted = append(notConverted, b)
+		}
+	}
+	if len(notConverted) == 0 {
+		return nil
+	}
+	return parquetConsistencyCheckError(notConverted)
+}
+
 func parquetConsistencyCheckError(blocks []*bucketindex.Block) error {
 	return fmt.Errorf("consistency check failed because some blocks were not available as parquet files: %s", strings.Join(convertBlockULIDToString(blocks), " "))
 }
@@ -444,6 +444,9 @@ func TestParquetQueryable_Limits(t *testing.T) {
 		limits       *validation.Overrides
 		queryLimiter *limiter.QueryLimiter
 		expectedErr  error
+		// Label names and values requests with matchers only read the label columns of the
+		// matching rows, so only the parquet row count and data bytes limits apply.
+		expectedLabelsErr error
 	}{
 		"row count limit hit - Parquet Queryable": {
 			limits: func() *validation.Overrides {
@@ -452,8 +455,9 @@ func TestParquetQueryable_Limits(t *testing.T) {
 				limits.ParquetMaxFetchedRowCount = 1
 				return validation.NewOverrides(limits, nil)
 			}(),
-			queryLimiter: limiter.NewQueryLimiter(0, 0, 0, 0),
-			expectedErr:  fmt.Errorf("would fetch too many rows: resource exhausted (used 1)"),
+			queryLimiter:      limiter.NewQueryLimiter(0, 0, 0, 0),
+			expectedErr:       fmt.Errorf("would fetch too many rows: resource exhausted (used 1)"),
+			expectedLabelsErr: fmt.Errorf("would fetch too many rows: resource exhausted (used 1)"),
 		},
 		"max series per query limit hit": {
 			limits: func() *validation.Overrides {
@@ -499,8 +503,9 @@ func TestParquetQueryable_Limits(t *testing.T) {
 				limits.ParquetMaxFetchedDataBytes = 1
 				return validation.NewOverrides(limits, nil)
 			}(),
-			queryLimiter: limiter.NewQueryLimiter(0, 0, 0, 1),
-			expectedErr:  fmt.Errorf("error materializing labels: failed to get column indexes: failed to materialize column indexes: would fetch too many data bytes: resource exhausted (used 1)"),
+			queryLimiter:      limiter.NewQueryLimiter(0, 0, 0, 1),
+			expectedErr:       fmt.Errorf("error materializing labels: failed to get column indexes: failed to materialize column indexes: would fetch too many data bytes: resource exhausted (used 1)"),
+			expectedLabelsErr: fmt.Errorf("materializer failed to materialize columns: would fetch too many data bytes: resource exhausted (used 1)"),
 		},
 		"limits within bounds - should succeed": {
 			limits: func() *validation.Overrides {
@@ -542,6 +547,19 @@ func TestParquetQueryable_Limits(t *testing.T) {
 				labels.MustNewMatcher(labels.MatchEqual, labels.MetricName, metricName),
 			}
 
+			// The label names and values requests with matchers go through the parquet limits.
+			names, _, namesErr := querier.LabelNames(ctx, nil, matchers...)
+			values, _, valuesErr := querier.LabelValues(ctx, "series", nil, matchers...)
+			if testData.expectedLabelsErr != nil {
+				require.ErrorContains(t, namesErr, testData.expectedLabelsErr.Error())
+				require.ErrorContains(t, valuesErr, testData.expectedLabelsErr.Error())
+			} else {
+				require.NoError(t, namesErr)
+				require.NoError(t, valuesErr)
+				require.Equal(t, []string{labels.MetricName, "series"}, names)
+				require.Len(t, values, seriesCount)
+			}
+
 			set := querier.Select(ctx, true, nil, matchers...)
 			if testData.expectedErr != nil {
 				require.False(t, set.Next())
@@ -604,6 +622,7 @@ func defaultOverridesWithQueryStoreAfter(t *testing.T, queryVerticalShardSize in
 type mockParquetQuerier struct {
 	queriedBlocks []*bucketindex.Block
 	queriedHints  *storage.SelectHints
+	err           error
 }
 
 func (m *mockParquetQuerier) Select(ctx context.Context, sortSeries bool, sp *storage.SelectHints, matchers ...*labels.Matcher) storage.SeriesSet {
@@ -611,13 +630,21 @@ func (m *mockParquetQuerier) Select(ctx context.Context, sortSeries bool, sp *st
 		m.queriedBlocks = append(m.queriedBlocks, blocks...)
 	}
 	m.queriedHints = sp
-	return series.NewConcreteSeriesSet(sortSeries, nil)
+	if m.err != nil {
+		return storage.ErrSeriesSet(m.err)
+	}
+	return series.NewConcreteSeriesSet(sortSeries, []storage.Series{
+		series.NewConcreteSeries(labels.FromStrings(labels.MetricName, "fromParquet", "fromParquet", "true"), nil),
+	})
 }
 
 func (m *mockParquetQuerier) LabelValues(ctx context.Context, name string, _ *storage.LabelHints, matchers ...*labels.Matcher) ([]string, annotations.Annotations, error) {
 	if blocks, ok := ExtractBlocksFromContext(ctx); ok {
 		m.queriedBlocks = append(m.queriedBlocks, blocks...)
 	}
+	if m.err != nil {
+		return nil, nil, m.err
+	}
 	return []string{"fromParquet"}, nil, nil
 }
 
@@ -625,6 +652,9 @@ func (m *mockParquetQuerier) LabelNames(ctx context.Context, _ *storage.LabelHin
 	if blocks, ok := ExtractBlocksFromContext(ctx); ok {
 		m.queriedBlocks = append(m.queriedBlocks, blocks...)
 	}
+	if m.err != nil {
+		return nil, nil, m.err
+	}
 	return []string{"fromParquet"}, nil, nil
 }
 
@@ -1183,3 +1213,79 @@ func TestParquetQueryableFallbackDisabled(t *testing.T) {
 		})
 	})
 }
+
+func TestParquetConsistencyCheck(t *testing.T) {
+	block1 := ulid.MustNew(1, nil)
+	block2 := ulid.MustNew(2, nil)
+
+	tests := map[string]struct {
+		blocks        []*bucketindex.Block
+		expectedError string
+	}{
+		"no blocks": {},
+		"blocks not converted yet": {
+			blocks: []*bucketindex.Block{
+				{ID: block1},
+				{ID: block2},
+			},
+			expectedError: fmt.Sprintf("consistency check failed because some blocks were not available as parquet files: %s %s", block1.String(), block2.String()),
+		},
+		"blocks incompatible with parquet": {
+			blocks: []*bucketindex.Block{
+				{ID: block1, Parquet: &parquet.ConverterMarkMeta{Version: parquet.CurrentVersion, Incompatible: true}},
+				{ID: block2, Parquet: &parquet.ConverterMarkMeta{Version: parquet.CurrentVersion, Incompatible: true}},
+			},
+		},
+		"mixed blocks": {
+			blocks: []*bucketindex.Block{
+				{ID: block1, Parquet: &parquet.ConverterMarkMeta{Version: parquet.CurrentVersion, Incompatible: true}},
+				{ID: block2},
+			},
+			expectedError: fmt.Sprintf("consistency check failed because some blocks were not available as parquet files: %s", block2.String()),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := parquetConsistencyCheck(tc.blocks)
+			if tc.expectedError == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestParquetQueryable_LabelsShouldReturnParquetQuerierErrors(t *testing.T) {
+	block1 := ulid.MustNew(1, nil)
+	minT := int64(10)
+	maxT := util.TimeToMillis(time.Now())
+	ctx := user.InjectOrgID(context.Background(), "user-1")
+	matchers := []*labels.Matcher{
+		labels.MustNewMatcher(labels.MatchEqual, labels.MetricName, "fromParquet"),
+	}
+
+	finder := &blocksFinderMock{}
+	finder.On("GetBlocks", mock.Anything, "user-1", minT, mock.Anything, mock.Anything).Return(bucketindex.Blocks{
+		&bucketindex.Block{ID: block1, Parquet: &parquet.ConverterMarkMeta{Version: parquet.ParquetConverterMarkVersion1}},
+	}, map[ulid.ULID]*bucketindex.BlockDeletionMark(nil), nil)
+
+	limitErr := validation.LimitError("would fetch too many rows")
+	pq := &parquetQuerierWithFallback{
+		minT:                  minT,
+		maxT:                  maxT,
+		finder:                finder,
+		parquetQuerier:        &mockParquetQuerier{err: limitErr},
+		metrics:               newParquetQueryableFallbackMetrics(prometheus.NewRegistry()),
+		limits:                defaultOverrides(t, 0),
+		logger:                log.NewNopLogger(),
+		defaultBlockStoreType: parquetBlockStore,
+	}
+
+	_, _, err := pq.LabelNames(ctx, nil, matchers...)
+	require.ErrorIs(t, err, limitErr)
+
+	_, _, err = pq.LabelValues(ctx, labels.MetricName, nil, matchers...)
+	require.ErrorIs(t, err, limitErr)
+}
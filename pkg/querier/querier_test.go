@@ -599,6 +599,7 @@ func TestQuerierMetric(t *testing.T) {
 	var cfg Config
 	flagext.DefaultValues(&cfg)
 	cfg.MaxConcurrent = 120
+	cfg.ActiveQueryTrackerDir = t.TempDir()
 
 	overrides := validation.NewOverrides(DefaultLimitsConfig(), nil)
 
@@ -1,23 +1,30 @@
 package querier
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
+	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/storage"
 	storecache "github.com/thanos-io/thanos/pkg/store/cache"
 
+	"github.com/cortexproject/cortex/pkg/cortexpb"
 	"github.com/cortexproject/cortex/pkg/ingester/client"
 	"github.com/cortexproject/cortex/pkg/util"
 	util_log "github.com/cortexproject/cortex/pkg/util/log"
+	"github.com/cortexproject/cortex/pkg/util/users"
+	"github.com/cortexproject/cortex/pkg/util/validation"
 )
 
 // Queries are a set of matchers with time ranges - should not get into megabytes
 const maxRemoteReadQuerySize = 1024 * 1024
 
-// RemoteReadHandler handles Prometheus remote read requests.
-func RemoteReadHandler(q storage.Queryable, logger log.Logger) http.Handler {
+// RemoteReadHandler handles Prometheus remote read requests. If the series metadata is enabled for
+// the tenant, the metadata of the metric names of the returned series is also returned along the
+// series of each query.
+func RemoteReadHandler(q storage.Queryable, m MetadataQuerier, limits *validation.Overrides, logger log.Logger) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		var req client.ReadRequest
@@ -53,6 +60,9 @@ func RemoteReadHandler(q storage.Queryable, logger log.Logger) http.Handler {
 				}
 				seriesSet := querier.Select(ctx, false, params, matchers...)
 				resp.Results[i], err = client.SeriesSetToQueryResponse(seriesSet)
+				if err == nil && seriesMetadataEnabled(ctx, m, limits) {
+					resp.Results[i].Metadata, err = seriesMetadata(ctx, m, resp.Results[i].Timeseries)
+				}
 				errors <- err
 			}(i, qr)
 		}
@@ -74,3 +84,63 @@ func RemoteReadHandler(q storage.Queryable, logger log.Logger) http.Handler {
 		}
 	})
 }
+
+// seriesMetadataEnabled returns whether the series metadata is enabled for all the tenants of the request.
+func seriesMetadataEnabled(ctx context.Context, m MetadataQuerier, limits *validation.Overrides) bool {
+	if m == nil || limits == nil {
+		return false
+	}
+	userIDs, err := users.TenantIDs(ctx)
+	if err != nil {
+		return false
+	}
+	for _, userID := range userIDs {
+		if !limits.EnableSeriesMetadata(userID) {
+			return false
+		}
+	}
+	return true
+}
+
+// seriesMetadata returns the metadata of the metric names of the series. The metadata of a single
+// metric name is queried by name, while the metadata of several metric names is filtered out of
+// the tenant's metadata, to query the ingesters once.
+func seriesMetadata(ctx context.Context, m MetadataQuerier, timeseries []cortexpb.TimeSeries) ([]*cortexpb.MetricMetadata, error) {
+	names := map[string]struct{}{}
+	for _, ts := range timeseries {
+		for _, l := range ts.Labels {
+			if l.Name == model.MetricNameLabel {
+				names[l.Value] = struct{}{}
+				break
+			}
+		}
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	req := &client.MetricsMetadataRequest{Limit: defaultLimit, LimitPerMetric: defaultLimit}
+	if len(names) == 1 {
+		for name := range names {
+			req.Metric = name
+		}
+	}
+	metadata, err := m.MetricsMetadata(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []*cortexpb.MetricMetadata
+	for _, md := range metadata {
+		if _, ok := names[md.MetricFamily]; !ok {
+			continue
+		}
+		res = append(res, &cortexpb.MetricMetadata{
+			MetricFamilyName: md.MetricFamily,
+			Type:             cortexpb.MetricTypeToMetricMetadataMetricType(md.Type),
+			Unit:             md.Unit,
+			Help:             md.Help,
+		})
+	}
+	return res, nil
+}
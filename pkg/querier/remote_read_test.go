@@ -14,13 +14,17 @@ import (
 	"github.com/golang/snappy"
 	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/scrape"
 	"github.com/prometheus/prometheus/storage"
 	"github.com/prometheus/prometheus/util/annotations"
 	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
 
 	"github.com/cortexproject/cortex/pkg/cortexpb"
 	"github.com/cortexproject/cortex/pkg/ingester/client"
 	"github.com/cortexproject/cortex/pkg/querier/series"
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+	"github.com/cortexproject/cortex/pkg/util/validation"
 )
 
 func TestRemoteReadHandler(t *testing.T) {
@@ -40,7 +44,7 @@ func TestRemoteReadHandler(t *testing.T) {
 			},
 		}, nil
 	})
-	handler := RemoteReadHandler(q, log.NewNopLogger())
+	handler := RemoteReadHandler(q, nil, nil, log.NewNopLogger())
 
 	requestBody, err := proto.Marshal(&client.ReadRequest{
 		Queries: []*client.QueryRequest{
@@ -88,6 +92,107 @@ func TestRemoteReadHandler(t *testing.T) {
 	require.Equal(t, expected, response)
 }
 
+type metadataQuerierFunc func(ctx context.Context, req *client.MetricsMetadataRequest) ([]scrape.MetricMetadata, error)
+
+func (f metadataQuerierFunc) MetricsMetadata(ctx context.Context, req *client.MetricsMetadataRequest) ([]scrape.MetricMetadata, error) {
+	return f(ctx, req)
+}
+
+func TestRemoteReadHandler_SeriesMetadata(t *testing.T) {
+	t.Parallel()
+	metadata := []scrape.MetricMetadata{
+		{MetricFamily: "requests_total", Type: model.MetricTypeCounter, Unit: "requests", Help: "Total number of requests."},
+		{MetricFamily: "temperature", Type: model.MetricTypeGauge, Unit: "celsius", Help: "Current temperature."},
+		{MetricFamily: "other", Type: model.MetricTypeGauge, Help: "Not queried."},
+	}
+	requestsMetadata := &cortexpb.MetricMetadata{MetricFamilyName: "requests_total", Type: cortexpb.COUNTER, Unit: "requests", Help: "Total number of requests."}
+	temperatureMetadata := &cortexpb.MetricMetadata{MetricFamilyName: "temperature", Type: cortexpb.GAUGE, Unit: "celsius", Help: "Current temperature."}
+
+	for name, tc := range map[string]struct {
+		enabled          bool
+		metricNames      []string
+		expectedRequest  *client.MetricsMetadataRequest
+		expectedMetadata []*cortexpb.MetricMetadata
+	}{
+		"disabled": {
+			metricNames: []string{"requests_total"},
+		},
+		"single metric name": {
+			enabled:          true,
+			metricNames:      []string{"requests_total"},
+			expectedRequest:  &client.MetricsMetadataRequest{Limit: -1, LimitPerMetric: -1, Metric: "requests_total"},
+			expectedMetadata: []*cortexpb.MetricMetadata{requestsMetadata},
+		},
+		"several metric names": {
+			enabled:          true,
+			metricNames:      []string{"requests_total", "temperature"},
+			expectedRequest:  &client.MetricsMetadataRequest{Limit: -1, LimitPerMetric: -1},
+			expectedMetadata: []*cortexpb.MetricMetadata{requestsMetadata, temperatureMetadata},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var matrix model.Matrix
+			for _, name := range tc.metricNames {
+				matrix = append(matrix, &model.SampleStream{
+					Metric: model.Metric{model.MetricNameLabel: model.LabelValue(name), "job": "test"},
+					Values: []model.SamplePair{{Timestamp: 1, Value: 1}},
+				})
+			}
+			q := storage.QueryableFunc(func(mint, maxt int64) (storage.Querier, error) {
+				return mockQuerier{matrix: matrix}, nil
+			})
+
+			var requests []*client.MetricsMetadataRequest
+			m := metadataQuerierFunc(func(_ context.Context, req *client.MetricsMetadataRequest) ([]scrape.MetricMetadata, error) {
+				requests = append(requests, req)
+				if req.Metric != "" {
+					for _, md := range metadata {
+						if md.MetricFamily == req.Metric {
+							return []scrape.MetricMetadata{md}, nil
+						}
+					}
+					return nil, nil
+				}
+				return metadata, nil
+			})
+
+			limits := validation.Limits{}
+			flagext.DefaultValues(&limits)
+			limits.EnableSeriesMetadata = tc.enabled
+			handler := RemoteReadHandler(q, m, validation.NewOverrides(limits, nil), log.NewNopLogger())
+
+			requestBody, err := proto.Marshal(&client.ReadRequest{
+				Queries: []*client.QueryRequest{{StartTimestampMs: 0, EndTimestampMs: 10}},
+			})
+			require.NoError(t, err)
+			request, err := http.NewRequest("GET", "/query", bytes.NewReader(snappy.Encode(nil, requestBody)))
+			require.NoError(t, err)
+			request = request.WithContext(user.InjectOrgID(request.Context(), "user-1"))
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusOK, recorder.Result().StatusCode)
+
+			responseBody, err := io.ReadAll(recorder.Result().Body)
+			require.NoError(t, err)
+			responseBody, err = snappy.Decode(nil, responseBody)
+			require.NoError(t, err)
+			var response client.ReadResponse
+			require.NoError(t, proto.Unmarshal(responseBody, &response))
+
+			require.Len(t, response.Results, 1)
+			require.Len(t, response.Results[0].Timeseries, len(tc.metricNames))
+			require.ElementsMatch(t, tc.expectedMetadata, response.Results[0].Metadata)
+			if tc.expectedRequest == nil {
+				require.Empty(t, requests)
+			} else {
+				require.Equal(t, []*client.MetricsMetadataRequest{tc.expectedRequest}, requests)
+			}
+		})
+	}
+}
+
 type mockQuerier struct {
 	matrix model.Matrix
 }
@@ -0,0 +1,353 @@
+package ring
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+)
+
+const (
+	adminActionState     = "state"
+	adminActionForget    = "forget"
+	adminActionRebalance = "rebalance"
+
+	previewInstancePrefix = "preview-"
+)
+
+var (
+	errAdminInstanceNotFound = errors.New("instance not found in the ring")
+	errAdminEmptyRing        = errors.New("found empty ring")
+)
+
+// AdminStateTransitionAllowed returns whether the ring admin API can move an instance from
+// one state to the other. Only instances which completed joining the ring can be moved.
+func AdminStateTransitionAllowed(from, to InstanceState) bool {
+	switch from {
+	case ACTIVE:
+		return to == READONLY || to == LEAVING
+	case READONLY:
+		return to == ACTIVE || to == LEAVING
+	default:
+		return false
+	}
+}
+
+type adminInstanceDesc struct {
+	ID                string  `json:"id"`
+	Zone              string  `json:"zone"`
+	State             string  `json:"state"`
+	Address           string  `json:"address"`
+	NumTokens         int     `json:"tokens"`
+	Ownership         float64 `json:"ownership"`
+	ExpectedOwnership float64 `json:"expected_ownership"`
+}
+
+type adminPreview struct {
+	AddedInstances   []string            `json:"added_instances"`
+	RemovedInstances []string            `json:"removed_instances"`
+	Instances        []adminInstanceDesc `json:"instances"`
+}
+
+type adminHTTPResponse struct {
+	Instances []adminInstanceDesc `json:"instances"`
+	Preview   *adminPreview       `json:"preview,omitempty"`
+	Now       time.Time           `json:"now"`
+}
+
+// AdminHandler serves the JSON ring admin API. GET requests return the ownership of each
+// instance, optionally along with a preview of the ownership after adding or removing
+// instances. POST requests change the state of an instance, forget it, or rebalance its
+// tokens, through the KV store CAS loop.
+func (r *Ring) AdminHandler(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+		r.serveAdminStatus(w, req)
+	case http.MethodPost:
+		r.serveAdminAction(w, req)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (r *Ring) serveAdminStatus(w http.ResponseWriter, req *http.Request) {
+	var (
+		addInstances int
+		numTokens    int
+		err          error
+	)
+
+	if v := req.FormValue("add_instances"); v != "" {
+		if addInstances, err = strconv.Atoi(v); err != nil || addInstances < 0 {
+			http.Error(w, "invalid add_instances: must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := req.FormValue("tokens"); v != "" {
+		if numTokens, err = strconv.Atoi(v); err != nil || numTokens <= 0 {
+			http.Error(w, "invalid tokens: must be a positive integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var removeInstances []string
+	if v := req.FormValue("remove_instances"); v != "" {
+		removeInstances = strings.Split(v, ",")
+	}
+
+	r.mtx.RLock()
+	desc := r.ringDesc.Clone().(*Desc)
+	r.mtx.RUnlock()
+
+	resp := adminHTTPResponse{
+		Instances: desc.adminInstances(r.cfg.ZoneAwarenessEnabled),
+		Now:       time.Now(),
+	}
+
+	if addInstances > 0 || len(removeInstances) > 0 {
+		preview, err := desc.previewScaling(addInstances, req.FormValue("zone"), numTokens, removeInstances, r.cfg.ZoneAwarenessEnabled)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		resp.Preview = preview
+	}
+
+	writeJSONResponse(w, resp)
+}
+
+func (r *Ring) serveAdminAction(w http.ResponseWriter, req *http.Request) {
+	id := req.FormValue("instance")
+	if id == "" {
+		http.Error(w, "instance is required", http.StatusBadRequest)
+		return
+	}
+
+	var err error
+	switch action := req.FormValue("action"); action {
+	case adminActionState:
+		state, ok := InstanceState_value[strings.ToUpper(req.FormValue("state"))]
+		if !ok {
+			http.Error(w, "invalid state", http.StatusBadRequest)
+			return
+		}
+		err = r.changeInstanceState(req.Context(), id, InstanceState(state))
+	case adminActionForget:
+		err = r.forgetInstance(req.Context(), id)
+	case adminActionRebalance:
+		numTokens := 0
+		if v := req.FormValue("tokens"); v != "" {
+			if numTokens, err = strconv.Atoi(v); err != nil || numTokens <= 0 {
+				http.Error(w, "invalid tokens: must be a positive integer", http.StatusBadRequest)
+				return
+			}
+		}
+		err = r.rebalanceInstanceTokens(req.Context(), id, numTokens)
+	default:
+		http.Error(w, fmt.Sprintf("invalid action %q: must be one of %s, %s, %s", action, adminActionState, adminActionForget, adminActionRebalance), http.StatusBadRequest)
+		return
+	}
+
+	if err != nil {
+		level.Warn(r.logger).Log("msg", "ring admin action failed", "instance", id, "action", req.FormValue("action"), "err", err)
+
+		switch {
+		case errors.Is(err, errAdminInstanceNotFound), errors.Is(err, errAdminEmptyRing):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+		return
+	}
+
+	level.Info(r.logger).Log("msg", "ring admin action succeeded", "instance", id, "action", req.FormValue("action"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// updateInstance applies the update function to the instance in the KV store. The instance
+// timestamp is bumped, so that the change is propagated by memberlist, and the change is marked
+// as made through the admin API, so that the lifecycler of the instance adopts it.
+func (r *Ring) updateInstance(ctx context.Context, id string, update func(*Desc, *InstanceDesc) error) error {
+	return r.KVClient.CAS(ctx, r.key, func(in any) (out any, retry bool, err error) {
+		if in == nil {
+			return nil, false, errAdminEmptyRing
+		}
+
+		ringDesc := in.(*Desc)
+		instance, ok := ringDesc.Ingesters[id]
+		if !ok {
+			return nil, false, errAdminInstanceNotFound
+		}
+
+		if err := update(ringDesc, &instance); err != nil {
+			return nil, false, err
+		}
+
+		now := time.Now().Unix()
+		instance.Timestamp = now
+		instance.AdminUpdatedTimestamp = now
+		ringDesc.Ingesters[id] = instance
+		return ringDesc, true, nil
+	})
+}
+
+func (r *Ring) changeInstanceState(ctx context.Context, id string, state InstanceState) error {
+	return r.updateInstance(ctx, id, func(_ *Desc, instance *InstanceDesc) error {
+		if !AdminStateTransitionAllowed(instance.State, state) {
+			return fmt.Errorf("changing instance state from %v -> %v is disallowed", instance.State, state)
+		}
+
+		instance.State = state
+		return nil
+	})
+}
+
+func (r *Ring) forgetInstance(ctx context.Context, id string) error {
+	return r.KVClient.CAS(ctx, r.key, func(in any) (out any, retry bool, err error) {
+		if in == nil {
+			return nil, false, errAdminEmptyRing
+		}
+
+		ringDesc := in.(*Desc)
+		if _, ok := ringDesc.Ingesters[id]; !ok {
+			return nil, false, errAdminInstanceNotFound
+		}
+
+		ringDesc.RemoveIngester(id)
+		return ringDesc, true, nil
+	})
+}
+
+// rebalanceInstanceTokens replaces the tokens of the instance with numTokens tokens generated by the
+// MinimizeSpreadTokenGenerator. If numTokens is 0, the instance keeps its number of tokens.
+func (r *Ring) rebalanceInstanceTokens(ctx context.Context, id string, numTokens int) error {
+	return r.updateInstance(ctx, id, func(ringDesc *Desc, instance *InstanceDesc) error {
+		if instance.State != ACTIVE && instance.State != READONLY {
+			return fmt.Errorf("rebalancing the tokens of an instance in state %v is disallowed", instance.State)
+		}
+
+		if numTokens == 0 {
+			numTokens = len(instance.Tokens)
+		}
+
+		// Generate the new tokens as if the instance was joining the ring without tokens.
+		instance.Tokens = nil
+		ringDesc.Ingesters[id] = *instance
+		instance.Tokens = NewMinimizeSpreadTokenGenerator().GenerateTokens(ringDesc, id, instance.Zone, numTokens, true)
+		return nil
+	})
+}
+
+// previewScaling returns the ownership of each instance after removing the removeInstances
+// and adding addInstances instances with numTokens tokens each, generated by the
+// MinimizeSpreadTokenGenerator. Instances are added to the given zone or, if empty and
+// zoneAware is true, to each zone. If numTokens is 0, the average number of tokens per
+// instance is used. The desc is modified in place.
+func (d *Desc) previewScaling(addInstances int, zone string, numTokens int, removeInstances []string, zoneAware bool) (*adminPreview, error) {
+	preview := &adminPreview{
+		AddedInstances:   []string{},
+		RemovedInstances: []string{},
+	}
+
+	for _, id := range removeInstances {
+		if _, ok := d.Ingesters[id]; !ok {
+			return nil, fmt.Errorf("instance %s not found in the ring", id)
+		}
+		d.RemoveIngester(id)
+		preview.RemovedInstances = append(preview.RemovedInstances, id)
+	}
+
+	if numTokens == 0 && len(d.Ingesters) > 0 {
+		total := 0
+		for _, instance := range d.Ingesters {
+			total += len(instance.Tokens)
+		}
+		numTokens = int(math.Round(float64(total) / float64(len(d.Ingesters))))
+	}
+
+	zones := []string{zone}
+	if zone == "" && zoneAware {
+		zones = getZones(d.getTokensByZone())
+	}
+
+	tg := NewMinimizeSpreadTokenGenerator()
+	now := time.Now()
+	for i := 0; i < addInstances; i++ {
+		for _, z := range zones {
+			id := previewInstancePrefix + strconv.Itoa(i)
+			if z != "" {
+				id = previewInstancePrefix + z + "-" + strconv.Itoa(i)
+			}
+
+			instance := d.AddIngester(id, "", z, nil, ACTIVE, now)
+			instance.Tokens = tg.GenerateTokens(d, id, z, numTokens, true)
+			d.Ingesters[id] = instance
+			preview.AddedInstances = append(preview.AddedInstances, id)
+		}
+	}
+
+	preview.Instances = d.adminInstances(zoneAware)
+	return preview, nil
+}
+
+// adminInstances returns the instances of the ring sorted by ID, along with their ownership.
+func (d *Desc) adminInstances(zoneAware bool) []adminInstanceDesc {
+	owned := d.tokenOwnership(zoneAware)
+
+	instancesByZone := map[string]int{}
+	for _, instance := range d.Ingesters {
+		instancesByZone[instance.Zone]++
+	}
+
+	out := make([]adminInstanceDesc, 0, len(d.Ingesters))
+	for id, instance := range d.Ingesters {
+		expected := 100 / float64(len(d.Ingesters))
+		if zoneAware {
+			expected = 100 / float64(instancesByZone[instance.Zone])
+		}
+
+		out = append(out, adminInstanceDesc{
+			ID:                id,
+			Zone:              instance.Zone,
+			State:             instance.State.String(),
+			Address:           instance.Addr,
+			NumTokens:         len(instance.Tokens),
+			Ownership:         (float64(owned[id]) / float64(math.MaxUint32+1)) * 100,
+			ExpectedOwnership: expected,
+		})
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// tokenOwnership returns the size of the token ranges owned by each instance. When zoneAware
+// is true, the ranges are computed within the zone of each instance.
+func (d *Desc) tokenOwnership(zoneAware bool) map[string]int64 {
+	var tokenGroups [][]uint32
+	if zoneAware {
+		for _, tokens := range d.getTokensByZone() {
+			tokenGroups = append(tokenGroups, tokens)
+		}
+	} else {
+		tokenGroups = [][]uint32{d.GetTokens()}
+	}
+
+	instanceByToken := d.getTokensInfo()
+	owned := map[string]int64{}
+	for _, tokens := range tokenGroups {
+		for i := 1; i <= len(tokens); i++ {
+			index := i % len(tokens)
+			owned[instanceByToken[tokens[index]].InstanceID] += tokenDistance(tokens[i-1], tokens[index])
+		}
+	}
+
+	return owned
+}
@@ -0,0 +1,270 @@
+package ring
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexproject/cortex/pkg/ring/kv"
+	"github.com/cortexproject/cortex/pkg/ring/kv/consul"
+	"github.com/cortexproject/cortex/pkg/util/services"
+	"github.com/cortexproject/cortex/pkg/util/test"
+)
+
+func TestAdminStateTransitionAllowed(t *testing.T) {
+	assert.True(t, AdminStateTransitionAllowed(ACTIVE, READONLY))
+	assert.True(t, AdminStateTransitionAllowed(ACTIVE, LEAVING))
+	assert.True(t, AdminStateTransitionAllowed(READONLY, ACTIVE))
+	assert.True(t, AdminStateTransitionAllowed(READONLY, LEAVING))
+	assert.False(t, AdminStateTransitionAllowed(ACTIVE, PENDING))
+	assert.False(t, AdminStateTransitionAllowed(JOINING, ACTIVE))
+	assert.False(t, AdminStateTransitionAllowed(LEAVING, ACTIVE))
+	assert.False(t, AdminStateTransitionAllowed(PENDING, READONLY))
+}
+
+func TestDesc_previewScaling(t *testing.T) {
+	desc := NewDesc()
+	for i := 0; i < 3; i++ {
+		id, instance := generateRingInstance(i, i%3, 128)
+		desc.Ingesters[id] = instance
+	}
+
+	t.Run("add instances to each zone", func(t *testing.T) {
+		preview, err := desc.Clone().(*Desc).previewScaling(1, "", 0, nil, true)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"preview-zone-0-0", "preview-zone-1-0", "preview-zone-2-0"}, preview.AddedInstances)
+		require.Len(t, preview.Instances, 6)
+
+		// Each zone has 2 instances, so every instance should own roughly half of the ring.
+		for _, instance := range preview.Instances {
+			assert.Equal(t, 128, instance.NumTokens)
+			assert.Equal(t, float64(50), instance.ExpectedOwnership)
+			assert.InDelta(t, 50, instance.Ownership, 5, instance.ID)
+		}
+	})
+
+	t.Run("add instances to a single zone", func(t *testing.T) {
+		preview, err := desc.Clone().(*Desc).previewScaling(2, "zone-1", 64, nil, true)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"preview-zone-1-0", "preview-zone-1-1"}, preview.AddedInstances)
+
+		for _, instance := range preview.Instances {
+			if instance.Zone != "zone-1" {
+				assert.Equal(t, float64(100), instance.Ownership)
+				continue
+			}
+			assert.InDelta(t, float64(100)/3, instance.ExpectedOwnership, 0.001)
+		}
+	})
+
+	t.Run("remove instances", func(t *testing.T) {
+		preview, err := desc.Clone().(*Desc).previewScaling(0, "", 0, []string{"instance-0"}, false)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"instance-0"}, preview.RemovedInstances)
+		require.Len(t, preview.Instances, 2)
+
+		total := float64(0)
+		for _, instance := range preview.Instances {
+			total += instance.Ownership
+		}
+		assert.InDelta(t, 100, total, 0.001)
+	})
+
+	t.Run("remove unknown instance", func(t *testing.T) {
+		_, err := desc.Clone().(*Desc).previewScaling(0, "", 0, []string{"unknown"}, false)
+		require.Error(t, err)
+	})
+}
+
+func TestRing_AdminHandler(t *testing.T) {
+	inmem, closer := consul.NewInMemoryClient(GetCodec(), log.NewNopLogger(), nil)
+	t.Cleanup(func() { assert.NoError(t, closer.Close()) })
+
+	ctx := context.Background()
+	desc := NewDesc()
+	for i := 0; i < 3; i++ {
+		id, instance := generateRingInstance(i, 0, 128)
+		desc.Ingesters[id] = instance
+	}
+	require.NoError(t, inmem.CAS(ctx, "ring", func(_ any) (any, bool, error) {
+		return desc, true, nil
+	}))
+
+	cfg := Config{
+		KVStore:           kv.Config{Mock: inmem},
+		HeartbeatTimeout:  time.Minute,
+		ReplicationFactor: 1,
+	}
+	r, err := New(cfg, "ingester", "ring", log.NewNopLogger(), nil)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(ctx, r))
+	t.Cleanup(func() {
+		_ = services.StopAndAwaitTerminated(ctx, r)
+	})
+
+	post := func(values url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/ring/admin", strings.NewReader(values.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		r.AdminHandler(rec, req)
+		return rec
+	}
+
+	getInstance := func(id string) (InstanceDesc, bool) {
+		val, err := inmem.Get(ctx, "ring")
+		require.NoError(t, err)
+		instance, ok := val.(*Desc).Ingesters[id]
+		return instance, ok
+	}
+
+	t.Run("GET returns the ownership and the preview", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		r.AdminHandler(rec, httptest.NewRequest(http.MethodGet, "/ring/admin?add_instances=1", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		resp := adminHTTPResponse{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		require.Len(t, resp.Instances, 3)
+		assert.Equal(t, "instance-0", resp.Instances[0].ID)
+		assert.Equal(t, ACTIVE.String(), resp.Instances[0].State)
+		require.NotNil(t, resp.Preview)
+		assert.Equal(t, []string{"preview-0"}, resp.Preview.AddedInstances)
+		assert.Len(t, resp.Preview.Instances, 4)
+	})
+
+	t.Run("GET rejects invalid parameters", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		r.AdminHandler(rec, httptest.NewRequest(http.MethodGet, "/ring/admin?add_instances=-1", nil))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("POST changes the instance state", func(t *testing.T) {
+		rec := post(url.Values{"action": {"state"}, "instance": {"instance-1"}, "state": {"readonly"}})
+		require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+
+		instance, ok := getInstance("instance-1")
+		require.True(t, ok)
+		assert.Equal(t, READONLY, instance.State)
+
+		// Moving back to JOINING is not allowed.
+		rec = post(url.Values{"action": {"state"}, "instance": {"instance-1"}, "state": {"JOINING"}})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("POST rebalances the instance tokens", func(t *testing.T) {
+		before, _ := getInstance("instance-2")
+
+		rec := post(url.Values{"action": {"rebalance"}, "instance": {"instance-2"}, "tokens": {"64"}})
+		require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+
+		after, _ := getInstance("instance-2")
+		assert.Len(t, after.Tokens, 64)
+		assert.NotEqual(t, before.Tokens, after.Tokens)
+		assert.GreaterOrEqual(t, after.Timestamp, before.Timestamp)
+	})
+
+	t.Run("POST forgets the instance", func(t *testing.T) {
+		rec := post(url.Values{"action": {"forget"}, "instance": {"instance-0"}})
+		require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+
+		_, ok := getInstance("instance-0")
+		assert.False(t, ok)
+
+		rec = post(url.Values{"action": {"forget"}, "instance": {"instance-0"}})
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("POST rejects unknown actions", func(t *testing.T) {
+		rec := post(url.Values{"action": {"unknown"}, "instance": {"instance-1"}})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestLifecycler_ShouldAdoptStateChangedThroughAdminAPI(t *testing.T) {
+	inmem, closer := consul.NewInMemoryClient(GetCodec(), log.NewNopLogger(), nil)
+	t.Cleanup(func() { assert.NoError(t, closer.Close()) })
+
+	ctx := context.Background()
+	cfg := Config{
+		KVStore:           kv.Config{Mock: inmem},
+		HeartbeatTimeout:  time.Minute,
+		ReplicationFactor: 1,
+	}
+	r, err := New(cfg, "ingester", "test", log.NewNopLogger(), nil)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(ctx, r))
+	t.Cleanup(func() {
+		_ = services.StopAndAwaitTerminated(ctx, r)
+	})
+
+	lc := startLifecycler(t, cfg, 100*time.Millisecond, 1, 1)
+	test.Poll(t, time.Second, ACTIVE, func() any {
+		return lc.GetState()
+	})
+
+	require.NoError(t, r.changeInstanceState(ctx, lc.ID, READONLY))
+	test.Poll(t, time.Second, READONLY, func() any {
+		return lc.GetState()
+	})
+
+	require.NoError(t, r.rebalanceInstanceTokens(ctx, lc.ID, 0))
+	val, err := inmem.Get(ctx, "test")
+	require.NoError(t, err)
+	expected := Tokens(val.(*Desc).Ingesters[lc.ID].Tokens)
+	test.Poll(t, time.Second, expected, func() any {
+		return lc.getTokens()
+	})
+}
+
+func TestLifecycler_ShouldNotAdoptStateChangedOutsideAdminAPI(t *testing.T) {
+	inmem, closer := consul.NewInMemoryClient(GetCodec(), log.NewNopLogger(), nil)
+	t.Cleanup(func() { assert.NoError(t, closer.Close()) })
+
+	ctx := context.Background()
+	cfg := Config{
+		KVStore:           kv.Config{Mock: inmem},
+		HeartbeatTimeout:  time.Minute,
+		ReplicationFactor: 1,
+	}
+
+	lc := startLifecycler(t, cfg, 100*time.Millisecond, 1, 1)
+	test.Poll(t, time.Second, ACTIVE, func() any {
+		return lc.GetState()
+	})
+
+	// A LEAVING state written without the admin API marker, eg. by a previous run of the
+	// instance, is overwritten by the next heartbeat.
+	require.NoError(t, inmem.CAS(ctx, "test", func(in any) (any, bool, error) {
+		desc := in.(*Desc)
+		instance := desc.Ingesters[lc.ID]
+		instance.State = LEAVING
+		desc.Ingesters[lc.ID] = instance
+		return desc, true, nil
+	}))
+	test.Poll(t, time.Second, ACTIVE, func() any {
+		val, err := inmem.Get(ctx, "test")
+		require.NoError(t, err)
+		return val.(*Desc).Ingesters[lc.ID].State
+	})
+	assert.Equal(t, ACTIVE, lc.GetState())
+
+	// The admin API marker is reset once the change has been adopted.
+	r, err := New(cfg, "ingester", "test", log.NewNopLogger(), nil)
+	require.NoError(t, err)
+	require.NoError(t, r.changeInstanceState(ctx, lc.ID, READONLY))
+	test.Poll(t, time.Second, int64(0), func() any {
+		val, err := inmem.Get(ctx, "test")
+		require.NoError(t, err)
+		return val.(*Desc).Ingesters[lc.ID].AdminUpdatedTimestamp
+	})
+	assert.Equal(t, READONLY, lc.GetState())
+}
@@ -55,6 +55,11 @@ type BasicLifecyclerConfig struct {
 	NumTokens               int
 	TokensGeneratorStrategy string
 
+	// TokensMigrationInterval is the interval at which the instance replaces some of its tokens,
+	// to get closer to an even ownership within its zone. 0 disables the migration.
+	TokensMigrationInterval time.Duration
+	TokensMigrationStep     int
+
 	// If true lifecycler doesn't unregister instance from the ring when it's stopping. Default value is false,
 	// which means unregistering.
 	KeepInstanceInTheRingOnShutdown bool
@@ -211,11 +216,22 @@ func (l *BasicLifecycler) running(ctx context.Context) error {
 		heartbeatTickerChan = heartbeatTicker.C
 	}
 
+	var tokensMigrationTickerChan <-chan time.Time
+	if l.cfg.TokensMigrationInterval > 0 && l.cfg.TokensMigrationStep > 0 {
+		tokensMigrationTicker := time.NewTicker(l.cfg.TokensMigrationInterval)
+		defer tokensMigrationTicker.Stop()
+
+		tokensMigrationTickerChan = tokensMigrationTicker.C
+	}
+
 	for {
 		select {
 		case <-heartbeatTickerChan:
 			l.heartbeat(ctx)
 
+		case <-tokensMigrationTickerChan:
+			l.migrateTokens(ctx)
+
 		case f := <-l.actorChan:
 			f()
 
@@ -464,9 +480,11 @@ func (l *BasicLifecycler) updateInstance(ctx context.Context, update func(*Desc,
 // heartbeat updates the instance timestamp within the ring. This function is guaranteed
 // to be called within the lifecycler main goroutine.
 func (l *BasicLifecycler) heartbeat(ctx context.Context) {
+	var skew float64
 	err := l.updateInstance(ctx, func(r *Desc, i *InstanceDesc) bool {
 		l.delegate.OnRingInstanceHeartbeat(l, r, i)
 		i.Timestamp = time.Now().Unix()
+		skew = r.ownershipSkew()[l.cfg.ID]
 		return true
 	})
 
@@ -476,6 +494,37 @@ func (l *BasicLifecycler) heartbeat(ctx context.Context) {
 	}
 
 	l.metrics.heartbeats.Inc()
+	l.metrics.ownershipSkew.Set(skew)
+}
+
+// migrateTokens replaces some of the instance tokens to get closer to an even ownership within
+// its zone. This function is guaranteed to be called within the lifecycler main goroutine.
+func (l *BasicLifecycler) migrateTokens(ctx context.Context) {
+	var migrated int
+	err := l.updateInstance(ctx, func(r *Desc, i *InstanceDesc) bool {
+		migrated = 0
+
+		tokens, ok := migrateTokens(r, l.cfg.ID, l.cfg.TokensMigrationStep, l.TokenGenerator)
+		if !ok {
+			return false
+		}
+
+		migrated = countReplacedTokens(i.Tokens, tokens)
+		i.Tokens = tokens
+		return true
+	})
+
+	if err != nil {
+		level.Warn(l.logger).Log("msg", "failed to migrate instance tokens in the ring", "ring", l.ringName, "err", err)
+		return
+	}
+	if migrated == 0 {
+		return
+	}
+
+	level.Info(l.logger).Log("msg", "migrated instance tokens in the ring", "ring", l.ringName, "migrated", migrated)
+	l.metrics.tokensMigrated.Add(float64(migrated))
+	l.delegate.OnRingInstanceTokens(l, l.GetTokens())
 }
 
 // changeState of the instance within the ring. This function is guaranteed
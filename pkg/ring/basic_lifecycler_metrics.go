@@ -9,6 +9,9 @@ type BasicLifecyclerMetrics struct {
 	heartbeats  prometheus.Counter
 	tokensOwned prometheus.Gauge
 	tokensToOwn prometheus.Gauge
+
+	ownershipSkew  prometheus.Gauge
+	tokensMigrated prometheus.Counter
 }
 
 func NewBasicLifecyclerMetrics(ringName string, reg prometheus.Registerer) *BasicLifecyclerMetrics {
@@ -28,5 +31,15 @@ func NewBasicLifecyclerMetrics(ringName string, reg prometheus.Registerer) *Basi
 			Help:        "The number of tokens to own in the ring.",
 			ConstLabels: prometheus.Labels{"name": ringName},
 		}),
+		ownershipSkew: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name:        "ring_member_ownership_skew",
+			Help:        "The relative difference between the token ranges owned in the ring within the zone and the ranges which would be owned if the ownership was even.",
+			ConstLabels: prometheus.Labels{"name": ringName},
+		}),
+		tokensMigrated: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name:        "ring_member_tokens_migrated_total",
+			Help:        "The total number of tokens replaced by the tokens migration.",
+			ConstLabels: prometheus.Labels{"name": ringName},
+		}),
 	}
 }
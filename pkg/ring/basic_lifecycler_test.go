@@ -2,6 +2,7 @@ package ring
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
@@ -432,6 +433,53 @@ func TestBasicLifecycler_updateInstance_ShouldAddInstanceToTheRingIfDoesNotExist
 	assert.Equal(t, expectedRegisteredAt.Unix(), desc.GetRegisteredAt().Unix())
 }
 
+func TestBasicLifecycler_TokensMigration(t *testing.T) {
+	ctx := context.Background()
+	cfg := prepareBasicLifecyclerConfig()
+	cfg.HeartbeatPeriod = 10 * time.Millisecond
+	cfg.TokensGeneratorStrategy = minimizeSpreadTokenStrategy
+	cfg.TokensMigrationInterval = 10 * time.Millisecond
+	cfg.TokensMigrationStep = 2
+
+	lifecycler, delegate, store, err := prepareBasicLifecycler(t, cfg)
+	require.NoError(t, err)
+	defer services.StopAndAwaitTerminated(ctx, lifecycler) //nolint:errcheck
+
+	// Another instance of the same zone owns almost the whole ring, because the instance
+	// registers tokens all next to each other.
+	require.NoError(t, store.CAS(ctx, testRingKey, func(in any) (out any, retry bool, err error) {
+		desc := NewDesc()
+		desc.AddIngester("other-id", "127.0.0.2:12345", cfg.Zone, Tokens{10, 20, 30, 40, 50}, ACTIVE, time.Now())
+		return desc, true, nil
+	}))
+
+	var notifiedTokens atomicTokens
+	delegate.onRegister = func(_ *BasicLifecycler, _ Desc, _ bool, _ string, _ InstanceDesc) (InstanceState, Tokens) {
+		return ACTIVE, Tokens{100, 101, 102, 103, 104}
+	}
+	delegate.onTokensChanged = func(_ *BasicLifecycler, tokens Tokens) {
+		notifiedTokens.Store(tokens)
+	}
+
+	require.NoError(t, services.StartAndAwaitRunning(ctx, lifecycler))
+
+	test.Poll(t, 5*time.Second, true, func() any {
+		return testutil.ToFloat64(lifecycler.metrics.tokensMigrated) > 0
+	})
+
+	desc, ok := getInstanceFromStore(t, store, testInstanceID)
+	require.True(t, ok)
+	assert.Len(t, desc.GetTokens(), cfg.NumTokens)
+	assert.NotEqual(t, Tokens{100, 101, 102, 103, 104}, Tokens(desc.GetTokens()))
+	assert.Equal(t, lifecycler.GetTokens(), notifiedTokens.Load())
+
+	// The ownership skew is reported on heartbeat, and gets closer to 0 while migrating.
+	test.Poll(t, 5*time.Second, true, func() any {
+		skew := testutil.ToFloat64(lifecycler.metrics.ownershipSkew)
+		return skew > -0.5 && skew < 0.5
+	})
+}
+
 func prepareBasicLifecyclerConfig() BasicLifecyclerConfig {
 	return BasicLifecyclerConfig{
 		ID:                  testInstanceID,
@@ -459,6 +507,23 @@ func prepareBasicLifecyclerWithDelegate(t testing.TB, cfg BasicLifecyclerConfig,
 	return lifecycler, store, err
 }
 
+type atomicTokens struct {
+	mtx    sync.Mutex
+	tokens Tokens
+}
+
+func (a *atomicTokens) Store(tokens Tokens) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	a.tokens = tokens
+}
+
+func (a *atomicTokens) Load() Tokens {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	return a.tokens
+}
+
 type mockDelegate struct {
 	onRegister      func(lifecycler *BasicLifecycler, ringDesc Desc, instanceExists bool, instanceID string, instanceDesc InstanceDesc) (InstanceState, Tokens)
 	onTokensChanged func(lifecycler *BasicLifecycler, tokens Tokens)
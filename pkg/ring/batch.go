@@ -86,9 +86,10 @@ func DoBatch(ctx context.Context, op Operation, r ReadRing, e util.AsyncExecutor
 	instances := make(map[string]instance, r.InstancesCount())
 
 	var (
-		bufDescs [GetBufferSize]InstanceDesc
-		bufHosts [GetBufferSize]string
-		bufZones = make(map[string]int, GetZoneSize)
+		bufDescs        [GetBufferSize]InstanceDesc
+		bufHosts        [GetBufferSize]string
+		bufZones        = make(map[string]int, GetZoneSize)
+		pendingTrackers = len(keys)
 	)
 	for i, key := range keys {
 		replicationSet, err := r.Get(key, op, bufDescs[:0], bufHosts[:0], bufZones)
@@ -96,21 +97,31 @@ func DoBatch(ctx context.Context, op Operation, r ReadRing, e util.AsyncExecutor
 			cleanup()
 			return err
 		}
+
+		// When the replication set is grouped by partition, the quorum is tracked per partition.
+		if len(replicationSet.Partitions) > 0 {
+			partitionTrackers := make([]itemTracker, len(replicationSet.Partitions))
+			pendingTrackers += len(partitionTrackers) - 1
+
+			for p, partition := range replicationSet.Partitions {
+				maxErrors := replicationSet.PartitionsMaxErrors[p]
+				partitionTrackers[p].minSuccess = len(partition) - maxErrors
+				partitionTrackers[p].maxFailures = maxErrors
+				partitionTrackers[p].remaining.Store(int32(len(partition)))
+
+				for _, desc := range partition {
+					addInstanceTracker(instances, desc, &partitionTrackers[p], i, expectedTrackers)
+				}
+			}
+			continue
+		}
+
 		itemTrackers[i].minSuccess = len(replicationSet.Instances) - replicationSet.MaxErrors
 		itemTrackers[i].maxFailures = replicationSet.MaxErrors
 		itemTrackers[i].remaining.Store(int32(len(replicationSet.Instances)))
 
 		for _, desc := range replicationSet.Instances {
-			curr, found := instances[desc.Addr]
-			if !found {
-				curr.itemTrackers = make([]*itemTracker, 0, expectedTrackers)
-				curr.indexes = make([]int, 0, expectedTrackers)
-			}
-			instances[desc.Addr] = instance{
-				desc:         desc,
-				itemTrackers: append(curr.itemTrackers, &itemTrackers[i]),
-				indexes:      append(curr.indexes, i),
-			}
+			addInstanceTracker(instances, desc, &itemTrackers[i], i, expectedTrackers)
 		}
 	}
 
@@ -118,7 +129,7 @@ func DoBatch(ctx context.Context, op Operation, r ReadRing, e util.AsyncExecutor
 		done: make(chan struct{}, 1),
 		err:  make(chan error, 1),
 	}
-	tracker.rpcsPending.Store(int32(len(itemTrackers)))
+	tracker.rpcsPending.Store(int32(pendingTrackers))
 
 	var wg sync.WaitGroup
 
@@ -148,6 +159,20 @@ func DoBatch(ctx context.Context, op Operation, r ReadRing, e util.AsyncExecutor
 	}
 }
 
+// addInstanceTracker adds the item tracker of the key at the given index to the instance.
+func addInstanceTracker(instances map[string]instance, desc InstanceDesc, tracker *itemTracker, index, expectedTrackers int) {
+	curr, found := instances[desc.Addr]
+	if !found {
+		curr.itemTrackers = make([]*itemTracker, 0, expectedTrackers)
+		curr.indexes = make([]int, 0, expectedTrackers)
+	}
+	instances[desc.Addr] = instance{
+		desc:         desc,
+		itemTrackers: append(curr.itemTrackers, tracker),
+		indexes:      append(curr.indexes, index),
+	}
+}
+
 func (b *batchTracker) record(instance instance, err error) {
 	// If we reach the required number of successful puts on this sample, then decrement the
 	// number of pending samples by one.
@@ -187,8 +212,11 @@ func (b *batchTracker) record(instance instance, err error) {
 		} else {
 			// If we successfully push all samples to min success instances,
 			// wake up the waiting rpc so it can return early.
-			if sampleTrackers[i].succeeded.Inc() >= int32(sampleTrackers[i].minSuccess) {
-				if b.rpcsPending.Dec() == 0 {
+			if succeeded := sampleTrackers[i].succeeded.Inc(); succeeded >= int32(sampleTrackers[i].minSuccess) {
+				// Only the success reaching the quorum completes the item: counting the following
+				// successes of the same item too would complete the batch while other items haven't
+				// reached their quorum yet.
+				if succeeded == int32(sampleTrackers[i].minSuccess) && b.rpcsPending.Dec() == 0 {
 					b.done <- struct{}{}
 				}
 				continue
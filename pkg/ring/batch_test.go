@@ -2,10 +2,13 @@ package ring
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/atomic"
 )
 
@@ -71,6 +74,17 @@ func (m *mockReadRing) ShuffleShardWithLookback(_ string, _ int, _ time.Duration
 func (m *mockReadRing) HasInstance(_ string) bool         { return true }
 func (m *mockReadRing) CleanupShuffleShardCache(_ string) {}
 
+// replicationSetsReadRing is a ReadRing implementation for testing DoBatch, returning the
+// configured replication set of each key.
+type replicationSetsReadRing struct {
+	mockReadRing
+	sets map[uint32]ReplicationSet
+}
+
+func (m *replicationSetsReadRing) Get(key uint32, _ Operation, _ []InstanceDesc, _ []string, _ map[string]int) (ReplicationSet, error) {
+	return m.sets[key], nil
+}
+
 func TestDoBatchCleanupCalledOnCallbackPanic(t *testing.T) {
 	ring := &mockReadRing{
 		inst: InstanceDesc{
@@ -104,3 +118,85 @@ func TestDoBatchCleanupCalledOnCallbackPanic(t *testing.T) {
 		return cleanupCalled.Load()
 	}, 5*time.Second, 10*time.Millisecond, "cleanup must be called even when callback panics")
 }
+
+func TestDoBatch_ShouldWaitForTheQuorumOfEachKey(t *testing.T) {
+	instances := func(addrs ...string) []InstanceDesc {
+		descs := make([]InstanceDesc, 0, len(addrs))
+		for _, addr := range addrs {
+			descs = append(descs, InstanceDesc{Addr: addr, State: ACTIVE, Timestamp: time.Now().Unix()})
+		}
+		return descs
+	}
+
+	// The keys are replicated to different instances.
+	r := &replicationSetsReadRing{sets: map[uint32]ReplicationSet{
+		1: {Instances: instances("1-a", "1-b", "1-c"), MaxErrors: 1},
+		2: {Instances: instances("2-a", "2-b", "2-c"), MaxErrors: 1},
+	}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// All the instances of the key 1 succeed, while the instances of the key 2 never answer:
+	// the successes of the key 1 beyond its quorum must not complete the batch.
+	callback := func(desc InstanceDesc, _ []int) error {
+		if strings.HasPrefix(desc.Addr, "2-") {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	}
+
+	err := DoBatch(ctx, Write, r, nil, []uint32{1, 2}, callback, func() {})
+	require.Error(t, err)
+}
+
+func TestDoBatch_PartitionRing(t *testing.T) {
+	desc := newTestPartitionRingDesc(map[string]uint32{"1": 100, "2": 200, "3": 300}, "zone-a", "zone-b", "zone-c")
+	require.NoError(t, desc.SetPartitionState("2", PARTITION_NON_READY))
+	r := newTestPartitionRing(t, desc)
+
+	// The key is read from the partition 2, which isn't ACTIVE, and from the ACTIVE partition 3.
+	tests := map[string]struct {
+		failing     []string
+		expectedErr bool
+	}{
+		"all instances succeed": {},
+		"a single instance of the partition which isn't ACTIVE succeeds": {
+			failing: []string{"ingester-zone-a-2", "ingester-zone-b-2"},
+		},
+		"all instances of the partition which isn't ACTIVE fail": {
+			failing:     []string{"ingester-zone-a-2", "ingester-zone-b-2", "ingester-zone-c-2"},
+			expectedErr: true,
+		},
+		"an instance of the ACTIVE partition fails": {
+			failing: []string{"ingester-zone-a-3"},
+		},
+		"the quorum of the ACTIVE partition isn't reached": {
+			failing:     []string{"ingester-zone-a-3", "ingester-zone-b-3"},
+			expectedErr: true,
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			callback := func(desc InstanceDesc, _ []int) error {
+				id, err := r.GetInstanceIdByAddr(desc.Addr)
+				require.NoError(t, err)
+				for _, failing := range testData.failing {
+					if id == failing {
+						return errors.New("failed")
+					}
+				}
+				return nil
+			}
+
+			err := DoBatch(context.Background(), Read, r, nil, []uint32{150}, callback, func() {})
+			if testData.expectedErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -1,11 +1,22 @@
 package ring
 
+import (
+	"context"
+	"errors"
+)
+
+// ErrTransferDisabled is the error returned by TransferOut when the transfers are disabled.
+var ErrTransferDisabled = errors.New("transfers disabled")
+
 // FlushTransferer controls the shutdown of an instance in the ring.
 // Methods on this interface are called when lifecycler is stopping.
 // At that point, it no longer runs the "actor loop", but it keeps updating heartbeat in the ring.
 // Ring entry is in LEAVING state.
+// When the instance is required to flush on shutdown, TransferOut is called first and Flush
+// is only called if the transfer failed or is disabled.
 type FlushTransferer interface {
 	Flush()
+	TransferOut(ctx context.Context) error
 }
 
 // NoopFlushTransferer is a FlushTransferer which does nothing and can
@@ -19,3 +30,8 @@ func NewNoopFlushTransferer() *NoopFlushTransferer {
 
 // Flush is a noop
 func (t *NoopFlushTransferer) Flush() {}
+
+// TransferOut is a noop
+func (t *NoopFlushTransferer) TransferOut(_ context.Context) error {
+	return ErrTransferDisabled
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/cortexproject/cortex/pkg/ring/kv/dynamodb"
 	"github.com/cortexproject/cortex/pkg/ring/kv/etcd"
 	"github.com/cortexproject/cortex/pkg/ring/kv/memberlist"
+	"github.com/cortexproject/cortex/pkg/ring/kv/raft"
 )
 
 const (
@@ -39,7 +40,7 @@ var inmemoryStoreInit sync.Once
 var inmemoryStore Client
 
 // StoreConfig is a configuration used for building single store client, either
-// Consul, DynamoDB, Etcd, Memberlist or MultiClient. It was extracted from Config to keep
+// Consul, DynamoDB, Etcd, Memberlist, Raft or MultiClient. It was extracted from Config to keep
 // single-client config separate from final client-config (with all the wrappers)
 type StoreConfig struct {
 	Consul   consul.Config   `yaml:"consul"`
@@ -50,6 +51,10 @@ type StoreConfig struct {
 	// Function that returns memberlist.KV store to use. By using a function, we can delay
 	// initialization of memberlist.KV until it is actually required.
 	MemberlistKV func() (*memberlist.KV, error) `yaml:"-"`
+
+	// Function that returns raft.KV store to use, delaying its initialization
+	// until it is actually required, like MemberlistKV.
+	RaftKV func() (*raft.KV, error) `yaml:"-"`
 }
 
 // Config is config for a KVStore currently used by ring and HA tracker,
@@ -81,7 +86,7 @@ func (cfg *Config) RegisterFlagsWithPrefix(flagsPrefix, defaultPrefix string, f
 		flagsPrefix = "ring."
 	}
 	f.StringVar(&cfg.Prefix, flagsPrefix+"prefix", defaultPrefix, "The prefix for the keys in the store. Should end with a /.")
-	f.StringVar(&cfg.Store, flagsPrefix+"store", "consul", "Backend storage to use for the ring. Supported values are: consul, dynamodb, etcd, inmemory, memberlist, multi.")
+	f.StringVar(&cfg.Store, flagsPrefix+"store", "consul", "Backend storage to use for the ring. Supported values are: consul, dynamodb, etcd, inmemory, memberlist, multi, raft.")
 }
 
 // Client is a high-level client for key-value stores (such as Etcd and
@@ -162,6 +167,13 @@ func createClient(backend string, prefix string, cfg StoreConfig, codec codec.Co
 			return nil, err
 		}
 
+	case "raft":
+		kv, err := cfg.RaftKV()
+		if err != nil {
+			return nil, err
+		}
+		client = raft.NewClient(kv, codec)
+
 	case "multi":
 		client, err = buildMultiClient(cfg, codec, reg, logger)
 
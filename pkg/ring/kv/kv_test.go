@@ -15,6 +15,7 @@ import (
 	"github.com/cortexproject/cortex/pkg/ring/kv/codec"
 	"github.com/cortexproject/cortex/pkg/ring/kv/consul"
 	"github.com/cortexproject/cortex/pkg/ring/kv/etcd"
+	"github.com/cortexproject/cortex/pkg/ring/kv/raft"
 )
 
 func withFixtures(t *testing.T, f func(*testing.T, Client)) {
@@ -32,6 +33,9 @@ func withFixtures(t *testing.T, f func(*testing.T, Client)) {
 			client, closer := etcd.NewInMemoryClient(codec.String{}, testLogger{})
 			return client, closer, nil
 		}},
+		{"raft", func() (Client, io.Closer, error) {
+			return raft.NewSingleNodeClient(codec.String{}, testLogger{})
+		}},
 	} {
 		t.Run(fixture.name, func(t *testing.T) {
 			client, closer, err := fixture.factory()
@@ -3,9 +3,12 @@ package memberlist
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
+	"net"
 	"net/http"
+	"net/url"
 	"sort"
 	"strconv"
 	"strings"
@@ -107,6 +110,10 @@ func (kvs *KVInitService) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		viewKeyParam        = "viewKey"
 		viewMsgParam        = "viewMsg"
 		deleteMessagesParam = "deleteMessages"
+		tombstonesParam     = "tombstones"
+		historyParam        = "history"
+		diffWithParam       = "diffWith"
+		ignoreFieldParam    = "ignoreField"
 	)
 
 	if err := req.ParseForm(); err == nil {
@@ -140,6 +147,31 @@ func (kvs *KVInitService) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 			return
 		}
 
+		if len(req.Form[dumpParam]) > 0 && req.Form[dumpParam][0] == "true" {
+			dump, err := kv.dumpState()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			writeJSON(w, dump)
+			return
+		}
+
+		if len(req.Form[tombstonesParam]) > 0 && req.Form[tombstonesParam][0] == "true" {
+			writeJSON(w, kv.tombstones(time.Now()))
+			return
+		}
+
+		if req.Form[historyParam] != nil {
+			writeJSON(w, kv.getKeyUpdates(req.Form[historyParam][0]))
+			return
+		}
+
+		if req.Form[diffWithParam] != nil {
+			diffWithPeer(w, req, kv, req.Form[diffWithParam][0], req.Form[ignoreFieldParam])
+			return
+		}
+
 		if len(req.Form[deleteMessagesParam]) > 0 && req.Form[deleteMessagesParam][0] == "true" {
 			kv.deleteSentReceivedMessages()
 
@@ -164,6 +196,7 @@ func (kvs *KVInitService) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		Store:            kv.storeCopy(),
 		SentMessages:     sent,
 		ReceivedMessages: received,
+		Tombstones:       kv.tombstones(time.Now()),
 	}
 
 	accept := req.Header.Get("Accept")
@@ -189,6 +222,79 @@ func (kvs *KVInitService) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// dumpParam is the parameter of the status page used to get the decoded state of the KV store.
+const dumpParam = "dump"
+
+// peerRequestTimeout is the timeout for fetching the state of a peer when comparing it with the local state.
+const peerRequestTimeout = 10 * time.Second
+
+func writeJSON(w http.ResponseWriter, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	// Ignore errors, we cannot do anything about them.
+	_, _ = w.Write(data)
+}
+
+func diffWithPeer(w http.ResponseWriter, req *http.Request, kv *KV, peer string, ignoreFields []string) {
+	peerURL, err := peerStatusPageURL(kv, req, peer)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	local, err := kv.dumpState()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), peerRequestTimeout)
+	defer cancel()
+
+	remote, err := FetchStateDump(ctx, http.DefaultClient, peerURL)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to fetch state of %s: %v", peer, err), http.StatusBadGateway)
+		return
+	}
+
+	diff, err := DiffStates(local, remote, ignoreFields)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, diff)
+}
+
+// peerStatusPageURL returns the URL of the status page of the memberlist member with the given name.
+// Only members of the cluster can be compared with, and they are expected to serve the status page
+// on the same HTTP port, scheme and path as this node, as configured in the KV config.
+func peerStatusPageURL(kv *KV, req *http.Request, peer string) (string, error) {
+	if kv.cfg.StatusPageHTTPPort <= 0 {
+		return "", errors.New("the HTTP port of the status page is not configured")
+	}
+
+	scheme := kv.cfg.StatusPageHTTPScheme
+	if scheme == "" {
+		scheme = "http"
+	}
+
+	for _, n := range kv.memberlist.Members() {
+		if n.Name != peer {
+			continue
+		}
+
+		host := net.JoinHostPort(n.Addr.String(), strconv.Itoa(kv.cfg.StatusPageHTTPPort))
+		return (&url.URL{Scheme: scheme, Host: host, Path: req.URL.Path}).String(), nil
+	}
+
+	return "", fmt.Errorf("%q is not a member of the memberlist cluster", peer)
+}
+
 func getFormat(req *http.Request) string {
 	const viewFormat = "format"
 
@@ -283,6 +389,7 @@ type pageData struct {
 	Store            map[string]valueDesc
 	SentMessages     []message
 	ReceivedMessages []message
+	Tombstones       []Tombstone
 }
 
 var pageTemplate = template.Must(template.New("webpage").Funcs(template.FuncMap{
@@ -326,6 +433,7 @@ const pageContent = `
 						| <a href="?viewKey={{ $k }}&format=json-pretty">json-pretty</a>
 						| <a href="?viewKey={{ $k }}&format=struct">struct</a>
 						| <a href="?downloadKey={{ $k }}">download</a>
+						| <a href="?history={{ $k }}">history</a>
 					</td>
 				</tr>
 				{{ end }}
@@ -334,6 +442,32 @@ const pageContent = `
 
 		<p>Note that value "version" is node-specific. It starts with 0 (on restart), and increases on each received update. Size is in bytes.</p>
 
+		<p><a href="?dump=true">Dump decoded state of all keys (JSON)</a></p>
+
+		<h2>Tombstones</h2>
+
+		<table width="100%" border="1">
+			<thead>
+				<tr>
+					<th>Key</th>
+					<th>Entry</th>
+					<th>Deleted At</th>
+					<th>Age</th>
+				</tr>
+			</thead>
+
+			<tbody>
+				{{ range .Tombstones }}
+				<tr>
+					<td>{{ .Key }}</td>
+					<td>{{ if .Entry }}{{ .Entry }}{{ else }}(whole key){{ end }}</td>
+					<td>{{ .DeletedAt }}</td>
+					<td>{{ .Age }}</td>
+				</tr>
+				{{ end }}
+			</tbody>
+		</table>
+
 		<h2>Memberlist Cluster Members</h2>
 
 		<table width="100%" border="1">
@@ -342,6 +476,7 @@ const pageContent = `
 					<th>Name</th>
 					<th>Address</th>
 					<th>State</th>
+					<th>Actions</th>
 				</tr>
 			</thead>
 
@@ -351,12 +486,13 @@ const pageContent = `
 					<td>{{ .Name }}</td>
 					<td>{{ .Address }}</td>
 					<td>{{ .State }}</td>
+					<td><a href="?diffWith={{ .Name }}">diff with local state</a></td>
 				</tr>
 				{{ end }}
 			</tbody>
 		</table>
 
-		<p>State: 0 = Alive, 1 = Suspect, 2 = Dead, 3 = Left</p>
+		<p>State: 0 = Alive, 1 = Suspect, 2 = Dead, 3 = Left. Comparing with a member requires it to serve this page on the same HTTP port as this node.</p>
 
 		<h2>Received Messages</h2>
 
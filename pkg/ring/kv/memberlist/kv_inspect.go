@@ -0,0 +1,299 @@
+package memberlist
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+type keyUpdateSource string
+
+const (
+	keyUpdateSourceCAS      keyUpdateSource = "cas"
+	keyUpdateSourceDelete   keyUpdateSource = "delete"
+	keyUpdateSourceGossip   keyUpdateSource = "gossip"
+	keyUpdateSourcePushPull keyUpdateSource = "push-pull"
+)
+
+// KeyUpdate describes a single update of a key in the KV store, as applied by this node.
+type KeyUpdate struct {
+	Time    time.Time       `json:"time"`
+	Version uint            `json:"version"`
+	Source  keyUpdateSource `json:"source"`
+	Deleted bool            `json:"deleted"`
+	Changes []string        `json:"changes,omitempty"`
+}
+
+// StateDump is the decoded content of the KV store of a single node.
+type StateDump struct {
+	Node string              `json:"node"`
+	Time time.Time           `json:"time"`
+	Keys map[string]KeyState `json:"keys"`
+}
+
+// KeyState is the state of a single key in the StateDump.
+type KeyState struct {
+	Codec string `json:"codec"`
+
+	// Version is node-specific, and not compared between nodes.
+	Version   uint            `json:"version"`
+	Deleted   bool            `json:"deleted"`
+	UpdatedAt time.Time       `json:"updated_at"`
+	Value     json.RawMessage `json:"value"`
+}
+
+// Tombstone is a deleted key, or an entry removed from a value which is still kept as a tombstone.
+type Tombstone struct {
+	Key string `json:"key"`
+	// Entry is empty when the whole key has been deleted.
+	Entry     string    `json:"entry,omitempty"`
+	DeletedAt time.Time `json:"deleted_at"`
+	Age       string    `json:"age"`
+}
+
+// DifferenceKind describes how a key differs between two nodes.
+type DifferenceKind string
+
+const (
+	DifferenceOnlyLocal DifferenceKind = "only_local"
+	DifferenceOnlyPeer  DifferenceKind = "only_peer"
+	DifferenceDeleted   DifferenceKind = "deleted"
+	DifferenceValue     DifferenceKind = "value"
+)
+
+// Difference is a single difference between the state of the KV store on two nodes.
+type Difference struct {
+	Key  string         `json:"key"`
+	Kind DifferenceKind `json:"kind"`
+
+	// Path within the decoded value, with fields separated by dots. Only set for DifferenceValue.
+	Path  string `json:"path,omitempty"`
+	Local any    `json:"local"`
+	Peer  any    `json:"peer"`
+}
+
+// StateDiff is the result of comparing the state of the KV store on two nodes.
+type StateDiff struct {
+	LocalNode   string       `json:"local_node"`
+	PeerNode    string       `json:"peer_node"`
+	Differences []Difference `json:"differences"`
+}
+
+// addKeyUpdate must be called with storeMu held.
+func (m *KV) addKeyUpdate(key string, update KeyUpdate) {
+	if m.cfg.KeyUpdateHistorySize <= 0 {
+		return
+	}
+
+	updates := append(m.keyUpdates[key], update)
+	if len(updates) > m.cfg.KeyUpdateHistorySize {
+		updates = slices.Clone(updates[len(updates)-m.cfg.KeyUpdateHistorySize:])
+	}
+	m.keyUpdates[key] = updates
+}
+
+// getKeyUpdates returns the recorded updates of the key, oldest first.
+func (m *KV) getKeyUpdates(key string) []KeyUpdate {
+	m.storeMu.Lock()
+	defer m.storeMu.Unlock()
+
+	return slices.Clone(m.keyUpdates[key])
+}
+
+func mergeContent(change Mergeable) []string {
+	if change == nil {
+		return nil
+	}
+	return change.MergeContent()
+}
+
+// dumpState returns the decoded content of all keys in the store.
+func (m *KV) dumpState() (*StateDump, error) {
+	dump := &StateDump{
+		Node: m.memberlist.LocalNode().Name,
+		Time: time.Now(),
+		Keys: map[string]KeyState{},
+	}
+
+	for key, desc := range m.storeCopy() {
+		value, err := json.Marshal(desc.value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode value of key %s: %w", key, err)
+		}
+
+		dump.Keys[key] = KeyState{
+			Codec:     desc.codecID,
+			Version:   desc.version,
+			Deleted:   desc.deleted,
+			UpdatedAt: desc.updatedAt,
+			Value:     value,
+		}
+	}
+	return dump, nil
+}
+
+// tombstones returns deleted keys and tombstones kept in the values, sorted by key and entry.
+func (m *KV) tombstones(now time.Time) []Tombstone {
+	var result []Tombstone
+	add := func(key, entry string, deletedAt time.Time) {
+		result = append(result, Tombstone{
+			Key:       key,
+			Entry:     entry,
+			DeletedAt: deletedAt,
+			Age:       now.Sub(deletedAt).Truncate(time.Second).String(),
+		})
+	}
+
+	for key, desc := range m.storeCopy() {
+		if desc.deleted {
+			add(key, "", desc.updatedAt)
+		}
+
+		if lister, ok := desc.value.(TombstonesLister); ok {
+			for entry, deletedAt := range lister.Tombstones() {
+				add(key, entry, deletedAt)
+			}
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Key != result[j].Key {
+			return result[i].Key < result[j].Key
+		}
+		return result[i].Entry < result[j].Entry
+	})
+	return result
+}
+
+// FetchStateDump fetches the state of the KV store from the memberlist status page at the given URL.
+func FetchStateDump(ctx context.Context, client *http.Client, statusPageURL string) (*StateDump, error) {
+	u, err := url.Parse(statusPageURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %q: %w", statusPageURL, err)
+	}
+
+	query := u.Query()
+	query.Set(dumpParam, "true")
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("unexpected status code %d from %s: %s", resp.StatusCode, u.Redacted(), strings.TrimSpace(string(body)))
+	}
+
+	dump := &StateDump{}
+	if err := json.NewDecoder(resp.Body).Decode(dump); err != nil {
+		return nil, fmt.Errorf("failed to decode state from %s: %w", u.Redacted(), err)
+	}
+	return dump, nil
+}
+
+// DiffStates compares the state of the KV store on two nodes. Fields of decoded values named
+// as one of ignoreFields are skipped, which is useful for fields like heartbeat timestamps that
+// are expected to differ between nodes. The version of the keys is node-specific, and is not compared.
+func DiffStates(local, peer *StateDump, ignoreFields []string) (*StateDiff, error) {
+	diff := &StateDiff{
+		LocalNode:   local.Node,
+		PeerNode:    peer.Node,
+		Differences: []Difference{},
+	}
+
+	keys := make([]string, 0, len(local.Keys)+len(peer.Keys))
+	for k := range local.Keys {
+		keys = append(keys, k)
+	}
+	for k := range peer.Keys {
+		if _, ok := local.Keys[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		l, inLocal := local.Keys[key]
+		p, inPeer := peer.Keys[key]
+
+		var localValue, peerValue any
+		if inLocal {
+			if err := json.Unmarshal(l.Value, &localValue); err != nil {
+				return nil, fmt.Errorf("failed to decode local value of key %s: %w", key, err)
+			}
+		}
+		if inPeer {
+			if err := json.Unmarshal(p.Value, &peerValue); err != nil {
+				return nil, fmt.Errorf("failed to decode peer value of key %s: %w", key, err)
+			}
+		}
+
+		switch {
+		case !inPeer:
+			diff.Differences = append(diff.Differences, Difference{Key: key, Kind: DifferenceOnlyLocal, Local: localValue})
+		case !inLocal:
+			diff.Differences = append(diff.Differences, Difference{Key: key, Kind: DifferenceOnlyPeer, Peer: peerValue})
+		default:
+			if l.Deleted != p.Deleted {
+				diff.Differences = append(diff.Differences, Difference{Key: key, Kind: DifferenceDeleted, Local: l.Deleted, Peer: p.Deleted})
+			}
+			diff.Differences = diffValues(diff.Differences, key, "", localValue, peerValue, ignoreFields)
+		}
+	}
+	return diff, nil
+}
+
+// diffValues recursively compares JSON-decoded values. Objects are compared field by field,
+// while any other values (including arrays) are reported as a whole when they differ.
+func diffValues(diffs []Difference, key, path string, local, peer any, ignoreFields []string) []Difference {
+	localObj, localIsObj := local.(map[string]any)
+	peerObj, peerIsObj := peer.(map[string]any)
+
+	if !localIsObj || !peerIsObj {
+		if !reflect.DeepEqual(local, peer) {
+			diffs = append(diffs, Difference{Key: key, Kind: DifferenceValue, Path: path, Local: local, Peer: peer})
+		}
+		return diffs
+	}
+
+	fields := make([]string, 0, len(localObj)+len(peerObj))
+	for f := range localObj {
+		fields = append(fields, f)
+	}
+	for f := range peerObj {
+		if _, ok := localObj[f]; !ok {
+			fields = append(fields, f)
+		}
+	}
+	sort.Strings(fields)
+
+	for _, f := range fields {
+		if slices.Contains(ignoreFields, f) {
+			continue
+		}
+
+		fieldPath := f
+		if path != "" {
+			fieldPath = path + "." + f
+		}
+		diffs = diffValues(diffs, key, fieldPath, localObj[f], peerObj[f], ignoreFields)
+	}
+	return diffs
+}
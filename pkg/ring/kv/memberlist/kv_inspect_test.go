@@ -0,0 +1,228 @@
+package memberlist
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexproject/cortex/pkg/ring/kv/codec"
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+	"github.com/cortexproject/cortex/pkg/util/services"
+)
+
+func TestKV_ShouldRecordKeyUpdateHistory(t *testing.T) {
+	c := dataCodec{}
+
+	var cfg KVConfig
+	flagext.DefaultValues(&cfg)
+	cfg.TCPTransport = TCPTransportConfig{}
+	cfg.Codecs = []codec.Codec{c}
+	cfg.KeyUpdateHistorySize = 3
+
+	mkv := NewKV(cfg, log.NewNopLogger(), &dnsProviderMock{}, prometheus.NewPedanticRegistry())
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), mkv))
+	defer services.StopAndAwaitTerminated(context.Background(), mkv) //nolint:errcheck
+
+	client, err := NewClient(mkv, c)
+	require.NoError(t, err)
+
+	now := time.Now()
+	require.NoError(t, client.CAS(context.Background(), key, func(in any) (out any, retry bool, err error) {
+		d := getOrCreateData(in)
+		d.Members["a"] = member{Timestamp: now.Unix(), State: JOINING}
+		return d, true, nil
+	}))
+
+	mkv.NotifyMsg(marshalKeyValuePair(t, key, c, &data{Members: map[string]member{
+		"b": {Timestamp: now.Unix(), State: ACTIVE},
+	}}, false, 0))
+
+	// Message which doesn't change anything isn't recorded.
+	mkv.NotifyMsg(marshalKeyValuePair(t, key, c, &data{Members: map[string]member{
+		"b": {Timestamp: now.Unix(), State: ACTIVE},
+	}}, false, 0))
+
+	require.NoError(t, client.Delete(context.Background(), key))
+
+	updates := mkv.getKeyUpdates(key)
+	require.Len(t, updates, 3)
+	assert.Equal(t, keyUpdateSourceCAS, updates[0].Source)
+	assert.Equal(t, []string{"a"}, updates[0].Changes)
+	assert.Equal(t, keyUpdateSourceGossip, updates[1].Source)
+	assert.Equal(t, []string{"b"}, updates[1].Changes)
+	assert.Equal(t, keyUpdateSourceDelete, updates[2].Source)
+	assert.True(t, updates[2].Deleted)
+	assert.Equal(t, []uint{1, 2, 3}, []uint{updates[0].Version, updates[1].Version, updates[2].Version})
+
+	// Only the latest updates are kept.
+	mkv.NotifyMsg(marshalKeyValuePair(t, key, c, &data{Members: map[string]member{
+		"c": {Timestamp: now.Unix(), State: ACTIVE},
+	}}, false, 0))
+
+	updates = mkv.getKeyUpdates(key)
+	require.Len(t, updates, 3)
+	assert.Equal(t, keyUpdateSourceGossip, updates[0].Source)
+	assert.Equal(t, []string{"c"}, updates[2].Changes)
+}
+
+func TestKV_ShouldListTombstones(t *testing.T) {
+	c := dataCodec{}
+
+	var cfg KVConfig
+	flagext.DefaultValues(&cfg)
+	cfg.TCPTransport = TCPTransportConfig{}
+	cfg.Codecs = []codec.Codec{c}
+
+	mkv := NewKV(cfg, log.NewNopLogger(), &dnsProviderMock{}, prometheus.NewPedanticRegistry())
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), mkv))
+	defer services.StopAndAwaitTerminated(context.Background(), mkv) //nolint:errcheck
+
+	client, err := NewClient(mkv, c)
+	require.NoError(t, err)
+
+	now := time.Now()
+	left := now.Add(-time.Minute)
+	require.NoError(t, client.CAS(context.Background(), "ring", func(in any) (out any, retry bool, err error) {
+		d := getOrCreateData(in)
+		d.Members["a"] = member{Timestamp: now.Unix(), State: ACTIVE}
+		d.Members["b"] = member{Timestamp: left.Unix(), State: LEFT}
+		return d, true, nil
+	}))
+	require.NoError(t, client.CAS(context.Background(), "deleted", func(in any) (out any, retry bool, err error) {
+		d := getOrCreateData(in)
+		d.Members["a"] = member{Timestamp: now.Unix(), State: ACTIVE}
+		return d, true, nil
+	}))
+	require.NoError(t, client.Delete(context.Background(), "deleted"))
+
+	tombstones := mkv.tombstones(now.Add(time.Hour))
+	require.Len(t, tombstones, 2)
+
+	assert.Equal(t, "deleted", tombstones[0].Key)
+	assert.Equal(t, "", tombstones[0].Entry)
+
+	assert.Equal(t, "ring", tombstones[1].Key)
+	assert.Equal(t, "b", tombstones[1].Entry)
+	assert.Equal(t, left.Unix(), tombstones[1].DeletedAt.Unix())
+	assert.Equal(t, now.Add(time.Hour).Sub(time.Unix(left.Unix(), 0)).Truncate(time.Second).String(), tombstones[1].Age)
+}
+
+func TestKVInitService_ShouldServeStateDumpUsedForDiffing(t *testing.T) {
+	c := dataCodec{}
+
+	var cfg KVConfig
+	flagext.DefaultValues(&cfg)
+	cfg.TCPTransport = TCPTransportConfig{}
+	cfg.Codecs = []codec.Codec{c}
+	cfg.NodeName = "local"
+	cfg.RandomizeNodeName = false
+
+	kvinit := NewKVInitService(&cfg, log.NewNopLogger(), &dnsProviderMock{}, prometheus.NewPedanticRegistry())
+	mkv, err := kvinit.GetMemberlistKV()
+	require.NoError(t, err)
+	require.NoError(t, mkv.AwaitRunning(context.Background()))
+	defer services.StopAndAwaitTerminated(context.Background(), mkv) //nolint:errcheck
+
+	client, err := NewClient(mkv, c)
+	require.NoError(t, err)
+	require.NoError(t, client.CAS(context.Background(), "ring", func(in any) (out any, retry bool, err error) {
+		d := getOrCreateData(in)
+		d.Members["a"] = member{Timestamp: 10, State: ACTIVE}
+		d.Members["b"] = member{Timestamp: 10, State: JOINING, Tokens: []uint32{1, 2}}
+		return d, true, nil
+	}))
+
+	server := httptest.NewServer(kvinit)
+	defer server.Close()
+
+	local, err := FetchStateDump(context.Background(), http.DefaultClient, server.URL+"/memberlist")
+	require.NoError(t, err)
+	assert.Equal(t, "local", local.Node)
+	require.Contains(t, local.Keys, "ring")
+	assert.Equal(t, c.CodecID(), local.Keys["ring"].Codec)
+	assert.Equal(t, uint(1), local.Keys["ring"].Version)
+
+	peer := &StateDump{
+		Node: "peer",
+		Keys: map[string]KeyState{
+			"ring":  {Value: json.RawMessage(`{"Members":{"a":{"Timestamp":20,"Tokens":[],"State":1},"b":{"Timestamp":10,"Tokens":[1,3],"State":1}}}`)},
+			"other": {Value: json.RawMessage(`"value"`), Deleted: true},
+		},
+	}
+
+	diff, err := DiffStates(local, peer, nil)
+	require.NoError(t, err)
+	assert.Equal(t, &StateDiff{
+		LocalNode: "local",
+		PeerNode:  "peer",
+		Differences: []Difference{
+			{Key: "other", Kind: DifferenceOnlyPeer, Peer: "value"},
+			{Key: "ring", Kind: DifferenceValue, Path: "Members.a.Timestamp", Local: float64(10), Peer: float64(20)},
+			{Key: "ring", Kind: DifferenceValue, Path: "Members.b.State", Local: float64(JOINING), Peer: float64(ACTIVE)},
+			{Key: "ring", Kind: DifferenceValue, Path: "Members.b.Tokens", Local: []any{float64(1), float64(2)}, Peer: []any{float64(1), float64(3)}},
+		},
+	}, diff)
+
+	diff, err = DiffStates(local, peer, []string{"Timestamp", "Tokens"})
+	require.NoError(t, err)
+	assert.Len(t, diff.Differences, 2)
+
+	// Tombstones and history are served as JSON.
+	resp, err := http.Get(server.URL + "/memberlist?history=ring")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var updates []KeyUpdate
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&updates))
+	require.Len(t, updates, 1)
+	assert.Equal(t, keyUpdateSourceCAS, updates[0].Source)
+
+	// Only members of the cluster can be compared with.
+	resp, err = http.Get(server.URL + "/memberlist?diffWith=unknown")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+}
+
+func TestPeerStatusPageURL_ShouldUseTheConfiguredHTTPPortAndScheme(t *testing.T) {
+	var cfg KVConfig
+	flagext.DefaultValues(&cfg)
+	cfg.TCPTransport = TCPTransportConfig{}
+	cfg.Codecs = []codec.Codec{dataCodec{}}
+	cfg.NodeName = "local"
+	cfg.RandomizeNodeName = false
+
+	mkv := NewKV(cfg, log.NewNopLogger(), &dnsProviderMock{}, prometheus.NewPedanticRegistry())
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), mkv))
+	defer services.StopAndAwaitTerminated(context.Background(), mkv) //nolint:errcheck
+
+	// The port of the Host header is the one seen by the client, eg. of a proxy, and must not be used.
+	req := httptest.NewRequest(http.MethodGet, "http://cortex:8080/memberlist?diffWith=local", nil)
+	addr := mkv.memberlist.LocalNode().Addr.String()
+
+	_, err := peerStatusPageURL(mkv, req, "local")
+	require.Error(t, err)
+
+	mkv.cfg.StatusPageHTTPPort = 9009
+	peerURL, err := peerStatusPageURL(mkv, req, "local")
+	require.NoError(t, err)
+	assert.Equal(t, "http://"+net.JoinHostPort(addr, "9009")+"/memberlist", peerURL)
+
+	mkv.cfg.StatusPageHTTPScheme = "https"
+	peerURL, err = peerStatusPageURL(mkv, req, "local")
+	require.NoError(t, err)
+	assert.Equal(t, "https://"+net.JoinHostPort(addr, "9009")+"/memberlist", peerURL)
+
+	_, err = peerStatusPageURL(mkv, req, "unknown")
+	require.Error(t, err)
+}
@@ -147,6 +147,7 @@ type KVConfig struct {
 	GossipToTheDeadTime time.Duration `yaml:"gossip_to_dead_nodes_time"`
 	DeadNodeReclaimTime time.Duration `yaml:"dead_node_reclaim_time"`
 	EnableCompression   bool          `yaml:"compression_enabled"`
+	MultiKeyEnabled     bool          `yaml:"multikey_enabled"`
 
 	// ip:port to advertise other cluster members. Used for NAT traversal
 	AdvertiseAddr string `yaml:"advertise_addr"`
@@ -175,6 +176,9 @@ type KVConfig struct {
 	// How much space to use to keep received and sent messages in memory (for troubleshooting).
 	MessageHistoryBufferBytes int `yaml:"message_history_buffer_bytes"`
 
+	// How many updates to keep in memory for each key (for troubleshooting).
+	KeyUpdateHistorySize int `yaml:"key_update_history_size"`
+
 	TCPTransport TCPTransportConfig `yaml:",inline"`
 
 	// Where to put custom metrics. Metrics are not registered, if this is nil.
@@ -183,6 +187,11 @@ type KVConfig struct {
 
 	// Codecs to register. Codecs need to be registered before joining other members.
 	Codecs []codec.Codec `yaml:"-"`
+
+	// Port and scheme of the HTTP server serving the status page of all the members, used to compare
+	// the state of this member with a peer.
+	StatusPageHTTPPort   int    `yaml:"-"`
+	StatusPageHTTPScheme string `yaml:"-"`
 }
 
 // RegisterFlagsWithPrefix registers flags.
@@ -194,7 +203,7 @@ func (cfg *KVConfig) RegisterFlagsWithPrefix(f *flag.FlagSet, prefix string) {
 	f.BoolVar(&cfg.RandomizeNodeName, prefix+"memberlist.randomize-node-name", true, "Add random suffix to the node name.")
 	f.DurationVar(&cfg.StreamTimeout, prefix+"memberlist.stream-timeout", mlDefaults.TCPTimeout, "The timeout for establishing a connection with a remote node, and for read/write operations.")
 	f.IntVar(&cfg.RetransmitMult, prefix+"memberlist.retransmit-factor", mlDefaults.RetransmitMult, "Multiplication factor used when sending out messages (factor * log(N+1)).")
-	f.Var(&cfg.JoinMembers, prefix+"memberlist.join", "Other cluster members to join. Can be specified multiple times. It can be an IP, hostname or an entry specified in the DNS Service Discovery format.")
+	f.Var(&cfg.JoinMembers, prefix+"memberlist.join", "Other cluster members to join. Can be specified multiple times. It can be an IP, hostname or an entry specified in the DNS, file or HTTP Service Discovery format.")
 	f.DurationVar(&cfg.MinJoinBackoff, prefix+"memberlist.min-join-backoff", 1*time.Second, "Min backoff duration to join other cluster members.")
 	f.DurationVar(&cfg.MaxJoinBackoff, prefix+"memberlist.max-join-backoff", 1*time.Minute, "Max backoff duration to join other cluster members.")
 	f.IntVar(&cfg.MaxJoinRetries, prefix+"memberlist.max-join-retries", 10, "Max number of retries to join other cluster members.")
@@ -209,7 +218,9 @@ func (cfg *KVConfig) RegisterFlagsWithPrefix(f *flag.FlagSet, prefix string) {
 	f.DurationVar(&cfg.GossipToTheDeadTime, prefix+"memberlist.gossip-to-dead-nodes-time", mlDefaults.GossipToTheDeadTime, "How long to keep gossiping to dead nodes, to give them chance to refute their death.")
 	f.DurationVar(&cfg.DeadNodeReclaimTime, prefix+"memberlist.dead-node-reclaim-time", mlDefaults.DeadNodeReclaimTime, "How soon can dead node's name be reclaimed with new address. 0 to disable.")
 	f.IntVar(&cfg.MessageHistoryBufferBytes, prefix+"memberlist.message-history-buffer-bytes", 0, "How much space to use for keeping received and sent messages in memory for troubleshooting (two buffers). 0 to disable.")
+	f.IntVar(&cfg.KeyUpdateHistorySize, prefix+"memberlist.key-update-history-size", 10, "How many of the latest updates of each key to keep in memory for troubleshooting. The updates are shown on the memberlist status page. 0 to disable.")
 	f.BoolVar(&cfg.EnableCompression, prefix+"memberlist.compression-enabled", mlDefaults.EnableCompression, "Enable message compression. This can be used to reduce bandwidth usage at the cost of slightly more CPU utilization.")
+	f.BoolVar(&cfg.MultiKeyEnabled, prefix+"memberlist.multikey-enabled", false, "[Experimental] Split values supporting multiple keys, like the ring, per item (instance) when gossiping changes and applying CAS updates, so that each change only carries the instances it updates. Nodes with this option disabled still understand the split changes.")
 	f.StringVar(&cfg.AdvertiseAddr, prefix+"memberlist.advertise-addr", mlDefaults.AdvertiseAddr, "Gossip address to advertise to other members in the cluster. Used for NAT traversal.")
 	f.IntVar(&cfg.AdvertisePort, prefix+"memberlist.advertise-port", mlDefaults.AdvertisePort, "Gossip port to advertise to other members in the cluster. Used for NAT traversal.")
 	f.StringVar(&cfg.ClusterLabel, prefix+"memberlist.cluster-label", mlDefaults.Label, "The cluster label is an optional string to include in outbound packets and gossip streams. Other members in the memberlist cluster will discard any message whose label doesn't match the configured one, unless the 'cluster-label-verification-disabled' configuration option is set to true.")
@@ -256,6 +267,9 @@ type KV struct {
 	storeMu sync.Mutex
 	store   map[string]valueDesc
 
+	// Latest updates of each key in the store, protected by storeMu. Used for troubleshooting only.
+	keyUpdates map[string][]KeyUpdate
+
 	// Codec registry
 	codecs map[string]codec.Codec
 
@@ -370,6 +384,7 @@ func NewKV(cfg KVConfig, logger log.Logger, dnsProvider DNSProvider, registerer
 		provider:   dnsProvider,
 
 		store:          make(map[string]valueDesc),
+		keyUpdates:     make(map[string][]KeyUpdate),
 		codecs:         make(map[string]codec.Codec),
 		watchers:       make(map[string][]chan string),
 		prefixWatchers: make(map[string][]chan string),
@@ -547,6 +562,7 @@ func (m *KV) sweepTombstones() {
 	for key, val := range m.store {
 		if val.deleted && now.Sub(val.updatedAt) > m.cfg.TombstoneTimeout {
 			delete(m.store, key)
+			delete(m.keyUpdates, key)
 			sweptCount++
 		}
 	}
@@ -762,7 +778,7 @@ func (m *KV) Delete(key string) error {
 		return fmt.Errorf("invalid codec %s for key %s", val.codecID, key)
 	}
 
-	change, newvar, err := m.mergeValueForKey(key, val.value, 0, codec, true, time.Now())
+	change, newvar, err := m.mergeValueForKey(key, val.value, 0, codec, true, time.Now(), keyUpdateSourceDelete)
 	if err != nil {
 		level.Error(m.logger).Log("msg", "failed to mark for deleted: error ", "key", key, "err", err)
 		return err
@@ -1019,7 +1035,7 @@ func (m *KV) trySingleCas(key string, codec codec.Codec, f func(in any) (out any
 
 	// To support detection of removed items from value, we will only allow CAS operation to
 	// succeed if version hasn't changed, i.e. state hasn't changed since running 'f'.
-	change, newver, err := m.mergeValueForKey(key, r, ver, codec, false, time.Now())
+	change, newver, err := m.mergeValueForKey(key, r, ver, codec, false, time.Now(), keyUpdateSourceCAS)
 	if err == errVersionMismatch {
 		return nil, 0, retry, err
 	}
@@ -1037,6 +1053,19 @@ func (m *KV) trySingleCas(key string, codec codec.Codec, f func(in any) (out any
 }
 
 func (m *KV) broadcastNewValue(key string, change Mergeable, version uint, codec codec.Codec) {
+	if m.cfg.MultiKeyEnabled {
+		if parts := splitMultiKeyValue(change, codec); len(parts) > 1 {
+			for _, part := range parts {
+				m.broadcastValue(key, part, version, codec)
+			}
+			return
+		}
+	}
+
+	m.broadcastValue(key, change, version, codec)
+}
+
+func (m *KV) broadcastValue(key string, change Mergeable, version uint, codec codec.Codec) {
 	data, err := codec.Encode(change)
 	if err != nil {
 		level.Error(m.logger).Log("msg", "failed to encode change", "key", key, "version", version, "err", err)
@@ -1127,7 +1156,7 @@ func (m *KV) NotifyMsg(msg []byte) {
 	}
 
 	// we have a ring update! Let's merge it with our version of the ring for given key
-	mod, version, err := m.mergeBytesValueForKey(kvPair.Key, kvPair.Value, codec, kvPair.Deleted, parsedTime)
+	mod, version, err := m.mergeBytesValueForKey(kvPair.Key, kvPair.Value, codec, kvPair.Deleted, parsedTime, keyUpdateSourceGossip)
 
 	changes := []string(nil)
 	if mod != nil {
@@ -1311,7 +1340,7 @@ func (m *KV) MergeRemoteState(data []byte, join bool) {
 		}
 
 		// we have both key and value, try to merge it with our state
-		change, newver, err := m.mergeBytesValueForKey(kvPair.Key, kvPair.Value, codec, kvPair.Deleted, parsedTime)
+		change, newver, err := m.mergeBytesValueForKey(kvPair.Key, kvPair.Value, codec, kvPair.Deleted, parsedTime, keyUpdateSourcePushPull)
 
 		changes := []string(nil)
 		if change != nil {
@@ -1339,7 +1368,7 @@ func (m *KV) MergeRemoteState(data []byte, join bool) {
 	}
 }
 
-func (m *KV) mergeBytesValueForKey(key string, incomingData []byte, codec codec.Codec, deleted bool, updatedAt time.Time) (Mergeable, uint, error) {
+func (m *KV) mergeBytesValueForKey(key string, incomingData []byte, codec codec.Codec, deleted bool, updatedAt time.Time, source keyUpdateSource) (Mergeable, uint, error) {
 	decodedValue, err := codec.Decode(incomingData)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to decode value: %v", err)
@@ -1350,14 +1379,14 @@ func (m *KV) mergeBytesValueForKey(key string, incomingData []byte, codec codec.
 		return nil, 0, fmt.Errorf("expected Mergeable, got: %T", decodedValue)
 	}
 
-	return m.mergeValueForKey(key, incomingValue, 0, codec, deleted, updatedAt)
+	return m.mergeValueForKey(key, incomingValue, 0, codec, deleted, updatedAt, source)
 }
 
 // Merges incoming value with value we have in our store. Returns "a change" that can be sent to other
 // cluster members to update their state, and new version of the value.
 // If CAS version is specified, then merging will fail if state has changed already, and errVersionMismatch is reported.
-// If no modification occurred, new version is 0.
-func (m *KV) mergeValueForKey(key string, incomingValue Mergeable, casVersion uint, codec codec.Codec, deleted bool, updatedAt time.Time) (Mergeable, uint, error) {
+// If no modification occurred, new version is 0. Source of the update is recorded in the key update history.
+func (m *KV) mergeValueForKey(key string, incomingValue Mergeable, casVersion uint, codec codec.Codec, deleted bool, updatedAt time.Time, source keyUpdateSource) (Mergeable, uint, error) {
 	m.storeMu.Lock()
 	defer m.storeMu.Unlock()
 
@@ -1369,7 +1398,15 @@ func (m *KV) mergeValueForKey(key string, incomingValue Mergeable, casVersion ui
 	if casVersion > 0 && curr.version != casVersion {
 		return nil, 0, errVersionMismatch
 	}
-	result, change, err := computeNewValue(incomingValue, curr.value, casVersion > 0)
+	localCAS := casVersion > 0
+	if localCAS && m.cfg.MultiKeyEnabled {
+		// Only merge the items changed by the CAS function, instead of the whole value.
+		if diff, ok := multiKeyDifference(curr.value, incomingValue); ok {
+			incomingValue, localCAS = diff, false
+		}
+	}
+
+	result, change, err := computeNewValue(incomingValue, curr.value, localCAS)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -1445,6 +1482,14 @@ func (m *KV) mergeValueForKey(key string, incomingValue Mergeable, casVersion ui
 		change = change.Clone().(Mergeable)
 	}
 
+	m.addKeyUpdate(key, KeyUpdate{
+		Time:    time.Now(),
+		Version: newVersion,
+		Source:  source,
+		Deleted: newDeleted,
+		Changes: mergeContent(change),
+	})
+
 	return change, newVersion, nil
 }
 
@@ -103,6 +103,16 @@ func (d *data) RemoveTombstones(limit time.Time) (total, removed int) {
 	return
 }
 
+func (d *data) Tombstones() map[string]time.Time {
+	out := map[string]time.Time{}
+	for n, m := range d.Members {
+		if m.State == LEFT {
+			out[n] = time.Unix(m.Timestamp, 0)
+		}
+	}
+	return out
+}
+
 func (m member) clone() member {
 	out := member{
 		Timestamp: m.Timestamp,
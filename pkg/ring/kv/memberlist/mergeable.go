@@ -52,3 +52,11 @@ type Mergeable interface {
 	// Returns the total number of tombstones present and the number of removed tombstones by this invocation.
 	RemoveTombstones(limit time.Time) (total, removed int)
 }
+
+// TombstonesLister can be implemented by Mergeable values, which keep tombstones of removed entries
+// in the value itself. It's only used for troubleshooting, to show the tombstones on the status page.
+type TombstonesLister interface {
+	// Tombstones returns the tombstones present in the value, keyed by the removed entry, along with
+	// the time when each of them was created.
+	Tombstones() map[string]time.Time
+}
@@ -0,0 +1,69 @@
+package memberlist
+
+import (
+	"sort"
+
+	"github.com/cortexproject/cortex/pkg/ring/kv/codec"
+)
+
+// splitMultiKeyValue splits the value into values holding a single item each, if the value supports
+// multiple keys. Each part is a valid value on its own, so nodes which don't split values can merge them too.
+// Returns nil if the value can't be split.
+func splitMultiKeyValue(value Mergeable, c codec.Codec) []Mergeable {
+	multiKey, ok := value.(codec.MultiKey)
+	if !ok {
+		return nil
+	}
+
+	items := multiKey.SplitByID()
+	ids := make([]string, 0, len(items))
+	for id := range items {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	parts := make([]Mergeable, 0, len(items))
+	for _, id := range ids {
+		// Decoding no data gives an empty value of the codec type.
+		empty, err := c.DecodeMultiKey(nil)
+		if err != nil {
+			return nil
+		}
+
+		part, ok := empty.(codec.MultiKey)
+		if !ok {
+			return nil
+		}
+		part.JoinIds(map[string]any{id: items[id]})
+
+		mergeable, ok := part.(Mergeable)
+		if !ok {
+			return nil
+		}
+		parts = append(parts, mergeable)
+	}
+	return parts
+}
+
+// multiKeyDifference returns the items of the value updated by a CAS function, as a value which can be
+// merged with the current one without the localCAS flag. This avoids merging the whole value on each CAS.
+// Returns false if the values don't support multiple keys, or if the CAS function removed some items, in
+// which case the whole value must be merged with the localCAS flag to create the tombstones.
+func multiKeyDifference(current, updated Mergeable) (Mergeable, bool) {
+	currentMultiKey, ok := current.(codec.MultiKey)
+	if !ok {
+		return nil, false
+	}
+	updatedMultiKey, ok := updated.(codec.MultiKey)
+	if !ok {
+		return nil, false
+	}
+
+	diff, removed, err := currentMultiKey.FindDifference(updatedMultiKey)
+	if err != nil || len(removed) > 0 {
+		return nil, false
+	}
+
+	result, ok := diff.(Mergeable)
+	return result, ok
+}
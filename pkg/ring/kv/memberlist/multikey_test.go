@@ -0,0 +1,168 @@
+package memberlist
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/gogo/protobuf/proto"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexproject/cortex/pkg/ring/kv/codec"
+	"github.com/cortexproject/cortex/pkg/util/services"
+)
+
+func (d *data) SplitByID() map[string]any {
+	out := make(map[string]any, len(d.Members))
+	for k, m := range d.Members {
+		out[k] = m.clone()
+	}
+	return out
+}
+
+func (d *data) JoinIds(in map[string]any) {
+	for k, m := range in {
+		d.Members[k] = m.(member)
+	}
+}
+
+func (d *data) GetItemFactory() proto.Message {
+	return nil
+}
+
+func (d *data) FindDifference(o codec.MultiKey) (any, []string, error) {
+	other, ok := o.(*data)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid type: %T", o)
+	}
+
+	updated := &data{Members: map[string]member{}}
+	var removed []string
+	for k, m := range other.Members {
+		if m.Timestamp > d.Members[k].Timestamp {
+			updated.Members[k] = m
+		}
+	}
+	for k := range d.Members {
+		if _, ok := other.Members[k]; !ok {
+			removed = append(removed, k)
+		}
+	}
+	return updated, removed, nil
+}
+
+// multiKeyDataCodec is a dataCodec which only supports decoding an empty multi-key value.
+type multiKeyDataCodec struct {
+	dataCodec
+}
+
+func (multiKeyDataCodec) DecodeMultiKey(in map[string][]byte) (any, error) {
+	if len(in) > 0 {
+		return nil, fmt.Errorf("multiKeyDataCodec only decodes empty values")
+	}
+	return &data{Members: map[string]member{}}, nil
+}
+
+func newMultiKeyTestKV(t *testing.T, multiKeyEnabled bool) (*KV, *Client) {
+	c := multiKeyDataCodec{}
+
+	cfg := KVConfig{}
+	// We will be checking for number of messages in the broadcast queue, so make sure to use known retransmit factor.
+	cfg.RetransmitMult = 1
+	cfg.MultiKeyEnabled = multiKeyEnabled
+	cfg.Codecs = []codec.Codec{c}
+
+	kv := NewKV(cfg, log.NewNopLogger(), &dnsProviderMock{}, prometheus.NewPedanticRegistry())
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), kv))
+	t.Cleanup(func() {
+		_ = services.StopAndAwaitTerminated(context.Background(), kv)
+	})
+
+	client, err := NewClient(kv, c)
+	require.NoError(t, err)
+	return kv, client
+}
+
+func TestMultiKey_ShouldBroadcastEachItemSeparately(t *testing.T) {
+	now := time.Now()
+	addMembers := func(in any) (out any, retry bool, err error) {
+		d := getOrCreateData(in)
+		for _, name := range []string{"a", "b", "c"} {
+			d.Members[name] = member{Timestamp: now.Unix(), State: ACTIVE, Tokens: []uint32{1, 2, 3}}
+		}
+		return d, true, nil
+	}
+
+	t.Run("multi-key disabled", func(t *testing.T) {
+		kv, client := newMultiKeyTestKV(t, false)
+		require.NoError(t, client.CAS(context.Background(), key, addMembers))
+
+		bs := kv.GetBroadcasts(0, math.MaxInt32)
+		require.Len(t, bs, 1)
+		assert.Len(t, decodeDataFromMarshalledKeyValuePair(t, bs[0], key, dataCodec{}).Members, 3)
+	})
+
+	t.Run("multi-key enabled", func(t *testing.T) {
+		kv, client := newMultiKeyTestKV(t, true)
+		require.NoError(t, client.CAS(context.Background(), key, addMembers))
+
+		bs := kv.GetBroadcasts(0, math.MaxInt32)
+		require.Len(t, bs, 3)
+
+		// Each message is a valid value, so a node which doesn't split values rebuilds the same state.
+		receiver, receiverClient := newMultiKeyTestKV(t, false)
+		received := map[string]bool{}
+		for _, b := range bs {
+			d := decodeDataFromMarshalledKeyValuePair(t, b, key, dataCodec{})
+			require.Len(t, d.Members, 1)
+			for name := range d.Members {
+				received[name] = true
+			}
+			receiver.NotifyMsg(b)
+		}
+		assert.Equal(t, map[string]bool{"a": true, "b": true, "c": true}, received)
+		assert.Equal(t, getData(t, client, key), getData(t, receiverClient, key))
+	})
+}
+
+func TestMultiKey_CASShouldOnlyMergeChangedItems(t *testing.T) {
+	kv, client := newMultiKeyTestKV(t, true)
+
+	now := time.Now()
+	require.NoError(t, client.CAS(context.Background(), key, func(in any) (out any, retry bool, err error) {
+		d := getOrCreateData(in)
+		d.Members["a"] = member{Timestamp: now.Unix(), State: JOINING}
+		d.Members["b"] = member{Timestamp: now.Unix(), State: JOINING}
+		return d, true, nil
+	}))
+	kv.GetBroadcasts(0, math.MaxInt32)
+
+	// Update of a single member only gossips that member.
+	require.NoError(t, client.CAS(context.Background(), key, func(in any) (out any, retry bool, err error) {
+		d := getOrCreateData(in)
+		d.Members["a"] = member{Timestamp: now.Unix() + 1, State: ACTIVE}
+		return d, true, nil
+	}))
+
+	bs := kv.GetBroadcasts(0, math.MaxInt32)
+	require.Len(t, bs, 1)
+	assert.Equal(t, &data{Members: map[string]member{
+		"a": {Timestamp: now.Unix() + 1, State: ACTIVE},
+	}}, decodeDataFromMarshalledKeyValuePair(t, bs[0], key, dataCodec{}))
+
+	// Members removed by the CAS function are still marked as LEFT.
+	require.NoError(t, client.CAS(context.Background(), key, func(in any) (out any, retry bool, err error) {
+		d := getOrCreateData(in)
+		delete(d.Members, "b")
+		return d, true, nil
+	}))
+
+	d := getData(t, client, key)
+	assert.Equal(t, ACTIVE, d.Members["a"].State)
+	assert.Equal(t, LEFT, d.Members["b"].State)
+}
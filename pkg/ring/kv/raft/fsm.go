@@ -0,0 +1,266 @@
+package raft
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/raft"
+)
+
+type commandOp string
+
+const (
+	opCAS    commandOp = "cas"
+	opDelete commandOp = "delete"
+)
+
+// command is a single change of the KV store, replicated through the Raft log.
+type command struct {
+	Op    commandOp `json:"op"`
+	Key   string    `json:"key"`
+	Value []byte    `json:"value,omitempty"`
+
+	// Version the key is expected to have for a CAS to succeed. 0 means the key must not exist.
+	Version uint64 `json:"version,omitempty"`
+}
+
+// applyResult is the outcome of applying a command to the FSM. On conflict, it holds the
+// current value and version of the key, so that the CAS can be retried right away.
+type applyResult struct {
+	conflict bool
+	value    []byte
+	version  uint64
+}
+
+// entry is a value stored in the FSM. Version is the index of the Raft log entry which
+// last modified the key, so it increases on every change.
+type entry struct {
+	Value   []byte `json:"value"`
+	Version uint64 `json:"version"`
+}
+
+// fsmSnapshotData is the serialized form of the FSM.
+type fsmSnapshotData struct {
+	Index   uint64            `json:"index"`
+	Entries map[string]*entry `json:"entries"`
+}
+
+// fsm is the replicated state machine holding the raw values of the KV store. Values are
+// opaque bytes, encoded and decoded by the codec of each client.
+type fsm struct {
+	mtx     sync.RWMutex
+	entries map[string]*entry
+
+	// Index of the last command applied to the FSM, and a channel closed each time it moves.
+	index     uint64
+	indexMove chan struct{}
+
+	// Called with the key of every changed value, once the change is visible.
+	notify func(key string)
+
+	metrics *metrics
+}
+
+func newFSM(notify func(key string), m *metrics) *fsm {
+	return &fsm{
+		entries:   map[string]*entry{},
+		indexMove: make(chan struct{}),
+		notify:    notify,
+		metrics:   m,
+	}
+}
+
+// Apply is part of raft.FSM interface.
+func (f *fsm) Apply(l *raft.Log) any {
+	var cmd command
+	if err := json.Unmarshal(l.Data, &cmd); err != nil {
+		return fmt.Errorf("failed to decode command: %w", err)
+	}
+
+	f.mtx.Lock()
+	res, changed := f.applyCommand(l.Index, cmd)
+	f.setIndex(l.Index)
+	f.mtx.Unlock()
+
+	if changed {
+		f.notify(cmd.Key)
+	}
+	return res
+}
+
+func (f *fsm) applyCommand(index uint64, cmd command) (*applyResult, bool) {
+	switch cmd.Op {
+	case opCAS:
+		var current *entry
+		if e, ok := f.entries[cmd.Key]; ok {
+			current = e
+		} else {
+			current = &entry{}
+		}
+		if current.Version != cmd.Version {
+			return &applyResult{conflict: true, value: current.Value, version: current.Version}, false
+		}
+
+		f.entries[cmd.Key] = &entry{Value: cmd.Value, Version: index}
+		return &applyResult{}, true
+
+	case opDelete:
+		if _, ok := f.entries[cmd.Key]; !ok {
+			return &applyResult{}, false
+		}
+
+		delete(f.entries, cmd.Key)
+		return &applyResult{}, true
+	}
+
+	return &applyResult{}, false
+}
+
+// setIndex must be called with the lock held.
+func (f *fsm) setIndex(index uint64) {
+	if index <= f.index {
+		return
+	}
+
+	f.index = index
+	close(f.indexMove)
+	f.indexMove = make(chan struct{})
+	f.metrics.appliedIndex.Set(float64(index))
+}
+
+// Snapshot is part of raft.FSM interface.
+func (f *fsm) Snapshot() (raft.FSMSnapshot, error) {
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+
+	// Values are never modified in place, so copying the map is enough.
+	entries := make(map[string]*entry, len(f.entries))
+	for k, e := range f.entries {
+		entries[k] = e
+	}
+
+	f.metrics.snapshots.Inc()
+	return &fsmSnapshot{data: fsmSnapshotData{Index: f.index, Entries: entries}}, nil
+}
+
+// Restore is part of raft.FSM interface.
+func (f *fsm) Restore(rc io.ReadCloser) error {
+	defer rc.Close()
+
+	var data fsmSnapshotData
+	if err := json.NewDecoder(rc).Decode(&data); err != nil {
+		return fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+	if data.Entries == nil {
+		data.Entries = map[string]*entry{}
+	}
+
+	f.mtx.Lock()
+	changed := make([]string, 0, len(f.entries)+len(data.Entries))
+	for k, e := range f.entries {
+		if restored, ok := data.Entries[k]; !ok || restored.Version != e.Version {
+			changed = append(changed, k)
+		}
+	}
+	for k := range data.Entries {
+		if _, ok := f.entries[k]; !ok {
+			changed = append(changed, k)
+		}
+	}
+
+	f.entries = data.Entries
+	f.setIndex(data.Index)
+	f.mtx.Unlock()
+
+	f.metrics.restores.Inc()
+	for _, k := range changed {
+		f.notify(k)
+	}
+	return nil
+}
+
+// get returns the value stored under key and its version, or nil and 0 if the key doesn't exist.
+func (f *fsm) get(key string) ([]byte, uint64) {
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+
+	e, ok := f.entries[key]
+	if !ok {
+		return nil, 0
+	}
+	return e.Value, e.Version
+}
+
+// list returns the sorted keys with the given prefix.
+func (f *fsm) list(prefix string) []string {
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+
+	keys := []string{}
+	for k := range f.entries {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// storeCopy returns a copy of all entries, for the status page.
+func (f *fsm) storeCopy() map[string]entry {
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+
+	result := make(map[string]entry, len(f.entries))
+	for k, e := range f.entries {
+		result[k] = *e
+	}
+	return result
+}
+
+func (f *fsm) lastIndex() uint64 {
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+
+	return f.index
+}
+
+// waitForIndex blocks until the FSM has applied the command with the given index, or any later one.
+func (f *fsm) waitForIndex(ctx context.Context, index uint64) error {
+	for {
+		f.mtx.RLock()
+		current, moved := f.index, f.indexMove
+		f.mtx.RUnlock()
+
+		if current >= index {
+			return nil
+		}
+
+		select {
+		case <-moved:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+type fsmSnapshot struct {
+	data fsmSnapshotData
+}
+
+// Persist is part of raft.FSMSnapshot interface.
+func (s *fsmSnapshot) Persist(sink raft.SnapshotSink) error {
+	if err := json.NewEncoder(sink).Encode(s.data); err != nil {
+		_ = sink.Cancel()
+		return err
+	}
+	return sink.Close()
+}
+
+// Release is part of raft.FSMSnapshot interface.
+func (s *fsmSnapshot) Release() {}
@@ -0,0 +1,273 @@
+package raft
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"go.uber.org/atomic"
+
+	"github.com/cortexproject/cortex/pkg/util/services"
+)
+
+// KVInitService initializes a raft.KV on first call to GetRaftKV, and starts it. On stop,
+// KV is stopped too. If KV fails, error is reported from the service.
+type KVInitService struct {
+	services.Service
+
+	// config used for initialization
+	cfg    *KVConfig
+	logger log.Logger
+
+	// init function, to avoid multiple initializations.
+	init sync.Once
+
+	// state
+	kv      atomic.Value
+	err     error
+	watcher *services.FailureWatcher
+}
+
+func NewKVInitService(cfg *KVConfig, logger log.Logger) *KVInitService {
+	kvinit := &KVInitService{
+		cfg:     cfg,
+		watcher: services.NewFailureWatcher(),
+		logger:  logger,
+	}
+	kvinit.Service = services.NewBasicService(nil, kvinit.running, kvinit.stopping).WithName("raft KV service")
+	return kvinit
+}
+
+// GetRaftKV will initialize raft.KV on first call, and add it to service failure watcher.
+func (kvs *KVInitService) GetRaftKV() (*KV, error) {
+	kvs.init.Do(func() {
+		kv := NewKV(*kvs.cfg, kvs.logger)
+		kvs.watcher.WatchService(kv)
+		kvs.err = kv.StartAsync(context.Background())
+
+		kvs.kv.Store(kv)
+	})
+
+	return kvs.getKV(), kvs.err
+}
+
+// Returns KV if it was initialized, or nil.
+func (kvs *KVInitService) getKV() *KV {
+	kv := kvs.kv.Load()
+	if kv == nil {
+		return nil
+	}
+	return kv.(*KV)
+}
+
+func (kvs *KVInitService) running(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-kvs.watcher.Chan():
+		// Only happens if KV service was actually initialized in GetRaftKV and it fails.
+		return err
+	}
+}
+
+func (kvs *KVInitService) stopping(_ error) error {
+	kv := kvs.getKV()
+	if kv == nil {
+		return nil
+	}
+
+	return services.StopAndAwaitTerminated(context.Background(), kv)
+}
+
+func (kvs *KVInitService) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	kv := kvs.getKV()
+	if kv == nil || kv.State() != services.Running {
+		w.Header().Set("Content-Type", "text/plain")
+		// Ignore inactionable errors.
+		_, _ = w.Write([]byte("This instance doesn't use the raft KV store, or it isn't running."))
+		return
+	}
+
+	const downloadKeyParam = "downloadKey"
+
+	store := kv.fsm.storeCopy()
+
+	if err := req.ParseForm(); err == nil && req.Form[downloadKeyParam] != nil {
+		downloadKey(w, store, req.Form[downloadKeyParam][0]) // Use first value, ignore the rest.
+		return
+	}
+
+	v := kv.pageData()
+	v.Store = store
+
+	accept := req.Header.Get("Accept")
+	if strings.Contains(accept, "application/json") {
+		w.Header().Set("Content-Type", "application/json")
+
+		data, err := json.Marshal(v)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		// We ignore errors here, because we cannot do anything about them.
+		_, _ = w.Write(data)
+		return
+	}
+
+	err := pageTemplate.Execute(w, v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func downloadKey(w http.ResponseWriter, store map[string]entry, key string) {
+	val, ok := store[key]
+	if !ok {
+		http.Error(w, "value not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Add("content-type", "application/octet-stream")
+	// Set content-length so that client knows whether it has received full response or not.
+	w.Header().Add("content-length", strconv.Itoa(len(val.Value)))
+	w.Header().Add("content-disposition", fmt.Sprintf("attachment; filename=%d-%s", val.Version, key))
+	w.WriteHeader(200)
+
+	// Ignore errors, we cannot do anything about them.
+	_, _ = w.Write(val.Value)
+}
+
+type pageMember struct {
+	ID       string `json:"id"`
+	Address  string `json:"address"`
+	Suffrage string `json:"suffrage"`
+}
+
+type pageData struct {
+	Now          time.Time         `json:"now"`
+	NodeID       string            `json:"node_id"`
+	State        string            `json:"state"`
+	LeaderID     string            `json:"leader_id"`
+	LeaderAddr   string            `json:"leader_address"`
+	AppliedIndex uint64            `json:"applied_index"`
+	Members      []pageMember      `json:"members"`
+	Stats        map[string]string `json:"stats"`
+	Store        map[string]entry  `json:"-"`
+}
+
+func (k *KV) pageData() pageData {
+	leaderAddr, leaderID := k.raft.LeaderWithID()
+
+	v := pageData{
+		Now:          time.Now(),
+		NodeID:       k.cfg.NodeID,
+		State:        k.raft.State().String(),
+		LeaderID:     string(leaderID),
+		LeaderAddr:   string(leaderAddr),
+		AppliedIndex: k.fsm.lastIndex(),
+		Stats:        k.raft.Stats(),
+	}
+
+	if f := k.raft.GetConfiguration(); f.Error() == nil {
+		for _, s := range f.Configuration().Servers {
+			v.Members = append(v.Members, pageMember{ID: string(s.ID), Address: string(s.Address), Suffrage: s.Suffrage.String()})
+		}
+	}
+	sort.Slice(v.Members, func(i, j int) bool {
+		return v.Members[i].ID < v.Members[j].ID
+	})
+
+	return v
+}
+
+var pageTemplate = template.Must(template.New("webpage").Parse(pageContent))
+
+const pageContent = `
+<!DOCTYPE html>
+<html>
+	<head>
+		<meta charset="UTF-8">
+		<title>Raft KV Status</title>
+	</head>
+	<body>
+		<h1>Raft KV Status</h1>
+		<p>Current time: {{ .Now }}</p>
+
+		<ul>
+		<li>Node ID: {{ .NodeID }}</li>
+		<li>State: {{ .State }}</li>
+		<li>Leader: {{ .LeaderID }} ({{ .LeaderAddr }})</li>
+		<li>Applied index: {{ .AppliedIndex }}</li>
+		</ul>
+
+		<h2>KV Store</h2>
+
+		<table width="100%" border="1">
+			<thead>
+				<tr>
+					<th>Key</th>
+					<th>Version</th>
+					<th>Size</th>
+					<th>Actions</th>
+				</tr>
+			</thead>
+
+			<tbody>
+				{{ range $k, $v := .Store }}
+				<tr>
+					<td>{{ $k }}</td>
+					<td>{{ $v.Version }}</td>
+					<td>{{ len $v.Value }}</td>
+					<td><a href="?downloadKey={{ $k }}">download</a></td>
+				</tr>
+				{{ end }}
+			</tbody>
+		</table>
+
+		<p>Version is the index of the raft log entry which last modified the value. Size is in bytes.</p>
+
+		<h2>Raft Cluster Members</h2>
+
+		<table width="100%" border="1">
+			<thead>
+				<tr>
+					<th>ID</th>
+					<th>Address</th>
+					<th>Suffrage</th>
+				</tr>
+			</thead>
+
+			<tbody>
+				{{ range .Members }}
+				<tr>
+					<td>{{ .ID }}</td>
+					<td>{{ .Address }}</td>
+					<td>{{ .Suffrage }}</td>
+				</tr>
+				{{ end }}
+			</tbody>
+		</table>
+
+		<h2>Raft Stats</h2>
+
+		<table border="1">
+			<tbody>
+				{{ range $k, $v := .Stats }}
+				<tr>
+					<td>{{ $k }}</td>
+					<td>{{ $v }}</td>
+				</tr>
+				{{ end }}
+			</tbody>
+		</table>
+	</body>
+</html>`
@@ -0,0 +1,56 @@
+package raft
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexproject/cortex/pkg/ring/kv/codec"
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+	"github.com/cortexproject/cortex/pkg/util/services"
+)
+
+func TestPage(t *testing.T) {
+	var cfg KVConfig
+	flagext.DefaultValues(&cfg)
+	cfg.NodeID = "node-0"
+	cfg.BindAddr = "127.0.0.1"
+	cfg.DataDir = t.TempDir()
+	bootstrapSingleNode(t, cfg.NodeID)(&cfg)
+
+	kvinit := NewKVInitService(&cfg, log.NewNopLogger())
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), kvinit))
+	t.Cleanup(func() {
+		_ = services.StopAndAwaitTerminated(context.Background(), kvinit)
+	})
+
+	kv, err := kvinit.GetRaftKV()
+	require.NoError(t, err)
+	require.NoError(t, kv.AwaitRunning(context.Background()))
+	require.NoError(t, NewClient(kv, codec.String{}).CAS(context.Background(), "hello", func(any) (any, bool, error) {
+		return "world", true, nil
+	}))
+
+	rec := httptest.NewRecorder()
+	kvinit.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/raft-kv", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "<td>hello</td>")
+	assert.Contains(t, rec.Body.String(), "<td>node-0</td>")
+
+	rec = httptest.NewRecorder()
+	kvinit.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/raft-kv?downloadKey=hello", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "world", rec.Body.String())
+}
+
+func TestStop(t *testing.T) {
+	var cfg KVConfig
+	flagext.DefaultValues(&cfg)
+	kvinit := NewKVInitService(&cfg, nil)
+	require.NoError(t, kvinit.stopping(nil))
+}
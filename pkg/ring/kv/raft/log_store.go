@@ -0,0 +1,163 @@
+package raft
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+
+	"github.com/hashicorp/go-msgpack/v2/codec"
+	"github.com/hashicorp/raft"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	logsBucket   = []byte("logs")
+	stableBucket = []byte("conf")
+
+	// Raft checks the error message to tell a missing key from a failure.
+	errKeyNotFound = errors.New("not found")
+)
+
+// logStore implements raft.LogStore and raft.StableStore on top of a bbolt database.
+// Logs are stored by index in big-endian order, so that the bbolt cursor iterates them in order.
+type logStore struct {
+	db *bolt.DB
+}
+
+func newLogStore(path string) (*logStore, error) {
+	db, err := bolt.Open(path, 0o600, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(logsBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(stableBucket)
+		return err
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return &logStore{db: db}, nil
+}
+
+func (s *logStore) Close() error {
+	return s.db.Close()
+}
+
+// FirstIndex is part of raft.LogStore interface.
+func (s *logStore) FirstIndex() (uint64, error) {
+	var idx uint64
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if k, _ := tx.Bucket(logsBucket).Cursor().First(); k != nil {
+			idx = binary.BigEndian.Uint64(k)
+		}
+		return nil
+	})
+	return idx, err
+}
+
+// LastIndex is part of raft.LogStore interface.
+func (s *logStore) LastIndex() (uint64, error) {
+	var idx uint64
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if k, _ := tx.Bucket(logsBucket).Cursor().Last(); k != nil {
+			idx = binary.BigEndian.Uint64(k)
+		}
+		return nil
+	})
+	return idx, err
+}
+
+// GetLog is part of raft.LogStore interface.
+func (s *logStore) GetLog(index uint64, log *raft.Log) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		val := tx.Bucket(logsBucket).Get(uint64Key(index))
+		if val == nil {
+			return raft.ErrLogNotFound
+		}
+		return codec.NewDecoderBytes(val, &codec.MsgpackHandle{}).Decode(log)
+	})
+}
+
+// StoreLog is part of raft.LogStore interface.
+func (s *logStore) StoreLog(log *raft.Log) error {
+	return s.StoreLogs([]*raft.Log{log})
+}
+
+// StoreLogs is part of raft.LogStore interface.
+func (s *logStore) StoreLogs(logs []*raft.Log) error {
+	handle := &codec.MsgpackHandle{BasicHandle: codec.BasicHandle{TimeNotBuiltin: true}}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(logsBucket)
+		for _, log := range logs {
+			buf := bytes.Buffer{}
+			if err := codec.NewEncoder(&buf, handle).Encode(log); err != nil {
+				return err
+			}
+			if err := bucket.Put(uint64Key(log.Index), buf.Bytes()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteRange is part of raft.LogStore interface. Both bounds are inclusive.
+func (s *logStore) DeleteRange(minIdx, maxIdx uint64) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		c := tx.Bucket(logsBucket).Cursor()
+		for k, _ := c.Seek(uint64Key(minIdx)); k != nil && binary.BigEndian.Uint64(k) <= maxIdx; k, _ = c.Next() {
+			if err := c.Delete(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Set is part of raft.StableStore interface.
+func (s *logStore) Set(key, val []byte) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(stableBucket).Put(key, val)
+	})
+}
+
+// Get is part of raft.StableStore interface.
+func (s *logStore) Get(key []byte) ([]byte, error) {
+	var val []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(stableBucket).Get(key)
+		if v == nil {
+			return errKeyNotFound
+		}
+
+		// The value is only valid while the transaction is open.
+		val = append([]byte(nil), v...)
+		return nil
+	})
+	return val, err
+}
+
+// SetUint64 is part of raft.StableStore interface.
+func (s *logStore) SetUint64(key []byte, val uint64) error {
+	return s.Set(key, uint64Key(val))
+}
+
+// GetUint64 is part of raft.StableStore interface.
+func (s *logStore) GetUint64(key []byte) (uint64, error) {
+	val, err := s.Get(key)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(val), nil
+}
+
+func uint64Key(v uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, v)
+}
@@ -0,0 +1,66 @@
+package raft
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/raft"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "raft.db")
+
+	store, err := newLogStore(path)
+	require.NoError(t, err)
+
+	first, err := store.FirstIndex()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), first)
+
+	var logs []*raft.Log
+	for i := uint64(1); i <= 5; i++ {
+		logs = append(logs, &raft.Log{Index: i, Term: 1, Type: raft.LogCommand, Data: []byte{byte(i)}})
+	}
+	require.NoError(t, store.StoreLogs(logs[:4]))
+	require.NoError(t, store.StoreLog(logs[4]))
+
+	// Logs are kept ordered by index, even past the first byte of the key.
+	require.NoError(t, store.StoreLog(&raft.Log{Index: 256, Term: 2, Type: raft.LogCommand}))
+
+	last, err := store.LastIndex()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(256), last)
+
+	var log raft.Log
+	require.NoError(t, store.GetLog(3, &log))
+	assert.Equal(t, uint64(3), log.Index)
+	assert.Equal(t, []byte{3}, log.Data)
+	require.ErrorIs(t, store.GetLog(10, &log), raft.ErrLogNotFound)
+
+	require.NoError(t, store.DeleteRange(1, 2))
+	first, err = store.FirstIndex()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), first)
+
+	// Raft relies on the error message to tell a missing key.
+	_, err = store.Get([]byte("missing"))
+	require.EqualError(t, err, "not found")
+
+	require.NoError(t, store.SetUint64([]byte("term"), 2))
+	require.NoError(t, store.Close())
+
+	// The state is persisted.
+	store, err = newLogStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+
+	term, err := store.GetUint64([]byte("term"))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), term)
+
+	first, err = store.FirstIndex()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), first)
+}
@@ -0,0 +1,74 @@
+package raft
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type metrics struct {
+	isLeader             prometheus.Gauge
+	appliedIndex         prometheus.Gauge
+	members              *prometheus.GaugeVec
+	casAttempts          prometheus.Counter
+	casSuccesses         prometheus.Counter
+	casFailures          prometheus.Counter
+	casConflicts         prometheus.Counter
+	forwardedRequests    *prometheus.CounterVec
+	snapshots            prometheus.Counter
+	restores             prometheus.Counter
+	removedDeadNonvoters prometheus.Counter
+	promotedNonvoters    prometheus.Counter
+}
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	return &metrics{
+		isLeader: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name: "cortex_raft_kv_leader",
+			Help: "1 if this node is the leader of the Raft KV cluster, 0 otherwise.",
+		}),
+		appliedIndex: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name: "cortex_raft_kv_applied_index",
+			Help: "Index of the last Raft log entry applied to the KV store on this node.",
+		}),
+		members: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cortex_raft_kv_cluster_members",
+			Help: "Number of members of the Raft KV cluster, by suffrage.",
+		}, []string{"suffrage"}),
+		casAttempts: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_raft_kv_cas_attempts_total",
+			Help: "Attempts to CAS-update a value in the Raft KV store.",
+		}),
+		casSuccesses: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_raft_kv_cas_success_total",
+			Help: "Successful CAS-updates of a value in the Raft KV store.",
+		}),
+		casFailures: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_raft_kv_cas_failure_total",
+			Help: "Failed CAS-updates of a value in the Raft KV store, after all retries.",
+		}),
+		casConflicts: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_raft_kv_cas_conflicts_total",
+			Help: "CAS attempts rejected because the value was modified concurrently.",
+		}),
+		forwardedRequests: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "cortex_raft_kv_forwarded_requests_total",
+			Help: "Requests forwarded by this node to the leader of the Raft KV cluster.",
+		}, []string{"type", "status"}),
+		snapshots: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_raft_kv_snapshots_total",
+			Help: "Number of snapshots of the KV store taken by this node.",
+		}),
+		restores: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_raft_kv_snapshot_restores_total",
+			Help: "Number of times the KV store was restored from a snapshot on this node.",
+		}),
+		removedDeadNonvoters: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_raft_kv_dead_nonvoters_removed_total",
+			Help: "Number of unreachable non-voting members removed from the cluster by this node while leader.",
+		}),
+		promotedNonvoters: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "cortex_raft_kv_nonvoters_promoted_total",
+			Help: "Number of non-voting members promoted to voters by this node while leader.",
+		}),
+	}
+}
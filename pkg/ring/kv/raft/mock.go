@@ -0,0 +1,71 @@
+package raft
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+
+	"github.com/go-kit/log"
+
+	"github.com/cortexproject/cortex/pkg/ring/kv/codec"
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+	"github.com/cortexproject/cortex/pkg/util/services"
+)
+
+// NewSingleNodeClient creates a Client backed by a single-node Raft cluster listening on
+// localhost, which stores its data in a temporary directory. It's meant to be used by tests.
+// The returned Closer stops the node and removes its data.
+func NewSingleNodeClient(codec codec.Codec, logger log.Logger) (*Client, io.Closer, error) {
+	dir, err := os.MkdirTemp("", "raft-kv")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The node bootstraps a cluster made of itself only, so its port must be known in advance.
+	port, err := getFreePort()
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, nil, err
+	}
+
+	cfg := KVConfig{}
+	flagext.DefaultValues(&cfg)
+	cfg.NodeID = "single-node"
+	cfg.BindAddr = "127.0.0.1"
+	cfg.BindPort = port
+	cfg.BootstrapMembers = flagext.StringSlice{fmt.Sprintf("%s=127.0.0.1:%d", cfg.NodeID, port)}
+	cfg.DataDir = dir
+
+	kv := NewKV(cfg, logger)
+	if err := services.StartAndAwaitRunning(context.Background(), kv); err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, nil, err
+	}
+
+	return NewClient(kv, codec), singleNodeCloser{kv: kv, dir: dir}, nil
+}
+
+type singleNodeCloser struct {
+	kv  *KV
+	dir string
+}
+
+func (c singleNodeCloser) Close() error {
+	err := services.StopAndAwaitTerminated(context.Background(), c.kv)
+	if rmErr := os.RemoveAll(c.dir); err == nil {
+		err = rmErr
+	}
+	return err
+}
+
+func getFreePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
@@ -0,0 +1,106 @@
+package raft
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cortexproject/cortex/pkg/ring/kv/codec"
+	"github.com/cortexproject/cortex/pkg/util/services"
+)
+
+// Client implements kv.Client interface, by using raft.KV
+type Client struct {
+	kv    *KV // reference to singleton Raft-based KV
+	codec codec.Codec
+}
+
+// NewClient creates new client instance.
+func NewClient(kv *KV, codec codec.Codec) *Client {
+	return &Client{
+		kv:    kv,
+		codec: codec,
+	}
+}
+
+// List is part of kv.Client interface.
+func (c *Client) List(ctx context.Context, prefix string) ([]string, error) {
+	err := c.awaitKVRunningOrStopping(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.kv.List(ctx, prefix)
+}
+
+// Get is part of kv.Client interface.
+func (c *Client) Get(ctx context.Context, key string) (any, error) {
+	err := c.awaitKVRunningOrStopping(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.kv.Get(ctx, key, c.codec)
+}
+
+// Delete is part of kv.Client interface.
+func (c *Client) Delete(ctx context.Context, key string) error {
+	err := c.awaitKVRunningOrStopping(ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.kv.Delete(ctx, key)
+}
+
+// CAS is part of kv.Client interface
+func (c *Client) CAS(ctx context.Context, key string, f func(in any) (out any, retry bool, err error)) error {
+	err := c.awaitKVRunningOrStopping(ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.kv.CAS(ctx, key, c.codec, f)
+}
+
+// WatchKey is part of kv.Client interface.
+func (c *Client) WatchKey(ctx context.Context, key string, f func(any) bool) {
+	err := c.awaitKVRunningOrStopping(ctx)
+	if err != nil {
+		return
+	}
+
+	c.kv.WatchKey(ctx, key, c.codec, f)
+}
+
+// WatchPrefix calls f whenever any value stored under prefix changes.
+// Part of kv.Client interface.
+func (c *Client) WatchPrefix(ctx context.Context, prefix string, f func(string, any) bool) {
+	err := c.awaitKVRunningOrStopping(ctx)
+	if err != nil {
+		return
+	}
+
+	c.kv.WatchPrefix(ctx, prefix, c.codec, f)
+}
+
+func (c *Client) LastUpdateTime(_ string) time.Time {
+	return time.Now().UTC()
+}
+
+// We want to use KV in Running and Stopping states.
+func (c *Client) awaitKVRunningOrStopping(ctx context.Context) error {
+	s := c.kv.State()
+	switch s {
+	case services.Running, services.Stopping:
+		return nil
+	case services.New, services.Starting:
+		err := c.kv.AwaitRunning(ctx)
+		if ns := c.kv.State(); ns == services.Stopping {
+			return nil
+		}
+		return err
+	default:
+		return fmt.Errorf("unexpected state: %v", s)
+	}
+}
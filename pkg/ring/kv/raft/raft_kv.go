@@ -0,0 +1,1069 @@
+package raft
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/hashicorp/go-hclog"
+	sockaddr "github.com/hashicorp/go-sockaddr"
+	"github.com/hashicorp/raft"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/cortexproject/cortex/pkg/ring/kv/codec"
+	"github.com/cortexproject/cortex/pkg/util/backoff"
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+	"github.com/cortexproject/cortex/pkg/util/services"
+	cortextls "github.com/cortexproject/cortex/pkg/util/tls"
+)
+
+const (
+	maxCasRetries = 10                    // max retries in CAS operation
+	casRetryDelay = 50 * time.Millisecond // max random delay before retrying a conflicting CAS
+
+	// How often the leader state and the cluster members are checked.
+	defaultCheckInterval = 5 * time.Second
+
+	// Max number of pooled connections to each node, used by the Raft transport.
+	transportMaxPool = 3
+
+	// Timeout to resolve the host names of the members, when checking the caller of a membership change.
+	addrResolveTimeout = 5 * time.Second
+)
+
+var (
+	errNoLeader  = errors.New("no leader elected in the raft KV cluster")
+	errNotLeader = errors.New("this node is not the leader of the raft KV cluster")
+)
+
+// KVConfig is a config for raft.KV
+type KVConfig struct {
+	NodeID string `yaml:"node_id"`
+
+	BindAddr string `yaml:"bind_addr"`
+	BindPort int    `yaml:"bind_port"`
+
+	// ip:port to advertise other cluster members.
+	AdvertiseAddr string `yaml:"advertise_addr"`
+	AdvertisePort int    `yaml:"advertise_port"`
+
+	DataDir string `yaml:"data_dir"`
+
+	// Voting members of a new cluster, and members of an existing cluster to join as a non-voter.
+	BootstrapMembers flagext.StringSlice `yaml:"bootstrap_members"`
+	JoinMembers      flagext.StringSlice `yaml:"join_members"`
+	MinJoinBackoff   time.Duration       `yaml:"min_join_backoff"`
+	MaxJoinBackoff   time.Duration       `yaml:"max_join_backoff"`
+	MaxVoters        int                 `yaml:"max_voters"`
+
+	LeaveOnStopping     bool          `yaml:"leave_on_stopping"`
+	DeadNodeReclaimTime time.Duration `yaml:"dead_node_reclaim_time"`
+
+	ApplyTimeout time.Duration `yaml:"apply_timeout"`
+
+	SnapshotInterval  time.Duration `yaml:"snapshot_interval"`
+	SnapshotThreshold uint64        `yaml:"snapshot_threshold"`
+	SnapshotRetain    int           `yaml:"snapshot_retain"`
+
+	// The raft KV traffic is secured with the memberlist TLS config, which is copied here.
+	TLSEnabled bool                   `yaml:"-"`
+	TLS        cortextls.ClientConfig `yaml:"-"`
+
+	// Where to put custom metrics. Metrics are not registered, if this is nil.
+	MetricsRegisterer prometheus.Registerer `yaml:"-"`
+}
+
+// RegisterFlagsWithPrefix registers flags.
+func (cfg *KVConfig) RegisterFlagsWithPrefix(f *flag.FlagSet, prefix string) {
+	defaults := raft.DefaultConfig()
+
+	f.StringVar(&cfg.NodeID, prefix+"raft-kv.node-id", "", "Unique ID of this node in the raft KV cluster. It must not change across restarts. Defaults to hostname.")
+	f.StringVar(&cfg.BindAddr, prefix+"raft-kv.bind-addr", "0.0.0.0", "IP address to listen on for the raft KV cluster traffic.")
+	f.IntVar(&cfg.BindPort, prefix+"raft-kv.bind-port", 7947, "Port to listen on for the raft KV cluster traffic.")
+	f.StringVar(&cfg.AdvertiseAddr, prefix+"raft-kv.advertise-addr", "", "IP address to advertise to the other members of the raft KV cluster. Defaults to the bind address, or to the first private IP address if listening on all addresses.")
+	f.IntVar(&cfg.AdvertisePort, prefix+"raft-kv.advertise-port", 0, "Port to advertise to the other members of the raft KV cluster. Defaults to the bind port.")
+	f.StringVar(&cfg.DataDir, prefix+"raft-kv.data-dir", "raft-kv", "Directory to store the raft log and the snapshots of the KV store. It must be persisted across restarts.")
+	f.Var(&cfg.BootstrapMembers, prefix+"raft-kv.bootstrap-members", "Voting members of the raft KV cluster, in the <node-id>=<host>:<port> format. Can be specified multiple times. The listed nodes bootstrap the cluster the first time they start. To run a single-node cluster, list only this node. A node without existing raft state must either be a bootstrap member, or have join members configured.")
+	f.Var(&cfg.JoinMembers, prefix+"raft-kv.join-members", "Members of an existing raft KV cluster, in the <host>:<port> format, that this node contacts to join the cluster as a non-voting member. Unless the node presents a client certificate signed by the memberlist TLS CA, it must connect from its advertised address. Can be specified multiple times.")
+	f.DurationVar(&cfg.MinJoinBackoff, prefix+"raft-kv.min-join-backoff", 1*time.Second, "Min backoff duration to join the raft KV cluster.")
+	f.DurationVar(&cfg.MaxJoinBackoff, prefix+"raft-kv.max-join-backoff", 1*time.Minute, "Max backoff duration to join the raft KV cluster.")
+	f.IntVar(&cfg.MaxVoters, prefix+"raft-kv.max-voters", 5, "Max number of voting members of the raft KV cluster. The leader promotes the healthy non-voting members which joined the cluster to voters until this number is reached. 0 to never promote non-voting members.")
+	f.BoolVar(&cfg.LeaveOnStopping, prefix+"raft-kv.leave-on-stopping", false, "If enabled, a voting member removes itself from the raft KV cluster when stopping. Non-voting members always leave the cluster when stopping.")
+	f.DurationVar(&cfg.DeadNodeReclaimTime, prefix+"raft-kv.dead-node-reclaim-time", 0, "How long the leader waits before removing an unreachable non-voting member from the raft KV cluster. 0 to disable.")
+	f.DurationVar(&cfg.ApplyTimeout, prefix+"raft-kv.apply-timeout", 10*time.Second, "Timeout for applying a change to the raft KV store, including forwarding it to the leader.")
+	f.DurationVar(&cfg.SnapshotInterval, prefix+"raft-kv.snapshot-interval", defaults.SnapshotInterval, "How often to check whether a snapshot of the raft KV store should be taken.")
+	f.Uint64Var(&cfg.SnapshotThreshold, prefix+"raft-kv.snapshot-threshold", defaults.SnapshotThreshold, "Number of raft log entries since the previous snapshot after which a new snapshot is taken.")
+	f.IntVar(&cfg.SnapshotRetain, prefix+"raft-kv.snapshot-retain", 2, "Number of snapshots of the raft KV store to keep on disk.")
+}
+
+func (cfg *KVConfig) RegisterFlags(f *flag.FlagSet) {
+	cfg.RegisterFlagsWithPrefix(f, "")
+}
+
+// Validate the config.
+func (cfg *KVConfig) Validate() error {
+	if _, err := parseBootstrapMembers(cfg.BootstrapMembers); err != nil {
+		return err
+	}
+	if cfg.MaxVoters < 0 {
+		return errors.New("the raft KV max voters must not be negative")
+	}
+	if cfg.SnapshotRetain < 1 {
+		return errors.New("the raft KV snapshot retain must be at least 1")
+	}
+	return nil
+}
+
+// parseBootstrapMembers parses the <node-id>=<host>:<port> entries.
+func parseBootstrapMembers(members []string) ([]raft.Server, error) {
+	servers := make([]raft.Server, 0, len(members))
+	for _, m := range members {
+		id, addr, ok := strings.Cut(m, "=")
+		if !ok || id == "" || addr == "" {
+			return nil, fmt.Errorf("invalid raft KV bootstrap member %q, expected format is <node-id>=<host>:<port>", m)
+		}
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			return nil, fmt.Errorf("invalid raft KV bootstrap member %q: %w", m, err)
+		}
+
+		servers = append(servers, raft.Server{
+			Suffrage: raft.Voter,
+			ID:       raft.ServerID(id),
+			Address:  raft.ServerAddress(addr),
+		})
+	}
+	return servers, nil
+}
+
+// KV implements a linearizable Key-Value store replicated with Raft. KV store has API similar to kv.Client,
+// except methods also need explicit codec for each operation.
+// KV is a Service. It needs to be started first, and is only usable once it enters Running state.
+// Writes are applied by the leader: other nodes forward them to it. Reads are served from the local state,
+// once it has caught up with the leader.
+type KV struct {
+	services.Service
+
+	cfg     KVConfig
+	logger  log.Logger
+	metrics *metrics
+
+	fsm       *fsm
+	raft      *raft.Raft
+	listener  *muxListener
+	transport *raft.NetworkTransport
+	store     *logStore
+
+	observations chan raft.Observation
+	observer     *raft.Observer
+
+	// Members whose heartbeats are failing, and since when. Only used by the leader.
+	failingSince  map[raft.ServerID]time.Time
+	checkInterval time.Duration
+
+	watchersMu     sync.Mutex
+	watchers       map[string][]chan string
+	prefixWatchers map[string][]chan string
+
+	// closed on shutdown
+	shutdown chan struct{}
+}
+
+// NewKV creates new Raft-based KV service. Service must be started before it can be used.
+func NewKV(cfg KVConfig, logger log.Logger) *KV {
+	kv := &KV{
+		cfg:            cfg,
+		logger:         log.With(logger, "component", "raft-kv"),
+		metrics:        newMetrics(cfg.MetricsRegisterer),
+		failingSince:   map[raft.ServerID]time.Time{},
+		checkInterval:  defaultCheckInterval,
+		watchers:       map[string][]chan string{},
+		prefixWatchers: map[string][]chan string{},
+		shutdown:       make(chan struct{}),
+	}
+
+	kv.fsm = newFSM(kv.notifyWatchers, kv.metrics)
+	kv.Service = services.NewBasicService(kv.starting, kv.running, kv.stopping).WithName("raft KV")
+	return kv
+}
+
+func (k *KV) starting(ctx context.Context) error {
+	if k.cfg.NodeID == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return fmt.Errorf("failed to get hostname for the raft KV node ID: %w", err)
+		}
+		k.cfg.NodeID = hostname
+	}
+
+	bootstrapMembers, err := parseBootstrapMembers(k.cfg.BootstrapMembers)
+	if err != nil {
+		return err
+	}
+
+	var tlsConfig *tls.Config
+	if k.cfg.TLSEnabled {
+		if tlsConfig, err = k.cfg.TLS.GetTLSConfig(); err != nil {
+			return fmt.Errorf("unable to create raft KV TLS config: %w", err)
+		}
+	}
+
+	if err := os.MkdirAll(k.cfg.DataDir, 0o750); err != nil {
+		return fmt.Errorf("failed to create raft KV data directory: %w", err)
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(k.cfg.BindAddr, strconv.Itoa(k.cfg.BindPort)))
+	if err != nil {
+		return fmt.Errorf("failed to listen for raft KV traffic: %w", err)
+	}
+
+	advertise, err := k.advertiseAddr(listener.Addr().(*net.TCPAddr))
+	if err != nil {
+		_ = listener.Close()
+		return err
+	}
+
+	raftLogger := newRaftLogger(k.logger)
+	k.listener = newMuxListener(listener, advertise, tlsConfig, k.cfg.ApplyTimeout, k.handleForwardRequest, k.logger)
+	k.transport = raft.NewNetworkTransportWithConfig(&raft.NetworkTransportConfig{
+		Stream:  k.listener,
+		MaxPool: transportMaxPool,
+		Timeout: k.cfg.ApplyTimeout,
+		Logger:  raftLogger,
+	})
+	go k.listener.serve()
+
+	// From now on, resources are released by closeResources() if starting fails.
+	if err := k.startRaft(ctx, bootstrapMembers, raftLogger); err != nil {
+		k.closeResources()
+		return err
+	}
+
+	level.Info(k.logger).Log("msg", "raft KV started", "node_id", k.cfg.NodeID, "advertise_addr", advertise.String())
+	return nil
+}
+
+func (k *KV) startRaft(ctx context.Context, bootstrapMembers []raft.Server, raftLogger hclog.Logger) error {
+	var err error
+	k.store, err = newLogStore(filepath.Join(k.cfg.DataDir, "raft.db"))
+	if err != nil {
+		return fmt.Errorf("failed to open raft KV log store: %w", err)
+	}
+
+	snapshots, err := raft.NewFileSnapshotStoreWithLogger(k.cfg.DataDir, k.cfg.SnapshotRetain, raftLogger)
+	if err != nil {
+		return fmt.Errorf("failed to open raft KV snapshot store: %w", err)
+	}
+
+	conf := raft.DefaultConfig()
+	conf.LocalID = raft.ServerID(k.cfg.NodeID)
+	conf.Logger = raftLogger
+	conf.SnapshotInterval = k.cfg.SnapshotInterval
+	conf.SnapshotThreshold = k.cfg.SnapshotThreshold
+
+	hasState, err := raft.HasExistingState(k.store, k.store, snapshots)
+	if err != nil {
+		return fmt.Errorf("failed to check raft KV existing state: %w", err)
+	}
+
+	if !hasState {
+		configuration, bootstrap := k.bootstrapConfiguration(bootstrapMembers)
+		if bootstrap {
+			level.Info(k.logger).Log("msg", "bootstrapping raft KV cluster", "members", len(configuration.Servers))
+			if err := raft.BootstrapCluster(conf, k.store, k.store, snapshots, k.transport, configuration); err != nil {
+				return fmt.Errorf("failed to bootstrap raft KV cluster: %w", err)
+			}
+		} else if len(k.cfg.JoinMembers) == 0 {
+			return fmt.Errorf("raft KV node %s has no existing state, is not a bootstrap member, and no join members are configured", k.cfg.NodeID)
+		}
+	}
+
+	k.raft, err = raft.NewRaft(conf, k.fsm, k.store, k.store, snapshots, k.transport)
+	if err != nil {
+		return fmt.Errorf("failed to start raft KV: %w", err)
+	}
+
+	// The failing heartbeats are tracked to remove the dead non-voting members, and to only promote healthy ones.
+	k.observations = make(chan raft.Observation, 64)
+	k.observer = raft.NewObserver(k.observations, false, func(o *raft.Observation) bool {
+		switch o.Data.(type) {
+		case raft.FailedHeartbeatObservation, raft.ResumedHeartbeatObservation, raft.LeaderObservation:
+			return true
+		}
+		return false
+	})
+	k.raft.RegisterObserver(k.observer)
+
+	if len(k.cfg.JoinMembers) > 0 && !k.isVoter() {
+		return k.joinCluster(ctx)
+	}
+	return nil
+}
+
+// bootstrapConfiguration returns the configuration to bootstrap the cluster with, and whether this node
+// should bootstrap it. Only the configured bootstrap members do.
+func (k *KV) bootstrapConfiguration(bootstrapMembers []raft.Server) (raft.Configuration, bool) {
+	for _, s := range bootstrapMembers {
+		if s.ID == raft.ServerID(k.cfg.NodeID) {
+			return raft.Configuration{Servers: bootstrapMembers}, true
+		}
+	}
+	return raft.Configuration{}, false
+}
+
+func (k *KV) advertiseAddr(bound *net.TCPAddr) (*net.TCPAddr, error) {
+	host := k.cfg.AdvertiseAddr
+	if host == "" {
+		if ip := net.ParseIP(k.cfg.BindAddr); k.cfg.BindAddr != "" && (ip == nil || !ip.IsUnspecified()) {
+			host = k.cfg.BindAddr
+		} else {
+			var err error
+			host, err = sockaddr.GetPrivateIP()
+			if err != nil {
+				return nil, fmt.Errorf("failed to get private IP address to advertise: %w", err)
+			}
+			if host == "" {
+				return nil, errors.New("no private IP address found to advertise, please configure the raft KV advertise address")
+			}
+		}
+	}
+
+	port := k.cfg.AdvertisePort
+	if port == 0 {
+		port = bound.Port
+	}
+
+	addr, err := net.ResolveTCPAddr("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve raft KV advertise address: %w", err)
+	}
+	return addr, nil
+}
+
+// joinCluster asks the configured join members to add this node to the cluster as a non-voter, until one succeeds.
+func (k *KV) joinCluster(ctx context.Context) error {
+	req := &forwardRequest{
+		Type:   forwardJoin,
+		NodeID: k.cfg.NodeID,
+		Addr:   string(k.transport.LocalAddr()),
+	}
+
+	boff := backoff.New(ctx, backoff.Config{
+		MinBackoff: k.cfg.MinJoinBackoff,
+		MaxBackoff: k.cfg.MaxJoinBackoff,
+	})
+
+	for boff.Ongoing() {
+		for _, addr := range k.cfg.JoinMembers {
+			if _, err := k.listener.sendForwardRequest(ctx, addr, req); err != nil {
+				level.Warn(k.logger).Log("msg", "failed to join raft KV cluster", "member", addr, "err", err)
+				continue
+			}
+
+			level.Info(k.logger).Log("msg", "joined raft KV cluster", "member", addr)
+			return nil
+		}
+
+		boff.Wait()
+	}
+	return fmt.Errorf("failed to join raft KV cluster: %w", boff.Err())
+}
+
+func (k *KV) running(ctx context.Context) error {
+	ticker := time.NewTicker(k.checkInterval)
+	defer ticker.Stop()
+
+	k.updateClusterMetrics()
+
+	for {
+		select {
+		case <-ticker.C:
+			k.updateClusterMetrics()
+			k.removeDeadNonvoters()
+			k.promoteNonvoters()
+
+		case o := <-k.observations:
+			k.observe(o)
+
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (k *KV) stopping(_ error) error {
+	defer close(k.shutdown)
+
+	if k.shouldLeave() {
+		ctx, cancel := context.WithTimeout(context.Background(), k.cfg.ApplyTimeout)
+		defer cancel()
+
+		_, err := k.execute(ctx, &forwardRequest{Type: forwardLeave, NodeID: k.cfg.NodeID})
+		if err != nil {
+			level.Warn(k.logger).Log("msg", "failed to leave raft KV cluster", "err", err)
+		} else {
+			level.Info(k.logger).Log("msg", "left raft KV cluster")
+		}
+	}
+
+	if k.observer != nil {
+		k.raft.DeregisterObserver(k.observer)
+	}
+
+	if err := k.raft.Shutdown().Error(); err != nil {
+		level.Warn(k.logger).Log("msg", "failed to shutdown raft", "err", err)
+	}
+	k.closeResources()
+	return nil
+}
+
+func (k *KV) closeResources() {
+	if k.transport != nil {
+		if err := k.transport.Close(); err != nil {
+			level.Warn(k.logger).Log("msg", "failed to close raft KV transport", "err", err)
+		}
+	}
+	if k.store != nil {
+		if err := k.store.Close(); err != nil {
+			level.Warn(k.logger).Log("msg", "failed to close raft KV log store", "err", err)
+		}
+	}
+}
+
+func (k *KV) shouldLeave() bool {
+	if k.cfg.LeaveOnStopping {
+		return true
+	}
+
+	server, ok := k.localServer()
+	return ok && server.Suffrage != raft.Voter
+}
+
+func (k *KV) isVoter() bool {
+	server, ok := k.localServer()
+	return ok && server.Suffrage == raft.Voter
+}
+
+// localServer returns this node from the latest known cluster configuration.
+func (k *KV) localServer() (raft.Server, bool) {
+	f := k.raft.GetConfiguration()
+	if f.Error() != nil {
+		return raft.Server{}, false
+	}
+
+	for _, s := range f.Configuration().Servers {
+		if s.ID == raft.ServerID(k.cfg.NodeID) {
+			return s, true
+		}
+	}
+	return raft.Server{}, false
+}
+
+func (k *KV) updateClusterMetrics() {
+	if k.raft.State() == raft.Leader {
+		k.metrics.isLeader.Set(1)
+	} else {
+		k.metrics.isLeader.Set(0)
+	}
+
+	f := k.raft.GetConfiguration()
+	if f.Error() != nil {
+		return
+	}
+
+	counts := map[raft.ServerSuffrage]int{raft.Voter: 0, raft.Nonvoter: 0}
+	for _, s := range f.Configuration().Servers {
+		counts[s.Suffrage]++
+	}
+	for suffrage, count := range counts {
+		k.metrics.members.WithLabelValues(strings.ToLower(suffrage.String())).Set(float64(count))
+	}
+}
+
+func (k *KV) observe(o raft.Observation) {
+	switch data := o.Data.(type) {
+	case raft.FailedHeartbeatObservation:
+		if _, ok := k.failingSince[data.PeerID]; !ok {
+			k.failingSince[data.PeerID] = data.LastContact
+		}
+	case raft.ResumedHeartbeatObservation:
+		delete(k.failingSince, data.PeerID)
+	case raft.LeaderObservation:
+		// Heartbeats are only tracked by the leader, so start over.
+		k.failingSince = map[raft.ServerID]time.Time{}
+	}
+}
+
+// removeDeadNonvoters removes the non-voting members which have been unreachable for longer than
+// the dead node reclaim time. Voting members are never removed automatically, since it would reduce
+// the fault tolerance of the cluster.
+func (k *KV) removeDeadNonvoters() {
+	if k.cfg.DeadNodeReclaimTime <= 0 || k.raft.State() != raft.Leader || len(k.failingSince) == 0 {
+		return
+	}
+
+	f := k.raft.GetConfiguration()
+	if f.Error() != nil {
+		return
+	}
+
+	for _, s := range f.Configuration().Servers {
+		since, ok := k.failingSince[s.ID]
+		if !ok || s.Suffrage == raft.Voter || time.Since(since) < k.cfg.DeadNodeReclaimTime {
+			continue
+		}
+
+		if err := k.raft.RemoveServer(s.ID, 0, k.cfg.ApplyTimeout).Error(); err != nil {
+			level.Warn(k.logger).Log("msg", "failed to remove dead raft KV member", "node_id", s.ID, "err", err)
+			continue
+		}
+
+		level.Info(k.logger).Log("msg", "removed dead raft KV member", "node_id", s.ID, "failing_since", since)
+		delete(k.failingSince, s.ID)
+		k.metrics.removedDeadNonvoters.Inc()
+	}
+}
+
+// promoteNonvoters promotes the healthy non-voting members to voters, until the cluster has max voters.
+// Members are promoted one at a time, so that each promotion is committed before the next one.
+func (k *KV) promoteNonvoters() {
+	if k.cfg.MaxVoters <= 0 || k.raft.State() != raft.Leader {
+		return
+	}
+
+	f := k.raft.GetConfiguration()
+	if f.Error() != nil {
+		return
+	}
+
+	voters := 0
+	var candidate *raft.Server
+	for _, s := range f.Configuration().Servers {
+		if s.Suffrage == raft.Voter {
+			voters++
+			continue
+		}
+
+		if _, failing := k.failingSince[s.ID]; !failing && s.Suffrage == raft.Nonvoter && candidate == nil {
+			candidate = &s
+		}
+	}
+
+	if candidate == nil || voters >= k.cfg.MaxVoters {
+		return
+	}
+
+	if err := k.raft.AddVoter(candidate.ID, candidate.Address, f.Index(), k.cfg.ApplyTimeout).Error(); err != nil {
+		level.Warn(k.logger).Log("msg", "failed to promote raft KV member to voter", "node_id", candidate.ID, "err", err)
+		return
+	}
+
+	level.Info(k.logger).Log("msg", "promoted raft KV member to voter", "node_id", candidate.ID)
+	k.metrics.promotedNonvoters.Inc()
+}
+
+// execute runs the request on the leader: locally if this node is the leader, or by forwarding it.
+func (k *KV) execute(ctx context.Context, req *forwardRequest) (*forwardResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, k.cfg.ApplyTimeout)
+	defer cancel()
+
+	addr, err := k.waitForLeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if k.raft.State() == raft.Leader {
+		resp := k.executeOnLeader(req)
+		if resp.Error != "" {
+			return nil, errors.New(resp.Error)
+		}
+		return resp, nil
+	}
+
+	forwarded := *req
+	forwarded.Forwarded = true
+
+	resp, err := k.listener.sendForwardRequest(ctx, string(addr), &forwarded)
+	if err != nil {
+		k.metrics.forwardedRequests.WithLabelValues(string(req.Type), "error").Inc()
+		return nil, err
+	}
+
+	k.metrics.forwardedRequests.WithLabelValues(string(req.Type), "success").Inc()
+	return resp, nil
+}
+
+// waitForLeader returns the address of the current leader, waiting for an election to complete if needed.
+func (k *KV) waitForLeader(ctx context.Context) (raft.ServerAddress, error) {
+	const pollInterval = 50 * time.Millisecond
+
+	for {
+		if addr, _ := k.raft.LeaderWithID(); addr != "" {
+			return addr, nil
+		}
+
+		select {
+		case <-time.After(pollInterval):
+		case <-ctx.Done():
+			return "", errNoLeader
+		}
+	}
+}
+
+// handleForwardRequest handles the requests sent by the other nodes.
+func (k *KV) handleForwardRequest(req *forwardRequest, caller forwardCaller) *forwardResponse {
+	if req.Type == forwardJoin || req.Type == forwardLeave {
+		if err := k.checkMembershipCaller(req, caller); err != nil {
+			level.Warn(k.logger).Log("msg", "rejected raft KV membership change", "type", req.Type, "node_id", req.NodeID, "remote", caller.addr, "err", err)
+			return &forwardResponse{Error: err.Error()}
+		}
+	}
+
+	if k.raft.State() == raft.Leader {
+		return k.executeOnLeader(req)
+	}
+
+	if req.Forwarded {
+		return &forwardResponse{Error: errNotLeader.Error()}
+	}
+
+	// Nodes joining the cluster can contact any member, which forwards the request to the leader.
+	relayed := *req
+	relayed.Relayed = true
+
+	resp, err := k.execute(context.Background(), &relayed)
+	if err != nil {
+		return &forwardResponse{Error: err.Error()}
+	}
+	return resp
+}
+
+// checkMembershipCaller returns an error if the caller isn't allowed to request the membership change.
+// A node can only remove itself from the cluster, and add itself with its own address, which is checked
+// against the address of the connection. The address of a joining node isn't checked if the caller
+// presented a certificate signed by the configured CA. Requests relayed on behalf of another node are
+// only accepted from the members of the cluster, or from callers with a certificate signed by the CA.
+func (k *KV) checkMembershipCaller(req *forwardRequest, caller forwardCaller) error {
+	callerHost, _, err := net.SplitHostPort(caller.addr.String())
+	if err != nil {
+		return fmt.Errorf("invalid caller address %s: %w", caller.addr, err)
+	}
+
+	f := k.raft.GetConfiguration()
+	if err := f.Error(); err != nil {
+		return err
+	}
+	servers := f.Configuration().Servers
+
+	if req.Relayed {
+		if caller.verified {
+			return nil
+		}
+		for _, s := range servers {
+			if addrHasHost(string(s.Address), callerHost) {
+				return nil
+			}
+		}
+		return errors.New("membership changes can only be relayed by the members of the raft KV cluster")
+	}
+
+	switch req.Type {
+	case forwardJoin:
+		if caller.verified || addrHasHost(req.Addr, callerHost) {
+			return nil
+		}
+		return fmt.Errorf("node %s can only join the raft KV cluster with its own address", req.NodeID)
+
+	case forwardLeave:
+		for _, s := range servers {
+			if string(s.ID) == req.NodeID && addrHasHost(string(s.Address), callerHost) {
+				return nil
+			}
+		}
+		return fmt.Errorf("node %s can only be removed from the raft KV cluster by itself", req.NodeID)
+	}
+	return nil
+}
+
+// addrHasHost returns whether the host of the <host>:<port> address is the given IP address,
+// or resolves to it.
+func addrHasHost(addr, ip string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+
+	callerIP := net.ParseIP(ip)
+	if hostIP := net.ParseIP(host); hostIP != nil {
+		return hostIP.Equal(callerIP)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), addrResolveTimeout)
+	defer cancel()
+
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return false
+	}
+	for _, resolved := range ips {
+		if resolved.IP.Equal(callerIP) {
+			return true
+		}
+	}
+	return false
+}
+
+func (k *KV) executeOnLeader(req *forwardRequest) *forwardResponse {
+	timeout := k.cfg.ApplyTimeout
+
+	switch req.Type {
+	case forwardApply:
+		f := k.raft.Apply(req.Command, timeout)
+		if err := f.Error(); err != nil {
+			return &forwardResponse{Error: err.Error()}
+		}
+
+		switch res := f.Response().(type) {
+		case *applyResult:
+			return &forwardResponse{Index: f.Index(), Conflict: res.conflict, Value: res.value, Version: res.version}
+		case error:
+			return &forwardResponse{Error: res.Error()}
+		}
+		return &forwardResponse{Index: f.Index()}
+
+	case forwardReadIndex:
+		// The barrier guarantees that all the entries committed so far are applied to the FSM,
+		// and that this node is still the leader.
+		if err := k.raft.Barrier(timeout).Error(); err != nil {
+			return &forwardResponse{Error: err.Error()}
+		}
+		return &forwardResponse{Index: k.fsm.lastIndex()}
+
+	case forwardJoin:
+		if req.NodeID == "" || req.Addr == "" {
+			return &forwardResponse{Error: "node ID and address are required to join"}
+		}
+
+		if err := k.raft.AddNonvoter(raft.ServerID(req.NodeID), raft.ServerAddress(req.Addr), 0, timeout).Error(); err != nil {
+			return &forwardResponse{Error: err.Error()}
+		}
+		level.Info(k.logger).Log("msg", "node joined raft KV cluster", "node_id", req.NodeID, "addr", req.Addr)
+		return &forwardResponse{}
+
+	case forwardLeave:
+		if err := k.raft.RemoveServer(raft.ServerID(req.NodeID), 0, timeout).Error(); err != nil {
+			return &forwardResponse{Error: err.Error()}
+		}
+		level.Info(k.logger).Log("msg", "node left raft KV cluster", "node_id", req.NodeID)
+		return &forwardResponse{}
+	}
+
+	return &forwardResponse{Error: fmt.Sprintf("unknown request type: %s", req.Type)}
+}
+
+// apply replicates the command, and waits until it's applied to the local FSM too, unless it conflicted.
+func (k *KV) apply(ctx context.Context, cmd command) (*forwardResponse, error) {
+	data, err := json.Marshal(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := k.execute(ctx, &forwardRequest{Type: forwardApply, Command: data})
+	if err != nil || resp.Conflict {
+		return resp, err
+	}
+
+	return resp, k.fsm.waitForIndex(ctx, resp.Index)
+}
+
+// sync waits until the local FSM has applied all the entries committed by the leader so far,
+// so that reads done after sync returns are linearizable.
+func (k *KV) sync(ctx context.Context) error {
+	resp, err := k.execute(ctx, &forwardRequest{Type: forwardReadIndex})
+	if err != nil {
+		return err
+	}
+
+	return k.fsm.waitForIndex(ctx, resp.Index)
+}
+
+// List returns the keys with the given prefix.
+func (k *KV) List(ctx context.Context, prefix string) ([]string, error) {
+	if err := k.sync(ctx); err != nil {
+		return nil, err
+	}
+
+	return k.fsm.list(prefix), nil
+}
+
+// Get returns the value stored under key, decoded with the codec, or nil if the key doesn't exist.
+func (k *KV) Get(ctx context.Context, key string, codec codec.Codec) (any, error) {
+	if err := k.sync(ctx); err != nil {
+		return nil, err
+	}
+
+	val, _, err := k.get(key, codec)
+	return val, err
+}
+
+func (k *KV) get(key string, codec codec.Codec) (any, uint64, error) {
+	data, version := k.fsm.get(key)
+	if version == 0 {
+		return nil, 0, nil
+	}
+
+	val, err := codec.Decode(data)
+	return val, version, err
+}
+
+// Delete removes the key. It's not an error if the key doesn't exist.
+func (k *KV) Delete(ctx context.Context, key string) error {
+	_, err := k.apply(ctx, command{Op: opDelete, Key: key})
+	return err
+}
+
+// CAS implements Compare-And-Set/Swap operation.
+//
+// Function 'f' is called with the current value, read after catching up with the leader. The value returned
+// by 'f' is stored only if the key hasn't been modified in the meantime, otherwise 'f' is called again with the
+// newer value returned by the leader. After too many failed retries, this method returns error.
+func (k *KV) CAS(ctx context.Context, key string, codec codec.Codec, f func(in any) (out any, retry bool, err error)) error {
+	var (
+		lastError error
+
+		// Current value and version of the key, read locally after catching up with the leader,
+		// or returned by the leader when the previous attempt conflicted.
+		current []byte
+		version uint64
+		fresh   bool
+	)
+
+	for retries := maxCasRetries; retries > 0 && ctx.Err() == nil; retries-- {
+		k.metrics.casAttempts.Inc()
+
+		if !fresh {
+			if err := k.sync(ctx); err != nil {
+				lastError = err
+				continue
+			}
+			current, version = k.fsm.get(key)
+		}
+		fresh = false
+
+		var in any
+		if version != 0 {
+			var err error
+			if in, err = codec.Decode(current); err != nil {
+				level.Error(k.logger).Log("msg", "error decoding key", "key", key, "err", err)
+				lastError = err
+				continue
+			}
+		}
+
+		out, retry, err := f(in)
+		if err != nil {
+			if !retry {
+				k.metrics.casFailures.Inc()
+				return err
+			}
+			lastError = err
+			continue
+		}
+
+		// Treat the callback returning nil as a decision to not actually write, but this is not an error.
+		if out == nil {
+			k.metrics.casSuccesses.Inc()
+			return nil
+		}
+
+		data, err := codec.Encode(out)
+		if err != nil {
+			level.Error(k.logger).Log("msg", "error serialising value", "key", key, "err", err)
+			lastError = err
+			continue
+		}
+
+		resp, err := k.apply(ctx, command{Op: opCAS, Key: key, Value: data, Version: version})
+		if err != nil {
+			level.Warn(k.logger).Log("msg", "error CASing key", "key", key, "err", err)
+			lastError = err
+			continue
+		}
+
+		if resp.Conflict {
+			// Retry with the value the leader returned, after a random delay so that concurrent
+			// writers don't keep conflicting.
+			k.metrics.casConflicts.Inc()
+			lastError = fmt.Errorf("key %s was modified concurrently", key)
+			current, version, fresh = resp.Value, resp.Version, true
+
+			select {
+			case <-time.After(time.Duration(rand.Int63n(int64(casRetryDelay)))):
+			case <-ctx.Done():
+			}
+			continue
+		}
+
+		k.metrics.casSuccesses.Inc()
+		return nil
+	}
+
+	k.metrics.casFailures.Inc()
+	if ctx.Err() != nil {
+		lastError = ctx.Err()
+	}
+	return fmt.Errorf("failed to CAS-update key %s: %w", key, lastError)
+}
+
+// WatchKey watches for value changes for given key. When value changes, 'f' function is called with the
+// latest value. Notifications that arrive while 'f' is running are coalesced into one subsequent 'f' call.
+//
+// Watching ends when 'f' returns false, context is done, or this KV is shut down.
+func (k *KV) WatchKey(ctx context.Context, key string, codec codec.Codec, f func(any) bool) {
+	// keep one extra notification, to avoid missing notification if we're busy running the function
+	w := make(chan string, 1)
+
+	// register watcher
+	k.watchersMu.Lock()
+	k.watchers[key] = append(k.watchers[key], w)
+	k.watchersMu.Unlock()
+
+	defer func() {
+		// unregister watcher on exit
+		k.watchersMu.Lock()
+		defer k.watchersMu.Unlock()
+
+		removeWatcherChannel(key, w, k.watchers)
+	}()
+
+	for {
+		select {
+		case <-w:
+			// value changed
+			val, _, err := k.get(key, codec)
+			if err != nil {
+				level.Warn(k.logger).Log("msg", "failed to decode value while watching for changes", "key", key, "err", err)
+				continue
+			}
+
+			if !f(val) {
+				return
+			}
+
+		case <-k.shutdown:
+			// stop watching on shutdown
+			return
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// WatchPrefix watches for any change of values stored under keys with given prefix. When change occurs,
+// function 'f' is called with key and current value.
+// Each change of the key results in one notification. If there are too many pending notifications ('f' is slow),
+// some notifications may be lost.
+//
+// Watching ends when 'f' returns false, context is done, or this KV is shut down.
+func (k *KV) WatchPrefix(ctx context.Context, prefix string, codec codec.Codec, f func(string, any) bool) {
+	// we use bigger buffer here, since keys are interesting and we don't want to lose them.
+	w := make(chan string, 16)
+
+	// register watcher
+	k.watchersMu.Lock()
+	k.prefixWatchers[prefix] = append(k.prefixWatchers[prefix], w)
+	k.watchersMu.Unlock()
+
+	defer func() {
+		// unregister watcher on exit
+		k.watchersMu.Lock()
+		defer k.watchersMu.Unlock()
+
+		removeWatcherChannel(prefix, w, k.prefixWatchers)
+	}()
+
+	for {
+		select {
+		case key := <-w:
+			val, _, err := k.get(key, codec)
+			if err != nil {
+				level.Warn(k.logger).Log("msg", "failed to decode value while watching for changes", "key", key, "err", err)
+				continue
+			}
+
+			if val == nil {
+				// Skip nil that can be returned when the key is deleted.
+				continue
+			}
+
+			if !f(key, val) {
+				return
+			}
+
+		case <-k.shutdown:
+			// stop watching on shutdown
+			return
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func removeWatcherChannel(k string, w chan string, watchers map[string][]chan string) {
+	ws := watchers[k]
+	for ix, kw := range ws {
+		if kw == w {
+			ws = append(ws[:ix], ws[ix+1:]...)
+			break
+		}
+	}
+
+	if len(ws) > 0 {
+		watchers[k] = ws
+	} else {
+		delete(watchers, k)
+	}
+}
+
+func (k *KV) notifyWatchers(key string) {
+	k.watchersMu.Lock()
+	defer k.watchersMu.Unlock()
+
+	for _, kw := range k.watchers[key] {
+		select {
+		case kw <- key:
+			// notification sent.
+		default:
+			// cannot send notification to this watcher at the moment
+			// but since this is a buffered channel, it means that
+			// there is already a pending notification anyway
+		}
+	}
+
+	for p, ws := range k.prefixWatchers {
+		if strings.HasPrefix(key, p) {
+			for _, pw := range ws {
+				select {
+				case pw <- key:
+					// notification sent.
+				default:
+					level.Warn(k.logger).Log("msg", "failed to send notification to prefix watcher", "prefix", p)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,442 @@
+package raft
+
+import (
+	"context"
+	stdtls "crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/hashicorp/raft"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+
+	"github.com/cortexproject/cortex/integration/ca"
+	"github.com/cortexproject/cortex/pkg/ring/kv/codec"
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+	"github.com/cortexproject/cortex/pkg/util/services"
+	"github.com/cortexproject/cortex/pkg/util/test"
+	"github.com/cortexproject/cortex/pkg/util/tls"
+)
+
+func TestKV_ShouldReplicateLinearizableCASAcrossNodes(t *testing.T) {
+	ports := getFreePorts(t, 3)
+
+	var bootstrap []string
+	for i, port := range ports {
+		bootstrap = append(bootstrap, fmt.Sprintf("node-%d=127.0.0.1:%d", i, port))
+	}
+
+	var kvs []*KV
+	for i, port := range ports {
+		kvs = append(kvs, newTestKV(t, fmt.Sprintf("node-%d", i), func(cfg *KVConfig) {
+			cfg.BindPort = port
+			cfg.BootstrapMembers = bootstrap
+			cfg.MaxVoters = len(ports)
+		}, true))
+	}
+
+	// A non-voting member joining through any of the voters. It's not promoted, since the cluster has max voters already.
+	kvs = append(kvs, newTestKV(t, "node-3", func(cfg *KVConfig) {
+		cfg.JoinMembers = flagext.StringSlice{fmt.Sprintf("127.0.0.1:%d", ports[1])}
+	}, true))
+
+	test.Poll(t, 5*time.Second, 4, func() any {
+		return len(clusterMembers(kvs[0]))
+	})
+	assert.Equal(t, raft.Nonvoter, clusterMembers(kvs[0])["node-3"])
+
+	increment := func(in any) (out any, retry bool, err error) {
+		current := 0
+		if in != nil {
+			current, _ = strconv.Atoi(in.(string))
+		}
+		return strconv.Itoa(current + 1), true, nil
+	}
+
+	// Each node sees the writes done through the other nodes right away.
+	for i := range 2 * len(kvs) {
+		client := NewClient(kvs[i%len(kvs)], codec.String{})
+
+		val, err := client.Get(context.Background(), "counter")
+		require.NoError(t, err)
+		if i == 0 {
+			assert.Nil(t, val)
+		} else {
+			assert.Equal(t, strconv.Itoa(i), val)
+		}
+
+		require.NoError(t, client.CAS(context.Background(), "counter", increment))
+	}
+
+	// Increment the counter concurrently from all nodes: each successful CAS must increment it exactly once.
+	const incrementsPerNode = 5
+
+	successful := atomic.NewInt64(int64(2 * len(kvs)))
+	wg := sync.WaitGroup{}
+	for _, kv := range kvs {
+		client := NewClient(kv, codec.String{})
+
+		wg.Go(func() {
+			for range incrementsPerNode {
+				if client.CAS(context.Background(), "counter", increment) == nil {
+					successful.Inc()
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	for _, kv := range kvs {
+		val, err := NewClient(kv, codec.String{}).Get(context.Background(), "counter")
+		require.NoError(t, err)
+		assert.Equal(t, strconv.FormatInt(successful.Load(), 10), val)
+	}
+
+	// The non-voting member leaves the cluster when stopping.
+	require.NoError(t, services.StopAndAwaitTerminated(context.Background(), kvs[3]))
+	test.Poll(t, 5*time.Second, 3, func() any {
+		return len(clusterMembers(kvs[0]))
+	})
+}
+
+func TestKV_ShouldWatchChangesAppliedByOtherNodes(t *testing.T) {
+	leader := newTestKV(t, "node-0", bootstrapSingleNode(t, "node-0"), true)
+	follower := newTestKV(t, "node-1", func(cfg *KVConfig) {
+		cfg.JoinMembers = flagext.StringSlice{string(leader.transport.LocalAddr())}
+	}, true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	observed := make(chan string, 10)
+	go NewClient(follower, codec.String{}).WatchPrefix(ctx, "ring/", func(key string, val any) bool {
+		observed <- key + "=" + val.(string)
+		return true
+	})
+
+	// Wait until the watcher is registered.
+	test.Poll(t, time.Second, 1, func() any {
+		follower.watchersMu.Lock()
+		defer follower.watchersMu.Unlock()
+		return len(follower.prefixWatchers)
+	})
+
+	client := NewClient(leader, codec.String{})
+	require.NoError(t, client.CAS(ctx, "ring/a", func(any) (any, bool, error) { return "1", true, nil }))
+	require.NoError(t, client.CAS(ctx, "other", func(any) (any, bool, error) { return "2", true, nil }))
+
+	select {
+	case v := <-observed:
+		assert.Equal(t, "ring/a=1", v)
+	case <-time.After(5 * time.Second):
+		t.Fatal("change not observed")
+	}
+
+	// Deletes are replicated too.
+	require.NoError(t, client.Delete(ctx, "ring/a"))
+	keys, err := NewClient(follower, codec.String{}).List(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"other"}, keys)
+}
+
+func TestKV_ShouldRestoreStateFromSnapshotOnRestart(t *testing.T) {
+	dir := t.TempDir()
+	bootstrap := bootstrapSingleNode(t, "node-0")
+	setDir := func(cfg *KVConfig) {
+		bootstrap(cfg)
+		cfg.DataDir = dir
+	}
+
+	kv := newTestKV(t, "node-0", setDir, false)
+	client := NewClient(kv, codec.String{})
+	for i := range 5 {
+		key := fmt.Sprintf("key-%d", i)
+		require.NoError(t, client.CAS(context.Background(), key, func(any) (any, bool, error) { return key, true, nil }))
+	}
+
+	require.NoError(t, kv.raft.Snapshot().Error())
+	assert.Equal(t, float64(1), testutil.ToFloat64(kv.metrics.snapshots))
+
+	// Entries applied after the snapshot are replayed from the log.
+	require.NoError(t, client.Delete(context.Background(), "key-0"))
+	require.NoError(t, services.StopAndAwaitTerminated(context.Background(), kv))
+
+	restarted := newTestKV(t, "node-0", setDir, true)
+	assert.Equal(t, float64(1), testutil.ToFloat64(restarted.metrics.restores))
+
+	keys, err := NewClient(restarted, codec.String{}).List(context.Background(), "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"key-1", "key-2", "key-3", "key-4"}, keys)
+}
+
+func TestKV_CASShouldFailOnConflictingVersion(t *testing.T) {
+	kv := newTestKV(t, "node-0", bootstrapSingleNode(t, "node-0"), true)
+	ctx := context.Background()
+
+	resp, err := kv.apply(ctx, command{Op: opCAS, Key: "key", Value: []byte("a")})
+	require.NoError(t, err)
+	assert.False(t, resp.Conflict)
+
+	// The key exists now, so it can't be created again.
+	resp, err = kv.apply(ctx, command{Op: opCAS, Key: "key", Value: []byte("b")})
+	require.NoError(t, err)
+	assert.True(t, resp.Conflict)
+
+	_, version := kv.fsm.get("key")
+	resp, err = kv.apply(ctx, command{Op: opCAS, Key: "key", Value: []byte("c"), Version: version})
+	require.NoError(t, err)
+	assert.False(t, resp.Conflict)
+
+	val, _ := kv.fsm.get("key")
+	assert.Equal(t, []byte("c"), val)
+}
+
+func TestKV_ShouldRequireBootstrapOrJoinMembersToStartANewCluster(t *testing.T) {
+	cfg := KVConfig{}
+	flagext.DefaultValues(&cfg)
+	cfg.NodeID = "node-0"
+	cfg.BindAddr = "127.0.0.1"
+	cfg.BindPort = 0
+	cfg.DataDir = t.TempDir()
+
+	kv := NewKV(cfg, log.NewNopLogger())
+	err := services.StartAndAwaitRunning(context.Background(), kv)
+	require.ErrorContains(t, err, "is not a bootstrap member, and no join members are configured")
+}
+
+func TestKV_ShouldPromoteHealthyNonvotersUpToMaxVoters(t *testing.T) {
+	leader := newTestKV(t, "node-0", func(cfg *KVConfig) {
+		bootstrapSingleNode(t, "node-0")(cfg)
+		cfg.MaxVoters = 2
+	}, true)
+
+	join := func(cfg *KVConfig) {
+		cfg.JoinMembers = flagext.StringSlice{string(leader.transport.LocalAddr())}
+	}
+	newTestKV(t, "node-1", join, true)
+	test.Poll(t, 5*time.Second, map[raft.ServerID]raft.ServerSuffrage{"node-0": raft.Voter, "node-1": raft.Voter}, func() any {
+		return clusterMembers(leader)
+	})
+
+	// The cluster has max voters already.
+	newTestKV(t, "node-2", join, true)
+	test.Poll(t, 5*time.Second, 3, func() any {
+		return len(clusterMembers(leader))
+	})
+	time.Sleep(3 * leader.checkInterval)
+
+	assert.Equal(t, raft.Nonvoter, clusterMembers(leader)["node-2"])
+	assert.Equal(t, float64(1), testutil.ToFloat64(leader.metrics.promotedNonvoters))
+}
+
+func TestKV_ShouldOnlyAcceptMutualTLSConnectionsWhenTLSIsEnabled(t *testing.T) {
+	dir := t.TempDir()
+
+	clusterCA := ca.New("Raft KV CA")
+	caFile := filepath.Join(dir, "ca.crt")
+	require.NoError(t, clusterCA.WriteCACertificate(caFile))
+
+	certFile, keyFile := filepath.Join(dir, "node.crt"), filepath.Join(dir, "node.key")
+	require.NoError(t, clusterCA.WriteCertificate(&x509.Certificate{
+		Subject:     pkix.Name{CommonName: "node"},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}, certFile, keyFile))
+
+	nodeTLS := tls.ClientConfig{CertPath: certFile, KeyPath: keyFile, CAPath: caFile}
+	enableTLS := func(cfg *KVConfig) {
+		cfg.TLSEnabled = true
+		cfg.TLS = nodeTLS
+	}
+
+	leader := newTestKV(t, "node-0", func(cfg *KVConfig) {
+		bootstrapSingleNode(t, "node-0")(cfg)
+		enableTLS(cfg)
+	}, true)
+	follower := newTestKV(t, "node-1", func(cfg *KVConfig) {
+		cfg.JoinMembers = flagext.StringSlice{string(leader.transport.LocalAddr())}
+		enableTLS(cfg)
+	}, true)
+
+	client := NewClient(follower, codec.String{})
+	require.NoError(t, client.CAS(context.Background(), "key", func(any) (any, bool, error) { return "value", true, nil }))
+	val, err := NewClient(leader, codec.String{}).Get(context.Background(), "key")
+	require.NoError(t, err)
+	assert.Equal(t, "value", val)
+
+	// Neither plaintext clients nor clients without a certificate signed by the CA can change the store.
+	leaderAddr := string(leader.transport.LocalAddr())
+	join := &forwardRequest{Type: forwardJoin, NodeID: "intruder", Addr: "127.0.0.1:1"}
+
+	withoutCert, err := (&tls.ClientConfig{CAPath: caFile}).GetTLSConfig()
+	require.NoError(t, err)
+
+	for name, tlsConfig := range map[string]*stdtls.Config{"plaintext": nil, "without client certificate": withoutCert} {
+		t.Run(name, func(t *testing.T) {
+			m := newMuxListener(nil, nil, tlsConfig, time.Second, nil, log.NewNopLogger())
+			_, err := m.sendForwardRequest(context.Background(), leaderAddr, join)
+			require.Error(t, err)
+		})
+	}
+	assert.NotContains(t, clusterMembers(leader), raft.ServerID("intruder"))
+}
+
+func TestKV_ShouldOnlyAcceptMembershipChangesFromTheNodeItself(t *testing.T) {
+	leader := newTestKV(t, "node-0", bootstrapSingleNode(t, "node-0"), true)
+	leaderAddr := string(leader.transport.LocalAddr())
+	newTestKV(t, "node-1", func(cfg *KVConfig) {
+		cfg.JoinMembers = flagext.StringSlice{leaderAddr}
+	}, true)
+	test.Poll(t, 5*time.Second, 2, func() any {
+		return len(clusterMembers(leader))
+	})
+
+	// The loopback addresses other than 127.0.0.1, which the nodes listen on, act as other hosts.
+	// The test cases run in order, since the last one removes node-1.
+	tests := []struct {
+		name        string
+		from        string
+		req         *forwardRequest
+		expectedErr string
+	}{
+		{
+			name:        "a node can't remove another node",
+			from:        "127.0.0.2",
+			req:         &forwardRequest{Type: forwardLeave, NodeID: "node-1", Forwarded: true},
+			expectedErr: "node node-1 can only be removed from the raft KV cluster by itself",
+		},
+		{
+			name:        "a node can't join with the address of another host",
+			from:        "127.0.0.2",
+			req:         &forwardRequest{Type: forwardJoin, NodeID: "intruder", Addr: "127.0.0.3:1"},
+			expectedErr: "node intruder can only join the raft KV cluster with its own address",
+		},
+		{
+			name:        "a node which isn't a member can't relay a membership change",
+			from:        "127.0.0.2",
+			req:         &forwardRequest{Type: forwardLeave, NodeID: "node-1", Forwarded: true, Relayed: true},
+			expectedErr: "membership changes can only be relayed by the members of the raft KV cluster",
+		},
+		{
+			name: "a node can remove itself",
+			from: "127.0.0.1",
+			req:  &forwardRequest{Type: forwardLeave, NodeID: "node-1", Forwarded: true},
+		},
+	}
+
+	for _, testData := range tests {
+		t.Run(testData.name, func(t *testing.T) {
+			resp := sendForwardRequestFrom(t, testData.from, leaderAddr, testData.req)
+			assert.Equal(t, testData.expectedErr, resp.Error)
+		})
+	}
+
+	assert.Equal(t, map[raft.ServerID]raft.ServerSuffrage{"node-0": raft.Voter}, clusterMembers(leader))
+}
+
+func TestKVConfig_Validate(t *testing.T) {
+	cfg := KVConfig{}
+	flagext.DefaultValues(&cfg)
+	require.NoError(t, cfg.Validate())
+
+	cfg.BootstrapMembers = flagext.StringSlice{"node-0=127.0.0.1:7947", "node-1=127.0.0.2:7947"}
+	require.NoError(t, cfg.Validate())
+
+	cfg.BootstrapMembers = flagext.StringSlice{"127.0.0.1:7947"}
+	require.ErrorContains(t, cfg.Validate(), "expected format is <node-id>=<host>:<port>")
+
+	cfg.BootstrapMembers = flagext.StringSlice{"node-0=127.0.0.1"}
+	require.ErrorContains(t, cfg.Validate(), "invalid raft KV bootstrap member")
+
+	cfg.BootstrapMembers = nil
+	cfg.MaxVoters = -1
+	require.ErrorContains(t, cfg.Validate(), "the raft KV max voters must not be negative")
+}
+
+func newTestKV(t *testing.T, nodeID string, modify func(cfg *KVConfig), stopOnCleanup bool) *KV {
+	cfg := KVConfig{}
+	flagext.DefaultValues(&cfg)
+	cfg.NodeID = nodeID
+	cfg.BindAddr = "127.0.0.1"
+	cfg.BindPort = 0
+	cfg.DataDir = t.TempDir()
+	cfg.MinJoinBackoff = 100 * time.Millisecond
+	cfg.MetricsRegisterer = prometheus.NewPedanticRegistry()
+	if modify != nil {
+		modify(&cfg)
+	}
+
+	kv := NewKV(cfg, log.NewNopLogger())
+	kv.checkInterval = 100 * time.Millisecond
+	if stopOnCleanup {
+		t.Cleanup(func() {
+			_ = services.StopAndAwaitTerminated(context.Background(), kv)
+		})
+	}
+
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), kv))
+	return kv
+}
+
+// bootstrapSingleNode configures the node to bootstrap a cluster made of itself only.
+func bootstrapSingleNode(t *testing.T, nodeID string) func(cfg *KVConfig) {
+	port := getFreePorts(t, 1)[0]
+
+	return func(cfg *KVConfig) {
+		cfg.BindPort = port
+		cfg.BootstrapMembers = flagext.StringSlice{fmt.Sprintf("%s=127.0.0.1:%d", nodeID, port)}
+	}
+}
+
+func clusterMembers(kv *KV) map[raft.ServerID]raft.ServerSuffrage {
+	f := kv.raft.GetConfiguration()
+	if f.Error() != nil {
+		return nil
+	}
+
+	members := map[raft.ServerID]raft.ServerSuffrage{}
+	for _, s := range f.Configuration().Servers {
+		members[s.ID] = s.Suffrage
+	}
+	return members
+}
+
+func getFreePorts(t *testing.T, n int) []int {
+	ports := make([]int, 0, n)
+	for range n {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		ports = append(ports, l.Addr().(*net.TCPAddr).Port)
+		require.NoError(t, l.Close())
+	}
+	return ports
+}
+
+// sendForwardRequestFrom sends the request to the node at addr from the local IP address.
+func sendForwardRequestFrom(t *testing.T, localIP, addr string, req *forwardRequest) *forwardResponse {
+	d := net.Dialer{LocalAddr: &net.TCPAddr{IP: net.ParseIP(localIP)}, Timeout: time.Second}
+	conn, err := d.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+	_, err = conn.Write([]byte{connTypeForward})
+	require.NoError(t, err)
+	require.NoError(t, json.NewEncoder(conn).Encode(req))
+
+	var resp forwardResponse
+	require.NoError(t, json.NewDecoder(conn).Decode(&resp))
+	return &resp
+}
@@ -0,0 +1,55 @@
+package raft
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/hashicorp/go-hclog"
+)
+
+// newRaftLogger returns a hclog.Logger, used by the Raft library, which writes to the given logger.
+func newRaftLogger(logger log.Logger) hclog.Logger {
+	return hclog.New(&hclog.LoggerOptions{
+		Name:        "raft",
+		Level:       hclog.Debug,
+		Output:      loggerAdapter{Logger: logger},
+		DisableTime: true,
+	})
+}
+
+// loggerAdapter parses the lines produced by hclog (esp. level), and logs them to the wrapped Logger.
+// Timestamps are disabled, since pkg/util/log.Logger is set up to include them already.
+type loggerAdapter struct {
+	log.Logger
+}
+
+// [INFO]  raft: entering follower state: follower="Node at 127.0.0.1:7947 [Follower]" leader-address= leader-id=
+var logRegexp = regexp.MustCompile(`^\[(?P<level>[A-Z]+)\]\s+(?:raft: )?(?P<msg>.*)$`)
+
+func (a loggerAdapter) Write(p []byte) (int, error) {
+	line := strings.TrimSpace(string(p))
+
+	lvl, msg := "", line
+	if m := logRegexp.FindStringSubmatch(line); m != nil {
+		lvl, msg = m[1], m[2]
+	}
+
+	var logger log.Logger
+	switch strings.ToLower(lvl) {
+	case "trace", "debug":
+		logger = level.Debug(a.Logger)
+	case "warn":
+		logger = level.Warn(a.Logger)
+	case "error":
+		logger = level.Error(a.Logger)
+	default:
+		logger = level.Info(a.Logger)
+	}
+
+	if err := logger.Log("msg", msg); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,275 @@
+package raft
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/hashicorp/raft"
+)
+
+// The first byte sent on every connection tells which protocol the connection is used for.
+// This allows Raft replication and requests forwarded to the leader to share a single port.
+const (
+	connTypeRaft    byte = 1
+	connTypeForward byte = 2
+)
+
+var errTransportClosed = errors.New("raft KV transport is closed")
+
+type forwardRequestType string
+
+const (
+	forwardApply     forwardRequestType = "apply"
+	forwardReadIndex forwardRequestType = "read_index"
+	forwardJoin      forwardRequestType = "join"
+	forwardLeave     forwardRequestType = "leave"
+)
+
+// forwardRequest is sent by a node to the leader (directly, or through any other node when joining)
+// for the operations which can only be run by the leader.
+type forwardRequest struct {
+	Type forwardRequestType `json:"type"`
+
+	// Command to apply, for forwardApply.
+	Command []byte `json:"command,omitempty"`
+
+	// Node to add or remove, for forwardJoin and forwardLeave.
+	NodeID string `json:"node_id,omitempty"`
+	Addr   string `json:"addr,omitempty"`
+
+	// Forwarded is set once the request has been sent to a node believed to be the leader,
+	// so that it doesn't bounce around the cluster while the leadership is changing.
+	Forwarded bool `json:"forwarded,omitempty"`
+
+	// Relayed is set when a member sends the request to the leader on behalf of the node which
+	// contacted it, so that the leader checks the member instead of the node.
+	Relayed bool `json:"relayed,omitempty"`
+}
+
+// forwardCaller describes the node which sent a forwarded request.
+type forwardCaller struct {
+	// Remote address of the connection.
+	addr net.Addr
+
+	// Whether the caller presented a client certificate signed by the configured CA.
+	verified bool
+}
+
+type forwardResponse struct {
+	// Index of the applied command for forwardApply, and index of the last command
+	// applied by the leader for forwardReadIndex.
+	Index    uint64 `json:"index"`
+	Conflict bool   `json:"conflict,omitempty"`
+	Error    string `json:"error,omitempty"`
+
+	// Current value and version of the key, when forwardApply conflicted.
+	Value   []byte `json:"value,omitempty"`
+	Version uint64 `json:"version,omitempty"`
+}
+
+// muxListener accepts connections on the Raft KV port, and dispatches them either to Raft or
+// to the handler of forwarded requests, depending on the first byte received.
+// When TLS is enabled, the connections are encrypted both ways, and if a CA is configured the
+// clients must present a certificate signed by it too, since any client can change the KV store.
+type muxListener struct {
+	listener  net.Listener
+	advertise net.Addr
+	timeout   time.Duration
+	logger    log.Logger
+
+	// nil if TLS is disabled.
+	clientTLS *tls.Config
+	serverTLS *tls.Config
+
+	handler func(*forwardRequest, forwardCaller) *forwardResponse
+
+	raftConns chan net.Conn
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+func newMuxListener(listener net.Listener, advertise net.Addr, tlsConfig *tls.Config, timeout time.Duration, handler func(*forwardRequest, forwardCaller) *forwardResponse, logger log.Logger) *muxListener {
+	m := &muxListener{
+		listener:  listener,
+		advertise: advertise,
+		timeout:   timeout,
+		logger:    logger,
+		clientTLS: tlsConfig,
+		handler:   handler,
+		raftConns: make(chan net.Conn),
+		done:      make(chan struct{}),
+	}
+
+	if tlsConfig != nil {
+		m.serverTLS = tlsConfig.Clone()
+		if m.serverTLS.RootCAs != nil {
+			m.serverTLS.ClientCAs = m.serverTLS.RootCAs
+			m.serverTLS.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	}
+	return m
+}
+
+func (m *muxListener) serve() {
+	for {
+		conn, err := m.listener.Accept()
+		if err != nil {
+			select {
+			case <-m.done:
+				return
+			default:
+			}
+
+			level.Warn(m.logger).Log("msg", "failed to accept connection", "err", err)
+			continue
+		}
+
+		if m.serverTLS != nil {
+			// The handshake is done on the first read, within the dispatch deadline.
+			conn = tls.Server(conn, m.serverTLS)
+		}
+
+		go m.dispatch(conn)
+	}
+}
+
+func (m *muxListener) dispatch(conn net.Conn) {
+	connType := []byte{0}
+	_ = conn.SetReadDeadline(time.Now().Add(m.timeout))
+	if _, err := conn.Read(connType); err != nil {
+		level.Debug(m.logger).Log("msg", "failed to read connection type", "remote", conn.RemoteAddr(), "err", err)
+		_ = conn.Close()
+		return
+	}
+	_ = conn.SetReadDeadline(time.Time{})
+
+	switch connType[0] {
+	case connTypeRaft:
+		select {
+		case m.raftConns <- conn:
+		case <-m.done:
+			_ = conn.Close()
+		}
+
+	case connTypeForward:
+		defer conn.Close()
+		m.handleForward(conn)
+
+	default:
+		level.Warn(m.logger).Log("msg", "unknown connection type", "remote", conn.RemoteAddr(), "type", connType[0])
+		_ = conn.Close()
+	}
+}
+
+func (m *muxListener) handleForward(conn net.Conn) {
+	var req forwardRequest
+	_ = conn.SetReadDeadline(time.Now().Add(m.timeout))
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		level.Warn(m.logger).Log("msg", "failed to decode forwarded request", "remote", conn.RemoteAddr(), "err", err)
+		return
+	}
+
+	caller := forwardCaller{addr: conn.RemoteAddr()}
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		caller.verified = len(tlsConn.ConnectionState().VerifiedChains) > 0
+	}
+
+	resp := m.handler(&req, caller)
+
+	_ = conn.SetWriteDeadline(time.Now().Add(m.timeout))
+	if err := json.NewEncoder(conn).Encode(resp); err != nil {
+		level.Warn(m.logger).Log("msg", "failed to send response to forwarded request", "remote", conn.RemoteAddr(), "err", err)
+	}
+}
+
+// Accept is part of raft.StreamLayer interface.
+func (m *muxListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-m.raftConns:
+		return conn, nil
+	case <-m.done:
+		return nil, errTransportClosed
+	}
+}
+
+// Close is part of raft.StreamLayer interface. It's called when the Raft transport is closed.
+func (m *muxListener) Close() error {
+	var err error
+	m.closeOnce.Do(func() {
+		close(m.done)
+		err = m.listener.Close()
+	})
+	return err
+}
+
+// Addr is part of raft.StreamLayer interface. It returns the address advertised to the other nodes.
+func (m *muxListener) Addr() net.Addr {
+	return m.advertise
+}
+
+// Dial is part of raft.StreamLayer interface.
+func (m *muxListener) Dial(address raft.ServerAddress, timeout time.Duration) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	conn, err := m.dial(ctx, string(address))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := conn.Write([]byte{connTypeRaft}); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (m *muxListener) dial(ctx context.Context, addr string) (net.Conn, error) {
+	if m.clientTLS != nil {
+		d := tls.Dialer{Config: m.clientTLS}
+		return d.DialContext(ctx, "tcp", addr)
+	}
+
+	var d net.Dialer
+	return d.DialContext(ctx, "tcp", addr)
+}
+
+// sendForwardRequest sends the request to the node at addr, and waits for its response.
+func (m *muxListener) sendForwardRequest(ctx context.Context, addr string, req *forwardRequest) (*forwardResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	conn, err := m.dial(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if _, err := conn.Write([]byte{connTypeForward}); err != nil {
+		return nil, err
+	}
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, err
+	}
+
+	var resp forwardResponse
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to read response from %s: %w", addr, err)
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("%s: %s", addr, resp.Error)
+	}
+	return &resp, nil
+}
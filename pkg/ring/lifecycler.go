@@ -46,6 +46,8 @@ type LifecyclerConfig struct {
 	// Config for the ingester lifecycle control
 	NumTokens                int           `yaml:"num_tokens"`
 	TokensGeneratorStrategy  string        `yaml:"tokens_generator_strategy"`
+	TokensMigrationInterval  time.Duration `yaml:"tokens_migration_interval"`
+	TokensMigrationStep      int           `yaml:"tokens_migration_step"`
 	HeartbeatPeriod          time.Duration `yaml:"heartbeat_period"`
 	ObservePeriod            time.Duration `yaml:"observe_period"`
 	JoinAfter                time.Duration `yaml:"join_after"`
@@ -83,6 +85,8 @@ func (cfg *LifecyclerConfig) RegisterFlagsWithPrefix(prefix string, f *flag.Flag
 
 	f.IntVar(&cfg.NumTokens, prefix+"num-tokens", 128, "Number of tokens for each ingester.")
 	f.StringVar(&cfg.TokensGeneratorStrategy, prefix+"tokens-generator-strategy", randomTokenStrategy, fmt.Sprintf("EXPERIMENTAL: Algorithm used to generate new ring tokens. Supported Values: %s", strings.Join(supportedTokenStrategy, ",")))
+	f.DurationVar(&cfg.TokensMigrationInterval, prefix+"tokens-migration-interval", 0, "EXPERIMENTAL: Interval at which the ingester replaces some of its tokens with tokens generated by the minimize-spread strategy, until its ownership within its zone is balanced. The series of the replaced tokens are written to other ingesters from then on, like when scaling the ingesters. Requires the minimize-spread tokens generator strategy. 0 to disable.")
+	f.IntVar(&cfg.TokensMigrationStep, prefix+"tokens-migration-step", 8, "EXPERIMENTAL: Maximum number of tokens replaced at each tokens migration interval.")
 	f.DurationVar(&cfg.HeartbeatPeriod, prefix+"heartbeat-period", 5*time.Second, "Period at which to heartbeat to consul. 0 = disabled.")
 	f.DurationVar(&cfg.JoinAfter, prefix+"join-after", 0*time.Second, "Period to wait for a claim from another member; will join automatically after this.")
 	f.DurationVar(&cfg.ObservePeriod, prefix+"observe-period", 0*time.Second, "Observe tokens after generating to resolve collisions. Useful when using gossiping ring.")
@@ -106,11 +110,12 @@ func (cfg *LifecyclerConfig) RegisterFlagsWithPrefix(prefix string, f *flag.Flag
 }
 
 func (cfg *LifecyclerConfig) Validate() error {
-	if cfg.TokensGeneratorStrategy != "" && !slices.Contains(supportedTokenStrategy, strings.ToLower(cfg.TokensGeneratorStrategy)) {
-		return errInvalidTokensGeneratorStrategy
+	tokensGenerator := TokensGeneratorConfig{
+		Strategy:          cfg.TokensGeneratorStrategy,
+		MigrationInterval: cfg.TokensMigrationInterval,
+		MigrationStep:     cfg.TokensMigrationStep,
 	}
-
-	return nil
+	return tokensGenerator.Validate()
 }
 
 // Lifecycler is responsible for managing the lifecycle of entries in the ring.
@@ -146,6 +151,11 @@ type Lifecycler struct {
 	tokenFile    *TokenFile
 	registeredAt time.Time
 
+	// Whether the lifecycler has written its instance to the KV store since it started. Changes made
+	// through the ring admin API before then are left over by a previous run and aren't adopted.
+	// Only accessed by the lifecycler loop.
+	instanceWritten bool
+
 	// Controls the ready-reporting
 	readyLock  sync.Mutex
 	ready      bool
@@ -577,6 +587,14 @@ func (i *Lifecycler) loop(ctx context.Context) error {
 		startHeartbeat()
 	}
 
+	var tokensMigrationTickerChan <-chan time.Time
+	if i.cfg.TokensMigrationInterval > 0 && i.cfg.TokensMigrationStep > 0 {
+		tokensMigrationTicker := time.NewTicker(i.cfg.TokensMigrationInterval)
+		defer tokensMigrationTicker.Stop()
+
+		tokensMigrationTickerChan = tokensMigrationTicker.C
+	}
+
 	for {
 		select {
 		case <-i.autojoinChan:
@@ -641,6 +659,8 @@ func (i *Lifecycler) loop(ctx context.Context) error {
 
 		case <-heartbeatTickerChan:
 			i.heartbeat(ctx)
+		case <-tokensMigrationTickerChan:
+			i.migrateTokens(ctx)
 		case f := <-i.actorChan:
 			f()
 
@@ -683,7 +703,7 @@ func (i *Lifecycler) stopping(runningError error) error {
 	// We dont need to mark us as leaving if READONLY. There is not request sent to us.
 	// Also important to avoid this change so we dont have resharding(for querier) happen when READONLY restart as we extended shard on READONLY but not on LEAVING
 	// Query also keeps calling pods on LEAVING or JOINING not causing any difference if left on READONLY
-	if i.GetState() != READONLY {
+	if state := i.GetState(); state != READONLY && state != LEAVING {
 		// Mark ourselved as Leaving so no more samples are send to us.
 		err := i.changeState(context.Background(), LEAVING)
 		if err != nil {
@@ -888,6 +908,53 @@ func (i *Lifecycler) RenewTokens(ratio float64, ctx context.Context) {
 	}
 }
 
+// migrateTokens replaces some of the instance tokens to get closer to an even ownership within
+// its zone. NB this must be called from loop()!
+func (i *Lifecycler) migrateTokens(ctx context.Context) {
+	var (
+		ringDesc *Desc
+		tokens   Tokens
+	)
+
+	err := i.KVStore.CAS(ctx, i.RingKey, func(in any) (out any, retry bool, err error) {
+		tokens = nil
+		if in == nil {
+			return nil, false, nil
+		}
+
+		ringDesc = in.(*Desc)
+		instanceDesc, ok := ringDesc.Ingesters[i.ID]
+		if !ok {
+			return nil, false, nil
+		}
+
+		migrated, ok := migrateTokens(ringDesc, i.ID, i.cfg.TokensMigrationStep, i.tg)
+		if !ok {
+			return nil, false, nil
+		}
+
+		tokens = migrated
+		instanceDesc.Tokens = migrated
+		instanceDesc.Timestamp = time.Now().Unix()
+		ringDesc.Ingesters[i.ID] = instanceDesc
+		return ringDesc, true, nil
+	})
+
+	if err != nil {
+		level.Warn(i.logger).Log("msg", "failed to migrate tokens", "ring", i.RingName, "err", err)
+		return
+	}
+	if tokens == nil {
+		return
+	}
+
+	migrated := countReplacedTokens(i.getTokens(), tokens)
+	level.Info(i.logger).Log("msg", "migrated tokens", "ring", i.RingName, "migrated", migrated)
+	i.lifecyclerMetrics.tokensMigrated.Add(float64(migrated))
+	i.setTokens(tokens)
+	i.setInstanceWritten(ringDesc)
+}
+
 // Verifies that tokens that this ingester has registered to the ring still belong to it.
 // Gossiping ring may change the ownership of tokens in case of conflicts.
 // If ingester doesn't own its tokens anymore, this method generates new tokens and puts them to the ring.
@@ -1001,6 +1068,7 @@ func (i *Lifecycler) autoJoin(ctx context.Context, targetState InstanceState, al
 
 	// Update counters
 	if err == nil {
+		i.setInstanceWritten(ringDesc)
 		i.updateCounters(ringDesc)
 	}
 
@@ -1025,6 +1093,9 @@ func (i *Lifecycler) updateConsul(ctx context.Context) error {
 			level.Info(i.logger).Log("msg", "found empty ring, inserting tokens", "ring", i.RingName)
 			ringDesc.AddIngester(i.ID, i.Addr, i.Zone, i.getTokens(), i.GetState(), i.getRegisteredAt())
 		} else {
+			i.adoptAdminChanges(instanceDesc)
+
+			instanceDesc.AdminUpdatedTimestamp = 0
 			instanceDesc.Timestamp = time.Now().Unix()
 			instanceDesc.State = i.GetState()
 			instanceDesc.Addr = i.Addr
@@ -1039,12 +1110,52 @@ func (i *Lifecycler) updateConsul(ctx context.Context) error {
 
 	// Update counters
 	if err == nil {
+		i.setInstanceWritten(ringDesc)
 		i.updateCounters(ringDesc)
+		if ringDesc != nil {
+			i.lifecyclerMetrics.ownershipSkew.Set(ringDesc.ownershipSkew()[i.ID])
+		}
 	}
 
 	return err
 }
 
+func (i *Lifecycler) setInstanceWritten(ringDesc *Desc) {
+	if ringDesc == nil {
+		return
+	}
+	if _, ok := ringDesc.Ingesters[i.ID]; ok {
+		i.instanceWritten = true
+	}
+}
+
+// adoptAdminChanges adopts the state and tokens of the instance changed in the ring through
+// the ring admin API, so that the heartbeat doesn't revert them. Only the changes marked by the
+// admin API since the lifecycler wrote its instance are adopted: the marker is reset by every
+// heartbeat and when joining the ring, so a state left in the ring by a previous run (eg. LEAVING)
+// is never adopted.
+func (i *Lifecycler) adoptAdminChanges(instanceDesc InstanceDesc) {
+	currState := i.GetState()
+	if !i.instanceWritten || instanceDesc.AdminUpdatedTimestamp == 0 || (currState != ACTIVE && currState != READONLY) {
+		return
+	}
+
+	if instanceDesc.State != currState && AdminStateTransitionAllowed(currState, instanceDesc.State) {
+		level.Info(i.logger).Log("msg", "adopting instance state changed in the ring", "old_state", currState, "new_state", instanceDesc.State, "ring", i.RingName)
+		i.setState(instanceDesc.State)
+
+		// The instance is rejoining the ring. It should reset its registered time.
+		if currState == READONLY && instanceDesc.State == ACTIVE {
+			i.setRegisteredAt(time.Now())
+		}
+	}
+
+	if len(instanceDesc.Tokens) > 0 && !tokensEqual(instanceDesc.Tokens, i.getTokens()) {
+		level.Info(i.logger).Log("msg", "adopting instance tokens changed in the ring", "tokens", len(instanceDesc.Tokens), "ring", i.RingName)
+		i.setTokens(instanceDesc.Tokens)
+	}
+}
+
 // changeState updates consul with state transitions for us.  NB this must be
 // called from loop()!  Use ChangeState for calls from outside of loop().
 func (i *Lifecycler) changeState(ctx context.Context, state InstanceState) error {
@@ -1131,6 +1242,21 @@ func (i *Lifecycler) SetUnregisterOnShutdown(enabled bool) {
 func (i *Lifecycler) processShutdown(ctx context.Context) {
 	flushRequired := i.flushOnShutdown.Load()
 
+	if flushRequired {
+		transferStart := time.Now()
+		if err := i.flushTransferer.TransferOut(ctx); err != nil {
+			if errors.Is(err, ErrTransferDisabled) {
+				level.Info(i.logger).Log("msg", "transfers are disabled", "ring", i.RingName)
+			} else {
+				level.Error(i.logger).Log("msg", "failed to transfer series to another instance, falling back to flush", "ring", i.RingName, "err", err)
+				i.lifecyclerMetrics.shutdownDuration.WithLabelValues("transfer", "fail").Observe(time.Since(transferStart).Seconds())
+			}
+		} else {
+			flushRequired = false
+			i.lifecyclerMetrics.shutdownDuration.WithLabelValues("transfer", "success").Observe(time.Since(transferStart).Seconds())
+		}
+	}
+
 	if flushRequired {
 		flushStart := time.Now()
 		i.flushTransferer.Flush()
@@ -10,6 +10,8 @@ type LifecyclerMetrics struct {
 	tokensOwned      prometheus.Gauge
 	tokensToOwn      prometheus.Gauge
 	shutdownDuration *prometheus.HistogramVec
+	ownershipSkew    prometheus.Gauge
+	tokensMigrated   prometheus.Counter
 }
 
 func NewLifecyclerMetrics(ringName string, reg prometheus.Registerer) *LifecyclerMetrics {
@@ -35,6 +37,16 @@ func NewLifecyclerMetrics(ringName string, reg prometheus.Registerer) *Lifecycle
 			Buckets:     prometheus.ExponentialBuckets(10, 2, 8), // Biggest bucket is 10*2^(9-1) = 2560, or 42 mins.
 			ConstLabels: prometheus.Labels{"name": ringName},
 		}, []string{"op", "status"}),
+		ownershipSkew: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Name:        "member_ring_ownership_skew",
+			Help:        "The relative difference between the token ranges owned in the ring within the zone and the ranges which would be owned if the ownership was even.",
+			ConstLabels: prometheus.Labels{"name": ringName},
+		}),
+		tokensMigrated: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name:        "member_ring_tokens_migrated_total",
+			Help:        "The total number of tokens replaced by the tokens migration.",
+			ConstLabels: prometheus.Labels{"name": ringName},
+		}),
 	}
 
 }
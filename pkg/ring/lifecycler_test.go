@@ -2,6 +2,7 @@ package ring
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"slices"
 	"testing"
@@ -9,8 +10,10 @@ import (
 
 	"github.com/go-kit/log"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
 
 	"github.com/cortexproject/cortex/pkg/ring/kv/consul"
 	"github.com/cortexproject/cortex/pkg/util/flagext"
@@ -126,6 +129,80 @@ func TestLifecycler_RenewTokens(t *testing.T) {
 	require.Equal(t, 51, diff)
 }
 
+func TestLifecyclerConfig_TokensMigration(t *testing.T) {
+	var cfg LifecyclerConfig
+	fs := flag.NewFlagSet("test", flag.PanicOnError)
+	cfg.RegisterFlags(fs)
+	require.NoError(t, cfg.Validate())
+
+	// The migration is configured with the ingester flags, and requires the minimize-spread strategy.
+	require.NoError(t, fs.Parse([]string{"-ingester.tokens-migration-interval=1m", "-ingester.tokens-migration-step=4"}))
+	assert.Equal(t, time.Minute, cfg.TokensMigrationInterval)
+	assert.Equal(t, 4, cfg.TokensMigrationStep)
+	assert.Equal(t, errTokensMigrationRequiresMinimizeSpread, cfg.Validate())
+
+	require.NoError(t, fs.Parse([]string{"-ingester.tokens-generator-strategy=" + minimizeSpreadTokenStrategy}))
+	require.NoError(t, cfg.Validate())
+
+	require.NoError(t, fs.Parse([]string{"-ingester.tokens-migration-step=0"}))
+	assert.Equal(t, errInvalidTokensMigrationStep, cfg.Validate())
+}
+
+func TestLifecycler_TokensMigration(t *testing.T) {
+	ringStore, closer := consul.NewInMemoryClient(GetCodec(), log.NewNopLogger(), nil)
+	t.Cleanup(func() { assert.NoError(t, closer.Close()) })
+
+	var ringConfig Config
+	flagext.DefaultValues(&ringConfig)
+	ringConfig.KVStore.Mock = ringStore
+
+	ctx := context.Background()
+	lifecyclerConfig := testLifecyclerConfig(ringConfig, "ing1")
+	lifecyclerConfig.NumTokens = 16
+	lifecyclerConfig.TokensGeneratorStrategy = minimizeSpreadTokenStrategy
+	lifecyclerConfig.TokensMigrationInterval = 10 * time.Millisecond
+	lifecyclerConfig.TokensMigrationStep = 4
+
+	// The instance is already in the ring with tokens all next to each other, so that
+	// the other instance of the zone owns almost the whole ring.
+	otherTokens := NewRandomTokenGenerator().GenerateTokens(NewDesc(), "ing2", "zone1", 16, true)
+	ownTokens := make(Tokens, 0, 16)
+	for i := uint32(1); i <= 16; i++ {
+		ownTokens = append(ownTokens, otherTokens[0]+i)
+	}
+	require.NoError(t, ringStore.CAS(ctx, ringKey, func(in any) (out any, retry bool, err error) {
+		desc := NewDesc()
+		desc.AddIngester("ing1", "0.0.0.0:1", "zone1", ownTokens, ACTIVE, time.Now())
+		desc.AddIngester("ing2", "0.0.0.0:2", "zone1", otherTokens, ACTIVE, time.Now())
+		return desc, true, nil
+	}))
+
+	l1, err := NewLifecycler(lifecyclerConfig, &nopFlushTransferer{}, "compactor", ringKey, true, true, log.NewNopLogger(), nil)
+	require.NoError(t, err)
+
+	require.NoError(t, services.StartAndAwaitRunning(ctx, l1))
+	defer services.StopAndAwaitTerminated(ctx, l1) // nolint:errcheck
+
+	test.Poll(t, 5*time.Second, true, func() any {
+		d, err := ringStore.Get(ctx, ringKey)
+		require.NoError(t, err)
+		skew := d.(*Desc).ownershipSkew()["ing1"]
+		return skew > -0.5 && skew < 0.5
+	})
+
+	require.Len(t, l1.getTokens(), 16)
+	require.IsIncreasing(t, l1.getTokens())
+	assert.Greater(t, testutil.ToFloat64(l1.lifecyclerMetrics.tokensMigrated), float64(0))
+
+	// The migrated tokens are kept by the heartbeat.
+	waitRingInstance(t, 3*time.Second, l1, func(instance InstanceDesc) error {
+		if !tokensEqual(instance.Tokens, l1.getTokens()) {
+			return errors.New("tokens should match")
+		}
+		return nil
+	})
+}
+
 func TestLifecycler_DefferedJoin(t *testing.T) {
 	ringStore, closer := consul.NewInMemoryClient(GetCodec(), log.NewNopLogger(), nil)
 	t.Cleanup(func() { assert.NoError(t, closer.Close()) })
@@ -297,6 +374,70 @@ func TestLifecycler_NilFlushTransferer(t *testing.T) {
 	assert.Equal(t, 0, lifecycler.HealthyInstancesCount())
 }
 
+func TestLifecycler_ShouldFlushOnShutdownOnlyIfTransferFailed(t *testing.T) {
+	tests := map[string]struct {
+		flushOnShutdown  bool
+		transferErr      error
+		expectedTransfer bool
+		expectedFlush    bool
+	}{
+		"should not transfer nor flush if flush on shutdown is disabled": {
+			flushOnShutdown: false,
+		},
+		"should not flush if the transfer succeeded": {
+			flushOnShutdown:  true,
+			expectedTransfer: true,
+		},
+		"should flush if the transfer failed": {
+			flushOnShutdown:  true,
+			transferErr:      errors.New("transfer failed"),
+			expectedTransfer: true,
+			expectedFlush:    true,
+		},
+		"should flush if transfers are disabled": {
+			flushOnShutdown:  true,
+			transferErr:      ErrTransferDisabled,
+			expectedTransfer: true,
+			expectedFlush:    true,
+		},
+	}
+
+	for testName, testData := range tests {
+		t.Run(testName, func(t *testing.T) {
+			ringStore, closer := consul.NewInMemoryClient(GetCodec(), log.NewNopLogger(), nil)
+			t.Cleanup(func() { assert.NoError(t, closer.Close()) })
+
+			var ringConfig Config
+			flagext.DefaultValues(&ringConfig)
+			ringConfig.KVStore.Mock = ringStore
+
+			flushTransferer := &countingFlushTransferer{transferErr: testData.transferErr}
+			lifecycler, err := NewLifecycler(testLifecyclerConfig(ringConfig, "ing1"), flushTransferer, "ingester", ringKey, true, testData.flushOnShutdown, log.NewNopLogger(), nil)
+			require.NoError(t, err)
+			require.NoError(t, services.StartAndAwaitRunning(context.Background(), lifecycler))
+			require.NoError(t, services.StopAndAwaitTerminated(context.Background(), lifecycler))
+
+			assert.Equal(t, testData.expectedTransfer, flushTransferer.transfers.Load() == 1)
+			assert.Equal(t, testData.expectedFlush, flushTransferer.flushes.Load() == 1)
+		})
+	}
+}
+
+type countingFlushTransferer struct {
+	transferErr error
+	transfers   atomic.Int32
+	flushes     atomic.Int32
+}
+
+func (f *countingFlushTransferer) Flush() {
+	f.flushes.Inc()
+}
+
+func (f *countingFlushTransferer) TransferOut(_ context.Context) error {
+	f.transfers.Inc()
+	return f.transferErr
+}
+
 func TestLifecycler_TwoRingsWithDifferentKeysOnTheSameKVStore(t *testing.T) {
 	// Create a shared ring
 	ringStore, closer := consul.NewInMemoryClient(GetCodec(), log.NewNopLogger(), nil)
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNormalizationAndConflictResolution(t *testing.T) {
@@ -593,3 +594,44 @@ func TestMergeResolvesConflictsWhenTokensChange(t *testing.T) {
 	assert.Equal(t, []uint32{1, 2, 3, 10, 20}, ring1.Ingesters["ing-A"].Tokens)
 	assert.Equal(t, []uint32{30, 40, 50}, ring1.Ingesters["ing-B"].Tokens)
 }
+
+func TestMergeOfPerInstanceParts(t *testing.T) {
+	now := time.Now().Unix()
+
+	current := &Desc{
+		Ingesters: map[string]InstanceDesc{
+			"Ing 1": {Addr: "addr1", Timestamp: now, State: ACTIVE, Tokens: []uint32{10, 20}},
+			"Ing 2": {Addr: "addr2", Timestamp: now, State: JOINING, Tokens: []uint32{30, 40}},
+		},
+	}
+
+	updated := current.Clone().(*Desc)
+	updated.Ingesters["Ing 2"] = InstanceDesc{Addr: "addr2", Timestamp: now + 1, State: ACTIVE, Tokens: []uint32{30, 40}}
+	updated.Ingesters["Ing 3"] = InstanceDesc{Addr: "addr3", Timestamp: now + 1, State: ACTIVE, Tokens: []uint32{20, 50}}
+
+	// Merging the whole value, as done by a local CAS.
+	expected := current.Clone().(*Desc)
+	expectedChange, err := expected.Merge(updated.Clone().(*Desc), true)
+	require.NoError(t, err)
+
+	// Merging only the difference, split per instance, as done when the ring is gossiped per instance.
+	diff, removed, err := current.Clone().(*Desc).FindDifference(updated.Clone().(*Desc))
+	require.NoError(t, err)
+	require.Empty(t, removed)
+
+	actual := current.Clone().(*Desc)
+	for id, instance := range diff.(*Desc).SplitByID() {
+		empty, err := GetCodec().DecodeMultiKey(nil)
+		require.NoError(t, err)
+
+		part := empty.(*Desc)
+		part.JoinIds(map[string]any{id: instance})
+		require.Len(t, part.Ingesters, 1)
+
+		_, err = actual.Merge(part, false)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, expected, actual)
+	assert.ElementsMatch(t, []string{"Ing 2", "Ing 3"}, expectedChange.MergeContent())
+}
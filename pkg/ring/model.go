@@ -482,6 +482,17 @@ func (d *Desc) RemoveTombstones(limit time.Time) (total, removed int) {
 	return
 }
 
+// Tombstones returns LEFT ingesters, along with the time they left the ring.
+func (d *Desc) Tombstones() map[string]time.Time {
+	result := map[string]time.Time{}
+	for n, ing := range d.Ingesters {
+		if ing.State == LEFT {
+			result[n] = time.Unix(ing.Timestamp, 0)
+		}
+	}
+	return result
+}
+
 // Clone returns a deep copy of the ring state.
 func (d *Desc) Clone() any {
 	return proto.Clone(d).(*Desc)
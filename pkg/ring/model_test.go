@@ -868,3 +868,12 @@ func Test_resolveConflicts(t *testing.T) {
 		})
 	}
 }
+
+func TestDesc_Tombstones(t *testing.T) {
+	desc := &Desc{Ingesters: map[string]InstanceDesc{
+		"ingester-1": {State: ACTIVE, Timestamp: 100},
+		"ingester-2": {State: LEFT, Timestamp: 200},
+	}}
+
+	assert.Equal(t, map[string]time.Time{"ingester-2": time.Unix(200, 0)}, desc.Tombstones())
+}
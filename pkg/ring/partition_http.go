@@ -0,0 +1,216 @@
+package ring
+
+import (
+	"html/template"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const partitionPageContent = `
+<!DOCTYPE html>
+<html>
+	<head>
+		<meta charset="UTF-8">
+		<title>Partition Ring Status</title>
+	</head>
+	<body>
+		<h1>Partition Ring Status</h1>
+		<p>Current time: {{ .Now }}</p>
+		<p>Storage updated: {{ .StorageLastUpdated }}</p>
+		<table width="100%" border="1">
+			<thead>
+				<tr>
+					<th>Partition ID</th>
+					<th>State</th>
+					<th>Registered At</th>
+					<th>Tokens</th>
+					<th>Ownership</th>
+					<th>Instance ID</th>
+					<th>Availability Zone</th>
+					<th>Instance State</th>
+					<th>Address</th>
+					<th>Last Heartbeat</th>
+				</tr>
+			</thead>
+			<tbody>
+				{{ range $i, $partition := .Partitions }}
+				{{ range $j, $instance := $partition.Instances }}
+				{{ if mod $i 2 }}
+				<tr>
+				{{ else }}
+				<tr bgcolor="#BEBEBE">
+				{{ end }}
+					{{ if eq $j 0 }}
+					<td rowspan="{{ len $partition.Instances }}">{{ $partition.ID }}</td>
+					<td rowspan="{{ len $partition.Instances }}">{{ $partition.State }}</td>
+					<td rowspan="{{ len $partition.Instances }}">{{ $partition.RegisteredTimestamp }}</td>
+					<td rowspan="{{ len $partition.Instances }}">{{ $partition.NumTokens }}</td>
+					<td rowspan="{{ len $partition.Instances }}">{{ $partition.Ownership }}%</td>
+					{{ end }}
+					<td>{{ .ID }}</td>
+					<td>{{ .Zone }}</td>
+					<td>{{ .State }}</td>
+					<td>{{ .Address }}</td>
+					<td>{{ .HeartbeatTimestamp }}</td>
+				</tr>
+				{{ else }}
+				{{ if mod $i 2 }}
+				<tr>
+				{{ else }}
+				<tr bgcolor="#BEBEBE">
+				{{ end }}
+					<td>{{ $partition.ID }}</td>
+					<td>{{ $partition.State }}</td>
+					<td>{{ $partition.RegisteredTimestamp }}</td>
+					<td>{{ $partition.NumTokens }}</td>
+					<td>{{ $partition.Ownership }}%</td>
+					<td colspan="5"></td>
+				</tr>
+				{{ end }}
+				{{ end }}
+			</tbody>
+		</table>
+		<br>
+		{{ if .ShowTokens }}
+		<input type="button" value="Hide Tokens" onclick="window.location.href = '?tokens=false' " />
+		{{ else }}
+		<input type="button" value="Show Tokens" onclick="window.location.href = '?tokens=true'" />
+		{{ end }}
+
+		{{ if .ShowTokens }}
+			{{ range $i, $partition := .Partitions }}
+				<h2>Partition: {{ .ID }}</h2>
+				<p>
+					Tokens:<br />
+					{{ range $token := .Tokens }}
+						{{ $token }}
+					{{ end }}
+				</p>
+			{{ end }}
+		{{ end }}
+	</body>
+</html>`
+
+var partitionPageTemplate *template.Template
+
+func init() {
+	t := template.New("webpage")
+	t.Funcs(template.FuncMap{"mod": func(i, j int) bool { return i%j == 0 }})
+	partitionPageTemplate = template.Must(t.Parse(partitionPageContent))
+}
+
+type partitionDesc struct {
+	ID                  string         `json:"id"`
+	State               string         `json:"state"`
+	RegisteredTimestamp string         `json:"registered_timestamp"`
+	Tokens              []uint32       `json:"tokens"`
+	Instances           []ingesterDesc `json:"instances"`
+	NumTokens           int            `json:"-"`
+	Ownership           float64        `json:"-"`
+}
+
+type partitionHTTPResponse struct {
+	Partitions         []partitionDesc `json:"partitions"`
+	Now                time.Time       `json:"now"`
+	StorageLastUpdated time.Time       `json:"storageLastUpdated"`
+	ShowTokens         bool            `json:"-"`
+}
+
+// ServeHTTP renders the partitions of the ring, along with their instances.
+func (r *PartitionRing) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	partitionIDs := make([]string, 0, len(r.ringDesc.Partitions))
+	for id := range r.ringDesc.Partitions {
+		partitionIDs = append(partitionIDs, id)
+	}
+	sortPartitionIDs(partitionIDs)
+
+	storageLastUpdate := r.KVClient.LastUpdateTime(r.key)
+	owned := r.countTokens()
+
+	partitions := make([]partitionDesc, 0, len(partitionIDs))
+	for _, id := range partitionIDs {
+		partition := r.ringDesc.Partitions[id]
+
+		registeredTimestamp := ""
+		if partition.RegisteredTimestamp != 0 {
+			registeredTimestamp = partition.GetRegisteredAt().String()
+		}
+
+		instanceIDs := make([]string, 0, len(partition.Instances))
+		for instanceID := range partition.Instances {
+			instanceIDs = append(instanceIDs, instanceID)
+		}
+		sort.Strings(instanceIDs)
+
+		instances := make([]ingesterDesc, 0, len(instanceIDs))
+		for _, instanceID := range instanceIDs {
+			instance := partition.Instances[instanceID]
+			state := instance.State.String()
+			if !instance.IsHealthy(Reporting, r.cfg.HeartbeatTimeout, storageLastUpdate) {
+				state = unhealthy
+			}
+
+			instanceRegisteredTimestamp := ""
+			if instance.RegisteredTimestamp != 0 {
+				instanceRegisteredTimestamp = instance.GetRegisteredAt().String()
+			}
+
+			instances = append(instances, ingesterDesc{
+				ID:                  instanceID,
+				State:               state,
+				Address:             instance.Addr,
+				HeartbeatTimestamp:  time.Unix(instance.Timestamp, 0).String(),
+				RegisteredTimestamp: instanceRegisteredTimestamp,
+				Zone:                instance.Zone,
+			})
+		}
+
+		partitions = append(partitions, partitionDesc{
+			ID:                  id,
+			State:               partition.State.String(),
+			RegisteredTimestamp: registeredTimestamp,
+			Tokens:              partition.Tokens,
+			Instances:           instances,
+			NumTokens:           len(partition.Tokens),
+			Ownership:           (float64(owned[id]) / float64(math.MaxUint32+1)) * 100,
+		})
+	}
+
+	tokensParam := req.URL.Query().Get("tokens")
+
+	renderHTTPResponse(w, partitionHTTPResponse{
+		Partitions:         partitions,
+		Now:                time.Now(),
+		StorageLastUpdated: storageLastUpdate,
+		ShowTokens:         tokensParam == "true",
+	}, partitionPageTemplate, req)
+}
+
+// countTokens returns the ring range owned by each partition.
+func (r *PartitionRing) countTokens() map[string]int64 {
+	owned := map[string]int64{}
+	for i := 1; i <= len(r.ringTokens); i++ { // Compute how many tokens are within the range.
+		index := i % len(r.ringTokens)
+		diff := tokenDistance(r.ringTokens[i-1], r.ringTokens[index])
+		owned[r.ringPartitionByToken[r.ringTokens[index]]] += diff
+	}
+	return owned
+}
+
+// sortPartitionIDs sorts the partition IDs numerically when they're numbers, and alphabetically otherwise.
+func sortPartitionIDs(ids []string) {
+	sort.Slice(ids, func(i, j int) bool {
+		a, errA := strconv.Atoi(ids[i])
+		b, errB := strconv.Atoi(ids[j])
+		if errA == nil && errB == nil {
+			return a < b
+		}
+		return ids[i] < ids[j]
+	})
+}
@@ -0,0 +1,225 @@
+package ring
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/cortexproject/cortex/pkg/ring/kv"
+	"github.com/cortexproject/cortex/pkg/util/services"
+)
+
+var instanceIDOrdinalRegexp = regexp.MustCompile(`(\d+)$`)
+
+// PartitionIDFromInstanceID returns the ID of the partition an instance belongs to, which is the
+// ordinal number at the end of its ID (eg. "ingester-zone-a-3" belongs to the partition "3"). This way
+// the instances with the same ordinal number in different zones are replicas of the same partition.
+func PartitionIDFromInstanceID(instanceID string) (string, error) {
+	match := instanceIDOrdinalRegexp.FindStringSubmatch(instanceID)
+	if match == nil {
+		return "", fmt.Errorf("instance ID %s doesn't end with an ordinal number", instanceID)
+	}
+
+	ordinal, err := strconv.Atoi(match[1])
+	if err != nil {
+		return "", errors.Wrapf(err, "parse ordinal number of instance ID %s", instanceID)
+	}
+	return strconv.Itoa(ordinal), nil
+}
+
+// PartitionLifecycler registers an instance to its partition in the partition ring, creating the
+// partition if it doesn't exist yet, and keeps the instance heartbeat and the partition state updated.
+type PartitionLifecycler struct {
+	*services.BasicService
+
+	cfg     LifecyclerConfig
+	KVStore kv.Client
+	tg      TokenGenerator
+	logger  log.Logger
+
+	// These values are initialised at startup, and never change
+	ID           string
+	Addr         string
+	Zone         string
+	PartitionID  string
+	RingName     string
+	RingKey      string
+	registeredAt time.Time
+}
+
+// NewPartitionLifecycler creates a new PartitionLifecycler. It must be started via StartAsync.
+func NewPartitionLifecycler(cfg LifecyclerConfig, ringName, ringKey string, logger log.Logger, reg prometheus.Registerer) (*PartitionLifecycler, error) {
+	partitionID, err := PartitionIDFromInstanceID(cfg.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	addr, err := GetInstanceAddr(cfg.Addr, cfg.InfNames, logger)
+	if err != nil {
+		return nil, err
+	}
+	port := GetInstancePort(cfg.Port, cfg.ListenPort)
+
+	// Suffix all client names with "-partition-lifecycler" to denote this kv client is used by the partition lifecycler
+	store, err := kv.NewClient(
+		cfg.RingConfig.KVStore,
+		GetPartitionRingCodec(),
+		kv.RegistererWithKVName(reg, ringName+"-partition-lifecycler"),
+		logger,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &PartitionLifecycler{
+		cfg:          cfg,
+		KVStore:      store,
+		tg:           NewRandomTokenGenerator(),
+		logger:       log.With(logger, "partition", partitionID),
+		ID:           cfg.ID,
+		Addr:         fmt.Sprintf("%s:%d", addr, port),
+		Zone:         cfg.Zone,
+		PartitionID:  partitionID,
+		RingName:     ringName,
+		RingKey:      ringKey,
+		registeredAt: time.Now(),
+	}
+
+	l.BasicService = services.
+		NewBasicService(l.starting, l.running, l.stopping).
+		WithName(fmt.Sprintf("%s partition ring lifecycler", ringName))
+
+	return l, nil
+}
+
+func (l *PartitionLifecycler) starting(ctx context.Context) error {
+	if err := l.updateRing(ctx); err != nil {
+		return errors.Wrapf(err, "failed to join the partition ring %s", l.RingName)
+	}
+
+	level.Info(l.logger).Log("msg", "instance registered in the partition ring", "ring", l.RingName)
+	return nil
+}
+
+func (l *PartitionLifecycler) running(ctx context.Context) error {
+	heartbeatTickerStop, heartbeatTickerChan := newDisableableTicker(l.cfg.HeartbeatPeriod)
+	defer heartbeatTickerStop()
+
+	for {
+		select {
+		case <-heartbeatTickerChan:
+			if err := l.updateRing(ctx); err != nil {
+				level.Warn(l.logger).Log("msg", "failed to heartbeat the partition ring", "ring", l.RingName, "err", err)
+			}
+
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (l *PartitionLifecycler) stopping(runningError error) error {
+	if runningError != nil || !l.cfg.UnregisterOnShutdown {
+		return nil
+	}
+
+	err := l.KVStore.CAS(context.Background(), l.RingKey, func(in any) (out any, retry bool, err error) {
+		if in == nil {
+			return nil, false, nil
+		}
+
+		ringDesc := in.(*PartitionRingDesc)
+		partition, ok := ringDesc.Partitions[l.PartitionID]
+		if !ok {
+			return nil, false, nil
+		}
+
+		ringDesc.RemoveInstance(l.PartitionID, l.ID)
+		if partition.replicas(false) == 0 {
+			// The last instance of the partition is leaving, so its series aren't available
+			// anymore and the partition is removed.
+			ringDesc.RemovePartition(l.PartitionID)
+		} else {
+			ringDesc.UpdatePartitionState(l.PartitionID, l.cfg.RingConfig.ReplicationFactor, l.cfg.RingConfig.ZoneAwarenessEnabled)
+		}
+		return ringDesc, true, nil
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to unregister from the partition ring %s", l.RingName)
+	}
+
+	level.Info(l.logger).Log("msg", "instance removed from the partition ring", "ring", l.RingName)
+	return nil
+}
+
+// updateRing adds the instance to its partition, creating the partition if missing, or updates its
+// heartbeat if already registered. The partition state is then updated based on its instances.
+func (l *PartitionLifecycler) updateRing(ctx context.Context) error {
+	return l.KVStore.CAS(ctx, l.RingKey, func(in any) (out any, retry bool, err error) {
+		ringDesc := GetOrCreatePartitionRingDesc(in)
+
+		partition, ok := ringDesc.Partitions[l.PartitionID]
+		if !ok || partition.State == PARTITION_DELETED {
+			// The tokens of deleted partitions are taken into account too, in order to not reuse them.
+			takenTokens := &Desc{Ingesters: map[string]InstanceDesc{"": {Tokens: ringDesc.GetTokens()}}}
+			tokens := l.tg.GenerateTokens(takenTokens, l.PartitionID, "", l.cfg.RingConfig.PartitionRing.NumTokens, true)
+			partition = ringDesc.AddPartition(l.PartitionID, tokens, time.Now())
+			level.Info(l.logger).Log("msg", "partition added to the partition ring", "ring", l.RingName)
+		}
+
+		instance, ok := partition.Instances[l.ID]
+		if !ok || instance.State == LEFT {
+			if _, err := ringDesc.AddInstance(l.PartitionID, l.ID, l.Addr, l.Zone, ACTIVE, l.registeredAt); err != nil {
+				return nil, false, err
+			}
+		} else {
+			instance.Addr = l.Addr
+			instance.Zone = l.Zone
+			instance.Timestamp = time.Now().Unix()
+			partition.Instances[l.ID] = instance
+		}
+
+		ringDesc.UpdatePartitionState(l.PartitionID, l.cfg.RingConfig.ReplicationFactor, l.cfg.RingConfig.ZoneAwarenessEnabled)
+		return ringDesc, true, nil
+	})
+}
+
+// ChangePartitionState changes the state of the instance's partition. Only the ACTIVE and READONLY
+// states can be requested: a partition switched to ACTIVE is moved to NON_READY if it hasn't enough
+// instances to honor the replication factor. All the instances of the partition are affected.
+func (l *PartitionLifecycler) ChangePartitionState(ctx context.Context, state PartitionState) error {
+	if state != PARTITION_ACTIVE && state != PARTITION_READONLY {
+		return fmt.Errorf("partition state %s can't be requested", state.String())
+	}
+
+	return l.KVStore.CAS(ctx, l.RingKey, func(in any) (out any, retry bool, err error) {
+		ringDesc := GetOrCreatePartitionRingDesc(in)
+		if err := ringDesc.SetPartitionState(l.PartitionID, state); err != nil {
+			return nil, false, err
+		}
+
+		ringDesc.UpdatePartitionState(l.PartitionID, l.cfg.RingConfig.ReplicationFactor, l.cfg.RingConfig.ZoneAwarenessEnabled)
+		return ringDesc, true, nil
+	})
+}
+
+// GetPartitionState returns the state of the instance's partition, as stored in the partition ring.
+func (l *PartitionLifecycler) GetPartitionState(ctx context.Context) (PartitionState, error) {
+	value, err := l.KVStore.Get(ctx, l.RingKey)
+	if err != nil {
+		return PARTITION_NON_READY, err
+	}
+
+	partition, ok := GetOrCreatePartitionRingDesc(value).Partitions[l.PartitionID]
+	if !ok || partition.State == PARTITION_DELETED {
+		return PARTITION_NON_READY, fmt.Errorf("partition %s not found in the ring", l.PartitionID)
+	}
+	return partition.State, nil
+}
@@ -0,0 +1,102 @@
+package ring
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexproject/cortex/pkg/ring/kv/consul"
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+	"github.com/cortexproject/cortex/pkg/util/services"
+	"github.com/cortexproject/cortex/pkg/util/test"
+)
+
+func TestPartitionIDFromInstanceID(t *testing.T) {
+	for instanceID, expected := range map[string]string{
+		"ingester-0":          "0",
+		"ingester-zone-a-12":  "12",
+		"ingester-zone-b-012": "12",
+		"7":                   "7",
+	} {
+		partitionID, err := PartitionIDFromInstanceID(instanceID)
+		require.NoError(t, err)
+		assert.Equal(t, expected, partitionID, instanceID)
+	}
+
+	_, err := PartitionIDFromInstanceID("ingester")
+	require.Error(t, err)
+}
+
+func TestPartitionLifecycler(t *testing.T) {
+	ctx := context.Background()
+	ringStore, closer := consul.NewInMemoryClient(GetPartitionRingCodec(), log.NewNopLogger(), nil)
+	t.Cleanup(func() { assert.NoError(t, closer.Close()) })
+
+	var ringConfig Config
+	flagext.DefaultValues(&ringConfig)
+	ringConfig.KVStore.Mock = ringStore
+	ringConfig.ReplicationFactor = 2
+	ringConfig.ZoneAwarenessEnabled = true
+	ringConfig.PartitionRing.Enabled = true
+	ringConfig.PartitionRing.NumTokens = 4
+
+	getPartition := func() (PartitionDesc, bool) {
+		value, err := ringStore.Get(ctx, "partition-ring")
+		require.NoError(t, err)
+		partition, ok := GetOrCreatePartitionRingDesc(value).Partitions["1"]
+		return partition, ok
+	}
+
+	startLifecycler := func(id, zone string) *PartitionLifecycler {
+		cfg := testLifecyclerConfig(ringConfig, id)
+		cfg.Zone = zone
+		l, err := NewPartitionLifecycler(cfg, "ingester", "partition-ring", log.NewNopLogger(), nil)
+		require.NoError(t, err)
+		require.NoError(t, services.StartAndAwaitRunning(ctx, l))
+		return l
+	}
+
+	// The first instance creates the partition, which isn't ready until it's replicated.
+	l1 := startLifecycler("ingester-zone-a-1", "zone-a")
+	assert.Equal(t, "1", l1.PartitionID)
+	partition, ok := getPartition()
+	require.True(t, ok)
+	assert.Equal(t, PARTITION_NON_READY, partition.State)
+	assert.Len(t, partition.Tokens, 4)
+	assert.Contains(t, partition.Instances, "ingester-zone-a-1")
+
+	// The second instance joins the partition, which becomes ACTIVE.
+	l2 := startLifecycler("ingester-zone-b-1", "zone-b")
+	partition, _ = getPartition()
+	assert.Equal(t, PARTITION_ACTIVE, partition.State)
+	assert.Len(t, partition.Instances, 2)
+
+	// The partition state can be changed.
+	require.NoError(t, l1.ChangePartitionState(ctx, PARTITION_READONLY))
+	state, err := l2.GetPartitionState(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, PARTITION_READONLY, state)
+	require.Error(t, l1.ChangePartitionState(ctx, PARTITION_DELETED))
+	require.NoError(t, l1.ChangePartitionState(ctx, PARTITION_ACTIVE))
+
+	// The heartbeat is updated periodically.
+	heartbeat := partition.Instances["ingester-zone-a-1"].Timestamp
+	test.Poll(t, 3*time.Second, true, func() any {
+		partition, _ := getPartition()
+		return partition.Instances["ingester-zone-a-1"].Timestamp > heartbeat
+	})
+
+	// The partition isn't ready anymore when an instance leaves, and is removed with its last instance.
+	require.NoError(t, services.StopAndAwaitTerminated(ctx, l2))
+	partition, _ = getPartition()
+	assert.Equal(t, PARTITION_NON_READY, partition.State)
+	assert.Len(t, partition.Instances, 1)
+
+	require.NoError(t, services.StopAndAwaitTerminated(ctx, l1))
+	_, ok = getPartition()
+	assert.False(t, ok)
+}
@@ -0,0 +1,394 @@
+package ring
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+
+	"github.com/cortexproject/cortex/pkg/ring/kv/codec"
+	"github.com/cortexproject/cortex/pkg/ring/kv/memberlist"
+)
+
+// ProtoPartitionRingDescFactory makes new PartitionRingDescs
+func ProtoPartitionRingDescFactory() proto.Message {
+	return NewPartitionRingDesc()
+}
+
+// GetPartitionRingCodec returns the codec used to encode and decode data being put by the partition ring.
+func GetPartitionRingCodec() codec.Codec {
+	return codec.NewProtoCodec("partitionRingDesc", ProtoPartitionRingDescFactory)
+}
+
+// NewPartitionRingDesc returns an empty ring.PartitionRingDesc
+func NewPartitionRingDesc() *PartitionRingDesc {
+	return &PartitionRingDesc{
+		Partitions: map[string]PartitionDesc{},
+	}
+}
+
+// GetOrCreatePartitionRingDesc returns the input partition ring, or a new empty one if nil.
+func GetOrCreatePartitionRingDesc(d any) *PartitionRingDesc {
+	if d == nil {
+		return NewPartitionRingDesc()
+	}
+	return d.(*PartitionRingDesc)
+}
+
+// AddPartition adds the given partition to the ring, in the NON_READY state.
+func (d *PartitionRingDesc) AddPartition(id string, tokens []uint32, registeredAt time.Time) PartitionDesc {
+	if d.Partitions == nil {
+		d.Partitions = map[string]PartitionDesc{}
+	}
+
+	partition := PartitionDesc{
+		State:               PARTITION_NON_READY,
+		Tokens:              tokens,
+		Instances:           map[string]InstanceDesc{},
+		RegisteredTimestamp: registeredAt.Unix(),
+		Timestamp:           time.Now().Unix(),
+	}
+
+	d.Partitions[id] = partition
+	return partition
+}
+
+// RemovePartition removes the given partition, along with its instances and tokens.
+func (d *PartitionRingDesc) RemovePartition(id string) {
+	delete(d.Partitions, id)
+}
+
+// AddInstance adds the given instance to the partition, which must exist in the ring.
+func (d *PartitionRingDesc) AddInstance(partitionID, id, addr, zone string, state InstanceState, registeredAt time.Time) (InstanceDesc, error) {
+	partition, ok := d.Partitions[partitionID]
+	if !ok || partition.State == PARTITION_DELETED {
+		return InstanceDesc{}, fmt.Errorf("partition %s not found in the ring", partitionID)
+	}
+	if partition.Instances == nil {
+		partition.Instances = map[string]InstanceDesc{}
+	}
+
+	registeredTimestamp := int64(0)
+	if !registeredAt.IsZero() {
+		registeredTimestamp = registeredAt.Unix()
+	}
+
+	instance := InstanceDesc{
+		Addr:                addr,
+		Timestamp:           time.Now().Unix(),
+		RegisteredTimestamp: registeredTimestamp,
+		State:               state,
+		Zone:                zone,
+	}
+
+	partition.Instances[id] = instance
+	d.Partitions[partitionID] = partition
+	return instance, nil
+}
+
+// RemoveInstance removes the given instance from the partition.
+func (d *PartitionRingDesc) RemoveInstance(partitionID, id string) {
+	if partition, ok := d.Partitions[partitionID]; ok {
+		delete(partition.Instances, id)
+	}
+}
+
+// SetPartitionState changes the state of the given partition, which must exist in the ring.
+func (d *PartitionRingDesc) SetPartitionState(partitionID string, state PartitionState) error {
+	partition, ok := d.Partitions[partitionID]
+	if !ok || partition.State == PARTITION_DELETED {
+		return fmt.Errorf("partition %s not found in the ring", partitionID)
+	}
+
+	if partition.State != state {
+		partition.State = state
+		partition.Timestamp = time.Now().Unix()
+		d.Partitions[partitionID] = partition
+	}
+	return nil
+}
+
+// UpdatePartitionState moves the given partition to the NON_READY state when it hasn't enough
+// instances to honor the replication factor, and to the ACTIVE state when it has them. When
+// zone-awareness is enabled, the instances are required to be in different zones. READONLY
+// partitions are kept READONLY until they lose an instance. Returns whether the state changed.
+func (d *PartitionRingDesc) UpdatePartitionState(partitionID string, replicationFactor int, zoneAwarenessEnabled bool) bool {
+	partition, ok := d.Partitions[partitionID]
+	if !ok || partition.State == PARTITION_DELETED {
+		return false
+	}
+
+	state := partition.State
+	if partition.replicas(zoneAwarenessEnabled) < replicationFactor {
+		state = PARTITION_NON_READY
+	} else if state == PARTITION_NON_READY {
+		state = PARTITION_ACTIVE
+	}
+
+	if state == partition.State {
+		return false
+	}
+	partition.State = state
+	partition.Timestamp = time.Now().Unix()
+	d.Partitions[partitionID] = partition
+	return true
+}
+
+// replicas returns the number of replicas of the partition, which is the number of distinct zones
+// of its instances if zone-awareness is enabled, or the number of its instances otherwise.
+func (p *PartitionDesc) replicas(zoneAwarenessEnabled bool) int {
+	if !zoneAwarenessEnabled {
+		count := 0
+		for _, instance := range p.Instances {
+			if instance.State != LEFT {
+				count++
+			}
+		}
+		return count
+	}
+
+	zones := map[string]struct{}{}
+	for _, instance := range p.Instances {
+		if instance.State != LEFT {
+			zones[instance.Zone] = struct{}{}
+		}
+	}
+	return len(zones)
+}
+
+// GetRegisteredAt returns the timestamp when the partition has been registered to the ring
+// or a zero value if unknown.
+func (p *PartitionDesc) GetRegisteredAt() time.Time {
+	if p == nil || p.RegisteredTimestamp == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(p.RegisteredTimestamp, 0)
+}
+
+// FindPartitionByInstance returns the ID of the partition the given instance belongs to.
+func (d *PartitionRingDesc) FindPartitionByInstance(id string) (string, bool) {
+	for partitionID, partition := range d.Partitions {
+		if instance, ok := partition.Instances[id]; ok && instance.State != LEFT {
+			return partitionID, true
+		}
+	}
+	return "", false
+}
+
+// GetTokens returns sorted list of tokens owned by all partitions in the ring.
+func (d *PartitionRingDesc) GetTokens() []uint32 {
+	numTokens := 0
+	for _, partition := range d.Partitions {
+		numTokens += len(partition.Tokens)
+	}
+
+	tokens := make([]uint32, 0, numTokens)
+	for _, partition := range d.Partitions {
+		tokens = append(tokens, partition.Tokens...)
+	}
+
+	sort.Sort(Tokens(tokens))
+	return tokens
+}
+
+// getPartitionsByToken returns the ID of the partition owning each token.
+func (d *PartitionRingDesc) getPartitionsByToken() map[uint32]string {
+	out := map[uint32]string{}
+	for partitionID, partition := range d.Partitions {
+		for _, token := range partition.Tokens {
+			out[token] = partitionID
+		}
+	}
+	return out
+}
+
+// Merge merges other partition ring into this one. Returns sub-ring that represents the change,
+// and can be sent out to other clients.
+//
+// Partitions are merged by picking the state and tokens with the most recent timestamp, and
+// their instances by picking the most recent heartbeat, exactly like instances of the token ring.
+// There is one exception: we accept the DELETED partition state and the LEFT instance state even
+// if the timestamp hasn't changed.
+//
+// localCAS flag tells the merge that it can use incoming ring as a full state, and detect
+// missing partitions and instances based on it.
+//
+// This method is part of memberlist.Mergeable interface, and is only used by gossiping ring.
+func (d *PartitionRingDesc) Merge(mergeable memberlist.Mergeable, localCAS bool) (memberlist.Mergeable, error) {
+	return d.mergeWithTime(mergeable, localCAS, time.Now())
+}
+
+func (d *PartitionRingDesc) mergeWithTime(mergeable memberlist.Mergeable, localCAS bool, now time.Time) (memberlist.Mergeable, error) {
+	if mergeable == nil {
+		return nil, nil
+	}
+
+	other, ok := mergeable.(*PartitionRingDesc)
+	if !ok {
+		// This method only deals with non-nil rings.
+		return nil, fmt.Errorf("expected *ring.PartitionRingDesc, got %T", mergeable)
+	}
+
+	if other == nil {
+		return nil, nil
+	}
+
+	if d.Partitions == nil {
+		d.Partitions = map[string]PartitionDesc{}
+	}
+
+	out := NewPartitionRingDesc()
+	maxFutureLimit := now.Add(30 * time.Minute).Unix()
+
+	for partitionID, opart := range other.Partitions {
+		if opart.Timestamp > maxFutureLimit {
+			return nil, fmt.Errorf("partition %s timestamp in the future, expected max of %d, got %d", partitionID, maxFutureLimit, opart.Timestamp)
+		}
+
+		tpart, exists := d.Partitions[partitionID]
+		changed := false
+
+		// tpart.Timestamp will be 0, if there was no such partition in our version
+		if !exists || opart.Timestamp > tpart.Timestamp || (opart.Timestamp == tpart.Timestamp && tpart.State != PARTITION_DELETED && opart.State == PARTITION_DELETED) {
+			tpart.State = opart.State
+			tpart.Tokens = append([]uint32(nil), opart.Tokens...) // make a copy of tokens
+			sort.Sort(Tokens(tpart.Tokens))
+			tpart.RegisteredTimestamp = opart.RegisteredTimestamp
+			tpart.Timestamp = opart.Timestamp
+			changed = true
+		}
+
+		if tpart.Instances == nil {
+			tpart.Instances = map[string]InstanceDesc{}
+		}
+
+		updatedInstances := map[string]InstanceDesc{}
+		for instanceID, oinst := range opart.Instances {
+			if oinst.Timestamp > maxFutureLimit {
+				return nil, fmt.Errorf("instance %s timestamp in the future, expected max of %d, got %d", instanceID, maxFutureLimit, oinst.Timestamp)
+			}
+
+			tinst := tpart.Instances[instanceID]
+			// tinst.Timestamp will be 0, if there was no such instance in our version
+			if oinst.Timestamp > tinst.Timestamp || (oinst.Timestamp == tinst.Timestamp && tinst.State != LEFT && oinst.State == LEFT) {
+				tpart.Instances[instanceID] = oinst
+				updatedInstances[instanceID] = oinst
+			}
+		}
+
+		if localCAS {
+			// This breaks commutativity! But we only do it locally, not when gossiping with others.
+			for instanceID, tinst := range tpart.Instances {
+				if _, ok := opart.Instances[instanceID]; !ok && tinst.State != LEFT {
+					// Missing, let's mark our instance as LEFT. We are deleting the entry "now", and should
+					// not keep the old timestamp, so that a pending gossip message can't resurrect it.
+					tinst.State = LEFT
+					tinst.Timestamp = now.Unix()
+					tpart.Instances[instanceID] = tinst
+					updatedInstances[instanceID] = tinst
+				}
+			}
+		}
+
+		if !changed && len(updatedInstances) == 0 {
+			continue
+		}
+
+		d.Partitions[partitionID] = tpart
+
+		change := tpart
+		change.Instances = updatedInstances
+		out.Partitions[partitionID] = change
+	}
+
+	if localCAS {
+		// This breaks commutativity! But we only do it locally, not when gossiping with others.
+		for partitionID, tpart := range d.Partitions {
+			if _, ok := other.Partitions[partitionID]; !ok && tpart.State != PARTITION_DELETED {
+				// Missing, let's mark our partition as DELETED.
+				tpart.State = PARTITION_DELETED
+				tpart.Tokens = nil
+				tpart.Instances = map[string]InstanceDesc{}
+				tpart.Timestamp = now.Unix()
+				d.Partitions[partitionID] = tpart
+				out.Partitions[partitionID] = tpart
+			}
+		}
+	}
+
+	// No updated partitions
+	if len(out.Partitions) == 0 {
+		return nil, nil
+	}
+
+	return out, nil
+}
+
+// MergeContent describes content of this Mergeable.
+// The partition ring simply returns the list of partitions that it includes.
+func (d *PartitionRingDesc) MergeContent() []string {
+	result := []string(nil)
+	for k := range d.Partitions {
+		result = append(result, k)
+	}
+	return result
+}
+
+// RemoveTombstones removes DELETED partitions and LEFT instances older than given time limit.
+// If time limit is zero, remove all DELETED partitions and LEFT instances.
+func (d *PartitionRingDesc) RemoveTombstones(limit time.Time) (total, removed int) {
+	isExpired := func(timestamp int64) bool {
+		return limit.IsZero() || time.Unix(timestamp, 0).Before(limit)
+	}
+
+	for partitionID, partition := range d.Partitions {
+		if partition.State == PARTITION_DELETED {
+			if isExpired(partition.Timestamp) {
+				delete(d.Partitions, partitionID)
+				removed++
+			} else {
+				total++
+			}
+			continue
+		}
+
+		for instanceID, instance := range partition.Instances {
+			if instance.State != LEFT {
+				continue
+			}
+			if isExpired(instance.Timestamp) {
+				delete(partition.Instances, instanceID)
+				removed++
+			} else {
+				total++
+			}
+		}
+	}
+	return
+}
+
+// Tombstones returns DELETED partitions and LEFT instances, along with the time they were removed.
+// LEFT instances are keyed by "<partition>/<instance>".
+func (d *PartitionRingDesc) Tombstones() map[string]time.Time {
+	result := map[string]time.Time{}
+	for partitionID, partition := range d.Partitions {
+		if partition.State == PARTITION_DELETED {
+			result[partitionID] = time.Unix(partition.Timestamp, 0)
+			continue
+		}
+
+		for instanceID, instance := range partition.Instances {
+			if instance.State == LEFT {
+				result[partitionID+"/"+instanceID] = time.Unix(instance.Timestamp, 0)
+			}
+		}
+	}
+	return result
+}
+
+// Clone returns a deep copy of the partition ring state.
+func (d *PartitionRingDesc) Clone() any {
+	return proto.Clone(d).(*PartitionRingDesc)
+}
@@ -0,0 +1,217 @@
+package ring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartitionRingDesc_UpdatePartitionState(t *testing.T) {
+	tests := map[string]struct {
+		state                PartitionState
+		zones                []string
+		zoneAwarenessEnabled bool
+		expectedState        PartitionState
+		expectedChanged      bool
+	}{
+		"non ready partition without enough instances": {
+			state:         PARTITION_NON_READY,
+			zones:         []string{"zone-a", "zone-b"},
+			expectedState: PARTITION_NON_READY,
+		},
+		"non ready partition with enough instances": {
+			state:           PARTITION_NON_READY,
+			zones:           []string{"zone-a", "zone-b", "zone-c"},
+			expectedState:   PARTITION_ACTIVE,
+			expectedChanged: true,
+		},
+		"non ready partition with enough instances in the same zone and zone-awareness enabled": {
+			state:                PARTITION_NON_READY,
+			zones:                []string{"zone-a", "zone-a", "zone-b"},
+			zoneAwarenessEnabled: true,
+			expectedState:        PARTITION_NON_READY,
+		},
+		"active partition losing an instance": {
+			state:           PARTITION_ACTIVE,
+			zones:           []string{"zone-a", "zone-b"},
+			expectedState:   PARTITION_NON_READY,
+			expectedChanged: true,
+		},
+		"read-only partition with enough instances": {
+			state:         PARTITION_READONLY,
+			zones:         []string{"zone-a", "zone-b", "zone-c"},
+			expectedState: PARTITION_READONLY,
+		},
+		"read-only partition losing an instance": {
+			state:           PARTITION_READONLY,
+			zones:           []string{"zone-a", "zone-b"},
+			expectedState:   PARTITION_NON_READY,
+			expectedChanged: true,
+		},
+	}
+
+	for name, testData := range tests {
+		t.Run(name, func(t *testing.T) {
+			desc := NewPartitionRingDesc()
+			desc.AddPartition("1", []uint32{1, 2}, time.Now())
+			for i, zone := range testData.zones {
+				_, err := desc.AddInstance("1", string(rune('a'+i)), "addr", zone, ACTIVE, time.Now())
+				require.NoError(t, err)
+			}
+			require.NoError(t, desc.SetPartitionState("1", testData.state))
+
+			assert.Equal(t, testData.expectedChanged, desc.UpdatePartitionState("1", 3, testData.zoneAwarenessEnabled))
+			assert.Equal(t, testData.expectedState, desc.Partitions["1"].State)
+		})
+	}
+}
+
+func TestPartitionRingDesc_AddInstance(t *testing.T) {
+	desc := NewPartitionRingDesc()
+
+	_, err := desc.AddInstance("1", "ingester-1", "addr", "zone-a", ACTIVE, time.Now())
+	require.Error(t, err)
+
+	desc.AddPartition("1", []uint32{1, 2}, time.Now())
+	_, err = desc.AddInstance("1", "ingester-1", "addr", "zone-a", ACTIVE, time.Now())
+	require.NoError(t, err)
+
+	partitionID, ok := desc.FindPartitionByInstance("ingester-1")
+	assert.True(t, ok)
+	assert.Equal(t, "1", partitionID)
+
+	desc.RemoveInstance("1", "ingester-1")
+	_, ok = desc.FindPartitionByInstance("ingester-1")
+	assert.False(t, ok)
+}
+
+func TestPartitionRingDesc_Merge(t *testing.T) {
+	now := time.Now().Unix()
+
+	firstRing := func() *PartitionRingDesc {
+		return &PartitionRingDesc{
+			Partitions: map[string]PartitionDesc{
+				"1": {State: PARTITION_ACTIVE, Tokens: []uint32{10, 20}, Timestamp: now, Instances: map[string]InstanceDesc{
+					"ingester-a-1": {Addr: "addr-a-1", Zone: "zone-a", State: ACTIVE, Timestamp: now},
+					"ingester-b-1": {Addr: "addr-b-1", Zone: "zone-b", State: ACTIVE, Timestamp: now},
+				}},
+			},
+		}
+	}
+
+	t.Run("newer partition state and instance heartbeat are merged", func(t *testing.T) {
+		ring := firstRing()
+		change, err := ring.mergeWithTime(&PartitionRingDesc{
+			Partitions: map[string]PartitionDesc{
+				"1": {State: PARTITION_READONLY, Tokens: []uint32{20, 10}, Timestamp: now + 5, Instances: map[string]InstanceDesc{
+					"ingester-a-1": {Addr: "addr-a-1", Zone: "zone-a", State: ACTIVE, Timestamp: now + 5},
+					"ingester-b-1": {Addr: "addr-b-1", Zone: "zone-b", State: ACTIVE, Timestamp: now - 5},
+				}},
+				"2": {State: PARTITION_NON_READY, Tokens: []uint32{30}, Timestamp: now, Instances: map[string]InstanceDesc{
+					"ingester-a-2": {Addr: "addr-a-2", Zone: "zone-a", State: ACTIVE, Timestamp: now},
+				}},
+			},
+		}, false, time.Unix(now, 0))
+		require.NoError(t, err)
+
+		assert.Equal(t, &PartitionRingDesc{
+			Partitions: map[string]PartitionDesc{
+				"1": {State: PARTITION_READONLY, Tokens: []uint32{10, 20}, Timestamp: now + 5, Instances: map[string]InstanceDesc{
+					"ingester-a-1": {Addr: "addr-a-1", Zone: "zone-a", State: ACTIVE, Timestamp: now + 5},
+					"ingester-b-1": {Addr: "addr-b-1", Zone: "zone-b", State: ACTIVE, Timestamp: now},
+				}},
+				"2": {State: PARTITION_NON_READY, Tokens: []uint32{30}, Timestamp: now, Instances: map[string]InstanceDesc{
+					"ingester-a-2": {Addr: "addr-a-2", Zone: "zone-a", State: ACTIVE, Timestamp: now},
+				}},
+			},
+		}, ring)
+
+		// The change only includes the updated instances.
+		assert.Equal(t, &PartitionRingDesc{
+			Partitions: map[string]PartitionDesc{
+				"1": {State: PARTITION_READONLY, Tokens: []uint32{10, 20}, Timestamp: now + 5, Instances: map[string]InstanceDesc{
+					"ingester-a-1": {Addr: "addr-a-1", Zone: "zone-a", State: ACTIVE, Timestamp: now + 5},
+				}},
+				"2": {State: PARTITION_NON_READY, Tokens: []uint32{30}, Timestamp: now, Instances: map[string]InstanceDesc{
+					"ingester-a-2": {Addr: "addr-a-2", Zone: "zone-a", State: ACTIVE, Timestamp: now},
+				}},
+			},
+		}, change)
+	})
+
+	t.Run("older updates are ignored", func(t *testing.T) {
+		ring := firstRing()
+		change, err := ring.mergeWithTime(&PartitionRingDesc{
+			Partitions: map[string]PartitionDesc{
+				"1": {State: PARTITION_NON_READY, Tokens: []uint32{10, 20}, Timestamp: now - 5, Instances: map[string]InstanceDesc{
+					"ingester-a-1": {Addr: "addr-a-1", Zone: "zone-a", State: LEAVING, Timestamp: now - 5},
+				}},
+			},
+		}, false, time.Unix(now, 0))
+		require.NoError(t, err)
+		assert.Nil(t, change)
+		assert.Equal(t, firstRing(), ring)
+	})
+
+	t.Run("deleted partition and left instance are accepted with the same timestamp", func(t *testing.T) {
+		ring := firstRing()
+		_, err := ring.mergeWithTime(&PartitionRingDesc{
+			Partitions: map[string]PartitionDesc{
+				"1": {State: PARTITION_ACTIVE, Tokens: []uint32{10, 20}, Timestamp: now, Instances: map[string]InstanceDesc{
+					"ingester-a-1": {Addr: "addr-a-1", Zone: "zone-a", State: LEFT, Timestamp: now},
+				}},
+			},
+		}, false, time.Unix(now, 0))
+		require.NoError(t, err)
+		assert.Equal(t, LEFT, ring.Partitions["1"].Instances["ingester-a-1"].State)
+
+		_, err = ring.mergeWithTime(&PartitionRingDesc{
+			Partitions: map[string]PartitionDesc{
+				"1": {State: PARTITION_DELETED, Timestamp: now},
+			},
+		}, false, time.Unix(now, 0))
+		require.NoError(t, err)
+		assert.Equal(t, PARTITION_DELETED, ring.Partitions["1"].State)
+		assert.Empty(t, ring.Partitions["1"].Tokens)
+	})
+
+	t.Run("local CAS marks missing instances as left and missing partitions as deleted", func(t *testing.T) {
+		ring := firstRing()
+		ring.Partitions["2"] = PartitionDesc{State: PARTITION_ACTIVE, Tokens: []uint32{30}, Timestamp: now, Instances: map[string]InstanceDesc{}}
+
+		_, err := ring.mergeWithTime(&PartitionRingDesc{
+			Partitions: map[string]PartitionDesc{
+				"1": {State: PARTITION_ACTIVE, Tokens: []uint32{10, 20}, Timestamp: now, Instances: map[string]InstanceDesc{
+					"ingester-a-1": {Addr: "addr-a-1", Zone: "zone-a", State: ACTIVE, Timestamp: now},
+				}},
+			},
+		}, true, time.Unix(now+10, 0))
+		require.NoError(t, err)
+
+		assert.Equal(t, InstanceDesc{Addr: "addr-b-1", Zone: "zone-b", State: LEFT, Timestamp: now + 10}, ring.Partitions["1"].Instances["ingester-b-1"])
+		assert.Equal(t, PartitionDesc{State: PARTITION_DELETED, Timestamp: now + 10, Instances: map[string]InstanceDesc{}}, ring.Partitions["2"])
+
+		assert.Equal(t, map[string]time.Time{
+			"1/ingester-b-1": time.Unix(now+10, 0),
+			"2":              time.Unix(now+10, 0),
+		}, ring.Tombstones())
+
+		total, removed := ring.RemoveTombstones(time.Time{})
+		assert.Equal(t, 0, total)
+		assert.Equal(t, 2, removed)
+		assert.Equal(t, []string{"1"}, ring.MergeContent())
+		assert.Len(t, ring.Partitions["1"].Instances, 1)
+	})
+
+	t.Run("timestamps too far in the future are rejected", func(t *testing.T) {
+		ring := firstRing()
+		_, err := ring.mergeWithTime(&PartitionRingDesc{
+			Partitions: map[string]PartitionDesc{
+				"1": {State: PARTITION_ACTIVE, Timestamp: now + int64(time.Hour.Seconds())},
+			},
+		}, false, time.Unix(now, 0))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,705 @@
+package ring
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/rand"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/cortexproject/cortex/pkg/ring/kv"
+	shardUtil "github.com/cortexproject/cortex/pkg/ring/shard"
+	"github.com/cortexproject/cortex/pkg/util/services"
+)
+
+// PartitionRingConfig is the config of the partition ring.
+type PartitionRingConfig struct {
+	Enabled   bool `yaml:"enabled"`
+	NumTokens int  `yaml:"num_tokens"`
+}
+
+// RegisterFlagsWithPrefix adds the flags required to config this to the given FlagSet with a specified prefix
+func (cfg *PartitionRingConfig) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, prefix+"ring.partition-ring.enabled", false, "EXPERIMENTAL: True to enable the partition ring. Ingesters join a partition, which is replicated to all its ingesters, and distributors shard series to partitions instead of ingesters. When zone-awareness is enabled, a partition is expected to have one ingester per zone.")
+	f.IntVar(&cfg.NumTokens, prefix+"ring.partition-ring.num-tokens", 128, "EXPERIMENTAL: Number of tokens for each partition of the partition ring.")
+}
+
+// partitionInstanceState returns the instance state equivalent to the partition state, which is used
+// to check whether a partition is eligible for an operation.
+func partitionInstanceState(state PartitionState) InstanceState {
+	switch state {
+	case PARTITION_ACTIVE:
+		return ACTIVE
+	case PARTITION_READONLY:
+		return READONLY
+	case PARTITION_NON_READY:
+		return JOINING
+	default:
+		return LEFT
+	}
+}
+
+// PartitionRing holds the information about the partitions of the partition ring. Series are
+// sharded to partitions, and each partition is replicated to all its instances.
+type PartitionRing struct {
+	services.Service
+
+	key      string
+	cfg      Config
+	KVClient kv.Client
+	strategy ReplicationStrategy
+
+	mtx        sync.RWMutex
+	ringDesc   *PartitionRingDesc
+	ringTokens []uint32
+
+	// Maps a token with the ID of the partition holding it, and an instance address with its ID.
+	// These maps are immutable and shared "as is" between subrings.
+	ringPartitionByToken map[uint32]string
+	ringInstanceIdByAddr map[string]string
+
+	// When did the partitions change the last time (instance heartbeats are ignored for this timestamp).
+	lastTopologyChange time.Time
+
+	// Cache of shuffle-sharded subrings per identifier. Invalidated when topology changes.
+	// If set to nil, no caching is done (used by subrings).
+	shuffledSubringCache map[subringCacheKey]*PartitionRing
+
+	numPartitionsGaugeVec *prometheus.GaugeVec
+
+	logger log.Logger
+}
+
+// NewPartitionRing creates a new PartitionRing. Being a service, PartitionRing needs to be started to do anything.
+func NewPartitionRing(cfg Config, name, key string, logger log.Logger, reg prometheus.Registerer) (*PartitionRing, error) {
+	// Suffix all client names with "-partition-ring" to denote this kv client is used by the partition ring
+	store, err := kv.NewClient(
+		cfg.KVStore,
+		GetPartitionRingCodec(),
+		kv.RegistererWithKVName(reg, name+"-partition-ring"),
+		logger,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPartitionRingWithStoreClient(cfg, name, key, store, reg, logger)
+}
+
+func NewPartitionRingWithStoreClient(cfg Config, name, key string, store kv.Client, reg prometheus.Registerer, logger log.Logger) (*PartitionRing, error) {
+	if cfg.ReplicationFactor <= 0 {
+		return nil, fmt.Errorf("ReplicationFactor must be greater than zero: %d", cfg.ReplicationFactor)
+	}
+
+	r := &PartitionRing{
+		key:                  key,
+		cfg:                  cfg,
+		KVClient:             store,
+		strategy:             NewDefaultReplicationStrategy(),
+		ringDesc:             NewPartitionRingDesc(),
+		shuffledSubringCache: map[subringCacheKey]*PartitionRing{},
+		numPartitionsGaugeVec: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Name:        "ring_partitions",
+			Help:        "Number of partitions in the partition ring",
+			ConstLabels: map[string]string{"name": name}},
+			[]string{"state"}),
+		logger: logger,
+	}
+
+	r.Service = services.NewBasicService(r.starting, r.loop, nil).WithName(fmt.Sprintf("%s partition ring client", name))
+	return r, nil
+}
+
+func (r *PartitionRing) starting(ctx context.Context) error {
+	// Get the initial ring state so that, as soon as the service will be running, the in-memory
+	// ring would be already populated.
+	value, err := r.KVClient.Get(ctx, r.key)
+	if err != nil {
+		return errors.Wrap(err, "unable to initialise partition ring state")
+	}
+	if value != nil {
+		r.updateRingState(value.(*PartitionRingDesc))
+	} else {
+		level.Info(r.logger).Log("msg", "partition ring doesn't exist in KV store yet")
+	}
+	return nil
+}
+
+func (r *PartitionRing) loop(ctx context.Context) error {
+	r.KVClient.WatchKey(ctx, r.key, func(value any) bool {
+		if value == nil {
+			level.Info(r.logger).Log("msg", "partition ring doesn't exist in KV store yet")
+			return true
+		}
+
+		r.updateRingState(value.(*PartitionRingDesc))
+		return true
+	})
+	return nil
+}
+
+func (r *PartitionRing) updateRingState(ringDesc *PartitionRingDesc) {
+	// Filter out deleted partitions, instances which left the ring and instances
+	// belonging to excluded zones.
+	for partitionID, partition := range ringDesc.Partitions {
+		if partition.State == PARTITION_DELETED {
+			delete(ringDesc.Partitions, partitionID)
+			continue
+		}
+		for instanceID, instance := range partition.Instances {
+			if instance.State == LEFT || slices.Contains(r.cfg.ExcludedZones, instance.Zone) {
+				delete(partition.Instances, instanceID)
+			}
+		}
+	}
+
+	r.mtx.RLock()
+	prevRing := r.ringDesc
+	r.mtx.RUnlock()
+
+	if partitionRingTopologyEqual(prevRing, ringDesc) {
+		r.mtx.Lock()
+		r.ringDesc = ringDesc
+		r.updateRingMetrics()
+		r.mtx.Unlock()
+		return
+	}
+
+	ringTokens := ringDesc.GetTokens()
+	ringPartitionByToken := ringDesc.getPartitionsByToken()
+	ringInstanceIdByAddr := map[string]string{}
+	for _, partition := range ringDesc.Partitions {
+		for instanceID, instance := range partition.Instances {
+			ringInstanceIdByAddr[instance.Addr] = instanceID
+		}
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.ringDesc = ringDesc
+	r.ringTokens = ringTokens
+	r.ringPartitionByToken = ringPartitionByToken
+	r.ringInstanceIdByAddr = ringInstanceIdByAddr
+	r.lastTopologyChange = time.Now()
+	if r.shuffledSubringCache != nil {
+		// Invalidate all cached subrings.
+		r.shuffledSubringCache = make(map[subringCacheKey]*PartitionRing)
+	}
+	r.updateRingMetrics()
+}
+
+// partitionRingTopologyEqual returns whether the two rings have the same partitions, with the
+// same states, tokens and instances. Instance states and heartbeats are ignored.
+func partitionRingTopologyEqual(a, b *PartitionRingDesc) bool {
+	if len(a.Partitions) != len(b.Partitions) {
+		return false
+	}
+
+	for partitionID, partitionA := range a.Partitions {
+		partitionB, ok := b.Partitions[partitionID]
+		if !ok || partitionA.State != partitionB.State || !tokensEqual(partitionA.Tokens, partitionB.Tokens) {
+			return false
+		}
+		if len(partitionA.Instances) != len(partitionB.Instances) {
+			return false
+		}
+		for instanceID, instanceA := range partitionA.Instances {
+			instanceB, ok := partitionB.Instances[instanceID]
+			if !ok || instanceA.Addr != instanceB.Addr || instanceA.Zone != instanceB.Zone || instanceA.RegisteredTimestamp != instanceB.RegisteredTimestamp {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (r *PartitionRing) updateRingMetrics() {
+	numByState := map[string]int{}
+	for _, state := range []PartitionState{PARTITION_NON_READY, PARTITION_ACTIVE, PARTITION_READONLY} {
+		numByState[state.String()] = 0
+	}
+	for _, partition := range r.ringDesc.Partitions {
+		numByState[partition.State.String()]++
+	}
+	for state, count := range numByState {
+		r.numPartitionsGaugeVec.WithLabelValues(state).Set(float64(count))
+	}
+}
+
+// isPartitionEligible returns whether the partition can be used for the given operation.
+func isPartitionEligible(partition PartitionDesc, op Operation) bool {
+	return op.IsInstanceInStateHealthy(partitionInstanceState(partition.State))
+}
+
+// healthyPartitionInstances returns the instances of the partition which are healthy for the given operation.
+func (r *PartitionRing) healthyPartitionInstances(partition PartitionDesc, op Operation, storageLastUpdate time.Time) []InstanceDesc {
+	instances := make([]InstanceDesc, 0, len(partition.Instances))
+	for _, instance := range partition.Instances {
+		if instance.IsHealthy(op, r.cfg.HeartbeatTimeout, storageLastUpdate) {
+			instances = append(instances, instance)
+		}
+	}
+	return instances
+}
+
+// Get returns the instances of the partition owning the given key. Partitions which aren't eligible
+// for the operation are skipped. When the operation can be executed on partitions which aren't ACTIVE,
+// these partitions are included until the first ACTIVE partition is found, because the series may have
+// been written to any of them. The replication set has the instances grouped by partition, with the max
+// errors of each partition: the quorum of the ACTIVE partition, and a single instance of the others.
+func (r *PartitionRing) Get(key uint32, op Operation, bufDescs []InstanceDesc, bufHosts []string, _ map[string]int) (ReplicationSet, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	if len(r.ringTokens) == 0 {
+		return ReplicationSet{}, ErrEmptyRing
+	}
+
+	var (
+		instances          = bufDescs[:0]
+		partitions         [][]InstanceDesc
+		maxErrors          []int
+		start              = searchToken(r.ringTokens, key)
+		storageLastUpdate  = r.KVClient.LastUpdateTime(r.key)
+		distinctPartitions = bufHosts[:0]
+	)
+
+	for i, iterations := start, 0; iterations < len(r.ringTokens); i++ {
+		iterations++
+		// Wrap i around in the ring.
+		i %= len(r.ringTokens)
+
+		partitionID, ok := r.ringPartitionByToken[r.ringTokens[i]]
+		if !ok {
+			// This should never happen unless a bug in the ring code.
+			return ReplicationSet{}, ErrInconsistentTokensInfo
+		}
+
+		if slices.Contains(distinctPartitions, partitionID) {
+			continue
+		}
+		distinctPartitions = append(distinctPartitions, partitionID)
+
+		partition := r.ringDesc.Partitions[partitionID]
+		if !isPartitionEligible(partition, op) {
+			continue
+		}
+
+		healthy, maxFailure, err := r.partitionQuorum(partition, op, storageLastUpdate)
+		if err != nil {
+			return ReplicationSet{}, err
+		}
+		instances = append(instances, healthy...)
+		partitions = append(partitions, healthy)
+		maxErrors = append(maxErrors, maxFailure)
+
+		if partition.State == PARTITION_ACTIVE {
+			break
+		}
+	}
+
+	if len(partitions) == 0 {
+		return ReplicationSet{}, ErrEmptyRing
+	}
+
+	return ReplicationSet{
+		Instances:           instances,
+		Partitions:          partitions,
+		PartitionsMaxErrors: maxErrors,
+	}, nil
+}
+
+// GetAllHealthy implements ReadRing.
+func (r *PartitionRing) GetAllHealthy(op Operation) (ReplicationSet, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	if len(r.ringDesc.Partitions) == 0 {
+		return ReplicationSet{}, ErrEmptyRing
+	}
+
+	storageLastUpdate := r.KVClient.LastUpdateTime(r.key)
+	var instances []InstanceDesc
+	for _, partition := range r.ringDesc.Partitions {
+		if isPartitionEligible(partition, op) {
+			instances = append(instances, r.healthyPartitionInstances(partition, op, storageLastUpdate)...)
+		}
+	}
+
+	return ReplicationSet{
+		Instances: instances,
+		MaxErrors: 0,
+	}, nil
+}
+
+// GetAllInstanceDescs implements ReadRing.
+func (r *PartitionRing) GetAllInstanceDescs(op Operation) ([]InstanceDesc, []InstanceDesc, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	if len(r.ringDesc.Partitions) == 0 {
+		return nil, nil, ErrEmptyRing
+	}
+
+	var healthyInstances, unhealthyInstances []InstanceDesc
+	storageLastUpdate := r.KVClient.LastUpdateTime(r.key)
+	for _, partition := range r.ringDesc.Partitions {
+		eligible := isPartitionEligible(partition, op)
+		for _, instance := range partition.Instances {
+			if eligible && instance.IsHealthy(op, r.cfg.HeartbeatTimeout, storageLastUpdate) {
+				healthyInstances = append(healthyInstances, instance)
+			} else {
+				unhealthyInstances = append(unhealthyInstances, instance)
+			}
+		}
+	}
+
+	return healthyInstances, unhealthyInstances, nil
+}
+
+// GetInstanceDescsForOperation implements ReadRing.
+func (r *PartitionRing) GetInstanceDescsForOperation(op Operation) (map[string]InstanceDesc, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	if len(r.ringDesc.Partitions) == 0 {
+		return map[string]InstanceDesc{}, ErrEmptyRing
+	}
+
+	storageLastUpdate := r.KVClient.LastUpdateTime(r.key)
+	instanceDescs := make(map[string]InstanceDesc)
+	for _, partition := range r.ringDesc.Partitions {
+		if !isPartitionEligible(partition, op) {
+			continue
+		}
+		for id, instance := range partition.Instances {
+			if instance.IsHealthy(op, r.cfg.HeartbeatTimeout, storageLastUpdate) {
+				instanceDescs[id] = instance
+			}
+		}
+	}
+
+	return instanceDescs, nil
+}
+
+// partitionQuorum returns the healthy instances of the partition and the number of them which can
+// fail. The quorum of an ACTIVE partition is the quorum of the writes, while only 1 healthy instance
+// is required to read a partition which isn't written anymore.
+func (r *PartitionRing) partitionQuorum(partition PartitionDesc, op Operation, storageLastUpdate time.Time) ([]InstanceDesc, int, error) {
+	if partition.State != PARTITION_ACTIVE {
+		healthy := r.healthyPartitionInstances(partition, op, storageLastUpdate)
+		if len(healthy) == 0 {
+			return nil, 0, ErrTooManyUnhealthyInstances
+		}
+		return healthy, len(healthy) - 1, nil
+	}
+
+	candidates := make([]InstanceDesc, 0, len(partition.Instances))
+	for _, instance := range partition.Instances {
+		candidates = append(candidates, instance)
+	}
+	return r.strategy.Filter(candidates, op, r.cfg.ReplicationFactor, r.cfg.HeartbeatTimeout, r.cfg.ZoneAwarenessEnabled, storageLastUpdate)
+}
+
+// GetReplicationSetForOperation returns the healthy instances of each partition eligible for the
+// operation, grouped by partition, with the max errors of each partition, so that the operation is
+// executed on the quorum of each partition. An error is returned if the quorum of a partition can't
+// be reached.
+func (r *PartitionRing) GetReplicationSetForOperation(op Operation) (ReplicationSet, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	if len(r.ringTokens) == 0 {
+		return ReplicationSet{}, ErrEmptyRing
+	}
+
+	partitionIDs := make([]string, 0, len(r.ringDesc.Partitions))
+	for partitionID := range r.ringDesc.Partitions {
+		partitionIDs = append(partitionIDs, partitionID)
+	}
+	sortPartitionIDs(partitionIDs)
+
+	var (
+		instances         []InstanceDesc
+		partitions        [][]InstanceDesc
+		maxErrors         []int
+		storageLastUpdate = r.KVClient.LastUpdateTime(r.key)
+	)
+	for _, partitionID := range partitionIDs {
+		partition := r.ringDesc.Partitions[partitionID]
+		if !isPartitionEligible(partition, op) || len(partition.Instances) == 0 {
+			continue
+		}
+
+		healthy, maxFailure, err := r.partitionQuorum(partition, op, storageLastUpdate)
+		if err != nil {
+			return ReplicationSet{}, err
+		}
+
+		// Spread the load across the instances of the partition.
+		rand.Shuffle(len(healthy), func(i, j int) { healthy[i], healthy[j] = healthy[j], healthy[i] })
+		instances = append(instances, healthy...)
+		partitions = append(partitions, healthy)
+		maxErrors = append(maxErrors, maxFailure)
+	}
+
+	if len(partitions) == 0 {
+		return ReplicationSet{}, ErrEmptyRing
+	}
+
+	return ReplicationSet{
+		Instances:           instances,
+		Partitions:          partitions,
+		PartitionsMaxErrors: maxErrors,
+	}, nil
+}
+
+// ReplicationFactor of the ring.
+func (r *PartitionRing) ReplicationFactor() int {
+	return r.cfg.ReplicationFactor
+}
+
+// InstancesCount returns the number of instances in the ring.
+func (r *PartitionRing) InstancesCount() int {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	count := 0
+	for _, partition := range r.ringDesc.Partitions {
+		count += len(partition.Instances)
+	}
+	return count
+}
+
+// PartitionsCount returns the number of partitions in the ring.
+func (r *PartitionRing) PartitionsCount() int {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	return len(r.ringDesc.Partitions)
+}
+
+// ShuffleShard returns a subring made of the partitions selected for the provided identifier (eg. a
+// tenant ID). The size is the number of instances, so the subring has size / replication factor partitions
+// (rounded up). READONLY partitions are included in the subring but not counted in the size, so that their
+// series can still be read.
+func (r *PartitionRing) ShuffleShard(identifier string, size int) ReadRing {
+	return r.shuffleShardWithCache(identifier, size)
+}
+
+// ShuffleShardWithZoneStability is like ShuffleShard. The partitions are already replicated across
+// zones, so the subring is zone stable by design.
+func (r *PartitionRing) ShuffleShardWithZoneStability(identifier string, size int) ReadRing {
+	return r.shuffleShardWithCache(identifier, size)
+}
+
+// ShuffleShardWithLookback is like ShuffleShard() but the returned subring includes all partitions
+// that have been part of the identifier's shard since "now - lookbackPeriod".
+//
+// This function doesn't support caching.
+func (r *PartitionRing) ShuffleShardWithLookback(identifier string, size int, lookbackPeriod time.Duration, now time.Time) ReadRing {
+	numPartitions := r.shardSizeToPartitions(size)
+	if numPartitions <= 0 || r.PartitionsCount() <= numPartitions {
+		return r
+	}
+
+	return r.shuffleShard(identifier, numPartitions, lookbackPeriod, now)
+}
+
+func (r *PartitionRing) shardSizeToPartitions(size int) int {
+	if size <= 0 {
+		return 0
+	}
+	return (size + r.cfg.ReplicationFactor - 1) / r.cfg.ReplicationFactor
+}
+
+func (r *PartitionRing) shuffleShardWithCache(identifier string, size int) ReadRing {
+	numPartitions := r.shardSizeToPartitions(size)
+	if numPartitions <= 0 || r.PartitionsCount() <= numPartitions {
+		return r
+	}
+
+	if cached := r.getCachedShuffledSubring(identifier, numPartitions); cached != nil {
+		return cached
+	}
+
+	result := r.shuffleShard(identifier, numPartitions, 0, time.Now())
+
+	r.setCachedShuffledSubring(identifier, numPartitions, result)
+	return result
+}
+
+func (r *PartitionRing) shuffleShard(identifier string, numPartitions int, lookbackPeriod time.Duration, now time.Time) *PartitionRing {
+	lookbackUntil := now.Add(-lookbackPeriod).Unix()
+
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	shard := make(map[string]PartitionDesc, numPartitions)
+
+	// To select one more partition while guaranteeing the "consistency" property, we do pick
+	// a random value from the generator and resolve uniqueness collisions (if any) continuing
+	// walking the ring.
+	random := rand.New(rand.NewSource(shardUtil.ShuffleShardSeed(identifier, "")))
+	for i := 0; i < numPartitions; i++ {
+		start := searchToken(r.ringTokens, random.Uint32())
+		found := false
+
+		for p, iterations := start, 0; iterations < len(r.ringTokens); p++ {
+			iterations++
+
+			// Wrap p around in the ring.
+			p %= len(r.ringTokens)
+
+			partitionID, ok := r.ringPartitionByToken[r.ringTokens[p]]
+			if !ok {
+				// This should never happen unless a bug in the ring code.
+				panic(ErrInconsistentTokensInfo)
+			}
+
+			// Ensure we select an unique partition.
+			if _, ok := shard[partitionID]; ok {
+				continue
+			}
+
+			partition := r.ringDesc.Partitions[partitionID]
+			shard[partitionID] = partition
+
+			// Partitions registered within the lookback period and READONLY partitions are included
+			// in the subring, but we continue selecting partitions.
+			if (lookbackPeriod > 0 && partition.RegisteredTimestamp >= lookbackUntil) || partition.State == PARTITION_READONLY {
+				continue
+			}
+
+			found = true
+			break
+		}
+
+		// If one more partition has not been found, we can stop looking for more partitions,
+		// because the ring has no more partitions which haven't been already selected.
+		if !found {
+			break
+		}
+	}
+
+	// Build a read-only ring for the shard.
+	shardDesc := &PartitionRingDesc{Partitions: shard}
+
+	return &PartitionRing{
+		key:        r.key,
+		cfg:        r.cfg,
+		KVClient:   r.KVClient,
+		strategy:   r.strategy,
+		ringDesc:   shardDesc,
+		ringTokens: shardDesc.GetTokens(),
+
+		// We reference the original maps as is in order to avoid copying. It's safe to do
+		// because these maps are immutable by design and they're a superset of the actual
+		// partitions and instances within the subring.
+		ringPartitionByToken: r.ringPartitionByToken,
+		ringInstanceIdByAddr: r.ringInstanceIdByAddr,
+
+		// For caching to work, remember these values.
+		lastTopologyChange: r.lastTopologyChange,
+
+		logger: r.logger,
+	}
+}
+
+func (r *PartitionRing) getCachedShuffledSubring(identifier string, numPartitions int) *PartitionRing {
+	if r.cfg.SubringCacheDisabled {
+		return nil
+	}
+
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	// if shuffledSubringCache map is nil, reading it returns default value (nil pointer).
+	cached := r.shuffledSubringCache[subringCacheKey{identifier: identifier, shardSize: numPartitions}]
+	if cached == nil {
+		return nil
+	}
+
+	cached.mtx.Lock()
+	defer cached.mtx.Unlock()
+
+	// Update the instances states and heartbeats. We know that the topology is the same,
+	// so the partitions and their instances are equal.
+	for partitionID := range cached.ringDesc.Partitions {
+		cached.ringDesc.Partitions[partitionID] = r.ringDesc.Partitions[partitionID]
+	}
+	return cached
+}
+
+func (r *PartitionRing) setCachedShuffledSubring(identifier string, numPartitions int, subring *PartitionRing) {
+	if subring == nil || r.cfg.SubringCacheDisabled {
+		return
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	// Only cache if *this* ring hasn't changed since computing result
+	// (which can happen between releasing the read lock and getting read-write lock).
+	if r.shuffledSubringCache != nil && r.lastTopologyChange.Equal(subring.lastTopologyChange) {
+		r.shuffledSubringCache[subringCacheKey{identifier: identifier, shardSize: numPartitions}] = subring
+	}
+}
+
+// CleanupShuffleShardCache implements ReadRing.
+func (r *PartitionRing) CleanupShuffleShardCache(identifier string) {
+	if r.cfg.SubringCacheDisabled {
+		return
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	for k := range r.shuffledSubringCache {
+		if k.identifier == identifier {
+			delete(r.shuffledSubringCache, k)
+		}
+	}
+}
+
+// GetInstanceState returns the current state of an instance or an error if the
+// instance does not exist in the ring.
+func (r *PartitionRing) GetInstanceState(instanceID string) (InstanceState, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	for _, partition := range r.ringDesc.Partitions {
+		if instance, ok := partition.Instances[instanceID]; ok {
+			return instance.GetState(), nil
+		}
+	}
+	return PENDING, ErrInstanceNotFound
+}
+
+// GetInstanceIdByAddr implements ReadRing.
+func (r *PartitionRing) GetInstanceIdByAddr(addr string) (string, error) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	if i, ok := r.ringInstanceIdByAddr[addr]; ok {
+		return i, nil
+	}
+
+	return "notFound", ErrInstanceNotFound
+}
+
+// HasInstance returns whether the ring contains an instance matching the provided instanceID.
+func (r *PartitionRing) HasInstance(instanceID string) bool {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	_, ok := r.ringDesc.FindPartitionByInstance(instanceID)
+	return ok
+}
@@ -0,0 +1,225 @@
+package ring
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexproject/cortex/pkg/ring/kv/consul"
+	"github.com/cortexproject/cortex/pkg/util/flagext"
+)
+
+// newTestPartitionRingDesc returns a ring with the given partitions, each one owning the given token
+// and having one healthy instance per zone.
+func newTestPartitionRingDesc(tokensByPartition map[string]uint32, zones ...string) *PartitionRingDesc {
+	now := time.Now()
+	desc := NewPartitionRingDesc()
+	for partitionID, token := range tokensByPartition {
+		desc.AddPartition(partitionID, []uint32{token}, now)
+		for _, zone := range zones {
+			instanceID := fmt.Sprintf("ingester-%s-%s", zone, partitionID)
+			_, _ = desc.AddInstance(partitionID, instanceID, instanceID+":9095", zone, ACTIVE, now)
+		}
+		desc.UpdatePartitionState(partitionID, len(zones), true)
+	}
+	return desc
+}
+
+func newTestPartitionRing(t *testing.T, desc *PartitionRingDesc) *PartitionRing {
+	store, closer := consul.NewInMemoryClient(GetPartitionRingCodec(), log.NewNopLogger(), nil)
+	t.Cleanup(func() { assert.NoError(t, closer.Close()) })
+
+	var cfg Config
+	flagext.DefaultValues(&cfg)
+	cfg.ReplicationFactor = 3
+	cfg.ZoneAwarenessEnabled = true
+
+	r, err := NewPartitionRingWithStoreClient(cfg, "ingester", "partition-ring", store, nil, log.NewNopLogger())
+	require.NoError(t, err)
+	r.updateRingState(desc.Clone().(*PartitionRingDesc))
+	return r
+}
+
+func setTestInstanceUnhealthy(desc *PartitionRingDesc, partitionID, instanceID string) {
+	instance := desc.Partitions[partitionID].Instances[instanceID]
+	instance.Timestamp = time.Now().Add(-time.Hour).Unix()
+	desc.Partitions[partitionID].Instances[instanceID] = instance
+}
+
+func instanceIDs(r *PartitionRing, instances []InstanceDesc) []string {
+	ids := make([]string, 0, len(instances))
+	for _, instance := range instances {
+		id, _ := r.GetInstanceIdByAddr(instance.Addr)
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestPartitionRing_Get(t *testing.T) {
+	desc := newTestPartitionRingDesc(map[string]uint32{"1": 100, "2": 200, "3": 300}, "zone-a", "zone-b", "zone-c")
+	r := newTestPartitionRing(t, desc)
+
+	// The series is written to the ACTIVE partition owning the key.
+	set, err := r.Get(150, Write, nil, nil, nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"ingester-zone-a-2", "ingester-zone-b-2", "ingester-zone-c-2"}, instanceIDs(r, set.Instances))
+	assert.Len(t, set.Partitions, 1)
+	assert.Equal(t, []int{1}, set.PartitionsMaxErrors)
+
+	// Partitions which aren't ACTIVE are skipped on writes, but read.
+	require.NoError(t, desc.SetPartitionState("2", PARTITION_NON_READY))
+	setTestInstanceUnhealthy(desc, "3", "ingester-zone-a-3")
+	r.updateRingState(desc.Clone().(*PartitionRingDesc))
+
+	set, err = r.Get(150, Write, nil, nil, nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"ingester-zone-b-3", "ingester-zone-c-3"}, instanceIDs(r, set.Instances))
+	assert.Equal(t, []int{0}, set.PartitionsMaxErrors)
+
+	set, err = r.Get(150, Read, nil, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, set.Partitions, 2)
+	assert.ElementsMatch(t, []string{"ingester-zone-a-2", "ingester-zone-b-2", "ingester-zone-c-2"}, instanceIDs(r, set.Partitions[0]))
+	assert.ElementsMatch(t, []string{"ingester-zone-b-3", "ingester-zone-c-3"}, instanceIDs(r, set.Partitions[1]))
+
+	// A single instance of the partition which isn't ACTIVE is required, while the quorum
+	// of the ACTIVE partition is honored.
+	assert.Equal(t, []int{2, 0}, set.PartitionsMaxErrors)
+	assert.Zero(t, set.MaxErrors)
+
+	// The quorum of the partition is required on writes.
+	setTestInstanceUnhealthy(desc, "3", "ingester-zone-b-3")
+	r.updateRingState(desc.Clone().(*PartitionRingDesc))
+	_, err = r.Get(150, Write, nil, nil, nil)
+	require.Error(t, err)
+
+	// The key is wrapped around the ring.
+	set, err = r.Get(350, Write, nil, nil, nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"ingester-zone-a-1", "ingester-zone-b-1", "ingester-zone-c-1"}, instanceIDs(r, set.Instances))
+}
+
+func TestPartitionRing_GetReplicationSetForOperation(t *testing.T) {
+	desc := newTestPartitionRingDesc(map[string]uint32{"1": 100, "2": 200, "3": 300}, "zone-a", "zone-b", "zone-c")
+
+	// Instances failing in different zones don't prevent reading all partitions.
+	setTestInstanceUnhealthy(desc, "1", "ingester-zone-a-1")
+	setTestInstanceUnhealthy(desc, "2", "ingester-zone-b-2")
+	r := newTestPartitionRing(t, desc)
+
+	set, err := r.GetReplicationSetForOperation(Read)
+	require.NoError(t, err)
+	require.Len(t, set.Partitions, 3)
+	assert.ElementsMatch(t, []string{"ingester-zone-b-1", "ingester-zone-c-1"}, instanceIDs(r, set.Partitions[0]))
+	assert.ElementsMatch(t, []string{"ingester-zone-a-2", "ingester-zone-c-2"}, instanceIDs(r, set.Partitions[1]))
+	assert.ElementsMatch(t, []string{"ingester-zone-a-3", "ingester-zone-b-3", "ingester-zone-c-3"}, instanceIDs(r, set.Partitions[2]))
+	assert.Len(t, set.Instances, 7)
+
+	// The partitions are read with the quorum of the writes.
+	assert.Equal(t, []int{0, 0, 1}, set.PartitionsMaxErrors)
+	assert.Zero(t, set.MaxErrors)
+
+	// A partition whose quorum can't be reached can't be read.
+	setTestInstanceUnhealthy(desc, "1", "ingester-zone-b-1")
+	r.updateRingState(desc.Clone().(*PartitionRingDesc))
+
+	_, err = r.GetReplicationSetForOperation(Read)
+	require.Error(t, err)
+
+	// Only 1 healthy instance is required to read a partition which isn't written anymore.
+	require.NoError(t, desc.SetPartitionState("1", PARTITION_READONLY))
+	r.updateRingState(desc.Clone().(*PartitionRingDesc))
+
+	set, err = r.GetReplicationSetForOperation(Read)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"ingester-zone-c-1"}, instanceIDs(r, set.Partitions[0]))
+	assert.Equal(t, []int{0, 0, 1}, set.PartitionsMaxErrors)
+
+	// A partition without any healthy instance can't be read.
+	setTestInstanceUnhealthy(desc, "1", "ingester-zone-c-1")
+	r.updateRingState(desc.Clone().(*PartitionRingDesc))
+
+	_, err = r.GetReplicationSetForOperation(Read)
+	require.Equal(t, ErrTooManyUnhealthyInstances, err)
+
+	// An empty ring can't be read.
+	_, err = newTestPartitionRing(t, NewPartitionRingDesc()).GetReplicationSetForOperation(Read)
+	require.Equal(t, ErrEmptyRing, err)
+}
+
+func TestPartitionRing_ShuffleShard(t *testing.T) {
+	tokens := map[string]uint32{}
+	g := NewRandomTokenGenerator()
+	for i, token := range g.GenerateTokens(NewDesc(), "", "", 10, true) {
+		tokens[fmt.Sprintf("%d", i)] = token
+	}
+	desc := newTestPartitionRingDesc(tokens, "zone-a", "zone-b", "zone-c")
+	r := newTestPartitionRing(t, desc)
+
+	// The shard size is the number of instances.
+	subring := r.ShuffleShard("user-1", 6).(*PartitionRing)
+	assert.Equal(t, 2, subring.PartitionsCount())
+	assert.Equal(t, 6, subring.InstancesCount())
+
+	// The subring is stable and cached.
+	assert.Same(t, subring, r.ShuffleShard("user-1", 6))
+	assert.Equal(t, subring.ringDesc.Partitions, r.ShuffleShardWithLookback("user-1", 6, 0, time.Now()).(*PartitionRing).ringDesc.Partitions)
+
+	// Increasing the shard size keeps the previous partitions.
+	larger := r.ShuffleShard("user-1", 9).(*PartitionRing)
+	assert.Equal(t, 3, larger.PartitionsCount())
+	for partitionID := range subring.ringDesc.Partitions {
+		assert.Contains(t, larger.ringDesc.Partitions, partitionID)
+	}
+
+	// Read-only partitions are included without being counted.
+	for partitionID := range subring.ringDesc.Partitions {
+		require.NoError(t, desc.SetPartitionState(partitionID, PARTITION_READONLY))
+		break
+	}
+	r.updateRingState(desc.Clone().(*PartitionRingDesc))
+
+	withReadOnly := r.ShuffleShard("user-1", 6).(*PartitionRing)
+	assert.Equal(t, 3, withReadOnly.PartitionsCount())
+	for partitionID := range subring.ringDesc.Partitions {
+		assert.Contains(t, withReadOnly.ringDesc.Partitions, partitionID)
+	}
+
+	// The whole ring is returned if the shard size is large enough.
+	assert.Same(t, r, r.ShuffleShard("user-1", 30))
+	assert.Same(t, r, r.ShuffleShard("user-1", 0))
+}
+
+func TestPartitionRing_ServeHTTP(t *testing.T) {
+	r := newTestPartitionRing(t, newTestPartitionRingDesc(map[string]uint32{"1": 100, "10": 200, "2": 300}, "zone-a", "zone-b"))
+
+	req := httptest.NewRequest(http.MethodGet, "/ingester/partition-ring", nil)
+	req.Header.Set("Accept", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	resp := partitionHTTPResponse{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Partitions, 3)
+	assert.Equal(t, "1", resp.Partitions[0].ID)
+	assert.Equal(t, "2", resp.Partitions[1].ID)
+	assert.Equal(t, "10", resp.Partitions[2].ID)
+	assert.Equal(t, PARTITION_ACTIVE.String(), resp.Partitions[0].State)
+	require.Len(t, resp.Partitions[0].Instances, 2)
+	assert.Equal(t, "ingester-zone-a-1", resp.Partitions[0].Instances[0].ID)
+
+	// The html page is rendered by default.
+	req = httptest.NewRequest(http.MethodGet, "/ingester/partition-ring", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "ingester-zone-b-10")
+}
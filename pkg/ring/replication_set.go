@@ -22,12 +22,27 @@ type ReplicationSet struct {
 	// Maximum number of different zones in which instances can fail. Max unavailable zones and
 	// max errors are mutually exclusive.
 	MaxUnavailableZones int
+
+	// Instances grouped by partition, when the replication set has been built from the partition
+	// ring. Do() executes the function on the instances of each partition until the quorum of every
+	// partition is reached, so the results of the instances of the same partition must be merged by
+	// the caller like the results of the replicas of the token ring. Max errors and max unavailable
+	// zones are ignored by Do() when partitions are set.
+	Partitions [][]InstanceDesc
+
+	// Maximum number of tolerated failing instances of each partition, in the same order as the
+	// partitions. The quorum of a partition set is honored per partition, so MaxErrors is zero.
+	PartitionsMaxErrors []int
 }
 
 // Do function f in parallel for all replicas in the set, erroring is we exceed
 // MaxErrors and returning early otherwise. zoneResultsQuorum allows only include
 // results from zones that already reach quorum to improve performance.
 func (r ReplicationSet) Do(ctx context.Context, delay time.Duration, zoneResultsQuorum bool, partialDataEnabled bool, f func(context.Context, *InstanceDesc) (any, error)) ([]any, error) {
+	if len(r.Partitions) > 0 {
+		return r.doPartitions(ctx, delay, partialDataEnabled, f)
+	}
+
 	type instanceResult struct {
 		res      any
 		err      error
@@ -107,6 +122,71 @@ func (r ReplicationSet) Do(ctx context.Context, delay time.Duration, zoneResults
 	return tracker.getResults(), nil
 }
 
+// doPartitions runs function f in parallel for each partition in the set, on the instances of the
+// partition until its quorum is reached. It fails if the quorum of any partition can't be reached.
+func (r ReplicationSet) doPartitions(ctx context.Context, delay time.Duration, partialDataEnabled bool, f func(context.Context, *InstanceDesc) (any, error)) ([]any, error) {
+	type partitionResult struct {
+		res []any
+		err error
+	}
+
+	ch := make(chan partitionResult, len(r.Partitions))
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Spawn a goroutine for each partition, reading it with the quorum the writes use.
+	for p, instances := range r.Partitions {
+		set := ReplicationSet{Instances: instances}
+		if p < len(r.PartitionsMaxErrors) {
+			set.MaxErrors = r.PartitionsMaxErrors[p]
+		}
+
+		go func() {
+			if len(set.Instances) == 0 {
+				ch <- partitionResult{err: fmt.Errorf("no instance available for the partition")}
+				return
+			}
+			res, err := set.Do(ctx, delay, false, false, f)
+			ch <- partitionResult{res: res, err: err}
+		}()
+	}
+
+	var (
+		results = make([]any, 0, len(r.Instances))
+		errs    []error
+	)
+	for range r.Partitions {
+		select {
+		case res := <-ch:
+			if res.err == nil {
+				results = append(results, res.res...)
+				continue
+			}
+			if !partialDataEnabled || validation.IsLimitError(res.err) {
+				return nil, res.err
+			}
+			errs = append(errs, res.err)
+
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if len(errs) > 0 {
+		if len(results) == 0 {
+			return nil, errs[0]
+		}
+
+		finalErr := partialdata.ErrPartialData
+		for _, partialErr := range errs {
+			finalErr = fmt.Errorf("%w: %w", finalErr, partialErr)
+		}
+		return results, finalErr
+	}
+
+	return results, nil
+}
+
 // Includes returns whether the replication set includes the replica with the provided addr.
 func (r ReplicationSet) Includes(addr string) bool {
 	for _, instance := range r.Instances {
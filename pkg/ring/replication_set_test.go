@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -367,6 +368,76 @@ var (
 	}
 )
 
+func TestReplicationSet_Do_Partitions(t *testing.T) {
+	partitions := [][]InstanceDesc{
+		{{Addr: "1-a", Zone: "zone-a"}, {Addr: "1-b", Zone: "zone-b"}, {Addr: "1-c", Zone: "zone-c"}},
+		{{Addr: "2-a", Zone: "zone-a"}, {Addr: "2-b", Zone: "zone-b"}, {Addr: "2-c", Zone: "zone-c"}},
+	}
+	var instances []InstanceDesc
+	for _, partition := range partitions {
+		instances = append(instances, partition...)
+	}
+	set := ReplicationSet{Instances: instances, Partitions: partitions, PartitionsMaxErrors: []int{1, 1}}
+
+	// The quorum of each partition is queried, like the writes.
+	results, err := set.Do(context.Background(), 0, false, false, func(_ context.Context, ing *InstanceDesc) (any, error) {
+		return ing.Addr, nil
+	})
+	require.NoError(t, err)
+	assert.Len(t, results, 4)
+	for _, prefix := range []string{"1-", "2-"} {
+		count := 0
+		for _, res := range results {
+			if strings.HasPrefix(res.(string), prefix) {
+				count++
+			}
+		}
+		assert.Equal(t, 2, count, prefix)
+	}
+
+	// Instances failing in different zones don't fail the request, as long as the quorum of
+	// each partition is reached.
+	results, err = set.Do(context.Background(), 0, false, false, func(_ context.Context, ing *InstanceDesc) (any, error) {
+		if ing.Addr == "1-a" || ing.Addr == "2-b" {
+			return nil, errFailure
+		}
+		return ing.Addr, nil
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []any{"1-b", "1-c", "2-a", "2-c"}, results)
+
+	// The request fails when the quorum of a partition isn't reached.
+	failPartition1 := func(_ context.Context, ing *InstanceDesc) (any, error) {
+		if ing.Addr == "1-a" || ing.Addr == "1-b" {
+			return nil, errFailure
+		}
+		return ing.Addr, nil
+	}
+	_, err = set.Do(context.Background(), 0, false, false, failPartition1)
+	require.Equal(t, errFailure, err)
+
+	// Partial data is returned if enabled.
+	results, err = set.Do(context.Background(), 0, false, true, failPartition1)
+	require.ErrorIs(t, err, partialdata.ErrPartialData)
+	require.Len(t, results, 2)
+	for _, res := range results {
+		assert.True(t, strings.HasPrefix(res.(string), "2-"))
+	}
+
+	// A partition which isn't written anymore only requires a single instance.
+	set.PartitionsMaxErrors = []int{2, 1}
+	results, err = set.Do(context.Background(), 0, false, false, failPartition1)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.Contains(t, results, "1-c")
+
+	// Limit errors fail the request.
+	_, err = set.Do(context.Background(), 0, false, true, func(_ context.Context, _ *InstanceDesc) (any, error) {
+		return nil, validation.LimitError("limit reached")
+	})
+	require.Equal(t, validation.LimitError("limit reached"), err)
+}
+
 func TestHasReplicationSetChanged_IgnoresTimeStamp(t *testing.T) {
 	// Only testing difference to underlying Equal function
 	for testName, testData := range replicationSetChangesTestCases {
@@ -148,6 +148,7 @@ type Config struct {
 	ZoneAwarenessEnabled   bool                   `yaml:"zone_awareness_enabled"`
 	ExcludedZones          flagext.StringSliceCSV `yaml:"excluded_zones"`
 	DetailedMetricsEnabled bool                   `yaml:"detailed_metrics_enabled"`
+	PartitionRing          PartitionRingConfig    `yaml:"partition_ring"`
 
 	// Whether the shuffle-sharding subring cache is disabled. This option is set
 	// internally and never exposed to the user.
@@ -168,6 +169,7 @@ func (cfg *Config) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
 	f.IntVar(&cfg.ReplicationFactor, prefix+"distributor.replication-factor", 3, "The number of ingesters to write to and read from.")
 	f.BoolVar(&cfg.ZoneAwarenessEnabled, prefix+"distributor.zone-awareness-enabled", false, "True to enable the zone-awareness and replicate ingested samples across different availability zones.")
 	f.Var(&cfg.ExcludedZones, prefix+"distributor.excluded-zones", "Comma-separated list of zones to exclude from the ring. Instances in excluded zones will be filtered out from the ring.")
+	cfg.PartitionRing.RegisterFlagsWithPrefix(prefix, f)
 }
 
 type instanceInfo struct {
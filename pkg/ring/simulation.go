@@ -0,0 +1,215 @@
+package ring
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/go-kit/log"
+	"github.com/pkg/errors"
+
+	"github.com/cortexproject/cortex/pkg/querier/partialdata"
+	"github.com/cortexproject/cortex/pkg/ring/kv"
+)
+
+var errSimulatedFailure = errors.New("simulated instance failure")
+
+// ReadAvailability is the outcome of the queries of a tenant in a failure simulation.
+type ReadAvailability string
+
+const (
+	// ReadsComplete means queries return complete results.
+	ReadsComplete ReadAvailability = "complete"
+	// ReadsPartial means queries fail, unless partial data is enabled in which case they return partial results.
+	ReadsPartial ReadAvailability = "partial"
+	// ReadsFailed means queries fail.
+	ReadsFailed ReadAvailability = "failed"
+)
+
+// FailureSimulation describes the instances to simulate the failure of, and the tenants to report about.
+type FailureSimulation struct {
+	FailedInstances []string
+	// All the instances of these zones are considered failed.
+	FailedZones []string
+
+	// Shuffle shard size by tenant. A shard size of 0 means the tenant uses the whole ring.
+	Tenants map[string]int
+
+	// Operation used for writes, either Write or WriteNoExtend.
+	WriteOp Operation
+}
+
+// TenantAvailability is the availability of writes and reads of a tenant in a failure simulation.
+type TenantAvailability struct {
+	Tenant          string   `json:"tenant"`
+	ShardSize       int      `json:"shard_size"`
+	Instances       int      `json:"instances"`
+	FailedInstances []string `json:"failed_instances"`
+
+	// WritesAvailable is false if any series of the tenant can't be written to a quorum of instances.
+	WritesAvailable bool `json:"writes_available"`
+	// Percentage of the tenant series, by token range, which can't be written to a quorum of instances.
+	WritesFailingPercent float64          `json:"writes_failing_percent"`
+	Reads                ReadAvailability `json:"reads"`
+	ReadsError           string           `json:"reads_error,omitempty"`
+}
+
+// FailureSimulationReport is the result of a failure simulation.
+type FailureSimulationReport struct {
+	FailedInstances []string             `json:"failed_instances"`
+	Tenants         []TenantAvailability `json:"tenants"`
+}
+
+// Affected returns whether the writes or the reads of any tenant are impacted by the failure.
+func (r *FailureSimulationReport) Affected() bool {
+	for _, t := range r.Tenants {
+		if !t.WritesAvailable || t.Reads != ReadsComplete {
+			return true
+		}
+	}
+	return false
+}
+
+// SimulateFailure reports the availability of the writes and reads of the tenants if the given
+// instances of the ring failed. See SimulateFailure for details.
+func (r *Ring) SimulateFailure(sim FailureSimulation) (*FailureSimulationReport, error) {
+	r.mtx.RLock()
+	desc := r.ringDesc.Clone().(*Desc)
+	r.mtx.RUnlock()
+
+	return SimulateFailure(r.cfg, r.key, r.KVClient, r.strategy, desc, sim)
+}
+
+// SimulateFailure reports the availability of the writes and reads of the tenants if the given
+// instances of the ring described by desc failed. The failed instances are simulated as still
+// being part of the ring, as it happens until their heartbeat times out, with every request sent
+// to them failing. The tenants' shards and replication sets are computed with the same code used
+// by the distributors, so the outcome matches what the distributors would do with the same ring.
+func SimulateFailure(cfg Config, key string, store kv.Client, strategy ReplicationStrategy, desc *Desc, sim FailureSimulation) (*FailureSimulationReport, error) {
+	failed := map[string]struct{}{}
+	for _, id := range sim.FailedInstances {
+		if _, ok := desc.Ingesters[id]; !ok {
+			return nil, fmt.Errorf("instance %s not found in the ring", id)
+		}
+		failed[id] = struct{}{}
+	}
+	for _, zone := range sim.FailedZones {
+		found := false
+		for id, instance := range desc.Ingesters {
+			if instance.Zone == zone {
+				failed[id] = struct{}{}
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("zone %s not found in the ring", zone)
+		}
+	}
+
+	cfg.SubringCacheDisabled = true
+	cfg.DetailedMetricsEnabled = false
+	r, err := NewWithStoreClientAndStrategy(cfg, "simulation", key, store, strategy, nil, log.NewNopLogger())
+	if err != nil {
+		return nil, err
+	}
+	r.updateRingState(desc)
+
+	failedAddrs := map[string]struct{}{}
+	report := &FailureSimulationReport{FailedInstances: []string{}, Tenants: make([]TenantAvailability, 0, len(sim.Tenants))}
+	for id := range failed {
+		report.FailedInstances = append(report.FailedInstances, id)
+		failedAddrs[desc.Ingesters[id].Addr] = struct{}{}
+	}
+	sort.Strings(report.FailedInstances)
+
+	for tenant, shardSize := range sim.Tenants {
+		shard := r.ShuffleShard(tenant, shardSize).(*Ring)
+		report.Tenants = append(report.Tenants, simulateTenantFailure(shard, tenant, shardSize, failedAddrs, sim.WriteOp))
+	}
+	sort.Slice(report.Tenants, func(i, j int) bool {
+		return report.Tenants[i].Tenant < report.Tenants[j].Tenant
+	})
+
+	return report, nil
+}
+
+func simulateTenantFailure(shard *Ring, tenant string, shardSize int, failedAddrs map[string]struct{}, writeOp Operation) TenantAvailability {
+	result := TenantAvailability{
+		Tenant:          tenant,
+		ShardSize:       shardSize,
+		Instances:       shard.InstancesCount(),
+		FailedInstances: []string{},
+	}
+
+	shard.mtx.RLock()
+	for id, instance := range shard.ringDesc.Ingesters {
+		if _, ok := failedAddrs[instance.Addr]; ok {
+			result.FailedInstances = append(result.FailedInstances, id)
+		}
+	}
+	tokens := shard.ringTokens
+	shard.mtx.RUnlock()
+	sort.Strings(result.FailedInstances)
+
+	// Series are assigned to the instances owning the token range their hash falls into,
+	// so each range is checked for quorum the same way the distributors do when pushing.
+	var (
+		failingRange int64
+		bufDescs     [GetBufferSize]InstanceDesc
+		bufHosts     [GetBufferSize]string
+		bufZones     = make(map[string]int, GetZoneSize)
+	)
+	for i, token := range tokens {
+		rangeSize := tokenDistance(token, tokens[(i+1)%len(tokens)])
+
+		set, err := shard.Get(token, writeOp, bufDescs[:0], bufHosts[:0], bufZones)
+		if err != nil {
+			failingRange += rangeSize
+			continue
+		}
+
+		healthy := 0
+		for _, instance := range set.Instances {
+			if _, ok := failedAddrs[instance.Addr]; !ok {
+				healthy++
+			}
+		}
+		if healthy < len(set.Instances)-set.MaxErrors {
+			failingRange += rangeSize
+		}
+	}
+
+	result.WritesAvailable = len(tokens) > 0 && failingRange == 0
+	if len(tokens) == 0 {
+		failingRange = math.MaxUint32 + 1
+	}
+	result.WritesFailingPercent = float64(failingRange) / float64(math.MaxUint32+1) * 100
+
+	result.Reads, result.ReadsError = simulateReads(shard, failedAddrs)
+	return result
+}
+
+func simulateReads(shard *Ring, failedAddrs map[string]struct{}) (ReadAvailability, string) {
+	set, err := shard.GetReplicationSetForOperation(Read)
+	if err != nil {
+		return ReadsFailed, err.Error()
+	}
+
+	_, err = set.Do(context.Background(), 0, false, true, func(_ context.Context, instance *InstanceDesc) (any, error) {
+		if _, ok := failedAddrs[instance.Addr]; ok {
+			return nil, errSimulatedFailure
+		}
+		return struct{}{}, nil
+	})
+	switch {
+	case err == nil:
+		return ReadsComplete, ""
+	case partialdata.IsPartialDataError(err):
+		return ReadsPartial, ""
+	case errors.Is(err, errSimulatedFailure):
+		return ReadsFailed, "too many failed instances"
+	default:
+		return ReadsFailed, err.Error()
+	}
+}
@@ -0,0 +1,133 @@
+package ring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulateFailure(t *testing.T) {
+	// 9 instances, 3 per zone: instance-1..9 are in zone-(i%3).
+	zonedRing := &Desc{Ingesters: generateRingInstances(9, 3, 128)}
+	// 6 instances, each in its own zone, to test rings without zone-awareness.
+	unzonedRing := &Desc{Ingesters: generateRingInstances(6, 6, 128)}
+
+	tests := map[string]struct {
+		desc         *Desc
+		zoneAware    bool
+		sim          FailureSimulation
+		expectedErr  string
+		expectedFunc func(t *testing.T, report *FailureSimulationReport)
+	}{
+		"a failed zone doesn't affect tenants with zone-awareness": {
+			desc:      zonedRing,
+			zoneAware: true,
+			sim:       FailureSimulation{FailedZones: []string{"zone-0"}, Tenants: map[string]int{"user-1": 0, "user-2": 3}},
+			expectedFunc: func(t *testing.T, report *FailureSimulationReport) {
+				assert.Equal(t, []string{"instance-3", "instance-6", "instance-9"}, report.FailedInstances)
+				assert.False(t, report.Affected())
+
+				require.Len(t, report.Tenants, 2)
+				assert.Equal(t, "user-1", report.Tenants[0].Tenant)
+				assert.Equal(t, 9, report.Tenants[0].Instances)
+				assert.Equal(t, []string{"instance-3", "instance-6", "instance-9"}, report.Tenants[0].FailedInstances)
+				assert.Equal(t, "user-2", report.Tenants[1].Tenant)
+				assert.Equal(t, 3, report.Tenants[1].Instances)
+				assert.Len(t, report.Tenants[1].FailedInstances, 1)
+
+				for _, tenant := range report.Tenants {
+					assert.True(t, tenant.WritesAvailable)
+					assert.Zero(t, tenant.WritesFailingPercent)
+					assert.Equal(t, ReadsComplete, tenant.Reads)
+				}
+			},
+		},
+		"two failed zones make writes and reads fail": {
+			desc:      zonedRing,
+			zoneAware: true,
+			sim:       FailureSimulation{FailedZones: []string{"zone-0", "zone-1"}, Tenants: map[string]int{"user-1": 3}},
+			expectedFunc: func(t *testing.T, report *FailureSimulationReport) {
+				assert.True(t, report.Affected())
+				require.Len(t, report.Tenants, 1)
+				assert.False(t, report.Tenants[0].WritesAvailable)
+				assert.Equal(t, float64(100), report.Tenants[0].WritesFailingPercent)
+				assert.Equal(t, ReadsFailed, report.Tenants[0].Reads)
+				assert.NotEmpty(t, report.Tenants[0].ReadsError)
+			},
+		},
+		"a failed instance is tolerated without zone-awareness": {
+			desc: unzonedRing,
+			sim:  FailureSimulation{FailedInstances: []string{"instance-1"}, Tenants: map[string]int{"user-1": 0}},
+			expectedFunc: func(t *testing.T, report *FailureSimulationReport) {
+				assert.False(t, report.Affected())
+			},
+		},
+		"two failed instances make some writes fail and queries partial without zone-awareness": {
+			desc: unzonedRing,
+			sim:  FailureSimulation{FailedInstances: []string{"instance-1", "instance-2"}, Tenants: map[string]int{"user-1": 0}},
+			expectedFunc: func(t *testing.T, report *FailureSimulationReport) {
+				require.Len(t, report.Tenants, 1)
+				assert.False(t, report.Tenants[0].WritesAvailable)
+				assert.Greater(t, report.Tenants[0].WritesFailingPercent, float64(0))
+				assert.Less(t, report.Tenants[0].WritesFailingPercent, float64(100))
+				assert.Equal(t, ReadsPartial, report.Tenants[0].Reads)
+			},
+		},
+		"unknown instance": {
+			desc:        zonedRing,
+			sim:         FailureSimulation{FailedInstances: []string{"unknown"}},
+			expectedErr: "instance unknown not found in the ring",
+		},
+		"unknown zone": {
+			desc:        zonedRing,
+			sim:         FailureSimulation{FailedZones: []string{"unknown"}},
+			expectedErr: "zone unknown not found in the ring",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := Config{HeartbeatTimeout: time.Minute, ReplicationFactor: 3, ZoneAwarenessEnabled: tc.zoneAware}
+			tc.sim.WriteOp = Write
+
+			report, err := SimulateFailure(cfg, testRingKey, &MockClient{}, NewDefaultReplicationStrategy(), tc.desc.Clone().(*Desc), tc.sim)
+			if tc.expectedErr != "" {
+				require.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			tc.expectedFunc(t, report)
+		})
+	}
+}
+
+func TestSimulateFailure_InstanceOutsideOfTenantShard(t *testing.T) {
+	cfg := Config{HeartbeatTimeout: time.Minute, ReplicationFactor: 3, ZoneAwarenessEnabled: true}
+	desc := &Desc{Ingesters: generateRingInstances(9, 3, 128)}
+
+	r, err := NewWithStoreClientAndStrategy(cfg, testRingName, testRingKey, &MockClient{}, NewDefaultReplicationStrategy(), nil, log.NewNopLogger())
+	require.NoError(t, err)
+	r.updateRingState(desc.Clone().(*Desc))
+
+	var outside string
+	shard := r.ShuffleShard("user-1", 3)
+	for id := range desc.Ingesters {
+		if !shard.HasInstance(id) {
+			outside = id
+			break
+		}
+	}
+	require.NotEmpty(t, outside)
+
+	report, err := r.SimulateFailure(FailureSimulation{FailedInstances: []string{outside}, Tenants: map[string]int{"user-1": 3, "user-2": 0}, WriteOp: Write})
+	require.NoError(t, err)
+	require.Len(t, report.Tenants, 2)
+
+	// The tenant using the whole ring is not affected either, thanks to the replication.
+	assert.False(t, report.Affected())
+	assert.Empty(t, report.Tenants[0].FailedInstances)
+	assert.Equal(t, []string{outside}, report.Tenants[1].FailedInstances)
+}
@@ -0,0 +1,155 @@
+package ring
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// tokensMigrationMaxSkew is the ownership skew within which an instance is considered balanced,
+// and doesn't migrate its tokens anymore.
+const tokensMigrationMaxSkew = 0.02
+
+var (
+	errTokensMigrationRequiresMinimizeSpread = errors.New("tokens migration requires the minimize-spread tokens generator strategy")
+	errInvalidTokensMigrationStep            = errors.New("tokens migration step must be greater than 0")
+)
+
+// TokensGeneratorConfig configures how the tokens of the instances of a ring are generated,
+// and the gradual migration of the tokens of the instances already in the ring.
+type TokensGeneratorConfig struct {
+	Strategy          string        `yaml:"tokens_generator_strategy"`
+	MigrationInterval time.Duration `yaml:"tokens_migration_interval"`
+	MigrationStep     int           `yaml:"tokens_migration_step"`
+}
+
+// RegisterFlagsWithPrefix adds the flags required to config this to the given FlagSet.
+func (cfg *TokensGeneratorConfig) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.StringVar(&cfg.Strategy, prefix+"tokens-generator-strategy", randomTokenStrategy, fmt.Sprintf("EXPERIMENTAL: Algorithm used to generate new ring tokens. Supported Values: %s", strings.Join(supportedTokenStrategy, ",")))
+	f.DurationVar(&cfg.MigrationInterval, prefix+"tokens-migration-interval", 0, "EXPERIMENTAL: Interval at which the instance replaces some of its tokens with tokens generated by the minimize-spread strategy, until its ownership within its zone is balanced. Requires the minimize-spread tokens generator strategy. 0 to disable.")
+	f.IntVar(&cfg.MigrationStep, prefix+"tokens-migration-step", 8, "EXPERIMENTAL: Maximum number of tokens replaced at each tokens migration interval.")
+}
+
+func (cfg *TokensGeneratorConfig) Validate() error {
+	if cfg.Strategy != "" && !slices.Contains(supportedTokenStrategy, strings.ToLower(cfg.Strategy)) {
+		return errInvalidTokensGeneratorStrategy
+	}
+	if cfg.MigrationInterval > 0 {
+		if !strings.EqualFold(cfg.Strategy, minimizeSpreadTokenStrategy) {
+			return errTokensMigrationRequiresMinimizeSpread
+		}
+		if cfg.MigrationStep <= 0 {
+			return errInvalidTokensMigrationStep
+		}
+	}
+
+	return nil
+}
+
+// ownershipSkew returns the relative difference between the token ranges owned by each instance
+// within its zone and the ranges it would own if the ownership was even, e.g. 0.1 means the
+// instance owns 10% more than expected.
+func (d *Desc) ownershipSkew() map[string]float64 {
+	owned := d.tokenOwnership(true)
+
+	instancesByZone := map[string]int{}
+	for _, instance := range d.Ingesters {
+		instancesByZone[instance.Zone]++
+	}
+
+	skew := make(map[string]float64, len(d.Ingesters))
+	for id, instance := range d.Ingesters {
+		expected := float64(math.MaxUint32+1) / float64(instancesByZone[instance.Zone])
+		skew[id] = float64(owned[id])/expected - 1
+	}
+	return skew
+}
+
+// zoneOwnershipSpread returns the sum of the squared ownership skews of the instances of the zone.
+func zoneOwnershipSpread(skew map[string]float64, d *Desc, zone string) float64 {
+	spread := 0.0
+	for id, instance := range d.Ingesters {
+		if instance.Zone == zone {
+			spread += skew[id] * skew[id]
+		}
+	}
+	return spread
+}
+
+// migrateTokens returns the tokens the instance should own to get closer to an even ownership
+// within its zone, replacing at most step of its current tokens with tokens generated by tg.
+// It returns false if the instance is already balanced, or if no replacement reduces the
+// ownership spread of the zone.
+func migrateTokens(d *Desc, id string, step int, tg TokenGenerator) (Tokens, bool) {
+	instance, ok := d.Ingesters[id]
+	if !ok || instance.State != ACTIVE || len(instance.Tokens) < 2 {
+		return nil, false
+	}
+
+	// Wait for every instance of the zone to own tokens, otherwise the ownership is meaningless.
+	for _, other := range d.Ingesters {
+		if other.Zone == instance.Zone && len(other.Tokens) == 0 {
+			return nil, false
+		}
+	}
+
+	skew := d.ownershipSkew()
+	if math.Abs(skew[id]) <= tokensMigrationMaxSkew {
+		return nil, false
+	}
+	step = min(step, len(instance.Tokens)-1)
+
+	// An instance owning too much drops its largest ranges, while an instance owning too little
+	// drops its smallest ranges, so that the new tokens move the ownership in the right direction.
+	zoneTokens := d.getTokensByZone()[instance.Zone]
+	ranges := make(map[uint32]int64, len(instance.Tokens))
+	for i, token := range zoneTokens {
+		prev := zoneTokens[(i+len(zoneTokens)-1)%len(zoneTokens)]
+		ranges[token] = tokenDistance(prev, token)
+	}
+
+	tokens := slices.Clone(instance.Tokens)
+	sort.SliceStable(tokens, func(i, j int) bool {
+		if skew[id] > 0 {
+			return ranges[tokens[i]] > ranges[tokens[j]]
+		}
+		return ranges[tokens[i]] < ranges[tokens[j]]
+	})
+	kept := tokens[step:]
+	slices.Sort(kept)
+
+	migrated := d.Clone().(*Desc)
+	migratedInstance := migrated.Ingesters[id]
+	migratedInstance.Tokens = kept
+	migrated.Ingesters[id] = migratedInstance
+
+	newTokens := tg.GenerateTokens(migrated, id, instance.Zone, step, true)
+	migratedInstance.Tokens = MergeTokens([][]uint32{kept, newTokens})
+	migrated.Ingesters[id] = migratedInstance
+
+	if zoneOwnershipSpread(migrated.ownershipSkew(), migrated, instance.Zone) >= zoneOwnershipSpread(skew, d, instance.Zone) {
+		return nil, false
+	}
+	return migratedInstance.Tokens, true
+}
+
+// countReplacedTokens returns the number of tokens of prev which are not in next.
+func countReplacedTokens(prev, next []uint32) int {
+	kept := make(map[uint32]struct{}, len(next))
+	for _, token := range next {
+		kept[token] = struct{}{}
+	}
+
+	replaced := 0
+	for _, token := range prev {
+		if _, ok := kept[token]; !ok {
+			replaced++
+		}
+	}
+	return replaced
+}
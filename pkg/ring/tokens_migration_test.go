@@ -0,0 +1,108 @@
+package ring
+
+import (
+	"math"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokensGeneratorConfig_Validate(t *testing.T) {
+	tests := map[string]struct {
+		cfg         TokensGeneratorConfig
+		expectedErr error
+	}{
+		"default config": {
+			cfg: TokensGeneratorConfig{Strategy: randomTokenStrategy, MigrationStep: 8},
+		},
+		"invalid strategy": {
+			cfg:         TokensGeneratorConfig{Strategy: "unknown"},
+			expectedErr: errInvalidTokensGeneratorStrategy,
+		},
+		"migration with the minimize-spread strategy": {
+			cfg: TokensGeneratorConfig{Strategy: minimizeSpreadTokenStrategy, MigrationInterval: time.Minute, MigrationStep: 8},
+		},
+		"migration with the random strategy": {
+			cfg:         TokensGeneratorConfig{Strategy: randomTokenStrategy, MigrationInterval: time.Minute, MigrationStep: 8},
+			expectedErr: errTokensMigrationRequiresMinimizeSpread,
+		},
+		"migration with an invalid step": {
+			cfg:         TokensGeneratorConfig{Strategy: minimizeSpreadTokenStrategy, MigrationInterval: time.Minute},
+			expectedErr: errInvalidTokensMigrationStep,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expectedErr, tc.cfg.Validate())
+		})
+	}
+}
+
+func TestMigrateTokens_ShouldConvergeToEvenOwnership(t *testing.T) {
+	const numTokens = 128
+	desc := &Desc{Ingesters: generateRingInstances(12, 3, numTokens)}
+	tg := NewMinimizeSpreadTokenGenerator()
+
+	initialSkew := maxAbsOwnershipSkew(desc)
+	require.Greater(t, initialSkew, tokensMigrationMaxSkew)
+
+	ids := make([]string, 0, len(desc.Ingesters))
+	for id := range desc.Ingesters {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for range 100 {
+		for _, id := range ids {
+			tokens, ok := migrateTokens(desc, id, 8, tg)
+			if !ok {
+				continue
+			}
+
+			require.Len(t, tokens, numTokens)
+			require.True(t, sort.IsSorted(tokens))
+
+			instance := desc.Ingesters[id]
+			instance.Tokens = tokens
+			desc.Ingesters[id] = instance
+		}
+	}
+
+	assert.Less(t, maxAbsOwnershipSkew(desc), initialSkew)
+	assert.LessOrEqual(t, maxAbsOwnershipSkew(desc), 0.05)
+
+	// Tokens must not be shared between instances.
+	assert.Len(t, desc.getTokensInfo(), 12*numTokens)
+}
+
+func TestMigrateTokens_ShouldSkipInstancesNotActiveOrBalanced(t *testing.T) {
+	tg := NewMinimizeSpreadTokenGenerator()
+
+	desc := &Desc{Ingesters: generateRingInstances(3, 1, 128)}
+	instance := desc.Ingesters["instance-1"]
+	instance.State = JOINING
+	desc.Ingesters["instance-1"] = instance
+
+	_, ok := migrateTokens(desc, "instance-1", 8, tg)
+	assert.False(t, ok)
+
+	// An instance alone in its zone owns the whole zone.
+	desc = &Desc{Ingesters: generateRingInstances(1, 1, 128)}
+	_, ok = migrateTokens(desc, "instance-1", 8, tg)
+	assert.False(t, ok)
+
+	_, ok = migrateTokens(desc, "unknown", 8, tg)
+	assert.False(t, ok)
+}
+
+func maxAbsOwnershipSkew(d *Desc) float64 {
+	maxSkew := 0.0
+	for _, skew := range d.ownershipSkew() {
+		maxSkew = math.Max(maxSkew, math.Abs(skew))
+	}
+	return maxSkew
+}
@@ -196,6 +196,10 @@ func (cfg *Config) Validate(limits validation.Limits, log log.Logger) error {
 		return errInvalidTenantShardSize
 	}
 
+	if err := cfg.Ring.TokensGenerator.Validate(); err != nil {
+		return errors.Wrap(err, "invalid ruler ring config")
+	}
+
 	if err := cfg.ClientTLSConfig.Validate(log); err != nil {
 		return errors.Wrap(err, "invalid ruler gRPC client config")
 	}
@@ -773,6 +777,16 @@ func (r *Ruler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// RingAdminHandler serves the JSON ring admin API of the ruler ring.
+func (r *Ruler) RingAdminHandler(w http.ResponseWriter, req *http.Request) {
+	if !r.cfg.EnableSharding {
+		http.Error(w, "Ruler has no ring because sharding is disabled.", http.StatusNotFound)
+		return
+	}
+
+	r.ring.AdminHandler(w, req)
+}
+
 func (r *Ruler) run(ctx context.Context) error {
 	level.Info(r.logger).Log("msg", "ruler up and running")
 
@@ -46,6 +46,8 @@ type RingConfig struct {
 	TokensFilePath         string        `yaml:"tokens_file_path"`
 	DetailedMetricsEnabled bool          `yaml:"detailed_metrics_enabled"`
 
+	TokensGenerator ring.TokensGeneratorConfig `yaml:",inline"`
+
 	// Instance details
 	InstanceID             string   `yaml:"instance_id" doc:"hidden"`
 	InstanceInterfaceNames []string `yaml:"instance_interface_names"`
@@ -78,6 +80,7 @@ func (cfg *RingConfig) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&cfg.ReplicationFactor, "ruler.ring.replication-factor", 1, "EXPERIMENTAL: The replication factor to use when loading rule groups for API HA.")
 	f.BoolVar(&cfg.ZoneAwarenessEnabled, "ruler.ring.zone-awareness-enabled", false, "EXPERIMENTAL: True to enable zone-awareness and load rule groups across different availability zones for API HA.")
 	f.StringVar(&cfg.TokensFilePath, "ruler.ring.tokens-file-path", "", "EXPERIMENTAL: File path where tokens are stored. If empty, tokens are not stored at shutdown and restored at startup.")
+	cfg.TokensGenerator.RegisterFlagsWithPrefix("ruler.ring.", f)
 	f.BoolVar(&cfg.DetailedMetricsEnabled, "ruler.ring.detailed-metrics-enabled", true, "Set to true to enable ring detailed metrics. These metrics provide detailed information, such as token count and ownership per tenant. Disabling them can significantly decrease the number of metrics emitted.")
 
 	// Instance flags
@@ -108,6 +111,9 @@ func (cfg *RingConfig) ToLifecyclerConfig(logger log.Logger) (ring.BasicLifecycl
 		HeartbeatPeriod:                 cfg.HeartbeatPeriod,
 		TokensObservePeriod:             0,
 		NumTokens:                       cfg.NumTokens,
+		TokensGeneratorStrategy:         cfg.TokensGenerator.Strategy,
+		TokensMigrationInterval:         cfg.TokensGenerator.MigrationInterval,
+		TokensMigrationStep:             cfg.TokensGenerator.MigrationStep,
 		FinalSleep:                      cfg.FinalSleep,
 		KeepInstanceInTheRingOnShutdown: cfg.KeepInstanceInTheRingOnShutdown,
 	}, nil
@@ -32,6 +32,12 @@ type ConverterMark struct {
 	// Shards is the number of parquet shards created for this block.
 	// This field is optional for backward compatibility.
 	Shards int `json:"shards,omitempty"`
+	// Incompatible is set when the block can't be correctly represented in parquet
+	// format. No parquet files are available for the block, and it should be queried
+	// from TSDB instead.
+	Incompatible bool `json:"incompatible,omitempty"`
+	// Reason explains why the block is incompatible.
+	Reason string `json:"reason,omitempty"`
 }
 
 func ReadConverterMark(ctx context.Context, id ulid.ULID, userBkt objstore.InstrumentedBucket, logger log.Logger) (*ConverterMark, error) {
@@ -61,6 +67,21 @@ func WriteConverterMark(ctx context.Context, id ulid.ULID, userBkt objstore.Buck
 		Version: CurrentVersion,
 		Shards:  shards,
 	}
+	return writeConverterMark(ctx, id, userBkt, marker)
+}
+
+// WriteIncompatibleConverterMark writes a converter mark flagging the block as not
+// convertible to parquet, so that it is not converted again and gets queried from TSDB.
+func WriteIncompatibleConverterMark(ctx context.Context, id ulid.ULID, userBkt objstore.Bucket, reason string) error {
+	marker := ConverterMark{
+		Version:      CurrentVersion,
+		Incompatible: true,
+		Reason:       reason,
+	}
+	return writeConverterMark(ctx, id, userBkt, marker)
+}
+
+func writeConverterMark(ctx context.Context, id ulid.ULID, userBkt objstore.Bucket, marker ConverterMark) error {
 	markerPath := path.Join(id.String(), ConverterMarkerFileName)
 	b, err := json.Marshal(marker)
 	if err != nil {
@@ -75,6 +96,11 @@ type ConverterMarkMeta struct {
 	// Shards is the number of parquet shards created for this block.
 	// This field is optional for backward compatibility.
 	Shards int `json:"shards,omitempty"`
+	// Incompatible is set when the block couldn't be converted to parquet and
+	// must be queried from TSDB.
+	Incompatible bool `json:"incompatible,omitempty"`
+	// Reason explains why the block is incompatible.
+	Reason string `json:"reason,omitempty"`
 }
 
 func ValidConverterMarkVersion(version int) bool {
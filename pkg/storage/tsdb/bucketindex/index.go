@@ -74,7 +74,7 @@ func (idx *Index) IsEmpty() bool {
 func (idx *Index) ParquetBlocks() []*Block {
 	blocks := make([]*Block, 0, len(idx.Blocks))
 	for _, b := range idx.Blocks {
-		if b.Parquet != nil {
+		if b.IsParquet() {
 			blocks = append(blocks, b)
 		}
 	}
@@ -85,7 +85,7 @@ func (idx *Index) ParquetBlocks() []*Block {
 func (idx *Index) NonParquetBlocks() []*Block {
 	blocks := make([]*Block, 0, len(idx.Blocks))
 	for _, b := range idx.Blocks {
-		if b.Parquet != nil {
+		if b.IsParquet() {
 			continue
 		}
 		blocks = append(blocks, b)
@@ -128,6 +128,17 @@ func (m *Block) Within(minT, maxT int64) bool {
 	return m.MinTime <= maxT && minT < m.MaxTime
 }
 
+// IsParquet returns whether the block has been converted and can be queried in Parquet format.
+func (m *Block) IsParquet() bool {
+	return m.Parquet != nil && !m.Parquet.Incompatible
+}
+
+// IsParquetIncompatible returns whether the block has been flagged as not convertible to
+// Parquet format, so it must always be queried from TSDB.
+func (m *Block) IsParquetIncompatible() bool {
+	return m.Parquet != nil && m.Parquet.Incompatible
+}
+
 func (m *Block) GetUploadedAt() time.Time {
 	return time.Unix(m.UploadedAt, 0)
 }
@@ -446,6 +446,18 @@ func TestIndex_ParquetBlocks(t *testing.T) {
 				{ID: block3, Parquet: &parquet.ConverterMarkMeta{Version: 1}},
 			},
 		},
+		"parquet incompatible blocks": {
+			index: &Index{
+				Blocks: Blocks{
+					{ID: block1, Parquet: &parquet.ConverterMarkMeta{Version: 1}},
+					{ID: block2, Parquet: &parquet.ConverterMarkMeta{Version: 1, Incompatible: true}},
+					{ID: block3},
+				},
+			},
+			expected: []*Block{
+				{ID: block1, Parquet: &parquet.ConverterMarkMeta{Version: 1}},
+			},
+		},
 		"all parquet blocks": {
 			index: &Index{
 				Blocks: Blocks{
@@ -210,8 +210,10 @@ func (w *Updater) updateParquetBlockIndexEntry(ctx context.Context, id ulid.ULID
 	}
 
 	block.Parquet = &parquet.ConverterMarkMeta{
-		Version: marker.Version,
-		Shards:  marker.Shards,
+		Version:      marker.Version,
+		Shards:       marker.Shards,
+		Incompatible: marker.Incompatible,
+		Reason:       marker.Reason,
 	}
 	return nil
 }
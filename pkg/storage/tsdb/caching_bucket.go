@@ -27,7 +27,7 @@ import (
 )
 
 var (
-	supportedBucketCacheBackends = []string{CacheBackendInMemory, CacheBackendMemcached, CacheBackendRedis}
+	supportedBucketCacheBackends = []string{CacheBackendInMemory, CacheBackendDisk, CacheBackendMemcached, CacheBackendRedis}
 
 	errUnsupportedBucketCacheBackend = errors.New("unsupported cache backend")
 	errDuplicatedBucketCacheBackend  = errors.New("duplicated cache backend")
@@ -37,11 +37,13 @@ const (
 	CacheBackendMemcached = "memcached"
 	CacheBackendRedis     = "redis"
 	CacheBackendInMemory  = "inmemory"
+	CacheBackendDisk      = "disk"
 )
 
 type BucketCacheBackend struct {
 	Backend    string                      `yaml:"backend"`
 	InMemory   InMemoryBucketCacheConfig   `yaml:"inmemory"`
+	Disk       DiskBucketCacheConfig       `yaml:"disk"`
 	Memcached  MemcachedClientConfig       `yaml:"memcached"`
 	Redis      RedisClientConfig           `yaml:"redis"`
 	MultiLevel MultiLevelBucketCacheConfig `yaml:"multilevel"`
@@ -80,6 +82,10 @@ func (cfg *BucketCacheBackend) Validate() error {
 			if err := cfg.Redis.Validate(); err != nil {
 				return err
 			}
+		case CacheBackendDisk:
+			if err := cfg.Disk.Validate(); err != nil {
+				return err
+			}
 		case CacheBackendInMemory:
 		}
 
@@ -100,12 +106,13 @@ type ChunksCacheConfig struct {
 
 func (cfg *ChunksCacheConfig) RegisterFlagsWithPrefix(f *flag.FlagSet, prefix string) {
 	f.StringVar(&cfg.Backend, prefix+"backend", "", fmt.Sprintf("The chunks cache backend type. Single or Multiple cache backend can be provided. "+
-		"Supported values in single cache: %s, %s, %s, and '' (disable). "+
-		"Supported values in multi level cache: a comma-separated list of (%s)", CacheBackendMemcached, CacheBackendRedis, CacheBackendInMemory, strings.Join(supportedBucketCacheBackends, ", ")))
+		"Supported values in single cache: %s, %s, %s, %s, and '' (disable). "+
+		"Supported values in multi level cache: a comma-separated list of (%s)", CacheBackendMemcached, CacheBackendRedis, CacheBackendInMemory, CacheBackendDisk, strings.Join(supportedBucketCacheBackends, ", ")))
 
 	cfg.Memcached.RegisterFlagsWithPrefix(f, prefix+"memcached.")
 	cfg.Redis.RegisterFlagsWithPrefix(f, prefix+"redis.")
 	cfg.InMemory.RegisterFlagsWithPrefix(f, prefix+"inmemory.", "chunks")
+	cfg.Disk.RegisterFlagsWithPrefix(f, prefix+"disk.", "chunks")
 	cfg.MultiLevel.RegisterFlagsWithPrefix(f, prefix+"multilevel.")
 
 	f.Int64Var(&cfg.SubrangeSize, prefix+"subrange-size", 16000, "Size of each subrange that bucket object is split into for better caching.")
@@ -156,16 +163,19 @@ type MetadataCacheConfig struct {
 	BucketIndexContentTTL    time.Duration `yaml:"bucket_index_content_ttl"`
 	BucketIndexMaxSize       int           `yaml:"bucket_index_max_size_bytes"`
 	PartitionedGroupsListTTL time.Duration `yaml:"partitioned_groups_list_ttl"`
+	BlockExemplarsContentTTL time.Duration `yaml:"block_exemplars_content_ttl"`
+	BlockExemplarsMaxSize    int           `yaml:"block_exemplars_max_size_bytes"`
 }
 
 func (cfg *MetadataCacheConfig) RegisterFlagsWithPrefix(f *flag.FlagSet, prefix string) {
 	f.StringVar(&cfg.Backend, prefix+"backend", "", fmt.Sprintf("The metadata cache backend type. Single or Multiple cache backend can be provided. "+
-		"Supported values in single cache: %s, %s, %s, and '' (disable). "+
-		"Supported values in multi level cache: a comma-separated list of (%s)", CacheBackendMemcached, CacheBackendRedis, CacheBackendInMemory, strings.Join(supportedBucketCacheBackends, ", ")))
+		"Supported values in single cache: %s, %s, %s, %s, and '' (disable). "+
+		"Supported values in multi level cache: a comma-separated list of (%s)", CacheBackendMemcached, CacheBackendRedis, CacheBackendInMemory, CacheBackendDisk, strings.Join(supportedBucketCacheBackends, ", ")))
 
 	cfg.Memcached.RegisterFlagsWithPrefix(f, prefix+"memcached.")
 	cfg.Redis.RegisterFlagsWithPrefix(f, prefix+"redis.")
 	cfg.InMemory.RegisterFlagsWithPrefix(f, prefix+"inmemory.", "metadata")
+	cfg.Disk.RegisterFlagsWithPrefix(f, prefix+"disk.", "metadata")
 	cfg.MultiLevel.RegisterFlagsWithPrefix(f, prefix+"multilevel.")
 
 	f.DurationVar(&cfg.TenantsListTTL, prefix+"tenants-list-ttl", 15*time.Minute, "How long to cache list of tenants in the bucket.")
@@ -180,6 +190,8 @@ func (cfg *MetadataCacheConfig) RegisterFlagsWithPrefix(f *flag.FlagSet, prefix
 	f.DurationVar(&cfg.BucketIndexContentTTL, prefix+"bucket-index-content-ttl", 5*time.Minute, "How long to cache content of the bucket index. 0 disables caching")
 	f.IntVar(&cfg.BucketIndexMaxSize, prefix+"bucket-index-max-size-bytes", 1*1024*1024, "Maximum size of bucket index content to cache in bytes. Caching will be skipped if the content exceeds this size. This is useful to avoid network round trip for large content if the configured caching backend has an hard limit on cached items size (in this case, you should set this limit to the same limit in the caching backend).")
 	f.DurationVar(&cfg.PartitionedGroupsListTTL, prefix+"partitioned-groups-list-ttl", 0, "How long to cache list of partitioned groups for an user. 0 disables caching")
+	f.DurationVar(&cfg.BlockExemplarsContentTTL, prefix+"block-exemplars-content-ttl", 24*time.Hour, "How long to cache content of the block exemplars file, and whether it exists. 0 disables caching")
+	f.IntVar(&cfg.BlockExemplarsMaxSize, prefix+"block-exemplars-max-size-bytes", 1*1024*1024, "Maximum size of block exemplars file content to cache in bytes. Caching will be skipped if the content exceeds this size. This is useful to avoid network round trip for large content if the configured caching backend has an hard limit on cached items size (in this case, you should set this limit to the same limit in the caching backend).")
 }
 
 func (cfg *MetadataCacheConfig) Validate() error {
@@ -197,12 +209,13 @@ type ParquetLabelsCacheConfig struct {
 
 func (cfg *ParquetLabelsCacheConfig) RegisterFlagsWithPrefix(f *flag.FlagSet, prefix string) {
 	f.StringVar(&cfg.Backend, prefix+"backend", "", fmt.Sprintf("The parquet labels cache backend type. Single or Multiple cache backend can be provided. "+
-		"Supported values in single cache: %s, %s, %s, and '' (disable). "+
-		"Supported values in multi level cache: a comma-separated list of (%s)", CacheBackendMemcached, CacheBackendRedis, CacheBackendInMemory, strings.Join(supportedBucketCacheBackends, ", ")))
+		"Supported values in single cache: %s, %s, %s, %s, and '' (disable). "+
+		"Supported values in multi level cache: a comma-separated list of (%s)", CacheBackendMemcached, CacheBackendRedis, CacheBackendInMemory, CacheBackendDisk, strings.Join(supportedBucketCacheBackends, ", ")))
 
 	cfg.Memcached.RegisterFlagsWithPrefix(f, prefix+"memcached.")
 	cfg.Redis.RegisterFlagsWithPrefix(f, prefix+"redis.")
 	cfg.InMemory.RegisterFlagsWithPrefix(f, prefix+"inmemory.", "parquet-labels")
+	cfg.Disk.RegisterFlagsWithPrefix(f, prefix+"disk.", "parquet-labels")
 	cfg.MultiLevel.RegisterFlagsWithPrefix(f, prefix+"multilevel.")
 
 	f.Int64Var(&cfg.SubrangeSize, prefix+"subrange-size", 16000, "Size of each subrange that bucket object is split into for better caching.")
@@ -218,11 +231,13 @@ func (cfg *ParquetLabelsCacheConfig) Validate() error {
 	return cfg.BucketCacheBackend.Validate()
 }
 
-func CreateCachingBucket(chunksConfig ChunksCacheConfig, metadataConfig MetadataCacheConfig, parquetLabelsConfig ParquetLabelsCacheConfig, matchers Matchers, bkt objstore.InstrumentedBucket, logger log.Logger, reg prometheus.Registerer) (objstore.InstrumentedBucket, error) {
+// CreateCachingBucket creates a caching bucket for the given component. The component
+// name is used to give each component its own disk caches directory.
+func CreateCachingBucket(component string, chunksConfig ChunksCacheConfig, metadataConfig MetadataCacheConfig, parquetLabelsConfig ParquetLabelsCacheConfig, matchers Matchers, bkt objstore.InstrumentedBucket, logger log.Logger, reg prometheus.Registerer) (objstore.InstrumentedBucket, error) {
 	cfg := cache.NewCachingBucketConfig()
 	cachingConfigured := false
 
-	chunksCache, err := createBucketCache("chunks-cache", &chunksConfig.BucketCacheBackend, logger, reg)
+	chunksCache, err := createBucketCache("chunks-cache", component, &chunksConfig.BucketCacheBackend, logger, reg)
 	if err != nil {
 		return nil, errors.Wrapf(err, "chunks-cache")
 	}
@@ -233,7 +248,7 @@ func CreateCachingBucket(chunksConfig ChunksCacheConfig, metadataConfig Metadata
 		cfg.CacheGetRange("parquet-chunks", chunksCache, matchers.GetParquetChunksMatcher(), chunksConfig.SubrangeSize, chunksConfig.AttributesTTL, chunksConfig.SubrangeTTL, chunksConfig.MaxGetRangeRequests)
 	}
 
-	metadataCache, err := createBucketCache("metadata-cache", &metadataConfig.BucketCacheBackend, logger, reg)
+	metadataCache, err := createBucketCache("metadata-cache", component, &metadataConfig.BucketCacheBackend, logger, reg)
 	if err != nil {
 		return nil, errors.Wrapf(err, "metadata-cache")
 	}
@@ -250,13 +265,19 @@ func CreateCachingBucket(chunksConfig ChunksCacheConfig, metadataConfig Metadata
 			cfg.CacheGet("bucket-index", metadataCache, matchers.GetBucketIndexMatcher(), metadataConfig.BucketIndexMaxSize, metadataConfig.BucketIndexContentTTL /* do not cache exist / not exist: */, 0, 0)
 		}
 
+		if metadataConfig.BlockExemplarsContentTTL > 0 {
+			// The block exemplars file is uploaded before the block is, and never changes afterwards,
+			// so whether it exists is cached as long as its content.
+			cfg.CacheGet("block-exemplars", metadataCache, matchers.GetBlockExemplarsMatcher(), metadataConfig.BlockExemplarsMaxSize, metadataConfig.BlockExemplarsContentTTL, metadataConfig.BlockExemplarsContentTTL, metadataConfig.BlockExemplarsContentTTL)
+		}
+
 		codec := snappyIterCodec{storecache.JSONIterCodec{}}
 		cfg.CacheIter("tenants-iter", metadataCache, matchers.GetTenantsIterMatcher(), metadataConfig.TenantsListTTL, codec, "")
 		cfg.CacheIter("tenant-blocks-iter", metadataCache, matchers.GetTenantBlocksIterMatcher(), metadataConfig.TenantBlocksListTTL, codec, "")
 		cfg.CacheIter("chunks-iter", metadataCache, matchers.GetChunksIterMatcher(), metadataConfig.ChunksListTTL, codec, "")
 	}
 
-	parquetLabelsCache, err := createBucketCache("parquet-labels-cache", &parquetLabelsConfig.BucketCacheBackend, logger, reg)
+	parquetLabelsCache, err := createBucketCache("parquet-labels-cache", component, &parquetLabelsConfig.BucketCacheBackend, logger, reg)
 	if err != nil {
 		return nil, errors.Wrapf(err, "parquet-labels-cache")
 	}
@@ -275,6 +296,11 @@ func CreateCachingBucket(chunksConfig ChunksCacheConfig, metadataConfig Metadata
 }
 
 func CreateCachingBucketForCompactor(metadataConfig MetadataCacheConfig, cleaner bool, bkt objstore.InstrumentedBucket, logger log.Logger, reg prometheus.Registerer) (objstore.InstrumentedBucket, error) {
+	component := "compactor"
+	if cleaner {
+		component = "cleaner"
+	}
+
 	matchers := NewMatchers()
 	// Do not cache block deletion marker for compactor
 	matchers.SetMetaFileMatcher(func(name string) bool {
@@ -283,7 +309,7 @@ func CreateCachingBucketForCompactor(metadataConfig MetadataCacheConfig, cleaner
 	cfg := cache.NewCachingBucketConfig()
 	cachingConfigured := false
 
-	metadataCache, err := createBucketCache("metadata-cache", &metadataConfig.BucketCacheBackend, logger, reg)
+	metadataCache, err := createBucketCache("metadata-cache", component, &metadataConfig.BucketCacheBackend, logger, reg)
 	if err != nil {
 		return nil, errors.Wrapf(err, "metadata-cache")
 	}
@@ -323,7 +349,7 @@ func CreateCachingBucketForCompactor(metadataConfig MetadataCacheConfig, cleaner
 	return storecache.NewCachingBucket(bkt, cfg, logger, reg)
 }
 
-func createBucketCache(cacheName string, cacheBackend *BucketCacheBackend, logger log.Logger, reg prometheus.Registerer) (cache.Cache, error) {
+func createBucketCache(cacheName, component string, cacheBackend *BucketCacheBackend, logger log.Logger, reg prometheus.Registerer) (cache.Cache, error) {
 	if cacheBackend.Backend == "" {
 		// No caching.
 		return nil, nil
@@ -342,6 +368,12 @@ func createBucketCache(cacheName string, cacheBackend *BucketCacheBackend, logge
 				return nil, errors.Wrapf(err, "failed to create in-memory chunk cache")
 			}
 			caches = append(caches, inMemoryCache)
+		case CacheBackendDisk:
+			diskCache, err := newDiskBucketCache(cacheName, component, cacheBackend.Disk, logger, reg)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to create disk cache")
+			}
+			caches = append(caches, diskCache)
 		case CacheBackendMemcached:
 			var client cacheutil.MemcachedClient
 			client, err := cacheutil.NewMemcachedClientWithConfig(logger, cacheName, cacheBackend.Memcached.ToMemcachedClientConfig(), reg)
@@ -373,6 +405,7 @@ func NewMatchers() Matchers {
 	matcherMap["metafile"] = isMetaFile
 	matcherMap["block-index"] = isBlockIndexFile
 	matcherMap["bucket-index"] = isBucketIndexFiles
+	matcherMap["block-exemplars"] = isBlockExemplarsFile
 	matcherMap["tenants-iter"] = isTenantsDir
 	matcherMap["tenant-blocks-iter"] = isTenantBlocksDir
 	matcherMap["chunks-iter"] = isChunksDir
@@ -406,6 +439,10 @@ func (m *Matchers) SetBucketIndexMatcher(f func(string) bool) {
 	m.matcherMap["bucket-index"] = f
 }
 
+func (m *Matchers) SetBlockExemplarsMatcher(f func(string) bool) {
+	m.matcherMap["block-exemplars"] = f
+}
+
 func (m *Matchers) SetTenantsIterMatcher(f func(string) bool) {
 	m.matcherMap["tenants-iter"] = f
 }
@@ -446,6 +483,10 @@ func (m *Matchers) GetBucketIndexMatcher() func(string) bool {
 	return m.matcherMap["bucket-index"]
 }
 
+func (m *Matchers) GetBlockExemplarsMatcher() func(string) bool {
+	return m.matcherMap["block-exemplars"]
+}
+
 func (m *Matchers) GetTenantsIterMatcher() func(string) bool {
 	return m.matcherMap["tenants-iter"]
 }
@@ -489,6 +530,16 @@ func isBucketIndexFiles(name string) bool {
 	return strings.HasSuffix(name, "/bucket-index.json.gz") || strings.HasSuffix(name, "/bucket-index-sync-status.json")
 }
 
+func isBlockExemplarsFile(name string) bool {
+	// Ensure the path ends with "<block id>/<block exemplars filename>".
+	if !strings.HasSuffix(name, "/"+BlockExemplarsFilename) {
+		return false
+	}
+
+	_, err := ulid.Parse(filepath.Base(filepath.Dir(name)))
+	return err == nil
+}
+
 func isTenantsDir(name string) bool {
 	return name == ""
 }
@@ -69,6 +69,38 @@ func Test_BucketCacheBackendValidation(t *testing.T) {
 			},
 			expectedErr: nil,
 		},
+		"valid bucket cache type (disk)": {
+			cfg: BucketCacheBackend{
+				Backend: CacheBackendDisk,
+				Disk: DiskBucketCacheConfig{
+					Directory:        "/tmp/cache",
+					MaxSizeBytes:     1024,
+					MaxItemSizeBytes: 512,
+				},
+			},
+			expectedErr: nil,
+		},
+		"invalid disk bucket cache without directory": {
+			cfg: BucketCacheBackend{
+				Backend: CacheBackendDisk,
+				Disk: DiskBucketCacheConfig{
+					MaxSizeBytes:     1024,
+					MaxItemSizeBytes: 512,
+				},
+			},
+			expectedErr: errDiskCacheDirectoryRequired,
+		},
+		"invalid disk bucket cache with max item size bigger than max size": {
+			cfg: BucketCacheBackend{
+				Backend: CacheBackendDisk,
+				Disk: DiskBucketCacheConfig{
+					Directory:        "/tmp/cache",
+					MaxSizeBytes:     512,
+					MaxItemSizeBytes: 1024,
+				},
+			},
+			expectedErr: errDiskCacheInvalidItemSize,
+		},
 		"invalid bucket cache type": {
 			cfg: BucketCacheBackend{
 				Backend: "dummy",
@@ -189,7 +221,7 @@ func Test_BucketIndexCache(t *testing.T) {
 				BucketIndexMaxSize:    1024 * 1024,
 			}
 
-			bkt, err := CreateCachingBucket(ChunksCacheConfig{}, metadataCfg, ParquetLabelsCacheConfig{}, NewMatchers(), wrappedBucket, log.NewNopLogger(), prometheus.NewRegistry())
+			bkt, err := CreateCachingBucket("test", ChunksCacheConfig{}, metadataCfg, ParquetLabelsCacheConfig{}, NewMatchers(), wrappedBucket, log.NewNopLogger(), prometheus.NewRegistry())
 			require.NoError(t, err)
 
 			r, err := bkt.Get(ctx, bucketIndexFile)
@@ -269,6 +301,64 @@ func Test_BucketIndexCacheForCompactor(t *testing.T) {
 	}
 }
 
+func Test_BlockExemplarsCache(t *testing.T) {
+	blockExemplarsFile := fmt.Sprintf("user1/%s/%s", ulid.MustNew(1, nil).String(), BlockExemplarsFilename)
+	missingBlockExemplarsFile := fmt.Sprintf("user1/%s/%s", ulid.MustNew(2, nil).String(), BlockExemplarsFilename)
+	const fileContent = "test-content"
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		ttl          time.Duration
+		expectCached bool
+	}{
+		"TTL > 0 caches block exemplars": {
+			ttl:          5 * time.Minute,
+			expectCached: true,
+		},
+		"TTL = 0 does not cache block exemplars": {
+			ttl:          0,
+			expectCached: false,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			inmem := objstore.NewInMemBucket()
+			require.NoError(t, inmem.Upload(ctx, blockExemplarsFile, bytes.NewReader([]byte(fileContent))))
+
+			wrappedBucket := &countingBucket{Bucket: inmem}
+			metadataCfg := MetadataCacheConfig{
+				BucketCacheBackend: BucketCacheBackend{
+					Backend:  CacheBackendInMemory,
+					InMemory: InMemoryBucketCacheConfig{MaxSizeBytes: 1024 * 1024},
+				},
+				BlockExemplarsContentTTL: tc.ttl,
+				BlockExemplarsMaxSize:    1024 * 1024,
+			}
+
+			bkt, err := CreateCachingBucket("test", ChunksCacheConfig{}, metadataCfg, ParquetLabelsCacheConfig{}, NewMatchers(), wrappedBucket, log.NewNopLogger(), prometheus.NewRegistry())
+			require.NoError(t, err)
+
+			for range 2 {
+				r, err := bkt.Get(ctx, blockExemplarsFile)
+				require.NoError(t, err)
+				_, _ = io.ReadAll(r)
+				_ = r.Close()
+
+				// Most blocks have no exemplars, so the missing files are cached too.
+				_, err = bkt.Get(ctx, missingBlockExemplarsFile)
+				require.True(t, bkt.IsObjNotFoundErr(err))
+			}
+
+			if tc.expectCached {
+				assert.Equal(t, int64(2), wrappedBucket.getCount, "second Gets should be served by the cache")
+			} else {
+				assert.Equal(t, int64(4), wrappedBucket.getCount, "second Gets should be served by the bucket")
+			}
+		})
+	}
+}
+
 func TestIsTenantDir(t *testing.T) {
 	assert.False(t, isTenantBlocksDir(""))
 	assert.True(t, isTenantBlocksDir("test"))
@@ -296,3 +386,14 @@ func TestIsBlockIndexFile(t *testing.T) {
 	assert.True(t, isBlockIndexFile(fmt.Sprintf("%s/index", blockID.String())))
 	assert.True(t, isBlockIndexFile(fmt.Sprintf("/%s/index", blockID.String())))
 }
+
+func TestIsBlockExemplarsFile(t *testing.T) {
+	blockID := ulid.MustNew(1, nil)
+
+	assert.False(t, isBlockExemplarsFile(""))
+	assert.False(t, isBlockExemplarsFile("/exemplars.json.gz"))
+	assert.False(t, isBlockExemplarsFile("test/exemplars.json.gz"))
+	assert.False(t, isBlockExemplarsFile(fmt.Sprintf("%s/index", blockID.String())))
+	assert.True(t, isBlockExemplarsFile(fmt.Sprintf("%s/exemplars.json.gz", blockID.String())))
+	assert.True(t, isBlockExemplarsFile(fmt.Sprintf("user1/%s/exemplars.json.gz", blockID.String())))
+}
@@ -181,6 +181,9 @@ type TSDBConfig struct {
 	// OutOfOrderCapMax is maximum capacity for OOO chunks (in samples).
 	OutOfOrderCapMax int64 `yaml:"out_of_order_cap_max"`
 
+	// If true, exemplars are persisted alongside the blocks shipped to the storage.
+	PersistExemplars bool `yaml:"persist_exemplars"`
+
 	// Posting Cache Configuration for TSDB
 	PostingsCache TSDBPostingsCacheConfig `yaml:"expanded_postings_cache" doc:"description=[EXPERIMENTAL] If enabled, ingesters will cache expanded postings when querying blocks. Caching can be configured separately for the head and compacted blocks."`
 }
@@ -210,6 +213,7 @@ func (cfg *TSDBConfig) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&cfg.MaxExemplars, "blocks-storage.tsdb.max-exemplars", 0, "Deprecated, use maxExemplars in limits instead. If the MaxExemplars value in limits is set to zero, cortex will fallback on this value. This setting enables support for exemplars in TSDB and sets the maximum number that will be stored. 0 or less means disabled.")
 	f.BoolVar(&cfg.MemorySnapshotOnShutdown, "blocks-storage.tsdb.memory-snapshot-on-shutdown", false, "True to enable snapshotting of in-memory TSDB data on disk when shutting down.")
 	f.Int64Var(&cfg.OutOfOrderCapMax, "blocks-storage.tsdb.out-of-order-cap-max", tsdb.DefaultOutOfOrderCapMax, "[EXPERIMENTAL] Configures the maximum number of samples per chunk that can be out-of-order.")
+	f.BoolVar(&cfg.PersistExemplars, "blocks-storage.tsdb.persist-exemplars", false, "[EXPERIMENTAL] True to persist exemplars in the storage. Ingesters upload the exemplars of each block alongside the block, compactors merge them when compacting blocks and queriers fetch them from the store-gateways, so that exemplars can be queried after the blocks have left the ingesters. This flag must be set on ingesters, compactors and queriers.")
 
 	flagext.DeprecatedFlag(f, "blocks-storage.tsdb.wal-compression-enabled", "Deprecated (use blocks-storage.tsdb.wal-compression-type instead): True to enable TSDB WAL compression.", util_log.Logger)
 
@@ -290,6 +294,7 @@ type BucketStoreConfig struct {
 	MetadataCache            MetadataCacheConfig      `yaml:"metadata_cache"`
 	ParquetLabelsCache       ParquetLabelsCacheConfig `yaml:"parquet_labels_cache"`
 	MatchersCacheMaxItems    int                      `yaml:"matchers_cache_max_items"`
+	ExemplarsCacheMaxItems   int                      `yaml:"exemplars_cache_max_items"`
 	IgnoreDeletionMarksDelay time.Duration            `yaml:"ignore_deletion_mark_delay"`
 	IgnoreBlocksWithin       time.Duration            `yaml:"ignore_blocks_within"`
 	IgnoreBlocksBefore       time.Duration            `yaml:"ignore_blocks_before"`
@@ -396,6 +401,7 @@ func (cfg *BucketStoreConfig) RegisterFlags(f *flag.FlagSet) {
 	f.Float64Var(&cfg.TokenBucketBytesLimiter.FetchedChunksTokenFactor, "blocks-storage.bucket-store.token-bucket-bytes-limiter.fetched-chunks-token-factor", 0, "Multiplication factor used for fetched chunks token")
 	f.Float64Var(&cfg.TokenBucketBytesLimiter.TouchedChunksTokenFactor, "blocks-storage.bucket-store.token-bucket-bytes-limiter.touched-chunks-token-factor", 1, "Multiplication factor used for touched chunks token")
 	f.IntVar(&cfg.MatchersCacheMaxItems, "blocks-storage.bucket-store.matchers-cache-max-items", 0, "Maximum number of entries in the regex matchers cache. 0 to disable.")
+	f.IntVar(&cfg.ExemplarsCacheMaxItems, "blocks-storage.bucket-store.exemplars-cache-max-items", 0, "Maximum number of blocks whose exemplars, decoded from the block exemplars file, are cached in memory by the store-gateway. 0 to disable.")
 	cfg.ParquetShardCache.RegisterFlagsWithPrefix("blocks-storage.bucket-store.", f)
 }
 
@@ -0,0 +1,472 @@
+package tsdb
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alecthomas/units"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	lru "github.com/hashicorp/golang-lru/v2/simplelru"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/thanos-io/thanos/pkg/cache"
+)
+
+const (
+	// diskCacheHeaderSize is the size of the fixed header written in front of each
+	// cached item: expiry time in unix nanoseconds (8 bytes) and key length (4 bytes).
+	diskCacheHeaderSize = 12
+
+	diskCacheTmpPrefix = "tmp-"
+
+	// The access time of the hit items is persisted in batches, either once this many
+	// items have been hit or once the interval has elapsed since the last flush.
+	diskCacheTouchBatchSize     = 1024
+	diskCacheTouchFlushInterval = time.Minute
+)
+
+var (
+	errDiskCacheDirectoryRequired = errors.New("disk cache directory is required")
+	errDiskCacheInvalidMaxSize    = errors.New("disk cache max size bytes must be greater than 0")
+	errDiskCacheInvalidItemSize   = errors.New("disk cache max item size bytes must be greater than 0 and not bigger than max size bytes")
+)
+
+type DiskBucketCacheConfig struct {
+	Directory        string `yaml:"directory"`
+	MaxSizeBytes     uint64 `yaml:"max_size_bytes"`
+	MaxItemSizeBytes uint64 `yaml:"max_item_size_bytes"`
+}
+
+func (cfg *DiskBucketCacheConfig) RegisterFlagsWithPrefix(f *flag.FlagSet, prefix string, item string) {
+	f.StringVar(&cfg.Directory, prefix+"directory", "", fmt.Sprintf("Local directory used to store the %s cache items. Each component stores its items in its own subdirectory. The content is preserved across restarts.", item))
+	f.Uint64Var(&cfg.MaxSizeBytes, prefix+"max-size-bytes", uint64(2*units.Gibibyte), fmt.Sprintf("Maximum size in bytes of the local disk %s cache of each component (shared between all tenants). Each component using the cache has its own budget, so a process running several of them can store up to this size times the number of components in the directory. Least recently used items are evicted when the limit is reached.", item))
+	f.Uint64Var(&cfg.MaxItemSizeBytes, prefix+"max-item-size-bytes", uint64(16*units.Mebibyte), fmt.Sprintf("Maximum size in bytes of a single item stored in the local disk %s cache. Bigger items are not cached.", item))
+}
+
+func (cfg *DiskBucketCacheConfig) Validate() error {
+	if cfg.Directory == "" {
+		return errDiskCacheDirectoryRequired
+	}
+	if cfg.MaxSizeBytes == 0 {
+		return errDiskCacheInvalidMaxSize
+	}
+	if cfg.MaxItemSizeBytes == 0 || cfg.MaxItemSizeBytes > cfg.MaxSizeBytes {
+		return errDiskCacheInvalidItemSize
+	}
+	return nil
+}
+
+// diskBucketCache is a cache.Cache storing each item in its own file on local disk.
+// It keeps an in-memory LRU index of the stored files, which is rebuilt from the
+// directory content on startup, and evicts the least recently used items once
+// the configured max size is exceeded. The recency order is tracked in memory and
+// persisted in batches through the files modification time.
+type diskBucketCache struct {
+	logger           log.Logger
+	name             string
+	dir              string
+	maxSizeBytes     uint64
+	maxItemSizeBytes uint64
+
+	mtx       sync.Mutex
+	curSize   uint64
+	lru       *lru.LRU[string, diskCacheEntry]
+	touched   map[string]struct{}
+	lastFlush time.Time
+
+	requests    prometheus.Counter
+	hits        prometheus.Counter
+	hitsExpired prometheus.Counter
+	added       prometheus.Counter
+	evicted     prometheus.Counter
+	overflow    prometheus.Counter
+	failures    *prometheus.CounterVec
+	items       prometheus.Gauge
+	sizeBytes   prometheus.Gauge
+}
+
+type diskCacheEntry struct {
+	size       uint64
+	expiryTime time.Time
+}
+
+// newDiskBucketCache creates a disk cache storing its items in the given subdirectory
+// of the configured directory. Each component owns its caches, so the subdirectory must
+// not be used by any other cache in the process.
+func newDiskBucketCache(name, subdir string, cfg DiskBucketCacheConfig, logger log.Logger, reg prometheus.Registerer) (*diskBucketCache, error) {
+	dir, err := filepath.Abs(filepath.Join(cfg.Directory, subdir, name))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, errors.Wrapf(err, "failed to create disk cache directory %s", dir)
+	}
+
+	c := &diskBucketCache{
+		logger:           log.With(logger, "cache", name, "dir", dir),
+		name:             name,
+		dir:              dir,
+		maxSizeBytes:     cfg.MaxSizeBytes,
+		maxItemSizeBytes: cfg.MaxItemSizeBytes,
+		touched:          map[string]struct{}{},
+		lastFlush:        time.Now(),
+	}
+
+	constLabels := prometheus.Labels{"name": name}
+	c.requests = promauto.With(reg).NewCounter(prometheus.CounterOpts{
+		Name:        "cortex_bucket_cache_disk_requests_total",
+		Help:        "Total number of requests to the disk cache.",
+		ConstLabels: constLabels,
+	})
+	c.hits = promauto.With(reg).NewCounter(prometheus.CounterOpts{
+		Name:        "cortex_bucket_cache_disk_hits_total",
+		Help:        "Total number of requests to the disk cache that were a hit.",
+		ConstLabels: constLabels,
+	})
+	c.hitsExpired = promauto.With(reg).NewCounter(prometheus.CounterOpts{
+		Name:        "cortex_bucket_cache_disk_hits_on_expired_data_total",
+		Help:        "Total number of requests to the disk cache that were a hit but needed to be evicted due to TTL.",
+		ConstLabels: constLabels,
+	})
+	c.added = promauto.With(reg).NewCounter(prometheus.CounterOpts{
+		Name:        "cortex_bucket_cache_disk_items_added_total",
+		Help:        "Total number of items that were added to the disk cache.",
+		ConstLabels: constLabels,
+	})
+	c.evicted = promauto.With(reg).NewCounter(prometheus.CounterOpts{
+		Name:        "cortex_bucket_cache_disk_items_evicted_total",
+		Help:        "Total number of items that were evicted from the disk cache.",
+		ConstLabels: constLabels,
+	})
+	c.overflow = promauto.With(reg).NewCounter(prometheus.CounterOpts{
+		Name:        "cortex_bucket_cache_disk_items_overflowed_total",
+		Help:        "Total number of items that could not be added to the disk cache due to being too big.",
+		ConstLabels: constLabels,
+	})
+	c.failures = promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+		Name:        "cortex_bucket_cache_disk_operation_failures_total",
+		Help:        "Total number of disk cache operations that failed.",
+		ConstLabels: constLabels,
+	}, []string{"operation"})
+	c.items = promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+		Name:        "cortex_bucket_cache_disk_items",
+		Help:        "Current number of items in the disk cache.",
+		ConstLabels: constLabels,
+	})
+	c.sizeBytes = promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+		Name:        "cortex_bucket_cache_disk_items_size_bytes",
+		Help:        "Current byte size of items in the disk cache.",
+		ConstLabels: constLabels,
+	})
+	promauto.With(reg).NewGaugeFunc(prometheus.GaugeOpts{
+		Name:        "cortex_bucket_cache_disk_max_size_bytes",
+		Help:        "Maximum number of bytes to be held in the disk cache.",
+		ConstLabels: constLabels,
+	}, func() float64 {
+		return float64(c.maxSizeBytes)
+	})
+
+	// Evictions are driven by the stored size using RemoveOldest, so the LRU itself is unbounded.
+	l, err := lru.NewLRU[string, diskCacheEntry](math.MaxInt, c.onEvict)
+	if err != nil {
+		return nil, err
+	}
+	c.lru = l
+
+	if err := c.load(); err != nil {
+		return nil, errors.Wrapf(err, "failed to load disk cache from %s", dir)
+	}
+
+	level.Info(c.logger).Log("msg", "created disk cache", "items", c.lru.Len(), "sizeBytes", c.curSize, "maxSizeBytes", c.maxSizeBytes, "maxItemSizeBytes", c.maxItemSizeBytes)
+
+	return c, nil
+}
+
+// load rebuilds the LRU index from the files found in the cache directory. Files
+// are added ordered by modification time, which is refreshed in batches for the hit
+// items, so the recency order is preserved across restarts.
+func (c *diskBucketCache) load() error {
+	type file struct {
+		path    string
+		size    uint64
+		modTime time.Time
+	}
+
+	var files []file
+	err := filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		// Leftovers of an interrupted write.
+		if strings.HasPrefix(d.Name(), diskCacheTmpPrefix) {
+			return os.Remove(path)
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, file{path: path, size: uint64(info.Size()), modTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	slices.SortFunc(files, func(a, b file) int {
+		return a.modTime.Compare(b.modTime)
+	})
+
+	now := time.Now()
+	for _, f := range files {
+		expiry, err := readDiskCacheExpiry(f.path)
+		if err != nil || now.After(expiry) {
+			// Corrupted or expired, drop it.
+			_ = os.Remove(f.path)
+			continue
+		}
+
+		c.lru.Add(filepath.Base(f.path), diskCacheEntry{size: f.size, expiryTime: expiry})
+		c.curSize += f.size
+		c.items.Inc()
+		c.sizeBytes.Add(float64(f.size))
+		c.ensureFits(0)
+	}
+
+	return nil
+}
+
+func (c *diskBucketCache) onEvict(hash string, entry diskCacheEntry) {
+	if err := os.Remove(c.path(hash)); err != nil && !os.IsNotExist(err) {
+		c.failures.WithLabelValues("evict").Inc()
+		level.Warn(c.logger).Log("msg", "failed to remove evicted disk cache item", "err", err)
+	}
+	delete(c.touched, hash)
+	c.curSize -= entry.size
+	c.evicted.Inc()
+	c.items.Dec()
+	c.sizeBytes.Sub(float64(entry.size))
+}
+
+// ensureFits evicts the least recently used items until an item of the given size
+// fits into the cache. Must be called with the lock held.
+func (c *diskBucketCache) ensureFits(size uint64) {
+	for c.curSize+size > c.maxSizeBytes {
+		if _, _, ok := c.lru.RemoveOldest(); !ok {
+			return
+		}
+	}
+}
+
+func (c *diskBucketCache) path(hash string) string {
+	return filepath.Join(c.dir, hash[:2], hash)
+}
+
+func diskCacheKeyHash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *diskBucketCache) Store(data map[string][]byte, ttl time.Duration) {
+	expiry := time.Now().Add(ttl)
+
+	for key, val := range data {
+		// The stored size accounts for the header and the key written in front of the value.
+		size := uint64(diskCacheHeaderSize + len(key) + len(val))
+		if size > c.maxItemSizeBytes {
+			c.overflow.Inc()
+			continue
+		}
+
+		hash := diskCacheKeyHash(key)
+		tmp, err := c.writeTemp(hash, key, val, expiry)
+		if err != nil {
+			c.failures.WithLabelValues("store").Inc()
+			level.Warn(c.logger).Log("msg", "failed to store item in disk cache", "err", err)
+			continue
+		}
+
+		// The file is moved in place under the same lock taken by the eviction, so that the
+		// index always matches the files on disk and the stored size stays exact.
+		c.mtx.Lock()
+		if err := os.Rename(tmp, c.path(hash)); err != nil {
+			c.mtx.Unlock()
+			_ = os.Remove(tmp)
+			c.failures.WithLabelValues("store").Inc()
+			level.Warn(c.logger).Log("msg", "failed to store item in disk cache", "err", err)
+			continue
+		}
+		// The file of an existing item has just been overwritten, so update the index
+		// in place instead of removing it (which would delete the file).
+		if old, ok := c.lru.Peek(hash); ok {
+			c.curSize -= old.size
+			c.sizeBytes.Sub(float64(old.size))
+		} else {
+			c.items.Inc()
+		}
+		c.lru.Add(hash, diskCacheEntry{size: size, expiryTime: expiry})
+		c.curSize += size
+		c.sizeBytes.Add(float64(size))
+		c.added.Inc()
+		c.ensureFits(0)
+		c.mtx.Unlock()
+	}
+}
+
+// writeTemp writes an item to a temporary file next to its final path, and returns the
+// path of the temporary file.
+func (c *diskBucketCache) writeTemp(hash, key string, val []byte, expiry time.Time) (string, error) {
+	dir := filepath.Dir(c.path(hash))
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", err
+	}
+
+	f, err := os.CreateTemp(dir, diskCacheTmpPrefix)
+	if err != nil {
+		return "", err
+	}
+	tmp := f.Name()
+
+	buf := make([]byte, diskCacheHeaderSize, diskCacheHeaderSize+len(key)+len(val))
+	binary.BigEndian.PutUint64(buf[0:8], uint64(expiry.UnixNano()))
+	binary.BigEndian.PutUint32(buf[8:12], uint32(len(key)))
+	buf = append(buf, key...)
+	buf = append(buf, val...)
+
+	if _, err := f.Write(buf); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return "", err
+	}
+	return tmp, nil
+}
+
+func (c *diskBucketCache) Fetch(ctx context.Context, keys []string) map[string][]byte {
+	results := make(map[string][]byte)
+	defer c.maybeFlushTouched()
+
+	for _, key := range keys {
+		if ctx.Err() != nil {
+			return results
+		}
+		c.requests.Inc()
+
+		hash := diskCacheKeyHash(key)
+		c.mtx.Lock()
+		entry, ok := c.lru.Get(hash)
+		if ok && time.Now().After(entry.expiryTime) {
+			c.lru.Remove(hash)
+			c.hitsExpired.Inc()
+			ok = false
+		}
+		c.mtx.Unlock()
+		if !ok {
+			continue
+		}
+
+		val, err := c.read(hash, key)
+		if os.IsNotExist(err) {
+			// Evicted in the meantime.
+			continue
+		}
+		if err != nil {
+			c.failures.WithLabelValues("fetch").Inc()
+			level.Warn(c.logger).Log("msg", "failed to read item from disk cache", "err", err)
+			c.mtx.Lock()
+			c.lru.Remove(hash)
+			c.mtx.Unlock()
+			continue
+		}
+
+		c.mtx.Lock()
+		c.touched[hash] = struct{}{}
+		c.mtx.Unlock()
+
+		c.hits.Inc()
+		results[key] = val
+	}
+
+	return results
+}
+
+// maybeFlushTouched refreshes the modification time of the items hit since the last
+// flush, to keep the recency order across restarts, once enough of them are pending.
+func (c *diskBucketCache) maybeFlushTouched() {
+	c.mtx.Lock()
+	now := time.Now()
+	if len(c.touched) == 0 || (len(c.touched) < diskCacheTouchBatchSize && now.Sub(c.lastFlush) < diskCacheTouchFlushInterval) {
+		c.mtx.Unlock()
+		return
+	}
+	touched := c.touched
+	c.touched = make(map[string]struct{}, len(touched))
+	c.lastFlush = now
+	c.mtx.Unlock()
+
+	for hash := range touched {
+		// The item may have been evicted in the meantime.
+		if err := os.Chtimes(c.path(hash), now, now); err != nil && !os.IsNotExist(err) {
+			c.failures.WithLabelValues("touch").Inc()
+		}
+	}
+}
+
+func (c *diskBucketCache) read(hash, key string) ([]byte, error) {
+	b, err := os.ReadFile(c.path(hash))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < diskCacheHeaderSize {
+		return nil, errors.New("truncated disk cache item")
+	}
+	keyLen := int(binary.BigEndian.Uint32(b[8:12]))
+	if len(b) < diskCacheHeaderSize+keyLen {
+		return nil, errors.New("truncated disk cache item")
+	}
+	if string(b[diskCacheHeaderSize:diskCacheHeaderSize+keyLen]) != key {
+		return nil, errors.New("disk cache item key mismatch")
+	}
+	return b[diskCacheHeaderSize+keyLen:], nil
+}
+
+func readDiskCacheExpiry(path string) (time.Time, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer f.Close()
+
+	header := make([]byte, diskCacheHeaderSize)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, int64(binary.BigEndian.Uint64(header[0:8]))), nil
+}
+
+func (c *diskBucketCache) Name() string {
+	return c.name
+}
+
+var _ cache.Cache = (*diskBucketCache)(nil)
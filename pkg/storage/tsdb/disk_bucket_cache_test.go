@@ -0,0 +1,229 @@
+package tsdb
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDiskBucketCache(t *testing.T, dir string, maxSize, maxItemSize uint64, reg prometheus.Registerer) *diskBucketCache {
+	t.Helper()
+
+	c, err := newDiskBucketCache("chunks-cache", "store-gateway", DiskBucketCacheConfig{
+		Directory:        dir,
+		MaxSizeBytes:     maxSize,
+		MaxItemSizeBytes: maxItemSize,
+	}, log.NewNopLogger(), reg)
+	require.NoError(t, err)
+	return c
+}
+
+func TestDiskBucketCache_StoreAndFetch(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	c := newTestDiskBucketCache(t, t.TempDir(), 1024, 512, reg)
+
+	c.Store(map[string][]byte{
+		"key1": []byte("value1"),
+		"key2": []byte("value2"),
+	}, time.Hour)
+
+	hits := c.Fetch(context.Background(), []string{"key1", "key2", "key3"})
+	assert.Equal(t, map[string][]byte{
+		"key1": []byte("value1"),
+		"key2": []byte("value2"),
+	}, hits)
+
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+		# HELP cortex_bucket_cache_disk_hits_total Total number of requests to the disk cache that were a hit.
+		# TYPE cortex_bucket_cache_disk_hits_total counter
+		cortex_bucket_cache_disk_hits_total{name="chunks-cache"} 2
+		# HELP cortex_bucket_cache_disk_requests_total Total number of requests to the disk cache.
+		# TYPE cortex_bucket_cache_disk_requests_total counter
+		cortex_bucket_cache_disk_requests_total{name="chunks-cache"} 3
+		# HELP cortex_bucket_cache_disk_items Current number of items in the disk cache.
+		# TYPE cortex_bucket_cache_disk_items gauge
+		cortex_bucket_cache_disk_items{name="chunks-cache"} 2
+	`), "cortex_bucket_cache_disk_hits_total", "cortex_bucket_cache_disk_requests_total", "cortex_bucket_cache_disk_items"))
+}
+
+func TestDiskBucketCache_OverwriteKey(t *testing.T) {
+	c := newTestDiskBucketCache(t, t.TempDir(), 1024, 512, nil)
+
+	c.Store(map[string][]byte{"key": []byte("old")}, time.Hour)
+	c.Store(map[string][]byte{"key": []byte("new-value")}, time.Hour)
+
+	assert.Equal(t, map[string][]byte{"key": []byte("new-value")}, c.Fetch(context.Background(), []string{"key"}))
+	assert.Equal(t, 1, c.lru.Len())
+	assert.Equal(t, uint64(diskCacheHeaderSize+len("key")+len("new-value")), c.curSize)
+}
+
+func TestDiskBucketCache_Expiry(t *testing.T) {
+	c := newTestDiskBucketCache(t, t.TempDir(), 1024, 512, nil)
+
+	c.Store(map[string][]byte{"key": []byte("value")}, -time.Second)
+
+	assert.Empty(t, c.Fetch(context.Background(), []string{"key"}))
+	assert.Equal(t, 0, c.lru.Len())
+	assert.NoFileExists(t, c.path(diskCacheKeyHash("key")))
+}
+
+func TestDiskBucketCache_Eviction(t *testing.T) {
+	// Each item takes 12 bytes of header + 4 bytes of key + 20 bytes of value.
+	const itemSize = diskCacheHeaderSize + 4 + 20
+	c := newTestDiskBucketCache(t, t.TempDir(), 3*itemSize, itemSize, nil)
+
+	value := []byte(strings.Repeat("x", 20))
+	c.Store(map[string][]byte{"key1": value}, time.Hour)
+	c.Store(map[string][]byte{"key2": value}, time.Hour)
+	c.Store(map[string][]byte{"key3": value}, time.Hour)
+
+	// Touch key1 so that key2 becomes the least recently used.
+	require.Len(t, c.Fetch(context.Background(), []string{"key1"}), 1)
+
+	c.Store(map[string][]byte{"key4": value}, time.Hour)
+
+	hits := c.Fetch(context.Background(), []string{"key1", "key2", "key3", "key4"})
+	assert.Len(t, hits, 3)
+	assert.NotContains(t, hits, "key2")
+	assert.NoFileExists(t, c.path(diskCacheKeyHash("key2")))
+	assert.Equal(t, uint64(3*itemSize), c.curSize)
+
+	// Items bigger than the max item size are not cached.
+	c.Store(map[string][]byte{"big": []byte(strings.Repeat("x", 100))}, time.Hour)
+	assert.Empty(t, c.Fetch(context.Background(), []string{"big"}))
+}
+
+func TestDiskBucketCache_PersistenceAcrossRestarts(t *testing.T) {
+	dir := t.TempDir()
+
+	c := newTestDiskBucketCache(t, dir, 1024, 512, nil)
+	c.Store(map[string][]byte{
+		"key1": []byte("value1"),
+		"key2": []byte("value2"),
+	}, time.Hour)
+	c.Store(map[string][]byte{"expired": []byte("value")}, -time.Second)
+
+	// Simulate a leftover of an interrupted write.
+	tmpFile := filepath.Join(c.dir, "ab", diskCacheTmpPrefix+"123")
+	require.NoError(t, os.MkdirAll(filepath.Dir(tmpFile), os.ModePerm))
+	require.NoError(t, os.WriteFile(tmpFile, []byte("partial"), os.ModePerm))
+
+	restarted := newTestDiskBucketCache(t, dir, 1024, 512, nil)
+	assert.Equal(t, 2, restarted.lru.Len())
+	assert.Equal(t, c.curSize-uint64(diskCacheHeaderSize+len("expired")+len("value")), restarted.curSize)
+	assert.NoFileExists(t, tmpFile)
+
+	assert.Equal(t, map[string][]byte{
+		"key1": []byte("value1"),
+		"key2": []byte("value2"),
+	}, restarted.Fetch(context.Background(), []string{"key1", "key2", "expired"}))
+}
+
+func TestDiskBucketCache_DirectoryPerComponent(t *testing.T) {
+	dir := t.TempDir()
+	cfg := DiskBucketCacheConfig{Directory: dir, MaxSizeBytes: 1024, MaxItemSizeBytes: 512}
+
+	querier, err := newDiskBucketCache("metadata-cache", "querier", cfg, log.NewNopLogger(), prometheus.NewPedanticRegistry())
+	require.NoError(t, err)
+	storeGateway, err := newDiskBucketCache("metadata-cache", "store-gateway", cfg, log.NewNopLogger(), prometheus.NewPedanticRegistry())
+	require.NoError(t, err)
+
+	assert.Equal(t, filepath.Join(dir, "querier", "metadata-cache"), querier.dir)
+	assert.Equal(t, filepath.Join(dir, "store-gateway", "metadata-cache"), storeGateway.dir)
+
+	querier.Store(map[string][]byte{"key": []byte("value")}, time.Hour)
+	assert.Len(t, querier.Fetch(context.Background(), []string{"key"}), 1)
+	assert.Empty(t, storeGateway.Fetch(context.Background(), []string{"key"}))
+}
+
+func TestDiskBucketCache_AccessTimesAreUpdatedInBatches(t *testing.T) {
+	c := newTestDiskBucketCache(t, t.TempDir(), 1024, 512, nil)
+	c.Store(map[string][]byte{"key": []byte("value")}, time.Hour)
+
+	path := c.path(diskCacheKeyHash("key"))
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	require.NoError(t, os.Chtimes(path, past, past))
+
+	// A hit is only tracked in memory until the batch is flushed.
+	require.Len(t, c.Fetch(context.Background(), []string{"key"}), 1)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, past, info.ModTime())
+	assert.Len(t, c.touched, 1)
+
+	// Once the flush interval has elapsed, the next fetch persists the pending access times.
+	c.lastFlush = time.Now().Add(-diskCacheTouchFlushInterval)
+	assert.Empty(t, c.Fetch(context.Background(), []string{"missing"}))
+	info, err = os.Stat(path)
+	require.NoError(t, err)
+	assert.True(t, info.ModTime().After(past))
+	assert.Empty(t, c.touched)
+}
+
+func TestDiskBucketCache_ItemSizeIncludesHeaderAndKey(t *testing.T) {
+	reg := prometheus.NewPedanticRegistry()
+	c := newTestDiskBucketCache(t, t.TempDir(), 1024, 64, reg)
+
+	key := "key"
+	fitting := make([]byte, 64-diskCacheHeaderSize-len(key))
+	c.Store(map[string][]byte{key: fitting}, time.Hour)
+	assert.Len(t, c.Fetch(context.Background(), []string{key}), 1)
+	assert.Equal(t, uint64(64), c.curSize)
+
+	// The value alone fits into the max item size, but not with the header and key.
+	c.Store(map[string][]byte{"other": make([]byte, 60)}, time.Hour)
+	assert.Empty(t, c.Fetch(context.Background(), []string{"other"}))
+	assert.Equal(t, float64(1), testutil.ToFloat64(c.overflow))
+}
+
+func TestDiskBucketCache_ConcurrentStoresAndEvictionsKeepTheIndexInSyncWithTheFiles(t *testing.T) {
+	const itemSize = diskCacheHeaderSize + 6 + 20
+	c := newTestDiskBucketCache(t, t.TempDir(), 10*itemSize, itemSize, nil)
+
+	value := []byte(strings.Repeat("x", 20))
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				// The workers overwrite each other's keys while evicting them.
+				key := fmt.Sprintf("key%03d", (w*7+i)%50)
+				c.Store(map[string][]byte{key: value}, time.Hour)
+				c.Fetch(context.Background(), []string{key})
+			}
+		}()
+	}
+	wg.Wait()
+
+	var files []string
+	var size uint64
+	require.NoError(t, filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, d.Name())
+		size += uint64(info.Size())
+		return nil
+	}))
+
+	assert.ElementsMatch(t, c.lru.Keys(), files)
+	assert.Equal(t, size, c.curSize)
+	assert.LessOrEqual(t, c.curSize, uint64(10*itemSize))
+}
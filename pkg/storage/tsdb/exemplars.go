@@ -0,0 +1,184 @@
+package tsdb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+
+	"github.com/go-kit/log"
+	"github.com/oklog/ulid/v2"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/thanos-io/objstore"
+
+	"github.com/cortexproject/cortex/pkg/util/runutil"
+)
+
+const (
+	// BlockExemplarsFilename is the known gzipped json filename holding the exemplars of a block's series.
+	// The file is stored alongside the block files, both on the local disk and in the storage.
+	BlockExemplarsFilename = "exemplars.json.gz"
+	// BlockExemplarsVersion1 is the current supported version of the block exemplars file.
+	BlockExemplarsVersion1 = 1
+)
+
+var (
+	ErrBlockExemplarsCorrupted          = errors.New("block exemplars corrupted")
+	ErrBlockExemplarsUnsupportedVersion = errors.New("block exemplars unsupported version")
+)
+
+// BlockExemplars holds the exemplars persisted alongside a block.
+type BlockExemplars struct {
+	// Version of the file.
+	Version int `json:"version"`
+	// Series with their exemplars, sorted by labels.
+	Series []exemplar.QueryResult `json:"series"`
+}
+
+// WriteBlockExemplars writes the exemplars of the input series to the block exemplars file
+// in the block dir. Exemplars with a non finite value are skipped because they can't be
+// encoded in json.
+func WriteBlockExemplars(blockDir string, series []exemplar.QueryResult) error {
+	filtered := make([]exemplar.QueryResult, 0, len(series))
+	for _, s := range series {
+		exemplars := make([]exemplar.Exemplar, 0, len(s.Exemplars))
+		for _, e := range s.Exemplars {
+			if !math.IsNaN(e.Value) && !math.IsInf(e.Value, 0) {
+				exemplars = append(exemplars, e)
+			}
+		}
+		if len(exemplars) > 0 {
+			filtered = append(filtered, exemplar.QueryResult{SeriesLabels: s.SeriesLabels, Exemplars: exemplars})
+		}
+	}
+
+	content, err := json.Marshal(BlockExemplars{Version: BlockExemplarsVersion1, Series: filtered})
+	if err != nil {
+		return errors.Wrap(err, "marshal block exemplars")
+	}
+
+	var gzipContent bytes.Buffer
+	gz := gzip.NewWriter(&gzipContent)
+	if _, err := gz.Write(content); err != nil {
+		return errors.Wrap(err, "gzip block exemplars")
+	}
+	if err := gz.Close(); err != nil {
+		return errors.Wrap(err, "close gzip block exemplars")
+	}
+
+	// Write to a temporary file first, so that a partially written file is never uploaded.
+	filename := filepath.Join(blockDir, BlockExemplarsFilename)
+	if err := os.WriteFile(filename+".tmp", gzipContent.Bytes(), 0o644); err != nil {
+		return errors.Wrap(err, "write block exemplars")
+	}
+	return errors.Wrap(os.Rename(filename+".tmp", filename), "rename block exemplars")
+}
+
+// UploadBlockExemplars uploads the block exemplars file found in the block dir to the block
+// location in the storage. Returns false if the block dir has no block exemplars file.
+func UploadBlockExemplars(ctx context.Context, logger log.Logger, bkt objstore.Bucket, blockDir string) (bool, error) {
+	blockID, err := ulid.Parse(filepath.Base(blockDir))
+	if err != nil {
+		return false, errors.Wrapf(err, "parse block ID from %s", blockDir)
+	}
+
+	src := filepath.Join(blockDir, BlockExemplarsFilename)
+	if _, err := os.Stat(src); errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+
+	if err := objstore.UploadFile(ctx, logger, bkt, src, path.Join(blockID.String(), BlockExemplarsFilename)); err != nil {
+		return false, errors.Wrap(err, "upload block exemplars")
+	}
+	return true, nil
+}
+
+// ReadBlockExemplars reads the exemplars of a block from the storage. No error is returned
+// if the block has no exemplars file.
+func ReadBlockExemplars(ctx context.Context, logger log.Logger, bkt objstore.BucketReader, blockID ulid.ULID) ([]exemplar.QueryResult, error) {
+	reader, err := bkt.Get(ctx, path.Join(blockID.String(), BlockExemplarsFilename))
+	if err != nil {
+		if bkt.IsObjNotFoundErr(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "read exemplars of block %s", blockID.String())
+	}
+	defer runutil.CloseWithLogOnErr(logger, reader, "close block exemplars reader")
+
+	gzipReader, err := gzip.NewReader(reader)
+	if err != nil {
+		return nil, ErrBlockExemplarsCorrupted
+	}
+	defer runutil.CloseWithLogOnErr(logger, gzipReader, "close block exemplars gzip reader")
+
+	content := BlockExemplars{}
+	if err := json.NewDecoder(gzipReader).Decode(&content); err != nil {
+		return nil, ErrBlockExemplarsCorrupted
+	}
+	if content.Version != BlockExemplarsVersion1 {
+		return nil, ErrBlockExemplarsUnsupportedVersion
+	}
+
+	return content.Series, nil
+}
+
+// FilterExemplars returns the exemplars of the series matching the keep function, with a
+// timestamp within minT and maxT (both included). Series left without exemplars are dropped.
+func FilterExemplars(series []exemplar.QueryResult, minT, maxT int64, keep func(labels.Labels) bool) []exemplar.QueryResult {
+	var result []exemplar.QueryResult
+
+	for _, s := range series {
+		if keep != nil && !keep(s.SeriesLabels) {
+			continue
+		}
+
+		var exemplars []exemplar.Exemplar
+		for _, e := range s.Exemplars {
+			if e.Ts >= minT && e.Ts <= maxT {
+				exemplars = append(exemplars, e)
+			}
+		}
+		if len(exemplars) > 0 {
+			result = append(result, exemplar.QueryResult{SeriesLabels: s.SeriesLabels, Exemplars: exemplars})
+		}
+	}
+
+	return result
+}
+
+// MergeExemplars merges the input series by labels, deduplicating their exemplars. The
+// returned series are sorted by labels, and their exemplars by timestamp.
+func MergeExemplars(sets ...[]exemplar.QueryResult) []exemplar.QueryResult {
+	byLabels := map[string]*exemplar.QueryResult{}
+	for _, set := range sets {
+		for _, s := range set {
+			key := s.SeriesLabels.String()
+			if merged, ok := byLabels[key]; ok {
+				merged.Exemplars = append(merged.Exemplars, s.Exemplars...)
+				continue
+			}
+			byLabels[key] = &exemplar.QueryResult{SeriesLabels: s.SeriesLabels, Exemplars: slices.Clone(s.Exemplars)}
+		}
+	}
+
+	result := make([]exemplar.QueryResult, 0, len(byLabels))
+	for _, s := range byLabels {
+		slices.SortFunc(s.Exemplars, exemplar.Compare)
+		s.Exemplars = slices.CompactFunc(s.Exemplars, func(a, b exemplar.Exemplar) bool {
+			return exemplar.Compare(a, b) == 0
+		})
+		result = append(result, *s)
+	}
+	slices.SortFunc(result, func(a, b exemplar.QueryResult) int {
+		return labels.Compare(a.SeriesLabels, b.SeriesLabels)
+	})
+
+	return result
+}
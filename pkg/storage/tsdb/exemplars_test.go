@@ -0,0 +1,152 @@
+package tsdb
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/oklog/ulid/v2"
+	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+)
+
+func TestBlockExemplars_WriteUploadRead(t *testing.T) {
+	ctx := context.Background()
+	bkt := objstore.NewInMemBucket()
+	blockID := ulid.MustNew(1, nil)
+	blockDir := filepath.Join(t.TempDir(), blockID.String())
+	require.NoError(t, os.Mkdir(blockDir, os.ModePerm))
+
+	// No error is returned when there are no exemplars.
+	uploaded, err := UploadBlockExemplars(ctx, log.NewNopLogger(), bkt, blockDir)
+	require.NoError(t, err)
+	assert.False(t, uploaded)
+
+	series, err := ReadBlockExemplars(ctx, log.NewNopLogger(), bkt, blockID)
+	require.NoError(t, err)
+	assert.Empty(t, series)
+
+	require.NoError(t, WriteBlockExemplars(blockDir, []exemplar.QueryResult{
+		{
+			SeriesLabels: labels.FromStrings("__name__", "series_1"),
+			Exemplars: []exemplar.Exemplar{
+				{Labels: labels.FromStrings("trace_id", "1"), Value: 1, Ts: 10, HasTs: true},
+				{Labels: labels.FromStrings("trace_id", "2"), Value: math.NaN(), Ts: 20, HasTs: true},
+			},
+		},
+		{
+			SeriesLabels: labels.FromStrings("__name__", "series_2"),
+			Exemplars: []exemplar.Exemplar{
+				{Labels: labels.FromStrings("trace_id", "3"), Value: math.Inf(1), Ts: 30, HasTs: true},
+			},
+		},
+	}))
+
+	uploaded, err = UploadBlockExemplars(ctx, log.NewNopLogger(), bkt, blockDir)
+	require.NoError(t, err)
+	assert.True(t, uploaded)
+
+	series, err = ReadBlockExemplars(ctx, log.NewNopLogger(), bkt, blockID)
+	require.NoError(t, err)
+	assert.Equal(t, []exemplar.QueryResult{
+		{
+			SeriesLabels: labels.FromStrings("__name__", "series_1"),
+			Exemplars: []exemplar.Exemplar{
+				{Labels: labels.FromStrings("trace_id", "1"), Value: 1, Ts: 10, HasTs: true},
+			},
+		},
+	}, series)
+
+	// A corrupted file is reported.
+	require.NoError(t, bkt.Upload(ctx, blockID.String()+"/"+BlockExemplarsFilename, strings.NewReader("invalid")))
+	_, err = ReadBlockExemplars(ctx, log.NewNopLogger(), bkt, blockID)
+	require.Equal(t, ErrBlockExemplarsCorrupted, err)
+}
+
+func TestFilterExemplars(t *testing.T) {
+	series := []exemplar.QueryResult{
+		{
+			SeriesLabels: labels.FromStrings("__name__", "series_1"),
+			Exemplars: []exemplar.Exemplar{
+				{Labels: labels.FromStrings("trace_id", "1"), Value: 1, Ts: 10},
+				{Labels: labels.FromStrings("trace_id", "2"), Value: 2, Ts: 20},
+			},
+		},
+		{
+			SeriesLabels: labels.FromStrings("__name__", "series_2"),
+			Exemplars: []exemplar.Exemplar{
+				{Labels: labels.FromStrings("trace_id", "3"), Value: 3, Ts: 30},
+			},
+		},
+	}
+
+	assert.Equal(t, []exemplar.QueryResult{
+		{
+			SeriesLabels: labels.FromStrings("__name__", "series_1"),
+			Exemplars: []exemplar.Exemplar{
+				{Labels: labels.FromStrings("trace_id", "2"), Value: 2, Ts: 20},
+			},
+		},
+	}, FilterExemplars(series, 15, 25, nil))
+
+	assert.Equal(t, []exemplar.QueryResult{series[1]}, FilterExemplars(series, 0, 100, func(lbls labels.Labels) bool {
+		return lbls.Get("__name__") == "series_2"
+	}))
+
+	assert.Empty(t, FilterExemplars(series, 40, 50, nil))
+}
+
+func TestMergeExemplars(t *testing.T) {
+	first := []exemplar.QueryResult{
+		{
+			SeriesLabels: labels.FromStrings("__name__", "series_2"),
+			Exemplars: []exemplar.Exemplar{
+				{Labels: labels.FromStrings("trace_id", "2"), Value: 2, Ts: 20},
+				{Labels: labels.FromStrings("trace_id", "3"), Value: 3, Ts: 30},
+			},
+		},
+	}
+	second := []exemplar.QueryResult{
+		{
+			SeriesLabels: labels.FromStrings("__name__", "series_2"),
+			Exemplars: []exemplar.Exemplar{
+				{Labels: labels.FromStrings("trace_id", "1"), Value: 1, Ts: 10},
+				{Labels: labels.FromStrings("trace_id", "3"), Value: 3, Ts: 30},
+			},
+		},
+		{
+			SeriesLabels: labels.FromStrings("__name__", "series_1"),
+			Exemplars: []exemplar.Exemplar{
+				{Labels: labels.FromStrings("trace_id", "4"), Value: 4, Ts: 40},
+			},
+		},
+	}
+
+	assert.Equal(t, []exemplar.QueryResult{
+		{
+			SeriesLabels: labels.FromStrings("__name__", "series_1"),
+			Exemplars: []exemplar.Exemplar{
+				{Labels: labels.FromStrings("trace_id", "4"), Value: 4, Ts: 40},
+			},
+		},
+		{
+			SeriesLabels: labels.FromStrings("__name__", "series_2"),
+			Exemplars: []exemplar.Exemplar{
+				{Labels: labels.FromStrings("trace_id", "1"), Value: 1, Ts: 10},
+				{Labels: labels.FromStrings("trace_id", "2"), Value: 2, Ts: 20},
+				{Labels: labels.FromStrings("trace_id", "3"), Value: 3, Ts: 30},
+			},
+		},
+	}, MergeExemplars(first, second))
+
+	// The input isn't modified.
+	assert.Len(t, first[0].Exemplars, 2)
+	assert.Empty(t, MergeExemplars())
+}
@@ -35,6 +35,7 @@ import (
 
 	"github.com/cortexproject/cortex/pkg/storage/bucket"
 	"github.com/cortexproject/cortex/pkg/storage/tsdb"
+	"github.com/cortexproject/cortex/pkg/storegateway/storegatewaypb"
 	"github.com/cortexproject/cortex/pkg/util"
 	"github.com/cortexproject/cortex/pkg/util/backoff"
 	cortex_errors "github.com/cortexproject/cortex/pkg/util/errors"
@@ -49,6 +50,9 @@ type BucketStores interface {
 	storepb.StoreServer
 	SyncBlocks(ctx context.Context) error
 	InitialSync(ctx context.Context) error
+
+	// Exemplars returns the exemplars persisted alongside the requested blocks.
+	Exemplars(ctx context.Context, req *storegatewaypb.ExemplarsRequest) (*storegatewaypb.ExemplarsResponse, error)
 }
 
 // ThanosBucketStores is a multi-tenant wrapper of Thanos BucketStore.
@@ -68,6 +72,9 @@ type ThanosBucketStores struct {
 	// Matchers cache shared across all tenants
 	matcherCache storecache.MatchersCache
 
+	// Reader of the exemplars persisted alongside the blocks.
+	exemplarsReader *exemplarsReader
+
 	// Chunks bytes pool shared across all tenants.
 	chunksPool pool.Pool[byte]
 
@@ -119,7 +126,7 @@ func NewBucketStores(cfg tsdb.BlocksStorageConfig, shardingStrategy ShardingStra
 // newThanosBucketStores creates a new TSDB-based bucket stores
 func newThanosBucketStores(cfg tsdb.BlocksStorageConfig, shardingStrategy ShardingStrategy, bucketClient objstore.InstrumentedBucket, limits *validation.Overrides, logLevel logging.Level, logger log.Logger, reg prometheus.Registerer) (*ThanosBucketStores, error) {
 	matchers := tsdb.NewMatchers()
-	cachingBucket, err := tsdb.CreateCachingBucket(cfg.BucketStore.ChunksCache, cfg.BucketStore.MetadataCache, tsdb.ParquetLabelsCacheConfig{}, matchers, bucketClient, logger, reg)
+	cachingBucket, err := tsdb.CreateCachingBucket("store-gateway", cfg.BucketStore.ChunksCache, cfg.BucketStore.MetadataCache, tsdb.ParquetLabelsCacheConfig{}, matchers, bucketClient, logger, reg)
 	if err != nil {
 		return nil, errors.Wrapf(err, "create caching bucket")
 	}
@@ -181,6 +188,10 @@ func newThanosBucketStores(cfg tsdb.BlocksStorageConfig, shardingStrategy Shardi
 		}
 	}
 
+	if u.exemplarsReader, err = newExemplarsReader(cfg.BucketStore.ExemplarsCacheMaxItems, limits); err != nil {
+		return nil, errors.Wrap(err, "create exemplars reader")
+	}
+
 	// Init the index cache.
 	if u.indexCache, err = tsdb.NewIndexCache(cfg.BucketStore.IndexCache, logger, reg); err != nil {
 		return nil, errors.Wrap(err, "create index cache")
@@ -449,6 +460,40 @@ func (u *ThanosBucketStores) LabelValues(ctx context.Context, req *storepb.Label
 
 // scanUsers in the bucket and return the list of found users. It includes active and deleting users
 // but not deleted users.
+// Exemplars implements BucketStores.
+func (u *ThanosBucketStores) Exemplars(ctx context.Context, req *storegatewaypb.ExemplarsRequest) (*storegatewaypb.ExemplarsResponse, error) {
+	spanLog, spanCtx := spanlogger.New(ctx, "BucketStores.Exemplars")
+	defer spanLog.Finish()
+
+	userID := getUserIDFromGRPCContext(spanCtx)
+	if userID == "" {
+		return nil, fmt.Errorf("no userID")
+	}
+
+	err := u.getStoreError(userID)
+	userBkt := bucket.NewUserBucketClient(userID, u.bucket, u.limits)
+	if err != nil {
+		if cortex_errors.ErrorIs(err, userBkt.IsAccessDeniedErr) {
+			return nil, httpgrpc.Errorf(int(codes.PermissionDenied), "store error: %s", err)
+		}
+
+		return nil, err
+	}
+
+	store := u.getStore(userID)
+	if store == nil {
+		return &storegatewaypb.ExemplarsResponse{}, nil
+	}
+
+	// Only the blocks loaded by this store-gateway are queried.
+	blockIDs, err := loadedExemplarsRequestBlocks(spanCtx, store, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.exemplarsReader.read(spanCtx, spanLog, userBkt, userID, blockIDs, req, u.cfg.BucketStore.BlockSyncConcurrency)
+}
+
 func (u *ThanosBucketStores) scanUsers(ctx context.Context) ([]string, error) {
 	activeUsers, deletingUsers, _, err := u.userScanner.ScanUsers(ctx)
 	if err != nil {
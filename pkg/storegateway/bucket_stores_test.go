@@ -23,6 +23,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/prometheus/common/promslog"
+	"github.com/prometheus/prometheus/model/exemplar"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/tsdb"
 	"github.com/prometheus/prometheus/util/annotations"
@@ -42,10 +43,12 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 
+	"github.com/cortexproject/cortex/pkg/cortexpb"
 	"github.com/cortexproject/cortex/pkg/storage/bucket"
 	"github.com/cortexproject/cortex/pkg/storage/bucket/filesystem"
 	cortex_tsdb "github.com/cortexproject/cortex/pkg/storage/tsdb"
 	"github.com/cortexproject/cortex/pkg/storage/tsdb/bucketindex"
+	"github.com/cortexproject/cortex/pkg/storegateway/storegatewaypb"
 	"github.com/cortexproject/cortex/pkg/util/flagext"
 	cortex_testutil "github.com/cortexproject/cortex/pkg/util/testutil"
 	"github.com/cortexproject/cortex/pkg/util/users"
@@ -690,6 +693,81 @@ func TestBucketStores_SyncBlocksWithIgnoreBlocksBefore(t *testing.T) {
 	`), "cortex_bucket_store_block_loads_total", "cortex_bucket_store_blocks_loaded", "cortex_blocks_meta_synced"))
 }
 
+func TestBucketStores_Exemplars(t *testing.T) {
+	t.Parallel()
+
+	const userID = "user-1"
+
+	ctx := context.Background()
+	cfg := prepareStorageConfig(t)
+	storageDir := t.TempDir()
+
+	// Generate 2 blocks, each one with the exemplars of its series.
+	var blockIDs []string
+	for _, b := range []struct {
+		metricName string
+		minT, maxT int64
+	}{{"series_1", 10, 100}, {"series_2", 100, 200}} {
+		generateStorageBlock(t, storageDir, userID, b.metricName, b.minT, b.maxT, 15)
+
+		entries, err := os.ReadDir(filepath.Join(storageDir, userID))
+		require.NoError(t, err)
+		for _, e := range entries {
+			if slices.Contains(blockIDs, e.Name()) {
+				continue
+			}
+			blockIDs = append(blockIDs, e.Name())
+			require.NoError(t, cortex_tsdb.WriteBlockExemplars(filepath.Join(storageDir, userID, e.Name()), []exemplar.QueryResult{{
+				SeriesLabels: labels.FromStrings(labels.MetricName, b.metricName),
+				Exemplars: []exemplar.Exemplar{
+					{Labels: labels.FromStrings("trace_id", b.metricName), Value: 1, Ts: b.minT, HasTs: true},
+				},
+			}}))
+		}
+	}
+	require.Len(t, blockIDs, 2)
+
+	bucket, err := filesystem.NewBucketClient(filesystem.Config{Directory: storageDir})
+	require.NoError(t, err)
+
+	stores, err := NewBucketStores(cfg, NewNoShardingStrategy(log.NewNopLogger(), nil), objstore.WithNoopInstr(bucket), defaultLimitsOverrides(t), mockLoggingLevel(), log.NewNopLogger(), prometheus.NewPedanticRegistry())
+	require.NoError(t, err)
+	require.NoError(t, stores.InitialSync(ctx))
+
+	// Blocks which aren't loaded by the store-gateway are not queried.
+	unknownBlockID := ulid.MustNew(1, nil).String()
+	req := &storegatewaypb.ExemplarsRequest{
+		MinTime: 0,
+		MaxTime: 200,
+		Matchers: []storegatewaypb.ExemplarsMatchers{
+			{Matchers: []storepb.LabelMatcher{{Type: storepb.LabelMatcher_EQ, Name: labels.MetricName, Value: "series_2"}}},
+		},
+		BlockIds: append([]string{unknownBlockID}, blockIDs...),
+	}
+
+	resp, err := stores.Exemplars(setUserIDToGRPCContext(ctx, userID), req)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, blockIDs, resp.QueriedBlocks)
+	assert.Equal(t, []cortexpb.TimeSeries{{
+		Labels:    cortexpb.FromLabelsToLabelAdapters(labels.FromStrings(labels.MetricName, "series_2")),
+		Exemplars: []cortexpb.Exemplar{{Labels: cortexpb.FromLabelsToLabelAdapters(labels.FromStrings("trace_id", "series_2")), Value: 1, TimestampMs: 100}},
+	}}, resp.Timeseries)
+
+	// The blocks are filtered by time range.
+	req.MinTime, req.MaxTime = 0, 50
+	req.Matchers = append(req.Matchers, storegatewaypb.ExemplarsMatchers{
+		Matchers: []storepb.LabelMatcher{{Type: storepb.LabelMatcher_RE, Name: labels.MetricName, Value: "series_.+"}},
+	})
+
+	resp, err = stores.Exemplars(setUserIDToGRPCContext(ctx, userID), req)
+	require.NoError(t, err)
+	assert.Equal(t, []string{blockIDs[0]}, resp.QueriedBlocks)
+	assert.Equal(t, []cortexpb.TimeSeries{{
+		Labels:    cortexpb.FromLabelsToLabelAdapters(labels.FromStrings(labels.MetricName, "series_1")),
+		Exemplars: []cortexpb.Exemplar{{Labels: cortexpb.FromLabelsToLabelAdapters(labels.FromStrings("trace_id", "series_1")), Value: 1, TimestampMs: 10}},
+	}}, resp.Timeseries)
+}
+
 func prepareStorageConfig(t testing.TB) cortex_tsdb.BlocksStorageConfig {
 	cfg := cortex_tsdb.BlocksStorageConfig{}
 	flagext.DefaultValues(&cfg)
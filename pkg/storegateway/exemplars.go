@@ -0,0 +1,261 @@
+package storegateway
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/go-kit/log"
+	"github.com/gogo/protobuf/types"
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/oklog/ulid/v2"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/thanos-io/objstore"
+	"github.com/thanos-io/thanos/pkg/block"
+	"github.com/thanos-io/thanos/pkg/store/hintspb"
+	"github.com/thanos-io/thanos/pkg/store/storepb"
+	"go.uber.org/atomic"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/cortexproject/cortex/pkg/cortexpb"
+	"github.com/cortexproject/cortex/pkg/storage/tsdb"
+	"github.com/cortexproject/cortex/pkg/storegateway/storegatewaypb"
+	"github.com/cortexproject/cortex/pkg/util/concurrency"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+// parseExemplarsRequestBlockIDs returns the IDs of the blocks requested by the exemplars request.
+func parseExemplarsRequestBlockIDs(req *storegatewaypb.ExemplarsRequest) ([]ulid.ULID, error) {
+	blockIDs := make([]ulid.ULID, 0, len(req.BlockIds))
+	for _, id := range req.BlockIds {
+		blockID, err := ulid.Parse(id)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, errors.Wrapf(err, "parse block ID %s", id).Error())
+		}
+		blockIDs = append(blockIDs, blockID)
+	}
+	return blockIDs, nil
+}
+
+// loadedExemplarsRequestBlocks returns the IDs of the blocks requested by the exemplars request which
+// are loaded by the store. They're looked up through the label names hints, which are cheap to get
+// when there are no matchers.
+func loadedExemplarsRequestBlocks(ctx context.Context, store storepb.StoreServer, req *storegatewaypb.ExemplarsRequest) ([]ulid.ULID, error) {
+	if len(req.BlockIds) == 0 {
+		return nil, nil
+	}
+
+	hints, err := types.MarshalAny(&hintspb.LabelNamesRequestHints{
+		BlockMatchers: []storepb.LabelMatcher{{
+			Type:  storepb.LabelMatcher_RE,
+			Name:  block.BlockIDLabel,
+			Value: strings.Join(req.BlockIds, "|"),
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := store.LabelNames(ctx, &storepb.LabelNamesRequest{Start: req.MinTime, End: req.MaxTime, Limit: 1, Hints: hints})
+	if err != nil {
+		return nil, err
+	}
+	if resp.Hints == nil {
+		return nil, nil
+	}
+
+	respHints := hintspb.LabelNamesResponseHints{}
+	if err := types.UnmarshalAny(resp.Hints, &respHints); err != nil {
+		return nil, err
+	}
+
+	blockIDs := make([]ulid.ULID, 0, len(respHints.QueriedBlocks))
+	for _, b := range respHints.QueriedBlocks {
+		blockID, err := ulid.Parse(b.Id)
+		if err != nil {
+			return nil, err
+		}
+		blockIDs = append(blockIDs, blockID)
+	}
+	return blockIDs, nil
+}
+
+// blockExemplars are the exemplars decoded from the exemplars file of a block.
+type blockExemplars struct {
+	series []exemplar.QueryResult
+
+	// Size of the exemplars file.
+	size int64
+}
+
+// exemplarsReader reads the exemplars persisted alongside the blocks. The exemplars decoded from the
+// files, which are immutable, are cached in memory for the most recently queried blocks.
+type exemplarsReader struct {
+	limits *validation.Overrides
+
+	// Nil if the cache is disabled.
+	cache *lru.Cache[string, blockExemplars]
+}
+
+func newExemplarsReader(maxItems int, limits *validation.Overrides) (*exemplarsReader, error) {
+	r := &exemplarsReader{limits: limits}
+	if maxItems > 0 {
+		cache, err := lru.New[string, blockExemplars](maxItems)
+		if err != nil {
+			return nil, err
+		}
+		r.cache = cache
+	}
+	return r, nil
+}
+
+// read returns the exemplars of the given blocks matching the request. The request fails once the
+// size of the read exemplars files exceeds the downloaded bytes limit, or the number of matching
+// series exceeds the fetched series limit.
+func (r *exemplarsReader) read(ctx context.Context, logger log.Logger, bkt objstore.BucketReader, userID string, blockIDs []ulid.ULID, req *storegatewaypb.ExemplarsRequest, concurrencyLimit int) (*storegatewaypb.ExemplarsResponse, error) {
+	matchersSets := make([][]*labels.Matcher, 0, len(req.Matchers))
+	for _, m := range req.Matchers {
+		matchers, err := storepb.MatchersToPromMatchers(m.Matchers...)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, errors.Wrap(err, "translate request labels matchers").Error())
+		}
+		matchersSets = append(matchersSets, matchers)
+	}
+
+	keep := func(lbls labels.Labels) bool {
+		for _, matchers := range matchersSets {
+			if matchesAll(lbls, matchers) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var (
+		maxBytes  = int64(r.limits.MaxDownloadedBytesPerRequest(userID))
+		maxSeries = r.limits.MaxFetchedSeriesPerQuery(userID)
+		bytes     = atomic.NewInt64(0)
+		mtx       sync.Mutex
+		sets      [][]exemplar.QueryResult
+		jobs      = make([]any, 0, len(blockIDs))
+	)
+	for _, blockID := range blockIDs {
+		jobs = append(jobs, blockID)
+	}
+
+	err := concurrency.ForEach(ctx, jobs, concurrencyLimit, func(ctx context.Context, job any) error {
+		blockID := job.(ulid.ULID)
+		cacheKey := userID + "/" + blockID.String()
+
+		e, ok := r.getCached(cacheKey)
+		if ok {
+			if maxBytes > 0 && bytes.Add(e.size) > maxBytes {
+				return exemplarsBytesLimitError(maxBytes)
+			}
+		} else {
+			blockBkt := &bytesLimitedBucketReader{BucketReader: bkt, bytes: bytes, maxBytes: maxBytes}
+			series, err := tsdb.ReadBlockExemplars(ctx, logger, blockBkt, blockID)
+			// The limit error is replaced by a corrupted file error when decoding the file.
+			if maxBytes > 0 && bytes.Load() > maxBytes {
+				return exemplarsBytesLimitError(maxBytes)
+			}
+			if err != nil {
+				return err
+			}
+			e = blockExemplars{series: series, size: blockBkt.read.Load()}
+			r.setCached(cacheKey, e)
+		}
+
+		filtered := tsdb.FilterExemplars(e.series, req.MinTime, req.MaxTime, keep)
+		mtx.Lock()
+		sets = append(sets, filtered)
+		mtx.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	merged := tsdb.MergeExemplars(sets...)
+	if maxSeries > 0 && len(merged) > maxSeries {
+		return nil, status.Error(codes.ResourceExhausted, fmt.Sprintf("exceeded series limit while fetching exemplars: limit %d violated (got %d)", maxSeries, len(merged)))
+	}
+
+	resp := &storegatewaypb.ExemplarsResponse{}
+	for _, s := range merged {
+		resp.Timeseries = append(resp.Timeseries, cortexpb.TimeSeries{
+			Labels:    cortexpb.FromLabelsToLabelAdapters(s.SeriesLabels),
+			Exemplars: cortexpb.FromExemplarsToExemplarProtos(s.Exemplars),
+		})
+	}
+	for _, blockID := range blockIDs {
+		resp.QueriedBlocks = append(resp.QueriedBlocks, blockID.String())
+	}
+	return resp, nil
+}
+
+func (r *exemplarsReader) getCached(key string) (blockExemplars, bool) {
+	if r.cache == nil {
+		return blockExemplars{}, false
+	}
+	return r.cache.Get(key)
+}
+
+func (r *exemplarsReader) setCached(key string, e blockExemplars) {
+	if r.cache != nil {
+		r.cache.Add(key, e)
+	}
+}
+
+func exemplarsBytesLimitError(maxBytes int64) error {
+	return status.Error(codes.ResourceExhausted, fmt.Sprintf("exceeded bytes limit while fetching exemplars: limit %d violated", maxBytes))
+}
+
+// bytesLimitedBucketReader counts the bytes read from the objects, and fails the reads once the
+// bytes shared with the other readers of the request exceed the limit.
+type bytesLimitedBucketReader struct {
+	objstore.BucketReader
+
+	bytes    *atomic.Int64
+	maxBytes int64
+
+	// Bytes read through this reader.
+	read atomic.Int64
+}
+
+func (b *bytesLimitedBucketReader) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	rc, err := b.BucketReader.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return &bytesLimitedReader{ReadCloser: rc, bkt: b}, nil
+}
+
+type bytesLimitedReader struct {
+	io.ReadCloser
+
+	bkt *bytesLimitedBucketReader
+}
+
+func (r *bytesLimitedReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.bkt.read.Add(int64(n))
+	if total := r.bkt.bytes.Add(int64(n)); r.bkt.maxBytes > 0 && total > r.bkt.maxBytes {
+		return n, exemplarsBytesLimitError(r.bkt.maxBytes)
+	}
+	return n, err
+}
+
+func matchesAll(lbls labels.Labels, matchers []*labels.Matcher) bool {
+	for _, m := range matchers {
+		if !m.Matches(lbls.Get(m.Name)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,139 @@
+package storegateway
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/oklog/ulid/v2"
+	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+	"github.com/thanos-io/thanos/pkg/store/storepb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	cortex_tsdb "github.com/cortexproject/cortex/pkg/storage/tsdb"
+	"github.com/cortexproject/cortex/pkg/storegateway/storegatewaypb"
+	"github.com/cortexproject/cortex/pkg/util/validation"
+)
+
+func TestExemplarsReader(t *testing.T) {
+	t.Parallel()
+
+	const userID = "user-1"
+	ctx := context.Background()
+
+	// Upload 2 blocks exemplars files, with 2 series each.
+	bkt := objstore.NewInMemBucket()
+	var blockIDs []ulid.ULID
+	for i := range 2 {
+		blockID := ulid.MustNew(uint64(i+1), nil)
+		blockDir := filepath.Join(t.TempDir(), blockID.String())
+		require.NoError(t, os.Mkdir(blockDir, os.ModePerm))
+		var series []exemplar.QueryResult
+		for j := range 2 {
+			name := fmt.Sprintf("series_%d_%d", i, j)
+			series = append(series, exemplar.QueryResult{
+				SeriesLabels: labels.FromStrings(labels.MetricName, name),
+				Exemplars:    []exemplar.Exemplar{{Labels: labels.FromStrings("trace_id", name), Value: 1, Ts: 10, HasTs: true}},
+			})
+		}
+		require.NoError(t, cortex_tsdb.WriteBlockExemplars(blockDir, series))
+		_, err := cortex_tsdb.UploadBlockExemplars(ctx, log.NewNopLogger(), bkt, blockDir)
+		require.NoError(t, err)
+		blockIDs = append(blockIDs, blockID)
+	}
+
+	fileSize := func(blockID ulid.ULID) int {
+		attrs, err := bkt.Attributes(ctx, blockID.String()+"/"+cortex_tsdb.BlockExemplarsFilename)
+		require.NoError(t, err)
+		return int(attrs.Size)
+	}
+	totalSize := fileSize(blockIDs[0]) + fileSize(blockIDs[1])
+
+	req := &storegatewaypb.ExemplarsRequest{
+		MinTime: 0,
+		MaxTime: 100,
+		Matchers: []storegatewaypb.ExemplarsMatchers{
+			{Matchers: []storepb.LabelMatcher{{Type: storepb.LabelMatcher_RE, Name: labels.MetricName, Value: "series_.+"}}},
+		},
+	}
+
+	newReader := func(t *testing.T, cacheMaxItems int, setLimits func(*validation.Limits)) *exemplarsReader {
+		limits := defaultLimitsConfig()
+		if setLimits != nil {
+			setLimits(&limits)
+		}
+		r, err := newExemplarsReader(cacheMaxItems, validation.NewOverrides(limits, nil))
+		require.NoError(t, err)
+		return r
+	}
+
+	t.Run("the decoded exemplars are cached", func(t *testing.T) {
+		t.Parallel()
+
+		cachedBkt := objstore.NewInMemBucket()
+		for _, blockID := range blockIDs {
+			name := blockID.String() + "/" + cortex_tsdb.BlockExemplarsFilename
+			rc, err := bkt.Get(ctx, name)
+			require.NoError(t, err)
+			require.NoError(t, cachedBkt.Upload(ctx, name, rc))
+		}
+
+		r := newReader(t, 10, nil)
+		resp, err := r.read(ctx, log.NewNopLogger(), cachedBkt, userID, blockIDs, req, 1)
+		require.NoError(t, err)
+		require.Len(t, resp.Timeseries, 4)
+
+		// The files aren't read again.
+		for _, blockID := range blockIDs {
+			require.NoError(t, cachedBkt.Delete(ctx, blockID.String()+"/"+cortex_tsdb.BlockExemplarsFilename))
+		}
+		resp, err = r.read(ctx, log.NewNopLogger(), cachedBkt, userID, blockIDs, req, 1)
+		require.NoError(t, err)
+		require.Len(t, resp.Timeseries, 4)
+		assert.Len(t, resp.QueriedBlocks, 2)
+	})
+
+	t.Run("the request fails once the downloaded bytes limit is exceeded", func(t *testing.T) {
+		t.Parallel()
+
+		for _, cacheMaxItems := range []int{0, 10} {
+			r := newReader(t, cacheMaxItems, func(l *validation.Limits) { l.MaxDownloadedBytesPerRequest = totalSize })
+			_, err := r.read(ctx, log.NewNopLogger(), bkt, userID, blockIDs, req, 1)
+			require.NoError(t, err)
+
+			r = newReader(t, cacheMaxItems, func(l *validation.Limits) { l.MaxDownloadedBytesPerRequest = totalSize - 1 })
+			_, err = r.read(ctx, log.NewNopLogger(), bkt, userID, blockIDs, req, 1)
+			require.Error(t, err)
+			assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+			// The size of the cached blocks is also accounted.
+			if cacheMaxItems > 0 {
+				_, err = r.read(ctx, log.NewNopLogger(), bkt, userID, blockIDs[:1], req, 1)
+				require.NoError(t, err)
+				_, err = r.read(ctx, log.NewNopLogger(), bkt, userID, blockIDs, req, 1)
+				require.Error(t, err)
+				assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+			}
+		}
+	})
+
+	t.Run("the request fails once the fetched series limit is exceeded", func(t *testing.T) {
+		t.Parallel()
+
+		r := newReader(t, 0, func(l *validation.Limits) { l.MaxFetchedSeriesPerQuery = 3 })
+		_, err := r.read(ctx, log.NewNopLogger(), bkt, userID, blockIDs[:1], req, 1)
+		require.NoError(t, err)
+
+		_, err = r.read(ctx, log.NewNopLogger(), bkt, userID, blockIDs, req, 1)
+		require.Error(t, err)
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	})
+}
@@ -97,6 +97,10 @@ func (cfg *Config) Validate(limits validation.Limits, monitoredResources flagext
 		if cfg.ShardingStrategy == util.ShardingStrategyShuffle && limits.StoreGatewayTenantShardSize <= 0 {
 			return errInvalidTenantShardSize
 		}
+
+		if err := cfg.ShardingRing.TokensGenerator.Validate(); err != nil {
+			return err
+		}
 	}
 
 	if err := cfg.HedgedRequest.Validate(); err != nil {
@@ -431,6 +435,14 @@ func (g *StoreGateway) LabelValues(ctx context.Context, req *storepb.LabelValues
 	return g.stores.LabelValues(ctx, req)
 }
 
+// Exemplars implements the Storegateway proto service.
+func (g *StoreGateway) Exemplars(ctx context.Context, req *storegatewaypb.ExemplarsRequest) (*storegatewaypb.ExemplarsResponse, error) {
+	if err := g.checkResourceUtilization(); err != nil {
+		return nil, err
+	}
+	return g.stores.Exemplars(ctx, req)
+}
+
 func (g *StoreGateway) checkResourceUtilization() error {
 	if g.resourceBasedLimiter == nil {
 		return nil
@@ -51,3 +51,18 @@ func (c *StoreGateway) RingHandler(w http.ResponseWriter, req *http.Request) {
 
 	c.ring.ServeHTTP(w, req)
 }
+
+// RingAdminHandler serves the JSON ring admin API of the store-gateway ring.
+func (c *StoreGateway) RingAdminHandler(w http.ResponseWriter, req *http.Request) {
+	if !c.gatewayCfg.ShardingEnabled {
+		http.Error(w, "Store gateway has no ring because sharding is disabled.", http.StatusNotFound)
+		return
+	}
+
+	if c.State() != services.Running {
+		http.Error(w, "Store gateway is not running yet.", http.StatusServiceUnavailable)
+		return
+	}
+
+	c.ring.AdminHandler(w, req)
+}
@@ -70,6 +70,8 @@ type RingConfig struct {
 	ZoneStableShuffleSharding       bool          `yaml:"zone_stable_shuffle_sharding" doc:"hidden"`
 	DetailedMetricsEnabled          bool          `yaml:"detailed_metrics_enabled"`
 
+	TokensGenerator ring.TokensGeneratorConfig `yaml:",inline"`
+
 	// Wait ring stability.
 	WaitStabilityMinDuration time.Duration `yaml:"wait_stability_min_duration"`
 	WaitStabilityMaxDuration time.Duration `yaml:"wait_stability_max_duration"`
@@ -108,6 +110,7 @@ func (cfg *RingConfig) RegisterFlags(f *flag.FlagSet) {
 	f.BoolVar(&cfg.ZoneAwarenessEnabled, ringFlagsPrefix+"zone-awareness-enabled", false, "True to enable zone-awareness and replicate blocks across different availability zones.")
 	f.BoolVar(&cfg.KeepInstanceInTheRingOnShutdown, ringFlagsPrefix+"keep-instance-in-the-ring-on-shutdown", false, "True to keep the store gateway instance in the ring when it shuts down. The instance will then be auto-forgotten from the ring after 10*heartbeat_timeout.")
 	f.BoolVar(&cfg.ZoneStableShuffleSharding, ringFlagsPrefix+"zone-stable-shuffle-sharding", true, "If true, use zone stable shuffle sharding algorithm. Otherwise, use the default shuffle sharding algorithm.")
+	cfg.TokensGenerator.RegisterFlagsWithPrefix(ringFlagsPrefix, f)
 	f.BoolVar(&cfg.DetailedMetricsEnabled, ringFlagsPrefix+"detailed-metrics-enabled", true, "Set to true to enable ring detailed metrics. These metrics provide detailed information, such as token count and ownership per tenant. Disabling them can significantly decrease the number of metrics emitted.")
 
 	// Wait stability flags.
@@ -160,6 +163,9 @@ func (cfg *RingConfig) ToLifecyclerConfig(logger log.Logger) (ring.BasicLifecycl
 		HeartbeatPeriod:                 cfg.HeartbeatPeriod,
 		TokensObservePeriod:             0,
 		NumTokens:                       RingNumTokens,
+		TokensGeneratorStrategy:         cfg.TokensGenerator.Strategy,
+		TokensMigrationInterval:         cfg.TokensGenerator.MigrationInterval,
+		TokensMigrationStep:             cfg.TokensGenerator.MigrationStep,
 		KeepInstanceInTheRingOnShutdown: cfg.KeepInstanceInTheRingOnShutdown,
 		FinalSleep:                      cfg.FinalSleep,
 	}, nil
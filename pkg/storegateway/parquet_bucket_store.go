@@ -27,6 +27,7 @@ import (
 
 	"github.com/cortexproject/cortex/pkg/util/parquetutil"
 	"github.com/cortexproject/cortex/pkg/util/spanlogger"
+	"github.com/cortexproject/cortex/pkg/util/users"
 	"github.com/cortexproject/cortex/pkg/util/validation"
 )
 
@@ -60,13 +61,23 @@ func (p *parquetBucketStore) findParquetBlocks(ctx context.Context, blockMatcher
 		return nil, status.Error(codes.InvalidArgument, "only one block matcher is supported")
 	}
 
+	userID, err := users.TenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The quotas are shared by all the blocks queried by the request, so that the
+	// limits apply to the request as a whole, like in the parquet queryable.
+	rowCountQuota := search.NewQuota(int64(p.limits.ParquetMaxFetchedRowCount(userID)))
+	chunkBytesQuota := search.NewQuota(int64(p.limits.ParquetMaxFetchedChunkBytes(userID)))
+	dataBytesQuota := search.NewQuota(int64(p.limits.ParquetMaxFetchedDataBytes(userID)))
+
 	blockIDs := strings.Split(blockMatchers[0].Value, "|")
 	blocks := make([]*parquetBlock, 0, len(blockIDs))
 	bucketOpener := parquet_storage.NewParquetBucketOpener(p.bucket)
-	noopQuota := search.NewQuota(search.NoopQuotaLimitFunc(ctx))
 	for _, blockID := range blockIDs {
 		// TODO: support shard ID > 0 later.
-		block, err := p.newParquetBlock(ctx, blockID, 0, bucketOpener, bucketOpener, p.chunksDecoder, noopQuota, noopQuota, noopQuota)
+		block, err := p.newParquetBlock(ctx, blockID, 0, bucketOpener, bucketOpener, p.chunksDecoder, rowCountQuota, chunkBytesQuota, dataBytesQuota)
 		if err != nil {
 			return nil, err
 		}
@@ -123,7 +134,7 @@ func (p *parquetBucketStore) Series(req *storepb.SeriesRequest, seriesSrv storep
 	}
 
 	if err = errGroup.Wait(); err != nil {
-		return err
+		return translateParquetError(err)
 	}
 
 	ss := convert.NewMergeChunkSeriesSet(seriesSet, labels.Compare, prom_storage.NewConcatenatingChunkSeriesMerger())
@@ -208,7 +219,7 @@ func (p *parquetBucketStore) LabelNames(ctx context.Context, req *storepb.LabelN
 	}
 
 	if err := errGroup.Wait(); err != nil {
-		return nil, err
+		return nil, translateParquetError(err)
 	}
 
 	anyHints, err := types.MarshalAny(resHints)
@@ -265,7 +276,7 @@ func (p *parquetBucketStore) LabelValues(ctx context.Context, req *storepb.Label
 	}
 
 	if err := errGroup.Wait(); err != nil {
-		return nil, err
+		return nil, translateParquetError(err)
 	}
 
 	anyHints, err := types.MarshalAny(resHints)
@@ -279,3 +290,12 @@ func (p *parquetBucketStore) LabelValues(ctx context.Context, req *storepb.Label
 		Hints:  anyHints,
 	}, nil
 }
+
+// translateParquetError converts the errors returned when a parquet_max_fetched_* limit
+// is hit into a ResourceExhausted gRPC error, which is handled as a limit error by the querier.
+func translateParquetError(err error) error {
+	if search.IsResourceExhausted(err) {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return err
+}
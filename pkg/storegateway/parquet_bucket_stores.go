@@ -30,6 +30,7 @@ import (
 	"github.com/cortexproject/cortex/pkg/querysharding"
 	"github.com/cortexproject/cortex/pkg/storage/bucket"
 	"github.com/cortexproject/cortex/pkg/storage/tsdb"
+	"github.com/cortexproject/cortex/pkg/storegateway/storegatewaypb"
 	cortex_util "github.com/cortexproject/cortex/pkg/util"
 	cortex_errors "github.com/cortexproject/cortex/pkg/util/errors"
 	"github.com/cortexproject/cortex/pkg/util/parquetutil"
@@ -55,6 +56,7 @@ type ParquetBucketStores struct {
 	chunksDecoder *schema.PrometheusParquetChunksDecoder
 
 	matcherCache      storecache.MatchersCache
+	exemplarsReader   *exemplarsReader
 	parquetShardCache parquetutil.CacheInterface[parquet_storage.ParquetShard]
 
 	inflightRequests *cortex_util.InflightRequestTracker
@@ -96,6 +98,10 @@ func newParquetBucketStores(cfg tsdb.BlocksStorageConfig, bucketClient objstore.
 		u.matcherCache = storecache.NoopMatchersCache
 	}
 
+	if u.exemplarsReader, err = newExemplarsReader(cfg.BucketStore.ExemplarsCacheMaxItems, limits); err != nil {
+		return nil, errors.Wrap(err, "create exemplars reader")
+	}
+
 	return u, nil
 }
 
@@ -196,6 +202,35 @@ func (u *ParquetBucketStores) LabelValues(ctx context.Context, req *storepb.Labe
 	return store.LabelValues(ctx, req)
 }
 
+// Exemplars implements BucketStores
+func (u *ParquetBucketStores) Exemplars(ctx context.Context, req *storegatewaypb.ExemplarsRequest) (*storegatewaypb.ExemplarsResponse, error) {
+	spanLog, spanCtx := spanlogger.New(ctx, "ParquetBucketStores.Exemplars")
+	defer spanLog.Finish()
+
+	userID := getUserIDFromGRPCContext(spanCtx)
+	if userID == "" {
+		return nil, fmt.Errorf("no userID")
+	}
+
+	err := u.getStoreError(userID)
+	userBkt := bucket.NewUserBucketClient(userID, u.bucket, u.limits)
+	if err != nil {
+		if cortex_errors.ErrorIs(err, userBkt.IsAccessDeniedErr) {
+			return nil, httpgrpc.Errorf(int(codes.PermissionDenied), "store error: %s", err)
+		}
+
+		return nil, err
+	}
+
+	// Parquet blocks aren't loaded by the store-gateway, so all the requested blocks are queried.
+	blockIDs, err := parseExemplarsRequestBlockIDs(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return u.exemplarsReader.read(spanCtx, spanLog, userBkt, userID, blockIDs, req, u.cfg.BucketStore.BlockSyncConcurrency)
+}
+
 // SyncBlocks implements BucketStores
 func (u *ParquetBucketStores) SyncBlocks(ctx context.Context) error {
 	return nil
@@ -272,6 +307,10 @@ type parquetBlock struct {
 	shard       parquet_storage.ParquetShard
 	m           *search.Materializer
 	concurrency int
+
+	// rowCountQuota is also used by the materializer, and it's reserved for the rows
+	// read by the label names and values requests with matchers.
+	rowCountQuota *search.Quota
 }
 
 func (p *parquetBucketStore) newParquetBlock(ctx context.Context, name string, shardID int, labelsFileOpener, chunksFileOpener parquet_storage.ParquetOpener, d *schema.PrometheusParquetChunksDecoder, rowCountQuota *search.Quota, chunkBytesQuota *search.Quota, dataBytesQuota *search.Quota) (*parquetBlock, error) {
@@ -316,10 +355,11 @@ func (p *parquetBucketStore) newParquetBlock(ctx context.Context, name string, s
 	}
 
 	return &parquetBlock{
-		shard:       shard,
-		m:           m,
-		concurrency: p.concurrency,
-		name:        name,
+		shard:         shard,
+		m:             m,
+		concurrency:   p.concurrency,
+		name:          name,
+		rowCountQuota: rowCountQuota,
 	}, nil
 }
 
@@ -451,6 +491,9 @@ func (b *parquetBlock) LabelNames(ctx context.Context, limit int64, matchers []*
 			if err != nil {
 				return err
 			}
+			if err := parquetutil.ReserveRows(b.rowCountQuota, rr); err != nil {
+				return err
+			}
 			series, err := b.m.MaterializeLabelNames(ctx, rgi, rr)
 			if err != nil {
 				return err
@@ -492,6 +535,9 @@ func (b *parquetBlock) LabelValues(ctx context.Context, name string, limit int64
 			if err != nil {
 				return err
 			}
+			if err := parquetutil.ReserveRows(b.rowCountQuota, rr); err != nil {
+				return err
+			}
 			series, err := b.m.MaterializeLabelValues(ctx, name, rgi, rr)
 			if err != nil {
 				return err
@@ -555,7 +601,7 @@ func chunkToStoreEncoding(in chunkenc.Encoding) storepb.Chunk_Encoding {
 func createCachingBucketClientForParquet(storageCfg tsdb.BlocksStorageConfig, bucketClient objstore.InstrumentedBucket, name string, logger log.Logger, reg prometheus.Registerer) (objstore.Bucket, error) {
 	// Create caching bucket using the existing infrastructure
 	matchers := tsdb.NewMatchers()
-	cachingBucket, err := tsdb.CreateCachingBucket(storageCfg.BucketStore.ChunksCache, storageCfg.BucketStore.MetadataCache, storageCfg.BucketStore.ParquetLabelsCache, matchers, bucketClient, logger, reg)
+	cachingBucket, err := tsdb.CreateCachingBucket(name, storageCfg.BucketStore.ChunksCache, storageCfg.BucketStore.MetadataCache, storageCfg.BucketStore.ParquetLabelsCache, matchers, bucketClient, logger, reg)
 	if err != nil {
 		return nil, errors.Wrap(err, "create caching bucket for parquet")
 	}
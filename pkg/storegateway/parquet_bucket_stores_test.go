@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/go-kit/log"
+	"github.com/gogo/protobuf/types"
 	"github.com/oklog/ulid"
 	"github.com/prometheus-community/parquet-common/convert"
 	"github.com/prometheus/client_golang/prometheus"
@@ -20,7 +22,9 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/thanos-io/objstore"
 	"github.com/thanos-io/thanos/pkg/block"
+	"github.com/thanos-io/thanos/pkg/store/hintspb"
 	"github.com/thanos-io/thanos/pkg/store/storepb"
+	"github.com/weaveworks/common/user"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
@@ -373,3 +377,97 @@ func convertToParquetBlocksForTesting(userPath string, userBkt objstore.Instrume
 	}
 	return blockIDs, nil
 }
+
+func TestParquetBucketStores_ShouldEnforceParquetFetchedLimits(t *testing.T) {
+	const userID = "user_id"
+
+	storageDir := t.TempDir()
+	generateStorageBlock(t, storageDir, userID, "series_1", 0, 100, 15)
+	generateStorageBlock(t, storageDir, userID, "series_2", 0, 100, 15)
+	bkt, err := filesystem.NewBucketClient(filesystem.Config{Directory: storageDir})
+	require.NoError(t, err)
+
+	uBucket := bucket.NewUserBucketClient(userID, bkt, validation.NewOverrides(validation.Limits{}, nil))
+	blockIDs, err := convertToParquetBlocksForTesting(fmt.Sprintf("%s/%s", storageDir, userID), uBucket)
+	require.NoError(t, err)
+	require.Len(t, blockIDs, 2)
+
+	matchers := []storepb.LabelMatcher{{Type: storepb.LabelMatcher_RE, Name: labels.MetricName, Value: "series_.*"}}
+	blockMatchers := []storepb.LabelMatcher{{Type: storepb.LabelMatcher_RE, Name: block.BlockIDLabel, Value: strings.Join(blockIDs, "|")}}
+
+	seriesHints, err := types.MarshalAny(&hintspb.SeriesRequestHints{BlockMatchers: blockMatchers})
+	require.NoError(t, err)
+	labelNamesHints, err := types.MarshalAny(&hintspb.LabelNamesRequestHints{BlockMatchers: blockMatchers})
+	require.NoError(t, err)
+	labelValuesHints, err := types.MarshalAny(&hintspb.LabelValuesRequestHints{BlockMatchers: blockMatchers})
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		limits        func(l *validation.Limits)
+		expectedError string
+	}{
+		"no limits": {
+			limits: func(l *validation.Limits) {},
+		},
+		"row count limit hit across blocks": {
+			limits:        func(l *validation.Limits) { l.ParquetMaxFetchedRowCount = 1 },
+			expectedError: "would fetch too many rows",
+		},
+		"data bytes limit hit": {
+			limits:        func(l *validation.Limits) { l.ParquetMaxFetchedDataBytes = 1 },
+			expectedError: "would fetch too many data bytes",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := prepareStorageConfig(t)
+			cfg.BucketStore.BucketStoreType = string(cortex_tsdb.ParquetBucketStore)
+			limits := defaultLimitsConfig()
+			tc.limits(&limits)
+
+			stores, err := NewBucketStores(cfg, NewNoShardingStrategy(log.NewNopLogger(), nil), objstore.WithNoopInstr(bkt), validation.NewOverrides(limits, nil), mockLoggingLevel(), log.NewNopLogger(), prometheus.NewPedanticRegistry())
+			require.NoError(t, err)
+			require.NoError(t, stores.InitialSync(context.Background()))
+
+			ctx := setUserIDToGRPCContext(context.Background(), userID)
+			ctx = user.InjectOrgID(ctx, userID)
+			checkErr := func(t *testing.T, err error) {
+				if tc.expectedError == "" {
+					require.NoError(t, err)
+					return
+				}
+				require.Error(t, err)
+				s, ok := status.FromError(err)
+				require.True(t, ok)
+				assert.Equal(t, codes.ResourceExhausted, s.Code())
+				assert.Contains(t, s.Message(), tc.expectedError)
+			}
+
+			t.Run("series", func(t *testing.T) {
+				srv := newBucketStoreSeriesServer(ctx)
+				err := stores.Series(&storepb.SeriesRequest{MinTime: 0, MaxTime: 100, Matchers: matchers, Hints: seriesHints, SkipChunks: true}, srv)
+				checkErr(t, err)
+				if err == nil {
+					assert.Len(t, srv.SeriesSet, 2)
+				}
+			})
+
+			t.Run("label names", func(t *testing.T) {
+				resp, err := stores.LabelNames(ctx, &storepb.LabelNamesRequest{Start: 0, End: 100, Matchers: matchers, Hints: labelNamesHints})
+				checkErr(t, err)
+				if err == nil {
+					assert.Equal(t, []string{labels.MetricName}, resp.Names)
+				}
+			})
+
+			t.Run("label values", func(t *testing.T) {
+				resp, err := stores.LabelValues(ctx, &storepb.LabelValuesRequest{Start: 0, End: 100, Label: labels.MetricName, Matchers: matchers, Hints: labelValuesHints})
+				checkErr(t, err)
+				if err == nil {
+					assert.ElementsMatch(t, []string{"series_1", "series_2"}, resp.Values)
+				}
+			})
+		})
+	}
+}
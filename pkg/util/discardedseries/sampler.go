@@ -0,0 +1,167 @@
+package discardedseries
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"golang.org/x/time/rate"
+
+	"github.com/cortexproject/cortex/pkg/cortexpb"
+)
+
+// SamplerLimits are the per-tenant limits of the Sampler.
+type SamplerLimits interface {
+	DiscardedSeriesSamplingRate(userID string) float64
+	DiscardedSeriesSamplingBufferSize(userID string) int
+}
+
+// Sample is a discarded series sampled for debugging.
+type Sample struct {
+	Labels      labels.Labels `json:"labels"`
+	TimestampMs int64         `json:"timestampMs"`
+	Reason      string        `json:"reason"`
+	Error       string        `json:"error,omitempty"`
+	DiscardedAt time.Time     `json:"discardedAt"`
+}
+
+// Sampler keeps, for each tenant, the latest discarded series in a fixed size ring buffer.
+// The number of series sampled per second is limited, so that sampling stays cheap when
+// a tenant has most of its series discarded: the rate limiter is checked before taking any
+// lock, and each tenant has its own lock.
+type Sampler struct {
+	limits SamplerLimits
+
+	// Map of tenant ID to *userSamples.
+	users sync.Map
+}
+
+type userSamples struct {
+	// The rate limiter is safe for concurrent use, so it's not guarded by the mutex.
+	limiter *rate.Limiter
+
+	mtx     sync.Mutex
+	samples []Sample
+	next    int
+	full    bool
+}
+
+func NewSampler(limits SamplerLimits) *Sampler {
+	return &Sampler{limits: limits}
+}
+
+// Sample records the discarded series if the tenant's sampling rate allows it, and returns
+// whether it was recorded. The series labels are copied.
+func (s *Sampler) Sample(userID, reason string, series []cortexpb.LabelAdapter, timestampMs int64, err error, now time.Time) bool {
+	if s == nil {
+		return false
+	}
+
+	limit := s.limits.DiscardedSeriesSamplingRate(userID)
+	size := s.limits.DiscardedSeriesSamplingBufferSize(userID)
+	if limit <= 0 || size <= 0 {
+		return false
+	}
+
+	var u *userSamples
+	if v, ok := s.users.Load(userID); ok {
+		u = v.(*userSamples)
+		if u.limiter.Limit() != rate.Limit(limit) {
+			u.limiter.SetLimitAt(now, rate.Limit(limit))
+			u.limiter.SetBurstAt(now, burst(limit))
+		}
+	} else {
+		v, _ := s.users.LoadOrStore(userID, &userSamples{limiter: rate.NewLimiter(rate.Limit(limit), burst(limit))})
+		u = v.(*userSamples)
+	}
+
+	if !u.limiter.AllowN(now, 1) {
+		return false
+	}
+
+	sample := Sample{
+		Labels:      cortexpb.FromLabelAdaptersToLabelsWithCopy(series),
+		TimestampMs: timestampMs,
+		Reason:      reason,
+		DiscardedAt: now,
+	}
+	if err != nil {
+		sample.Error = err.Error()
+	}
+
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+
+	if len(u.samples) != size {
+		u.resize(size)
+	}
+	u.samples[u.next] = sample
+	u.next = (u.next + 1) % len(u.samples)
+	if u.next == 0 {
+		u.full = true
+	}
+	return true
+}
+
+// Samples returns the discarded series sampled for the tenant, the latest first.
+func (s *Sampler) Samples(userID string) []Sample {
+	res := []Sample{}
+	if s == nil {
+		return res
+	}
+
+	v, ok := s.users.Load(userID)
+	if !ok {
+		return res
+	}
+	u := v.(*userSamples)
+
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+	return append(res, u.latest()...)
+}
+
+// RemoveUser drops the discarded series sampled for the tenant.
+func (s *Sampler) RemoveUser(userID string) {
+	if s == nil {
+		return
+	}
+
+	s.users.Delete(userID)
+}
+
+// latest returns the samples, the latest first. Must be called with the lock held.
+func (u *userSamples) latest() []Sample {
+	n := u.next
+	if u.full {
+		n = len(u.samples)
+	}
+
+	res := make([]Sample, 0, n)
+	for i := 1; i <= n; i++ {
+		res = append(res, u.samples[(u.next-i+len(u.samples))%len(u.samples)])
+	}
+	return res
+}
+
+// resize changes the size of the ring buffer, keeping the latest samples. Must be called
+// with the lock held.
+func (u *userSamples) resize(size int) {
+	latest := u.latest()
+	if len(latest) > size {
+		latest = latest[:size]
+	}
+
+	u.samples = make([]Sample, size)
+	for i := range latest {
+		u.samples[len(latest)-1-i] = latest[i]
+	}
+	u.next = len(latest) % size
+	u.full = len(latest) == size
+}
+
+// burst allows sampling a second worth of discarded series at once, and at least one.
+func burst(limit float64) int {
+	return max(1, int(math.Ceil(limit)))
+}
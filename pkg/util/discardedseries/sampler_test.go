@@ -0,0 +1,123 @@
+package discardedseries
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexproject/cortex/pkg/cortexpb"
+)
+
+type mockSamplerLimits struct {
+	rate       float64
+	bufferSize int
+}
+
+func (m *mockSamplerLimits) DiscardedSeriesSamplingRate(string) float64 {
+	return m.rate
+}
+
+func (m *mockSamplerLimits) DiscardedSeriesSamplingBufferSize(string) int {
+	return m.bufferSize
+}
+
+func seriesNames(samples []Sample) []string {
+	names := make([]string, 0, len(samples))
+	for _, s := range samples {
+		names = append(names, s.Labels.Get(labels.MetricName))
+	}
+	return names
+}
+
+func TestSampler(t *testing.T) {
+	limits := &mockSamplerLimits{rate: 2, bufferSize: 3}
+	s := NewSampler(limits)
+	now := time.Unix(1000, 0)
+
+	series := func(name string) []cortexpb.LabelAdapter {
+		return cortexpb.FromLabelsToLabelAdapters(labels.FromStrings(labels.MetricName, name, "job", "test"))
+	}
+
+	// The burst allows sampling 2 series at once.
+	require.True(t, s.Sample("user", "sample-out-of-order", series("a"), 10, errors.New("out of order sample"), now))
+	require.True(t, s.Sample("user", "sample-too-old", series("b"), 20, nil, now))
+	require.False(t, s.Sample("user", "sample-too-old", series("c"), 30, nil, now))
+
+	assert.Equal(t, []Sample{
+		{Labels: labels.FromStrings(labels.MetricName, "b", "job", "test"), TimestampMs: 20, Reason: "sample-too-old", DiscardedAt: now},
+		{Labels: labels.FromStrings(labels.MetricName, "a", "job", "test"), TimestampMs: 10, Reason: "sample-out-of-order", Error: "out of order sample", DiscardedAt: now},
+	}, s.Samples("user"))
+	assert.Empty(t, s.Samples("other"))
+
+	// The oldest samples are evicted once the buffer is full.
+	now = now.Add(time.Second)
+	require.True(t, s.Sample("user", "rate_limited", series("c"), 30, nil, now))
+	require.True(t, s.Sample("user", "rate_limited", series("d"), 40, nil, now))
+	assert.Equal(t, []string{"d", "c", "b"}, seriesNames(s.Samples("user")))
+
+	// The latest samples are kept when the buffer shrinks.
+	limits.bufferSize = 2
+	now = now.Add(time.Second)
+	require.True(t, s.Sample("user", "rate_limited", series("e"), 50, nil, now))
+	assert.Equal(t, []string{"e", "d"}, seriesNames(s.Samples("user")))
+
+	limits.bufferSize = 4
+	require.True(t, s.Sample("user", "rate_limited", series("f"), 60, nil, now))
+	assert.Equal(t, []string{"f", "e", "d"}, seriesNames(s.Samples("user")))
+
+	// Nothing is sampled once disabled.
+	limits.rate = 0
+	now = now.Add(time.Second)
+	require.False(t, s.Sample("user", "rate_limited", series("g"), 70, nil, now))
+	assert.Equal(t, []string{"f", "e", "d"}, seriesNames(s.Samples("user")))
+
+	s.RemoveUser("user")
+	assert.Empty(t, s.Samples("user"))
+}
+
+func TestSampler_ShouldCopyLabels(t *testing.T) {
+	s := NewSampler(&mockSamplerLimits{rate: 1, bufferSize: 1})
+
+	series := cortexpb.FromLabelsToLabelAdapters(labels.FromStrings(labels.MetricName, "a"))
+	require.True(t, s.Sample("user", "sample-too-old", series, 10, nil, time.Now()))
+	series[0].Value = "b"
+
+	assert.Equal(t, labels.FromStrings(labels.MetricName, "a"), s.Samples("user")[0].Labels)
+}
+
+func TestSampler_Concurrency(t *testing.T) {
+	s := NewSampler(&mockSamplerLimits{rate: 10, bufferSize: 100})
+	now := time.Unix(1000, 0)
+	series := cortexpb.FromLabelsToLabelAdapters(labels.FromStrings(labels.MetricName, "a"))
+
+	wg := sync.WaitGroup{}
+	for _, userID := range []string{"user-1", "user-2"} {
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for range 100 {
+					s.Sample(userID, "sample-too-old", series, 10, nil, now)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	// Each tenant has its own rate limiter, which allows a burst of a second worth of samples.
+	assert.Len(t, s.Samples("user-1"), 10)
+	assert.Len(t, s.Samples("user-2"), 10)
+}
+
+func TestSampler_Nil(t *testing.T) {
+	var s *Sampler
+
+	assert.False(t, s.Sample("user", "sample-too-old", nil, 10, nil, time.Now()))
+	assert.Empty(t, s.Samples("user"))
+	s.RemoveUser("user")
+}
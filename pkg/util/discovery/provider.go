@@ -0,0 +1,366 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/discovery/targetgroup"
+	"github.com/thanos-io/thanos/pkg/discovery/dns"
+	"gopkg.in/yaml.v2"
+
+	"github.com/cortexproject/cortex/pkg/util/multierror"
+)
+
+const (
+	// FilePrefix is the prefix of the addresses resolved by reading the targets of a file in the
+	// Prometheus file_sd format, for example file+/etc/cortex/memberlist.yaml.
+	FilePrefix = "file+"
+
+	// HTTPPrefix is the prefix of the addresses resolved by fetching the targets from an endpoint
+	// compatible with the Prometheus http_sd format, for example httpsd+http://sd.local/memberlist.
+	HTTPPrefix = "httpsd+"
+
+	// Maximum time to fetch the targets from an HTTP service discovery endpoint.
+	httpSDTimeout = 10 * time.Second
+)
+
+// IsServiceDiscoveryAddress returns whether the address is resolved with the file or HTTP service
+// discovery rather than DNS.
+func IsServiceDiscoveryAddress(addr string) bool {
+	return strings.HasPrefix(addr, FilePrefix) || strings.HasPrefix(addr, HTTPPrefix)
+}
+
+// dnsProvider is the subset of the Thanos DNS provider used by the Provider.
+type dnsProvider interface {
+	Resolve(ctx context.Context, addrs []string, flushOld bool) error
+	Addresses() []string
+}
+
+// Provider resolves addresses with the file and HTTP service discovery, in addition to the DNS
+// service discovery modes of the Thanos DNS provider, which all the other addresses are resolved
+// with. It can be used in place of the Thanos DNS provider in environments without DNS records
+// for the backend servers, for example when headless services are not available.
+type Provider struct {
+	dns    dnsProvider
+	client *http.Client
+	logger log.Logger
+
+	mtx sync.RWMutex
+	// A map from service discovery address to the targets it was last resolved to.
+	resolved map[string][]string
+	// Watches the directories of the resolved files, created on the first file lookup.
+	// Nil if no file has been resolved yet, or if the files can't be watched.
+	watcher     *fsnotify.Watcher
+	watchedDirs map[string]struct{}
+	closed      bool
+
+	// Notified when the targets of a file change between two resolutions.
+	changes chan struct{}
+
+	lookupsCount  *prometheus.CounterVec
+	failuresCount *prometheus.CounterVec
+}
+
+// NewProvider returns a new empty provider, resolving the DNS addresses with the given resolver type.
+func NewProvider(logger log.Logger, reg prometheus.Registerer, resolverType dns.ResolverType) *Provider {
+	return newProvider(dns.NewProvider(logger, reg, resolverType), logger, reg)
+}
+
+func newProvider(dnsProvider dnsProvider, logger log.Logger, reg prometheus.Registerer) *Provider {
+	return &Provider{
+		dns:         dnsProvider,
+		client:      &http.Client{Timeout: httpSDTimeout},
+		logger:      logger,
+		resolved:    map[string][]string{},
+		watchedDirs: map[string]struct{}{},
+		changes:     make(chan struct{}, 1),
+		lookupsCount: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "service_discovery_lookups_total",
+			Help: "The number of file and HTTP service discovery lookups.",
+		}, []string{"type"}),
+		failuresCount: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "service_discovery_failures_total",
+			Help: "The number of file and HTTP service discovery lookup failures.",
+		}, []string{"type"}),
+	}
+}
+
+// Resolve resolves the addresses and stores the results, which are returned by Addresses. If flushOld
+// is true, the results of the addresses not in addrs are removed. If the lookup of an address fails,
+// its previous results are kept.
+func (p *Provider) Resolve(ctx context.Context, addrs []string, flushOld bool) error {
+	var (
+		errs       multierror.MultiError
+		dnsAddrs   []string
+		sdAddrs    []string
+		sdResolved = map[string][]string{}
+	)
+
+	for _, addr := range addrs {
+		if IsServiceDiscoveryAddress(addr) {
+			sdAddrs = append(sdAddrs, addr)
+		} else {
+			dnsAddrs = append(dnsAddrs, addr)
+		}
+	}
+
+	errs.Add(p.dns.Resolve(ctx, dnsAddrs, flushOld))
+
+	for _, addr := range sdAddrs {
+		targets, err := p.lookup(ctx, addr)
+		if err != nil {
+			level.Error(p.logger).Log("msg", "failed to resolve service discovery address", "addr", addr, "err", err)
+			errs.Add(err)
+			continue
+		}
+		sdResolved[addr] = targets
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if flushOld {
+		for addr := range p.resolved {
+			if !slices.Contains(sdAddrs, addr) {
+				delete(p.resolved, addr)
+			}
+		}
+	}
+	for addr, targets := range sdResolved {
+		p.resolved[addr] = targets
+
+		if strings.HasPrefix(addr, FilePrefix) {
+			p.watch(strings.TrimPrefix(addr, FilePrefix))
+		}
+	}
+
+	return errs.Err()
+}
+
+// Changes returns a channel notified when the targets of a resolved file change. The files are
+// watched for changes, and resolved again right away, so that the new targets are returned by
+// Addresses without waiting for the next resolution.
+func (p *Provider) Changes() <-chan struct{} {
+	return p.changes
+}
+
+// Close stops watching the resolved files.
+func (p *Provider) Close() error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	p.closed = true
+	if p.watcher == nil {
+		return nil
+	}
+	return p.watcher.Close()
+}
+
+// watch starts watching the directory of the file, if not watched already. The directory is watched
+// rather than the file, so that the file replacements (e.g. by a Kubernetes ConfigMap update) are seen.
+// It must be called with the mutex held.
+func (p *Provider) watch(path string) {
+	if p.closed {
+		return
+	}
+
+	if p.watcher == nil {
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			level.Warn(p.logger).Log("msg", "failed to watch service discovery files, the changes are only picked up at the next lookup", "err", err)
+			p.closed = true
+			return
+		}
+
+		p.watcher = watcher
+		go p.watchLoop(watcher)
+	}
+
+	dir := filepath.Dir(path)
+	if _, ok := p.watchedDirs[dir]; ok {
+		return
+	}
+	if err := p.watcher.Add(dir); err != nil {
+		level.Warn(p.logger).Log("msg", "failed to watch service discovery file directory, the changes are only picked up at the next lookup", "dir", dir, "err", err)
+		return
+	}
+	p.watchedDirs[dir] = struct{}{}
+}
+
+func (p *Provider) watchLoop(watcher *fsnotify.Watcher) {
+	for {
+		select {
+		case _, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			p.refreshFiles()
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			level.Warn(p.logger).Log("msg", "error watching service discovery files", "err", err)
+		}
+	}
+}
+
+// refreshFiles resolves the files again, and notifies the changes of their targets.
+func (p *Provider) refreshFiles() {
+	p.mtx.RLock()
+	var addrs []string
+	for addr := range p.resolved {
+		if strings.HasPrefix(addr, FilePrefix) {
+			addrs = append(addrs, addr)
+		}
+	}
+	p.mtx.RUnlock()
+
+	changed := false
+	for _, addr := range addrs {
+		// The file may be only partially written, the previous targets are kept until the next event.
+		targets, err := p.lookup(context.Background(), addr)
+		if err != nil {
+			continue
+		}
+
+		p.mtx.Lock()
+		// Skip the addresses flushed in the meantime.
+		if prev, ok := p.resolved[addr]; ok && !slices.Equal(prev, targets) {
+			p.resolved[addr] = targets
+			changed = true
+		}
+		p.mtx.Unlock()
+	}
+
+	if changed {
+		select {
+		case p.changes <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Addresses returns the latest addresses resolved by the provider.
+func (p *Provider) Addresses() []string {
+	addrs := p.dns.Addresses()
+
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+
+	for _, targets := range p.resolved {
+		addrs = append(addrs, targets...)
+	}
+	return addrs
+}
+
+func (p *Provider) lookup(ctx context.Context, addr string) ([]string, error) {
+	var (
+		sdType string
+		groups []*targetgroup.Group
+		err    error
+	)
+
+	switch {
+	case strings.HasPrefix(addr, FilePrefix):
+		sdType = "file"
+		groups, err = readFileTargetGroups(strings.TrimPrefix(addr, FilePrefix))
+	case strings.HasPrefix(addr, HTTPPrefix):
+		sdType = "http"
+		groups, err = p.fetchHTTPTargetGroups(ctx, strings.TrimPrefix(addr, HTTPPrefix))
+	default:
+		return nil, fmt.Errorf("unsupported service discovery address %q", addr)
+	}
+
+	p.lookupsCount.WithLabelValues(sdType).Inc()
+	if err != nil {
+		p.failuresCount.WithLabelValues(sdType).Inc()
+		return nil, err
+	}
+	return targetGroupsAddresses(groups), nil
+}
+
+// readFileTargetGroups reads the target groups from a file in the Prometheus file_sd format. The
+// file is decoded as JSON if it has the .json extension, and as YAML otherwise.
+func readFileTargetGroups(path string) ([]*targetgroup.Group, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "read service discovery file")
+	}
+
+	var groups []*targetgroup.Group
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(content, &groups)
+	} else {
+		err = yaml.UnmarshalStrict(content, &groups)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "decode service discovery file %s", path)
+	}
+	return groups, nil
+}
+
+// fetchHTTPTargetGroups fetches the target groups from an endpoint compatible with the Prometheus
+// http_sd format, which must reply with a 200 status code and a JSON list of target groups.
+func (p *Provider) fetchHTTPTargetGroups(ctx context.Context, url string) ([]*targetgroup.Group, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "create service discovery request")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "fetch service discovery targets")
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("service discovery endpoint %s returned HTTP status %s", url, resp.Status)
+	}
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		return nil, fmt.Errorf("service discovery endpoint %s returned unsupported content type %q", url, resp.Header.Get("Content-Type"))
+	}
+
+	var groups []*targetgroup.Group
+	if err := json.NewDecoder(resp.Body).Decode(&groups); err != nil {
+		return nil, errors.Wrapf(err, "decode service discovery targets from %s", url)
+	}
+	return groups, nil
+}
+
+// targetGroupsAddresses returns the addresses of the targets of the groups. The labels of the groups are ignored.
+func targetGroupsAddresses(groups []*targetgroup.Group) []string {
+	var addrs []string
+	for _, group := range groups {
+		if group == nil {
+			continue
+		}
+		for _, target := range group.Targets {
+			if addr := string(target[model.AddressLabel]); addr != "" {
+				addrs = append(addrs, addr)
+			}
+		}
+	}
+	return addrs
+}
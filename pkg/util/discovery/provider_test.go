@@ -0,0 +1,137 @@
+package discovery
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockDNSProvider struct {
+	resolved []string
+	addrs    []string
+}
+
+func (m *mockDNSProvider) Resolve(_ context.Context, addrs []string, _ bool) error {
+	m.resolved = addrs
+	m.addrs = nil
+	for _, addr := range addrs {
+		m.addrs = append(m.addrs, "resolved-"+addr)
+	}
+	return nil
+}
+
+func (m *mockDNSProvider) Addresses() []string {
+	return m.addrs
+}
+
+func TestProvider_ShouldResolveFileAddresses(t *testing.T) {
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "targets.yaml")
+	jsonPath := filepath.Join(dir, "targets.json")
+
+	require.NoError(t, os.WriteFile(yamlPath, []byte(`
+- targets: ["10.0.0.1:7946", "10.0.0.2:7946"]
+  labels:
+    zone: a
+- targets: ["10.0.0.3:7946"]
+`), 0o644))
+	require.NoError(t, os.WriteFile(jsonPath, []byte(`[{"targets": ["10.0.1.1:11211"], "labels": {"zone": "b"}}]`), 0o644))
+
+	dns := &mockDNSProvider{}
+	p := newProvider(dns, log.NewNopLogger(), prometheus.NewPedanticRegistry())
+
+	require.NoError(t, p.Resolve(context.Background(), []string{FilePrefix + yamlPath, FilePrefix + jsonPath, "dns+memcached:11211"}, true))
+	assert.Equal(t, []string{"dns+memcached:11211"}, dns.resolved)
+	assert.ElementsMatch(t, []string{"resolved-dns+memcached:11211", "10.0.0.1:7946", "10.0.0.2:7946", "10.0.0.3:7946", "10.0.1.1:11211"}, p.Addresses())
+
+	// Changes of the file are picked up by the next resolution.
+	require.NoError(t, os.WriteFile(yamlPath, []byte(`[{"targets": ["10.0.0.4:7946"]}]`), 0o644))
+	require.NoError(t, p.Resolve(context.Background(), []string{FilePrefix + yamlPath}, true))
+	assert.ElementsMatch(t, []string{"10.0.0.4:7946"}, p.Addresses())
+}
+
+func TestProvider_ShouldWatchResolvedFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "targets.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`[{"targets": ["10.0.0.1:7946"]}]`), 0o644))
+
+	p := newProvider(&mockDNSProvider{}, log.NewNopLogger(), prometheus.NewPedanticRegistry())
+	t.Cleanup(func() { require.NoError(t, p.Close()) })
+	require.NoError(t, p.Resolve(context.Background(), []string{FilePrefix + path}, true))
+
+	// The file is replaced, as done by a Kubernetes ConfigMap update.
+	tmp := path + ".tmp"
+	require.NoError(t, os.WriteFile(tmp, []byte(`[{"targets": ["10.0.0.2:7946"]}]`), 0o644))
+	require.NoError(t, os.Rename(tmp, path))
+
+	select {
+	case <-p.Changes():
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "the change of the file has not been notified")
+	}
+	assert.Equal(t, []string{"10.0.0.2:7946"}, p.Addresses())
+}
+
+func TestProvider_ShouldKeepPreviousTargetsOnFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "targets.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`[{"targets": ["10.0.0.1:7946"]}]`), 0o644))
+
+	p := newProvider(&mockDNSProvider{}, log.NewNopLogger(), prometheus.NewPedanticRegistry())
+	require.NoError(t, p.Resolve(context.Background(), []string{FilePrefix + path}, true))
+
+	require.NoError(t, os.WriteFile(path, []byte(`not a list of target groups`), 0o644))
+	require.Error(t, p.Resolve(context.Background(), []string{FilePrefix + path}, true))
+	assert.Equal(t, []string{"10.0.0.1:7946"}, p.Addresses())
+
+	require.NoError(t, os.Remove(path))
+	require.Error(t, p.Resolve(context.Background(), []string{FilePrefix + path}, true))
+	assert.Equal(t, []string{"10.0.0.1:7946"}, p.Addresses())
+
+	// The targets of the addresses no longer resolved are flushed.
+	require.NoError(t, p.Resolve(context.Background(), nil, true))
+	assert.Empty(t, p.Addresses())
+}
+
+func TestProvider_ShouldResolveHTTPAddresses(t *testing.T) {
+	var (
+		status      = http.StatusOK
+		contentType = "application/json"
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`[{"targets": ["10.0.0.1:9095", "10.0.0.2:9095"], "labels": {"__meta_zone": "a"}}, {"targets": []}]`))
+	}))
+	defer server.Close()
+
+	p := newProvider(&mockDNSProvider{}, log.NewNopLogger(), prometheus.NewPedanticRegistry())
+	addr := HTTPPrefix + server.URL + "/targets"
+
+	require.NoError(t, p.Resolve(context.Background(), []string{addr}, true))
+	assert.ElementsMatch(t, []string{"10.0.0.1:9095", "10.0.0.2:9095"}, p.Addresses())
+
+	// Responses not compatible with the http_sd format are rejected.
+	status = http.StatusInternalServerError
+	require.Error(t, p.Resolve(context.Background(), []string{addr}, true))
+
+	status = http.StatusOK
+	contentType = "text/plain"
+	require.Error(t, p.Resolve(context.Background(), []string{addr}, true))
+
+	assert.ElementsMatch(t, []string{"10.0.0.1:9095", "10.0.0.2:9095"}, p.Addresses())
+}
+
+func TestIsServiceDiscoveryAddress(t *testing.T) {
+	assert.True(t, IsServiceDiscoveryAddress("file+/etc/cortex/targets.yaml"))
+	assert.True(t, IsServiceDiscoveryAddress("httpsd+http://sd.local/targets"))
+	assert.False(t, IsServiceDiscoveryAddress("dns+memcached:11211"))
+	assert.False(t, IsServiceDiscoveryAddress("memcached:11211"))
+}
@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/go-kit/log/level"
 	"github.com/pkg/errors"
+	"github.com/thanos-io/thanos/pkg/discovery/dns"
 
+	"github.com/cortexproject/cortex/pkg/util/discovery"
 	"github.com/cortexproject/cortex/pkg/util/grpcutil"
 	util_log "github.com/cortexproject/cortex/pkg/util/log"
 	"github.com/cortexproject/cortex/pkg/util/services"
@@ -26,8 +29,21 @@ type dnsWatcher struct {
 	notifications DNSNotifications
 }
 
-// NewDNSWatcher creates a new DNS watcher and returns a service that is wrapping it.
+// NewDNSWatcher creates a new DNS watcher and returns a service that is wrapping it. If the address has
+// the file or HTTP service discovery prefix, its targets are looked up every dnsLookupPeriod instead,
+// and whenever the file changes.
 func NewDNSWatcher(address string, dnsLookupPeriod time.Duration, notifications DNSNotifications) (services.Service, error) {
+	if discovery.IsServiceDiscoveryAddress(address) {
+		w := &serviceDiscoveryWatcher{
+			address:       address,
+			lookupPeriod:  dnsLookupPeriod,
+			provider:      discovery.NewProvider(util_log.Logger, nil, dns.GolangResolverType),
+			notifications: notifications,
+			addresses:     map[string]struct{}{},
+		}
+		return services.NewBasicService(w.lookup, w.running, w.stopping), nil
+	}
+
 	resolver, err := grpcutil.NewDNSResolverWithFreq(dnsLookupPeriod, util_log.Logger)
 	if err != nil {
 		return nil, err
@@ -81,3 +97,68 @@ func (w *dnsWatcher) watchDNSLoop(servCtx context.Context) error {
 		}
 	}
 }
+
+// serviceDiscoveryWatcher looks up the targets of a file or HTTP service discovery address and
+// sends notifications about the changes.
+type serviceDiscoveryWatcher struct {
+	address       string
+	lookupPeriod  time.Duration
+	provider      *discovery.Provider
+	notifications DNSNotifications
+
+	// Addresses notified as added and not removed since.
+	addresses map[string]struct{}
+}
+
+func (w *serviceDiscoveryWatcher) running(ctx context.Context) error {
+	ticker := time.NewTicker(w.lookupPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			_ = w.lookup(ctx)
+		case <-w.provider.Changes():
+			// The provider resolved the changed file already.
+			w.notify()
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (w *serviceDiscoveryWatcher) stopping(_ error) error {
+	return w.provider.Close()
+}
+
+func (w *serviceDiscoveryWatcher) lookup(ctx context.Context) error {
+	if err := w.provider.Resolve(ctx, []string{w.address}, true); err != nil {
+		// Keep the previous addresses, they are looked up again at the next iteration.
+		level.Warn(util_log.Logger).Log("msg", "failed to look up service discovery address", "addr", w.address, "err", err)
+		return nil
+	}
+
+	w.notify()
+	return nil
+}
+
+// notify sends notifications about the addresses added and removed since the previous notifications.
+func (w *serviceDiscoveryWatcher) notify() {
+	current := map[string]struct{}{}
+	for _, addr := range w.provider.Addresses() {
+		current[addr] = struct{}{}
+	}
+
+	for addr := range w.addresses {
+		if _, ok := current[addr]; !ok {
+			delete(w.addresses, addr)
+			w.notifications.AddressRemoved(addr)
+		}
+	}
+	for addr := range current {
+		if _, ok := w.addresses[addr]; !ok {
+			w.addresses[addr] = struct{}{}
+			w.notifications.AddressAdded(addr)
+		}
+	}
+}
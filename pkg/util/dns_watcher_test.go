@@ -0,0 +1,70 @@
+package util_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/cortexproject/cortex/pkg/util"
+	"github.com/cortexproject/cortex/pkg/util/services"
+	"github.com/cortexproject/cortex/pkg/util/test"
+)
+
+type mockDNSNotifications struct {
+	mtx       sync.Mutex
+	addresses []string
+}
+
+func (m *mockDNSNotifications) AddressAdded(address string) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.addresses = append(m.addresses, address)
+}
+
+func (m *mockDNSNotifications) AddressRemoved(address string) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.addresses = slices.DeleteFunc(m.addresses, func(addr string) bool { return addr == address })
+}
+
+func (m *mockDNSNotifications) getAddresses() []string {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	addresses := slices.Clone(m.addresses)
+	slices.Sort(addresses)
+	return addresses
+}
+
+func TestDNSWatcher_ShouldWatchServiceDiscoveryFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schedulers.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`[{"targets": ["10.0.0.1:9095", "10.0.0.2:9095"]}]`), 0o644))
+
+	notifications := &mockDNSNotifications{}
+	w, err := util.NewDNSWatcher("file+"+path, 10*time.Millisecond, notifications)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), w))
+	t.Cleanup(func() {
+		require.NoError(t, services.StopAndAwaitTerminated(context.Background(), w))
+	})
+
+	require.Equal(t, []string{"10.0.0.1:9095", "10.0.0.2:9095"}, notifications.getAddresses())
+
+	require.NoError(t, os.WriteFile(path, []byte(`[{"targets": ["10.0.0.2:9095", "10.0.0.3:9095"]}]`), 0o644))
+	test.Poll(t, time.Second, []string{"10.0.0.2:9095", "10.0.0.3:9095"}, func() any {
+		return notifications.getAddresses()
+	})
+
+	// The addresses are kept if the file can't be read.
+	require.NoError(t, os.Remove(path))
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, []string{"10.0.0.2:9095", "10.0.0.3:9095"}, notifications.getAddresses())
+}
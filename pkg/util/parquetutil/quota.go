@@ -0,0 +1,20 @@
+package parquetutil
+
+import (
+	"fmt"
+
+	"github.com/prometheus-community/parquet-common/search"
+)
+
+// ReserveRows checks the row count quota for the rows which are going to be read to
+// materialize the label names or values.
+func ReserveRows(quota *search.Quota, rr []search.RowRange) error {
+	rows := int64(0)
+	for _, r := range rr {
+		rows += r.Count
+	}
+	if err := quota.Reserve(rows); err != nil {
+		return fmt.Errorf("would fetch too many rows: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package parquetutil
+
+import (
+	"testing"
+
+	"github.com/prometheus-community/parquet-common/search"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReserveRows(t *testing.T) {
+	quota := search.NewQuota(10)
+
+	require.NoError(t, ReserveRows(quota, []search.RowRange{{From: 0, Count: 4}, {From: 10, Count: 4}}))
+	require.ErrorContains(t, ReserveRows(quota, []search.RowRange{{From: 20, Count: 3}}), "would fetch too many rows")
+}
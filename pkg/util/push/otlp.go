@@ -104,6 +104,7 @@ func OTLPHandler(maxRecvMsgSize int, overrides *validation.Overrides, cfg distri
 			} else if resp.GetCode() != http.StatusAccepted && resp.GetCode() != http.StatusTooManyRequests {
 				level.Warn(logger).Log("msg", "push refused", "err", err)
 			}
+			setRespHeaders(w, resp)
 			http.Error(w, string(resp.Body), int(resp.Code))
 		}
 	})
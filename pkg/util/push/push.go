@@ -83,6 +83,7 @@ func Handler(remoteWrite2Enabled bool, acceptUnknownRemoteWriteContentType bool,
 				} else if resp.GetCode() != http.StatusAccepted && resp.GetCode() != http.StatusTooManyRequests {
 					level.Warn(logger).Log("msg", "push refused", "err", err)
 				}
+				setRespHeaders(w, resp)
 				http.Error(w, string(resp.Body), int(resp.Code))
 			}
 		}
@@ -140,6 +141,7 @@ func Handler(remoteWrite2Enabled bool, acceptUnknownRemoteWriteContentType bool,
 				} else if resp.GetCode() != http.StatusAccepted && resp.GetCode() != http.StatusTooManyRequests {
 					level.Warn(logger).Log("msg", "push refused", "err", err)
 				}
+				setRespHeaders(w, resp)
 				http.Error(w, string(resp.Body), int(resp.Code))
 			} else {
 				setPRW2RespHeader(w, writeResp.Samples, writeResp.Histograms, writeResp.Exemplars)
@@ -206,6 +208,15 @@ func setPRW2RespHeader(w http.ResponseWriter, samples, histograms, exemplars int
 	w.Header().Set(rw20WrittenExemplarsHeader, strconv.FormatInt(exemplars, 10))
 }
 
+// setRespHeaders copies the headers of the push error response, such as Retry-After.
+func setRespHeaders(w http.ResponseWriter, resp *httpgrpc.HTTPResponse) {
+	for _, h := range resp.GetHeaders() {
+		for _, v := range h.Values {
+			w.Header().Add(h.Key, v)
+		}
+	}
+}
+
 func convertV2RequestToV1(req *cortexpb.PreallocWriteRequestV2, enableTypeAndUnitLabels bool, enableStartTimestamp bool) (v1Req cortexpb.PreallocWriteRequest, err error) {
 	v1Timeseries := make([]cortexpb.PreallocTimeseries, 0, len(req.Timeseries))
 	var v1Metadata []*cortexpb.MetricMetadata
@@ -1055,6 +1055,93 @@ func TestHandler_cortexWriteRequest(t *testing.T) {
 	})
 }
 
+func TestHandler_RemoteWriteV2ParityWithV1(t *testing.T) {
+	var limits validation.Limits
+	flagext.DefaultValues(&limits)
+	limits.EnableStartTimestamp = true
+	overrides := validation.NewOverrides(limits, nil)
+
+	// The v1 request equivalent to the v2 one, with the metadata sent along the series.
+	v1Req := cortexpb.WriteRequest{
+		Timeseries: []cortexpb.PreallocTimeseries{{TimeSeries: &cortexpb.TimeSeries{
+			Labels:    cortexpb.FromLabelsToLabelAdapters(labels.FromStrings(labels.MetricName, "requests_total", "job", "test")),
+			Samples:   []cortexpb.Sample{{Value: 1, TimestampMs: 2000, StartTimestampMs: 1000}},
+			Exemplars: []cortexpb.Exemplar{{Labels: cortexpb.FromLabelsToLabelAdapters(labels.FromStrings("trace_id", "abc")), Value: 1, TimestampMs: 2000}},
+		}}},
+		Metadata: []*cortexpb.MetricMetadata{{MetricFamilyName: "requests_total", Type: cortexpb.COUNTER, Unit: "requests", Help: "Total number of requests."}},
+		Source:   cortexpb.API,
+	}
+	v1Body, err := v1Req.Marshal()
+	require.NoError(t, err)
+
+	v2Req := cortexpb.WriteRequestV2{
+		Symbols: []string{"", labels.MetricName, "requests_total", "job", "test", "trace_id", "abc", "requests", "Total number of requests."},
+		Timeseries: []cortexpb.PreallocTimeseriesV2{{TimeSeriesV2: &cortexpb.TimeSeriesV2{
+			LabelsRefs:       []uint32{1, 2, 3, 4},
+			Samples:          []cortexpb.Sample{{Value: 1, TimestampMs: 2000}},
+			Exemplars:        []cortexpb.ExemplarV2{{LabelsRefs: []uint32{5, 6}, Value: 1, Timestamp: 2000}},
+			Metadata:         cortexpb.MetadataV2{Type: cortexpb.METRIC_TYPE_COUNTER, UnitRef: 7, HelpRef: 8},
+			CreatedTimestamp: 1000,
+		}}},
+		Source: cortexpb.API,
+	}
+	v2Body, err := v2Req.Marshal()
+	require.NoError(t, err)
+
+	// Keep a copy of the pushed request, which is returned to the pool once pushed.
+	var pushed []cortexpb.WriteRequest
+	push := func(_ context.Context, req *cortexpb.WriteRequest) (*cortexpb.WriteResponse, error) {
+		b, err := req.Marshal()
+		require.NoError(t, err)
+		var c cortexpb.WriteRequest
+		require.NoError(t, c.Unmarshal(b))
+		pushed = append(pushed, c)
+		return &cortexpb.WriteResponse{}, nil
+	}
+	handler := Handler(true, false, 100000, overrides, nil, push, nil)
+
+	for _, req := range []*http.Request{createRequest(t, v1Body, false), createRequest(t, v2Body, true)} {
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, req.WithContext(user.InjectOrgID(context.Background(), "user-1")))
+		require.Less(t, resp.Code, 300)
+	}
+
+	require.Len(t, pushed, 2)
+	assert.Equal(t, pushed[0].Timeseries[0].TimeSeries, pushed[1].Timeseries[0].TimeSeries)
+	assert.Equal(t, pushed[0].Metadata, pushed[1].Metadata)
+}
+
+func TestHandler_ShouldForwardPushErrorHeaders(t *testing.T) {
+	var limits validation.Limits
+	flagext.DefaultValues(&limits)
+	overrides := validation.NewOverrides(limits, nil)
+
+	push := func(context.Context, *cortexpb.WriteRequest) (*cortexpb.WriteResponse, error) {
+		return nil, httpgrpc.ErrorFromHTTPResponse(&httpgrpc.HTTPResponse{
+			Code:    http.StatusTooManyRequests,
+			Body:    []byte("ingestion rate limit exceeded"),
+			Headers: []*httpgrpc.Header{{Key: "Retry-After", Values: []string{"10"}}},
+		})
+	}
+	handler := Handler(true, false, 100000, overrides, nil, push, nil)
+
+	t.Run("remote write v1", func(t *testing.T) {
+		req := createRequest(t, createCortexWriteRequestProtobuf(t, false, cortexpb.API), false)
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+		assert.Equal(t, "10", resp.Header().Get("Retry-After"))
+	})
+	t.Run("remote write v2", func(t *testing.T) {
+		req := createRequest(t, createCortexRemoteWriteV2Protobuf(t, false, cortexpb.API), true)
+		req = req.WithContext(user.InjectOrgID(context.Background(), "user-1"))
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, req)
+		assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+		assert.Equal(t, "10", resp.Header().Get("Retry-After"))
+	})
+}
+
 func TestHandler_ignoresSkipLabelNameValidationIfSet(t *testing.T) {
 	var limits validation.Limits
 	flagext.DefaultValues(&limits)
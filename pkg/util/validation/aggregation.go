@@ -0,0 +1,111 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+const (
+	// AggregationSum sums the latest value of the input series.
+	AggregationSum = "sum"
+	// AggregationSumIncrease sums the increases of the input counters into an output counter.
+	AggregationSumIncrease = "sum_increase"
+)
+
+var (
+	errAggregationRuleInvalidRecord    = errors.New("aggregation rule record must be a valid metric name")
+	errAggregationRuleDuplicateRecord  = errors.New("duplicate aggregation rule record")
+	errAggregationRuleInvalidOperation = fmt.Errorf("aggregation rule operation must be one of: %s, %s", AggregationSum, AggregationSumIncrease)
+	errAggregationRuleByAndWithout     = errors.New("aggregation rule can't set both by and without")
+)
+
+// AggregationRule defines an aggregation computed at ingest time by the distributors over
+// the series matching a selector.
+type AggregationRule struct {
+	Record    string   `yaml:"record" json:"record" doc:"nocli|description=Metric name of the aggregated series."`
+	Matchers  string   `yaml:"matchers" json:"matchers" doc:"nocli|description=PromQL series selector (e.g. {__name__=~\"http_requests_total\"}) of the input series. Only float samples are aggregated."`
+	Operation string   `yaml:"operation" json:"operation" doc:"nocli|description=Aggregation operation. Supported values are: sum (sum of the latest value of each input series), sum_increase (counter incremented by the increases of the input counters, which can be queried with rate()).|default=sum"`
+	By        []string `yaml:"by" json:"by" doc:"nocli|description=Labels to keep in the aggregated series. Can't be set along with without."`
+	Without   []string `yaml:"without" json:"without" doc:"nocli|description=Labels to remove from the aggregated series. If neither by nor without is set, all the input series are aggregated into a single series."`
+	DropInput bool     `yaml:"drop_input" json:"drop_input" doc:"nocli|description=If true, the input series are not ingested.|default=false"`
+
+	// Parsed matchers, populated during validation.
+	parsedMatchers []*labels.Matcher `yaml:"-" json:"-" doc:"nocli"`
+}
+
+// Validate parses the matchers and checks the rule.
+func (r *AggregationRule) Validate() error {
+	if r.Record == "" || !model.UTF8Validation.IsValidMetricName(r.Record) {
+		return fmt.Errorf("%w: %q", errAggregationRuleInvalidRecord, r.Record)
+	}
+
+	switch r.Operation {
+	case "":
+		r.Operation = AggregationSum
+	case AggregationSum, AggregationSumIncrease:
+	default:
+		return fmt.Errorf("aggregation rule %q: %w", r.Record, errAggregationRuleInvalidOperation)
+	}
+
+	if len(r.By) > 0 && len(r.Without) > 0 {
+		return fmt.Errorf("aggregation rule %q: %w", r.Record, errAggregationRuleByAndWithout)
+	}
+
+	matchers, err := parser.ParseMetricSelector(r.Matchers)
+	if err != nil {
+		return fmt.Errorf("aggregation rule %q: %w", r.Record, err)
+	}
+	r.parsedMatchers = matchers
+	return nil
+}
+
+// Matches returns whether the series with the given labels is an input of the rule.
+// Must call Validate() first.
+func (r *AggregationRule) Matches(lbls labels.Labels) bool {
+	for _, m := range r.parsedMatchers {
+		if !m.Matches(lbls.Get(m.Name)) {
+			return false
+		}
+	}
+	return true
+}
+
+// OutputLabels returns the labels of the aggregated series the input series with the given
+// labels is aggregated into.
+func (r *AggregationRule) OutputLabels(b *labels.Builder, lbls labels.Labels) labels.Labels {
+	switch {
+	case len(r.By) > 0:
+		b.Reset(lbls)
+		b.Keep(r.By...)
+	case len(r.Without) > 0:
+		b.Reset(lbls)
+		b.Del(r.Without...)
+	default:
+		b.Reset(labels.EmptyLabels())
+	}
+	b.Set(labels.MetricName, r.Record)
+	return b.Labels()
+}
+
+// AggregationRules is a list of aggregation rules.
+type AggregationRules []AggregationRule
+
+// Validate parses and validates all aggregation rules, ensuring records are unique.
+func (r AggregationRules) Validate() error {
+	records := make([]string, 0, len(r))
+	for i := range r {
+		if err := r[i].Validate(); err != nil {
+			return err
+		}
+		if slices.Contains(records, r[i].Record) {
+			return fmt.Errorf("%w: %q", errAggregationRuleDuplicateRecord, r[i].Record)
+		}
+		records = append(records, r[i].Record)
+	}
+	return nil
+}
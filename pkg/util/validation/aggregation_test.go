@@ -0,0 +1,87 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregationRule_Validate(t *testing.T) {
+	t.Run("defaults to sum", func(t *testing.T) {
+		rule := AggregationRule{Record: "job:foo:sum", Matchers: `{__name__="foo"}`}
+		require.NoError(t, rule.Validate())
+		assert.Equal(t, AggregationSum, rule.Operation)
+	})
+
+	t.Run("invalid record", func(t *testing.T) {
+		rule := AggregationRule{Matchers: `{__name__="foo"}`}
+		assert.ErrorIs(t, rule.Validate(), errAggregationRuleInvalidRecord)
+	})
+
+	t.Run("invalid operation", func(t *testing.T) {
+		rule := AggregationRule{Record: "foo:avg", Matchers: `{__name__="foo"}`, Operation: "avg"}
+		assert.ErrorIs(t, rule.Validate(), errAggregationRuleInvalidOperation)
+	})
+
+	t.Run("both by and without", func(t *testing.T) {
+		rule := AggregationRule{Record: "foo:sum", Matchers: `{__name__="foo"}`, By: []string{"job"}, Without: []string{"pod"}}
+		assert.ErrorIs(t, rule.Validate(), errAggregationRuleByAndWithout)
+	})
+
+	t.Run("invalid matchers", func(t *testing.T) {
+		rule := AggregationRule{Record: "foo:sum", Matchers: `{__name__=~"[bad"}`}
+		assert.Error(t, rule.Validate())
+	})
+}
+
+func TestAggregationRules_Validate(t *testing.T) {
+	rules := AggregationRules{
+		{Record: "foo:sum", Matchers: `{__name__="foo"}`},
+		{Record: "foo:sum", Matchers: `{__name__="bar"}`},
+	}
+	assert.ErrorIs(t, rules.Validate(), errAggregationRuleDuplicateRecord)
+
+	// Rules with the same record would compute the same output series, also with different operations.
+	rules = AggregationRules{
+		{Record: "foo:sum", Matchers: `{__name__="foo"}`},
+		{Record: "foo:sum", Matchers: `{__name__="foo"}`, Operation: AggregationSumIncrease},
+	}
+	assert.ErrorIs(t, rules.Validate(), errAggregationRuleDuplicateRecord)
+}
+
+func TestAggregationRule_OutputLabels(t *testing.T) {
+	input := labels.FromStrings(labels.MetricName, "foo", "job", "a", "pod", "p-1")
+	b := labels.NewBuilder(labels.EmptyLabels())
+
+	tests := map[string]struct {
+		rule     AggregationRule
+		expected labels.Labels
+	}{
+		"by": {
+			rule:     AggregationRule{Record: "job:foo:sum", Matchers: `{__name__="foo"}`, By: []string{"job"}},
+			expected: labels.FromStrings(labels.MetricName, "job:foo:sum", "job", "a"),
+		},
+		"without": {
+			rule:     AggregationRule{Record: "job:foo:sum", Matchers: `{__name__="foo"}`, Without: []string{"pod"}},
+			expected: labels.FromStrings(labels.MetricName, "job:foo:sum", "job", "a"),
+		},
+		"all series": {
+			rule:     AggregationRule{Record: "foo:sum", Matchers: `{__name__="foo"}`},
+			expected: labels.FromStrings(labels.MetricName, "foo:sum"),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, tc.rule.Validate())
+			assert.True(t, tc.rule.Matches(input))
+			assert.Equal(t, tc.expected, tc.rule.OutputLabels(b, input))
+		})
+	}
+
+	rule := AggregationRule{Record: "foo:sum", Matchers: `{__name__="foo", job="b"}`}
+	require.NoError(t, rule.Validate())
+	assert.False(t, rule.Matches(input))
+}
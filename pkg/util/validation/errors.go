@@ -21,6 +21,7 @@ type ValidationError error
 // error format only contains the cause and the series.
 type genericValidationError struct {
 	message string
+	reason  string
 	cause   string
 	series  []cortexpb.LabelAdapter
 }
@@ -95,6 +96,7 @@ func labelSizeBytesExceededError(series []cortexpb.LabelAdapter, labelsSizeBytes
 func newInvalidLabelError(series []cortexpb.LabelAdapter, labelName string) ValidationError {
 	return &genericValidationError{
 		message: "sample invalid label: %.200q metric %.200q",
+		reason:  invalidLabel,
 		cause:   labelName,
 		series:  series,
 	}
@@ -103,6 +105,7 @@ func newInvalidLabelError(series []cortexpb.LabelAdapter, labelName string) Vali
 func newDuplicatedLabelError(series []cortexpb.LabelAdapter, labelName string) ValidationError {
 	return &genericValidationError{
 		message: "duplicate label name: %.200q metric %.200q",
+		reason:  duplicateLabelNames,
 		cause:   labelName,
 		series:  series,
 	}
@@ -111,6 +114,7 @@ func newDuplicatedLabelError(series []cortexpb.LabelAdapter, labelName string) V
 func newLabelsNotSortedError(series []cortexpb.LabelAdapter, labelName string) ValidationError {
 	return &genericValidationError{
 		message: "labels not sorted: %.200q metric %.200q",
+		reason:  labelsNotSorted,
 		cause:   labelName,
 		series:  series,
 	}
@@ -161,6 +165,7 @@ func (e *invalidMetricNameError) Error() string {
 // sampleValidationError is a ValidationError implementation suitable for sample validation errors.
 type sampleValidationError struct {
 	message    string
+	reason     string
 	metricName string
 	timestamp  int64
 }
@@ -172,6 +177,7 @@ func (e *sampleValidationError) Error() string {
 func newSampleTimestampTooOldError(metricName string, timestamp int64) ValidationError {
 	return &sampleValidationError{
 		message:    "timestamp too old: %d metric: %.200q",
+		reason:     greaterThanMaxSampleAge,
 		metricName: metricName,
 		timestamp:  timestamp,
 	}
@@ -180,6 +186,7 @@ func newSampleTimestampTooOldError(metricName string, timestamp int64) Validatio
 func newSampleTimestampTooNewError(metricName string, timestamp int64) ValidationError {
 	return &sampleValidationError{
 		message:    "timestamp too new: %d metric: %.200q",
+		reason:     tooFarInFuture,
 		metricName: metricName,
 		timestamp:  timestamp,
 	}
@@ -188,6 +195,7 @@ func newSampleTimestampTooNewError(metricName string, timestamp int64) Validatio
 // exemplarValidationError is a ValidationError implementation suitable for exemplar validation errors.
 type exemplarValidationError struct {
 	message        string
+	reason         string
 	seriesLabels   []cortexpb.LabelAdapter
 	exemplarLabels []cortexpb.LabelAdapter
 	timestamp      int64
@@ -200,6 +208,7 @@ func (e *exemplarValidationError) Error() string {
 func newExemplarEmtpyLabelsError(seriesLabels []cortexpb.LabelAdapter, exemplarLabels []cortexpb.LabelAdapter, timestamp int64) ValidationError {
 	return &exemplarValidationError{
 		message:        "exemplar missing labels, timestamp: %d series: %s labels: %s",
+		reason:         exemplarLabelsMissing,
 		seriesLabels:   seriesLabels,
 		exemplarLabels: exemplarLabels,
 		timestamp:      timestamp,
@@ -209,6 +218,7 @@ func newExemplarEmtpyLabelsError(seriesLabels []cortexpb.LabelAdapter, exemplarL
 func newExemplarMissingTimestampError(seriesLabels []cortexpb.LabelAdapter, exemplarLabels []cortexpb.LabelAdapter, timestamp int64) ValidationError {
 	return &exemplarValidationError{
 		message:        "exemplar missing timestamp, timestamp: %d series: %s labels: %s",
+		reason:         exemplarTimestampInvalid,
 		seriesLabels:   seriesLabels,
 		exemplarLabels: exemplarLabels,
 		timestamp:      timestamp,
@@ -220,6 +230,7 @@ var labelLenMsg = "exemplar combined labelset exceeds " + strconv.Itoa(ExemplarM
 func newExemplarLabelLengthError(seriesLabels []cortexpb.LabelAdapter, exemplarLabels []cortexpb.LabelAdapter, timestamp int64) ValidationError {
 	return &exemplarValidationError{
 		message:        labelLenMsg,
+		reason:         exemplarLabelsTooLong,
 		seriesLabels:   seriesLabels,
 		exemplarLabels: exemplarLabels,
 		timestamp:      timestamp,
@@ -298,6 +309,41 @@ func (e *nativeHistogramInvalidError) Error() string {
 	return fmt.Sprintf("invalid native histogram, validation err: %v, metric: %.200q", e.nhValidationErr, formatLabelSet(e.series))
 }
 
+// DiscardReason returns the reason the samples of a series failing the validation with the
+// given error are discarded with, or an empty string if err isn't a ValidationError.
+func DiscardReason(err error) string {
+	switch e := err.(type) {
+	case *genericValidationError:
+		return e.reason
+	case *sampleValidationError:
+		return e.reason
+	case *exemplarValidationError:
+		return e.reason
+	case *labelNameTooLongError:
+		return labelNameTooLong
+	case *labelValueTooLongError:
+		return labelValueTooLong
+	case *labelsSizeBytesExceededError:
+		return labelsSizeBytesExceeded
+	case *tooManyLabelsError:
+		return maxLabelNamesPerSeries
+	case *noMetricNameError:
+		return missingMetricName
+	case *invalidMetricNameError:
+		return invalidMetricName
+	case *histogramBucketLimitExceededError:
+		return nativeHistogramBucketCountLimitExceeded
+	case *nativeHistogramSchemaInvalidError:
+		return nativeHistogramInvalidSchema
+	case *nativeHistogramSampleSizeBytesExceededError:
+		return nativeHistogramSampleSizeBytesExceeded
+	case *nativeHistogramInvalidError:
+		return nativeHistogramInvalid
+	default:
+		return ""
+	}
+}
+
 // formatLabelSet formats label adapters as a metric name with labels, while preserving
 // label order, and keeping duplicates. If there are multiple "__name__" labels, only
 // first one is used as metric name, other ones will be included as regular labels.
@@ -323,3 +369,22 @@ func formatLabelSet(ls []cortexpb.LabelAdapter) string {
 
 	return fmt.Sprintf("%s{%s}", metricName, strings.Join(labelStrings, ", "))
 }
+
+// seriesPolicyError is a ValidationError returned when a series violates the tenant's series policy.
+type seriesPolicyError struct {
+	violation string
+	cause     string
+	series    []cortexpb.LabelAdapter
+}
+
+func (e *seriesPolicyError) Error() string {
+	return fmt.Sprintf("series policy violation (%s): %.200q metric %.200q", e.violation, e.cause, formatLabelSet(e.series))
+}
+
+func newSeriesPolicyError(series []cortexpb.LabelAdapter, violation, cause string) ValidationError {
+	return &seriesPolicyError{
+		violation: violation,
+		cause:     cause,
+		series:    series,
+	}
+}
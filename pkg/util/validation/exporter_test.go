@@ -53,7 +53,10 @@ func TestOverridesExporter_withConfig(t *testing.T) {
 		cortex_overrides{limit_name="compactor_partition_series_count",user="tenant-a"} 0
 		cortex_overrides{limit_name="compactor_tenant_shard_size",user="tenant-a"} 0
 		cortex_overrides{limit_name="creation_grace_period",user="tenant-a"} 600
+		cortex_overrides{limit_name="discarded_series_sampling_buffer_size",user="tenant-a"} 100
+		cortex_overrides{limit_name="discarded_series_sampling_rate",user="tenant-a"} 0
 		cortex_overrides{limit_name="enable_native_histograms",user="tenant-a"} 0
+		cortex_overrides{limit_name="enable_series_metadata",user="tenant-a"} 0
 		cortex_overrides{limit_name="enable_start_timestamp",user="tenant-a"} 0
 		cortex_overrides{limit_name="enable_type_and_unit_labels",user="tenant-a"} 0
 		cortex_overrides{limit_name="enforce_metadata_metric_name",user="tenant-a"} 1
@@ -73,6 +76,7 @@ func TestOverridesExporter_withConfig(t *testing.T) {
 		cortex_overrides{limit_name="max_global_metadata_per_metric",user="tenant-a"} 0
 		cortex_overrides{limit_name="max_global_metadata_per_user",user="tenant-a"} 0
 		cortex_overrides{limit_name="max_global_native_histogram_series_per_user",user="tenant-a"} 0
+		cortex_overrides{limit_name="max_global_series_churn_per_user",user="tenant-a"} 0
 		cortex_overrides{limit_name="max_global_series_per_metric",user="tenant-a"} 0
 		cortex_overrides{limit_name="max_global_series_per_user",user="tenant-a"} 0
 		cortex_overrides{limit_name="max_label_cardinality_for_unoptimized_regex",user="tenant-a"} 0
@@ -93,6 +97,7 @@ func TestOverridesExporter_withConfig(t *testing.T) {
 		cortex_overrides{limit_name="max_query_parallelism",user="tenant-a"} 14
 		cortex_overrides{limit_name="max_query_response_size",user="tenant-a"} 0
 		cortex_overrides{limit_name="max_regex_pattern_length",user="tenant-a"} 0
+		cortex_overrides{limit_name="max_series_churn_per_user",user="tenant-a"} 0
 		cortex_overrides{limit_name="max_series_per_metric",user="tenant-a"} 50000
 		cortex_overrides{limit_name="max_series_per_user",user="tenant-a"} 5e+06
 		cortex_overrides{limit_name="max_total_label_value_length_for_unoptimized_regex",user="tenant-a"} 0
@@ -118,8 +123,11 @@ func TestOverridesExporter_withConfig(t *testing.T) {
 		cortex_overrides{limit_name="ruler_query_offset",user="tenant-a"} 0
 		cortex_overrides{limit_name="ruler_tenant_shard_size",user="tenant-a"} 0
 		cortex_overrides{limit_name="rules_partial_data",user="tenant-a"} 0
+		cortex_overrides{limit_name="sample_dedup_window",user="tenant-a"} 0
+		cortex_overrides{limit_name="series_churn_window",user="tenant-a"} 3600
 		cortex_overrides{limit_name="shuffle_sharding_ingesters_lookback_period",user="tenant-a"} 0
 		cortex_overrides{limit_name="store_gateway_tenant_shard_size",user="tenant-a"} 0
+		cortex_overrides{limit_name="write_buffer_max_bytes",user="tenant-a"} 0
 	`), "cortex_overrides"))
 }
 
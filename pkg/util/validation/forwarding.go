@@ -0,0 +1,123 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+const (
+	// ForwardingProtocolV1 forwards series using the remote write 1.0 protocol.
+	ForwardingProtocolV1 = "v1"
+	// ForwardingProtocolV2 forwards series using the remote write 2.0 protocol.
+	ForwardingProtocolV2 = "v2"
+)
+
+var (
+	errForwardingEndpointInvalidURL      = errors.New("forwarding endpoint url must be an absolute http or https URL")
+	errForwardingEndpointDuplicateURL    = errors.New("duplicate forwarding endpoint url")
+	errForwardingEndpointInvalidProtocol = fmt.Errorf("forwarding endpoint protocol version must be one of: %s, %s", ForwardingProtocolV1, ForwardingProtocolV2)
+)
+
+// ForwardingEndpointConfig defines a remote write endpoint the distributor forwards a copy
+// of the tenant's series to.
+type ForwardingEndpointConfig struct {
+	URL             string   `yaml:"url" json:"url" doc:"nocli|description=URL of the remote write endpoint the matching series are forwarded to."`
+	ProtocolVersion string   `yaml:"protocol_version" json:"protocol_version" doc:"nocli|description=Remote write protocol version used to forward series. Supported values are: v1, v2.|default=v1"`
+	Matchers        []string `yaml:"matchers" json:"matchers" doc:"nocli|description=List of PromQL series selectors (e.g. {__name__=~\"api_.*\"}). A series is forwarded if it matches any of them. If empty, all series are forwarded. Metadata is forwarded if its metric family name matches the metric name matchers of any selector."`
+
+	// Parsed matchers, populated during validation.
+	parsedMatchers [][]*labels.Matcher `yaml:"-" json:"-" doc:"nocli"`
+}
+
+// Validate parses the matchers and checks the endpoint URL and protocol version.
+func (c *ForwardingEndpointConfig) Validate() error {
+	u, err := url.Parse(c.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: %q", errForwardingEndpointInvalidURL, c.URL)
+	}
+
+	switch c.ProtocolVersion {
+	case "":
+		c.ProtocolVersion = ForwardingProtocolV1
+	case ForwardingProtocolV1, ForwardingProtocolV2:
+	default:
+		return fmt.Errorf("forwarding endpoint %q: %w", u.Redacted(), errForwardingEndpointInvalidProtocol)
+	}
+
+	c.parsedMatchers = make([][]*labels.Matcher, 0, len(c.Matchers))
+	for _, selector := range c.Matchers {
+		matchers, err := parser.ParseMetricSelector(selector)
+		if err != nil {
+			return fmt.Errorf("forwarding endpoint %q: %w", u.Redacted(), err)
+		}
+		c.parsedMatchers = append(c.parsedMatchers, matchers)
+	}
+	return nil
+}
+
+// MatchesSeries returns whether the series with the given labels should be forwarded
+// to the endpoint. Must call Validate() first.
+func (c *ForwardingEndpointConfig) MatchesSeries(lbls labels.Labels) bool {
+	if len(c.parsedMatchers) == 0 {
+		return true
+	}
+
+	for _, matchers := range c.parsedMatchers {
+		matches := true
+		for _, m := range matchers {
+			if !m.Matches(lbls.Get(m.Name)) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesMetricFamily returns whether the metadata of the given metric family should be
+// forwarded to the endpoint. Only the matchers on the metric name are taken into account,
+// because metadata isn't associated with any other label. Must call Validate() first.
+func (c *ForwardingEndpointConfig) MatchesMetricFamily(name string) bool {
+	if len(c.parsedMatchers) == 0 {
+		return true
+	}
+
+	for _, matchers := range c.parsedMatchers {
+		matches := true
+		for _, m := range matchers {
+			if m.Name == labels.MetricName && !m.Matches(name) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+// ForwardingEndpointsConfig is a list of forwarding endpoint configurations.
+type ForwardingEndpointsConfig []ForwardingEndpointConfig
+
+// Validate parses and validates all forwarding endpoints, ensuring URLs are unique.
+func (c ForwardingEndpointsConfig) Validate() error {
+	urls := make(map[string]struct{}, len(c))
+	for i := range c {
+		if err := c[i].Validate(); err != nil {
+			return err
+		}
+		if _, exists := urls[c[i].URL]; exists {
+			return fmt.Errorf("%w: %q", errForwardingEndpointDuplicateURL, c[i].URL)
+		}
+		urls[c[i].URL] = struct{}{}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForwardingEndpointConfig_Validate(t *testing.T) {
+	t.Run("defaults to remote write v1", func(t *testing.T) {
+		cfg := ForwardingEndpointConfig{URL: "http://localhost/api/v1/push"}
+		require.NoError(t, cfg.Validate())
+		assert.Equal(t, ForwardingProtocolV1, cfg.ProtocolVersion)
+	})
+
+	t.Run("invalid url", func(t *testing.T) {
+		for _, u := range []string{"", "localhost:8080", "ftp://localhost", "http://"} {
+			cfg := ForwardingEndpointConfig{URL: u}
+			assert.ErrorIs(t, cfg.Validate(), errForwardingEndpointInvalidURL, u)
+		}
+	})
+
+	t.Run("invalid protocol version", func(t *testing.T) {
+		cfg := ForwardingEndpointConfig{URL: "http://localhost", ProtocolVersion: "v3"}
+		assert.ErrorIs(t, cfg.Validate(), errForwardingEndpointInvalidProtocol)
+	})
+
+	t.Run("invalid matchers", func(t *testing.T) {
+		cfg := ForwardingEndpointConfig{URL: "http://localhost", Matchers: []string{`{__name__=~"[bad"}`}}
+		assert.Error(t, cfg.Validate())
+	})
+}
+
+func TestForwardingEndpointsConfig_Validate(t *testing.T) {
+	t.Run("nil is valid", func(t *testing.T) {
+		var cfg ForwardingEndpointsConfig
+		require.NoError(t, cfg.Validate())
+	})
+
+	t.Run("duplicate urls", func(t *testing.T) {
+		cfg := ForwardingEndpointsConfig{
+			{URL: "http://localhost"},
+			{URL: "http://localhost", ProtocolVersion: ForwardingProtocolV2},
+		}
+		assert.ErrorIs(t, cfg.Validate(), errForwardingEndpointDuplicateURL)
+	})
+}
+
+func TestForwardingEndpointConfig_Matches(t *testing.T) {
+	all := ForwardingEndpointConfig{URL: "http://localhost"}
+	require.NoError(t, all.Validate())
+	assert.True(t, all.MatchesSeries(labels.FromStrings(labels.MetricName, "foo")))
+	assert.True(t, all.MatchesMetricFamily("foo"))
+
+	cfg := ForwardingEndpointConfig{
+		URL:      "http://localhost",
+		Matchers: []string{`{__name__="foo", job="a"}`, `{__name__=~"bar_.*"}`},
+	}
+	require.NoError(t, cfg.Validate())
+
+	assert.True(t, cfg.MatchesSeries(labels.FromStrings(labels.MetricName, "foo", "job", "a")))
+	assert.False(t, cfg.MatchesSeries(labels.FromStrings(labels.MetricName, "foo", "job", "b")))
+	assert.True(t, cfg.MatchesSeries(labels.FromStrings(labels.MetricName, "bar_total", "job", "b")))
+	assert.False(t, cfg.MatchesSeries(labels.FromStrings(labels.MetricName, "baz")))
+
+	// Only the metric name matchers apply to metadata.
+	assert.True(t, cfg.MatchesMetricFamily("foo"))
+	assert.True(t, cfg.MatchesMetricFamily("bar_total"))
+	assert.False(t, cfg.MatchesMetricFamily("baz"))
+}
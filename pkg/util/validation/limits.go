@@ -28,6 +28,8 @@ import (
 var errMaxGlobalSeriesPerUserValidation = errors.New("the ingester.max-global-series-per-user limit is unsupported if distributor.shard-by-all-labels is disabled")
 var errMaxGlobalNativeHistogramSeriesPerUserValidation = errors.New("the ingester.max-global-native-histogram-series-per-user limit is unsupported if distributor.shard-by-all-labels or ingester.active-series-metrics-enabled is disabled")
 var errMaxLocalNativeHistogramSeriesPerUserValidation = errors.New("the ingester.max-local-native-histogram-series-per-user limit is unsupported if ingester.active-series-metrics-enabled is disabled")
+var errMaxGlobalSeriesChurnPerUserValidation = errors.New("the ingester.max-global-series-churn-per-user limit is unsupported if distributor.shard-by-all-labels is disabled")
+var errInvalidSeriesChurnWindow = errors.New("the ingester.series-churn-window must be greater than 0 if a series churn limit is enabled")
 var errDuplicateQueryPriorities = errors.New("duplicate entry of priorities found. Make sure they are all unique, including the default priority")
 var errCompilingQueryPriorityRegex = errors.New("error compiling query priority regex")
 var errDuplicatePerLabelSetLimit = errors.New("duplicate per labelSet limits found. Make sure they are all unique")
@@ -128,35 +130,42 @@ type LimitsPerLabelSet struct {
 // limits via flags, or per-user limits via yaml config.
 type Limits struct {
 	// Distributor enforced limits.
-	IngestionRate                     float64             `yaml:"ingestion_rate" json:"ingestion_rate"`
-	NativeHistogramIngestionRate      float64             `yaml:"native_histogram_ingestion_rate" json:"native_histogram_ingestion_rate"`
-	IngestionRateStrategy             string              `yaml:"ingestion_rate_strategy" json:"ingestion_rate_strategy"`
-	IngestionBurstSize                int                 `yaml:"ingestion_burst_size" json:"ingestion_burst_size"`
-	NativeHistogramIngestionBurstSize int                 `yaml:"native_histogram_ingestion_burst_size" json:"native_histogram_ingestion_burst_size"`
-	AcceptHASamples                   bool                `yaml:"accept_ha_samples" json:"accept_ha_samples"`
-	AcceptMixedHASamples              bool                `yaml:"accept_mixed_ha_samples" json:"accept_mixed_ha_samples"`
-	HAClusterLabel                    string              `yaml:"ha_cluster_label" json:"ha_cluster_label"`
-	HAReplicaLabel                    string              `yaml:"ha_replica_label" json:"ha_replica_label"`
-	HAMaxClusters                     int                 `yaml:"ha_max_clusters" json:"ha_max_clusters"`
-	HATrackerFailoverTimeout          model.Duration      `yaml:"ha_tracker_failover_timeout" json:"ha_tracker_failover_timeout"`
-	DropLabels                        flagext.StringSlice `yaml:"drop_labels" json:"drop_labels"`
-	MaxLabelNameLength                int                 `yaml:"max_label_name_length" json:"max_label_name_length"`
-	MaxLabelValueLength               int                 `yaml:"max_label_value_length" json:"max_label_value_length"`
-	MaxLabelNamesPerSeries            int                 `yaml:"max_label_names_per_series" json:"max_label_names_per_series"`
-	MaxLabelsSizeBytes                int                 `yaml:"max_labels_size_bytes" json:"max_labels_size_bytes"`
-	MaxNativeHistogramSampleSizeBytes int                 `yaml:"max_native_histogram_sample_size_bytes" json:"max_native_histogram_sample_size_bytes"`
-	MaxMetadataLength                 int                 `yaml:"max_metadata_length" json:"max_metadata_length"`
-	RejectOldSamples                  bool                `yaml:"reject_old_samples" json:"reject_old_samples"`
-	RejectOldSamplesMaxAge            model.Duration      `yaml:"reject_old_samples_max_age" json:"reject_old_samples_max_age"`
-	CreationGracePeriod               model.Duration      `yaml:"creation_grace_period" json:"creation_grace_period"`
-	EnforceMetadataMetricName         bool                `yaml:"enforce_metadata_metric_name" json:"enforce_metadata_metric_name"`
-	EnforceMetricName                 bool                `yaml:"enforce_metric_name" json:"enforce_metric_name"`
-	IngestionTenantShardSize          int                 `yaml:"ingestion_tenant_shard_size" json:"ingestion_tenant_shard_size"`
-	MetricRelabelConfigs              []*relabel.Config   `yaml:"metric_relabel_configs,omitempty" json:"metric_relabel_configs,omitempty" doc:"nocli|description=List of metric relabel configurations. Note that in most situations, it is more effective to use metrics relabeling directly in the Prometheus server, e.g. remote_write.write_relabel_configs."`
-	MaxNativeHistogramBuckets         int                 `yaml:"max_native_histogram_buckets" json:"max_native_histogram_buckets"`
-	PromoteResourceAttributes         []string            `yaml:"promote_resource_attributes" json:"promote_resource_attributes"`
-	EnableTypeAndUnitLabels           bool                `yaml:"enable_type_and_unit_labels" json:"enable_type_and_unit_labels"`
-	EnableStartTimestamp              bool                `yaml:"enable_start_timestamp" json:"enable_start_timestamp"`
+	IngestionRate                     float64                   `yaml:"ingestion_rate" json:"ingestion_rate"`
+	NativeHistogramIngestionRate      float64                   `yaml:"native_histogram_ingestion_rate" json:"native_histogram_ingestion_rate"`
+	IngestionRateStrategy             string                    `yaml:"ingestion_rate_strategy" json:"ingestion_rate_strategy"`
+	IngestionBurstSize                int                       `yaml:"ingestion_burst_size" json:"ingestion_burst_size"`
+	NativeHistogramIngestionBurstSize int                       `yaml:"native_histogram_ingestion_burst_size" json:"native_histogram_ingestion_burst_size"`
+	AcceptHASamples                   bool                      `yaml:"accept_ha_samples" json:"accept_ha_samples"`
+	AcceptMixedHASamples              bool                      `yaml:"accept_mixed_ha_samples" json:"accept_mixed_ha_samples"`
+	HAClusterLabel                    string                    `yaml:"ha_cluster_label" json:"ha_cluster_label"`
+	HAReplicaLabel                    string                    `yaml:"ha_replica_label" json:"ha_replica_label"`
+	HAMaxClusters                     int                       `yaml:"ha_max_clusters" json:"ha_max_clusters"`
+	HATrackerFailoverTimeout          model.Duration            `yaml:"ha_tracker_failover_timeout" json:"ha_tracker_failover_timeout"`
+	DropLabels                        flagext.StringSlice       `yaml:"drop_labels" json:"drop_labels"`
+	MaxLabelNameLength                int                       `yaml:"max_label_name_length" json:"max_label_name_length"`
+	MaxLabelValueLength               int                       `yaml:"max_label_value_length" json:"max_label_value_length"`
+	MaxLabelNamesPerSeries            int                       `yaml:"max_label_names_per_series" json:"max_label_names_per_series"`
+	MaxLabelsSizeBytes                int                       `yaml:"max_labels_size_bytes" json:"max_labels_size_bytes"`
+	MaxNativeHistogramSampleSizeBytes int                       `yaml:"max_native_histogram_sample_size_bytes" json:"max_native_histogram_sample_size_bytes"`
+	MaxMetadataLength                 int                       `yaml:"max_metadata_length" json:"max_metadata_length"`
+	RejectOldSamples                  bool                      `yaml:"reject_old_samples" json:"reject_old_samples"`
+	RejectOldSamplesMaxAge            model.Duration            `yaml:"reject_old_samples_max_age" json:"reject_old_samples_max_age"`
+	CreationGracePeriod               model.Duration            `yaml:"creation_grace_period" json:"creation_grace_period"`
+	EnforceMetadataMetricName         bool                      `yaml:"enforce_metadata_metric_name" json:"enforce_metadata_metric_name"`
+	EnforceMetricName                 bool                      `yaml:"enforce_metric_name" json:"enforce_metric_name"`
+	IngestionTenantShardSize          int                       `yaml:"ingestion_tenant_shard_size" json:"ingestion_tenant_shard_size"`
+	MetricRelabelConfigs              []*relabel.Config         `yaml:"metric_relabel_configs,omitempty" json:"metric_relabel_configs,omitempty" doc:"nocli|description=List of metric relabel configurations. Note that in most situations, it is more effective to use metrics relabeling directly in the Prometheus server, e.g. remote_write.write_relabel_configs."`
+	MaxNativeHistogramBuckets         int                       `yaml:"max_native_histogram_buckets" json:"max_native_histogram_buckets"`
+	PromoteResourceAttributes         []string                  `yaml:"promote_resource_attributes" json:"promote_resource_attributes"`
+	EnableTypeAndUnitLabels           bool                      `yaml:"enable_type_and_unit_labels" json:"enable_type_and_unit_labels"`
+	EnableStartTimestamp              bool                      `yaml:"enable_start_timestamp" json:"enable_start_timestamp"`
+	EnableSeriesMetadata              bool                      `yaml:"enable_series_metadata" json:"enable_series_metadata"`
+	ForwardingEndpoints               ForwardingEndpointsConfig `yaml:"forwarding_endpoints,omitempty" json:"forwarding_endpoints,omitempty" doc:"nocli|description=[Experimental] List of remote write endpoints the distributor asynchronously forwards a copy of the accepted series and metadata to, with the tenant ID in the X-Scope-OrgID header. Forwarding failures don't fail the ingestion."`
+	AggregationRules                  AggregationRules          `yaml:"aggregation_rules,omitempty" json:"aggregation_rules,omitempty" doc:"nocli|description=[Experimental] List of aggregation rules evaluated by the distributors at ingest time. Each rule aggregates the float samples of the matching series into a new series pushed once per aggregation interval, optionally dropping the input series."`
+	WriteBufferMaxBytes               int                       `yaml:"write_buffer_max_bytes" json:"write_buffer_max_bytes"`
+	SeriesPolicy                      SeriesPolicy              `yaml:"series_policy" json:"series_policy" doc:"nocli|description=[Experimental] Naming conventions the series and metadata must follow, enforced by the distributors. Series violating the policy are discarded with a series_policy_* reason."`
+	DiscardedSeriesSamplingRate       float64                   `yaml:"discarded_series_sampling_rate" json:"discarded_series_sampling_rate"`
+	DiscardedSeriesSamplingBufferSize int                       `yaml:"discarded_series_sampling_buffer_size" json:"discarded_series_sampling_buffer_size"`
 
 	// Ingester enforced limits.
 	// Series
@@ -166,6 +175,10 @@ type Limits struct {
 	MaxGlobalSeriesPerUser                int                        `yaml:"max_global_series_per_user" json:"max_global_series_per_user"`
 	MaxGlobalSeriesPerMetric              int                        `yaml:"max_global_series_per_metric" json:"max_global_series_per_metric"`
 	MaxGlobalNativeHistogramSeriesPerUser int                        `yaml:"max_global_native_histogram_series_per_user" json:"max_global_native_histogram_series_per_user"`
+	MaxLocalSeriesChurnPerUser            int                        `yaml:"max_series_churn_per_user" json:"max_series_churn_per_user"`
+	MaxGlobalSeriesChurnPerUser           int                        `yaml:"max_global_series_churn_per_user" json:"max_global_series_churn_per_user"`
+	SeriesChurnWindow                     model.Duration             `yaml:"series_churn_window" json:"series_churn_window"`
+	SampleDedupWindow                     model.Duration             `yaml:"sample_dedup_window" json:"sample_dedup_window"`
 	LimitsPerLabelSet                     []LimitsPerLabelSet        `yaml:"limits_per_label_set" json:"limits_per_label_set" doc:"nocli|description=[Experimental] Enable limits per LabelSet. Supported limits per labelSet: [max_series]"`
 	ActiveSeriesTrackers                  ActiveSeriesTrackersConfig `yaml:"active_series_trackers,omitempty" json:"active_series_trackers,omitempty" doc:"nocli|description=List of active series tracker configurations. Each tracker counts active series matching its matchers and exposes the count as a metric."`
 	EnableNativeHistograms                bool                       `yaml:"enable_native_histograms" json:"enable_native_histograms"`
@@ -289,6 +302,10 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	f.Var(&l.DropLabels, "distributor.drop-label", "This flag can be used to specify label names that to drop during sample ingestion within the distributor and can be repeated in order to drop multiple labels.")
 	f.BoolVar(&l.EnableTypeAndUnitLabels, "distributor.enable-type-and-unit-labels", false, "EXPERIMENTAL: If true, the __type__ and __unit__ labels are added to metrics. This applies to remote write v2 and OTLP requests.")
 	f.BoolVar(&l.EnableStartTimestamp, "distributor.enable-start-timestamp", false, "EXPERIMENTAL: If true, StartTimestampMs (ST) is handled for remote write v2 samples and histograms. CreatedTimestamp (CT) is used as a fallback when ST is not set.")
+	f.BoolVar(&l.EnableSeriesMetadata, "distributor.enable-series-metadata", false, "EXPERIMENTAL: If true, the metadata of a write request, such as the per-series type, unit and help of remote write v2 requests, is always sharded by metric name, so that the ingesters owning the series of a metric also get its metadata when the series are sharded by metric name, and the ingesters store it in the TSDB along the series of the same request. The ingesters periodically snapshot the metadata in the TSDB directory and restore it when opening the TSDB, so that it's returned by the metadata API after a restart. The remote read responses include the metadata of the returned series metric names.")
+	f.IntVar(&l.WriteBufferMaxBytes, "distributor.write-buffer-max-bytes", 0, "[Experimental] Maximum size in bytes of the tenant's write requests buffered on disk by each distributor while less than a quorum of ingesters is reachable. Requires -distributor.write-buffer.dir to be set. The write requests of the tenant are marshaled only when they're buffered. If the limit is lowered, the oldest buffered requests exceeding it are dropped. 0 to disable.")
+	f.Float64Var(&l.DiscardedSeriesSamplingRate, "validation.discarded-series-sampling-rate", 0, "[Experimental] Maximum number of discarded series per second sampled by each distributor and ingester, with their labels, timestamp and discard reason. The latest samples are exposed by the /distributor/tenant/{tenant}/discarded_series and /ingester/tenant/{tenant}/discarded_series endpoints. 0 to disable.")
+	f.IntVar(&l.DiscardedSeriesSamplingBufferSize, "validation.discarded-series-sampling-buffer-size", 100, "[Experimental] Maximum number of discarded series samples kept in memory for each tenant by each distributor and ingester. The oldest samples are evicted first.")
 	f.IntVar(&l.MaxLabelNameLength, "validation.max-length-label-name", 1024, "Maximum length accepted for label names")
 	f.IntVar(&l.MaxLabelValueLength, "validation.max-length-label-value", 2048, "Maximum length accepted for label value. This setting also applies to the metric name")
 	f.IntVar(&l.MaxLabelNamesPerSeries, "validation.max-label-names-per-series", 30, "Maximum number of label names per series.")
@@ -315,6 +332,11 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&l.MaxGlobalSeriesPerMetric, "ingester.max-global-series-per-metric", 0, "The maximum number of active series per metric name, across the cluster before replication. 0 to disable.")
 	f.IntVar(&l.MaxLocalNativeHistogramSeriesPerUser, "ingester.max-native-histogram-series-per-user", 0, "The maximum number of active native histogram series per user, per ingester. 0 to disable. Supported only if ingester.active-series-metrics-enabled is true.")
 	f.IntVar(&l.MaxGlobalNativeHistogramSeriesPerUser, "ingester.max-global-native-histogram-series-per-user", 0, "The maximum number of active native histogram series per user, across the cluster before replication. 0 to disable. Supported only if -distributor.shard-by-all-labels and ingester.active-series-metrics-enabled is true.")
+	f.IntVar(&l.MaxLocalSeriesChurnPerUser, "ingester.max-series-churn-per-user", 0, "[Experimental] The maximum number of series a user can create within -ingester.series-churn-window, per ingester. 0 to disable.")
+	f.IntVar(&l.MaxGlobalSeriesChurnPerUser, "ingester.max-global-series-churn-per-user", 0, "[Experimental] The maximum number of series a user can create within -ingester.series-churn-window, across the cluster before replication. 0 to disable. Supported only if -distributor.shard-by-all-labels is true.")
+	_ = l.SeriesChurnWindow.Set("1h")
+	f.Var(&l.SeriesChurnWindow, "ingester.series-churn-window", "[Experimental] Sliding time window over which the series created by a user are counted, to enforce the series churn limits and report the series churn in the user stats.")
+	f.Var(&l.SampleDedupWindow, "ingester.sample-dedup-window", "[Experimental] If greater than 0, the ingester keeps at most one sample per series for each time bucket of this duration, and discards the other ones with the sample-deduplicated reason. Only the latest bucket of each series is tracked, so out of order samples in older buckets are not deduplicated. It deduplicates the identical streams pushed by HA pairs which can't add the cluster and replica labels used by the HA tracker. It must be lower than the scrape interval. 0 to disable.")
 	f.BoolVar(&l.EnableNativeHistograms, "blocks-storage.tsdb.enable-native-histograms", false, "[EXPERIMENTAL] True to enable native histogram.")
 	f.IntVar(&l.MaxExemplars, "ingester.max-exemplars", 0, "Enables support for exemplars in TSDB and sets the maximum number that will be stored. less than zero means disabled. If the value is set to zero, cortex will fallback to blocks-storage.tsdb.max-exemplars value.")
 	f.Var(&l.OutOfOrderTimeWindow, "ingester.out-of-order-time-window", "[Experimental] Configures the allowed time window for ingestion of out-of-order samples. Disabled (0s) by default.")
@@ -419,6 +441,14 @@ func (l *Limits) Validate(nameValidationScheme model.ValidationScheme, shardByAl
 		return errMaxLocalNativeHistogramSeriesPerUserValidation
 	}
 
+	if l.MaxGlobalSeriesChurnPerUser > 0 && !shardByAllLabels {
+		return errMaxGlobalSeriesChurnPerUserValidation
+	}
+
+	if (l.MaxLocalSeriesChurnPerUser > 0 || l.MaxGlobalSeriesChurnPerUser > 0) && l.SeriesChurnWindow <= 0 {
+		return errInvalidSeriesChurnWindow
+	}
+
 	if err := l.RulerExternalLabels.Validate(func(l labels.Label) error {
 		if !nameValidationScheme.IsValidLabelName(l.Name) {
 			return fmt.Errorf("%w: %q", errInvalidLabelName, l.Name)
@@ -497,6 +527,7 @@ func (l *Limits) UnmarshalYAML(unmarshal func(any) error) error {
 		*l = *defaultLimits
 		// Make copy of default limits. Otherwise unmarshalling would modify map in default limits.
 		l.copyNotificationIntegrationLimits(defaultLimits.NotificationRateLimitPerIntegration)
+		l.SeriesPolicy.LabelValueRegexes = maps.Clone(defaultLimits.SeriesPolicy.LabelValueRegexes)
 	}
 	type plain Limits
 	if err := unmarshal((*plain)(l)); err != nil {
@@ -515,6 +546,18 @@ func (l *Limits) UnmarshalYAML(unmarshal func(any) error) error {
 		return err
 	}
 
+	if err := l.ForwardingEndpoints.Validate(); err != nil {
+		return err
+	}
+
+	if err := l.AggregationRules.Validate(); err != nil {
+		return err
+	}
+
+	if err := l.SeriesPolicy.Validate(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -527,6 +570,7 @@ func (l *Limits) UnmarshalJSON(data []byte) error {
 		*l = *defaultLimits
 		// Make copy of default limits. Otherwise unmarshalling would modify map in default limits.
 		l.copyNotificationIntegrationLimits(defaultLimits.NotificationRateLimitPerIntegration)
+		l.SeriesPolicy.LabelValueRegexes = maps.Clone(defaultLimits.SeriesPolicy.LabelValueRegexes)
 	}
 
 	type plain Limits
@@ -549,6 +593,18 @@ func (l *Limits) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
+	if err := l.ForwardingEndpoints.Validate(); err != nil {
+		return err
+	}
+
+	if err := l.AggregationRules.Validate(); err != nil {
+		return err
+	}
+
+	if err := l.SeriesPolicy.Validate(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -829,6 +885,26 @@ func (o *Overrides) MaxGlobalNativeHistogramSeriesPerUser(userID string) int {
 	return o.GetOverridesForUser(userID).MaxGlobalNativeHistogramSeriesPerUser
 }
 
+// MaxLocalSeriesChurnPerUser returns the maximum number of series a user is allowed to create within the series churn window in a single ingester.
+func (o *Overrides) MaxLocalSeriesChurnPerUser(userID string) int {
+	return o.GetOverridesForUser(userID).MaxLocalSeriesChurnPerUser
+}
+
+// MaxGlobalSeriesChurnPerUser returns the maximum number of series a user is allowed to create within the series churn window across the cluster.
+func (o *Overrides) MaxGlobalSeriesChurnPerUser(userID string) int {
+	return o.GetOverridesForUser(userID).MaxGlobalSeriesChurnPerUser
+}
+
+// SeriesChurnWindow returns the sliding time window over which the series created by a user are counted.
+func (o *Overrides) SeriesChurnWindow(userID string) time.Duration {
+	return time.Duration(o.GetOverridesForUser(userID).SeriesChurnWindow)
+}
+
+// SampleDedupWindow returns the time bucket duration within which the ingester keeps at most one sample per series.
+func (o *Overrides) SampleDedupWindow(userID string) time.Duration {
+	return time.Duration(o.GetOverridesForUser(userID).SampleDedupWindow)
+}
+
 // EnableNativeHistograms returns whether the Ingester should accept native histogram samples from this user.
 func (o *Overrides) EnableNativeHistograms(userID string) bool {
 	return o.GetOverridesForUser(userID).EnableNativeHistograms
@@ -1225,6 +1301,35 @@ func (o *Overrides) EnableStartTimestamp(userID string) bool {
 	return o.GetOverridesForUser(userID).EnableStartTimestamp
 }
 
+func (o *Overrides) EnableSeriesMetadata(userID string) bool {
+	return o.GetOverridesForUser(userID).EnableSeriesMetadata
+}
+
+// ForwardingEndpoints returns the remote write endpoints the series of a given user are forwarded to.
+func (o *Overrides) ForwardingEndpoints(userID string) ForwardingEndpointsConfig {
+	return o.GetOverridesForUser(userID).ForwardingEndpoints
+}
+
+// AggregationRules returns the ingest-time aggregation rules for a given user.
+func (o *Overrides) AggregationRules(userID string) AggregationRules {
+	return o.GetOverridesForUser(userID).AggregationRules
+}
+
+// WriteBufferMaxBytes returns the maximum size of the write requests of a given user buffered on disk by each distributor.
+func (o *Overrides) WriteBufferMaxBytes(userID string) int {
+	return o.GetOverridesForUser(userID).WriteBufferMaxBytes
+}
+
+// DiscardedSeriesSamplingRate returns the maximum number of discarded series per second sampled for a given user.
+func (o *Overrides) DiscardedSeriesSamplingRate(userID string) float64 {
+	return o.GetOverridesForUser(userID).DiscardedSeriesSamplingRate
+}
+
+// DiscardedSeriesSamplingBufferSize returns the maximum number of discarded series samples kept for a given user.
+func (o *Overrides) DiscardedSeriesSamplingBufferSize(userID string) int {
+	return o.GetOverridesForUser(userID).DiscardedSeriesSamplingBufferSize
+}
+
 func (o *Overrides) DisabledRuleGroups(userID string) DisabledRuleGroups {
 	if o.tenantLimits != nil {
 		l := o.tenantLimits.ByUserID(userID)
@@ -118,6 +118,20 @@ func TestLimits_Validate(t *testing.T) {
 			activeSeriesMetricsEnabled: false,
 			expected:                   errMaxLocalNativeHistogramSeriesPerUserValidation,
 		},
+		"max-global-series-churn-per-user enabled and shard-by-all-labels=false": {
+			limits:           Limits{MaxGlobalSeriesChurnPerUser: 1000, SeriesChurnWindow: model.Duration(time.Hour)},
+			shardByAllLabels: false,
+			expected:         errMaxGlobalSeriesChurnPerUserValidation,
+		},
+		"max-global-series-churn-per-user enabled and shard-by-all-labels=true": {
+			limits:           Limits{MaxGlobalSeriesChurnPerUser: 1000, SeriesChurnWindow: model.Duration(time.Hour)},
+			shardByAllLabels: true,
+			expected:         nil,
+		},
+		"max-series-churn-per-user enabled and series-churn-window=0": {
+			limits:   Limits{MaxLocalSeriesChurnPerUser: 1000},
+			expected: errInvalidSeriesChurnWindow,
+		},
 		"external-labels invalid label name": {
 			limits:   Limits{RulerExternalLabels: labels.FromStrings("123invalid", "good")},
 			expected: errInvalidLabelName,
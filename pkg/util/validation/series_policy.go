@@ -0,0 +1,164 @@
+package validation
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/cortexproject/cortex/pkg/cortexpb"
+)
+
+const (
+	// Discard reasons of the series policy violations.
+	seriesPolicyMissingRequiredLabel = "series_policy_missing_required_label"
+	seriesPolicyForbiddenLabel       = "series_policy_forbidden_label"
+	seriesPolicyInvalidMetricName    = "series_policy_invalid_metric_name"
+	seriesPolicyInvalidLabelValue    = "series_policy_invalid_label_value"
+	seriesPolicyUnknownUnit          = "series_policy_unknown_unit"
+	seriesPolicyUnknownMetricType    = "series_policy_unknown_metric_type"
+
+	// Labels added to the series when -distributor.enable-type-and-unit-labels is enabled.
+	metricTypeLabel = "__type__"
+	metricUnitLabel = "__unit__"
+)
+
+var validMetricTypes = []string{
+	string(model.MetricTypeCounter),
+	string(model.MetricTypeGauge),
+	string(model.MetricTypeHistogram),
+	string(model.MetricTypeGaugeHistogram),
+	string(model.MetricTypeSummary),
+	string(model.MetricTypeInfo),
+	string(model.MetricTypeStateset),
+	string(model.MetricTypeUnknown),
+}
+
+// SeriesPolicy defines the naming conventions the series and metadata of a tenant must follow.
+type SeriesPolicy struct {
+	RequiredLabels     []string          `yaml:"required_labels" json:"required_labels" doc:"nocli|description=Labels every series must have, with a non-empty value."`
+	ForbiddenLabels    []string          `yaml:"forbidden_labels" json:"forbidden_labels" doc:"nocli|description=Labels no series can have."`
+	MetricNameRegex    string            `yaml:"metric_name_regex" json:"metric_name_regex" doc:"nocli|description=Anchored regular expression the metric names must match. If empty, metric names are not checked."`
+	LabelValueRegexes  map[string]string `yaml:"label_value_regexes" json:"label_value_regexes" doc:"nocli|description=Map of label names to the anchored regular expression their values must match. Series without the label are not checked.|default={}"`
+	AllowedUnits       []string          `yaml:"allowed_units" json:"allowed_units" doc:"nocli|description=Units allowed in the metadata, and in the __unit__ label of the series. If empty, units are not checked. Metrics without unit are always allowed."`
+	AllowedMetricTypes []string          `yaml:"allowed_metric_types" json:"allowed_metric_types" doc:"nocli|description=Metric types allowed in the metadata, and in the __type__ label of the series. Supported values are: counter, gauge, histogram, gaugehistogram, summary, info, stateset, unknown. If empty, metric types are not checked."`
+	WarnOnly           bool              `yaml:"warn_only" json:"warn_only" doc:"nocli|description=If true, the series and metadata violating the policy are not discarded, but counted in the cortex_series_policy_warnings_total metric.|default=false"`
+
+	// Compiled regular expressions, populated during validation.
+	metricNameRegex   *labels.FastRegexMatcher            `yaml:"-" json:"-" doc:"nocli"`
+	labelValueRegexes map[string]*labels.FastRegexMatcher `yaml:"-" json:"-" doc:"nocli"`
+}
+
+// Validate compiles the regular expressions and checks the policy.
+func (p *SeriesPolicy) Validate() error {
+	for _, name := range p.RequiredLabels {
+		if slices.Contains(p.ForbiddenLabels, name) {
+			return fmt.Errorf("series policy label %q can't be both required and forbidden", name)
+		}
+	}
+
+	p.metricNameRegex = nil
+	if p.MetricNameRegex != "" {
+		m, err := labels.NewFastRegexMatcher(p.MetricNameRegex)
+		if err != nil {
+			return fmt.Errorf("invalid series policy metric name regex: %w", err)
+		}
+		p.metricNameRegex = m
+	}
+
+	p.labelValueRegexes = nil
+	for name, regex := range p.LabelValueRegexes {
+		if p.labelValueRegexes == nil {
+			p.labelValueRegexes = make(map[string]*labels.FastRegexMatcher, len(p.LabelValueRegexes))
+		}
+		m, err := labels.NewFastRegexMatcher(regex)
+		if err != nil {
+			return fmt.Errorf("invalid series policy regex for label %q: %w", name, err)
+		}
+		p.labelValueRegexes[name] = m
+	}
+
+	for _, t := range p.AllowedMetricTypes {
+		if !slices.Contains(validMetricTypes, t) {
+			return fmt.Errorf("invalid series policy metric type %q, supported values are: %s", t, strings.Join(validMetricTypes, ", "))
+		}
+	}
+	return nil
+}
+
+// enabled returns whether the policy checks the series labels.
+func (p *SeriesPolicy) enabled() bool {
+	return len(p.RequiredLabels) > 0 || len(p.ForbiddenLabels) > 0 || p.metricNameRegex != nil ||
+		len(p.labelValueRegexes) > 0 || len(p.AllowedUnits) > 0 || len(p.AllowedMetricTypes) > 0
+}
+
+func (p *SeriesPolicy) allowedUnit(unit string) bool {
+	return unit == "" || len(p.AllowedUnits) == 0 || slices.Contains(p.AllowedUnits, unit)
+}
+
+func (p *SeriesPolicy) allowedMetricType(metricType string) bool {
+	return len(p.AllowedMetricTypes) == 0 || slices.Contains(p.AllowedMetricTypes, metricType)
+}
+
+// ValidateSeriesPolicy checks that ls follows the tenant's series policy. Labels must be sorted.
+// It returns (nil, "") when valid, or (error, discardReason) when invalid. Callers should increment
+// DiscardedSamples/DiscardedExemplars with the returned reason when non-empty, unless the policy is
+// in warn-only mode, in which case they should increment SeriesPolicyWarnings instead.
+func ValidateSeriesPolicy(limits *Limits, ls []cortexpb.LabelAdapter) (ValidationError, string) {
+	p := &limits.SeriesPolicy
+	if !p.enabled() {
+		return nil, ""
+	}
+
+	for _, name := range p.RequiredLabels {
+		if !hasLabel(ls, name) {
+			return newSeriesPolicyError(ls, "missing required label", name), seriesPolicyMissingRequiredLabel
+		}
+	}
+
+	for _, l := range ls {
+		if slices.Contains(p.ForbiddenLabels, l.Name) {
+			return newSeriesPolicyError(ls, "forbidden label", l.Name), seriesPolicyForbiddenLabel
+		}
+
+		switch {
+		case l.Name == labels.MetricName && p.metricNameRegex != nil && !p.metricNameRegex.MatchString(l.Value):
+			return newSeriesPolicyError(ls, "invalid metric name", l.Value), seriesPolicyInvalidMetricName
+		case l.Name == metricUnitLabel && !p.allowedUnit(l.Value):
+			return newSeriesPolicyError(ls, "unknown unit", l.Value), seriesPolicyUnknownUnit
+		case l.Name == metricTypeLabel && !p.allowedMetricType(l.Value):
+			return newSeriesPolicyError(ls, "unknown metric type", l.Value), seriesPolicyUnknownMetricType
+		}
+
+		if m, ok := p.labelValueRegexes[l.Name]; ok && !m.MatchString(l.Value) {
+			return newSeriesPolicyError(ls, "invalid value for label "+l.Name, l.Value), seriesPolicyInvalidLabelValue
+		}
+	}
+
+	return nil, ""
+}
+
+// validateMetadataPolicy checks that the metadata follows the tenant's series policy, and returns
+// the discard reason if not.
+func validateMetadataPolicy(p *SeriesPolicy, metadata *cortexpb.MetricMetadata) (string, string) {
+	if !p.allowedUnit(metadata.Unit) {
+		return seriesPolicyUnknownUnit, metadata.Unit
+	}
+
+	metricType := strings.ToLower(cortexpb.MetricMetadata_MetricType_name[int32(metadata.Type)])
+	if !p.allowedMetricType(metricType) {
+		return seriesPolicyUnknownMetricType, metricType
+	}
+	return "", ""
+}
+
+func hasLabel(ls []cortexpb.LabelAdapter, name string) bool {
+	for _, l := range ls {
+		if l.Name == name {
+			return l.Value != ""
+		}
+	}
+	return false
+}
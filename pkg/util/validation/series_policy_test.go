@@ -0,0 +1,172 @@
+package validation
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/httpgrpc"
+	"gopkg.in/yaml.v2"
+
+	"github.com/cortexproject/cortex/pkg/cortexpb"
+)
+
+func TestSeriesPolicy_Validate(t *testing.T) {
+	for name, tc := range map[string]struct {
+		policy      SeriesPolicy
+		expectedErr string
+	}{
+		"empty policy": {},
+		"valid policy": {
+			policy: SeriesPolicy{
+				RequiredLabels:     []string{"team"},
+				ForbiddenLabels:    []string{"pod_ip"},
+				MetricNameRegex:    "[a-z_]+",
+				LabelValueRegexes:  map[string]string{"env": "prod|staging"},
+				AllowedUnits:       []string{"seconds"},
+				AllowedMetricTypes: []string{"counter", "gauge"},
+			},
+		},
+		"required and forbidden label": {
+			policy:      SeriesPolicy{RequiredLabels: []string{"team"}, ForbiddenLabels: []string{"team"}},
+			expectedErr: `series policy label "team" can't be both required and forbidden`,
+		},
+		"invalid metric name regex": {
+			policy:      SeriesPolicy{MetricNameRegex: "("},
+			expectedErr: "invalid series policy metric name regex",
+		},
+		"invalid label value regex": {
+			policy:      SeriesPolicy{LabelValueRegexes: map[string]string{"env": "("}},
+			expectedErr: `invalid series policy regex for label "env"`,
+		},
+		"invalid metric type": {
+			policy:      SeriesPolicy{AllowedMetricTypes: []string{"histogram", "timer"}},
+			expectedErr: `invalid series policy metric type "timer"`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := tc.policy.Validate()
+			if tc.expectedErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tc.expectedErr)
+			}
+		})
+	}
+}
+
+func TestValidateSeriesPolicy(t *testing.T) {
+	limits := &Limits{}
+	require.NoError(t, yaml.Unmarshal([]byte(`
+series_policy:
+  required_labels: [team]
+  forbidden_labels: [pod_ip]
+  metric_name_regex: "[a-z_]+_(total|seconds|bytes)"
+  label_value_regexes:
+    env: prod|staging
+  allowed_units: [seconds, bytes]
+  allowed_metric_types: [counter, histogram]
+`), limits))
+
+	for name, tc := range map[string]struct {
+		series         labels.Labels
+		expectedErr    error
+		expectedReason string
+	}{
+		"valid series": {
+			series: labels.FromStrings(labels.MetricName, "http_requests_total", "env", "prod", "team", "a"),
+		},
+		"series without label checked by a regex": {
+			series: labels.FromStrings(labels.MetricName, "http_requests_total", "team", "a"),
+		},
+		"missing required label": {
+			series:         labels.FromStrings(labels.MetricName, "http_requests_total", "env", "prod"),
+			expectedErr:    newSeriesPolicyError(nil, "missing required label", "team"),
+			expectedReason: seriesPolicyMissingRequiredLabel,
+		},
+		"empty required label": {
+			series:         labels.FromStrings(labels.MetricName, "http_requests_total", "team", ""),
+			expectedErr:    newSeriesPolicyError(nil, "missing required label", "team"),
+			expectedReason: seriesPolicyMissingRequiredLabel,
+		},
+		"forbidden label": {
+			series:         labels.FromStrings(labels.MetricName, "http_requests_total", "pod_ip", "10.0.0.1", "team", "a"),
+			expectedErr:    newSeriesPolicyError(nil, "forbidden label", "pod_ip"),
+			expectedReason: seriesPolicyForbiddenLabel,
+		},
+		"invalid metric name": {
+			series:         labels.FromStrings(labels.MetricName, "http_requests", "team", "a"),
+			expectedErr:    newSeriesPolicyError(nil, "invalid metric name", "http_requests"),
+			expectedReason: seriesPolicyInvalidMetricName,
+		},
+		"invalid label value": {
+			series:         labels.FromStrings(labels.MetricName, "http_requests_total", "env", "dev", "team", "a"),
+			expectedErr:    newSeriesPolicyError(nil, "invalid value for label env", "dev"),
+			expectedReason: seriesPolicyInvalidLabelValue,
+		},
+		"unknown unit": {
+			series:         labels.FromStrings(labels.MetricName, "http_requests_total", metricUnitLabel, "requests", "team", "a"),
+			expectedErr:    newSeriesPolicyError(nil, "unknown unit", "requests"),
+			expectedReason: seriesPolicyUnknownUnit,
+		},
+		"unknown metric type": {
+			series:         labels.FromStrings(labels.MetricName, "http_requests_total", metricTypeLabel, "gauge", "team", "a"),
+			expectedErr:    newSeriesPolicyError(nil, "unknown metric type", "gauge"),
+			expectedReason: seriesPolicyUnknownMetricType,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			ls := cortexpb.FromLabelsToLabelAdapters(tc.series)
+			err, reason := ValidateSeriesPolicy(limits, ls)
+			assert.Equal(t, tc.expectedReason, reason)
+			if tc.expectedErr == nil {
+				assert.NoError(t, err)
+			} else {
+				tc.expectedErr.(*seriesPolicyError).series = ls
+				assert.Equal(t, tc.expectedErr, err)
+			}
+		})
+	}
+
+	// Nothing is checked without a policy.
+	err, reason := ValidateSeriesPolicy(&Limits{}, cortexpb.FromLabelsToLabelAdapters(labels.FromStrings("foo", "bar")))
+	assert.NoError(t, err)
+	assert.Empty(t, reason)
+}
+
+func TestValidateMetadata_SeriesPolicy(t *testing.T) {
+	cfg := new(Limits)
+	cfg.MaxMetadataLength = 100
+	cfg.SeriesPolicy = SeriesPolicy{AllowedUnits: []string{"seconds"}, AllowedMetricTypes: []string{"counter"}}
+	require.NoError(t, cfg.SeriesPolicy.Validate())
+	reg := prometheus.NewRegistry()
+	validateMetrics := NewValidateMetrics(reg)
+
+	assert.NoError(t, ValidateMetadata(validateMetrics, cfg, "user", &cortexpb.MetricMetadata{MetricFamilyName: "a_total", Type: cortexpb.COUNTER}))
+	assert.NoError(t, ValidateMetadata(validateMetrics, cfg, "user", &cortexpb.MetricMetadata{MetricFamilyName: "a_total", Type: cortexpb.COUNTER, Unit: "seconds"}))
+	assert.Equal(t,
+		httpgrpc.Errorf(http.StatusBadRequest, `metadata series policy violation (series_policy_unknown_unit): "requests" metric "a_total"`),
+		ValidateMetadata(validateMetrics, cfg, "user", &cortexpb.MetricMetadata{MetricFamilyName: "a_total", Type: cortexpb.COUNTER, Unit: "requests"}))
+	assert.Equal(t,
+		httpgrpc.Errorf(http.StatusBadRequest, `metadata series policy violation (series_policy_unknown_metric_type): "gauge" metric "a"`),
+		ValidateMetadata(validateMetrics, cfg, "user", &cortexpb.MetricMetadata{MetricFamilyName: "a", Type: cortexpb.GAUGE}))
+
+	// In warn-only mode, the metadata violating the policy is counted but not discarded.
+	cfg.SeriesPolicy.WarnOnly = true
+	assert.NoError(t, ValidateMetadata(validateMetrics, cfg, "user", &cortexpb.MetricMetadata{MetricFamilyName: "a", Type: cortexpb.UNKNOWN}))
+
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+		# HELP cortex_discarded_metadata_total The total number of metadata that were discarded.
+		# TYPE cortex_discarded_metadata_total counter
+		cortex_discarded_metadata_total{reason="series_policy_unknown_metric_type",user="user"} 1
+		cortex_discarded_metadata_total{reason="series_policy_unknown_unit",user="user"} 1
+		# HELP cortex_series_policy_warnings_total The total number of samples and metadata violating the series policy which were not discarded because the policy is in warn-only mode.
+		# TYPE cortex_series_policy_warnings_total counter
+		cortex_series_policy_warnings_total{reason="series_policy_unknown_metric_type",user="user"} 1
+	`), "cortex_discarded_metadata_total", "cortex_series_policy_warnings_total"))
+}
@@ -27,6 +27,7 @@ const (
 
 	errMetadataMissingMetricName = "metadata missing metric name"
 	errMetadataTooLong           = "metadata '%s' value too long: %.200q metric %.200q"
+	errMetadataPolicyViolation   = "metadata series policy violation (%s): %.200q metric %.200q"
 
 	typeMetricName = "METRIC_NAME"
 	typeHelp       = "HELP"
@@ -84,6 +85,7 @@ type ValidateMetrics struct {
 	DiscardedSamples                  *prometheus.CounterVec
 	DiscardedExemplars                *prometheus.CounterVec
 	DiscardedMetadata                 *prometheus.CounterVec
+	SeriesPolicyWarnings              *prometheus.CounterVec
 	HistogramSamplesReducedResolution *prometheus.CounterVec
 	LabelSizeBytes                    *prometheus.HistogramVec
 
@@ -136,6 +138,14 @@ func NewValidateMetrics(r prometheus.Registerer) *ValidateMetrics {
 		[]string{discardReasonLabel, "user"},
 	)
 	registerCollector(r, discardedMetadata)
+	seriesPolicyWarnings := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cortex_series_policy_warnings_total",
+			Help: "The total number of samples and metadata violating the series policy which were not discarded because the policy is in warn-only mode.",
+		},
+		[]string{discardReasonLabel, "user"},
+	)
+	registerCollector(r, seriesPolicyWarnings)
 	histogramSamplesReducedResolution := prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "cortex_reduced_resolution_histogram_samples_total",
@@ -174,6 +184,7 @@ func NewValidateMetrics(r prometheus.Registerer) *ValidateMetrics {
 		DiscardedSamplesPerLabelSet:       discardedSamplesPerLabelSet,
 		DiscardedExemplars:                discardedExemplars,
 		DiscardedMetadata:                 discardedMetadata,
+		SeriesPolicyWarnings:              seriesPolicyWarnings,
 		HistogramSamplesReducedResolution: histogramSamplesReducedResolution,
 		LabelSizeBytes:                    labelSizeBytes,
 		LabelSetTracker:                   labelset.NewLabelSetTracker(),
@@ -370,6 +381,15 @@ func ValidateMetadata(validateMetrics *ValidateMetrics, cfg *Limits, userID stri
 		return httpgrpc.Errorf(http.StatusBadRequest, errMetadataTooLong, metadataType, cause, metadata.GetMetricFamilyName())
 	}
 
+	if reason, cause := validateMetadataPolicy(&cfg.SeriesPolicy, metadata); reason != "" {
+		if cfg.SeriesPolicy.WarnOnly {
+			validateMetrics.SeriesPolicyWarnings.WithLabelValues(reason, userID).Inc()
+			return nil
+		}
+		validateMetrics.DiscardedMetadata.WithLabelValues(reason, userID).Inc()
+		return httpgrpc.Errorf(http.StatusBadRequest, errMetadataPolicyViolation, reason, cause, metadata.GetMetricFamilyName())
+	}
+
 	return nil
 }
 
@@ -501,6 +521,9 @@ func DeletePerUserValidationMetrics(validateMetrics *ValidateMetrics, userID str
 	if err := util.DeleteMatchingLabels(validateMetrics.DiscardedMetadata, filter); err != nil {
 		level.Warn(log).Log("msg", "failed to remove cortex_discarded_metadata_total metric for user", "user", userID, "err", err)
 	}
+	if err := util.DeleteMatchingLabels(validateMetrics.SeriesPolicyWarnings, filter); err != nil {
+		level.Warn(log).Log("msg", "failed to remove cortex_series_policy_warnings_total metric for user", "user", userID, "err", err)
+	}
 	if err := util.DeleteMatchingLabels(validateMetrics.HistogramSamplesReducedResolution, filter); err != nil {
 		level.Warn(log).Log("msg", "failed to remove cortex_reduced_resolution_histogram_samples_total metric for user", "user", userID, "err", err)
 	}
@@ -848,3 +848,34 @@ func TestValidateMetrics_UpdateLabelSet(t *testing.T) {
 			cortex_discarded_samples_total{reason="dummy",user="user"} 100
 	`), "cortex_discarded_samples_total", "cortex_discarded_samples_per_labelset_total"))
 }
+
+func TestDiscardReason(t *testing.T) {
+	series := []cortexpb.LabelAdapter{{Name: model.MetricNameLabel, Value: "foo"}}
+
+	for _, tc := range []struct {
+		err      error
+		expected string
+	}{
+		{err: newInvalidLabelError(series, "0invalid"), expected: invalidLabel},
+		{err: newDuplicatedLabelError(series, "foo"), expected: duplicateLabelNames},
+		{err: newLabelsNotSortedError(series, "foo"), expected: labelsNotSorted},
+		{err: newLabelNameTooLongError(series, "foo", 1), expected: labelNameTooLong},
+		{err: newLabelValueTooLongError(series, "foo", "bar", 1), expected: labelValueTooLong},
+		{err: labelSizeBytesExceededError(series, 10, 1), expected: labelsSizeBytesExceeded},
+		{err: newTooManyLabelsError(series, 0), expected: maxLabelNamesPerSeries},
+		{err: newNoMetricNameError(), expected: missingMetricName},
+		{err: newInvalidMetricNameError("0foo"), expected: invalidMetricName},
+		{err: newSampleTimestampTooOldError("foo", 0), expected: greaterThanMaxSampleAge},
+		{err: newSampleTimestampTooNewError("foo", 0), expected: tooFarInFuture},
+		{err: newExemplarEmtpyLabelsError(series, nil, 0), expected: exemplarLabelsMissing},
+		{err: newExemplarMissingTimestampError(series, nil, 0), expected: exemplarTimestampInvalid},
+		{err: newExemplarLabelLengthError(series, nil, 0), expected: exemplarLabelsTooLong},
+		{err: newHistogramBucketLimitExceededError(series, 1), expected: nativeHistogramBucketCountLimitExceeded},
+		{err: newNativeHistogramSchemaInvalidError(series, 100), expected: nativeHistogramInvalidSchema},
+		{err: newNativeHistogramSampleSizeBytesExceededError(series, 10, 1), expected: nativeHistogramSampleSizeBytesExceeded},
+		{err: newNativeHistogramInvalidError(series, errors.New("invalid")), expected: nativeHistogramInvalid},
+		{err: errors.New("other"), expected: ""},
+	} {
+		assert.Equal(t, tc.expected, DiscardReason(tc.err), tc.err.Error())
+	}
+}
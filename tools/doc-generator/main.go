@@ -31,6 +31,7 @@ import (
 	"github.com/cortexproject/cortex/pkg/ring/kv/consul"
 	"github.com/cortexproject/cortex/pkg/ring/kv/etcd"
 	"github.com/cortexproject/cortex/pkg/ring/kv/memberlist"
+	"github.com/cortexproject/cortex/pkg/ring/kv/raft"
 	"github.com/cortexproject/cortex/pkg/ruler"
 	"github.com/cortexproject/cortex/pkg/ruler/rulestore"
 	"github.com/cortexproject/cortex/pkg/storage/bucket/s3"
@@ -160,6 +161,11 @@ var (
 			structType: reflect.TypeFor[queryrange.Config](),
 			desc:       "The query_range_config configures the query splitting and caching in the Cortex query-frontend.",
 		},
+		{
+			name:       "raft_kv_config",
+			structType: reflect.TypeFor[raft.KVConfig](),
+			desc:       "The raft_kv_config configures the embedded Raft-based KV store.",
+		},
 		{
 			name:       "redis_config",
 			structType: reflect.TypeFor[cache.RedisConfig](),